@@ -0,0 +1,124 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc/jsonrpc"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkpointHeight *uint64 // checkpointHeight is the height of the canonical block to finalize
+	checkpointKey    *string // checkpointKey is the key file path of the checkpoint authority
+)
+
+// finalityCmd represents the get finality command
+var finalityCmd = &cobra.Command{
+	Use:   "getfinality",
+	Short: "get the finality status of the chain",
+	Long: `get the latest finalized block, the finality depth and the latest checkpoint of the chain.
+    For example:
+		client.exe getfinality`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jsonrpc.Dial("tcp", rpcAddr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer client.Close()
+
+		var finality core.Finality
+		if err = client.Call("seele.GetFinality", nil, &finality); err != nil {
+			fmt.Printf("get finality failed %s\n", err.Error())
+			return
+		}
+
+		str, err := json.MarshalIndent(finality, "", "\t")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println(string(str))
+	},
+}
+
+// checkpointCmd represents the add checkpoint command
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "finalize the canonical block with the checkpoint authority key",
+	Long: `sign the checkpoint of the canonical block at the height with the key of the checkpoint authority,
+    and add it to the node via admin.AddCheckpoint.
+    For example:
+		client.exe checkpoint --height 1000 -f keyfile`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jsonrpc.Dial("tcp", rpcAddr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer client.Close()
+
+		admin, err := jsonrpc.Dial("tcp", adminAddr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer admin.Close()
+
+		pass, err := common.GetPassword()
+		if err != nil {
+			fmt.Printf("get password failed %s\n", err.Error())
+			return
+		}
+
+		key, err := keystore.GetKey(resolveKeyFile(*checkpointKey), pass)
+		if err != nil {
+			fmt.Printf("invalid authority key file. it should be a private key: %s\n", err.Error())
+			return
+		}
+
+		request := seele.GetBlockByHeightRequest{Height: int64(*checkpointHeight)}
+		var block map[string]interface{}
+		if err = client.Call("seele.GetBlockByHeight", &request, &block); err != nil {
+			fmt.Printf("get block failed %s\n", err.Error())
+			return
+		}
+
+		hash, err := common.HexToHash(block["hash"].(string))
+		if err != nil {
+			fmt.Printf("invalid block hash %s\n", err.Error())
+			return
+		}
+
+		var result bool
+		checkpoint := core.NewCheckpoint(*checkpointHeight, hash, key.PrivateKey)
+		if err = admin.Call("admin.AddCheckpoint", checkpoint, &result); err != nil {
+			fmt.Printf("add checkpoint failed %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("block %d of hash %s finalized\n", *checkpointHeight, hash.ToHex())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(finalityCmd)
+	rootCmd.AddCommand(checkpointCmd)
+
+	checkpointHeight = checkpointCmd.Flags().Uint64("height", 0, "height of the canonical block to finalize")
+	checkpointCmd.MarkFlagRequired("height")
+
+	checkpointKey = checkpointCmd.Flags().StringP("from", "f", "", "key file of the checkpoint authority")
+	checkpointCmd.MarkFlagRequired("from")
+}
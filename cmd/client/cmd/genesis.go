@@ -0,0 +1,180 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/bft"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/miner/pow"
+	"github.com/spf13/cobra"
+)
+
+var genesisFile *string
+var genesisChainID *uint64
+var genesisAccounts *[]string
+var genesisDifficulty *int64
+var genesisBlockPeriod *uint64
+var genesisMinDifficulty *int64
+var genesisRewards *[]uint
+var genesisTailReward *int64
+var genesisInitialReward *int64
+var genesisHalvingInterval *uint64
+var genesisBlocksPerEra *uint64
+var genesisPowAlgorithm *string
+var genesisValidators *[]string
+
+// genesisCmd represents the genesis command
+var genesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "genesis spec actions",
+	Long:  `create the genesis spec file to bootstrap a private network`,
+}
+
+// genesisInitCmd represents the genesis init command
+var genesisInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "create a genesis spec file",
+	Long: `create a genesis spec file, which is written to the data folder of a node by "node init <genesis.json>"
+    For example:
+		client.exe genesis init -f genesis.json --chainid 100 --account 0x<address>=1000 --period 1 --mindifficulty 1000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		info := core.GenesisInfo{
+			ChainID:  *genesisChainID,
+			Accounts: make(map[string]int64),
+		}
+
+		for _, account := range *genesisAccounts {
+			parts := strings.SplitN(account, "=", 2)
+			if len(parts) != 2 {
+				fmt.Printf("invalid account %s, should be <address>=<balance>\n", account)
+				return
+			}
+
+			address, err := parseAddress(parts[0])
+			if err != nil {
+				fmt.Printf("invalid account address: %s\n", err.Error())
+				return
+			}
+
+			balance, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil || balance < 0 {
+				fmt.Printf("invalid account balance %s\n", parts[1])
+				return
+			}
+
+			info.Accounts[address.ToHex()] = balance
+		}
+
+		if *genesisDifficulty > 0 {
+			info.InitialDifficulty = big.NewInt(*genesisDifficulty)
+		}
+
+		if *genesisBlockPeriod > 0 || *genesisMinDifficulty > 0 {
+			info.Difficulty = pow.DefaultDifficultyConfig()
+			if *genesisBlockPeriod > 0 {
+				info.Difficulty.BlockPeriod = *genesisBlockPeriod
+			}
+
+			if *genesisMinDifficulty > 0 {
+				info.Difficulty.MinDifficulty = big.NewInt(*genesisMinDifficulty)
+			}
+		}
+
+		if len(*genesisRewards) > 0 || *genesisTailReward >= 0 || *genesisBlocksPerEra > 0 || *genesisHalvingInterval > 0 {
+			info.Reward = pow.DefaultRewardConfig()
+			if len(*genesisRewards) > 0 {
+				info.Reward.Rewards = make([]int64, len(*genesisRewards))
+				for i, reward := range *genesisRewards {
+					info.Reward.Rewards[i] = int64(reward)
+				}
+			}
+
+			if *genesisTailReward >= 0 {
+				info.Reward.TailReward = *genesisTailReward
+			}
+
+			if *genesisBlocksPerEra > 0 {
+				info.Reward.BlocksPerEra = *genesisBlocksPerEra
+			}
+
+			// the halving curve replaces the default reward table
+			if *genesisHalvingInterval > 0 {
+				info.Reward.InitialReward = *genesisInitialReward
+				info.Reward.HalvingInterval = *genesisHalvingInterval
+				if len(*genesisRewards) == 0 {
+					info.Reward.Rewards = nil
+				}
+			}
+		}
+
+		if *genesisPowAlgorithm != "" {
+			info.PowAlgorithm = *genesisPowAlgorithm
+		}
+
+		if len(*genesisValidators) > 0 {
+			info.Bft = &bft.Config{BlockPeriod: *genesisBlockPeriod}
+			for _, validator := range *genesisValidators {
+				address, err := parseAddress(validator)
+				if err != nil {
+					fmt.Printf("invalid validator address: %s\n", err.Error())
+					return
+				}
+
+				info.Bft.Validators = append(info.Bft.Validators, address)
+			}
+		}
+
+		if err := info.Validate(); err != nil {
+			fmt.Printf("invalid genesis spec: %s\n", err.Error())
+			return
+		}
+
+		encoded, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			fmt.Printf("encoding the genesis spec failed: %s\n", err.Error())
+			return
+		}
+
+		if common.FileOrFolderExists(*genesisFile) {
+			fmt.Printf("genesis spec file %s already exists\n", *genesisFile)
+			return
+		}
+
+		if err = ioutil.WriteFile(*genesisFile, encoded, 0644); err != nil {
+			fmt.Printf("writing the genesis spec failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("genesis spec written to %s\n", *genesisFile)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(genesisCmd)
+	genesisCmd.AddCommand(genesisInitCmd)
+
+	genesisFile = genesisInitCmd.Flags().StringP("file", "f", "genesis.json", "genesis spec file to create")
+	genesisChainID = genesisInitCmd.Flags().Uint64("chainid", 0, "network id of the chain, 0 to use the network id of the node config")
+	genesisAccounts = genesisInitCmd.Flags().StringSlice("account", nil, "pre-funded account of <address>=<balance>, could be specified multiple times")
+	genesisDifficulty = genesisInitCmd.Flags().Int64("difficulty", 0, "difficulty of the genesis block, 0 for the default")
+	genesisBlockPeriod = genesisInitCmd.Flags().Uint64("period", 0, "target block period in seconds, 0 for the default")
+	genesisMinDifficulty = genesisInitCmd.Flags().Int64("mindifficulty", 0, "floor of the block difficulty, 0 for the default")
+	genesisRewards = genesisInitCmd.Flags().UintSlice("rewards", nil, "block rewards per era, e.g. 200,100,50, empty for the default")
+	genesisTailReward = genesisInitCmd.Flags().Int64("tailreward", -1, "block reward after the eras of rewards, -1 for the default")
+	genesisInitialReward = genesisInitCmd.Flags().Int64("initialreward", 0, "block reward of the halving curve before the first halving")
+	genesisHalvingInterval = genesisInitCmd.Flags().Uint64("halvinginterval", 0, "number of blocks after which the block reward halves, 0 to use the rewards per era")
+	genesisBlocksPerEra = genesisInitCmd.Flags().Uint64("blocksperera", 0, "number of blocks of a reward era, 0 for the default")
+	genesisPowAlgorithm = genesisInitCmd.Flags().String("algorithm", "", "POW algorithm of the chain, hash, memhash or bft, empty for hash")
+	genesisValidators = genesisInitCmd.Flags().StringSlice("validators", nil, "validator addresses of the bft consensus, e.g. 0x<address1>,0x<address2>")
+}
@@ -36,7 +36,7 @@ var getbalanceCmd = &cobra.Command{
 		if account == nil || *account == "" {
 			address = nil
 		} else {
-			result, err := common.HexToAddress(*account)
+			result, err := parseAddress(*account)
 			if err != nil {
 				fmt.Printf("invalid account address: %s\n", err.Error())
 				return
@@ -54,7 +54,7 @@ var getbalanceCmd = &cobra.Command{
 		if address == nil {
 			fmt.Printf("no account is provided. the coinbase balance: %s\n", amount)
 		} else {
-			fmt.Printf("Account: %s\nBalance: %s\n", address.ToHex(), amount)
+			fmt.Printf("Account: %s\nBalance: %s\n", address.ToChecksumHex(), amount)
 		}
 	},
 }
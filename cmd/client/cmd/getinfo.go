@@ -34,7 +34,8 @@ var getinfo = &cobra.Command{
 			fmt.Printf("getting the miner info failed: %s\n", err.Error())
 		}
 
-		fmt.Printf("coinbase address: %s\n", info.Coinbase.ToHex())
+		fmt.Printf("network id: %d\n", info.NetworkID)
+		fmt.Printf("coinbase address: %s\n", info.Coinbase.ToChecksumHex())
 		fmt.Printf("current block height: %d\n", info.CurrentBlockHeight)
 		fmt.Printf("current block header hash: %s\n", info.HeaderHash.ToHex())
 	},
@@ -0,0 +1,215 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strings"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+type htlcInfo struct {
+	operation *string // operation is the HTLC action
+	sender    *string // sender is the address refunded since the time lock
+	receiver  *string // receiver is the address to claim the funds with the preimage
+	hashLock  *string // hashLock is the SHA-256 hash of the preimage
+	timeLock  *uint64 // timeLock is the block height since which the funds could only be refunded
+	preimage  *string // preimage is the secret to claim the funds
+	amount    *uint64 // amount is the amount to claim or refund, 0 for all the funds but the fee
+	gasPrice  *uint64 // gasPrice specifies the fee paid for each unit of gas used
+	sigHash   *uint8  // sigHash is the sighash version to sign the tx
+	chainID   *uint64 // chainID is the network id the tx is signed for
+	from      *string // from is the key file path of the receiver to claim or the sender to refund
+}
+
+var htlcParameter = htlcInfo{}
+
+// htlcCmd represents the htlc command
+var htlcCmd = &cobra.Command{
+	Use:   "htlc",
+	Short: "hash-time-locked transfer actions for the atomic swaps",
+	Long: `The funds are locked by transferring to the HTLC address with sendtx.
+  For example:
+    client.exe htlc -o secret
+    client.exe htlc -o status --sender 0x<address> --receiver 0x<address> --hashlock 0x<hash> --timelock <height>
+    client.exe htlc -o claim --sender 0x<address> --receiver 0x<address> --hashlock 0x<hash> --timelock <height> --preimage 0x<secret> -f keyfile
+    client.exe htlc -o refund --sender 0x<address> --receiver 0x<address> --hashlock 0x<hash> --timelock <height> -f keyfile`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if strings.ToLower(*htlcParameter.operation) == "secret" {
+			preimage := make([]byte, types.HTLCPreimageSize)
+			if _, err := rand.Read(preimage); err != nil {
+				fmt.Printf("generating the preimage failed: %s\n", err.Error())
+				return
+			}
+
+			fmt.Printf("preimage: %s\n", hexutil.BytesToHex(preimage))
+			fmt.Printf("hash lock: %s\n", types.HTLCHashLock(preimage).ToHex())
+			return
+		}
+
+		htlc, err := parseHTLC()
+		if err != nil {
+			fmt.Printf("invalid HTLC: %s\n", err.Error())
+			return
+		}
+
+		client, err := jsonrpc.Dial("tcp", rpcAddr)
+		if err != nil {
+			fmt.Printf("invalid address: %s\n", err.Error())
+			return
+		}
+		defer client.Close()
+
+		var info seele.HTLCInfo
+		if err = client.Call("seele.GetHTLC", htlc, &info); err != nil {
+			fmt.Printf("getting the HTLC failed: %s\n", err.Error())
+			return
+		}
+
+		switch strings.ToLower(*htlcParameter.operation) {
+		case "status":
+			fmt.Printf("address: %s\n", info.Address.ToHex())
+			fmt.Printf("balance: %s, nonce: %d\n", info.Balance, info.Nonce)
+			fmt.Printf("height: %d, time lock: %d, expired: %v\n", info.Height, htlc.TimeLock, info.Expired)
+		case "claim":
+			preimage, err := hexutil.HexToBytes(*htlcParameter.preimage)
+			if err != nil {
+				fmt.Printf("invalid preimage: %s\n", err.Error())
+				return
+			}
+
+			tx, err := newHTLCTx(cmd, client, &info, func(amount, gasPrice *big.Int) (*types.Transaction, error) {
+				return types.NewHTLCClaimTransaction(htlc, preimage, amount, gasPrice, types.TransferGas, info.Nonce)
+			})
+			if err != nil {
+				fmt.Printf("creating the claim tx failed: %s\n", err.Error())
+				return
+			}
+
+			sendHTLCTx(client, tx)
+		case "refund":
+			tx, err := newHTLCTx(cmd, client, &info, func(amount, gasPrice *big.Int) (*types.Transaction, error) {
+				return types.NewHTLCRefundTransaction(htlc, amount, gasPrice, types.TransferGas, info.Nonce)
+			})
+			if err != nil {
+				fmt.Printf("creating the refund tx failed: %s\n", err.Error())
+				return
+			}
+
+			sendHTLCTx(client, tx)
+		default:
+			fmt.Printf("unknown operation: %s\n", *htlcParameter.operation)
+		}
+	},
+}
+
+// parseHTLC parses the HTLC of the flags.
+func parseHTLC() (*types.HTLC, error) {
+	sender, err := parseAddress(*htlcParameter.sender)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err := parseAddress(*htlcParameter.receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	hashLock, err := common.HexToHash(*htlcParameter.hashLock)
+	if err != nil {
+		return nil, err
+	}
+
+	htlc := &types.HTLC{
+		Sender:   sender,
+		Receiver: receiver,
+		HashLock: hashLock,
+		TimeLock: *htlcParameter.timeLock,
+	}
+
+	return htlc, htlc.Validate()
+}
+
+// newHTLCTx creates the HTLC tx of the amount in flag, or all the funds but the max fee, and signs it
+// for the network of the node by default to prevent replay on other networks.
+func newHTLCTx(cmd *cobra.Command, client *rpc.Client, info *seele.HTLCInfo, create func(amount, gasPrice *big.Int) (*types.Transaction, error)) (*types.Transaction, error) {
+	if types.SigHashVersion(*htlcParameter.sigHash) > types.LatestSigHashVersion {
+		return nil, fmt.Errorf("invalid sighash version %d, the latest version is %d", *htlcParameter.sigHash, types.LatestSigHashVersion)
+	}
+
+	chainID := *htlcParameter.chainID
+	if !cmd.Flags().Changed("chainid") {
+		var minerInfo seele.MinerInfo
+		if err := client.Call("seele.GetInfo", nil, &minerInfo); err != nil {
+			return nil, fmt.Errorf("getting the network id failed: %s", err.Error())
+		}
+
+		chainID = minerInfo.NetworkID
+	}
+
+	gasPrice := new(big.Int).SetUint64(*htlcParameter.gasPrice)
+	amount := new(big.Int).SetUint64(*htlcParameter.amount)
+	if amount.Sign() == 0 {
+		amount.Sub(info.Balance, new(big.Int).Mul(gasPrice, big.NewInt(types.TransferGas)))
+	}
+
+	tx, err := create(amount, gasPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	pass, err := common.GetPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keystore.GetKey(resolveKeyFile(*htlcParameter.from), pass)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.SignWithScheme(key.PrivateKey, types.SigHashScheme{Version: types.SigHashVersion(*htlcParameter.sigHash), ChainID: chainID})
+	return tx, nil
+}
+
+// sendHTLCTx adds the HTLC tx to the tx pool of the node.
+func sendHTLCTx(client *rpc.Client, tx *types.Transaction) {
+	var result bool
+	if err := client.Call("seele.AddTx", &tx, &result); err != nil || !result {
+		fmt.Printf("adding the tx failed: %v\n", err)
+		return
+	}
+
+	fmt.Printf("adding the tx succeeded, hash: %s\n", tx.Hash.ToHex())
+}
+
+func init() {
+	rootCmd.AddCommand(htlcCmd)
+
+	htlcParameter.operation = htlcCmd.Flags().StringP("operation", "o", "", "operation of the HTLC, exp[secret, status, claim, refund]")
+	htlcCmd.MarkFlagRequired("operation")
+
+	htlcParameter.sender = htlcCmd.Flags().String("sender", "", "address of the sender refunded since the time lock")
+	htlcParameter.receiver = htlcCmd.Flags().String("receiver", "", "address of the receiver to claim the funds with the preimage")
+	htlcParameter.hashLock = htlcCmd.Flags().String("hashlock", "", "SHA-256 hash of the preimage")
+	htlcParameter.timeLock = htlcCmd.Flags().Uint64("timelock", 0, "block height since which the funds could only be refunded")
+	htlcParameter.preimage = htlcCmd.Flags().String("preimage", "", "the secret to claim the funds")
+	htlcParameter.amount = htlcCmd.Flags().Uint64P("amount", "m", 0, "the amount to claim or refund, 0 for all the funds but the fee")
+	htlcParameter.gasPrice = htlcCmd.Flags().Uint64("price", 1, "the fee paid for each unit of gas used")
+	htlcParameter.sigHash = htlcCmd.Flags().Uint8("sighash", uint8(types.SigHashLegacy), "the sighash version to sign the tx")
+	htlcParameter.chainID = htlcCmd.Flags().Uint64("chainid", 0, "the network id the tx is signed for, which is the network id of the node by default")
+	htlcParameter.from = htlcCmd.Flags().StringP("from", "f", "", "key file path of the receiver to claim or the sender to refund, or the key name in <datadir>/keystore")
+}
@@ -6,15 +6,26 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/rpc/jsonrpc"
+	"os"
 	"strings"
 
+	"github.com/seeleteam/go-seele/miner"
 	"github.com/spf13/cobra"
 )
 
 var threadsNum *int
 var operation *string
+var hashrate *float64
+var workID *string
+var workNonce *uint64
+var reportFrom *uint64
+var reportTo *uint64
+var reportCoinbases *[]string
+var reportFormat *string
+var reportFile *string
 
 // getbalanceCmd represents the getbalance command
 var minerCmd = &cobra.Command{
@@ -22,7 +33,16 @@ var minerCmd = &cobra.Command{
 	Short: "miner actions",
 	Long: `For example:
 	 client.exe miner -o start [-t <miner threads num>]
-	 client.exe miner -o stop`,
+	 client.exe miner -o stop
+	 client.exe miner -o status
+	 client.exe miner -o hashrate
+	 client.exe miner -o estimate [-r <hashes per second>]
+	 client.exe miner -o report --from <height> [--to <height>] [--coinbase <address>] [--format csv|json] [--out <file>]
+	 client.exe miner -o template
+	 client.exe miner -o blocktemplate
+	 client.exe miner -o getwork
+	 client.exe miner -o submitwork --id <work id> --nonce <nonce>
+	 client.exe miner -o stratum`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client, err := jsonrpc.Dial("tcp", rpcAddr)
 		if err != nil {
@@ -48,6 +68,101 @@ var minerCmd = &cobra.Command{
 				return
 			}
 			fmt.Println("miner stop succeed")
+		case "status":
+			var status map[string]interface{}
+			err = client.Call("miner.GetStatus", &input, &status)
+			if err != nil {
+				fmt.Printf("getting the miner status failed: %s\n", err.Error())
+				return
+			}
+
+			encoded, _ := json.MarshalIndent(status, "", "\t")
+			fmt.Println(string(encoded))
+		case "hashrate":
+			var rate map[string]interface{}
+			err = client.Call("miner.Hashrate", &input, &rate)
+			if err != nil {
+				fmt.Printf("getting the miner hashrate failed: %s\n", err.Error())
+				return
+			}
+			fmt.Printf("hashrate: %.2f hashes/s\n", rate["total"])
+			if threads, ok := rate["threads"].([]interface{}); ok {
+				for i, threadRate := range threads {
+					fmt.Printf("thread %d: %.2f hashes/s\n", i, threadRate)
+				}
+			}
+		case "estimate":
+			var earnings map[string]interface{}
+			err = client.Call("miner.EstimateEarnings", hashrate, &earnings)
+			if err != nil {
+				fmt.Printf("estimating the mining earnings failed: %s\n", err.Error())
+				return
+			}
+			fmt.Printf("hashrate: %v hashes/s\n", earnings["hashrate"])
+			fmt.Printf("difficulty: %v\n", earnings["difficulty"])
+			fmt.Printf("block reward: %v, average fees: %v\n", earnings["blockReward"], earnings["averageFees"])
+			fmt.Printf("blocks/day: %v, coins/day: %v\n", earnings["blocksPerDay"], earnings["coinsPerDay"])
+		case "report":
+			request := map[string]interface{}{"From": *reportFrom, "To": *reportTo, "Coinbases": *reportCoinbases}
+			var report miner.EarningsReport
+			err = client.Call("miner.EarningsReport", request, &report)
+			if err != nil {
+				fmt.Printf("building the earnings report failed: %s\n", err.Error())
+				return
+			}
+
+			if err = writeEarningsReport(&report, *reportFormat, *reportFile); err != nil {
+				fmt.Printf("writing the earnings report failed: %s\n", err.Error())
+			}
+		case "template":
+			var template map[string]interface{}
+			err = client.Call("miner.BuildBlockTemplate", map[string]interface{}{}, &template)
+			if err != nil {
+				fmt.Printf("building the block template failed: %s\n", err.Error())
+				return
+			}
+
+			encoded, _ := json.MarshalIndent(template, "", "\t")
+			fmt.Println(string(encoded))
+		case "blocktemplate":
+			var block map[string]interface{}
+			err = client.Call("miner.GetBlockTemplate", map[string]interface{}{}, &block)
+			if err != nil {
+				fmt.Printf("getting the block template failed: %s\n", err.Error())
+				return
+			}
+
+			encoded, _ := json.MarshalIndent(block, "", "\t")
+			fmt.Println(string(encoded))
+		case "getwork":
+			var work map[string]interface{}
+			err = client.Call("miner.GetWork", &input, &work)
+			if err != nil {
+				fmt.Printf("getting the mining work failed: %s\n", err.Error())
+				return
+			}
+
+			encoded, _ := json.MarshalIndent(work, "", "\t")
+			fmt.Println(string(encoded))
+		case "submitwork":
+			request := map[string]interface{}{"id": *workID, "nonce": *workNonce}
+			var accepted bool
+			err = client.Call("miner.SubmitWork", request, &accepted)
+			if err != nil {
+				fmt.Printf("submitting the mining work failed: %s\n", err.Error())
+				return
+			}
+			fmt.Println("mining work accepted")
+		case "stratum":
+			var stats []map[string]interface{}
+			err = client.Call("miner.GetStratumStats", &input, &stats)
+			if err != nil {
+				fmt.Printf("getting the stratum stats failed: %s\n", err.Error())
+				return
+			}
+
+			encoded, _ := json.MarshalIndent(stats, "", "\t")
+			fmt.Println(string(encoded))
 		default:
 			fmt.Println("operation is not defined.")
 		}
@@ -57,8 +172,48 @@ var minerCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(minerCmd)
 
-	threadsNum = minerCmd.Flags().IntP("threads", "t", 0, "threads num of the miner")
+	threadsNum = minerCmd.Flags().IntP("threads", "t", 0, "threads num of the miner, 0 for the number of CPUs, -1 to mine by the external miners only")
+
+	hashrate = minerCmd.Flags().Float64P("hashrate", "r", 0, "hashes per second to estimate the earnings, 0 for the measured hashrate of the miner")
 
-	operation = minerCmd.Flags().StringP("operation", "o", "", "operation of the miner, exp[start, stop]")
+	workID = minerCmd.Flags().String("id", "", "id of the mining work to submit")
+	workNonce = minerCmd.Flags().Uint64("nonce", 0, "nonce found for the mining work to submit")
+
+	reportFrom = minerCmd.Flags().Uint64("from", 1, "first block height of the earnings report")
+	reportTo = minerCmd.Flags().Uint64("to", 0, "last block height of the earnings report, 0 for the HEAD block")
+	reportCoinbases = minerCmd.Flags().StringSlice("coinbase", nil, "coinbase address of the earnings report, the coinbase of the node if not specified")
+	reportFormat = minerCmd.Flags().String("format", "json", "format of the earnings report, csv or json")
+	reportFile = minerCmd.Flags().String("out", "", "file to write the earnings report, the stdout if not specified")
+
+	operation = minerCmd.Flags().StringP("operation", "o", "", "operation of the miner, exp[start, stop, status, hashrate, estimate, template, blocktemplate, getwork, submitwork, stratum]")
 	minerCmd.MarkFlagRequired("operation")
 }
+
+// writeEarningsReport writes the earnings report in csv or json to the file, or the stdout if not specified.
+func writeEarningsReport(report *miner.EarningsReport, format string, file string) error {
+	out := os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	switch strings.ToLower(format) {
+	case "csv":
+		return report.WriteCSV(out)
+	case "json":
+		encoded, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(out, string(encoded))
+		return err
+	default:
+		return fmt.Errorf("unsupported format %s", format)
+	}
+}
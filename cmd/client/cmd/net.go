@@ -0,0 +1,83 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc/jsonrpc"
+
+	"github.com/seeleteam/go-seele/p2p"
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+var diagPeers *[]string
+var diagNodes *[]string
+
+// netCmd represents the network command
+var netCmd = &cobra.Command{
+	Use:   "net",
+	Short: "network actions",
+	Long:  `troubleshoot the connectivity of the node`,
+}
+
+// netDiagCmd represents the network diagnostics command
+var netDiagCmd = &cobra.Command{
+	Use:   "diag",
+	Short: "ping the connected peers and test dialing the nodes",
+	Long: `measure the protocol-level round trip time of the connected peers, and dial the nodes with
+    the full handshake to report the stage where it failed, see admin.PingPeer and admin.DialTest.
+    For example:
+		client.exe net diag --peer <node id> --node snode://<node id>@<ip>:<port>`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(*diagPeers) == 0 && len(*diagNodes) == 0 {
+			fmt.Println("no peer or node specified")
+			return
+		}
+
+		client, err := jsonrpc.Dial("tcp", adminAddr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer client.Close()
+
+		for _, peer := range *diagPeers {
+			var result seele.PingResult
+			if err = client.Call("admin.PingPeer", &peer, &result); err != nil {
+				fmt.Printf("ping peer %s failed %s\n", peer, err.Error())
+				continue
+			}
+
+			fmt.Printf("ping peer %s: rtt=%.3fms\n", peer, result.RTT)
+		}
+
+		for _, node := range *diagNodes {
+			var result p2p.DialResult
+			if err = client.Call("admin.DialTest", &node, &result); err != nil {
+				fmt.Printf("dial test %s failed %s\n", node, err.Error())
+				continue
+			}
+
+			str, err := json.MarshalIndent(result, "", "\t")
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			fmt.Println(string(str))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(netCmd)
+	netCmd.AddCommand(netDiagCmd)
+
+	diagPeers = netDiagCmd.Flags().StringSlice("peer", nil, "node id of the connected peer to ping, could be specified multiple times")
+	diagNodes = netDiagCmd.Flags().StringSlice("node", nil, "node of snode://<node id>@<ip>:<port> to dial, could be specified multiple times")
+}
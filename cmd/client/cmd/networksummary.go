@@ -0,0 +1,49 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc/jsonrpc"
+
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+// networksummaryCmd represents the get network summary command
+var networksummaryCmd = &cobra.Command{
+	Use:   "networksummary",
+	Short: "get the counts of the client versions and fork ids of the connected peers",
+	Long: `For example:
+	client.exe networksummary`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jsonrpc.Dial("tcp", adminAddr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer client.Close()
+
+		var summary seele.NetworkSummary
+		if err = client.Call("admin.NetworkSummary", nil, &summary); err != nil {
+			fmt.Printf("get network summary failed %s\n", err.Error())
+			return
+		}
+
+		str, err := json.MarshalIndent(summary, "", "\t")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println(string(str))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networksummaryCmd)
+}
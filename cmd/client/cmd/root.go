@@ -9,11 +9,15 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/seeleteam/go-seele/common"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var rpcAddr string
+var adminAddr string
+var dataDir string
+var noChecksum bool
 
 // rootCmd represents the base command called without any subcommands
 var rootCmd = &cobra.Command{
@@ -37,6 +41,23 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVarP(&rpcAddr, "addr", "a", "127.0.0.1:55027", "rpc address")
+	rootCmd.PersistentFlags().StringVar(&adminAddr, "admin", "127.0.0.1:56027", "admin rpc address of the node, which serves the admin APIs only")
+	rootCmd.PersistentFlags().StringVar(&dataDir, "datadir", common.GetDefaultDataFolder(), "data folder of the client, which holds the keystore")
+	rootCmd.PersistentFlags().BoolVar(&noChecksum, "nochecksum", false, "accept the addresses that are not checksummed, i.e. in a single case")
+}
+
+// parseAddress parses the address hex, which should be checksummed unless --nochecksum is set.
+func parseAddress(hex string) (common.Address, error) {
+	address, checksummed, err := common.HexToChecksummedAddress(hex)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if !checksummed && !noChecksum {
+		return common.Address{}, fmt.Errorf("%s, use %s or --nochecksum", common.ErrAddressNotChecksummed, address.ToChecksumHex())
+	}
+
+	return address, nil
 }
 
 // initConfig reads in the config file and ENV variables if set.
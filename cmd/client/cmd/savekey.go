@@ -7,6 +7,7 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/seeleteam/go-seele/common"
 	"github.com/seeleteam/go-seele/common/keystore"
@@ -17,6 +18,9 @@ import (
 var keyStr *string
 var keyFile *string
 
+// keystoreDir is the folder to save keys in the data folder
+const keystoreDir = "keystore"
+
 // savekey represents the savekey command
 var savekey = &cobra.Command{
 	Use:   "savekey",
@@ -31,9 +35,8 @@ var savekey = &cobra.Command{
 			return
 		}
 
-		if keyFile == nil || *keyFile == "" {
-			fmt.Printf("invalid key file path\n")
-			return
+		if *keyFile == "" {
+			*keyFile = filepath.Join(dataDir, keystoreDir, crypto.MustGetAddress(privateKey).ToHex())
 		}
 
 		pass, err := common.SetPassword()
@@ -47,7 +50,12 @@ var savekey = &cobra.Command{
 			PrivateKey: privateKey,
 		}
 
-		keystore.StoreKey(*keyFile, pass, &key)
+		if err = keystore.StoreKey(*keyFile, pass, &key); err != nil {
+			fmt.Printf("saving the key failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("key saved to %s\n", *keyFile)
 	},
 }
 
@@ -57,5 +65,19 @@ func init() {
 	keyStr = savekey.Flags().StringP("key", "k", "", "private key")
 	savekey.MarkFlagRequired("key")
 
-	keyFile = savekey.Flags().StringP("file", "f", ".keystore", "key file")
+	keyFile = savekey.Flags().StringP("file", "f", "", "key file, default is <datadir>/keystore/<address>")
+}
+
+// resolveKeyFile returns the key file in the keystore folder of the data folder
+// if the specified file does not exist.
+func resolveKeyFile(file string) string {
+	if common.FileOrFolderExists(file) {
+		return file
+	}
+
+	if keystoreFile := filepath.Join(dataDir, keystoreDir, file); common.FileOrFolderExists(keystoreFile) {
+		return keystoreFile
+	}
+
+	return file
 }
@@ -6,23 +6,40 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/rpc"
 	"net/rpc/jsonrpc"
+	"os"
+	"time"
 
 	"github.com/seeleteam/go-seele/common"
 	"github.com/seeleteam/go-seele/common/keystore"
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/seele"
 	"github.com/spf13/cobra"
 )
 
 type txInfo struct {
-	amount *uint64 // amount specifies the coin amount to be transferred
-	to     *string // to is the public address of the receiver
-	from   *string // from is the key file path of the sender
+	amount   *uint64 // amount specifies the coin amount to be transferred
+	gasPrice *uint64 // gasPrice specifies the fee paid for each unit of gas used
+	gasLimit *uint64 // gasLimit specifies the maximum gas the tx could use
+	sigHash  *uint8  // sigHash specifies the sighash version to sign the tx
+	chainID  *uint64 // chainID specifies the network id the tx is signed for
+	expireAt *uint64 // expireAt specifies the block height or unix timestamp since which the tx expires
+	to       *string // to is the public address of the receiver
+	from     *string // from is the key file path of the sender
+
+	wait    *uint64        // wait specifies the number of confirmations to wait for, 0 to return once sent
+	timeout *time.Duration // timeout specifies the max time to wait for the confirmations
 }
 
+// sendtxPollInterval is the interval to poll the tx status while waiting for the confirmations.
+const sendtxPollInterval = time.Second
+
 var parameter = txInfo{}
 
 // sendtxCmd represents the sendtx command
@@ -32,8 +49,20 @@ var sendtxCmd = &cobra.Command{
 	Long: `send a tx to the miner
   For example:
     client.exe sendtx -m 0 -t 0x<public address> -f keyfile
-    client.exe sendtx -a 127.0.0.1:55027 -m 0 -t 0x<public address> -f keyfile `,
+    client.exe sendtx -a 127.0.0.1:55027 -m 0 -t 0x<public address> -f keyfile
+    client.exe sendtx -m 0 -t 0x<public address> -f keyfile --wait 6 --timeout 10m
+  With --wait, it exits with the receipt once the tx is confirmed, or exits with code 1 if not confirmed in time.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if types.SigHashVersion(*parameter.sigHash) > types.LatestSigHashVersion {
+			fmt.Printf("invalid sighash version %d, the latest version is %d\n", *parameter.sigHash, types.LatestSigHashVersion)
+			return
+		}
+
+		if *parameter.expireAt != 0 && types.SigHashVersion(*parameter.sigHash) == types.SigHashV1 {
+			fmt.Printf("the expiry is not signed in sighash version %d\n", types.SigHashV1)
+			return
+		}
+
 		client, err := jsonrpc.Dial("tcp", rpcAddr)
 		if err != nil {
 			fmt.Printf("invalid address: %s\n", err.Error())
@@ -41,7 +70,7 @@ var sendtxCmd = &cobra.Command{
 		}
 		defer client.Close()
 
-		toAddr, err := common.HexToAddress(*parameter.to)
+		toAddr, err := parseAddress(*parameter.to)
 		if err != nil {
 			fmt.Printf("invalid receiver address: %s\n", err.Error())
 			return
@@ -53,7 +82,7 @@ var sendtxCmd = &cobra.Command{
 			return
 		}
 
-		key, err := keystore.GetKey(*parameter.from, pass)
+		key, err := keystore.GetKey(resolveKeyFile(*parameter.from), pass)
 		if err != nil {
 			fmt.Printf("invalid sender key file. it should be a private key: %s\n", err.Error())
 			return
@@ -74,9 +103,23 @@ var sendtxCmd = &cobra.Command{
 
 		fmt.Printf("got the sender account nonce: %d\n", nonce)
 
+		// sign the tx for the network of the node by default to prevent replay on other networks
+		chainID := *parameter.chainID
+		if !cmd.Flags().Changed("chainid") {
+			var info seele.MinerInfo
+			if err = client.Call("seele.GetInfo", nil, &info); err != nil {
+				fmt.Printf("getting the network id failed: %s\n", err.Error())
+				return
+			}
+
+			chainID = info.NetworkID
+		}
+
 		amount := big.NewInt(0).SetUint64(*parameter.amount)
-		tx := types.NewTransaction(*from, toAddr, amount, nonce)
-		tx.Sign(key.PrivateKey)
+		gasPrice := big.NewInt(0).SetUint64(*parameter.gasPrice)
+		tx := types.NewTransaction(*from, toAddr, amount, gasPrice, *parameter.gasLimit, nonce)
+		tx.Data.ExpireAt = *parameter.expireAt
+		tx.SignWithScheme(key.PrivateKey, types.SigHashScheme{Version: types.SigHashVersion(*parameter.sigHash), ChainID: chainID})
 
 		var result bool
 		err = client.Call("seele.AddTx", &tx, &result)
@@ -86,9 +129,68 @@ var sendtxCmd = &cobra.Command{
 		}
 
 		fmt.Println("adding the tx succeeded.")
+
+		if *parameter.wait == 0 {
+			return
+		}
+
+		fmt.Printf("waiting for %d confirmations of tx %s\n", *parameter.wait, tx.Hash.ToHex())
+		status, err := waitForConfirmations(client, tx.Hash, *parameter.wait, *parameter.timeout)
+		if err != nil {
+			fmt.Printf("waiting for the tx failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		encoded, _ := json.MarshalIndent(status, "", "\t")
+		fmt.Println(string(encoded))
 	},
 }
 
+// waitForConfirmations polls the status of the tx until it is packed in the canonical chain with
+// the number of confirmations, in which the tx is waited again if reorganized out of the block.
+func waitForConfirmations(client *rpc.Client, txHash common.Hash, confirmations uint64, timeout time.Duration) (*seele.TxStatus, error) {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(sendtxPollInterval)
+	defer ticker.Stop()
+
+	var included common.Hash
+	reorged := false
+	for {
+		var status seele.TxStatus
+		if err := client.Call("seele.GetTxStatus", &txHash, &status); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case status.Status == seele.TxStatusIncluded && status.BlockHash != included:
+			if !included.IsEmpty() {
+				fmt.Printf("tx reorganized from block %s into block %s\n", included.ToHex(), status.BlockHash.ToHex())
+			} else {
+				fmt.Printf("tx included in block %d %s\n", status.BlockHeight, status.BlockHash.ToHex())
+			}
+			included = status.BlockHash
+		case status.Status != seele.TxStatusIncluded && !included.IsEmpty():
+			fmt.Printf("tx reorganized out of block %s, %s\n", included.ToHex(), status.Status)
+			included = common.EmptyHash
+			reorged = true
+		case status.Status == seele.TxStatusUnknown && !reorged:
+			// the tx is dropped from the pool, e.g. replaced or expired, while the tx reorganized
+			// out of the chain is waited till timeout in case it is packed again
+			return nil, errors.New("tx not found in the pool or the chain")
+		}
+
+		if status.Status == seele.TxStatusIncluded && status.Confirmations >= confirmations {
+			return &status, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return nil, fmt.Errorf("timeout, tx status %s with %d confirmations", status.Status, status.Confirmations)
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(sendtxCmd)
 
@@ -98,6 +200,15 @@ func init() {
 	parameter.amount = sendtxCmd.Flags().Uint64P("amount", "m", 0, "the amount of the transferred coins")
 	sendtxCmd.MarkFlagRequired("amount")
 
-	parameter.from = sendtxCmd.Flags().StringP("from", "f", "", "key file path of the sender")
+	parameter.gasPrice = sendtxCmd.Flags().Uint64("price", 1, "the fee paid for each unit of gas used")
+	parameter.gasLimit = sendtxCmd.Flags().Uint64("gas", types.TransferGas, "the maximum gas the tx could use")
+	parameter.sigHash = sendtxCmd.Flags().Uint8("sighash", uint8(types.SigHashLegacy), "the sighash version to sign the tx")
+	parameter.chainID = sendtxCmd.Flags().Uint64("chainid", 0, "the network id the tx is signed for, which is the network id of the node by default")
+	parameter.expireAt = sendtxCmd.Flags().Uint64("expire", 0, fmt.Sprintf("the block height (below %d) or unix timestamp since which the tx expires, 0 for never", types.ExpireHeightLimit))
+
+	parameter.from = sendtxCmd.Flags().StringP("from", "f", "", "key file path of the sender, or the key name in <datadir>/keystore")
 	sendtxCmd.MarkFlagRequired("from")
+
+	parameter.wait = sendtxCmd.Flags().Uint64("wait", 0, "the number of confirmations to wait for, 0 to return once sent")
+	parameter.timeout = sendtxCmd.Flags().Duration("timeout", 10*time.Minute, "the max time to wait for the confirmations, e.g. 30s or 10m")
 }
@@ -0,0 +1,49 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc/jsonrpc"
+
+	"github.com/seeleteam/go-seele/common/resource"
+	"github.com/spf13/cobra"
+)
+
+// subsystemstatsCmd represents the get subsystem stats command
+var subsystemstatsCmd = &cobra.Command{
+	Use:   "subsystemstats",
+	Short: "get the goroutines, memory and queue depths accounted to each subsystem of the node",
+	Long: `For example:
+	client.exe subsystemstats`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := jsonrpc.Dial("tcp", rpcAddr)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer client.Close()
+
+		var stats []*resource.Stats
+		if err = client.Call("debug.SubsystemStats", nil, &stats); err != nil {
+			fmt.Printf("get subsystem stats failed %s\n", err.Error())
+			return
+		}
+
+		str, err := json.MarshalIndent(stats, "", "\t")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println(string(str))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(subsystemstatsCmd)
+}
@@ -0,0 +1,192 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"path/filepath"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sweepKeysDir  *string
+	sweepTo       *string
+	sweepGasPrice *uint64
+	sweepMin      *uint64
+	sweepSigHash  *uint8
+	sweepChainID  *uint64
+	sweepInterval *time.Duration
+)
+
+// sweepReport is the totals of a sweep.
+type sweepReport struct {
+	scanned int      // number of the key files scanned
+	funded  int      // number of the keys of which the balance covers the fee and the min amount
+	swept   int      // number of the txs submitted
+	failed  int      // number of the keys failed to load, query or submit
+	amount  *big.Int // total amount transferred
+	fee     *big.Int // total max fee of the txs
+}
+
+// sweepCmd represents the sweep command
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "consolidate the balances of many keys into one account",
+	Long: `consolidate the balances of the key files in a folder into one account, in which a tx of the whole
+  balance less the fee is sent from each funded key, and the keys should share the same password.
+  For example:
+    client.exe sweep --keys ./keys --to 0x<public address>
+    client.exe sweep --keys ./keys --to 0x<public address> --min 1000 --interval 1s`,
+	Run: func(cmd *cobra.Command, args []string) {
+		to, err := parseAddress(*sweepTo)
+		if err != nil {
+			fmt.Printf("invalid receiver address: %s\n", err.Error())
+			return
+		}
+
+		if types.SigHashVersion(*sweepSigHash) > types.LatestSigHashVersion {
+			fmt.Printf("invalid sighash version %d, the latest version is %d\n", *sweepSigHash, types.LatestSigHashVersion)
+			return
+		}
+
+		files, err := ioutil.ReadDir(*sweepKeysDir)
+		if err != nil {
+			fmt.Printf("reading the keys folder failed: %s\n", err.Error())
+			return
+		}
+
+		client, err := jsonrpc.Dial("tcp", rpcAddr)
+		if err != nil {
+			fmt.Printf("invalid address: %s\n", err.Error())
+			return
+		}
+		defer client.Close()
+
+		chainID := *sweepChainID
+		if !cmd.Flags().Changed("chainid") {
+			var info seele.MinerInfo
+			if err = client.Call("seele.GetInfo", nil, &info); err != nil {
+				fmt.Printf("getting the network id failed: %s\n", err.Error())
+				return
+			}
+
+			chainID = info.NetworkID
+		}
+
+		pass, err := common.GetPassword()
+		if err != nil {
+			fmt.Printf("get password failed %s\n", err.Error())
+			return
+		}
+
+		scheme := types.SigHashScheme{Version: types.SigHashVersion(*sweepSigHash), ChainID: chainID}
+		gasPrice := new(big.Int).SetUint64(*sweepGasPrice)
+		report := &sweepReport{amount: big.NewInt(0), fee: big.NewInt(0)}
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+
+			report.scanned++
+			path := filepath.Join(*sweepKeysDir, file.Name())
+			key, err := keystore.GetKey(path, pass)
+			if err != nil {
+				fmt.Printf("%s: loading the key failed: %s\n", file.Name(), err.Error())
+				report.failed++
+				continue
+			}
+
+			tx, err := newSweepTx(client, key.PrivateKey, to, gasPrice, scheme)
+			if err != nil {
+				fmt.Printf("%s: %s\n", file.Name(), err.Error())
+				report.failed++
+				continue
+			}
+
+			if tx == nil {
+				continue
+			}
+
+			report.funded++
+
+			var result bool
+			if err = client.Call("seele.AddTx", tx, &result); err != nil || !result {
+				fmt.Printf("%s: adding the tx failed: %v\n", file.Name(), err)
+				report.failed++
+				continue
+			}
+
+			fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(tx.Data.GasLimit))
+			report.swept++
+			report.amount.Add(report.amount, tx.Data.Amount)
+			report.fee.Add(report.fee, fee)
+			fmt.Printf("%s: swept %s from %s, tx %s\n", file.Name(), tx.Data.Amount, tx.Data.From.ToChecksumHex(), tx.Hash.ToHex())
+
+			// throttle the submissions to not flood the tx pool of the node
+			time.Sleep(*sweepInterval)
+		}
+
+		fmt.Printf("scanned %d keys, %d funded, %d swept, %d failed\n", report.scanned, report.funded, report.swept, report.failed)
+		fmt.Printf("total amount %s, total fee %s, to %s\n", report.amount, report.fee, to.ToChecksumHex())
+	},
+}
+
+// newSweepTx creates the tx to transfer the whole balance of the key less the fee, which is nil
+// if the key is the receiver, or the balance less the fee is less than the min amount.
+func newSweepTx(client *rpc.Client, privKey *ecdsa.PrivateKey, to common.Address, gasPrice *big.Int, scheme types.SigHashScheme) (*types.Transaction, error) {
+	from := crypto.MustGetAddress(privKey)
+	if from.Equal(to) {
+		return nil, nil
+	}
+
+	balance := big.NewInt(0)
+	if err := client.Call("seele.GetBalance", from, balance); err != nil {
+		return nil, fmt.Errorf("getting the balance failed: %s", err.Error())
+	}
+
+	amount := new(big.Int).Sub(balance, new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(types.TransferGas)))
+	if amount.Cmp(new(big.Int).SetUint64(*sweepMin)) < 0 {
+		return nil, nil
+	}
+
+	// the nonce after the pending txs of the key, e.g. swept in the previous run but not included yet
+	var nonce uint64
+	if err := client.Call("txpool.GetPendingNonce", from, &nonce); err != nil {
+		return nil, fmt.Errorf("getting the pending nonce failed: %s", err.Error())
+	}
+
+	tx := types.NewTransaction(*from, to, amount, gasPrice, types.TransferGas, nonce)
+	tx.SignWithScheme(privKey, scheme)
+	return tx, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sweepCmd)
+
+	sweepKeysDir = sweepCmd.Flags().String("keys", "", "folder of the key files to sweep")
+	sweepCmd.MarkFlagRequired("keys")
+
+	sweepTo = sweepCmd.Flags().StringP("to", "t", "", "public address of the receiver")
+	sweepCmd.MarkFlagRequired("to")
+
+	sweepGasPrice = sweepCmd.Flags().Uint64("price", 1, "the fee paid for each unit of gas used")
+	sweepMin = sweepCmd.Flags().Uint64("min", 1, "the min amount to sweep from a key after the fee, the keys of less are skipped")
+	sweepSigHash = sweepCmd.Flags().Uint8("sighash", uint8(types.SigHashLegacy), "the sighash version to sign the txs")
+	sweepChainID = sweepCmd.Flags().Uint64("chainid", 0, "the network id the txs are signed for, which is the network id of the node by default")
+	sweepInterval = sweepCmd.Flags().Duration("interval", 200*time.Millisecond, "the interval between the tx submissions")
+}
@@ -0,0 +1,97 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc/jsonrpc"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/spf13/cobra"
+)
+
+var txpoolTxHash *string
+
+// txpoolCmd represents the tx pool command
+var txpoolCmd = &cobra.Command{
+	Use:   "txpool",
+	Short: "tx pool actions",
+	Long:  `inspect the pending and queued txs in the tx pool of the node`,
+}
+
+// txpoolContentCmd represents the tx pool content command
+var txpoolContentCmd = &cobra.Command{
+	Use:   "content",
+	Short: "get the pending and queued txs grouped by sender",
+	Long: `For example:
+	client.exe txpool content`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var result map[string]map[string][]map[string]interface{}
+		callTxPool("txpool.Content", nil, &result)
+	},
+}
+
+// txpoolStatusCmd represents the tx pool status command
+var txpoolStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "get the status of a tx in the pool, pending, queued or unknown",
+	Long: `For example:
+	client.exe txpool status --hash 0x<tx hash>`,
+	Run: func(cmd *cobra.Command, args []string) {
+		hash, err := common.HexToHash(*txpoolTxHash)
+		if err != nil {
+			fmt.Printf("invalid tx hash: %s\n", err.Error())
+			return
+		}
+
+		var result string
+		callTxPool("txpool.Status", &hash, &result)
+	},
+}
+
+// txpoolInspectCmd represents the tx pool inspect command
+var txpoolInspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "get the numbers of the pending and queued txs and their senders",
+	Long: `For example:
+	client.exe txpool inspect`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var result map[string]interface{}
+		callTxPool("txpool.Inspect", nil, &result)
+	},
+}
+
+// callTxPool calls the tx pool method and prints the result in json.
+func callTxPool(method string, input interface{}, result interface{}) {
+	client, err := jsonrpc.Dial("tcp", rpcAddr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer client.Close()
+
+	if err = client.Call(method, input, result); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	encoded, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(string(encoded))
+}
+
+func init() {
+	rootCmd.AddCommand(txpoolCmd)
+	txpoolCmd.AddCommand(txpoolContentCmd, txpoolStatusCmd, txpoolInspectCmd)
+
+	txpoolTxHash = txpoolStatusCmd.Flags().String("hash", "", "hash of the tx")
+	txpoolStatusCmd.MarkFlagRequired("hash")
+}
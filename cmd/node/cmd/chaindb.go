@@ -0,0 +1,89 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/seeleteam/go-seele/common/flock"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/node"
+	"github.com/seeleteam/go-seele/seele"
+)
+
+// loadOfflineConfig loads the node config for the commands operating on the data folder
+// of a stopped node, in which the dataDir overrides the DataDir in config file if specified.
+func loadOfflineConfig(configFile, genesisFile, dataDir string) (*node.Config, error) {
+	nCfg, err := LoadConfigFromFile(configFile, genesisFile, "")
+	if err != nil {
+		return nil, fmt.Errorf("reading the config file failed: %s", err)
+	}
+
+	if dataDir != "" {
+		nCfg.DataDir = dataDir
+	}
+
+	return nCfg, nil
+}
+
+// chainDatabases is the blockchain and account state DBs of a stopped node, the data
+// folder of which is locked so that the node could not start on it meanwhile.
+type chainDatabases struct {
+	lock           *flock.Lock
+	chainDB        database.Database
+	accountStateDB database.Database
+}
+
+func openChainDatabases(dataDir string) (*chainDatabases, error) {
+	lock, err := flock.New(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	chainDB, err := leveldb.NewLevelDB(filepath.Join(dataDir, seele.BlockChainDir))
+	if err != nil {
+		lock.Release()
+		return nil, fmt.Errorf("opening the blockchain DB failed: %s", err)
+	}
+
+	accountStateDB, err := leveldb.NewLevelDB(filepath.Join(dataDir, seele.AccountStateDir))
+	if err != nil {
+		chainDB.Close()
+		lock.Release()
+		return nil, fmt.Errorf("opening the account state DB failed: %s", err)
+	}
+
+	return &chainDatabases{lock, chainDB, accountStateDB}, nil
+}
+
+// store returns the blockchain store of the opened blockchain DB.
+func (dbs *chainDatabases) store() store.BlockchainStore {
+	return store.NewBlockchainDatabase(dbs.chainDB)
+}
+
+// blockchain loads the blockchain, which requires the genesis block initialized.
+func (dbs *chainDatabases) blockchain() (*core.Blockchain, error) {
+	if _, err := dbs.store().GetBlockHash(0); err != nil {
+		return nil, fmt.Errorf("genesis block not found, please initialize the data folder with the init command: %s", err)
+	}
+
+	chain, err := core.NewBlockchain(dbs.store(), dbs.accountStateDB)
+	if err != nil {
+		return nil, fmt.Errorf("loading the blockchain failed: %s", err)
+	}
+
+	return chain, nil
+}
+
+func (dbs *chainDatabases) close() {
+	dbs.accountStateDB.Close()
+	dbs.chainDB.Close()
+	dbs.lock.Release()
+}
@@ -10,12 +10,18 @@ import (
 	"io/ioutil"
 	"math/big"
 	"path/filepath"
+	"time"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/common/resource"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/crypto"
 	"github.com/seeleteam/go-seele/node"
 	"github.com/seeleteam/go-seele/p2p"
 	"github.com/seeleteam/go-seele/p2p/discovery"
+	"github.com/seeleteam/go-seele/seele"
 )
 
 // Config aggregates all configs exposed to users
@@ -33,18 +39,81 @@ type Config struct {
 	// JSON API address
 	RPCAddr string
 
+	// JSON API address of the admin APIs, e.g. admin and scheduler, which should be a loopback address, empty to disable
+	AdminAddr string
+
+	// proof-of-work stamp difficulty (leading zero bits) required by the JSON API for tx submission, 0 to disable
+	RPCStampBits uint
+
+	// deadline in seconds of long-running RPC requests on both JSON API and http server, 0 for no deadline
+	RPCRequestTimeout int64
+
+	// number of confirmations of the historical data of which the responses are cached by http server, 120 if 0
+	RPCCacheConfirmations uint64
+
 	// ServerPrivateKey private key for p2p module, do not use it as any accounts
 	ServerPrivateKey string
 
+	// ServerKeyStore loads the private key for p2p module from a key store (file, keyring or pkcs11) instead of ServerPrivateKey
+	ServerKeyStore *keystore.Config
+
 	// network id, not used now. @TODO maybe be removed or just use Version
 	NetworkID uint64
 
 	// capacity of the transaction pool
 	Capacity uint
 
+	// maximum number of the txs of a sender in the transaction pool, 0 for no limit
+	AccountCapacity uint
+
+	// maximum time in seconds of the non-local txs in the transaction pool, 0 for the default 3 hours
+	TxLifetime uint64
+
+	// minimum gas price of the txs accepted by the transaction pool, 0 to accept any
+	MinGasPrice uint64
+
+	// minimum fee (gas price * intrinsic gas) per byte of the txs accepted by the transaction pool,
+	// 0 to accept any, which is adjustable at runtime via admin.SetTxFeeFloor
+	MinFeePerByte uint64
+
+	// minimum fee per byte of the zero-amount txs with payload, e.g. data-only spam, 0 for MinFeePerByte
+	MinPayloadFeePerByte uint64
+
+	// minimum percentage of the gas price increase to replace a pending tx of the same nonce, 0 for the default 10%
+	PriceBump uint
+
+	// maximum number of the recently received txs whose validation verdicts are cached to skip
+	// the duplicates, about 100 bytes each, 0 for the default 16384 txs
+	SeenTxCacheSize int
+
+	// time in seconds to keep the validation verdicts of the received txs, 0 for the default 10 minutes
+	SeenTxCacheTTL uint64
+
+	// disable the journal of the local txs, which are replayed into the transaction pool on restart
+	DisableTxJournal bool
+
 	// coinbase used by the miner
 	Coinbase string
 
+	// extra data embedded in the reward tx of the mined blocks, e.g. the pool tag or version string, at most 32 bytes
+	CoinbaseExtra string
+
+	// whether to pin each mining thread to a cpu core, only supported on Linux
+	MinerAffinity bool
+
+	// interval in seconds to rebuild the mining task with the latest txs and timestamp, 0 to rebuild only for the higher-fee txs and the new head
+	MinerRecommit uint64
+
+	// number of blocks behind the sync target to pause the mining until the sync completes, 0 for the default 16 blocks
+	MinerSyncPauseBlocks uint64
+
+	// path of the Go plugin (built with -buildmode=plugin) that exports an Engine variable implementing miner.Engine,
+	// which seals the blocks instead of the mining threads, e.g. with the GPU or remote workers, empty to disable
+	MinerEnginePlugin string
+
+	// escrow account to pay the mining rewards to instead of the coinbase, which is released by the operator and auditor together
+	Escrow EscrowConfig
+
 	// static nodes which will be connected to find more nodes when the node starts
 	StaticNodes []string
 
@@ -54,18 +123,72 @@ type Config struct {
 	// If IsDebug is true, the log level will be DebugLevel, otherwise it is InfoLevel
 	IsDebug bool
 
+	// If DebugStateDiff is true, the accounts that differ from the expected state root will be logged on block state hash mismatch, which is expensive
+	DebugStateDiff bool
+
+	// MaxReorgDepth is the maximum depth of the chain reorganization accepted automatically, 0 for unlimited.
+	// Deeper forks are refused and should be switched manually via debug.SetHead
+	MaxReorgDepth uint64
+
+	// FinalityDepth is the number of confirmations after which the canonical blocks are final, 0 to disable.
+	// The blocks that fork the canonical chain before the finalized block are rejected
+	FinalityDepth uint64
+
+	// CheckpointAuthority is the address in hex to sign the checkpoints that finalize the blocks, e.g. for the
+	// private chains, empty to disable. The checkpoints are added via admin.AddCheckpoint
+	CheckpointAuthority string
+
+	// MinSyncSubnets is the number of distinct subnets (/16 for IPv4) of the peers that should claim a chain head,
+	// i.e. the same or a higher total difficulty, before syncing to it, which mitigates the eclipse attacks. 0 to disable
+	MinSyncSubnets int
+
+	// SigHashForks are the block heights to activate the tx sighash versions, e.g. [{"Version": 1, "Height": 100000}].
+	// All versions are activated since genesis if empty
+	SigHashForks []types.SigHashFork
+
+	// If LogIndex is true, the contract log indices will be built in background for fast log queries over large height ranges
+	LogIndex bool
+
+	// ExecutionPlugins are the paths of Go plugins (built with -buildmode=plugin) that export an Observer variable implementing core.ExecutionObserver
+	ExecutionPlugins []string
+
+	// NetworkReportURL is the crawler endpoint to post the counts of the peer client versions and fork ids hourly, empty to disable.
+	// No node ids or addresses of the peers are reported.
+	NetworkReportURL string
+
 	// If PrintLog is true, all logs will be printed in the console, otherwise they will be stored in the file.
 	PrintLog bool
 
 	// http server config info
 	HttpServer HttpServer
-}
 
-// GenesisInfo genesis info for generate genesis block, it could be used for initialize account balance
-type GenesisInfo struct {
-	// accounts info for genesis block used for test
-	// map key is account address -> value is account balance
-	Accounts map[string]int64
+	// additional http rpc listeners, each with its own address, TLS, namespaces, auth and rate limit
+	HTTPListeners []node.HTTPListenerConfig
+
+	// snapshot publisher config info
+	Snapshot SnapshotConfig
+
+	// scheduled backup config info
+	Backup BackupConfig
+
+	// stratum server config info for the mining pools
+	Stratum StratumConfig
+
+	// bft validator config info, only for the chains of the bft consensus
+	Bft BftConfig
+
+	// signer process config info, nil to keep the signing keys in the node process
+	SignerProcess *SignerProcessConfig
+
+	// maximum value (amount plus max fee) of the txs signed by the node for each address within the spending window,
+	// e.g. {"0x...": 1000000}, the addresses not listed are not limited
+	SpendingLimits map[string]*big.Int
+
+	// time window in seconds of the spending limits, 0 for the default 24 hours
+	SpendingWindow uint64
+
+	// soft limits of the subsystems (p2p, pool, rpc, sync) by name, which throttle themselves once reached
+	SubsystemLimits map[string]resource.Limits
 }
 
 // HttpServer config for http server
@@ -80,6 +203,81 @@ type HttpServer struct {
 
 	// HTTPHostFilter is the whitelist of hostnames which are allowed on incoming requests.
 	HTTPWhiteHost []string
+
+	// StampBits is the proof-of-work stamp difficulty (leading zero bits) required for tx submission, 0 to disable.
+	StampBits uint
+
+	// CacheSize is the max bytes of the cached responses of the historical data, 0 to disable.
+	CacheSize int
+}
+
+// BftConfig config for the local validator of the bft consensus
+type BftConfig struct {
+	// PrivateKey is the private key of the local validator, empty if not a validator
+	PrivateKey string
+
+	// KeyStore loads the private key of the local validator from a key store instead of PrivateKey
+	KeyStore *keystore.Config
+}
+
+// SnapshotConfig config for publishing the signed chain snapshots
+type SnapshotConfig struct {
+	// PublishDir is the folder to write the latest snapshot archive, empty to disable
+	PublishDir string
+
+	// PublishAddr is the HTTP address to serve the latest snapshot archive, empty to disable
+	PublishAddr string
+
+	// PublishInterval is the interval in seconds to publish a new snapshot
+	PublishInterval int64
+
+	// PrivateKey is the private key to sign the published snapshots
+	PrivateKey string
+
+	// KeyStore loads the private key to sign the published snapshots from a key store instead of PrivateKey
+	KeyStore *keystore.Config
+}
+
+// BackupConfig config for backing up the chain databases on schedule
+type BackupConfig struct {
+	// Dir is the folder to write the scheduled backups, empty to disable
+	Dir string
+
+	// Interval is the interval in seconds to take a new backup
+	Interval int64
+
+	// Keep is the number of the latest backups to keep, 0 to keep all
+	Keep int
+}
+
+// StratumConfig config for the stratum server, which publishes the mining jobs to the pool workers
+type StratumConfig struct {
+	// Addr is the TCP address of the stratum server, empty to disable
+	Addr string
+
+	// ShareDifficulty is the difficulty of the shares submitted by the workers, which is required if Addr is set
+	ShareDifficulty uint64
+}
+
+// SignerProcessConfig config for the signer process, which holds the private keys to sign the
+// scheduled txs in a separate OS process and serves the node over the external signer protocol,
+// so that no key material is loaded in the network-facing node process
+type SignerProcessConfig struct {
+	// KeyStores are the keys loaded in the signer process
+	KeyStores []*keystore.Config
+
+	// User is the OS user to run the signer process as, e.g. the only user that could read the
+	// key files, empty for the user of the node process. Only supported on Linux
+	User string
+}
+
+// EscrowConfig config for the escrow account to pay the mining rewards to
+type EscrowConfig struct {
+	// Operator is the address of the operator, who signs the release txs
+	Operator string
+
+	// Auditor is the address of the auditor, who co-signs the release txs
+	Auditor string
 }
 
 // GetConfigFromFile unmarshals the config from the given file
@@ -94,74 +292,183 @@ func GetConfigFromFile(filepath string) (Config, error) {
 	return config, err
 }
 
-// GetGenesisInfoFromFile get genesis info from a specific file
-func GetGenesisInfoFromFile(filepath string) (GenesisInfo, error) {
-	var info GenesisInfo
-	buff, err := ioutil.ReadFile(filepath)
+// GetGenesisAccountsFromFile get genesis accounts from a specific file
+func GetGenesisAccountsFromFile(filepath string) (map[common.Address]*big.Int, error) {
+	info, err := core.GetGenesisInfoFromFile(filepath)
 	if err != nil {
-		return info, err
+		return nil, err
 	}
 
-	err = json.Unmarshal(buff, &info)
-	return info, err
+	return info.GetAccounts()
 }
 
-// GetGenesisAccountsFromFile get genesis accounts from a specific file
-func GetGenesisAccountsFromFile(filepath string) (map[common.Address]*big.Int, error) {
-	info, err := GetGenesisInfoFromFile(filepath)
+// LoadConfigFromFile gets node config from the given file. If the network is specified,
+// its preset is applied, while the genesis config file still takes precedence over the preset genesis.
+func LoadConfigFromFile(configFile string, genesisConfigFile string, network string) (*node.Config, error) {
+	config, err := GetConfigFromFile(configFile)
 	if err != nil {
 		return nil, err
 	}
 
-	accounts := make(map[common.Address]*big.Int)
-	for k, v := range info.Accounts {
-		addr, err := common.HexToAddress(k)
-		if err != nil {
+	var preset *NetworkPreset
+	if network != "" {
+		if preset, err = GetNetworkPreset(network); err != nil {
 			return nil, err
 		}
 
-		balance := big.NewInt(v)
-		accounts[addr] = balance
-	}
-
-	return accounts, nil
-}
-
-// LoadConfigFromFile gets node config from the given file
-func LoadConfigFromFile(configFile string, genesisConfigFile string) (*node.Config, error) {
-	config, err := GetConfigFromFile(configFile)
-	if err != nil {
-		return nil, err
+		preset.apply(&config)
 	}
 
 	nodeConfig := new(node.Config)
 	nodeConfig.Name = config.Name
 	nodeConfig.Version = config.Version
 	nodeConfig.RPCAddr = config.RPCAddr
+	nodeConfig.AdminAddr = config.AdminAddr
 	nodeConfig.HTTPAddr = config.HttpServer.HTTPAddr
 	nodeConfig.HTTPCors = config.HttpServer.HTTPCors
 	nodeConfig.HTTPWhiteHost = config.HttpServer.HTTPWhiteHost
+	nodeConfig.RPCStampBits = config.RPCStampBits
+	nodeConfig.HTTPStampBits = config.HttpServer.StampBits
+	nodeConfig.HTTPCacheSize = config.HttpServer.CacheSize
+	nodeConfig.RPCCacheConfirmations = config.RPCCacheConfirmations
+	nodeConfig.HTTPListeners = config.HTTPListeners
+	nodeConfig.SubsystemLimits = config.SubsystemLimits
+	nodeConfig.RPCRequestTimeout = time.Duration(config.RPCRequestTimeout) * time.Second
 
 	nodeConfig.P2P, err = GetP2pConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
+	var genesis *core.GenesisInfo
 	if genesisConfigFile != "" {
-		accounts, err := GetGenesisAccountsFromFile(genesisConfigFile)
+		info, err := core.GetGenesisInfoFromFile(genesisConfigFile)
 		if err != nil {
 			return nil, err
 		}
-		nodeConfig.SeeleConfig.GenesisAccounts = accounts
+		genesis = &info
+	} else if preset != nil {
+		genesis = &preset.Genesis
+	}
+
+	if genesis != nil {
+		if nodeConfig.SeeleConfig.GenesisAccounts, err = genesis.GetAccounts(); err != nil {
+			return nil, err
+		}
+		nodeConfig.SeeleConfig.GenesisDifficulty = genesis.InitialDifficulty
+		nodeConfig.SeeleConfig.ChainConf = genesis.Chain
+		nodeConfig.SeeleConfig.DifficultyConf = genesis.Difficulty
+		nodeConfig.SeeleConfig.RewardConf = genesis.Reward
+		nodeConfig.SeeleConfig.PowAlgorithm = genesis.PowAlgorithm
+		nodeConfig.SeeleConfig.MemHashConf = genesis.MemHash
+		nodeConfig.SeeleConfig.BftConf = genesis.Bft
 	}
 
 	nodeConfig.SeeleConfig.Coinbase = common.HexMustToAddres(config.Coinbase)
+	nodeConfig.SeeleConfig.CoinbaseExtra = []byte(config.CoinbaseExtra)
+	nodeConfig.SeeleConfig.MinerAffinity = config.MinerAffinity
+	nodeConfig.SeeleConfig.MinerEnginePlugin = config.MinerEnginePlugin
+	nodeConfig.SeeleConfig.MinerRecommit = time.Duration(config.MinerRecommit) * time.Second
+	nodeConfig.SeeleConfig.MinerSyncPauseBlocks = config.MinerSyncPauseBlocks
+	if config.Escrow.Operator != "" || config.Escrow.Auditor != "" {
+		nodeConfig.SeeleConfig.Escrow = &types.EscrowAccount{
+			Operator: common.HexMustToAddres(config.Escrow.Operator),
+			Auditor:  common.HexMustToAddres(config.Escrow.Auditor),
+		}
+	}
 	nodeConfig.SeeleConfig.NetworkID = config.NetworkID
+	if genesis != nil && genesis.ChainID != 0 {
+		nodeConfig.SeeleConfig.NetworkID = genesis.ChainID
+	}
 	nodeConfig.SeeleConfig.TxConf.Capacity = config.Capacity
+	nodeConfig.SeeleConfig.TxConf.AccountCapacity = config.AccountCapacity
+	nodeConfig.SeeleConfig.TxConf.Lifetime = time.Duration(config.TxLifetime) * time.Second
+	nodeConfig.SeeleConfig.TxConf.MinGasPrice = new(big.Int).SetUint64(config.MinGasPrice)
+	nodeConfig.SeeleConfig.TxConf.PriceBump = config.PriceBump
+	nodeConfig.SeeleConfig.TxConf.MinFeePerByte = new(big.Int).SetUint64(config.MinFeePerByte)
+	if config.MinPayloadFeePerByte > 0 {
+		nodeConfig.SeeleConfig.TxConf.MinPayloadFeePerByte = new(big.Int).SetUint64(config.MinPayloadFeePerByte)
+	}
+	nodeConfig.SeeleConfig.TxConf.SeenCacheSize = config.SeenTxCacheSize
+	nodeConfig.SeeleConfig.TxConf.SeenCacheTTL = time.Duration(config.SeenTxCacheTTL) * time.Second
+	nodeConfig.SeeleConfig.DebugStateDiff = config.DebugStateDiff
+	nodeConfig.SeeleConfig.MaxReorgDepth = config.MaxReorgDepth
+	nodeConfig.SeeleConfig.FinalityDepth = config.FinalityDepth
+	if config.CheckpointAuthority != "" {
+		authority, err := common.HexToAddress(config.CheckpointAuthority)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeConfig.SeeleConfig.CheckpointAuthority = &authority
+	}
+
+	nodeConfig.SeeleConfig.MinSyncSubnets = config.MinSyncSubnets
+	nodeConfig.SeeleConfig.SigHashForks = config.SigHashForks
+	nodeConfig.SeeleConfig.LogIndex = config.LogIndex
+	nodeConfig.SeeleConfig.ExecutionPlugins = config.ExecutionPlugins
+	nodeConfig.SeeleConfig.ClientVersion = seele.ClientVersion(config.Version)
+	nodeConfig.SeeleConfig.NetworkReportURL = config.NetworkReportURL
+	nodeConfig.SeeleConfig.SpendingWindow = time.Duration(config.SpendingWindow) * time.Second
+	if len(config.SpendingLimits) > 0 {
+		nodeConfig.SeeleConfig.SpendingLimits = make(map[common.Address]*big.Int)
+		for addr, limit := range config.SpendingLimits {
+			address, err := common.HexToAddress(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			nodeConfig.SeeleConfig.SpendingLimits[address] = limit
+		}
+	}
+
+	if config.Snapshot.KeyStore != nil {
+		key, err := keystore.LoadKey(config.Snapshot.KeyStore)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeConfig.SeeleConfig.SnapshotConf.PrivateKey = key.PrivateKey
+	} else if config.Snapshot.PrivateKey != "" {
+		if nodeConfig.SeeleConfig.SnapshotConf.PrivateKey, err = crypto.LoadECDSAFromString(config.Snapshot.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	if nodeConfig.SeeleConfig.SnapshotConf.PrivateKey != nil {
+		nodeConfig.SeeleConfig.SnapshotConf.PublishDir = config.Snapshot.PublishDir
+		nodeConfig.SeeleConfig.SnapshotConf.PublishAddr = config.Snapshot.PublishAddr
+		nodeConfig.SeeleConfig.SnapshotConf.PublishInterval = time.Duration(config.Snapshot.PublishInterval) * time.Second
+	}
+
+	if config.Bft.KeyStore != nil {
+		key, err := keystore.LoadKey(config.Bft.KeyStore)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeConfig.SeeleConfig.BftKey = key.PrivateKey
+	} else if config.Bft.PrivateKey != "" {
+		if nodeConfig.SeeleConfig.BftKey, err = crypto.LoadECDSAFromString(config.Bft.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	nodeConfig.SeeleConfig.BackupConf.Dir = config.Backup.Dir
+	nodeConfig.SeeleConfig.BackupConf.Interval = time.Duration(config.Backup.Interval) * time.Second
+	nodeConfig.SeeleConfig.BackupConf.Keep = config.Backup.Keep
+
+	if config.Stratum.Addr != "" {
+		nodeConfig.SeeleConfig.StratumConf.Addr = config.Stratum.Addr
+		nodeConfig.SeeleConfig.StratumConf.ShareDifficulty = new(big.Int).SetUint64(config.Stratum.ShareDifficulty)
+	}
 
 	common.PrintLog = config.PrintLog
 	common.IsDebug = config.IsDebug
 	nodeConfig.DataDir = filepath.Join(common.GetDefaultDataFolder(), config.DataDir)
+	if !config.DisableTxJournal {
+		nodeConfig.SeeleConfig.TxConf.Journal = filepath.Join(nodeConfig.DataDir, seele.TxJournalFile)
+	}
 	return nodeConfig, nil
 }
 
@@ -169,23 +476,37 @@ func LoadConfigFromFile(configFile string, genesisConfigFile string) (*node.Conf
 func GetP2pConfig(config Config) (p2p.Config, error) {
 	p2pConfig := p2p.Config{}
 
-	if len(config.StaticNodes) != 0 {
-		for _, id := range config.StaticNodes {
-			n, err := discovery.NewNodeFromString(id)
-			if err != nil {
-				return p2p.Config{}, err
-			}
+	if config.ServerKeyStore != nil {
+		key, err := keystore.LoadKey(config.ServerKeyStore)
+		if err != nil {
+			return p2pConfig, err
+		}
 
-			p2pConfig.StaticNodes = append(p2pConfig.StaticNodes, n)
+		p2pConfig.PrivateKey = key.PrivateKey
+	} else {
+		key, err := crypto.LoadECDSAFromString(config.ServerPrivateKey)
+		if err != nil {
+			return p2pConfig, err
 		}
+
+		p2pConfig.PrivateKey = key
 	}
 
-	key, err := crypto.LoadECDSAFromString(config.ServerPrivateKey)
-	if err != nil {
-		return p2pConfig, err
+	self := crypto.MustGetAddress(p2pConfig.PrivateKey)
+	for _, id := range config.StaticNodes {
+		n, err := discovery.NewNodeFromString(id)
+		if err != nil {
+			return p2p.Config{}, err
+		}
+
+		// the node itself may be one of the bootnodes of the network preset
+		if n.ID.Equal(*self) {
+			continue
+		}
+
+		p2pConfig.StaticNodes = append(p2pConfig.StaticNodes, n)
 	}
 
-	p2pConfig.PrivateKey = key
 	p2pConfig.ListenAddr = config.ListenAddr
 	return p2pConfig, nil
 }
@@ -0,0 +1,173 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/seele/firehose"
+	"github.com/seeleteam/go-seele/seele/logindex"
+	"github.com/spf13/cobra"
+)
+
+var dbConfigFile *string
+var dbDataDir *string
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "maintain the chain database of a stopped node",
+	Long:  `use "node db help [<command>]" for detailed usage`,
+}
+
+// dbCompactCmd represents the db compact command
+var dbCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "compact the blockchain and account state DBs to reclaim the space of deleted entries",
+	Long: `For example:
+			node.exe db compact -c cmd\node.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbs, err := openOfflineDatabases()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		if err = compactDatabase("blockchain DB", dbs.chainDB); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		if err = compactDatabase("account state DB", dbs.accountStateDB); err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Println("database compacted")
+	},
+}
+
+// dbReindexCmd represents the db reindex command
+var dbReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "rebuild the tx and log indices from the canonical blocks",
+	Long: `rebuild the tx lookup indices and the contract log indices from the canonical blocks,
+which removes the stale indices of the forks. The rebuild could be interrupted and resumed later.
+  For example:
+			node.exe db reindex -c cmd\node.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbs, err := openOfflineDatabases()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		chain, err := dbs.blockchain()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// stop on interrupt, and the rebuild is resumed next time
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+		go func() {
+			select {
+			case <-interrupt:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		txBar := newProgressBar("tx indices")
+		err = store.RebuildTxIndices(ctx, dbs.chainDB, txBar.update)
+		txBar.done()
+		if err != nil {
+			fmt.Printf("rebuilding the tx indices failed: %s\n", err.Error())
+			return
+		}
+
+		indexer, err := logindex.NewIndexer(firehose.New(chain, dbs.accountStateDB), dbs.chainDB, log.GetLogger("logindex", common.PrintLog))
+		if err != nil {
+			fmt.Printf("loading the log indexer failed: %s\n", err.Error())
+			return
+		}
+
+		// the log indices are removed down to genesis, and then rebuilt up to the HEAD block
+		block, _ := chain.CurrentBlock()
+		head, start := block.Header.Height, indexer.Progress().Height
+		unindexBar, indexBar := newProgressBar("removing log indices"), newProgressBar("log indices")
+		unindexing := start > 0
+		indexer.SetProgressHandler(func(progress firehose.Cursor) {
+			if unindexing && progress.Height == 0 {
+				unindexBar.update(start, start)
+				unindexBar.done()
+				unindexing = false
+			} else if unindexing {
+				unindexBar.update(start-progress.Height, start)
+			} else {
+				indexBar.update(progress.Height, head)
+			}
+		})
+
+		err = indexer.Reindex(ctx)
+		indexBar.done()
+		if err != nil {
+			fmt.Printf("rebuilding the log indices failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("indices rebuilt till height %d\n", indexer.Progress().Height)
+	},
+}
+
+func openOfflineDatabases() (*chainDatabases, error) {
+	nCfg, err := loadOfflineConfig(*dbConfigFile, "", *dbDataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// make sure the node is not running on the same data folder
+	return openChainDatabases(nCfg.DataDir)
+}
+
+func compactDatabase(name string, db database.Database) error {
+	compacter, ok := db.(database.Compacter)
+	if !ok {
+		return fmt.Errorf("the %s does not support compaction", name)
+	}
+
+	fmt.Printf("compacting the %s...\n", name)
+	if err := compacter.Compact(); err != nil {
+		return fmt.Errorf("compacting the %s failed: %s", name, err)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbCompactCmd)
+	dbCmd.AddCommand(dbReindexCmd)
+
+	dbConfigFile = dbCmd.PersistentFlags().StringP("config", "c", "", "seele node config file (required)")
+	dbCmd.MarkPersistentFlagRequired("config")
+
+	dbDataDir = dbCmd.PersistentFlags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file")
+}
@@ -0,0 +1,63 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/seeleteam/go-seele/core"
+	"github.com/spf13/cobra"
+)
+
+var initConfigFile *string
+var initDataDir *string
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init <genesis.json>",
+	Short: "create the data folder and write the genesis block",
+	Long: `For example:
+			node.exe init cmd\genesis.json -c cmd\node.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := loadOfflineConfig(*initConfigFile, args[0], *initDataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		dbs, err := openChainDatabases(nCfg.DataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		bcStore := dbs.store()
+		genesis := core.NewGenesis(nCfg.SeeleConfig.GenesisAccounts, nCfg.SeeleConfig.GenesisDifficulty)
+		if err = genesis.InitializeAndValidate(bcStore, dbs.accountStateDB); err != nil {
+			fmt.Printf("initializing the genesis block failed: %s\n", err.Error())
+			return
+		}
+
+		genesisHash, err := bcStore.GetBlockHash(0)
+		if err != nil {
+			fmt.Printf("getting the genesis block hash failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("data folder %s initialized, genesis hash: %s\n", nCfg.DataDir, genesisHash.ToHex())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initConfigFile = initCmd.Flags().StringP("config", "c", "", "seele node config file (required)")
+	initCmd.MarkFlagRequired("config")
+
+	initDataDir = initCmd.Flags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file")
+}
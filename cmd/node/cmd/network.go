@@ -0,0 +1,128 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/seeleteam/go-seele/core"
+)
+
+// NetworkPreset is a named network which selects the genesis accounts, bootnodes,
+// network id and default listen addresses of the node.
+type NetworkPreset struct {
+	// NetworkID is the id exchanged in the handshake, peers of other networks are rejected
+	NetworkID uint64
+
+	// Genesis is the genesis info of the network, overridden by a custom genesis file
+	Genesis core.GenesisInfo
+
+	// Bootnodes are appended to the static nodes of the config file
+	Bootnodes []string
+
+	// default addresses used when they are not specified in the config file
+	ListenAddr string
+	RPCAddr    string
+	AdminAddr  string
+	HTTPAddr   string
+}
+
+var networkPresets = map[string]*NetworkPreset{
+	// no public bootnodes yet, specify them via StaticNodes in the config file
+	"mainnet": {
+		NetworkID:  1,
+		Genesis:    core.GenesisInfo{Accounts: map[string]int64{}},
+		ListenAddr: "0.0.0.0:8057",
+		RPCAddr:    "127.0.0.1:8027",
+		AdminAddr:  "127.0.0.1:8047",
+		HTTPAddr:   "127.0.0.1:8037",
+	},
+	"testnet": {
+		NetworkID:  2,
+		Genesis:    core.GenesisInfo{Accounts: map[string]int64{}},
+		ListenAddr: "0.0.0.0:18057",
+		RPCAddr:    "127.0.0.1:18027",
+		AdminAddr:  "127.0.0.1:18047",
+		HTTPAddr:   "127.0.0.1:18037",
+	},
+	// local network of the sample configs in cmd/node/config
+	"devnet": {
+		NetworkID: 3,
+		Genesis: core.GenesisInfo{
+			Accounts: map[string]int64{
+				"0x55489251c9d3b394e430d50cb20e271c8560d39b02dfb7efe9610ff51fa4affcf663ad4337117263f64b24149fed5c4fe95d5fb3a00d45a32e6433a200fa0301": 10,
+				"0x2d7d61c30a2f62cacc84bdd17759da7498ba7f0b9081f501a3a4c37c492eb493a0dcd59caaa7284bf38500d4d896cbb0caea504e5b9b3d1802433d06465a0a23": 20,
+			},
+		},
+		Bootnodes: []string{
+			"snode://23ddfb54a488f906cdb9cbd257eac5663a4c74ba25619bb902651602a4491be4ce437907fcc567b31be6746a014931f4670ac116c0010e5beb28b0dce2c6eaad@127.0.0.1:39007",
+		},
+		ListenAddr: "0.0.0.0:39007",
+		RPCAddr:    "127.0.0.1:55027",
+		AdminAddr:  "127.0.0.1:56027",
+		HTTPAddr:   "127.0.0.1:65027",
+	},
+}
+
+// GetNetworkPreset returns the network preset of the specified name.
+func GetNetworkPreset(name string) (*NetworkPreset, error) {
+	preset, ok := networkPresets[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %s, available networks: %s", name, strings.Join(networkNames(), ", "))
+	}
+
+	return preset, nil
+}
+
+func networkNames() []string {
+	names := make([]string, 0, len(networkPresets))
+	for name := range networkPresets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// apply applies the preset to the specified config. The network id is always
+// replaced, while the addresses are only filled if not specified in the config.
+func (preset *NetworkPreset) apply(config *Config) {
+	config.NetworkID = preset.NetworkID
+
+	for _, bootnode := range preset.Bootnodes {
+		if !containsString(config.StaticNodes, bootnode) {
+			config.StaticNodes = append(config.StaticNodes, bootnode)
+		}
+	}
+
+	if config.ListenAddr == "" {
+		config.ListenAddr = preset.ListenAddr
+	}
+
+	if config.RPCAddr == "" {
+		config.RPCAddr = preset.RPCAddr
+	}
+
+	if config.AdminAddr == "" {
+		config.AdminAddr = preset.AdminAddr
+	}
+
+	if config.HttpServer.HTTPAddr == "" {
+		config.HttpServer.HTTPAddr = preset.HTTPAddr
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,54 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// progressBarWidth is the number of characters of the progress bar.
+	progressBarWidth = 40
+
+	// progressInterval is the minimum interval to redraw the progress bar.
+	progressInterval = 200 * time.Millisecond
+)
+
+// progressBar prints the progress of a long-running offline operation in a single line.
+type progressBar struct {
+	name    string
+	drawnAt time.Time
+}
+
+func newProgressBar(name string) *progressBar {
+	return &progressBar{name: name}
+}
+
+// update redraws the bar with the specified progress, at most every progressInterval
+// unless the operation is done.
+func (bar *progressBar) update(current, total uint64) {
+	if current < total && time.Since(bar.drawnAt) < progressInterval {
+		return
+	}
+
+	bar.drawnAt = time.Now()
+
+	ratio := float64(1)
+	if total > 0 {
+		ratio = float64(current) / float64(total)
+	}
+
+	filled := int(ratio * progressBarWidth)
+	fmt.Printf("\r%s [%s%s] %3d%% (%d/%d)", bar.name, strings.Repeat("=", filled),
+		strings.Repeat(" ", progressBarWidth-filled), int(ratio*100), current, total)
+}
+
+// done ends the line of the progress bar.
+func (bar *progressBar) done() {
+	fmt.Println()
+}
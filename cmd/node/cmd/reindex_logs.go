@@ -0,0 +1,71 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/seele/firehose"
+	"github.com/seeleteam/go-seele/seele/logindex"
+	"github.com/spf13/cobra"
+)
+
+var reindexConfigFile *string
+var reindexDataDir *string
+
+// reindexLogsCmd represents the reindexlogs command
+var reindexLogsCmd = &cobra.Command{
+	Use:   "reindexlogs",
+	Short: "rebuild the contract log indices of a stopped node",
+	Long: `For example:
+			node.exe reindexlogs -c cmd\node.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := loadOfflineConfig(*reindexConfigFile, "", *reindexDataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		// make sure the node is not running on the same data folder
+		dbs, err := openChainDatabases(nCfg.DataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		chain, err := dbs.blockchain()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		indexer, err := logindex.NewIndexer(firehose.New(chain, dbs.accountStateDB), dbs.chainDB, log.GetLogger("logindex", common.PrintLog))
+		if err != nil {
+			fmt.Printf("loading the log indexer failed: %s\n", err.Error())
+			return
+		}
+
+		if err = indexer.Reindex(context.Background()); err != nil {
+			fmt.Printf("rebuilding the log indices failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("log indices rebuilt till height %d\n", indexer.Progress().Height)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reindexLogsCmd)
+
+	reindexConfigFile = reindexLogsCmd.Flags().StringP("config", "c", "", "seele node config file (required)")
+	reindexLogsCmd.MarkFlagRequired("config")
+
+	reindexDataDir = reindexLogsCmd.Flags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file")
+}
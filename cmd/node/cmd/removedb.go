@@ -0,0 +1,78 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/flock"
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+var removeDBConfigFile *string
+var removeDBDataDir *string
+var removeDBForce *bool
+
+// removeDBCmd represents the removedb command
+var removeDBCmd = &cobra.Command{
+	Use:   "removedb",
+	Short: "remove the blockchain and account state databases of a stopped node",
+	Long: `For example:
+			node.exe removedb -c cmd\node.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := loadOfflineConfig(*removeDBConfigFile, "", *removeDBDataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		// make sure the node is not running on the same data folder
+		lock, err := flock.New(nCfg.DataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer lock.Release()
+
+		dirs := []string{
+			filepath.Join(nCfg.DataDir, seele.BlockChainDir),
+			filepath.Join(nCfg.DataDir, seele.AccountStateDir),
+		}
+
+		for _, dir := range dirs {
+			if !common.FileOrFolderExists(dir) {
+				fmt.Printf("%s not found, skipped\n", dir)
+				continue
+			}
+
+			if !*removeDBForce && !common.Confirm(fmt.Sprintf("remove the database %s?", dir)) {
+				fmt.Printf("%s skipped\n", dir)
+				continue
+			}
+
+			if err = os.RemoveAll(dir); err != nil {
+				fmt.Printf("removing %s failed: %s\n", dir, err.Error())
+				return
+			}
+
+			fmt.Printf("%s removed\n", dir)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(removeDBCmd)
+
+	removeDBConfigFile = removeDBCmd.Flags().StringP("config", "c", "", "seele node config file (required)")
+	removeDBCmd.MarkFlagRequired("config")
+
+	removeDBDataDir = removeDBCmd.Flags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file")
+	removeDBForce = removeDBCmd.Flags().BoolP("yes", "y", false, "remove the databases without confirmation")
+}
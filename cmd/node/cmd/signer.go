@@ -0,0 +1,203 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/seele/scheduler"
+	"github.com/spf13/cobra"
+)
+
+// signerStartTimeout is the timeout to wait for the signer process to load the keys and listen.
+const signerStartTimeout = 30 * time.Second
+
+// signerSecretLength is the length in bytes of the secret shared with the signer process.
+const signerSecretLength = 32
+
+var (
+	errSignerNoKeys   = errors.New("no keys to load in the signer process")
+	errSignerNoSecret = errors.New("no secret received by the signer process")
+)
+
+var signerKeyStores *string
+
+// signerCmd represents the signer command, which runs as the child of the node process
+var signerCmd = &cobra.Command{
+	Use:    "signer",
+	Short:  "run the signer process that holds the private keys, started by the node",
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSigner(*signerKeyStores, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "signer process failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signerCmd)
+
+	signerKeyStores = signerCmd.Flags().String("keystores", "", "key stores to load in JSON")
+}
+
+// runSigner reads the secret shared with the node from the first line of in, loads the keys
+// of the key stores, and serves the external signer protocol on a loopback address which is
+// written to out. Only the requests of the secret are signed, so the other local processes
+// could not sign with the keys via the address. It returns once in is closed, e.g. the node
+// process exits.
+func runSigner(keyStores string, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	secret := strings.TrimSpace(line)
+	if len(secret) == 0 {
+		return errSignerNoSecret
+	}
+
+	var configs []*keystore.Config
+	if err = json.Unmarshal([]byte(keyStores), &configs); err != nil {
+		return err
+	}
+
+	if len(configs) == 0 {
+		return errSignerNoKeys
+	}
+
+	accounts := scheduler.NewAccounts()
+	for _, config := range configs {
+		key, err := keystore.LoadKey(config)
+		if err != nil {
+			return fmt.Errorf("loading the key %s failed: %s", config.Name, err)
+		}
+
+		if _, err = accounts.Unlock(key.PrivateKey); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	go scheduler.NewSignerService(accounts, secret).Serve(listener)
+
+	if _, err = fmt.Fprintln(out, listener.Addr().String()); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(ioutil.Discard, reader)
+	return err
+}
+
+// signerProcess is the signer child process of the node.
+type signerProcess struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	address string // address of the external signer protocol
+	secret  string // secret shared with the signer process, generated for each launch
+}
+
+// startSignerProcess starts the signer process of the config, passes the secret on its stdin
+// instead of the command line which is visible to the other users, and waits for it to listen.
+func startSignerProcess(config *SignerProcessConfig) (*signerProcess, error) {
+	secret := make([]byte, signerSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	keyStores, err := json.Marshal(config.KeyStores)
+	if err != nil {
+		return nil, err
+	}
+
+	attr, err := signerSysProcAttr(config)
+	if err != nil {
+		return nil, err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(executable, "signer", "--keystores", string(keyStores))
+	cmd.SysProcAttr = attr
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	process := &signerProcess{cmd: cmd, stdin: stdin, secret: hex.EncodeToString(secret)}
+	if _, err = fmt.Fprintln(stdin, process.secret); err != nil {
+		process.stop()
+		return nil, err
+	}
+
+	addressCh := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdout).ReadString('\n')
+		addressCh <- strings.TrimSpace(line)
+	}()
+
+	select {
+	case process.address = <-addressCh:
+	case <-time.After(signerStartTimeout):
+	}
+
+	if len(process.address) == 0 {
+		process.stop()
+		return nil, errors.New("signer process failed to start")
+	}
+
+	return process, nil
+}
+
+// stop stops the signer process by closing its stdin.
+func (process *signerProcess) stop() {
+	process.stdin.Close()
+
+	done := make(chan struct{})
+	go func() {
+		process.cmd.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(signerStartTimeout):
+		process.cmd.Process.Kill()
+	}
+}
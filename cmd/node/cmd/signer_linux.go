@@ -0,0 +1,41 @@
+// +build linux
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// signerSysProcAttr returns the attributes of the signer process, which is killed once the
+// node process dies, and runs as the user of the config if specified.
+func signerSysProcAttr(config *SignerProcessConfig) (*syscall.SysProcAttr, error) {
+	attr := &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	if len(config.User) == 0 {
+		return attr, nil
+	}
+
+	u, err := user.Lookup(config.User)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	attr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	return attr, nil
+}
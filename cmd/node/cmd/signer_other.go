@@ -0,0 +1,23 @@
+// +build !linux
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"errors"
+	"syscall"
+)
+
+// signerSysProcAttr returns the attributes of the signer process, which exits once its stdin
+// is closed along with the node process. Running as another user is not supported.
+func signerSysProcAttr(config *SignerProcessConfig) (*syscall.SysProcAttr, error) {
+	if len(config.User) > 0 {
+		return nil, errors.New("running the signer process as another user is only supported on Linux")
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,108 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/seeleteam/go-seele/seele/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var snapshotConfigFile *string
+var snapshotDataDir *string
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "create or restore the chain snapshot of a stopped node",
+	Long:  `use "node snapshot help [<command>]" for detailed usage`,
+}
+
+// snapshotCreateCmd represents the snapshot create command
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create <file>",
+	Short: "write the canonical chain up to the HEAD block and its state into the snapshot file",
+	Long: `For example:
+			node.exe snapshot create chain.snapshot -c cmd\node.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := loadOfflineConfig(*snapshotConfigFile, "", *snapshotDataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		dbs, err := openChainDatabases(nCfg.DataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		chain, err := dbs.blockchain()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		signed, err := snapshot.New(chain, dbs.accountStateDB, args[0], nil)
+		if err != nil {
+			fmt.Printf("creating the snapshot failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("snapshot created, height:%d, hash:%s\n", signed.Snapshot.Height, signed.Snapshot.HeadHash.ToHex())
+	},
+}
+
+// snapshotRestoreCmd represents the snapshot restore command
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "import the blocks and state of the snapshot file into the initialized data folder",
+	Long: `For example:
+			node.exe snapshot restore chain.snapshot -c cmd\node.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		nCfg, err := loadOfflineConfig(*snapshotConfigFile, "", *snapshotDataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		dbs, err := openChainDatabases(nCfg.DataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		chain, err := dbs.blockchain()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		snap, err := snapshot.Restore(chain, dbs.accountStateDB, args[0])
+		if err != nil {
+			fmt.Printf("restoring the snapshot failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("snapshot restored, height:%d, hash:%s\n", snap.Height, snap.HeadHash.ToHex())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+
+	snapshotConfigFile = snapshotCmd.PersistentFlags().StringP("config", "c", "", "seele node config file (required)")
+	snapshotCmd.MarkPersistentFlagRequired("config")
+
+	snapshotDataDir = snapshotCmd.PersistentFlags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file")
+}
@@ -8,10 +8,12 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types/vectors"
 	"github.com/seeleteam/go-seele/log"
 	"github.com/seeleteam/go-seele/monitor"
 	"github.com/seeleteam/go-seele/node"
@@ -22,6 +24,13 @@ import (
 var seeleNodeConfigFile *string
 var miner *string
 var genesisConfigFile *string
+var bootstrapURL *string
+var bootstrapPublisher *string
+var dataDir *string
+var network *string
+var selfTest *bool
+var memoryDB *bool
+var devSeal *bool
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
@@ -29,19 +38,64 @@ var startCmd = &cobra.Command{
 	Short: "start the node of seele",
 	Long: `usage example:
 		node.exe start -c cmd\node.json
-		start a node.`,
+		start a node.
+		node.exe start -c cmd\node.json --memorydb --dev
+		start an ephemeral node in memory, which seals the blocks once the txs arrive.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		var wg sync.WaitGroup
-		nCfg, err := LoadConfigFromFile(*seeleNodeConfigFile, *genesisConfigFile)
+		if *selfTest {
+			if err := vectors.SelfTest(); err != nil {
+				fmt.Printf("wire compatibility self test failed: %s\n", err.Error())
+				return
+			}
+
+			fmt.Println("wire compatibility self test passed")
+		}
+
+		if *dataDir != "" {
+			log.LogFolder = filepath.Join(*dataDir, "log")
+		}
+
+		nCfg, err := LoadConfigFromFile(*seeleNodeConfigFile, *genesisConfigFile, *network)
+		if err != nil {
+			fmt.Printf("reading the config file failed: %s\n", err.Error())
+			return
+		}
+
+		if *dataDir != "" {
+			nCfg.DataDir = *dataDir
+		}
+
+		nCfg.SeeleConfig.MemoryDB = *memoryDB
+		nCfg.SeeleConfig.DevSeal = *devSeal
+
+		// the signing keys are only loaded in the signer process
+		config, err := GetConfigFromFile(*seeleNodeConfigFile)
 		if err != nil {
 			fmt.Printf("reading the config file failed: %s\n", err.Error())
 			return
 		}
 
+		if config.SignerProcess != nil {
+			signer, err := startSignerProcess(config.SignerProcess)
+			if err != nil {
+				fmt.Printf("starting the signer process failed: %s\n", err.Error())
+				return
+			}
+			defer signer.stop()
+
+			nCfg.SeeleConfig.ExternalSigner = signer.address
+			nCfg.SeeleConfig.ExternalSignerSecret = signer.secret
+			fmt.Printf("signer process started, address: %s\n", signer.address)
+		}
+
 		// print some config infos
 		fmt.Printf("log folder: %s\n", log.LogFolder)
 		fmt.Printf("data folder: %s\n", nCfg.DataDir)
+		if *memoryDB {
+			fmt.Println("the chain is kept in memory, which is lost once the node stops")
+		}
 
 		seeleNode, err := node.New(nCfg)
 		if err != nil {
@@ -61,6 +115,19 @@ var startCmd = &cobra.Command{
 			return
 		}
 
+		if *bootstrapURL != "" {
+			publisher, err := common.HexToAddress(*bootstrapPublisher)
+			if err != nil {
+				fmt.Printf("invalid snapshot publisher address: %s\n", err.Error())
+				return
+			}
+
+			if err = seeleService.BootstrapFromURL(*bootstrapURL, publisher); err != nil {
+				fmt.Printf("bootstrapping from snapshot failed: %s\n", err.Error())
+				return
+			}
+		}
+
 		// monitor service
 		monitorService, err := monitor.NewMonitorService(seeleService, seeleNode, nCfg, slog, "Test monitor")
 		if err != nil {
@@ -77,7 +144,7 @@ var startCmd = &cobra.Command{
 
 		seeleNode.Start()
 		if strings.ToLower(*miner) == "start" {
-			err = seeleService.Miner().Start()
+			err = seeleService.Miner().Start(0)
 			if err != nil {
 				fmt.Println("Starting the miner failed: ", err.Error())
 				return
@@ -97,5 +164,17 @@ func init() {
 
 	miner = startCmd.Flags().StringP("miner", "m", "start", "miner start or not, [start, stop]")
 
-	genesisConfigFile = startCmd.Flags().StringP("genesis", "g", "", "seele genesis config file")
+	genesisConfigFile = startCmd.Flags().StringP("genesis", "g", "", "seele genesis config file, which overrides the genesis of the network preset")
+
+	network = startCmd.Flags().String("network", "", "network preset that selects the genesis, bootnodes, network id and default ports, [mainnet, testnet, devnet]")
+
+	bootstrapURL = startCmd.Flags().String("bootstrap-from-url", "", "URL of the signed chain snapshot to import before joining the network")
+	bootstrapPublisher = startCmd.Flags().String("bootstrap-publisher", "", "public address of the trusted snapshot publisher")
+
+	selfTest = startCmd.Flags().Bool("selftest", false, "verify the wire encodings, hashes and signatures against the golden test vectors before starting")
+
+	memoryDB = startCmd.Flags().Bool("memorydb", false, "keep the chain, state and indices in memory, e.g. for the ephemeral CI and fuzzing runs")
+	devSeal = startCmd.Flags().Bool("dev", false, "seal the blocks at once when the txs arrive, in which the difficulty stays around 1, for the dev chains only")
+
+	dataDir = startCmd.Flags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file and also holds the logs")
 }
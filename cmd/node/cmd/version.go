@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/seele"
+	"github.com/spf13/cobra"
+)
+
+var versionConfigFile *string
+var versionDataDir *string
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "print the versions, and check the database of a stopped node if the config is specified",
+	Long: `For example:
+			node.exe version
+			node.exe version -c cmd\node.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("protocol version: %d\n", seele.SeeleVersion)
+		fmt.Printf("DB schema version: %d\n", store.SchemaVersion)
+
+		if *versionConfigFile == "" {
+			return
+		}
+
+		nCfg, err := loadOfflineConfig(*versionConfigFile, "", *versionDataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		fmt.Printf("node version: %s\n", nCfg.Version)
+
+		dbs, err := openChainDatabases(nCfg.DataDir)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		defer dbs.close()
+
+		bcStore := dbs.store()
+		genesisHash, err := bcStore.GetBlockHash(0)
+		if err != nil {
+			fmt.Printf("data folder %s is not initialized\n", nCfg.DataDir)
+			return
+		}
+
+		fmt.Printf("genesis hash: %s\n", genesisHash.ToHex())
+
+		// databases created before the schema version is introduced have no version
+		version, err := bcStore.GetSchemaVersion()
+		if err != nil {
+			fmt.Println("data folder DB schema version: unknown")
+			return
+		}
+
+		fmt.Printf("data folder DB schema version: %d\n", version)
+		if version != store.SchemaVersion {
+			fmt.Println("the DB schema version mismatch, please remove the databases with the removedb command and resync")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+
+	versionConfigFile = versionCmd.Flags().StringP("config", "c", "", "seele node config file to check the data folder")
+	versionDataDir = versionCmd.Flags().String("datadir", "", "data folder of the node, which overrides the DataDir in config file")
+}
@@ -0,0 +1,57 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/seeleteam/go-seele/core/types/vectors"
+	"github.com/spf13/cobra"
+)
+
+var outputFile *string
+
+// generateCmd represents the generate command
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "generate the golden test vectors",
+	Long: `generate the canonical encodings, hashes and signatures of the txs, block headers and receipts
+    For example:
+		vectors.exe generate -o vectors.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		generated, err := vectors.Generate()
+		if err != nil {
+			fmt.Printf("generating the test vectors failed: %s\n", err.Error())
+			return
+		}
+
+		encoded, err := json.MarshalIndent(generated, "", "  ")
+		if err != nil {
+			fmt.Printf("encoding the test vectors failed: %s\n", err.Error())
+			return
+		}
+
+		if *outputFile == "" {
+			fmt.Println(string(encoded))
+			return
+		}
+
+		if err = ioutil.WriteFile(*outputFile, encoded, 0644); err != nil {
+			fmt.Printf("writing the test vectors failed: %s\n", err.Error())
+			return
+		}
+
+		fmt.Printf("%d test vectors written to %s, digest %s\n", len(generated.Vectors), *outputFile, generated.Digest().ToHex())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+
+	outputFile = generateCmd.Flags().StringP("output", "o", "", "file to write the test vectors, empty to print")
+}
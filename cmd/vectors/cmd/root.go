@@ -0,0 +1,29 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// rootCmd represents the base command called without any subcommands
+var rootCmd = &cobra.Command{
+	Use:   "vectors",
+	Short: "wire compatibility test vectors",
+	Long:  `generate and verify the golden test vectors of the encodings, hashes and signatures for the third-party SDKs`,
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+}
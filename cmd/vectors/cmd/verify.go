@@ -0,0 +1,60 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/seeleteam/go-seele/core/types/vectors"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify [<vectors.json>]",
+	Short: "verify the test vectors against this build",
+	Long: `verify the test vectors file against the ones generated by this build, or run the self test if no file specified
+    For example:
+		vectors.exe verify vectors.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			if err := vectors.SelfTest(); err != nil {
+				fmt.Printf("self test failed: %s\n", err.Error())
+				os.Exit(1)
+			}
+
+			fmt.Println("self test passed")
+			return
+		}
+
+		content, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("reading the test vectors failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		var loaded vectors.Vectors
+		if err = json.Unmarshal(content, &loaded); err != nil {
+			fmt.Printf("decoding the test vectors failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		if err = loaded.Verify(); err != nil {
+			fmt.Printf("verifying the test vectors failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+
+		fmt.Printf("%d test vectors verified\n", len(loaded.Vectors))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
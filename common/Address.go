@@ -7,16 +7,28 @@ package common
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/crypto/sha3"
 )
 
 const (
 	addressIDBits = 512 // the length of the public key
 )
 
+var (
+	// ErrAddressChecksum is returned when the mixed-case address hex does not match its checksum.
+	ErrAddressChecksum = errors.New("address checksum mismatch")
+
+	// ErrAddressNotChecksummed is returned when the address hex is required to be checksummed
+	// but is in a single case.
+	ErrAddressNotChecksummed = errors.New("address is not checksummed")
+)
+
 // Address we use public key as node id
 type Address [addressIDBits / 8]byte
 
@@ -39,6 +51,35 @@ func (id *Address) ToHex() string {
 	return hexutil.BytesToHex(id.Bytes())
 }
 
+// ToChecksumHex returns the 0x-prefixed hex of the address in mixed case, in which each letter
+// is upper case if the corresponding nibble of the Keccak-512 hash of the lower-case hex is at
+// least 8, so that the typos in the address are detected with high probability.
+func (id Address) ToChecksumHex() string {
+	hex := []byte(hexutil.BytesToHex(id.Bytes())[2:])
+
+	hasher := sha3.NewKeccak512()
+	hasher.Write(hex)
+	hash := hasher.Sum(nil)
+
+	for i, c := range hex {
+		nibble := hash[i/2] >> 4
+		if i%2 == 1 {
+			nibble = hash[i/2] & 0x0f
+		}
+
+		if c >= 'a' && nibble >= 8 {
+			hex[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(hex)
+}
+
+// String implements the fmt.Stringer interface, returning the checksummed hex.
+func (id Address) String() string {
+	return id.ToChecksumHex()
+}
+
 func (id *Address) Equal(b Address) bool {
 	return bytes.Equal(id[:], b[:])
 }
@@ -57,6 +98,27 @@ func HexToAddress(id string) (Address, error) {
 	return nid, nil
 }
 
+// HexToChecksummedAddress converts the hex in any case to address, and returns whether the hex
+// is checksummed, i.e. in mixed case. It returns ErrAddressChecksum if the mixed-case hex does
+// not match the checksum.
+func HexToChecksummedAddress(id string) (Address, bool, error) {
+	addr, err := HexToAddress(id)
+	if err != nil {
+		return Address{}, false, err
+	}
+
+	digits := id[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return addr, false, nil
+	}
+
+	if addr.ToChecksumHex()[2:] != digits {
+		return Address{}, true, ErrAddressChecksum
+	}
+
+	return addr, true, nil
+}
+
 func HexMustToAddres(id string) Address {
 	a, err := HexToAddress(id)
 	if err != nil {
@@ -84,3 +146,20 @@ func BigToAddress(b *big.Int) Address { return BytesToAddress(b.Bytes()) }
 
 // Big converts address to a big int.
 func (id Address) Big() *big.Int { return new(big.Int).SetBytes(id[:]) }
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// so that the address is encoded in 0x-prefixed hex in JSON.
+func (id Address) MarshalText() ([]byte, error) {
+	return []byte(id.ToHex()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (id *Address) UnmarshalText(input []byte) error {
+	addr, err := HexToAddress(string(input))
+	if err != nil {
+		return err
+	}
+
+	*id = addr
+	return nil
+}
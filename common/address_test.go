@@ -6,6 +6,8 @@
 package common
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/magiconair/properties/assert"
@@ -30,3 +32,48 @@ func Test_BytesToAddress(t *testing.T) {
 	}
 	assert.Equal(t, BytesToAddress(b3).Bytes(), b3[1:])
 }
+
+func Test_Address_JSON(t *testing.T) {
+	addr := BytesToAddress([]byte{1, 2})
+
+	encoded, err := json.Marshal(addr)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, string(encoded), `"`+addr.ToHex()+`"`)
+
+	var decoded Address
+	assert.Equal(t, json.Unmarshal(encoded, &decoded), error(nil))
+	assert.Equal(t, decoded, addr)
+
+	assert.Equal(t, json.Unmarshal([]byte(`"0x0102"`), &decoded) != nil, true)
+}
+
+func Test_Address_Checksum(t *testing.T) {
+	addr := BytesToAddress([]byte{0xab, 0xcd, 0xef, 0x12})
+	checksummed := addr.ToChecksumHex()
+	assert.Equal(t, strings.ToLower(checksummed), addr.ToHex())
+	assert.Equal(t, addr.String(), checksummed)
+
+	parsed, ok, err := HexToChecksummedAddress(checksummed)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, ok, true)
+	assert.Equal(t, parsed, addr)
+
+	// single case is valid but not checksummed
+	parsed, ok, err = HexToChecksummedAddress(addr.ToHex())
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, ok, false)
+	assert.Equal(t, parsed, addr)
+
+	// flip the case of a letter
+	i := strings.IndexAny(checksummed[2:], "abcdefABCDEF") + 2
+	flipped := []byte(checksummed)
+	if flipped[i] >= 'a' {
+		flipped[i] -= 'a' - 'A'
+	} else {
+		flipped[i] += 'a' - 'A'
+	}
+
+	_, ok, err = HexToChecksummedAddress(string(flipped))
+	assert.Equal(t, err, ErrAddressChecksum)
+	assert.Equal(t, ok, true)
+}
@@ -0,0 +1,76 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package flock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileName is the name of the lock file in the locked folder.
+const FileName = "LOCK"
+
+// ErrLocked is returned when the folder is already locked by another process.
+type ErrLocked struct {
+	Dir string // Dir is the locked folder
+	Pid int    // Pid is the id of the process that holds the lock, 0 if unknown
+}
+
+func (err *ErrLocked) Error() string {
+	if err.Pid > 0 {
+		return fmt.Sprintf("data folder %s is already in use by process %d, use another data folder for each node", err.Dir, err.Pid)
+	}
+
+	return fmt.Sprintf("data folder %s is already in use by another process, use another data folder for each node", err.Dir)
+}
+
+// Lock is an exclusive lock of a folder across processes.
+type Lock struct {
+	file *os.File
+}
+
+// New acquires the exclusive lock of the specified folder, creating the folder if necessary.
+// Returns *ErrLocked if the folder is already locked by another process.
+func New(dir string) (*Lock, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, FileName)
+	file, err := lockFile(path)
+	if err != nil {
+		if err == errLocked {
+			return nil, &ErrLocked{dir, readPid(path)}
+		}
+
+		return nil, err
+	}
+
+	// record the pid for the error message of other processes
+	file.Truncate(0)
+	file.WriteString(strconv.Itoa(os.Getpid()))
+
+	return &Lock{file}, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	return unlockFile(l.file)
+}
+
+func readPid(path string) int {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(content)))
+	return pid
+}
@@ -0,0 +1,35 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package flock
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_Lock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	lock, err := New(dir)
+	assert.Equal(t, err, nil)
+
+	// locked
+	_, err = New(dir)
+	assert.Equal(t, err, &ErrLocked{dir, os.Getpid()})
+
+	// lock again after released
+	assert.Equal(t, lock.Release(), nil)
+	lock, err = New(dir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, lock.Release(), nil)
+}
@@ -0,0 +1,40 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package flock
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+var errLocked = errors.New("locked")
+
+// errSharingViolation is returned by windows when the file is opened by another process without sharing.
+const errSharingViolation syscall.Errno = 32
+
+func lockFile(path string) (*os.File, error) {
+	pathp, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// open the file without sharing, so that the file could not be opened by other processes
+	handle, err := syscall.CreateFile(pathp, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if err == errSharingViolation {
+			return nil, errLocked
+		}
+
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+func unlockFile(file *os.File) error {
+	return file.Close()
+}
@@ -7,6 +7,7 @@ package common
 
 import (
 	"bytes"
+	"fmt"
 	"math/big"
 
 	"github.com/seeleteam/go-seele/common/hexutil"
@@ -79,3 +80,24 @@ func BigToHash(b *big.Int) Hash { return BytesToHash(b.Bytes()) }
 
 // Big converts this Hash to a big int.
 func (a Hash) Big() *big.Int { return new(big.Int).SetBytes(a[:]) }
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// so that the hash is encoded in 0x-prefixed hex in JSON.
+func (a Hash) MarshalText() ([]byte, error) {
+	return []byte(a.ToHex()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (a *Hash) UnmarshalText(input []byte) error {
+	b, err := hexutil.HexToBytes(string(input))
+	if err != nil {
+		return err
+	}
+
+	if len(b) != HashLength {
+		return fmt.Errorf("wrong hash length, want %d bytes", HashLength)
+	}
+
+	a.SetBytes(b)
+	return nil
+}
@@ -0,0 +1,174 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	// TypeFile is the key store type of encrypted key files.
+	TypeFile = "file"
+
+	// TypeKeyring is the key store type of the OS keyring.
+	TypeKeyring = "keyring"
+
+	// TypePKCS11 is the key store type of the PKCS#11 token, e.g. HSM.
+	TypePKCS11 = "pkcs11"
+
+	// defaultKeyringService is the service name of the keys in OS keyring.
+	defaultKeyringService = "go-seele"
+)
+
+var (
+	// ErrUnknownKeyStore is returned when the key store type is not supported.
+	ErrUnknownKeyStore = errors.New("unknown key store type")
+
+	// ErrKeyringNotSupported is returned when the OS keyring is not supported on the current platform.
+	ErrKeyringNotSupported = fmt.Errorf("OS keyring is not supported on %s", runtime.GOOS)
+
+	// ErrPKCS11NotSupported is returned when the binary is built without PKCS#11 support,
+	// which is built with the pkcs11 build tag.
+	ErrPKCS11NotSupported = errors.New("PKCS#11 key store is not supported in this build")
+
+	// ErrPKCS11ModuleRequired is returned when the PKCS#11 library path is not specified.
+	ErrPKCS11ModuleRequired = errors.New("PKCS#11 module is required")
+)
+
+// KeyStore is the storage of the private keys, e.g. node identity and account keys.
+type KeyStore interface {
+	// GetKey retrieves the key of the specified name and decrypts it with the password.
+	GetKey(name, password string) (*Key, error)
+
+	// StoreKey encrypts the key with the password and stores it with the specified name.
+	StoreKey(name, password string, key *Key) error
+}
+
+// Config is the configuration to load a key from a key store.
+type Config struct {
+	Type         string // Type is the key store type, file, keyring or pkcs11
+	Name         string // Name is the key file path, keyring account or PKCS#11 key label
+	PasswordFile string // PasswordFile is the file that contains the password of the key, empty for no password
+	Module       string // Module is the PKCS#11 library path, only used by pkcs11 key store
+	PINFile      string // PINFile is the file that contains the user PIN of the PKCS#11 token, only used by pkcs11 key store
+}
+
+// NewKeyStore creates the key store of the specified config.
+func NewKeyStore(config *Config) (KeyStore, error) {
+	switch config.Type {
+	case TypeFile, "":
+		return &FileKeyStore{}, nil
+	case TypeKeyring:
+		return &KeyringKeyStore{Service: defaultKeyringService}, nil
+	case TypePKCS11:
+		return newPKCS11KeyStore(config)
+	default:
+		return nil, ErrUnknownKeyStore
+	}
+}
+
+// LoadKey loads the key of the specified config.
+func LoadKey(config *Config) (*Key, error) {
+	store, err := NewKeyStore(config)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := readSecretFile(config.PasswordFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.GetKey(config.Name, password)
+}
+
+// readSecretFile returns the secret in the specified file without the trailing line breaks,
+// or empty if the file is not specified.
+func readSecretFile(path string) (string, error) {
+	if len(path) == 0 {
+		return "", nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// FileKeyStore stores the encrypted keys in files, the key name is the file path.
+type FileKeyStore struct{}
+
+// GetKey implements KeyStore
+func (store *FileKeyStore) GetKey(name, password string) (*Key, error) {
+	return GetKey(name, password)
+}
+
+// StoreKey implements KeyStore
+func (store *FileKeyStore) StoreKey(name, password string, key *Key) error {
+	return StoreKey(name, password, key)
+}
+
+// KeyringKeyStore stores the encrypted keys in the OS keyring, i.e. the keychain
+// on macOS and the secret service (via secret-tool) on Linux.
+type KeyringKeyStore struct {
+	Service string // Service is the keyring service name of the keys
+}
+
+// GetKey implements KeyStore
+func (store *KeyringKeyStore) GetKey(name, password string) (*Key, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", store.Service, "-a", name, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", store.Service, "account", name)
+	default:
+		return nil, ErrKeyringNotSupported
+	}
+
+	content, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s from keyring, %s", name, err)
+	}
+
+	return DecryptKey(bytes.TrimSpace(content), password)
+}
+
+// StoreKey implements KeyStore
+func (store *KeyringKeyStore) StoreKey(name, password string, key *Key) error {
+	content, err := EncryptKey(key, password)
+	if err != nil {
+		return err
+	}
+
+	// the key is written on stdin instead of the command line which is visible to the other users
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// the password data is prompted twice without the value of -w
+		cmd = exec.Command("security", "add-generic-password", "-U", "-s", store.Service, "-a", name, "-w")
+		cmd.Stdin = strings.NewReader(string(content) + "\n" + string(content) + "\n")
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label", store.Service+" "+name, "service", store.Service, "account", name)
+		cmd.Stdin = bytes.NewReader(content)
+	default:
+		return ErrKeyringNotSupported
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store key %s in keyring, %s, %s", name, err, output)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+// +build !pkcs11
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package keystore
+
+// newPKCS11KeyStore returns ErrPKCS11NotSupported, since the binary is built without the pkcs11 build tag.
+func newPKCS11KeyStore(config *Config) (KeyStore, error) {
+	return nil, ErrPKCS11NotSupported
+}
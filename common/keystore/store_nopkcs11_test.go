@@ -0,0 +1,19 @@
+// +build !pkcs11
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package keystore
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_NewKeyStore_PKCS11NotSupported(t *testing.T) {
+	_, err := NewKeyStore(&Config{Type: TypePKCS11, Module: "libsofthsm2.so"})
+	assert.Equal(t, err, ErrPKCS11NotSupported)
+}
@@ -0,0 +1,171 @@
+// +build pkcs11
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package keystore
+
+import (
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+)
+
+// pkcs11Application is the application of the data objects of the keys in the PKCS#11 token.
+const pkcs11Application = "go-seele"
+
+// PKCS11KeyStore stores the encrypted keys as the private data objects in the PKCS#11 token,
+// e.g. HSM, the key name is the label of the data object. The key is loaded from the first
+// slot with the token present.
+type PKCS11KeyStore struct {
+	Module string // Module is the PKCS#11 library path
+	PIN    string // PIN is the user PIN of the token
+}
+
+// newPKCS11KeyStore creates the PKCS#11 key store of the module and PIN file of the config.
+func newPKCS11KeyStore(config *Config) (KeyStore, error) {
+	if len(config.Module) == 0 {
+		return nil, ErrPKCS11ModuleRequired
+	}
+
+	pin, err := readSecretFile(config.PINFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PKCS11KeyStore{config.Module, pin}, nil
+}
+
+// GetKey implements KeyStore
+func (store *PKCS11KeyStore) GetKey(name, password string) (*Key, error) {
+	var content []byte
+	err := store.withSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		objects, err := findPKCS11Objects(ctx, session, name)
+		if err != nil {
+			return err
+		}
+
+		if len(objects) == 0 {
+			return fmt.Errorf("key %s not found in PKCS#11 token", name)
+		}
+
+		attrs, err := ctx.GetAttributeValue(session, objects[0], []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil)})
+		if err != nil {
+			return err
+		}
+
+		content = attrs[0].Value
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s from PKCS#11 token, %s", name, err)
+	}
+
+	return DecryptKey(content, password)
+}
+
+// StoreKey implements KeyStore, which replaces the existing key of the same name.
+func (store *PKCS11KeyStore) StoreKey(name, password string, key *Key) error {
+	content, err := EncryptKey(key, password)
+	if err != nil {
+		return err
+	}
+
+	err = store.withSession(func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error {
+		objects, err := findPKCS11Objects(ctx, session, name)
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			if err = ctx.DestroyObject(session, object); err != nil {
+				return err
+			}
+		}
+
+		_, err = ctx.CreateObject(session, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, pkcs11Application),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, name),
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, content),
+		})
+
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to store key %s in PKCS#11 token, %s", name, err)
+	}
+
+	return nil
+}
+
+// withSession loads the module, and runs the specified function in the session of the first
+// token logged in as the user.
+func (store *PKCS11KeyStore) withSession(fn func(ctx *pkcs11.Ctx, session pkcs11.SessionHandle) error) error {
+	ctx := pkcs11.New(store.Module)
+	if ctx == nil {
+		return fmt.Errorf("failed to load PKCS#11 module %s", store.Module)
+	}
+	defer ctx.Destroy()
+
+	if err := ctx.Initialize(); err != nil {
+		return err
+	}
+	defer ctx.Finalize()
+
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return err
+	}
+
+	if len(slots) == 0 {
+		return fmt.Errorf("no token present in PKCS#11 module %s", store.Module)
+	}
+
+	session, err := ctx.OpenSession(slots[0], pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return err
+	}
+	defer ctx.CloseSession(session)
+
+	if err = ctx.Login(session, pkcs11.CKU_USER, store.PIN); err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return err
+	}
+	defer ctx.Logout(session)
+
+	return fn(ctx, session)
+}
+
+// findPKCS11Objects returns the data objects of the key of the specified name.
+func findPKCS11Objects(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, name string) ([]pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, pkcs11Application),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, name),
+	}
+
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return nil, err
+	}
+	defer ctx.FindObjectsFinal(session)
+
+	var objects []pkcs11.ObjectHandle
+	for {
+		found, _, err := ctx.FindObjects(session, 16)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(found) == 0 {
+			return objects, nil
+		}
+
+		objects = append(objects, found...)
+	}
+}
@@ -0,0 +1,39 @@
+// +build pkcs11
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_NewKeyStore_PKCS11(t *testing.T) {
+	_, err := NewKeyStore(&Config{Type: TypePKCS11})
+	assert.Equal(t, err, ErrPKCS11ModuleRequired)
+
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pinFile := filepath.Join(dir, "pin")
+	assert.Equal(t, ioutil.WriteFile(pinFile, []byte("1234\n"), 0600), nil)
+
+	store, err := NewKeyStore(&Config{Type: TypePKCS11, Module: "libsofthsm2.so", PINFile: pinFile})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, store, &PKCS11KeyStore{"libsofthsm2.so", "1234"})
+
+	// the module could not be loaded
+	_, err = store.GetKey("key", "password")
+	assert.Equal(t, err != nil, true)
+}
@@ -0,0 +1,51 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_NewKeyStore(t *testing.T) {
+	store, err := NewKeyStore(&Config{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, store, &FileKeyStore{})
+
+	_, err = NewKeyStore(&Config{Type: "unknown"})
+	assert.Equal(t, err, ErrUnknownKeyStore)
+}
+
+func Test_LoadKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr, keypair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		panic(err)
+	}
+
+	config := &Config{
+		Type:         TypeFile,
+		Name:         filepath.Join(dir, "keyfile"),
+		PasswordFile: filepath.Join(dir, "password"),
+	}
+
+	assert.Equal(t, ioutil.WriteFile(config.PasswordFile, []byte("testfile\n"), 0600), nil)
+	assert.Equal(t, StoreKey(config.Name, "testfile", &Key{*addr, keypair}), nil)
+
+	key, err := LoadKey(config)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, key.Address, *addr)
+}
@@ -0,0 +1,231 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+// Package resource accounts the goroutines, allocated bytes and queue depths per subsystem of
+// the node, so that it is clear which subsystem is responsible once the node bloats. Each
+// subsystem could have soft limits, which are checked by the subsystem to throttle itself,
+// e.g. to refuse new connections, instead of being enforced here.
+package resource
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrSubsystemUnknown is returned when setting the limits of an unregistered subsystem.
+var ErrSubsystemUnknown = errors.New("unknown subsystem")
+
+// The subsystems of the node.
+var (
+	// P2P accounts the peer goroutines, and the received messages blocked to deliver to the protocols.
+	// Throttled by refusing the inbound connections.
+	P2P = Register("p2p")
+
+	// Pool accounts the txs in the tx pool. Throttled by refusing the new txs.
+	Pool = Register("pool")
+
+	// Miner accounts the mining threads, which are configured explicitly and not throttled.
+	Miner = Register("miner")
+
+	// RPC accounts the RPC connections and HTTP requests being served. Throttled by refusing the new ones.
+	RPC = Register("rpc")
+
+	// Sync accounts the block download goroutines. Throttled by skipping the sync rounds.
+	Sync = Register("sync")
+)
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]*Subsystem)
+)
+
+// Limits are the soft limits of a subsystem, 0 for unlimited.
+type Limits struct {
+	Goroutines int64 `json:"goroutines"` // Goroutines is the number of goroutines of the subsystem
+	Bytes      int64 `json:"bytes"`      // Bytes is the estimated bytes allocated by the subsystem
+	Queue      int64 `json:"queue"`      // Queue is the number of the queued items of the subsystem
+}
+
+// Stats are the resources used by a subsystem.
+type Stats struct {
+	Name          string `json:"name"`
+	Goroutines    int64  `json:"goroutines"`
+	Bytes         int64  `json:"bytes"`
+	Queue         int64  `json:"queue"`
+	Limits        Limits `json:"limits"`
+	Throttled     bool   `json:"throttled"`     // Throttled is true if any soft limit is reached
+	ThrottleCount uint64 `json:"throttleCount"` // ThrottleCount is the number of times the subsystem throttled itself
+}
+
+// Subsystem accounts the resources used by a subsystem, which is safe for concurrent use.
+type Subsystem struct {
+	name string
+
+	goroutines int64
+	bytes      int64
+	queue      int64
+
+	limitsLock sync.RWMutex
+	limits     Limits
+
+	throttleCount uint64
+}
+
+// Register returns the subsystem of the specified name, which is created if not registered yet.
+func Register(name string) *Subsystem {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if s := registry[name]; s != nil {
+		return s
+	}
+
+	s := &Subsystem{name: name}
+	registry[name] = s
+
+	return s
+}
+
+// SetLimits sets the soft limits of the registered subsystems by name.
+func SetLimits(limits map[string]Limits) error {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	for name := range limits {
+		if registry[name] == nil {
+			return ErrSubsystemUnknown
+		}
+	}
+
+	for name, l := range limits {
+		registry[name].SetLimits(l)
+	}
+
+	return nil
+}
+
+// AllStats returns the stats of all the registered subsystems in the order of the names.
+func AllStats() []*Stats {
+	registryLock.RLock()
+	stats := make([]*Stats, 0, len(registry))
+	for _, s := range registry {
+		stats = append(stats, s.Stats())
+	}
+	registryLock.RUnlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
+
+// Name returns the name of the subsystem.
+func (s *Subsystem) Name() string {
+	return s.name
+}
+
+// Go runs the function in a goroutine accounted to the subsystem.
+func (s *Subsystem) Go(f func()) {
+	atomic.AddInt64(&s.goroutines, 1)
+
+	go func() {
+		defer atomic.AddInt64(&s.goroutines, -1)
+		f()
+	}()
+}
+
+// Track accounts the current goroutine to the subsystem until the returned function is called,
+// e.g. for the goroutines started by the standard library.
+func (s *Subsystem) Track() func() {
+	atomic.AddInt64(&s.goroutines, 1)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { atomic.AddInt64(&s.goroutines, -1) })
+	}
+}
+
+// Alloc accounts the estimated bytes allocated by the subsystem.
+func (s *Subsystem) Alloc(bytes int) {
+	atomic.AddInt64(&s.bytes, int64(bytes))
+}
+
+// Free accounts the estimated bytes released by the subsystem.
+func (s *Subsystem) Free(bytes int) {
+	atomic.AddInt64(&s.bytes, -int64(bytes))
+}
+
+// Enqueue accounts an item of the specified bytes queued by the subsystem.
+func (s *Subsystem) Enqueue(bytes int) {
+	atomic.AddInt64(&s.queue, 1)
+	s.Alloc(bytes)
+}
+
+// Dequeue accounts an item of the specified bytes dequeued by the subsystem.
+func (s *Subsystem) Dequeue(bytes int) {
+	atomic.AddInt64(&s.queue, -1)
+	s.Free(bytes)
+}
+
+// SetQueue sets the queue depth of the subsystem, e.g. if the queue length is known.
+func (s *Subsystem) SetQueue(depth int) {
+	atomic.StoreInt64(&s.queue, int64(depth))
+}
+
+// SetLimits sets the soft limits of the subsystem.
+func (s *Subsystem) SetLimits(limits Limits) {
+	s.limitsLock.Lock()
+	defer s.limitsLock.Unlock()
+
+	s.limits = limits
+}
+
+// Limits returns the soft limits of the subsystem.
+func (s *Subsystem) Limits() Limits {
+	s.limitsLock.RLock()
+	defer s.limitsLock.RUnlock()
+
+	return s.limits
+}
+
+// Throttle returns true if any soft limit of the subsystem is reached, in which case the
+// subsystem should throttle itself, and the throttling is counted.
+func (s *Subsystem) Throttle() bool {
+	if !s.exceeded() {
+		return false
+	}
+
+	atomic.AddUint64(&s.throttleCount, 1)
+	return true
+}
+
+// exceeded indicates whether any soft limit is reached.
+func (s *Subsystem) exceeded() bool {
+	limits := s.Limits()
+
+	return reached(atomic.LoadInt64(&s.goroutines), limits.Goroutines) ||
+		reached(atomic.LoadInt64(&s.bytes), limits.Bytes) ||
+		reached(atomic.LoadInt64(&s.queue), limits.Queue)
+}
+
+func reached(value, limit int64) bool {
+	return limit > 0 && value >= limit
+}
+
+// Stats returns the resources used by the subsystem.
+func (s *Subsystem) Stats() *Stats {
+	return &Stats{
+		Name:          s.name,
+		Goroutines:    atomic.LoadInt64(&s.goroutines),
+		Bytes:         atomic.LoadInt64(&s.bytes),
+		Queue:         atomic.LoadInt64(&s.queue),
+		Limits:        s.Limits(),
+		Throttled:     s.exceeded(),
+		ThrottleCount: atomic.LoadUint64(&s.throttleCount),
+	}
+}
@@ -0,0 +1,67 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_Subsystem(t *testing.T) {
+	s := Register("test")
+	assert.Equal(t, Register("test"), s)
+
+	s.SetLimits(Limits{Goroutines: 1, Bytes: 100})
+	assert.Equal(t, s.Throttle(), false)
+
+	// goroutine limit
+	stop := make(chan struct{})
+	s.Go(func() { <-stop })
+	assert.Equal(t, s.Stats().Goroutines, int64(1))
+	assert.Equal(t, s.Throttle(), true)
+
+	close(stop)
+	for i := 0; i < 100 && s.Stats().Goroutines > 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, s.Throttle(), false)
+
+	done := s.Track()
+	assert.Equal(t, s.Stats().Goroutines, int64(1))
+	done()
+	done()
+	assert.Equal(t, s.Stats().Goroutines, int64(0))
+
+	// bytes limit
+	s.Enqueue(100)
+	stats := s.Stats()
+	assert.Equal(t, stats.Queue, int64(1))
+	assert.Equal(t, stats.Bytes, int64(100))
+	assert.Equal(t, stats.Throttled, true)
+	assert.Equal(t, s.Throttle(), true)
+
+	s.Dequeue(100)
+	stats = s.Stats()
+	assert.Equal(t, stats.Queue, int64(0))
+	assert.Equal(t, stats.Throttled, false)
+	assert.Equal(t, stats.ThrottleCount, uint64(2))
+}
+
+func Test_SetLimits(t *testing.T) {
+	assert.Equal(t, SetLimits(map[string]Limits{"unknown": {Queue: 1}}), ErrSubsystemUnknown)
+
+	assert.Equal(t, SetLimits(map[string]Limits{"pool": {Queue: 10}}), error(nil))
+	defer Pool.SetLimits(Limits{})
+	assert.Equal(t, Pool.Limits().Queue, int64(10))
+
+	names := []string{}
+	for _, stats := range AllStats() {
+		names = append(names, stats.Name)
+	}
+	assert.Equal(t, names[:5], []string{"miner", "p2p", "pool", "rpc", "sync"})
+}
@@ -8,6 +8,7 @@ package common
 import (
 	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/howeyc/gopass"
 )
@@ -43,3 +44,14 @@ func SetPassword() (string, error) {
 
 	return string(pass), nil
 }
+
+// Confirm asks user to confirm the prompt interactively, and returns true if "y" or "yes" is input
+func Confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+
+	var answer string
+	fmt.Scanln(&answer)
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
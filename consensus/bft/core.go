@@ -0,0 +1,515 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package bft
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/event"
+)
+
+const (
+	msgPreprepare  uint8 = iota // proposal of the block by the proposer of the round
+	msgPrepare                  // vote of the proposal
+	msgCommit                   // commit seal of the proposal once prepared by the quorum
+	msgRoundChange              // vote to move to the round on timeout
+)
+
+// maxFutureRounds is the maximum number of the rounds after the current round of which the messages
+// are buffered, so that a faulty validator could not exhaust the memory by the arbitrary rounds.
+const maxFutureRounds = 10
+
+var (
+	errMessageInvalid = errors.New("invalid bft message")
+	errNotStarted     = errors.New("bft engine not started")
+)
+
+// Message is the consensus message exchanged between the validators, of which the sender is
+// recovered from the signature.
+type Message struct {
+	Code      uint8
+	Height    uint64
+	Round     uint64
+	Digest    common.Hash       // Digest is the seal hash of the proposal, empty for the round change
+	Block     *types.Block      `rlp:"nil"` // Block is the proposal of the preprepare message
+	Seal      *crypto.Signature `rlp:"nil"` // Seal is the commit seal of the commit message
+	Signature *crypto.Signature // Signature is the signature of the sender
+}
+
+// messageKey identifies the message of a validator in the current height, of which only the first
+// one of each code and round is handled and relayed, so that the messages buffered for a validator
+// are bounded by the rounds.
+type messageKey struct {
+	sender common.Address
+	code   uint8
+	round  uint64
+}
+
+// hash returns the hash of the message signed by the sender.
+func (msg *Message) hash() common.Hash {
+	return crypto.MustHash([]interface{}{msg.Code, msg.Height, msg.Round, msg.Digest})
+}
+
+// proposal is the block to propose of the local validator, prepared by the miner.
+type proposal struct {
+	block  *types.Block
+	sealed chan *types.Block
+}
+
+// roundState is the state of the local validator in the round of the height.
+type roundState struct {
+	height    uint64
+	round     uint64
+	voted     uint64       // highest round voted to change to
+	proposal  *types.Block // proposal accepted in the round
+	locked    *types.Block // proposal prepared by the quorum, which is proposed again in the later rounds
+	sealed    *types.Block // proposal of the local validator with the commit seals, kept until taken by the miner
+	prepared  bool
+	committed bool
+
+	prepares     map[common.Address]common.Hash
+	commits      map[common.Address]*Message
+	roundChanges map[uint64]map[common.Address]bool
+	future       map[uint64][]*futureMessage // messages of the later rounds, handled once moved to the round
+	timer        *time.Timer
+}
+
+// futureMessage is the message of a later round received before moving to the round.
+type futureMessage struct {
+	msg    *Message
+	sender common.Address
+}
+
+// Start starts the consensus of the local validator with the broadcaster of the messages to the
+// other validators, which follows the HEAD block of the chain. It does nothing for the non-validators.
+func (engine *Engine) Start(chain blockchain, broadcaster Broadcaster) {
+	if engine.key == nil {
+		return
+	}
+
+	engine.lock.Lock()
+	engine.chain, engine.broadcaster = chain, broadcaster
+	engine.startHeight()
+	engine.lock.Unlock()
+
+	event.BlockInsertedEventManager.AddAsyncListener(engine.handleBlockInserted)
+}
+
+// Stop stops the consensus of the local validator.
+func (engine *Engine) Stop() {
+	if engine.key == nil {
+		return
+	}
+
+	event.BlockInsertedEventManager.RemoveListener(engine.handleBlockInserted)
+
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	if engine.state != nil {
+		engine.state.timer.Stop()
+		engine.state = nil
+	}
+}
+
+func (engine *Engine) handleBlockInserted(e event.Event) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	if engine.state == nil {
+		return
+	}
+
+	if block := e.(*types.Block); block.Header.Height >= engine.state.height {
+		engine.startHeight()
+	}
+}
+
+// startHeight starts the round 0 of the height next to the HEAD block.
+func (engine *Engine) startHeight() {
+	if engine.state != nil {
+		engine.state.timer.Stop()
+	}
+
+	head, _ := engine.chain.CurrentBlock()
+	engine.state = &roundState{
+		height:       head.Header.Height + 1,
+		roundChanges: make(map[uint64]map[common.Address]bool),
+		future:       make(map[uint64][]*futureMessage),
+	}
+	engine.seen = make(map[messageKey]bool)
+	engine.startRound(0)
+}
+
+// startRound starts the round of the current height, and proposes the block if the local validator
+// is the proposer of the round.
+func (engine *Engine) startRound(round uint64) {
+	state := engine.state
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+
+	state.round, state.proposal = round, nil
+	state.prepared, state.committed = false, false
+	state.prepares = make(map[common.Address]common.Hash)
+	state.commits = make(map[common.Address]*Message)
+	if state.voted < round {
+		state.voted = round
+	}
+
+	height := state.height
+	state.timer = time.AfterFunc(engine.config.requestTimeout(round), func() {
+		engine.handleTimeout(height, round, round+1)
+	})
+
+	engine.log.Debug("bft started round %d of height %d", round, height)
+	engine.propose()
+
+	// the votes of the passed rounds are not counted any more
+	for r := range state.roundChanges {
+		if r <= round {
+			delete(state.roundChanges, r)
+		}
+	}
+
+	// handle the messages of the round received before moving to it
+	for r, msgs := range state.future {
+		if r > round {
+			continue
+		}
+
+		delete(state.future, r)
+		if r == round {
+			for _, future := range msgs {
+				engine.handle(future.msg, future.sender)
+			}
+		}
+	}
+}
+
+// handleTimeout votes to change to the specified round, if the round of the height is not committed
+// in time. The round already voted by joining the others is voted again rather than skipped, so that
+// the validators timing out together do not split their votes.
+func (engine *Engine) handleTimeout(height, round, vote uint64) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	state := engine.state
+	if state == nil || state.height != height || state.round != round || state.committed {
+		return
+	}
+
+	if vote < state.voted {
+		vote = state.voted
+	}
+
+	// the votes beyond the rounds buffered by the others are dropped
+	if max := state.round + maxFutureRounds; vote > max {
+		vote = max
+	}
+
+	engine.voteRoundChange(vote)
+
+	// keep voting for the higher rounds until the quorum agree on one
+	if engine.state == state && state.round == round {
+		state.timer = time.AfterFunc(engine.config.requestTimeout(vote), func() {
+			engine.handleTimeout(height, round, vote+1)
+		})
+	}
+}
+
+func (engine *Engine) voteRoundChange(round uint64) {
+	if round > engine.state.voted {
+		engine.state.voted = round
+	}
+
+	engine.send(&Message{Code: msgRoundChange, Height: engine.state.height, Round: round})
+}
+
+// setPending sets the block to propose prepared by the miner.
+func (engine *Engine) setPending(pending *proposal) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	engine.pending = pending
+	if engine.state != nil {
+		engine.propose()
+		engine.deliver()
+	}
+}
+
+func (engine *Engine) clearPending(pending *proposal) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	if engine.pending == pending {
+		engine.pending = nil
+	}
+}
+
+// propose proposes the locked block or the block prepared by the miner, if the local validator is
+// the proposer of the current round.
+func (engine *Engine) propose() {
+	state := engine.state
+	if state.proposal != nil || engine.proposer(state.height, state.round) != engine.address {
+		return
+	}
+
+	block := state.locked
+	if block == nil && engine.pending != nil && engine.pending.block.Header.Height == state.height {
+		block = engine.pending.block
+	}
+
+	if block == nil {
+		return
+	}
+
+	engine.send(&Message{Code: msgPreprepare, Height: state.height, Round: state.round, Digest: sealHash(block.Header), Block: block})
+}
+
+// send signs and broadcasts the message to the other validators, and handles it locally.
+func (engine *Engine) send(msg *Message) {
+	if msg.Code == msgCommit {
+		msg.Seal = crypto.NewSignature(engine.key, msg.Digest.Bytes())
+	}
+
+	msg.Signature = crypto.NewSignature(engine.key, msg.hash().Bytes())
+	payload, err := rlp.EncodeToBytes(msg)
+	if err != nil {
+		engine.log.Warn("failed to encode bft message, %s", err)
+		return
+	}
+
+	engine.seen[messageKey{engine.address, msg.Code, msg.Round}] = true
+	engine.broadcaster.Broadcast(payload)
+	engine.handle(msg, engine.address)
+}
+
+// HandleMessage handles the consensus message from the peers, and returns whether the message is
+// new and should be relayed to the other peers.
+func (engine *Engine) HandleMessage(payload []byte) (bool, error) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	if engine.state == nil {
+		return false, errNotStarted
+	}
+
+	msg := new(Message)
+	if err := rlp.DecodeBytes(payload, msg); err != nil || msg.Signature == nil {
+		return false, errMessageInvalid
+	}
+
+	sender, err := msg.Signature.RecoverAddress(msg.hash().Bytes())
+	if err != nil {
+		return false, err
+	}
+
+	if !engine.validators[sender] {
+		return false, ErrUnauthorizedValidator
+	}
+
+	// the messages of the other heights or too far rounds are not relayed
+	if msg.Height != engine.state.height || msg.Round > engine.state.round+maxFutureRounds {
+		return false, nil
+	}
+
+	key := messageKey{sender, msg.Code, msg.Round}
+	if engine.seen[key] {
+		return false, nil
+	}
+
+	engine.seen[key] = true
+	engine.handle(msg, sender)
+	return true, nil
+}
+
+// handle handles the message of the current height from the validator.
+func (engine *Engine) handle(msg *Message, sender common.Address) {
+	state := engine.state
+
+	if msg.Code == msgRoundChange {
+		engine.handleRoundChange(msg, sender)
+		return
+	}
+
+	if msg.Round > state.round {
+		state.future[msg.Round] = append(state.future[msg.Round], &futureMessage{msg, sender})
+		return
+	}
+
+	if msg.Round < state.round {
+		return
+	}
+
+	switch msg.Code {
+	case msgPreprepare:
+		engine.handlePreprepare(msg, sender)
+	case msgPrepare:
+		state.prepares[sender] = msg.Digest
+		engine.checkPrepared()
+	case msgCommit:
+		if msg.Seal == nil {
+			return
+		}
+
+		if signer, err := msg.Seal.RecoverAddress(msg.Digest.Bytes()); err != nil || !signer.Equal(sender) {
+			return
+		}
+
+		state.commits[sender] = msg
+		engine.checkCommitted()
+	}
+}
+
+func (engine *Engine) handlePreprepare(msg *Message, sender common.Address) {
+	state := engine.state
+	if state.proposal != nil || msg.Block == nil || engine.proposer(state.height, state.round) != sender {
+		return
+	}
+
+	header := msg.Block.Header
+	if header.Height != state.height || !sealHash(header).Equal(msg.Digest) || !msg.Block.HeaderHash.Equal(header.Hash()) {
+		return
+	}
+
+	// the validators locked on a proposal only accept the same one in the later rounds
+	if state.locked != nil && !sealHash(state.locked.Header).Equal(msg.Digest) {
+		return
+	}
+
+	head, _ := engine.chain.CurrentBlock()
+	if !header.PreviousBlockHash.Equal(head.HeaderHash) {
+		return
+	}
+
+	if err := engine.verifyProposal(header, head.Header); err != nil {
+		engine.log.Debug("bft rejected proposal of height %d from %s, %s", state.height, sender.ToHex(), err)
+		return
+	}
+
+	state.proposal = msg.Block
+	engine.send(&Message{Code: msgPrepare, Height: state.height, Round: state.round, Digest: msg.Digest})
+	engine.checkPrepared()
+	engine.checkCommitted()
+}
+
+// checkPrepared locks the proposal and broadcasts the commit seal once the quorum prepare it.
+func (engine *Engine) checkPrepared() {
+	state := engine.state
+	if state.proposal == nil || state.prepared {
+		return
+	}
+
+	digest := sealHash(state.proposal.Header)
+	if countVotes(state.prepares, digest) < engine.quorum() {
+		return
+	}
+
+	state.prepared, state.locked = true, state.proposal
+	engine.send(&Message{Code: msgCommit, Height: state.height, Round: state.round, Digest: digest})
+}
+
+// checkCommitted seals the proposal of the local validator once the quorum commit it.
+func (engine *Engine) checkCommitted() {
+	state := engine.state
+	if state.proposal == nil || state.committed {
+		return
+	}
+
+	digest := sealHash(state.proposal.Header)
+	extra := &extra{Round: state.round}
+	for committer, msg := range state.commits {
+		if msg.Digest.Equal(digest) {
+			extra.Committers = append(extra.Committers, committer)
+			extra.Seals = append(extra.Seals, msg.Seal)
+		}
+	}
+
+	if len(extra.Committers) < engine.quorum() {
+		return
+	}
+
+	state.committed = true
+	state.timer.Stop()
+
+	if engine.proposer(state.height, state.round) != engine.address || engine.pending == nil {
+		return
+	}
+
+	encoded, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		engine.log.Warn("failed to encode bft commit seals, %s", err)
+		return
+	}
+
+	header := state.proposal.Header.Clone()
+	header.Extensions = []rlp.RawValue{encoded}
+	state.sealed = &types.Block{
+		HeaderHash:   header.Hash(),
+		Header:       header,
+		Transactions: state.proposal.Transactions,
+	}
+
+	engine.deliver()
+}
+
+// deliver returns the sealed block to the pending Seal, which may be of another block of the same
+// height if the miner rebuilt the task after the proposal.
+func (engine *Engine) deliver() {
+	state := engine.state
+	if state.sealed == nil || engine.pending == nil || engine.pending.block.Header.Height != state.height {
+		return
+	}
+
+	select {
+	case engine.pending.sealed <- state.sealed:
+	default:
+	}
+}
+
+// handleRoundChange joins the round change once f+1 validators vote for it, i.e. at least one
+// honest validator timed out, and moves to the round once the quorum vote for it.
+func (engine *Engine) handleRoundChange(msg *Message, sender common.Address) {
+	state := engine.state
+	if msg.Round <= state.round {
+		return
+	}
+
+	votes := state.roundChanges[msg.Round]
+	if votes == nil {
+		votes = make(map[common.Address]bool)
+		state.roundChanges[msg.Round] = votes
+	}
+
+	votes[sender] = true
+
+	if faulty := len(engine.config.Validators) - engine.quorum(); len(votes) > faulty && !votes[engine.address] && !state.committed {
+		engine.voteRoundChange(msg.Round)
+		return
+	}
+
+	if len(votes) >= engine.quorum() {
+		engine.startRound(msg.Round)
+	}
+}
+
+// countVotes returns the number of the votes of the digest.
+func countVotes(votes map[common.Address]common.Hash, digest common.Hash) int {
+	count := 0
+	for _, voted := range votes {
+		if voted.Equal(digest) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,345 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+// Package bft implements a BFT consensus in the style of IBFT for the permissioned chains, in
+// which the validators of genesis take turns to propose the blocks, and a block is committed
+// once more than 2/3 of the validators sign it in the three-phase protocol. The commit seals
+// are kept in the header extension, so that the blocks are final once written.
+package bft
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// Algorithm is the name of the BFT consensus in the configs.
+const Algorithm = "bft"
+
+const (
+	// DefaultBlockPeriod is the default min interval in seconds between blocks.
+	DefaultBlockPeriod = 1
+
+	// DefaultRequestTimeout is the default timeout in seconds of the first round of a height.
+	DefaultRequestTimeout = 10
+
+	// maxFutureBlockTime is the max seconds the block timestamp could be ahead of the local clock.
+	maxFutureBlockTime = pow.MaxFutureBlockTime
+)
+
+var (
+	// ErrExtraInvalid is returned when the header extension of the commit seals is missing or malformed.
+	ErrExtraInvalid = errors.New("invalid bft header extension")
+
+	// ErrSealsInsufficient is returned when the block is signed by less than the quorum of the validators.
+	ErrSealsInsufficient = errors.New("insufficient commit seals of the validators")
+
+	// ErrUnauthorizedValidator is returned when the seal or message is signed by a non-validator.
+	ErrUnauthorizedValidator = errors.New("unauthorized validator")
+
+	errConfigInvalid          = errors.New("invalid bft config, the validators should be specified without duplicates")
+	errBlockTimestampInvalid  = errors.New("block timestamp earlier than the block period since the parent")
+	errBlockTimestampFuture   = errors.New("block timestamp too far in the future")
+	errBlockDifficultyInvalid = errors.New("invalid bft block difficulty")
+	errOmmersNotAllowed       = errors.New("ommers not allowed in bft blocks")
+)
+
+// Config is the config of the consensus, which should be the same across the network.
+type Config struct {
+	Validators     []common.Address // Validators are the accounts to propose and commit the blocks
+	BlockPeriod    uint64           // BlockPeriod is the min interval in seconds between blocks, DefaultBlockPeriod if 0
+	RequestTimeout uint64           // RequestTimeout is the timeout in seconds of the first round, doubled in each round change, DefaultRequestTimeout if 0
+}
+
+// Validate validates the config.
+func (config *Config) Validate() error {
+	if len(config.Validators) == 0 {
+		return errConfigInvalid
+	}
+
+	seen := make(map[common.Address]bool)
+	for _, validator := range config.Validators {
+		if seen[validator] {
+			return errConfigInvalid
+		}
+
+		seen[validator] = true
+	}
+
+	return nil
+}
+
+// blockPeriod returns the min interval in seconds between blocks.
+func (config *Config) blockPeriod() uint64 {
+	if config.BlockPeriod == 0 {
+		return DefaultBlockPeriod
+	}
+
+	return config.BlockPeriod
+}
+
+// requestTimeout returns the timeout of the round, which is doubled in each round change.
+func (config *Config) requestTimeout(round uint64) time.Duration {
+	timeout := time.Duration(config.RequestTimeout) * time.Second
+	if timeout == 0 {
+		timeout = DefaultRequestTimeout * time.Second
+	}
+
+	if round > 6 {
+		round = 6
+	}
+
+	return timeout << round
+}
+
+// extra is the header extension of the committed block.
+type extra struct {
+	Round      uint64              // Round is the round in which the block is committed
+	Committers []common.Address    // Committers are the validators of the seals
+	Seals      []*crypto.Signature // Seals are the signatures of the seal hash by the committers
+}
+
+// sealHash returns the hash of the header without the commit seals, which is signed by the validators.
+func sealHash(header *types.BlockHeader) common.Hash {
+	header = header.Clone()
+	header.Extensions = nil
+	return header.Hash()
+}
+
+// decodeExtra decodes the commit seals in the header extension.
+func decodeExtra(header *types.BlockHeader) (*extra, error) {
+	if len(header.Extensions) != 1 {
+		return nil, ErrExtraInvalid
+	}
+
+	decoded := new(extra)
+	if err := rlp.DecodeBytes(header.Extensions[0], decoded); err != nil || len(decoded.Committers) != len(decoded.Seals) {
+		return nil, ErrExtraInvalid
+	}
+
+	return decoded, nil
+}
+
+// Broadcaster broadcasts the consensus messages to the peers.
+type Broadcaster interface {
+	Broadcast(payload []byte)
+}
+
+// blockchain is the chain to follow the HEAD block.
+type blockchain interface {
+	CurrentBlock() (*types.Block, *state.Statedb)
+}
+
+// Engine is the consensus engine of BFT, which implements consensus.Engine. The validators run
+// the miner to propose the blocks in Seal, and exchange the consensus messages via HandleMessage
+// and the Broadcaster once started. The other nodes only verify the commit seals of the blocks.
+//
+// Note, the block is assembled with the commit seals by its proposer only, since the seals are
+// part of the block hash.
+type Engine struct {
+	config     *Config
+	reward     *pow.RewardConfig
+	validators map[common.Address]bool
+	key        *ecdsa.PrivateKey // key of the local validator, nil if not a validator
+	address    common.Address    // address of the local validator
+	log        *log.SeeleLog
+
+	lock        sync.Mutex
+	chain       blockchain
+	broadcaster Broadcaster
+	state       *roundState
+	pending     *proposal           // block to propose of the local validator, prepared by the miner
+	seen        map[messageKey]bool // messages handled in the current height
+}
+
+// NewEngine creates the BFT engine of the config, in which the key of the local validator is nil
+// for the non-validator nodes. The reward schedule is the default one if nil.
+func NewEngine(config *Config, reward *pow.RewardConfig, key *ecdsa.PrivateKey, log *log.SeeleLog) (*Engine, error) {
+	if config == nil {
+		return nil, errConfigInvalid
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if reward == nil {
+		reward = pow.DefaultRewardConfig()
+	}
+
+	engine := &Engine{
+		config:     config,
+		reward:     reward,
+		validators: make(map[common.Address]bool),
+		log:        log,
+		seen:       make(map[messageKey]bool),
+	}
+
+	for _, validator := range config.Validators {
+		engine.validators[validator] = true
+	}
+
+	if key != nil {
+		engine.address = *crypto.MustGetAddress(key)
+		if !engine.validators[engine.address] {
+			return nil, ErrUnauthorizedValidator
+		}
+
+		engine.key = key
+	}
+
+	return engine, nil
+}
+
+// quorum returns the number of the validators to commit a block, i.e. ceil(2n/3).
+func (engine *Engine) quorum() int {
+	return (2*len(engine.config.Validators) + 2) / 3
+}
+
+// proposer returns the validator to propose the block of the height in the round.
+func (engine *Engine) proposer(height, round uint64) common.Address {
+	validators := engine.config.Validators
+	return validators[(height+round)%uint64(len(validators))]
+}
+
+// Validators returns the validators of the chain.
+func (engine *Engine) Validators() []common.Address {
+	return append([]common.Address(nil), engine.config.Validators...)
+}
+
+// ExtendsHeader implements consensus.HeaderExtender, the commit seals are kept in the header extension.
+func (engine *Engine) ExtendsHeader() bool {
+	return true
+}
+
+// IsInstantFinal implements consensus.InstantFinality, the committed blocks are final.
+func (engine *Engine) IsInstantFinal() bool {
+	return true
+}
+
+// VerifyHeader implements consensus.Engine, which validates the timestamp and difficulty against
+// the parent, and the commit seals of the quorum of the validators.
+func (engine *Engine) VerifyHeader(header, parent *types.BlockHeader) error {
+	if err := engine.verifyProposal(header, parent); err != nil {
+		return err
+	}
+
+	extra, err := decodeExtra(header)
+	if err != nil {
+		return err
+	}
+
+	hash := sealHash(header)
+	committed := make(map[common.Address]bool)
+	for i, committer := range extra.Committers {
+		if !engine.validators[committer] || committed[committer] {
+			return ErrUnauthorizedValidator
+		}
+
+		if signer, err := extra.Seals[i].RecoverAddress(hash.Bytes()); err != nil || !signer.Equal(committer) {
+			return ErrUnauthorizedValidator
+		}
+
+		committed[committer] = true
+	}
+
+	if len(committed) < engine.quorum() {
+		return ErrSealsInsufficient
+	}
+
+	return nil
+}
+
+// verifyProposal validates the consensus fields of the header except the commit seals.
+func (engine *Engine) verifyProposal(header, parent *types.BlockHeader) error {
+	timestamp := header.CreateTimestamp.Uint64()
+	if timestamp < parent.CreateTimestamp.Uint64()+engine.config.blockPeriod() {
+		return errBlockTimestampInvalid
+	}
+
+	if timestamp > uint64(time.Now().Unix())+maxFutureBlockTime {
+		return errBlockTimestampFuture
+	}
+
+	if header.Difficulty == nil || header.Difficulty.Cmp(big.NewInt(1)) != 0 {
+		return errBlockDifficultyInvalid
+	}
+
+	if len(header.Ommers) > 0 {
+		return errOmmersNotAllowed
+	}
+
+	return nil
+}
+
+// Prepare implements consensus.Engine, which sets the difficulty to 1 and delays the timestamp
+// to the block period since the parent.
+func (engine *Engine) Prepare(header, parent *types.BlockHeader) error {
+	header.Difficulty = big.NewInt(1)
+	header.Ommers = nil
+
+	if earliest := parent.CreateTimestamp.Uint64() + engine.config.blockPeriod(); header.CreateTimestamp == nil || header.CreateTimestamp.Uint64() < earliest {
+		header.CreateTimestamp = new(big.Int).SetUint64(earliest)
+	}
+
+	return nil
+}
+
+// CalcDifficulty implements consensus.Engine, the difficulty of the BFT blocks is always 1, so
+// that the total difficulty is the height.
+func (engine *Engine) CalcDifficulty(timestamp uint64, parent *types.BlockHeader) *big.Int {
+	return big.NewInt(1)
+}
+
+// BlockReward implements consensus.Engine, which pays the proposer by the reward schedule.
+func (engine *Engine) BlockReward(height uint64) *big.Int {
+	return big.NewInt(engine.reward.GetReward(height))
+}
+
+// Finalize implements consensus.Engine, there is no reward beyond the reward tx.
+func (engine *Engine) Finalize(statedb *state.Statedb, header *types.BlockHeader) []*consensus.Reward {
+	return nil
+}
+
+// Seal implements consensus.Engine, which proposes the block once the local validator is the
+// proposer of the round, and returns the block with the commit seals once committed. It waits
+// until aborted on the other validators and non-validators.
+func (engine *Engine) Seal(block *types.Block, abort <-chan struct{}) (*types.Block, error) {
+	if engine.key == nil {
+		<-abort
+		return nil, nil
+	}
+
+	// the block is proposed no earlier than its timestamp
+	if delay := time.Until(time.Unix(block.Header.CreateTimestamp.Int64(), 0)); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-abort:
+			return nil, nil
+		}
+	}
+
+	pending := &proposal{block: block, sealed: make(chan *types.Block, 1)}
+	engine.setPending(pending)
+	defer engine.clearPending(pending)
+
+	select {
+	case sealed := <-pending.sealed:
+		return sealed, nil
+	case <-abort:
+		return nil, nil
+	}
+}
@@ -0,0 +1,245 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package bft
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/log"
+)
+
+// Engine implements consensus.Engine
+var _ consensus.Engine = &Engine{}
+
+type testChain struct {
+	head *types.Block
+}
+
+func (chain *testChain) CurrentBlock() (*types.Block, *state.Statedb) {
+	return chain.head, nil
+}
+
+// testNetwork delivers the broadcast messages to the other engines asynchronously.
+type testNetwork struct {
+	engines []*Engine
+}
+
+type testBroadcaster struct {
+	network *testNetwork
+	sender  *Engine
+}
+
+func (b *testBroadcaster) Broadcast(payload []byte) {
+	for _, engine := range b.network.engines {
+		if engine != b.sender {
+			go engine.HandleMessage(payload)
+		}
+	}
+}
+
+func newTestValidators(t *testing.T, n int) ([]common.Address, []*ecdsa.PrivateKey) {
+	addresses := make([]common.Address, n)
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		addr, key, err := crypto.GenerateKeyPair()
+		assert.Equal(t, err, error(nil))
+		addresses[i], keys[i] = *addr, key
+	}
+
+	return addresses, keys
+}
+
+// newTestNetwork creates the engines of the validators, and starts the online ones.
+func newTestNetwork(t *testing.T, config *Config, keys []*ecdsa.PrivateKey, online []bool, chain *testChain) *testNetwork {
+	network := &testNetwork{}
+	for _, key := range keys {
+		engine, err := NewEngine(config, nil, key, log.GetLogger("bft", false))
+		assert.Equal(t, err, error(nil))
+		network.engines = append(network.engines, engine)
+	}
+
+	for i, engine := range network.engines {
+		if online[i] {
+			engine.Start(chain, &testBroadcaster{network, engine})
+		}
+	}
+
+	return network
+}
+
+func (network *testNetwork) stop() {
+	for _, engine := range network.engines {
+		engine.Stop()
+	}
+}
+
+func newTestParent() *types.Block {
+	header := &types.BlockHeader{Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(time.Now().Unix() - 10)}
+	return types.NewBlock(header, nil)
+}
+
+func newTestProposal(t *testing.T, engine *Engine, parent *types.Block) *types.Block {
+	header := &types.BlockHeader{PreviousBlockHash: parent.HeaderHash, Height: parent.Header.Height + 1, CreateTimestamp: big.NewInt(0)}
+	assert.Equal(t, engine.Prepare(header, parent.Header), error(nil))
+	return types.NewBlock(header, nil)
+}
+
+func sealTestBlock(t *testing.T, engine *Engine, block *types.Block) *types.Block {
+	abort := make(chan struct{})
+	timer := time.AfterFunc(10*time.Second, func() { close(abort) })
+	defer timer.Stop()
+
+	sealed, err := engine.Seal(block, abort)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sealed != nil, true)
+	return sealed
+}
+
+func Test_Config_Validate(t *testing.T) {
+	addresses, _ := newTestValidators(t, 2)
+	assert.Equal(t, (&Config{}).Validate(), errConfigInvalid)
+	assert.Equal(t, (&Config{Validators: []common.Address{addresses[0], addresses[0]}}).Validate(), errConfigInvalid)
+	assert.Equal(t, (&Config{Validators: addresses}).Validate(), error(nil))
+
+	// the local key should be of a validator
+	_, key, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+	_, err = NewEngine(&Config{Validators: addresses}, nil, key, log.GetLogger("bft", false))
+	assert.Equal(t, err, ErrUnauthorizedValidator)
+}
+
+func Test_Engine_Quorum(t *testing.T) {
+	for n, quorum := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 6: 4, 7: 5} {
+		addresses, _ := newTestValidators(t, n)
+		engine, err := NewEngine(&Config{Validators: addresses}, nil, nil, log.GetLogger("bft", false))
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, engine.quorum(), quorum)
+	}
+}
+
+func Test_Engine_Commit(t *testing.T) {
+	addresses, keys := newTestValidators(t, 4)
+	config := &Config{Validators: addresses}
+	chain := &testChain{newTestParent()}
+	network := newTestNetwork(t, config, keys, []bool{true, true, true, true}, chain)
+	defer network.stop()
+
+	// validator 1 proposes the height 1 in round 0
+	proposer := network.engines[1]
+	sealed := sealTestBlock(t, proposer, newTestProposal(t, proposer, chain.head))
+	assert.Equal(t, sealed.HeaderHash, sealed.Header.Hash())
+
+	// the non-validators verify the commit seals
+	verifier, err := NewEngine(config, nil, nil, log.GetLogger("bft", false))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, verifier.VerifyHeader(sealed.Header, chain.head.Header), error(nil))
+
+	extra, err := decodeExtra(sealed.Header)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, extra.Round, uint64(0))
+	assert.Equal(t, len(extra.Committers) >= 3, true)
+
+	// less than the quorum
+	extra.Committers, extra.Seals = extra.Committers[:2], extra.Seals[:2]
+	encoded, err := rlp.EncodeToBytes(extra)
+	assert.Equal(t, err, error(nil))
+	header := sealed.Header.Clone()
+	header.Extensions = []rlp.RawValue{encoded}
+	assert.Equal(t, verifier.VerifyHeader(header, chain.head.Header), ErrSealsInsufficient)
+
+	// seal of the other block
+	header = sealed.Header.Clone()
+	header.Height++
+	assert.Equal(t, verifier.VerifyHeader(header, chain.head.Header), ErrUnauthorizedValidator)
+
+	// missing seals
+	header.Extensions = nil
+	assert.Equal(t, verifier.VerifyHeader(header, chain.head.Header), ErrExtraInvalid)
+}
+
+func Test_Engine_RoundChange(t *testing.T) {
+	addresses, keys := newTestValidators(t, 4)
+	config := &Config{Validators: addresses, RequestTimeout: 1}
+	chain := &testChain{newTestParent()}
+
+	// the proposer of round 0 is offline, so that validator 2 proposes in round 1
+	network := newTestNetwork(t, config, keys, []bool{true, false, true, true}, chain)
+	defer network.stop()
+
+	proposer := network.engines[2]
+	sealed := sealTestBlock(t, proposer, newTestProposal(t, proposer, chain.head))
+
+	extra, err := decodeExtra(sealed.Header)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, extra.Round, uint64(1))
+	assert.Equal(t, proposer.VerifyHeader(sealed.Header, chain.head.Header), error(nil))
+}
+
+func Test_Engine_VerifyProposal(t *testing.T) {
+	addresses, _ := newTestValidators(t, 1)
+	engine, err := NewEngine(&Config{Validators: addresses, BlockPeriod: 5}, nil, nil, log.GetLogger("bft", false))
+	assert.Equal(t, err, error(nil))
+
+	parent := &types.BlockHeader{Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(100)}
+	header := &types.BlockHeader{Height: 1, CreateTimestamp: big.NewInt(101)}
+	assert.Equal(t, engine.Prepare(header, parent), error(nil))
+	assert.Equal(t, header.CreateTimestamp.Uint64(), uint64(105))
+	assert.Equal(t, engine.verifyProposal(header, parent), error(nil))
+
+	header.CreateTimestamp = big.NewInt(104)
+	assert.Equal(t, engine.verifyProposal(header, parent), errBlockTimestampInvalid)
+
+	header.CreateTimestamp = big.NewInt(time.Now().Unix() + maxFutureBlockTime + 10)
+	assert.Equal(t, engine.verifyProposal(header, parent), errBlockTimestampFuture)
+
+	header.CreateTimestamp, header.Difficulty = big.NewInt(105), big.NewInt(2)
+	assert.Equal(t, engine.verifyProposal(header, parent), errBlockDifficultyInvalid)
+}
+
+func newTestMessage(t *testing.T, key *ecdsa.PrivateKey, msg *Message) []byte {
+	msg.Signature = crypto.NewSignature(key, msg.hash().Bytes())
+	payload, err := rlp.EncodeToBytes(msg)
+	assert.Equal(t, err, error(nil))
+	return payload
+}
+
+func Test_Engine_FutureMessages(t *testing.T) {
+	addresses, keys := newTestValidators(t, 4)
+	chain := &testChain{newTestParent()}
+	network := newTestNetwork(t, &Config{Validators: addresses}, keys, []bool{true, false, false, false}, chain)
+	defer network.stop()
+
+	engine := network.engines[0]
+	handle := func(code uint8, round uint64, digest common.Hash) bool {
+		payload := newTestMessage(t, keys[1], &Message{Code: code, Height: 1, Round: round, Digest: digest})
+		relayed, err := engine.HandleMessage(payload)
+		assert.Equal(t, err, error(nil))
+		return relayed
+	}
+
+	// buffered once per sender, code and round
+	assert.Equal(t, handle(msgPrepare, 2, common.StringToHash("a")), true)
+	assert.Equal(t, handle(msgPrepare, 2, common.StringToHash("b")), false)
+	assert.Equal(t, handle(msgCommit, 2, common.StringToHash("a")), true)
+	assert.Equal(t, len(engine.state.future[2]), 2)
+
+	// the rounds too far are dropped
+	assert.Equal(t, handle(msgPrepare, maxFutureRounds+1, common.StringToHash("a")), false)
+	assert.Equal(t, handle(msgRoundChange, maxFutureRounds+1, common.EmptyHash), false)
+	assert.Equal(t, handle(msgRoundChange, maxFutureRounds, common.EmptyHash), true)
+	assert.Equal(t, len(engine.state.future), 1)
+	assert.Equal(t, len(engine.state.roundChanges), 1)
+}
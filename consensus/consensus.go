@@ -0,0 +1,101 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package consensus
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// ErrHeaderNotLinked is returned when the headers to verify in batch are not a chain in ascending order.
+var ErrHeaderNotLinked = errors.New("headers not linked to the parents")
+
+// Engine is the consensus engine to verify, prepare and seal the blocks. The blockchain and
+// the miner only depend on this interface, so that the alternative engines, e.g. PoA for the
+// private networks or hybrid PoS, could be implemented without touching them. The default
+// POW engine is implemented in package miner/pow.
+type Engine interface {
+	// VerifyHeader verifies the consensus fields of the header against its parent, e.g. the
+	// timestamp, difficulty and nonce.
+	VerifyHeader(header, parent *types.BlockHeader) error
+
+	// Prepare initializes the consensus fields of the new header upon its parent, e.g. the
+	// difficulty, before the txs are applied.
+	Prepare(header, parent *types.BlockHeader) error
+
+	// Seal seals the block, e.g. searches the nonce, and returns the sealed block until done
+	// or the abort channel is closed, in which case nil is returned.
+	Seal(block *types.Block, abort <-chan struct{}) (*types.Block, error)
+
+	// CalcDifficulty returns the difficulty of the block created at the specified timestamp
+	// upon the parent block.
+	CalcDifficulty(timestamp uint64, parent *types.BlockHeader) *big.Int
+
+	// BlockReward returns the amount of the reward tx paid to the creator of the block at
+	// the specified height.
+	BlockReward(height uint64) *big.Int
+
+	// Finalize applies the rewards of the block beyond the reward tx to the state, e.g. the
+	// ommer rewards, before the txs of the block are applied, and returns the rewards applied.
+	Finalize(statedb *state.Statedb, header *types.BlockHeader) []*Reward
+}
+
+// Reward is the amount rewarded to the account by the consensus engine.
+type Reward struct {
+	To     common.Address
+	Amount *big.Int
+}
+
+// HeaderExtender is the optional interface of the Engine that keeps its consensus data in the
+// header extensions, e.g. the commit seals of the BFT validators. The extensions are verified
+// by the engine in VerifyHeader instead of being rejected as the fields of a newer version.
+type HeaderExtender interface {
+	ExtendsHeader() bool
+}
+
+// InstantFinality is the optional interface of the Engine whose blocks are final once written,
+// e.g. BFT, so that the blocks lower than the HEAD block could not be reorganized.
+type InstantFinality interface {
+	IsInstantFinal() bool
+}
+
+// BatchVerifier is the optional interface of the Engine that verifies a chain of headers in one
+// pass, e.g. the headers downloaded in sync, faster than VerifyHeader of each header.
+type BatchVerifier interface {
+	// VerifyHeaders verifies the headers in ascending order against their parents, in which the
+	// first header is the verified parent of the second one, and the headers should be linked by
+	// the previous block hash and height.
+	VerifyHeaders(headers []*types.BlockHeader) error
+}
+
+// VerifyHeaders verifies the chain of headers in which the first header is the verified parent,
+// in batch if the engine implements BatchVerifier, or by VerifyHeader of each header.
+func VerifyHeaders(engine Engine, headers []*types.BlockHeader) error {
+	if verifier, ok := engine.(BatchVerifier); ok {
+		return verifier.VerifyHeaders(headers)
+	}
+
+	for i := 1; i < len(headers); i++ {
+		if !Linked(headers[i], headers[i-1], headers[i-1].Hash()) {
+			return ErrHeaderNotLinked
+		}
+
+		if err := engine.VerifyHeader(headers[i], headers[i-1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Linked returns whether the header is the child of the parent of the specified hash.
+func Linked(header, parent *types.BlockHeader, parentHash common.Hash) bool {
+	return header.Height == parent.Height+1 && header.PreviousBlockHash.Equal(parentHash)
+}
@@ -0,0 +1,207 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package memhash
+
+import (
+	"encoding/binary"
+	"runtime"
+	"sync"
+
+	"github.com/seeleteam/go-seele/crypto/sha3"
+)
+
+const (
+	hashBytes      = 64  // hashBytes is the size of the keccak512 hash, i.e. a cache or dataset item
+	hashWords      = 16  // hashWords is the number of 32 bit words of a hash
+	mixBytes       = 128 // mixBytes is the width of the mix, i.e. 2 dataset items
+	mixWords       = 32  // mixWords is the number of 32 bit words of the mix
+	cacheRounds    = 3   // cacheRounds is the number of the RandMemoHash rounds to generate the cache
+	datasetParents = 256 // datasetParents is the number of the cache items to generate a dataset item
+	loopAccesses   = 64  // loopAccesses is the number of the dataset accesses per hash
+)
+
+// keccak512 returns the keccak512 hash of the data.
+func keccak512(data ...[]byte) []byte {
+	hasher := sha3.NewKeccak512()
+	for _, b := range data {
+		hasher.Write(b)
+	}
+
+	return hasher.Sum(nil)
+}
+
+// keccak256 returns the keccak256 hash of the data.
+func keccak256(data ...[]byte) []byte {
+	hasher := sha3.NewKeccak256()
+	for _, b := range data {
+		hasher.Write(b)
+	}
+
+	return hasher.Sum(nil)
+}
+
+// fnv is the non-associative mixing function of FNV-1, which replaces the XOR in the dataset
+// generation and accesses.
+func fnv(a, b uint32) uint32 {
+	return a*0x01000193 ^ b
+}
+
+// fnvHash mixes the data into the mix in place.
+func fnvHash(mix []uint32, data []uint32) {
+	for i := 0; i < len(mix); i++ {
+		mix[i] = mix[i]*0x01000193 ^ data[i]
+	}
+}
+
+// toWords decodes the bytes into the little-endian 32 bit words.
+func toWords(dest []uint32, src []byte) {
+	for i := range dest {
+		dest[i] = binary.LittleEndian.Uint32(src[i*4:])
+	}
+}
+
+// toBytes encodes the 32 bit words into the little-endian bytes.
+func toBytes(src []uint32) []byte {
+	dest := make([]byte, len(src)*4)
+	for i, word := range src {
+		binary.LittleEndian.PutUint32(dest[i*4:], word)
+	}
+
+	return dest
+}
+
+// seedHash returns the seed of the epoch, which is hashed from the zero hash for each epoch.
+func seedHash(epoch uint64) []byte {
+	seed := make([]byte, 32)
+	for i := uint64(0); i < epoch; i++ {
+		seed = keccak256(seed)
+	}
+
+	return seed
+}
+
+// generateCache generates the verification cache of the size in bytes from the seed, which is
+// a sequential hash chain followed by the RandMemoHash rounds, so that it could not be generated
+// with less memory.
+func generateCache(size uint64, seed []byte) []uint32 {
+	rows := int(size / hashBytes)
+	cache := make([]byte, rows*hashBytes)
+
+	copy(cache, keccak512(seed))
+	for i := 1; i < rows; i++ {
+		copy(cache[i*hashBytes:], keccak512(cache[(i-1)*hashBytes:i*hashBytes]))
+	}
+
+	xored := make([]byte, hashBytes)
+	for round := 0; round < cacheRounds; round++ {
+		for i := 0; i < rows; i++ {
+			src := (i - 1 + rows) % rows
+			dst := i * hashBytes
+			other := int(binary.LittleEndian.Uint32(cache[dst:])%uint32(rows)) * hashBytes
+
+			for k := 0; k < hashBytes; k++ {
+				xored[k] = cache[src*hashBytes+k] ^ cache[other+k]
+			}
+
+			copy(cache[dst:], keccak512(xored))
+		}
+	}
+
+	words := make([]uint32, len(cache)/4)
+	toWords(words, cache)
+	return words
+}
+
+// datasetItem generates the dataset item of the index from the cache, which mixes
+// datasetParents pseudo-random cache items.
+func datasetItem(cache []uint32, index uint32) []uint32 {
+	rows := uint32(len(cache) / hashWords)
+
+	mix := make([]uint32, hashWords)
+	copy(mix, cache[(index%rows)*hashWords:])
+	mix[0] ^= index
+	toWords(mix, keccak512(toBytes(mix)))
+
+	for j := uint32(0); j < datasetParents; j++ {
+		parent := fnv(index^j, mix[j%hashWords]) % rows
+		fnvHash(mix, cache[parent*hashWords:(parent+1)*hashWords])
+	}
+
+	toWords(mix, keccak512(toBytes(mix)))
+	return mix
+}
+
+// generateDataset generates the full dataset of the size in bytes from the cache concurrently,
+// which is only required by the miners.
+func generateDataset(size uint64, cache []uint32) []uint32 {
+	rows := uint32(size / hashBytes)
+	dataset := make([]uint32, uint64(rows)*hashWords)
+
+	threads := uint32(runtime.NumCPU())
+	var wg sync.WaitGroup
+	for t := uint32(0); t < threads; t++ {
+		wg.Add(1)
+		go func(first uint32) {
+			defer wg.Done()
+
+			for index := first; index < rows; index += threads {
+				copy(dataset[index*hashWords:], datasetItem(cache, index))
+			}
+		}(t)
+	}
+
+	wg.Wait()
+	return dataset
+}
+
+// hashimoto hashes the seal hash and nonce with loopAccesses pseudo-random dataset accesses of
+// the size in bytes, which are read via the lookup of the dataset item index. It returns the
+// digest of the mix and the result to compare against the mining target.
+func hashimoto(sealHash []byte, nonce uint64, size uint64, lookup func(index uint32) []uint32) ([]byte, []byte) {
+	rows := uint32(size / mixBytes)
+
+	nonceBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(nonceBytes, nonce)
+	seed := keccak512(sealHash, nonceBytes)
+	seedHead := binary.LittleEndian.Uint32(seed)
+
+	mix := make([]uint32, mixWords)
+	toWords(mix[:hashWords], seed)
+	copy(mix[hashWords:], mix[:hashWords])
+
+	temp := make([]uint32, mixWords)
+	for i := 0; i < loopAccesses; i++ {
+		parent := fnv(uint32(i)^seedHead, mix[i%mixWords]) % rows
+		for j := uint32(0); j < mixBytes/hashBytes; j++ {
+			copy(temp[j*hashWords:], lookup(2*parent+j))
+		}
+
+		fnvHash(mix, temp)
+	}
+
+	// compress the mix into 8 words
+	for i := 0; i < mixWords; i += 4 {
+		mix[i/4] = fnv(fnv(fnv(mix[i], mix[i+1]), mix[i+2]), mix[i+3])
+	}
+
+	digest := toBytes(mix[:mixWords/4])
+	return digest, keccak256(seed, digest)
+}
+
+// hashimotoLight hashes with the dataset items generated from the cache on the fly, which is
+// used to verify the headers without the full dataset.
+func hashimotoLight(size uint64, cache []uint32, sealHash []byte, nonce uint64) ([]byte, []byte) {
+	return hashimoto(sealHash, nonce, size, func(index uint32) []uint32 {
+		return datasetItem(cache, index)
+	})
+}
+
+// hashimotoFull hashes with the full dataset, which is used to seal the blocks.
+func hashimotoFull(dataset []uint32, sealHash []byte, nonce uint64) ([]byte, []byte) {
+	return hashimoto(sealHash, nonce, uint64(len(dataset))*4, func(index uint32) []uint32 {
+		return dataset[index*hashWords : (index+1)*hashWords]
+	})
+}
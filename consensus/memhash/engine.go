@@ -0,0 +1,228 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+// Package memhash implements a memory-hard POW algorithm in the style of ethash, in which each
+// hash reads the pseudo-random items of a large dataset (DAG) regenerated per epoch, so that
+// the hashrate is bounded by the memory bandwidth rather than the hashing circuits.
+package memhash
+
+import (
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// Algorithm is the name of the memory-hard POW algorithm in the configs.
+const Algorithm = "memhash"
+
+const (
+	// DefaultEpochLength is the number of blocks of an epoch, after which the dataset is regenerated.
+	DefaultEpochLength = 30000
+
+	// DefaultCacheSize is the size in bytes of the verification cache of an epoch.
+	DefaultCacheSize = 16 * 1024 * 1024
+
+	// DefaultDatasetSize is the size in bytes of the full dataset of an epoch to mine.
+	DefaultDatasetSize = 1024 * 1024 * 1024
+)
+
+var (
+	// ErrNonceInvalid is returned when the memory-hard hash of the header does not meet the target.
+	ErrNonceInvalid = errors.New("invalid memhash nonce")
+
+	errConfigInvalid = errors.New("invalid memhash config, the cache size should be a positive multiple of 64 bytes, " +
+		"and the dataset size a multiple of 128 bytes no less than the cache size")
+)
+
+// Config is the config of the algorithm, which should be the same across the network.
+type Config struct {
+	EpochLength uint64 // EpochLength is the number of blocks of an epoch, DefaultEpochLength if 0
+	CacheSize   uint64 // CacheSize is the size in bytes of the verification cache, DefaultCacheSize if 0
+	DatasetSize uint64 // DatasetSize is the size in bytes of the dataset to mine, DefaultDatasetSize if 0
+}
+
+// DefaultConfig returns the default config of the algorithm.
+func DefaultConfig() *Config {
+	return &Config{
+		EpochLength: DefaultEpochLength,
+		CacheSize:   DefaultCacheSize,
+		DatasetSize: DefaultDatasetSize,
+	}
+}
+
+// withDefaults returns the copy of the config in which the unspecified fields are defaulted.
+func (config Config) withDefaults() *Config {
+	if config.EpochLength == 0 {
+		config.EpochLength = DefaultEpochLength
+	}
+
+	if config.CacheSize == 0 {
+		config.CacheSize = DefaultCacheSize
+	}
+
+	if config.DatasetSize == 0 {
+		config.DatasetSize = DefaultDatasetSize
+	}
+
+	return &config
+}
+
+// Validate validates the config, in which the unspecified fields are defaulted.
+func (config Config) Validate() error {
+	c := config.withDefaults()
+	if c.CacheSize%hashBytes != 0 || c.DatasetSize%mixBytes != 0 || c.DatasetSize < c.CacheSize {
+		return errConfigInvalid
+	}
+
+	return nil
+}
+
+// Engine is the consensus engine of the memory-hard POW, in which the difficulty adjustment,
+// the rewards and the ommers are the same as the POW engine. The headers are verified with
+// the cache of the epoch, while the blocks are sealed with the full dataset, both of which are
+// generated on demand and cached for the recent epochs.
+//
+// Note, the blocks are sealed by the engine instead of the mining threads of the miner, and
+// the external miners and the miner engine plugins only support the POW engine.
+type Engine struct {
+	*pow.Engine
+	config *Config
+	log    *log.SeeleLog
+
+	lock     sync.Mutex
+	caches   map[uint64][]uint32 // caches are the verification caches of the recent epochs
+	datasets map[uint64][]uint32 // datasets are the full datasets of the recent epochs
+}
+
+// NewEngine creates the memory-hard POW engine of the configs, the default config if nil.
+func NewEngine(difficulty *pow.DifficultyConfig, reward *pow.RewardConfig, config *Config, log *log.SeeleLog) (*Engine, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		Engine:   pow.NewEngine(difficulty, reward),
+		config:   config.withDefaults(),
+		log:      log,
+		caches:   make(map[uint64][]uint32),
+		datasets: make(map[uint64][]uint32),
+	}, nil
+}
+
+// epoch returns the epoch of the block height.
+func (engine *Engine) epoch(height uint64) uint64 {
+	return height / engine.config.EpochLength
+}
+
+// cache returns the verification cache of the epoch, which is generated if not cached. Only the
+// caches of the epoch and the previous one are kept for the blocks around the epoch change.
+func (engine *Engine) cache(epoch uint64) []uint32 {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	if cache := engine.caches[epoch]; cache != nil {
+		return cache
+	}
+
+	for cached := range engine.caches {
+		if cached+1 < epoch || cached > epoch+1 {
+			delete(engine.caches, cached)
+		}
+	}
+
+	cache := generateCache(engine.config.CacheSize, seedHash(epoch))
+	engine.caches[epoch] = cache
+	return cache
+}
+
+// dataset returns the full dataset of the epoch, which is generated if not cached. Only the
+// dataset of the latest epoch is kept since it is large.
+func (engine *Engine) dataset(epoch uint64) []uint32 {
+	cache := engine.cache(epoch)
+
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+
+	if dataset := engine.datasets[epoch]; dataset != nil {
+		return dataset
+	}
+
+	engine.log.Info("generating the memhash dataset of epoch %d, %d bytes", epoch, engine.config.DatasetSize)
+
+	engine.datasets = map[uint64][]uint32{epoch: generateDataset(engine.config.DatasetSize, cache)}
+	return engine.datasets[epoch]
+}
+
+// sealHash returns the hash of the header without the nonce, which is hashed with the nonce.
+func sealHash(header *types.BlockHeader) common.Hash {
+	header = header.Clone()
+	header.Nonce = 0
+	return header.Hash()
+}
+
+// VerifyHeader implements consensus.Engine, which validates the timestamp and difficulty against
+// the parent, and the memory-hard hash of the nonce with the verification cache.
+func (engine *Engine) VerifyHeader(header, parent *types.BlockHeader) error {
+	if err := engine.ValidateDifficulty(header, parent); err != nil {
+		return err
+	}
+
+	cache := engine.cache(engine.epoch(header.Height))
+	_, result := hashimotoLight(engine.config.DatasetSize, cache, sealHash(header).Bytes(), header.Nonce)
+	if new(big.Int).SetBytes(result).Cmp(pow.GetMiningTarget(header.Difficulty)) > 0 {
+		return ErrNonceInvalid
+	}
+
+	return nil
+}
+
+// Seal implements consensus.Engine, which searches the nonce with the full dataset of the epoch
+// in the goroutines of the CPU number. The dataset is generated at the first block of the epoch.
+func (engine *Engine) Seal(block *types.Block, abort <-chan struct{}) (*types.Block, error) {
+	dataset := engine.dataset(engine.epoch(block.Header.Height))
+	hash := sealHash(block.Header).Bytes()
+	target := pow.GetMiningTarget(block.Header.Difficulty)
+
+	threads := uint64(runtime.NumCPU())
+	found := make(chan uint64, threads)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for t := uint64(0); t < threads; t++ {
+		go func(nonce uint64) {
+			for ; ; nonce += threads {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if _, result := hashimotoFull(dataset, hash, nonce); new(big.Int).SetBytes(result).Cmp(target) <= 0 {
+					found <- nonce
+					return
+				}
+			}
+		}(t)
+	}
+
+	select {
+	case nonce := <-found:
+		header := block.Header.Clone()
+		header.Nonce = nonce
+		return &types.Block{HeaderHash: header.Hash(), Header: header, Transactions: block.Transactions}, nil
+	case <-abort:
+		return nil, nil
+	}
+}
@@ -0,0 +1,96 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package memhash
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// Engine implements consensus.Engine
+var _ consensus.Engine = &Engine{}
+
+func newTestEngine(t *testing.T) *Engine {
+	config := &Config{EpochLength: 10, CacheSize: 1024, DatasetSize: 8192}
+	engine, err := NewEngine(nil, nil, config, log.GetLogger("memhash", false))
+	assert.Equal(t, err, error(nil))
+	return engine
+}
+
+func Test_Hashimoto_LightAndFull(t *testing.T) {
+	cache := generateCache(1024, seedHash(1))
+	dataset := generateDataset(8192, cache)
+	assert.Equal(t, len(dataset), 8192/4)
+
+	hash := bytes.Repeat([]byte{1}, 32)
+	for nonce := uint64(0); nonce < 10; nonce++ {
+		lightDigest, lightResult := hashimotoLight(8192, cache, hash, nonce)
+		fullDigest, fullResult := hashimotoFull(dataset, hash, nonce)
+		assert.Equal(t, lightDigest, fullDigest)
+		assert.Equal(t, lightResult, fullResult)
+	}
+
+	// the results of the other epochs differ
+	_, result := hashimotoLight(8192, cache, hash, 0)
+	_, other := hashimotoLight(8192, generateCache(1024, seedHash(2)), hash, 0)
+	assert.Equal(t, bytes.Equal(result, other), false)
+}
+
+func Test_Engine_SealAndVerify(t *testing.T) {
+	engine := newTestEngine(t)
+	parent := &types.BlockHeader{Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(100)}
+	header := &types.BlockHeader{Height: 15, CreateTimestamp: big.NewInt(101)}
+	assert.Equal(t, engine.Prepare(header, parent), error(nil))
+
+	header.Difficulty = big.NewInt(16)
+	sealed, err := engine.Seal(types.NewBlock(header, nil), make(chan struct{}))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sealed.HeaderHash, sealed.Header.Hash())
+	assert.Equal(t, engine.VerifyHeader(sealed.Header, parent), error(nil))
+	assert.Equal(t, len(engine.datasets[1]) > 0, true)
+
+	// the nonce is unlikely to meet the max difficulty
+	sealed.Header.Difficulty = new(big.Int).Lsh(big.NewInt(1), 255)
+	assert.Equal(t, engine.VerifyHeader(sealed.Header, parent), ErrNonceInvalid)
+
+	// aborted
+	abort := make(chan struct{})
+	close(abort)
+	sealed, err = engine.Seal(types.NewBlock(sealed.Header, nil), abort)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sealed == nil, true)
+}
+
+func Test_Engine_Cache(t *testing.T) {
+	engine := newTestEngine(t)
+	engine.cache(0)
+	engine.cache(1)
+	assert.Equal(t, len(engine.caches), 2)
+
+	// the caches of the old epochs are dropped
+	engine.cache(3)
+	assert.Equal(t, len(engine.caches), 1)
+	assert.Equal(t, engine.epoch(35), uint64(3))
+}
+
+func Test_Config_Validate(t *testing.T) {
+	assert.Equal(t, Config{}.Validate(), error(nil))
+	assert.Equal(t, Config{CacheSize: 100}.Validate(), errConfigInvalid)
+	assert.Equal(t, Config{CacheSize: 1024, DatasetSize: 1000}.Validate(), errConfigInvalid)
+	assert.Equal(t, Config{CacheSize: 1024, DatasetSize: 512}.Validate(), errConfigInvalid)
+
+	_, err := NewEngine(nil, nil, &Config{CacheSize: 100}, nil)
+	assert.Equal(t, err, errConfigInvalid)
+	_, err = NewEngine(nil, pow.DefaultRewardConfig(), nil, nil)
+	assert.Equal(t, err, error(nil))
+}
@@ -8,16 +8,21 @@ package core
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
 	"github.com/seeleteam/go-seele/core/state"
 	"github.com/seeleteam/go-seele/core/store"
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/core/vm"
 	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/event"
+	"github.com/seeleteam/go-seele/log"
 	"github.com/seeleteam/go-seele/miner/pow"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
 )
 
 var (
@@ -41,6 +46,14 @@ var (
 	// does not match the state root hash in block header.
 	ErrBlockStateHashMismatch = errors.New("block state hash mismatch")
 
+	// ErrBlockReceiptHashMismatch is returned when the calculated receipt root hash of block
+	// does not match the receipt root hash in block header.
+	ErrBlockReceiptHashMismatch = errors.New("block receipt hash mismatch")
+
+	// ErrBlockLogsBloomMismatch is returned when the calculated logs bloom of block
+	// does not match the logs bloom in block header.
+	ErrBlockLogsBloomMismatch = errors.New("block logs bloom mismatch")
+
 	// ErrBlockEmptyTxs is returned when writing a block with empty transactions.
 	ErrBlockEmptyTxs = errors.New("empty transactions in block")
 
@@ -51,18 +64,19 @@ var (
 	// the creator address in the block header.
 	ErrBlockCoinbaseMismatch = errors.New("coinbase mismatch")
 
-	errContractCreationNotSupported = errors.New("smart contract creation not supported yet")
-)
+	// ErrBlockOversized is returned when the encoded block size exceeds the MaxBlockSize of the chain config.
+	ErrBlockOversized = errors.New("block oversized")
 
-type consensusEngine interface {
-	// ValidateHeader validates the specified header and return error if validation failed.
-	// Generally, need to validate the block nonce.
-	ValidateHeader(blockHeader *types.BlockHeader) error
+	// ErrBlockHeaderVersion is returned when the block header is of a newer version than
+	// supported, or has the extension fields unknown to its version.
+	ErrBlockHeaderVersion = errors.New("unsupported block header version")
 
-	// ValidateRewardAmount validates the specified amount and returns error if validation failed.
-	// The amount of miner reward will change over time.
-	ValidateRewardAmount(blockHeight uint64, amount *big.Int) error
-}
+	// ErrReorgTooDeep is returned when the block is refused to be the HEAD block since it
+	// reorganizes the canonical chain deeper than the maximum reorg depth.
+	ErrReorgTooDeep = errors.New("chain reorganization too deep")
+
+	errContractCreationNotSupported = errors.New("smart contract creation not supported yet")
+)
 
 // Blockchain represents the block chain with a genesis block. The Blockchain manages
 // blocks insertion, deletion, reorganizations and persistence with a given database.
@@ -70,12 +84,28 @@ type consensusEngine interface {
 type Blockchain struct {
 	bcStore        store.BlockchainStore
 	accountStateDB database.Database
-	engine         consensusEngine
+	engine         consensus.Engine
 	headerChain    *HeaderChain
 	genesisBlock   *types.Block
 	lock           sync.RWMutex // lock for update blockchain info. for example write block
 
 	blockLeaves *BlockLeaves
+
+	stateDiffLog *log.SeeleLog // logs the state diff on state root mismatch if not nil
+
+	maxReorgDepth uint64        // maximum depth of the automatic chain reorganization, 0 for unlimited
+	reorgLog      *log.SeeleLog // logs the refused chain reorganizations
+
+	finalityDepth       uint64          // number of confirmations to finalize a block, 0 to disable
+	checkpointAuthority *common.Address // authority to sign the checkpoints, nil to disable
+	checkpoint          *Checkpoint     // latest checkpoint of the authority
+
+	sigHashRules     *types.SigHashRules   // rules to validate the sighash scheme of txs
+	chainConfig      *types.ChainConfig    // consensus config specified in genesis
+	difficultyConfig *pow.DifficultyConfig // nil to skip the difficulty validation
+	rewardConfig     *pow.RewardConfig     // reward schedule specified in genesis
+
+	ommerCandidates map[common.Hash]*types.BlockHeader // recent stale blocks that could be referenced as ommers
 }
 
 // NewBlockchain returns an initialized block chain with the given store and account state DB.
@@ -84,6 +114,11 @@ func NewBlockchain(bcStore store.BlockchainStore, accountStateDB database.Databa
 		bcStore:        bcStore,
 		accountStateDB: accountStateDB,
 		engine:         &pow.Engine{},
+		sigHashRules:   types.DefaultSigHashRules(0),
+		chainConfig:    types.DefaultChainConfig(),
+		rewardConfig:   pow.DefaultRewardConfig(),
+
+		ommerCandidates: make(map[common.Hash]*types.BlockHeader),
 	}
 
 	var err error
@@ -151,6 +186,140 @@ func (bc *Blockchain) CurrentState() *state.Statedb {
 	return state
 }
 
+// EnableStateDiff enables to log the accounts that differ between the expected and computed
+// state roots when a block state hash mismatch occurs. It is expensive and only for debugging.
+func (bc *Blockchain) EnableStateDiff(log *log.SeeleLog) {
+	bc.stateDiffLog = log
+}
+
+// SetMaxReorgDepth sets the maximum depth of the chain reorganization that is automatically
+// accepted, 0 for unlimited. Blocks that reorganize deeper are stored without becoming the HEAD
+// block, which should be switched manually via SetHead if the fork is expected.
+func (bc *Blockchain) SetMaxReorgDepth(depth uint64, log *log.SeeleLog) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.maxReorgDepth, bc.reorgLog = depth, log
+}
+
+// SetSigHashRules sets the rules to validate the sighash scheme of txs, in which the chain id
+// is generally the network id, and the new sighash versions are activated at the fork heights.
+func (bc *Blockchain) SetSigHashRules(rules *types.SigHashRules) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.sigHashRules = rules
+}
+
+// SetDifficultyConfig sets the config of the block period and difficulty adjustment to validate the
+// block difficulty, which is skipped by default.
+func (bc *Blockchain) SetDifficultyConfig(config *pow.DifficultyConfig) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.difficultyConfig = config
+	bc.engine = pow.NewEngine(config, bc.rewardConfig)
+}
+
+// SetRewardConfig sets the reward schedule of the blocks specified in genesis.
+func (bc *Blockchain) SetRewardConfig(config *pow.RewardConfig) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.rewardConfig = config
+	bc.engine = pow.NewEngine(bc.difficultyConfig, config)
+}
+
+// SetEngine sets the consensus engine to validate and finalize the blocks instead of the POW
+// engine of the difficulty and reward configs, e.g. PoA for the private chains. It should be
+// called after the configs are set, which reset the engine to POW.
+func (bc *Blockchain) SetEngine(engine consensus.Engine) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.engine = engine
+}
+
+// Engine returns the consensus engine of the chain.
+func (bc *Blockchain) Engine() consensus.Engine {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.engine
+}
+
+// RewardConfig returns the reward schedule of the blocks.
+func (bc *Blockchain) RewardConfig() *pow.RewardConfig {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.rewardConfig
+}
+
+// SetChainConfig sets the consensus config of the chain specified in genesis.
+func (bc *Blockchain) SetChainConfig(config *types.ChainConfig) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.chainConfig = config
+}
+
+// ChainConfig returns the consensus config of the chain.
+func (bc *Blockchain) ChainConfig() *types.ChainConfig {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.chainConfig
+}
+
+// CalcDifficulty returns the difficulty of the block created at the specified timestamp upon the parent block.
+func (bc *Blockchain) CalcDifficulty(timestamp uint64, parent *types.BlockHeader) *big.Int {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.engine.CalcDifficulty(timestamp, parent)
+}
+
+// ValidateTxSigHash validates the sighash scheme of the tx to be packed in the next block.
+func (bc *Blockchain) ValidateTxSigHash(tx *types.Transaction) error {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	return bc.sigHashRules.Validate(tx, bc.blockLeaves.GetBestBlock().Header.Height+1)
+}
+
+// logStateDiff logs the accounts that differ between the expected state root of the specified
+// block and the computed state. If the expected state is not available locally, which is generally
+// the case for blocks mined by other nodes, the accounts changed by the block are logged instead
+// so that they could be compared against the logs of the miner.
+func (bc *Blockchain) logStateDiff(block, preBlock *types.Block, computed *state.Statedb) {
+	logger := bc.stateDiffLog
+	logger.Warn("state hash mismatch, block height:%d, hash:%s, expected state root:%s", block.Header.Height,
+		block.HeaderHash.ToHex(), block.Header.StateHash.ToHex())
+
+	diffs, err := computed.Diff(block.Header.StateHash)
+	if err != nil {
+		logger.Warn("expected state root is not available locally (%s), diff against the parent state instead", err)
+		if diffs, err = computed.Diff(preBlock.Header.StateHash); err != nil {
+			logger.Warn("failed to diff state against the parent state, %s", err)
+			return
+		}
+	}
+
+	for _, diff := range diffs {
+		logger.Warn("state diff, account:%s, expected:%s, computed:%s", diff.Address.ToHex(),
+			formatAccount(diff.Other), formatAccount(diff.Current))
+	}
+}
+
+func formatAccount(account *state.Account) string {
+	if account == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("{nonce:%d, balance:%s, codeHash:%s}", account.Nonce, account.Amount, account.CodeHash.ToHex())
+}
+
 // WriteBlock writes the specified block to the blockchain store.
 func (bc *Blockchain) WriteBlock(block *types.Block) error {
 	// Do not write the block if already exists.
@@ -171,6 +340,10 @@ func (bc *Blockchain) WriteBlock(block *types.Block) error {
 		return ErrBlockInvalidParentHash
 	}
 
+	if err = bc.validateFinality(preBlock.Header); err != nil {
+		return err
+	}
+
 	// Ensure the specified block is valid to insert.
 	if err = bc.validateBlock(block, preBlock); err != nil {
 		return err
@@ -178,7 +351,8 @@ func (bc *Blockchain) WriteBlock(block *types.Block) error {
 
 	// Process the txs in the block and check the state root hash.
 	var blockStatedb *state.Statedb
-	if blockStatedb, err = bc.applyTxs(block, preBlock); err != nil {
+	var receipts []*types.Receipt
+	if blockStatedb, receipts, err = bc.applyTxs(block, preBlock); err != nil {
 		return err
 	}
 
@@ -194,9 +368,21 @@ func (bc *Blockchain) WriteBlock(block *types.Block) error {
 	stateRootHash = blockStatedb.Commit(batch)
 
 	if !stateRootHash.Equal(block.Header.StateHash) {
+		if bc.stateDiffLog != nil {
+			bc.logStateDiff(block, preBlock, blockStatedb)
+		}
+
 		return ErrBlockStateHashMismatch
 	}
 
+	if !types.ReceiptMerkleRootHash(receipts).Equal(block.Header.ReceiptHash) {
+		return ErrBlockReceiptHashMismatch
+	}
+
+	if types.CreateBloom(receipts) != block.Header.LogsBloom {
+		return ErrBlockLogsBloomMismatch
+	}
+
 	// Update block leaves and write the block into store.
 	currentBlock := &types.Block{
 		HeaderHash:   block.HeaderHash,
@@ -213,6 +399,38 @@ func (bc *Blockchain) WriteBlock(block *types.Block) error {
 	blockIndex := NewBlockIndex(blockStatedb, currentBlock, td.Add(td, block.Header.Difficulty))
 
 	isHead := bc.blockLeaves.IsBestBlockIndex(blockIndex)
+	if isHead {
+		var refused bool
+		if refused, err = bc.isReorgTooDeep(block); err != nil {
+			return err
+		}
+
+		if refused {
+			// keep the block and its state so that the fork could be switched manually
+			if err = bc.bcStore.PutBlock(block, td, false); err != nil {
+				return err
+			}
+
+			if err = bc.bcStore.PutReceipts(block.HeaderHash, receipts); err != nil {
+				return err
+			}
+
+			if err = batch.Commit(); err != nil {
+				return err
+			}
+
+			committed = true
+			if bc.reorgLog != nil {
+				head := bc.blockLeaves.GetBestBlock()
+				bc.reorgLog.Error("chain reorganization deeper than %d refused, HEAD height:%d, hash:%s, fork height:%d, hash:%s, switch manually via debug.SetHead if expected",
+					bc.maxReorgDepth, head.Header.Height, head.HeaderHash.ToHex(), block.Header.Height, block.HeaderHash.ToHex())
+			}
+
+			return ErrReorgTooDeep
+		}
+	}
+
+	oldHead := bc.blockLeaves.GetBestBlock()
 	bc.blockLeaves.Add(blockIndex)
 	bc.blockLeaves.RemoveByHash(block.Header.PreviousBlockHash)
 	bc.headerChain.WriteHeader(currentBlock.Header)
@@ -229,6 +447,10 @@ func (bc *Blockchain) WriteBlock(block *types.Block) error {
 		return err
 	}
 
+	if err = bc.bcStore.PutReceipts(block.HeaderHash, receipts); err != nil {
+		return err
+	}
+
 	// FIXME: write the block and update the account state in a batch.
 	// Otherwise, restore the account state during service startup.
 	if err = batch.Commit(); err != nil {
@@ -237,9 +459,32 @@ func (bc *Blockchain) WriteBlock(block *types.Block) error {
 
 	committed = true
 
+	if !isHead {
+		bc.addOmmerCandidate(block.Header)
+	}
+
+	if observer := currentExecutionObservers(); observer != nil {
+		observer.OnBlockEnd(currentBlock, blockStatedb)
+	}
+
+	if isHead {
+		bc.fireChainReorg(oldHead, currentBlock)
+	}
+
+	event.BlockInsertedEventManager.Fire(currentBlock)
+
 	return nil
 }
 
+// fireChainReorg fires the reorganization event if the new HEAD block is not on top of the old one.
+// The reorganization is not fired if the blocks reorganized out fail to load, since the txs
+// dropped from them are unknown.
+func (bc *Blockchain) fireChainReorg(oldHead, newHead *types.Block) {
+	if reorg, err := newChainReorg(bc.bcStore, oldHead, newHead); err == nil && reorg != nil {
+		event.ChainReorgEventManager.Fire(reorg)
+	}
+}
+
 func (bc *Blockchain) validateBlock(block, preBlock *types.Block) error {
 	if !block.HeaderHash.Equal(block.Header.Hash()) {
 		return ErrBlockHashMismatch
@@ -250,11 +495,42 @@ func (bc *Blockchain) validateBlock(block, preBlock *types.Block) error {
 		return ErrBlockTxsHashMismatch
 	}
 
+	if block.Size() > bc.chainConfig.MaxBlockSize {
+		return ErrBlockOversized
+	}
+
 	if block.Header.Height != preBlock.Header.Height+1 {
 		return ErrBlockInvalidHeight
 	}
 
-	return bc.engine.ValidateHeader(block.Header)
+	if err := validateHeaderVersion(block.Header, bc.engine); err != nil {
+		return err
+	}
+
+	if err := bc.validateMedianTime(block.Header); err != nil {
+		return err
+	}
+
+	if err := bc.engine.VerifyHeader(block.Header, preBlock.Header); err != nil {
+		return err
+	}
+
+	return bc.validateOmmers(block.Header.Height, block.Header.Ommers, preBlock.Header)
+}
+
+// validateHeaderVersion validates that the header format is supported. The headers of newer
+// versions are decoded with the extension fields, but could not be validated until upgraded,
+// unless the extensions are kept by the engine, see consensus.HeaderExtender.
+func validateHeaderVersion(header *types.BlockHeader, engine consensus.Engine) error {
+	if header.Version > types.BlockHeaderVersion {
+		return ErrBlockHeaderVersion
+	}
+
+	if extender, ok := engine.(consensus.HeaderExtender); len(header.Extensions) > 0 && (!ok || !extender.ExtendsHeader()) {
+		return ErrBlockHeaderVersion
+	}
+
+	return nil
 }
 
 // GetStore returns the blockchain store instance.
@@ -262,24 +538,33 @@ func (bc *Blockchain) GetStore() store.BlockchainStore {
 	return bc.bcStore
 }
 
-// applyTxs processes the txs in the specified block and returns the new state DB of the block.
+// applyTxs processes the txs in the specified block and returns the new state DB and tx receipts of the block.
 // This method supposes the specified block is validated.
-func (bc *Blockchain) applyTxs(block, preBlock *types.Block) (*state.Statedb, error) {
-	minerRewardTx, err := bc.validateMinerRewardTx(block)
+func (bc *Blockchain) applyTxs(block, preBlock *types.Block) (*state.Statedb, []*types.Receipt, error) {
+	statedb, err := state.NewStatedb(preBlock.Header.StateHash, bc.accountStateDB)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	statedb, err := state.NewStatedb(preBlock.Header.StateHash, bc.accountStateDB)
+	receipts, err := bc.ExecuteBlock(block, statedb, currentExecutionObservers())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err := bc.updateStateDB(statedb, minerRewardTx, block.Transactions[1:], block.Header); err != nil {
+	return statedb, receipts, nil
+}
+
+// ExecuteBlock applies the miner reward, the rewards of the consensus engine and the txs of the
+// specified block to the statedb of its parent in the same way as the block is inserted, and
+// notifies the observer of the execution if not nil. It returns the receipts of the txs except
+// the miner reward.
+func (bc *Blockchain) ExecuteBlock(block *types.Block, statedb *state.Statedb, observer ExecutionObserver) ([]*types.Receipt, error) {
+	minerRewardTx, err := bc.validateMinerRewardTx(block)
+	if err != nil {
 		return nil, err
 	}
 
-	return statedb, nil
+	return bc.updateStateDB(statedb, minerRewardTx, block.Transactions[1:], block.Header, observer)
 }
 
 func (bc *Blockchain) validateMinerRewardTx(block *types.Block) (*types.Transaction, error) {
@@ -292,6 +577,10 @@ func (bc *Blockchain) validateMinerRewardTx(block *types.Block) (*types.Transact
 		return nil, ErrBlockInvalidToAddress
 	}
 
+	if err := minerRewardTx.ValidateReward(); err != nil {
+		return nil, err
+	}
+
 	if !bytes.Equal(minerRewardTx.Data.To.Bytes(), block.Header.Creator.Bytes()) {
 		return nil, ErrBlockCoinbaseMismatch
 	}
@@ -304,39 +593,87 @@ func (bc *Blockchain) validateMinerRewardTx(block *types.Block) (*types.Transact
 		return nil, types.ErrAmountNegative
 	}
 
-	if err := bc.engine.ValidateRewardAmount(block.Header.Height, minerRewardTx.Data.Amount); err != nil {
-		return nil, err
+	if reward := bc.engine.BlockReward(block.Header.Height); minerRewardTx.Data.Amount.Cmp(reward) != 0 {
+		return nil, fmt.Errorf("invalid reward amount, block height %d, want %s, got %s", block.Header.Height, reward, minerRewardTx.Data.Amount)
 	}
 
 	return minerRewardTx, nil
 }
 
-func (bc *Blockchain) updateStateDB(statedb *state.Statedb, minerRewardTx *types.Transaction, txs []*types.Transaction, blockHeader *types.BlockHeader) error {
+// updateStateDB applies the miner reward, ommer rewards and txs to the specified statedb, and notifies
+// the observer of the execution if not nil. It returns the receipts of the txs except the miner reward.
+func (bc *Blockchain) updateStateDB(statedb *state.Statedb, minerRewardTx *types.Transaction, txs []*types.Transaction, blockHeader *types.BlockHeader, observer ExecutionObserver) ([]*types.Receipt, error) {
 	// process miner reward
 	stateObj := statedb.GetOrNewStateObject(*minerRewardTx.Data.To)
 	stateObj.AddAmount(minerRewardTx.Data.Amount)
 
+	if observer != nil {
+		observer.OnTransfer(common.Address{}, *minerRewardTx.Data.To, minerRewardTx.Data.Amount)
+	}
+
+	// the engine applies the rewards of the consensus, e.g. the ommer rewards of POW
+	for _, reward := range bc.engine.Finalize(statedb, blockHeader) {
+		if observer != nil {
+			observer.OnTransfer(common.Address{}, reward.To, reward.Amount)
+		}
+	}
+
+	// verify the tx signatures concurrently, while the state is validated before each tx applied
+	if err := types.BatchValidate(txs, nil, bc.chainConfig); err != nil {
+		return nil, err
+	}
+
+	// the tx expiry is evaluated against the median time past of the parent block
+	medianTime, err := medianTimePast(bc.bcStore, blockHeader.PreviousBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
 	receipts := make([]*types.Receipt, len(txs))
 	// process other txs
 	for i, tx := range txs {
-		if err := tx.Validate(statedb); err != nil {
-			return err
+		if err := bc.sigHashRules.Validate(tx, blockHeader.Height); err != nil {
+			return nil, err
+		}
+
+		if tx.IsExpired(blockHeader.Height, medianTime) {
+			return nil, types.ErrTxExpired
+		}
+
+		if err := tx.ValidateHTLC(blockHeader.Height); err != nil {
+			return nil, err
+		}
+
+		if err := tx.ValidateState(statedb); err != nil {
+			return nil, err
+		}
+
+		if observer != nil {
+			observer.OnTxStart(tx, blockHeader)
+		}
+
+		receipt, err := bc.applyTransaction(tx, *minerRewardTx.Data.To, statedb, blockHeader, observer)
+		if observer != nil {
+			observer.OnTxEnd(tx, receipt, err)
 		}
 
-		receipt, err := bc.ApplyTransaction(tx, *minerRewardTx.Data.To, statedb, blockHeader)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		receipts[i] = receipt
 	}
 
-	return nil
+	return receipts, nil
 }
 
 // ApplyTransaction apply a transaction and change statedb corresponding and generate its receipt
 func (bc *Blockchain) ApplyTransaction(tx *types.Transaction, coinbase common.Address, statedb *state.Statedb, blockHeader *types.BlockHeader) (*types.Receipt, error) {
-	context := newEVMContext(tx, blockHeader, coinbase, bc.bcStore)
+	return bc.applyTransaction(tx, coinbase, statedb, blockHeader, nil)
+}
+
+func (bc *Blockchain) applyTransaction(tx *types.Transaction, coinbase common.Address, statedb *state.Statedb, blockHeader *types.BlockHeader, observer ExecutionObserver) (*types.Receipt, error) {
+	context := newEVMContext(tx, blockHeader, coinbase, bc.bcStore, observer)
 	receipt, err := processContract(context, tx, statedb, &vm.Config{})
 	if err != nil {
 		return nil, err
@@ -345,6 +682,81 @@ func (bc *Blockchain) ApplyTransaction(tx *types.Transaction, coinbase common.Ad
 	return receipt, nil
 }
 
+// isReorgTooDeep indicates whether switching the HEAD block to the specified block
+// reorganizes the canonical chain deeper than the maximum reorg depth.
+func (bc *Blockchain) isReorgTooDeep(block *types.Block) (bool, error) {
+	head := bc.blockLeaves.GetBestBlock()
+	if bc.maxReorgDepth == 0 || block.Header.PreviousBlockHash.Equal(head.HeaderHash) {
+		return false, nil
+	}
+
+	// find the common ancestor in the canonical chain
+	for hash := block.Header.PreviousBlockHash; ; {
+		header, err := bc.bcStore.GetBlockHeader(hash)
+		if err != nil {
+			return false, err
+		}
+
+		if header.Height+bc.maxReorgDepth < head.Header.Height {
+			return true, nil
+		}
+
+		canonicalHash, err := bc.bcStore.GetBlockHash(header.Height)
+		if err != nil {
+			return false, err
+		}
+
+		if hash.Equal(canonicalHash) {
+			return false, nil
+		}
+
+		hash = header.PreviousBlockHash
+	}
+}
+
+// SetHead forcibly switches the HEAD block to the specified block in the store, e.g. to
+// accept a fork refused for the deep reorganization, or to rewind the chain. The state of
+// the block must be available. Note, the other forks are discarded from the block leaves.
+func (bc *Blockchain) SetHead(hash common.Hash) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	block, err := bc.bcStore.GetBlock(hash)
+	if err != nil {
+		return err
+	}
+
+	td, err := bc.bcStore.GetBlockTotalDifficulty(hash)
+	if err != nil {
+		return err
+	}
+
+	statedb, err := state.NewStatedb(block.Header.StateHash, bc.accountStateDB)
+	if err != nil {
+		return err
+	}
+
+	if err = bc.updateHashByHeight(block); err != nil {
+		return err
+	}
+
+	if err = bc.bcStore.PutBlockHeader(hash, block.Header, td, true); err != nil {
+		return err
+	}
+
+	bc.headerChain.WriteHeader(block.Header)
+
+	oldHead := bc.blockLeaves.GetBestBlock()
+	bc.blockLeaves = NewBlockLeaves()
+	bc.blockLeaves.Add(NewBlockIndex(statedb, block, td))
+
+	if !oldHead.HeaderHash.Equal(block.HeaderHash) {
+		bc.fireChainReorg(oldHead, block)
+	}
+
+	return nil
+}
+
 // updateHashByHeight updates the height-to-hash mapping for the specified new HEAD block in the canonical chain.
 func (bc *Blockchain) updateHashByHeight(block *types.Block) error {
 	// Delete height-to-hash mappings with the larger height than that of the new HEAD block in the canonical chain.
@@ -366,8 +778,9 @@ func (bc *Blockchain) updateHashByHeight(block *types.Block) error {
 			return err
 		}
 
+		// no canonical block at the height if the new HEAD block is higher than the old one
 		canonicalHash, err := bc.bcStore.GetBlockHash(header.Height)
-		if err != nil {
+		if err != nil && err != leveldbErrors.ErrNotFound {
 			return err
 		}
 
@@ -7,11 +7,14 @@ package core
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/magiconair/properties/assert"
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
 	"github.com/seeleteam/go-seele/core/state"
 	"github.com/seeleteam/go-seele/core/store"
 	"github.com/seeleteam/go-seele/core/types"
@@ -27,9 +30,9 @@ type testAccount struct {
 }
 
 var testGenesisAccounts = []*testAccount{
-	newTestAccount(100, 0),
-	newTestAccount(100, 0),
-	newTestAccount(100, 0),
+	newTestAccount(1000000, 0),
+	newTestAccount(1000000, 0),
+	newTestAccount(1000000, 0),
 }
 
 func newTestAccount(amount, nonce uint64) *testAccount {
@@ -77,15 +80,20 @@ func newTestBlockTx(genesisAccountIndex int, amount, nonce uint64) *types.Transa
 	fromAccount := testGenesisAccounts[genesisAccountIndex]
 	toAddress := crypto.MustGenerateRandomAddress()
 
-	tx := types.NewTransaction(fromAccount.addr, *toAddress, new(big.Int).SetUint64(amount), nonce)
+	tx := types.NewTransaction(fromAccount.addr, *toAddress, new(big.Int).SetUint64(amount), big.NewInt(1), types.TransferGas, nonce)
 	tx.Sign(fromAccount.privKey)
 
 	return tx
 }
 
 func newTestBlock(bc *Blockchain, parentHash common.Hash, blockHeight, txNum, startNonce uint64) *types.Block {
+	return newTestBlockWithOmmers(bc, parentHash, blockHeight, txNum, startNonce, nil)
+}
+
+func newTestBlockWithOmmers(bc *Blockchain, parentHash common.Hash, blockHeight, txNum, startNonce uint64,
+	ommers []*types.BlockHeader) *types.Block {
 	minerAccount := newTestAccount(uint64(pow.GetReward(blockHeight)), 0)
-	rewardTx := types.NewTransaction(common.Address{}, minerAccount.addr, minerAccount.data.Amount, minerAccount.data.Nonce)
+	rewardTx := types.NewRewardTransaction(minerAccount.addr, minerAccount.data.Amount, nil)
 	rewardTx.Sign(minerAccount.privKey)
 
 	txs := []*types.Transaction{rewardTx}
@@ -100,8 +108,9 @@ func newTestBlock(bc *Blockchain, parentHash common.Hash, blockHeight, txNum, st
 		TxHash:            types.MerkleRootHash(txs),
 		Height:            blockHeight,
 		Difficulty:        big.NewInt(1),
-		CreateTimestamp:   big.NewInt(1),
+		CreateTimestamp:   new(big.Int).SetUint64(blockHeight),
 		Nonce:             10,
+		Ommers:            ommers,
 	}
 
 	stateRootHash := common.EmptyHash
@@ -112,10 +121,14 @@ func newTestBlock(bc *Blockchain, parentHash common.Hash, blockHeight, txNum, st
 			panic(err)
 		}
 
-		if err = bc.updateStateDB(statedb, rewardTx, txs[1:], header); err != nil {
+		receipts, err := bc.updateStateDB(statedb, rewardTx, txs[1:], header, nil)
+		if err != nil {
 			panic(err)
 		}
 
+		header.ReceiptHash = types.ReceiptMerkleRootHash(receipts)
+		header.LogsBloom = types.CreateBloom(receipts)
+
 		stateRootHash = statedb.Commit(nil)
 	}
 
@@ -153,6 +166,33 @@ func Test_Blockchain_WriteBlock_TxRootHashChanged(t *testing.T) {
 	assert.Equal(t, bc.WriteBlock(newBlock), ErrBlockTxsHashMismatch)
 }
 
+func Test_Blockchain_WriteBlock_Oversized(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	newBlock := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 3, 0)
+	bc.SetChainConfig(&types.ChainConfig{MaxPayloadSize: 1, MaxBlockSize: newBlock.Size() - 1})
+	assert.Equal(t, bc.WriteBlock(newBlock), ErrBlockOversized)
+
+	bc.SetChainConfig(&types.ChainConfig{MaxPayloadSize: 1, MaxBlockSize: newBlock.Size()})
+	assert.Equal(t, bc.WriteBlock(newBlock), error(nil))
+}
+
+func Test_Blockchain_WriteBlock_ReceiptRootHashChanged(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	newBlock := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 3, 0)
+	newBlock.Header.ReceiptHash = common.EmptyHash
+	newBlock.HeaderHash = newBlock.Header.Hash()
+
+	assert.Equal(t, bc.WriteBlock(newBlock), ErrBlockReceiptHashMismatch)
+}
+
 func Test_Blockchain_WriteBlock_InvalidHeight(t *testing.T) {
 	db, dispose := newTestDatabase()
 	defer dispose()
@@ -180,10 +220,24 @@ func Test_Blockchain_WriteBlock_ValidBlock(t *testing.T) {
 
 	storedBlock, err := bc.bcStore.GetBlock(newBlock.HeaderHash)
 	assert.Equal(t, err, error(nil))
-	assert.Equal(t, storedBlock, newBlock)
+	// compared in encoding, since the tx hashes and senders are cached in the written block
+	assert.Equal(t, common.SerializePanic(storedBlock), common.SerializePanic(newBlock))
 
 	_, err = state.NewStatedb(newBlock.Header.StateHash, db)
 	assert.Equal(t, err, error(nil))
+
+	// receipt proof of the last tx
+	receipts, err := bc.bcStore.GetReceipts(newBlock.HeaderHash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(receipts), 3)
+
+	blockHash, err := bc.bcStore.GetReceiptBlockHash(newBlock.Transactions[3].Hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, blockHash, newBlock.HeaderHash)
+
+	proof, err := types.NewReceiptProof(blockHash, receipts, 2)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, types.VerifyReceiptProof(newBlock.Header, proof), error(nil))
 }
 
 func Test_Blockchain_WriteBlock_DupBlocks(t *testing.T) {
@@ -311,3 +365,168 @@ func assertCanonicalHash(t *testing.T, bc *Blockchain, height uint64, expectedHa
 	assert.Equal(t, err, error(nil))
 	assert.Equal(t, hash, expectedHash)
 }
+
+func Test_Blockchain_MaxReorgDepth(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	bc.SetMaxReorgDepth(1, nil)
+
+	// genesis <- block11 <- block12 <- block13 (canonical)
+	parentHash := bc.genesisBlock.HeaderHash
+	for height := uint64(1); height <= 3; height++ {
+		block := newTestBlock(bc, parentHash, height, 0, 0)
+		assert.Equal(t, bc.WriteBlock(block), error(nil))
+		parentHash = block.HeaderHash
+	}
+
+	head, _ := bc.CurrentBlock()
+
+	// genesis <- block21 <- block22 <- block23 <- block24
+	fork21 := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 0, 0)
+	assert.Equal(t, bc.WriteBlock(fork21), error(nil))
+
+	parentHash = fork21.HeaderHash
+	var fork *types.Block
+	for height := uint64(2); height <= 3; height++ {
+		fork = newTestBlock(bc, parentHash, height, 0, 0)
+		assert.Equal(t, bc.WriteBlock(fork), error(nil))
+		parentHash = fork.HeaderHash
+	}
+
+	fork = newTestBlock(bc, parentHash, 4, 0, 0)
+	assert.Equal(t, bc.WriteBlock(fork), ErrReorgTooDeep)
+
+	currentBlock, _ := bc.CurrentBlock()
+	assert.Equal(t, currentBlock, head)
+	assertCanonicalHash(t, bc, 3, head.HeaderHash)
+
+	// switch to the refused fork manually
+	assert.Equal(t, bc.SetHead(fork.HeaderHash), error(nil))
+
+	currentBlock, _ = bc.CurrentBlock()
+	assert.Equal(t, currentBlock.HeaderHash, fork.HeaderHash)
+	assertCanonicalHash(t, bc, 1, fork21.HeaderHash)
+	assertCanonicalHash(t, bc, 4, fork.HeaderHash)
+}
+
+func Test_Blockchain_ValidateTxSigHash(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	bc.SetSigHashRules(types.DefaultSigHashRules(2))
+
+	// signed for the default chain id 0
+	tx := newTestBlockTx(0, 1, 0)
+	assert.Equal(t, errors.Is(bc.ValidateTxSigHash(tx), types.ErrChainIDMismatch), true)
+
+	tx.SignWithScheme(testGenesisAccounts[0].privKey, types.SigHashScheme{Version: types.SigHashV1, ChainID: 2})
+	assert.Equal(t, bc.ValidateTxSigHash(tx), error(nil))
+}
+
+func Test_Blockchain_ApplyTransaction_FeePayer(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	sender, payer := testGenesisAccounts[0], testGenesisAccounts[1]
+
+	tx := types.NewTransaction(sender.addr, *crypto.MustGenerateRandomAddress(), big.NewInt(10), big.NewInt(1), types.TransferGas, 0)
+	tx.Data.FeePayer = &payer.addr
+	tx.SigVersion = types.SigHashV3
+	tx.Sign(sender.privKey)
+	tx.SignAsFeePayer(payer.privKey)
+
+	statedb, err := state.NewStatedb(bc.genesisBlock.Header.StateHash, db)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, tx.Validate(statedb, bc.ChainConfig()), error(nil))
+
+	receipt, err := bc.ApplyTransaction(tx, *crypto.MustGenerateRandomAddress(), statedb, bc.genesisBlock.Header)
+	assert.Equal(t, err, error(nil))
+
+	// the sender pays the amount only, while the fee payer pays the fee
+	assert.Equal(t, statedb.GetBalance(sender.addr), new(big.Int).Sub(sender.data.Amount, tx.Data.Amount))
+	assert.Equal(t, statedb.GetBalance(payer.addr), new(big.Int).Sub(payer.data.Amount, receipt.Fee))
+}
+
+func Test_Blockchain_ApplyTransaction_Failed(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	sender := testGenesisAccounts[0]
+
+	// the INVALID opcode fails the contract creation with all the gas used
+	tx, err := types.NewContractTransaction(bc.ChainConfig(), sender.addr, big.NewInt(10), big.NewInt(2), types.TransferGas+50000, 0, []byte{0xfe})
+	assert.Equal(t, err, error(nil))
+	tx.Sign(sender.privKey)
+
+	statedb, err := state.NewStatedb(bc.genesisBlock.Header.StateHash, db)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, tx.Validate(statedb, bc.ChainConfig()), error(nil))
+
+	// the coinbase is created by the miner reward in the block
+	coinbase := *crypto.MustGenerateRandomAddress()
+	statedb.CreateAccount(coinbase)
+
+	receipt, err := bc.ApplyTransaction(tx, coinbase, statedb, bc.genesisBlock.Header)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, receipt.Failed, true)
+	assert.Equal(t, receipt.UsedGas, tx.Data.GasLimit)
+	assert.Equal(t, receipt.Fee, new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(tx.Data.GasLimit)))
+	assert.Equal(t, receipt.ContractAddress, common.Address{})
+
+	// only the fee is charged, while the amount transfer is reverted
+	assert.Equal(t, statedb.GetBalance(sender.addr), new(big.Int).Sub(sender.data.Amount, receipt.Fee))
+	assert.Equal(t, statedb.GetBalance(coinbase), receipt.Fee)
+	assert.Equal(t, statedb.GetNonce(sender.addr), uint64(1))
+	assert.Equal(t, statedb.Exist(crypto.CreateAddress(sender.addr, 0)), false)
+}
+
+func Test_Blockchain_validateHeaderVersion(t *testing.T) {
+	header := &types.BlockHeader{Version: types.BlockHeaderVersion}
+	assert.Equal(t, validateHeaderVersion(header, pow.Engine{}), error(nil))
+
+	header.Version = types.BlockHeaderVersion + 1
+	assert.Equal(t, validateHeaderVersion(header, pow.Engine{}), ErrBlockHeaderVersion)
+
+	header.Version = types.BlockHeaderVersion
+	header.Extensions = []rlp.RawValue{common.SerializePanic(uint64(1))}
+	assert.Equal(t, validateHeaderVersion(header, pow.Engine{}), ErrBlockHeaderVersion)
+}
+
+// testEngine verifies any header and rewards the block creator beyond the POW rewards.
+type testEngine struct {
+	pow.Engine
+	verified int
+	bonus    *big.Int
+}
+
+func (engine *testEngine) VerifyHeader(header, parent *types.BlockHeader) error {
+	engine.verified++
+	return nil
+}
+
+func (engine *testEngine) Finalize(statedb *state.Statedb, header *types.BlockHeader) []*consensus.Reward {
+	statedb.GetOrNewStateObject(header.Creator).AddAmount(engine.bonus)
+	return []*consensus.Reward{{To: header.Creator, Amount: engine.bonus}}
+}
+
+func Test_Blockchain_SetEngine(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	engine := &testEngine{bonus: big.NewInt(7)}
+	bc.SetEngine(engine)
+	assert.Equal(t, bc.Engine(), consensus.Engine(engine))
+
+	block := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 0, 0)
+	assert.Equal(t, bc.WriteBlock(block), error(nil))
+	assert.Equal(t, engine.verified, 1)
+
+	_, statedb := bc.CurrentBlock()
+	assert.Equal(t, statedb.GetBalance(block.Header.Creator), big.NewInt(pow.GetReward(1)+7))
+}
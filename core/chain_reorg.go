@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// ChainReorg is the reorganization of the canonical chain, which is fired by
+// event.ChainReorgEventManager once the HEAD block is switched to a block not on top of it.
+type ChainReorg struct {
+	OldHead    *types.Block         // HEAD block before the reorganization
+	NewHead    *types.Block         // HEAD block after the reorganization
+	DroppedTxs []*types.Transaction // txs only in the blocks reorganized out, in the chain order
+}
+
+// newChainReorg returns the reorganization from the old HEAD block to the new one, or nil if
+// the new HEAD block is on top of the old one.
+func newChainReorg(bcStore store.BlockchainStore, oldHead, newHead *types.Block) (*ChainReorg, error) {
+	if newHead.Header.PreviousBlockHash.Equal(oldHead.HeaderHash) {
+		return nil, nil
+	}
+
+	dropped, err := droppedTransactions(bcStore, oldHead, newHead)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChainReorg{oldHead, newHead, dropped}, nil
+}
+
+// droppedTransactions diffs the old chain and the new chain after their common ancestor, and
+// returns the txs in the old chain but not in the new chain. The reward txs are excluded.
+func droppedTransactions(bcStore store.BlockchainStore, oldHead, newHead *types.Block) ([]*types.Transaction, error) {
+	var oldBlocks []*types.Block
+	included := make(map[common.Hash]bool)
+
+	parent := func(block *types.Block) (*types.Block, error) {
+		return bcStore.GetBlock(block.Header.PreviousBlockHash)
+	}
+
+	// walk back the both chains to the common ancestor
+	var err error
+	for oldBlock, newBlock := oldHead, newHead; !oldBlock.HeaderHash.Equal(newBlock.HeaderHash); {
+		if oldBlock.Header.Height >= newBlock.Header.Height {
+			oldBlocks = append(oldBlocks, oldBlock)
+			if oldBlock, err = parent(oldBlock); err != nil {
+				return nil, err
+			}
+		} else {
+			for _, tx := range newBlock.Transactions {
+				included[tx.Hash] = true
+			}
+
+			if newBlock, err = parent(newBlock); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var dropped []*types.Transaction
+	for i := len(oldBlocks) - 1; i >= 0; i-- {
+		// the first tx is the miner reward
+		for j := 1; j < len(oldBlocks[i].Transactions); j++ {
+			if tx := oldBlocks[i].Transactions[j]; !included[tx.Hash] {
+				dropped = append(dropped, tx)
+			}
+		}
+	}
+
+	return dropped, nil
+}
@@ -0,0 +1,86 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/event"
+)
+
+func Test_Blockchain_ChainReorg(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	var reorgs []*ChainReorg
+	listener := func(e event.Event) { reorgs = append(reorgs, e.(*ChainReorg)) }
+	event.ChainReorgEventManager.AddListener(listener)
+	defer event.ChainReorgEventManager.RemoveListener(listener)
+
+	// genesis <- block11 <- block12 (canonical)
+	block11 := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 2, 0)
+	assert.Equal(t, bc.WriteBlock(block11), error(nil))
+	block12 := newTestBlock(bc, block11.HeaderHash, 2, 1, 2)
+	assert.Equal(t, bc.WriteBlock(block12), error(nil))
+	assert.Equal(t, len(reorgs), 0)
+
+	// genesis <- block11 <- block22 <- block23
+	block22 := newTestBlock(bc, block11.HeaderHash, 2, 0, 0)
+	assert.Equal(t, bc.WriteBlock(block22), error(nil))
+	block23 := newTestBlock(bc, block22.HeaderHash, 3, 0, 0)
+	assert.Equal(t, bc.WriteBlock(block23), error(nil))
+
+	assert.Equal(t, len(reorgs), 1)
+	assert.Equal(t, reorgs[0].OldHead.HeaderHash, block12.HeaderHash)
+	assert.Equal(t, reorgs[0].NewHead.HeaderHash, block23.HeaderHash)
+	assert.Equal(t, txHashes(reorgs[0].DroppedTxs), txHashes(block12.Transactions[1:]))
+
+	// rewind the chain to block11
+	assert.Equal(t, bc.SetHead(block11.HeaderHash), error(nil))
+	assert.Equal(t, len(reorgs), 2)
+	assert.Equal(t, len(reorgs[1].DroppedTxs), 0)
+}
+
+func Test_droppedTransactions(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	// genesis <- block11 <- block12
+	block11 := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 2, 0)
+	assert.Equal(t, bc.WriteBlock(block11), error(nil))
+	block12 := newTestBlock(bc, block11.HeaderHash, 2, 1, 2)
+	assert.Equal(t, bc.WriteBlock(block12), error(nil))
+
+	// genesis <- block21, which includes the first tx of block11
+	block21 := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 0, 0)
+	block21.Transactions = append(block21.Transactions, block11.Transactions[1])
+
+	dropped, err := droppedTransactions(bc.bcStore, block12, block21)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, txHashes(dropped), txHashes([]*types.Transaction{block11.Transactions[2], block12.Transactions[1]}))
+
+	// rewind to the ancestor
+	dropped, err = droppedTransactions(bc.bcStore, block12, block11)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, txHashes(dropped), txHashes(block12.Transactions[1:]))
+}
+
+// txHashes returns the hashes of the txs, since the txs loaded from the store are the decoded copies.
+func txHashes(txs []*types.Transaction) []common.Hash {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash
+	}
+
+	return hashes
+}
@@ -0,0 +1,170 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+var (
+	// ErrBlockFinalized is returned when the block forks the canonical chain before the finalized block.
+	ErrBlockFinalized = errors.New("block conflicts with the finalized block")
+
+	// ErrCheckpointAuthorityMissing is returned when adding a checkpoint without the checkpoint authority configured.
+	ErrCheckpointAuthorityMissing = errors.New("checkpoint authority not configured")
+
+	// ErrCheckpointSignatureInvalid is returned when the checkpoint is not signed by the checkpoint authority.
+	ErrCheckpointSignatureInvalid = errors.New("checkpoint signature is invalid")
+
+	// ErrCheckpointNotCanonical is returned when the checkpoint block is not in the canonical chain.
+	ErrCheckpointNotCanonical = errors.New("checkpoint block not in the canonical chain")
+
+	// ErrCheckpointStale is returned when the checkpoint is not higher than the latest checkpoint.
+	ErrCheckpointStale = errors.New("checkpoint not higher than the latest checkpoint")
+)
+
+// Checkpoint marks the block of the height as final, which is signed by the checkpoint authority.
+type Checkpoint struct {
+	Height    uint64            // Height is the height of the finalized block
+	Hash      common.Hash       // Hash is the hash of the finalized block
+	Signature *crypto.Signature // Signature is the authority signature of the checkpoint hash
+}
+
+// hash returns the hash of the checkpoint which is signed by the authority.
+func (cp *Checkpoint) hash() common.Hash {
+	return crypto.MustHash([]interface{}{cp.Height, cp.Hash})
+}
+
+// NewCheckpoint creates the checkpoint of the block signed with the private key of the authority.
+func NewCheckpoint(height uint64, hash common.Hash, privKey *ecdsa.PrivateKey) *Checkpoint {
+	cp := &Checkpoint{Height: height, Hash: hash}
+	cp.Signature = crypto.NewSignature(privKey, cp.hash().Bytes())
+	return cp
+}
+
+// Verify verifies the signature of the checkpoint against the authority.
+func (cp *Checkpoint) Verify(authority common.Address) error {
+	if cp.Signature == nil || !cp.Signature.Verify(&authority, cp.hash().Bytes()) {
+		return ErrCheckpointSignatureInvalid
+	}
+
+	return nil
+}
+
+// Finality is the finality status of the canonical chain.
+type Finality struct {
+	Height     uint64      // Height is the height of the latest finalized block
+	Hash       common.Hash // Hash is the hash of the latest finalized block
+	Depth      uint64      // Depth is the number of confirmations to finalize a block, 0 if disabled
+	Checkpoint *Checkpoint // Checkpoint is the latest checkpoint of the authority, if any
+}
+
+// SetFinality sets the number of confirmations after which the canonical blocks are final, 0 to
+// disable, and the authority to sign the checkpoints for the private chains, nil to disable. The
+// blocks that fork the canonical chain before the finalized block are rejected.
+func (bc *Blockchain) SetFinality(depth uint64, authority *common.Address) {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	bc.finalityDepth, bc.checkpointAuthority = depth, authority
+}
+
+// AddCheckpoint verifies the checkpoint against the checkpoint authority and finalizes its block,
+// which should be in the canonical chain. Note, the checkpoints are only kept in memory.
+func (bc *Blockchain) AddCheckpoint(cp *Checkpoint) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	if bc.checkpointAuthority == nil {
+		return ErrCheckpointAuthorityMissing
+	}
+
+	if err := cp.Verify(*bc.checkpointAuthority); err != nil {
+		return err
+	}
+
+	if bc.checkpoint != nil && cp.Height <= bc.checkpoint.Height {
+		return ErrCheckpointStale
+	}
+
+	if hash, err := bc.bcStore.GetBlockHash(cp.Height); err != nil || !hash.Equal(cp.Hash) {
+		return ErrCheckpointNotCanonical
+	}
+
+	bc.checkpoint = cp
+	return nil
+}
+
+// Finality returns the finality status of the canonical chain.
+func (bc *Blockchain) Finality() (*Finality, error) {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	finality := &Finality{
+		Height:     bc.finalizedHeight(),
+		Depth:      bc.finalityDepth,
+		Checkpoint: bc.checkpoint,
+	}
+
+	var err error
+	if finality.Hash, err = bc.bcStore.GetBlockHash(finality.Height); err != nil {
+		return nil, err
+	}
+
+	return finality, nil
+}
+
+// finalizedHeight returns the height of the latest finalized block in the canonical chain, which
+// is the higher of the block with enough confirmations and the checkpoint block, 0 for genesis.
+// The HEAD block is final if the engine is of instant finality.
+func (bc *Blockchain) finalizedHeight() uint64 {
+	head := bc.blockLeaves.GetBestBlock()
+	if engine, ok := bc.engine.(consensus.InstantFinality); ok && engine.IsInstantFinal() {
+		return head.Header.Height
+	}
+
+	var height uint64
+	if bc.finalityDepth > 0 && head.Header.Height >= bc.finalityDepth {
+		height = head.Header.Height - bc.finalityDepth
+	}
+
+	if bc.checkpoint != nil && bc.checkpoint.Height > height {
+		height = bc.checkpoint.Height
+	}
+
+	return height
+}
+
+// validateFinality returns ErrBlockFinalized if the block of the parent forks the canonical chain
+// before the finalized block, i.e. the common ancestor is lower than the finalized block.
+func (bc *Blockchain) validateFinality(parent *types.BlockHeader) error {
+	finalized := bc.finalizedHeight()
+	if finalized == 0 {
+		return nil
+	}
+
+	for hash, header := parent.Hash(), parent; ; {
+		if header.Height < finalized {
+			return ErrBlockFinalized
+		}
+
+		canonicalHash, err := bc.bcStore.GetBlockHash(header.Height)
+		if err == nil && hash.Equal(canonicalHash) {
+			return nil
+		}
+
+		hash = header.PreviousBlockHash
+		if header, err = bc.bcStore.GetBlockHeader(hash); err != nil {
+			return err
+		}
+	}
+}
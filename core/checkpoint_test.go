@@ -0,0 +1,99 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// writeTestChain writes the blocks from the parent to the height, and returns the last block.
+func writeTestChain(t *testing.T, bc *Blockchain, parentHash common.Hash, from, to uint64) *types.Block {
+	var block *types.Block
+	for height := from; height <= to; height++ {
+		block = newTestBlock(bc, parentHash, height, 0, 0)
+		assert.Equal(t, bc.WriteBlock(block), error(nil))
+		parentHash = block.HeaderHash
+	}
+
+	return block
+}
+
+func Test_Blockchain_FinalityDepth(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	bc.SetFinality(2, nil)
+
+	// genesis <- block11 <- block12 <- block13 <- block14 (canonical), block12 finalized
+	head := writeTestChain(t, bc, bc.genesisBlock.HeaderHash, 1, 4)
+
+	finality, err := bc.Finality()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, finality.Height, uint64(2))
+	assertCanonicalHash(t, bc, 2, finality.Hash)
+
+	// fork from block11 conflicts with the finalized block12
+	block11, err := bc.bcStore.GetBlockByHeight(1)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, bc.WriteBlock(newTestBlock(bc, block11.HeaderHash, 2, 0, 0)), ErrBlockFinalized)
+
+	// fork from the finalized block12 is accepted
+	block12, err := bc.bcStore.GetBlockByHeight(2)
+	assert.Equal(t, err, error(nil))
+	writeTestChain(t, bc, block12.HeaderHash, 3, 4)
+
+	current, _ := bc.CurrentBlock()
+	assert.Equal(t, current.HeaderHash, head.HeaderHash)
+}
+
+func Test_Blockchain_AddCheckpoint(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	head := writeTestChain(t, bc, bc.genesisBlock.HeaderHash, 1, 3)
+	block2, err := bc.bcStore.GetBlockByHeight(2)
+	assert.Equal(t, err, error(nil))
+
+	authority, privKey, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+
+	checkpoint := NewCheckpoint(2, block2.HeaderHash, privKey)
+	assert.Equal(t, bc.AddCheckpoint(checkpoint), ErrCheckpointAuthorityMissing)
+
+	bc.SetFinality(0, authority)
+
+	// signed by another key
+	_, otherKey, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, bc.AddCheckpoint(NewCheckpoint(2, block2.HeaderHash, otherKey)), ErrCheckpointSignatureInvalid)
+
+	// not canonical
+	assert.Equal(t, bc.AddCheckpoint(NewCheckpoint(2, common.StringToHash("fork"), privKey)), ErrCheckpointNotCanonical)
+
+	assert.Equal(t, bc.AddCheckpoint(checkpoint), error(nil))
+	assert.Equal(t, bc.AddCheckpoint(checkpoint), ErrCheckpointStale)
+
+	finality, err := bc.Finality()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, finality.Height, uint64(2))
+	assert.Equal(t, finality.Hash, block2.HeaderHash)
+	assert.Equal(t, finality.Checkpoint, checkpoint)
+
+	// fork from block1 conflicts with the checkpoint
+	block1, err := bc.bcStore.GetBlockByHeight(1)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, bc.WriteBlock(newTestBlock(bc, block1.HeaderHash, 2, 0, 0)), ErrBlockFinalized)
+
+	current, _ := bc.CurrentBlock()
+	assert.Equal(t, current.HeaderHash, head.HeaderHash)
+}
@@ -6,7 +6,6 @@
 package core
 
 import (
-	"math"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/params"
@@ -17,8 +16,9 @@ import (
 	"github.com/seeleteam/go-seele/core/vm"
 )
 
-// newEVMContext creates a new context for use in the EVM.
-func newEVMContext(tx *types.Transaction, header *types.BlockHeader, minerAddress common.Address, bcStore store.BlockchainStore) *vm.Context {
+// newEVMContext creates a new context for use in the EVM. The observer is notified
+// of the value transfers if not nil.
+func newEVMContext(tx *types.Transaction, header *types.BlockHeader, minerAddress common.Address, bcStore store.BlockchainStore, observer ExecutionObserver) *vm.Context {
 	canTransferFunc := func(db vm.StateDB, addr common.Address, amount *big.Int) bool {
 		return db.GetBalance(addr).Cmp(amount) >= 0
 	}
@@ -26,6 +26,10 @@ func newEVMContext(tx *types.Transaction, header *types.BlockHeader, minerAddres
 	transferFunc := func(db vm.StateDB, sender, recipient common.Address, amount *big.Int) {
 		db.SubBalance(sender, amount)
 		db.AddBalance(recipient, amount)
+
+		if observer != nil {
+			observer.OnTransfer(sender, recipient, amount)
+		}
 	}
 
 	heightToHashMapping := map[uint64]common.Hash{
@@ -57,33 +61,63 @@ func newEVMContext(tx *types.Transaction, header *types.BlockHeader, minerAddres
 		Time:        new(big.Int).Set(header.CreateTimestamp),
 		Difficulty:  new(big.Int).Set(header.Difficulty),
 		// GasLimit:    header.GasLimit,
-		// GasPrice:    new(big.Int).Set(tx.GasPrice()),
+		GasPrice: new(big.Int).Set(tx.Data.GasPrice),
 	}
 }
 
 // processContract process the specified contract tx and return the receipt.
+// The fee of the used gas is transferred from the sender to the miner, even if the tx
+// failed in the EVM, e.g. reverted or out of gas, in which case the state changes of the
+// tx are reverted, and the failure receipt is returned.
 func processContract(context *vm.Context, tx *types.Transaction, statedb *state.Statedb, vmConfig *vm.Config) (*types.Receipt, error) {
+	if tx.Data.GasLimit < types.TransferGas {
+		return nil, types.ErrIntrinsicGas
+	}
+
 	evm := vm.NewEVM(*context, statedb, getDefaultChainConfig(), *vmConfig)
 
 	var err error
+	var leftOverGas uint64
 	caller := vm.AccountRef(tx.Data.From)
 	receipt := &types.Receipt{TxHash: tx.Hash}
+	gas := tx.Data.GasLimit - types.TransferGas
 
-	// Currently, use math.MaxUint64 gas to bypass ErrInsufficientBalance error.
-	if tx.Data.To == nil {
-		receipt.Result, receipt.ContractAddress, _, err = evm.Create(caller, tx.Data.Payload, math.MaxUint64, tx.Data.Amount)
+	if tx.Data.Type == types.TxTypeContractCreate {
+		receipt.Result, receipt.ContractAddress, leftOverGas, err = evm.Create(caller, tx.Data.Payload, gas, tx.Data.Amount)
 	} else {
 		statedb.SetNonce(tx.Data.From, statedb.GetNonce(tx.Data.From)+1)
-		receipt.Result, _, err = evm.Call(caller, *tx.Data.To, tx.Data.Payload, math.MaxUint64, tx.Data.Amount)
+		receipt.Result, leftOverGas, err = evm.Call(caller, *tx.Data.To, tx.Data.CallInput(), gas, tx.Data.Amount)
 	}
 
+	// all the gas is used unless reverted, so the failed tx could not consume the gas for free
 	if err != nil {
-		return nil, err
+		receipt.Failed = true
+		receipt.ContractAddress = common.Address{}
+	}
+
+	// the HTLC claim reveals the preimage in the log for the counterparty of the swap
+	if tx.Data.Type == types.TxTypeHTLC && !receipt.Failed {
+		unlock, err := tx.HTLCUnlock()
+		if err != nil {
+			return nil, err
+		}
+
+		statedb.AddLog(unlock.Log())
+	}
+
+	// the balance for the max fee is ensured in tx validation
+	receipt.UsedGas = tx.Data.GasLimit - leftOverGas
+	receipt.Fee = new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(receipt.UsedGas))
+	if receipt.Fee.Sign() > 0 {
+		context.Transfer(statedb, tx.Data.Payer(), context.Coinbase, receipt.Fee)
 	}
 
 	receipt.PostState = statedb.Commit(nil)
 
-	// @todo add logs to receipt, which depend on the state DB implementation.
+	receipt.Logs = statedb.TakeLogs()
+	for _, log := range receipt.Logs {
+		log.BlockNumber = context.BlockNumber.Uint64()
+	}
 
 	return receipt, nil
 }
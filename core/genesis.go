@@ -34,6 +34,16 @@ type Genesis struct {
 
 // GetGenesis get genesis block according to accounts' balance
 func GetGenesis(accounts map[common.Address]*big.Int) *Genesis {
+	return NewGenesis(accounts, nil)
+}
+
+// NewGenesis get genesis block according to accounts' balance and the difficulty of the
+// genesis block, which is 1 if nil.
+func NewGenesis(accounts map[common.Address]*big.Int, difficulty *big.Int) *Genesis {
+	if difficulty == nil {
+		difficulty = big.NewInt(1)
+	}
+
 	statedb, err := getStateDB(accounts)
 	if err != nil {
 		panic(err)
@@ -46,7 +56,7 @@ func GetGenesis(accounts map[common.Address]*big.Int) *Genesis {
 			Creator:           common.Address{},
 			StateHash:         stateRootHash,
 			TxHash:            types.MerkleRootHash(nil),
-			Difficulty:        big.NewInt(1),
+			Difficulty:        new(big.Int).Set(difficulty),
 			Height:            genesisBlockHeight,
 			CreateTimestamp:   big.NewInt(0),
 			Nonce:             1,
@@ -90,6 +100,10 @@ func (genesis *Genesis) store(bcStore store.BlockchainStore, accountStateDB data
 		return err
 	}
 
+	if err = bcStore.PutSchemaVersion(store.SchemaVersion); err != nil {
+		return err
+	}
+
 	return bcStore.PutBlockHeader(genesis.header.Hash(), genesis.header, genesis.header.Difficulty, true)
 }
 
@@ -0,0 +1,148 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/bft"
+	"github.com/seeleteam/go-seele/consensus/memhash"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+var (
+	// ErrGenesisDifficultyInvalid is returned when the difficulty of the genesis block is not positive.
+	ErrGenesisDifficultyInvalid = errors.New("invalid genesis difficulty")
+
+	// ErrGenesisAlgorithmInvalid is returned when the POW algorithm of the genesis is not supported.
+	ErrGenesisAlgorithmInvalid = errors.New("unsupported POW algorithm")
+)
+
+// GenesisInfo is the genesis spec of a chain loaded from a JSON file, so that private networks
+// could be bootstrapped without recompiling. The configs of the public networks are used for
+// the fields not specified.
+type GenesisInfo struct {
+	// ChainID is the network id of the chain, which the txs are signed for. The network id of the node config is used if 0
+	ChainID uint64
+
+	// accounts info for genesis block used for test
+	// map key is account address -> value is account balance
+	Accounts map[string]int64
+
+	// InitialDifficulty is the difficulty of the genesis block, 1 if nil
+	InitialDifficulty *big.Int
+
+	// consensus config of the chain, e.g. {"MaxPayloadSize": 32768, "MaxBlockSize": 1048576}. The default config of the public networks is used if nil
+	Chain *types.ChainConfig
+
+	// block period and difficulty adjustment of the chain, e.g. {"BlockPeriod": 1, "MinDifficulty": 1000, "BoundDivisor": 2048, "MaxDownSteps": 99}.
+	// The default config of the public networks is used if nil
+	Difficulty *pow.DifficultyConfig
+
+	// reward schedule of the blocks, e.g. {"Rewards": [200, 100], "TailReward": 30, "BlocksPerEra": 525000},
+	// or the halving curve, e.g. {"InitialReward": 200, "HalvingInterval": 525000, "TailReward": 30}.
+	// The default schedule of the public networks is used if nil
+	Reward *pow.RewardConfig
+
+	// PowAlgorithm is the POW algorithm of the chain, "hash" to hash the header or "memhash" for the
+	// memory-hard algorithm, or "bft" for the permissioned chains of the Bft validators, "hash" if empty
+	PowAlgorithm string
+
+	// config of the memhash algorithm, e.g. {"EpochLength": 30000, "CacheSize": 16777216, "DatasetSize": 1073741824}.
+	// The default config is used if nil
+	MemHash *memhash.Config
+
+	// config of the bft consensus, e.g. {"Validators": ["0x..."], "BlockPeriod": 1, "RequestTimeout": 10},
+	// which is required if the PowAlgorithm is "bft"
+	Bft *bft.Config
+}
+
+// GetGenesisInfoFromFile get genesis info from a specific file
+func GetGenesisInfoFromFile(filepath string) (GenesisInfo, error) {
+	var info GenesisInfo
+	buff, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return info, err
+	}
+
+	if err = json.Unmarshal(buff, &info); err != nil {
+		return info, err
+	}
+
+	return info, info.Validate()
+}
+
+// Validate validates the accounts and configs of the genesis info.
+func (info GenesisInfo) Validate() error {
+	if _, err := info.GetAccounts(); err != nil {
+		return err
+	}
+
+	if info.InitialDifficulty != nil && info.InitialDifficulty.Sign() <= 0 {
+		return ErrGenesisDifficultyInvalid
+	}
+
+	if info.Chain != nil {
+		if err := info.Chain.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if info.Difficulty != nil {
+		if err := info.Difficulty.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if info.Reward != nil {
+		if err := info.Reward.Validate(); err != nil {
+			return err
+		}
+	}
+
+	switch info.PowAlgorithm {
+	case "", pow.Algorithm, memhash.Algorithm:
+	case bft.Algorithm:
+		if info.Bft == nil {
+			return ErrGenesisAlgorithmInvalid
+		}
+	default:
+		return ErrGenesisAlgorithmInvalid
+	}
+
+	if info.MemHash != nil {
+		if err := info.MemHash.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if info.Bft != nil {
+		return info.Bft.Validate()
+	}
+
+	return nil
+}
+
+// GetAccounts converts the genesis info into the genesis accounts
+func (info GenesisInfo) GetAccounts() (map[common.Address]*big.Int, error) {
+	accounts := make(map[common.Address]*big.Int)
+	for k, v := range info.Accounts {
+		addr, err := common.HexToAddress(k)
+		if err != nil {
+			return nil, err
+		}
+
+		balance := big.NewInt(v)
+		accounts[addr] = balance
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,83 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/bft"
+	"github.com/seeleteam/go-seele/consensus/memhash"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_GenesisInfo_FromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "GenesisInfo")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr := crypto.MustGenerateRandomAddress()
+	spec := `{
+		"ChainID": 100,
+		"Accounts": {"` + addr.ToHex() + `": 20},
+		"InitialDifficulty": 1000,
+		"Reward": {"Rewards": [5, 3], "TailReward": 1, "BlocksPerEra": 10}
+	}`
+
+	file := filepath.Join(dir, "genesis.json")
+	if err = ioutil.WriteFile(file, []byte(spec), 0644); err != nil {
+		panic(err)
+	}
+
+	info, err := GetGenesisInfoFromFile(file)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, info.ChainID, uint64(100))
+	assert.Equal(t, info.InitialDifficulty, big.NewInt(1000))
+	assert.Equal(t, info.Reward.GetReward(10), int64(3))
+	assert.Equal(t, info.Reward.GetReward(20), int64(1))
+	assert.Equal(t, info.Chain == nil, true)
+
+	accounts, err := info.GetAccounts()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, accounts[*addr], big.NewInt(20))
+}
+
+func Test_GenesisInfo_Validate(t *testing.T) {
+	info := GenesisInfo{Accounts: map[string]int64{"0x01": 1}}
+	assert.Equal(t, info.Validate() != nil, true)
+
+	info = GenesisInfo{InitialDifficulty: big.NewInt(0)}
+	assert.Equal(t, info.Validate(), ErrGenesisDifficultyInvalid)
+
+	info = GenesisInfo{}
+	assert.Equal(t, info.Validate(), error(nil))
+
+	info = GenesisInfo{PowAlgorithm: "unknown"}
+	assert.Equal(t, info.Validate(), ErrGenesisAlgorithmInvalid)
+
+	info = GenesisInfo{PowAlgorithm: memhash.Algorithm, MemHash: &memhash.Config{CacheSize: 1024, DatasetSize: 8192}}
+	assert.Equal(t, info.Validate(), error(nil))
+
+	info.MemHash.DatasetSize = 100
+	assert.Equal(t, info.Validate() != nil, true)
+
+	// the validators are required for bft
+	info = GenesisInfo{PowAlgorithm: bft.Algorithm}
+	assert.Equal(t, info.Validate(), ErrGenesisAlgorithmInvalid)
+
+	info.Bft = &bft.Config{}
+	assert.Equal(t, info.Validate() != nil, true)
+
+	info.Bft.Validators = []common.Address{*crypto.MustGenerateRandomAddress()}
+	assert.Equal(t, info.Validate(), error(nil))
+}
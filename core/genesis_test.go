@@ -97,3 +97,11 @@ func Test_Genesis_Init_GenesisMismatch(t *testing.T) {
 	err := genesis.InitializeAndValidate(bcStore, db)
 	assert.Equal(t, err, ErrGenesisHashMismatch)
 }
+
+func Test_Genesis_NewGenesis_Difficulty(t *testing.T) {
+	assert.Equal(t, NewGenesis(nil, nil).header.Hash(), GetGenesis(nil).header.Hash())
+
+	genesis := NewGenesis(nil, big.NewInt(100))
+	assert.Equal(t, genesis.header.Difficulty, big.NewInt(100))
+	assert.Equal(t, genesis.header.Hash() == GetGenesis(nil).header.Hash(), false)
+}
@@ -0,0 +1,67 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// MedianTimeSpan is the number of the recent headers to calculate the median time past.
+const MedianTimeSpan = 11
+
+// ErrBlockTimestampMedian is returned when the block timestamp is not later than the median time past of the parent.
+var ErrBlockTimestampMedian = errors.New("block timestamp not later than the median time past")
+
+// MedianTimePast returns the median timestamp of the block of the specified hash and its
+// ancestors within MedianTimeSpan, which could not be skewed by a single miner. The time-dependent
+// rules of the next block, e.g. the tx expiry, are evaluated against it instead of the timestamp
+// of the block itself.
+func (bc *Blockchain) MedianTimePast(hash common.Hash) (uint64, error) {
+	return medianTimePast(bc.bcStore, hash)
+}
+
+// medianTimePast returns the median timestamp of the header of the hash and its ancestors
+// within MedianTimeSpan, and the fewer headers near the genesis block.
+func medianTimePast(bcStore store.BlockchainStore, hash common.Hash) (uint64, error) {
+	timestamps := make([]uint64, 0, MedianTimeSpan)
+	for len(timestamps) < MedianTimeSpan {
+		header, err := bcStore.GetBlockHeader(hash)
+		if err != nil {
+			return 0, err
+		}
+
+		timestamps = append(timestamps, header.CreateTimestamp.Uint64())
+		if header.Height == genesisBlockHeight {
+			break
+		}
+
+		hash = header.PreviousBlockHash
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+	return timestamps[len(timestamps)/2], nil
+}
+
+// validateMedianTime validates that the timestamp of the header is later than the median time
+// past of its parent, so that the miners could not set the timestamp back in the past, even
+// if the difficulty validation is skipped.
+func (bc *Blockchain) validateMedianTime(header *types.BlockHeader) error {
+	medianTime, err := medianTimePast(bc.bcStore, header.PreviousBlockHash)
+	if err != nil {
+		return err
+	}
+
+	if header.CreateTimestamp.Uint64() <= medianTime {
+		return ErrBlockTimestampMedian
+	}
+
+	return nil
+}
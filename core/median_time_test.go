@@ -0,0 +1,88 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// putTestHeaders puts the chain of the headers of the timestamps from genesis, and returns the header hashes.
+func putTestHeaders(t *testing.T, bcStore store.BlockchainStore, timestamps []int64) []common.Hash {
+	hashes := make([]common.Hash, len(timestamps))
+	parentHash := common.EmptyHash
+	for i, timestamp := range timestamps {
+		header := &types.BlockHeader{
+			PreviousBlockHash: parentHash,
+			Height:            uint64(i),
+			Difficulty:        big.NewInt(1),
+			CreateTimestamp:   big.NewInt(timestamp),
+		}
+
+		hashes[i] = header.Hash()
+		assert.Equal(t, bcStore.PutBlockHeader(hashes[i], header, big.NewInt(int64(i+1)), true), error(nil))
+		parentHash = hashes[i]
+	}
+
+	return hashes
+}
+
+func Test_MedianTimePast(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	hashes := putTestHeaders(t, bcStore, []int64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 1000})
+
+	// fewer headers near genesis
+	mtp, err := medianTimePast(bcStore, hashes[0])
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, mtp, uint64(0))
+
+	mtp, err = medianTimePast(bcStore, hashes[3])
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, mtp, uint64(20))
+
+	mtp, err = medianTimePast(bcStore, hashes[11])
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, mtp, uint64(60))
+
+	// the skewed timestamp of the latest block is not the median
+	mtp, err = medianTimePast(bcStore, hashes[12])
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, mtp, uint64(70))
+
+	_, err = medianTimePast(bcStore, common.StringToHash("unknown"))
+	assert.Equal(t, err != nil, true)
+}
+
+func Test_Blockchain_ValidateMedianTime(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+	parentHash := bc.genesisBlock.HeaderHash
+	for height := uint64(1); height <= 3; height++ {
+		block := newTestBlock(bc, parentHash, height, 0, 0)
+		assert.Equal(t, bc.WriteBlock(block), error(nil))
+		parentHash = block.HeaderHash
+	}
+
+	// the median of the timestamps 0, 1, 2, 3 is 2
+	block := newTestBlock(bc, parentHash, 4, 0, 0)
+	block.Header.CreateTimestamp = big.NewInt(2)
+	block.HeaderHash = block.Header.Hash()
+	assert.Equal(t, bc.WriteBlock(block), ErrBlockTimestampMedian)
+
+	block.Header.CreateTimestamp = big.NewInt(3)
+	block.HeaderHash = block.Header.Hash()
+	assert.Equal(t, bc.WriteBlock(block), error(nil))
+}
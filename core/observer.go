@@ -0,0 +1,121 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// ExecutionObserver observes the tx execution when blocks are inserted into the blockchain,
+// e.g. to track the internal txs or state diffs for analytics. The tx callbacks are invoked
+// while the block is being validated, and the block may be rejected afterwards, e.g. due to
+// state hash mismatch. So observers should buffer the tx results, and only take them once
+// OnBlockEnd is invoked, which happens after the block is written into the blockchain.
+//
+// Callbacks are invoked synchronously in the block insertion, so they should be fast and
+// must not modify the arguments.
+type ExecutionObserver interface {
+	// OnTxStart is invoked before the tx is applied.
+	OnTxStart(tx *types.Transaction, blockHeader *types.BlockHeader)
+
+	// OnTransfer is invoked for every value transfer, including the tx amount and the
+	// internal transfers between contracts. The sender of the miner reward is empty.
+	OnTransfer(from, to common.Address, amount *big.Int)
+
+	// OnTxEnd is invoked after the tx is applied, with the receipt if succeeded or the error.
+	OnTxEnd(tx *types.Transaction, receipt *types.Receipt, err error)
+
+	// OnBlockEnd is invoked after the block is written into the blockchain, with the state of the block.
+	OnBlockEnd(block *types.Block, statedb *state.Statedb)
+}
+
+// BaseExecutionObserver implements ExecutionObserver with nothing to do,
+// so that observers could embed it and only implement the callbacks they need.
+type BaseExecutionObserver struct{}
+
+// OnTxStart implements ExecutionObserver.
+func (BaseExecutionObserver) OnTxStart(tx *types.Transaction, blockHeader *types.BlockHeader) {}
+
+// OnTransfer implements ExecutionObserver.
+func (BaseExecutionObserver) OnTransfer(from, to common.Address, amount *big.Int) {}
+
+// OnTxEnd implements ExecutionObserver.
+func (BaseExecutionObserver) OnTxEnd(tx *types.Transaction, receipt *types.Receipt, err error) {}
+
+// OnBlockEnd implements ExecutionObserver.
+func (BaseExecutionObserver) OnBlockEnd(block *types.Block, statedb *state.Statedb) {}
+
+var (
+	observersLock sync.RWMutex
+	observers     executionObservers
+)
+
+// RegisterExecutionObserver registers the observer to observe the tx execution of inserted blocks.
+func RegisterExecutionObserver(observer ExecutionObserver) {
+	observersLock.Lock()
+	defer observersLock.Unlock()
+
+	observers = append(append(executionObservers{}, observers...), observer)
+}
+
+// UnregisterExecutionObserver removes the registered observer.
+func UnregisterExecutionObserver(observer ExecutionObserver) {
+	observersLock.Lock()
+	defer observersLock.Unlock()
+
+	var remains executionObservers
+	for _, o := range observers {
+		if o != observer {
+			remains = append(remains, o)
+		}
+	}
+
+	observers = remains
+}
+
+// currentExecutionObservers returns the registered observers, or nil if none.
+func currentExecutionObservers() ExecutionObserver {
+	observersLock.RLock()
+	defer observersLock.RUnlock()
+
+	if len(observers) == 0 {
+		return nil
+	}
+
+	return observers
+}
+
+// executionObservers dispatches the callbacks to all observers in registration order.
+type executionObservers []ExecutionObserver
+
+func (list executionObservers) OnTxStart(tx *types.Transaction, blockHeader *types.BlockHeader) {
+	for _, o := range list {
+		o.OnTxStart(tx, blockHeader)
+	}
+}
+
+func (list executionObservers) OnTransfer(from, to common.Address, amount *big.Int) {
+	for _, o := range list {
+		o.OnTransfer(from, to, amount)
+	}
+}
+
+func (list executionObservers) OnTxEnd(tx *types.Transaction, receipt *types.Receipt, err error) {
+	for _, o := range list {
+		o.OnTxEnd(tx, receipt, err)
+	}
+}
+
+func (list executionObservers) OnBlockEnd(block *types.Block, statedb *state.Statedb) {
+	for _, o := range list {
+		o.OnBlockEnd(block, statedb)
+	}
+}
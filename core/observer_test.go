@@ -0,0 +1,79 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+type testTransfer struct {
+	from, to common.Address
+	amount   *big.Int
+}
+
+type testObserver struct {
+	BaseExecutionObserver
+
+	txStarts  int
+	txEnds    int
+	transfers []testTransfer
+	blocks    []*types.Block
+}
+
+func (o *testObserver) OnTxStart(tx *types.Transaction, blockHeader *types.BlockHeader) {
+	o.txStarts++
+}
+
+func (o *testObserver) OnTransfer(from, to common.Address, amount *big.Int) {
+	o.transfers = append(o.transfers, testTransfer{from, to, amount})
+}
+
+func (o *testObserver) OnTxEnd(tx *types.Transaction, receipt *types.Receipt, err error) {
+	o.txEnds++
+}
+
+func (o *testObserver) OnBlockEnd(block *types.Block, statedb *state.Statedb) {
+	o.blocks = append(o.blocks, block)
+}
+
+func Test_ExecutionObserver(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	observer := &testObserver{}
+	RegisterExecutionObserver(observer)
+
+	block := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 2, 0)
+	assert.Equal(t, bc.WriteBlock(block), error(nil))
+
+	assert.Equal(t, observer.txStarts, 2)
+	assert.Equal(t, observer.txEnds, 2)
+	assert.Equal(t, len(observer.blocks), 1)
+	assert.Equal(t, observer.blocks[0].HeaderHash, block.HeaderHash)
+
+	// miner reward, 2 tx transfers and their fees
+	assert.Equal(t, len(observer.transfers), 5)
+	assert.Equal(t, observer.transfers[0].from, common.Address{})
+	assert.Equal(t, observer.transfers[0].to, block.Header.Creator)
+	assert.Equal(t, observer.transfers[1].from, testGenesisAccounts[0].addr)
+	assert.Equal(t, observer.transfers[1].amount, big.NewInt(1))
+	assert.Equal(t, observer.transfers[2].to, block.Header.Creator)
+	assert.Equal(t, observer.transfers[2].amount, big.NewInt(types.TransferGas))
+
+	// not notified after unregistered
+	UnregisterExecutionObserver(observer)
+	block = newTestBlock(bc, block.HeaderHash, 2, 1, 2)
+	assert.Equal(t, bc.WriteBlock(block), error(nil))
+	assert.Equal(t, len(observer.blocks), 1)
+}
@@ -0,0 +1,115 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"sort"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// validateOmmers validates the ommers referenced by the block of the specified height upon
+// the parent block:
+//  1. at most types.MaxOmmers ommers, which do not reference ommers themselves
+//  2. each ommer is at most types.MaxOmmerDepth lower than the block, and its parent is an ancestor
+//  3. each ommer is neither an ancestor, nor referenced by an ancestor or another ommer
+//  4. the difficulty and pow of each ommer are valid
+func (bc *Blockchain) validateOmmers(height uint64, ommers []*types.BlockHeader, parent *types.BlockHeader) error {
+	if len(ommers) == 0 {
+		return nil
+	}
+
+	if len(ommers) > types.MaxOmmers {
+		return types.ErrOmmersTooMany
+	}
+
+	// the parent of an ommer at the max depth is types.MaxOmmerDepth+1 lower than the block
+	ancestors := make(map[common.Hash]*types.BlockHeader)
+	referenced := make(map[common.Hash]bool)
+	for header, i := parent, 0; i <= types.MaxOmmerDepth; i++ {
+		ancestors[header.Hash()] = header
+		for _, ommer := range header.Ommers {
+			referenced[ommer.Hash()] = true
+		}
+
+		if header.Height == genesisBlockHeight {
+			break
+		}
+
+		var err error
+		if header, err = bc.bcStore.GetBlockHeader(header.PreviousBlockHash); err != nil {
+			return err
+		}
+	}
+
+	for _, ommer := range ommers {
+		hash := ommer.Hash()
+		if len(ommer.Ommers) > 0 || ommer.Height >= height || height-ommer.Height > types.MaxOmmerDepth ||
+			ancestors[hash] != nil || referenced[hash] {
+			return types.ErrOmmerInvalid
+		}
+
+		if err := validateHeaderVersion(ommer, bc.engine); err != nil {
+			return err
+		}
+
+		ommerParent := ancestors[ommer.PreviousBlockHash]
+		if ommerParent == nil || ommer.Height != ommerParent.Height+1 {
+			return types.ErrOmmerInvalid
+		}
+
+		if err := bc.engine.VerifyHeader(ommer, ommerParent); err != nil {
+			return err
+		}
+
+		referenced[hash] = true
+	}
+
+	return nil
+}
+
+// addOmmerCandidate caches the header of the block that does not become the HEAD block,
+// which could be referenced as an ommer by the later blocks, and removes the candidates
+// too low to be referenced.
+func (bc *Blockchain) addOmmerCandidate(header *types.BlockHeader) {
+	for hash, candidate := range bc.ommerCandidates {
+		if candidate.Height+types.MaxOmmerDepth < header.Height {
+			delete(bc.ommerCandidates, hash)
+		}
+	}
+
+	if len(header.Ommers) == 0 {
+		bc.ommerCandidates[header.Hash()] = header.Clone()
+	}
+}
+
+// GetOmmerCandidates returns the stale blocks that could be referenced as the ommers by the
+// next block upon the specified parent block, the nearest first.
+func (bc *Blockchain) GetOmmerCandidates(parent *types.Block) []*types.BlockHeader {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+
+	candidates := make([]*types.BlockHeader, 0, len(bc.ommerCandidates))
+	for _, candidate := range bc.ommerCandidates {
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Height > candidates[j].Height })
+
+	var ommers []*types.BlockHeader
+	for _, candidate := range candidates {
+		if len(ommers) == types.MaxOmmers {
+			break
+		}
+
+		if bc.validateOmmers(parent.Header.Height+1, append(ommers, candidate), parent.Header) == nil {
+			ommers = append(ommers, candidate.Clone())
+		}
+	}
+
+	return ommers
+}
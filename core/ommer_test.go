@@ -0,0 +1,78 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+func Test_Blockchain_Ommers(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	block1 := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 3, 0)
+	assert.Equal(t, bc.WriteBlock(block1), error(nil))
+
+	// stale block of the same height
+	stale := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 3, 0)
+	assert.Equal(t, bc.WriteBlock(stale), error(nil))
+
+	ommers := bc.GetOmmerCandidates(block1)
+	assert.Equal(t, len(ommers), 1)
+	assert.Equal(t, ommers[0].Hash(), stale.HeaderHash)
+
+	block2 := newTestBlockWithOmmers(bc, block1.HeaderHash, 2, 0, 3, ommers)
+	assert.Equal(t, bc.WriteBlock(block2), error(nil))
+
+	currentBlock, statedb := bc.CurrentBlock()
+	assert.Equal(t, currentBlock.HeaderHash, block2.HeaderHash)
+	// the reward of the stale block itself is only in its fork
+	assert.Equal(t, statedb.GetBalance(stale.Header.Creator), big.NewInt(pow.GetOmmerReward(2, 1)))
+	assert.Equal(t, statedb.GetBalance(block2.Header.Creator),
+		big.NewInt(pow.GetReward(2)+pow.GetOmmerInclusionReward(2)))
+
+	// referenced already
+	assert.Equal(t, len(bc.GetOmmerCandidates(block2)), 0)
+	block3 := newTestBlockWithOmmers(bc, block2.HeaderHash, 3, 0, 3, []*types.BlockHeader{stale.Header})
+	assert.Equal(t, bc.WriteBlock(block3), types.ErrOmmerInvalid)
+
+	// ancestor
+	block3 = newTestBlockWithOmmers(bc, block2.HeaderHash, 3, 0, 3, []*types.BlockHeader{block1.Header})
+	assert.Equal(t, bc.WriteBlock(block3), types.ErrOmmerInvalid)
+
+	// too many
+	ommers = []*types.BlockHeader{stale.Header, stale.Header, stale.Header}
+	assert.Equal(t, bc.validateOmmers(3, ommers, block2.Header), types.ErrOmmersTooMany)
+}
+
+func Test_Blockchain_OmmerTooDeep(t *testing.T) {
+	db, dispose := newTestDatabase()
+	defer dispose()
+
+	bc := newTestBlockchain(db)
+
+	block := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 0, 0)
+	assert.Equal(t, bc.WriteBlock(block), error(nil))
+
+	stale := newTestBlock(bc, bc.genesisBlock.HeaderHash, 1, 0, 0)
+	assert.Equal(t, bc.WriteBlock(stale), error(nil))
+
+	for height := uint64(2); height <= types.MaxOmmerDepth+1; height++ {
+		block = newTestBlock(bc, block.HeaderHash, height, 0, 0)
+		assert.Equal(t, bc.WriteBlock(block), error(nil))
+	}
+
+	ommers := []*types.BlockHeader{stale.Header}
+	assert.Equal(t, bc.validateOmmers(block.Header.Height+1, ommers, block.Header), types.ErrOmmerInvalid)
+	assert.Equal(t, len(bc.GetOmmerCandidates(block)), 0)
+}
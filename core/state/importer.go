@@ -0,0 +1,97 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package state
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/trie"
+)
+
+// importBatchSize is the number of accounts committed into the database at a time.
+const importBatchSize = 4096
+
+// ErrCodeHashMismatch is returned when the imported code does not match the code hash of the account.
+var ErrCodeHashMismatch = errors.New("account code hash mismatch")
+
+// Importer writes the accounts, e.g. visited by ForEachAccount, into an empty state trie.
+// Unlike the Statedb, the accounts are not cached but committed in batches, so that the
+// memory usage is bounded whatever the number of accounts is.
+type Importer struct {
+	db      database.Database
+	trie    *trie.Trie
+	batch   database.Batch
+	pending int
+}
+
+// NewImporter creates an importer of the state trie in the specified database.
+func NewImporter(db database.Database) (*Importer, error) {
+	t, err := trie.NewTrie(common.EmptyHash, []byte("S"), db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Importer{
+		db:    db,
+		trie:  t,
+		batch: db.NewBatch(),
+	}, nil
+}
+
+// Put writes the account and the code of the contract account into the state trie.
+func (i *Importer) Put(addr common.Address, account *Account, code []byte) error {
+	if account.CodeHash.IsEmpty() != (len(code) == 0) {
+		return ErrCodeHashMismatch
+	}
+
+	if len(code) > 0 {
+		if !crypto.HashBytes(code).Equal(account.CodeHash) {
+			return ErrCodeHashMismatch
+		}
+
+		i.batch.Put(append(keyPrefixCode, addr.Bytes()...), code)
+	}
+
+	data, err := rlp.EncodeToBytes(account)
+	if err != nil {
+		return err
+	}
+
+	if err = i.trie.Put(addr[:], data); err != nil {
+		return err
+	}
+
+	if i.pending++; i.pending < importBatchSize {
+		return nil
+	}
+
+	_, err = i.Commit()
+	return err
+}
+
+// Commit commits the pending accounts into the database and returns the state root hash.
+func (i *Importer) Commit() (common.Hash, error) {
+	root := i.trie.Commit(i.batch)
+	if err := i.batch.Commit(); err != nil {
+		return common.EmptyHash, err
+	}
+
+	// reload the trie from the root to release the committed nodes in memory
+	t, err := trie.NewTrie(root, []byte("S"), i.db)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	i.trie = t
+	i.batch = i.db.NewBatch()
+	i.pending = 0
+
+	return root, nil
+}
@@ -6,11 +6,14 @@
 package state
 
 import (
+	"bytes"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/hashicorp/golang-lru"
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/database"
 	"github.com/seeleteam/go-seele/trie"
 )
@@ -26,7 +29,16 @@ var (
 type Statedb struct {
 	db           database.Database
 	trie         *trie.Trie
-	stateObjects *lru.Cache // stateObjects maps account addresses of common.Address type to the state objects of *StateObject type
+	stateObjects *lru.Cache   // stateObjects maps account addresses of common.Address type to the state objects of *StateObject type
+	logs         []*types.Log // logs added by the tx being processed
+	snapshots    []*snapshot  // snapshots taken by the EVM, see Snapshot
+}
+
+// snapshot keeps the state objects before touched since the snapshot is taken,
+// so that the state changes could be reverted, e.g. once the tx failed in the EVM.
+type snapshot struct {
+	objects map[common.Address]*StateObject // copies of the touched objects, nil if not existed
+	logs    int                             // number of the logs when the snapshot is taken
 }
 
 // NewStatedb constructs and returns a statedb instance
@@ -56,9 +68,10 @@ func (s *Statedb) GetCopy() (*Statedb, error) {
 	}
 
 	for _, k := range s.stateObjects.Keys() {
+		// the state objects are copied, so that the changes of the copy are not visible in the original statedb
 		v, ok := s.stateObjects.Peek(k)
 		if ok {
-			copies.Add(k, v)
+			copies.Add(k, v.(*StateObject).GetCopy())
 		}
 	}
 
@@ -186,18 +199,151 @@ func (s *Statedb) getStateObject(addr common.Address) *StateObject {
 	value, ok := s.stateObjects.Get(addr)
 	if ok {
 		object := value.(*StateObject)
+		s.journal(addr, object)
 		return object
 	}
 
 	object := newStateObject(addr)
 	val, _ := s.trie.Get(addr[:])
 	if len(val) == 0 {
+		s.journal(addr, nil)
 		return nil
 	}
 
 	if err := rlp.DecodeBytes(val, &object.account); err != nil {
+		s.journal(addr, nil)
 		return nil
 	}
+	s.journal(addr, object)
 	s.cache(addr, object)
 	return object
 }
+
+// journal keeps the copy of the state object in the latest snapshot if it is
+// touched for the first time since the snapshot is taken.
+func (s *Statedb) journal(addr common.Address, object *StateObject) {
+	if len(s.snapshots) == 0 {
+		return
+	}
+
+	latest := s.snapshots[len(s.snapshots)-1]
+	if _, found := latest.objects[addr]; found {
+		return
+	}
+
+	if object != nil {
+		object = object.GetCopy()
+	}
+
+	latest.objects[addr] = object
+}
+
+// AccountDiff is an account whose value differs between two states.
+// The account is nil if it does not exist in the state.
+type AccountDiff struct {
+	Address common.Address
+	Other   *Account // account in the state of the specified root
+	Current *Account // account in the current state
+}
+
+// Diff returns the accounts whose values differ between the current state and the state
+// of the specified root in address ASC order. Both state tries are fully iterated, so it is
+// expensive and only for debugging. Note, the current state should be committed before.
+func (s *Statedb) Diff(root common.Hash) ([]AccountDiff, error) {
+	other, err := trie.NewTrie(root, []byte("S"), s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	otherAccounts, err := trieAccounts(other)
+	if err != nil {
+		return nil, err
+	}
+
+	currentAccounts, err := trieAccounts(s.trie)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []AccountDiff
+	for addr, current := range currentAccounts {
+		if o, ok := otherAccounts[addr]; !ok || !bytes.Equal(o, current) {
+			diffs = append(diffs, newAccountDiff(addr, o, current))
+		}
+	}
+
+	for addr, o := range otherAccounts {
+		if _, ok := currentAccounts[addr]; !ok {
+			diffs = append(diffs, newAccountDiff(addr, o, nil))
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return bytes.Compare(diffs[i].Address.Bytes(), diffs[j].Address.Bytes()) < 0
+	})
+
+	return diffs, nil
+}
+
+// ForEachAccount visits all the accounts of the committed state trie in the key order,
+// together with the code of the contract accounts. Nodes are loaded on the fly, so that
+// the whole state is never held in memory. The iteration stops on the first error returned
+// by the callback. Note, the uncommitted changes of the state are not visited.
+func (s *Statedb) ForEachAccount(callback func(addr common.Address, account *Account, code []byte) error) error {
+	var cbErr error
+	err := s.trie.ForEach(func(key, value []byte) {
+		if cbErr != nil {
+			return
+		}
+
+		account := new(Account)
+		if cbErr = rlp.DecodeBytes(value, account); cbErr != nil {
+			return
+		}
+
+		var code []byte
+		if !account.CodeHash.IsEmpty() {
+			if code, cbErr = s.db.Get(append(keyPrefixCode, key...)); cbErr != nil {
+				return
+			}
+		}
+
+		cbErr = callback(common.BytesToAddress(key), account, code)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return cbErr
+}
+
+func trieAccounts(t *trie.Trie) (map[common.Address][]byte, error) {
+	accounts := make(map[common.Address][]byte)
+	err := t.ForEach(func(key, value []byte) {
+		accounts[common.BytesToAddress(key)] = value
+	})
+
+	return accounts, err
+}
+
+func newAccountDiff(addr common.Address, other, current []byte) AccountDiff {
+	return AccountDiff{
+		Address: addr,
+		Other:   decodeAccount(other),
+		Current: decodeAccount(current),
+	}
+}
+
+func decodeAccount(data []byte) *Account {
+	if len(data) == 0 {
+		return nil
+	}
+
+	account := new(Account)
+	if err := rlp.DecodeBytes(data, account); err != nil {
+		return nil
+	}
+
+	return account
+}
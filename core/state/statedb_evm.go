@@ -126,18 +126,51 @@ func (s *Statedb) Empty(address common.Address) bool {
 
 // RevertToSnapshot reverts all state changes made since the given revision.
 func (s *Statedb) RevertToSnapshot(revid int) {
-	// @todo
+	if revid < 0 || revid >= len(s.snapshots) {
+		return
+	}
+
+	// the objects are restored from the latest snapshot, so that the copies
+	// of the earliest snapshot are kept once the object is touched in several
+	for i := len(s.snapshots) - 1; i >= revid; i-- {
+		for addr, object := range s.snapshots[i].objects {
+			if object == nil {
+				// the object may be committed into the trie if the cache is full
+				s.stateObjects.Remove(addr)
+				s.trie.Delete(addr[:])
+				continue
+			}
+
+			// committed again in case of the changes committed into the trie
+			object.dirtyAccount = true
+			s.cache(addr, object)
+		}
+	}
+
+	s.logs = s.logs[:s.snapshots[revid].logs]
+	s.snapshots = s.snapshots[:revid]
 }
 
 // Snapshot returns an identifier for the current revision of the statedb.
 func (s *Statedb) Snapshot() int {
-	// @todo
-	return 0
+	s.snapshots = append(s.snapshots, &snapshot{
+		objects: make(map[common.Address]*StateObject),
+		logs:    len(s.logs),
+	})
+
+	return len(s.snapshots) - 1
 }
 
 // AddLog adds a log.
 func (s *Statedb) AddLog(log *types.Log) {
-	// @todo
+	s.logs = append(s.logs, log)
+}
+
+// TakeLogs returns the logs added since the last call and clears them.
+func (s *Statedb) TakeLogs() []*types.Log {
+	logs := s.logs
+	s.logs = nil
+	return logs
 }
 
 // AddPreimage records a SHA3 preimage seen by the VM.
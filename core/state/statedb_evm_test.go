@@ -6,10 +6,12 @@
 package state
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/magiconair/properties/assert"
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/crypto"
 )
 
@@ -85,3 +87,39 @@ func Test_Code(t *testing.T) {
 	assert.Equal(t, statedb2.GetCodeSize(addr), len(code))
 	assert.Equal(t, stateObj.dirtyCode, false)
 }
+
+func Test_Snapshot(t *testing.T) {
+	statedb, stateObj, dispose := newTestEVMStateDB()
+	defer dispose()
+
+	addr := stateObj.address
+	statedb.SetBalance(addr, big.NewInt(100))
+	statedb.SetNonce(addr, 1)
+
+	// the changes since the snapshot are reverted
+	revid := statedb.Snapshot()
+	statedb.SubBalance(addr, big.NewInt(10))
+	statedb.SetNonce(addr, 2)
+	created := *crypto.MustGenerateRandomAddress()
+	statedb.CreateAccount(created)
+	statedb.AddBalance(created, big.NewInt(10))
+	statedb.AddLog(&types.Log{Address: created})
+
+	// the nested snapshot is reverted as well
+	statedb.Snapshot()
+	statedb.SubBalance(addr, big.NewInt(20))
+
+	statedb.RevertToSnapshot(revid)
+	assert.Equal(t, statedb.GetBalance(addr), big.NewInt(100))
+	assert.Equal(t, statedb.GetNonce(addr), uint64(1))
+	assert.Equal(t, statedb.Exist(created), false)
+	assert.Equal(t, len(statedb.TakeLogs()), 0)
+
+	// the same state as the changes before the snapshot only
+	expected, err := NewStatedb(common.EmptyHash, statedb.db)
+	assert.Equal(t, err, error(nil))
+	expected.CreateAccount(addr)
+	expected.SetBalance(addr, big.NewInt(100))
+	expected.SetNonce(addr, 1)
+	assert.Equal(t, statedb.Commit(nil), expected.Commit(nil))
+}
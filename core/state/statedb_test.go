@@ -201,3 +201,112 @@ func TestStatedb_Cache(t *testing.T) {
 		t.Error("trie root hash should changed")
 	}
 }
+
+func Test_Statedb_Diff(t *testing.T) {
+	db, remove := newTestStateDB()
+	defer remove()
+
+	addr1, addr2, addr3 := getAddr(1), getAddr(2), getAddr(3)
+
+	statedb, _ := NewStatedb(common.EmptyHash, db)
+	statedb.GetOrNewStateObject(addr1).SetAmount(big.NewInt(10))
+	statedb.GetOrNewStateObject(addr2).SetAmount(big.NewInt(20))
+
+	batch := db.NewBatch()
+	root := statedb.Commit(batch)
+	batch.Commit()
+
+	statedb, _ = NewStatedb(root, db)
+	statedb.SetBalance(addr1, big.NewInt(11))
+	statedb.GetOrNewStateObject(addr3).SetAmount(big.NewInt(30))
+	statedb.Commit(nil)
+
+	diffs, err := statedb.Diff(root)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(diffs), 2)
+
+	for _, diff := range diffs {
+		switch diff.Address {
+		case addr1:
+			assert.Equal(t, diff.Other.Amount, big.NewInt(10))
+			assert.Equal(t, diff.Current.Amount, big.NewInt(11))
+		case addr3:
+			assert.Equal(t, diff.Other == nil, true)
+			assert.Equal(t, diff.Current.Amount, big.NewInt(30))
+		default:
+			t.Fatalf("unexpected diff account %s", diff.Address.ToHex())
+		}
+	}
+
+	// no diff against itself
+	statedb, _ = NewStatedb(root, db)
+	diffs, err = statedb.Diff(root)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(diffs), 0)
+}
+
+func Test_Statedb_ForEachAccount_Import(t *testing.T) {
+	db, remove := newTestStateDB()
+	defer remove()
+
+	statedb, _ := NewStatedb(common.EmptyHash, db)
+	for i := 1; i <= 10; i++ {
+		statedb.GetOrNewStateObject(getAddr(i)).SetAmount(big.NewInt(int64(i)))
+	}
+	statedb.SetCode(getAddr(1), []byte("contract code"))
+
+	batch := db.NewBatch()
+	root := statedb.Commit(batch)
+	batch.Commit()
+
+	otherDB, removeOther := newTestStateDB()
+	defer removeOther()
+
+	importer, err := NewImporter(otherDB)
+	assert.Equal(t, err, error(nil))
+
+	statedb, _ = NewStatedb(root, db)
+	visited := 0
+	err = statedb.ForEachAccount(func(addr common.Address, account *Account, code []byte) error {
+		visited++
+		return importer.Put(addr, account, code)
+	})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, visited, 10)
+
+	imported, err := importer.Commit()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, imported, root)
+
+	statedb, _ = NewStatedb(imported, otherDB)
+	assert.Equal(t, statedb.GetBalance(getAddr(10)), big.NewInt(10))
+	assert.Equal(t, statedb.GetCode(getAddr(1)), []byte("contract code"))
+
+	// code tampered
+	account := &Account{Amount: big.NewInt(1), CodeHash: statedb.GetCodeHash(getAddr(1))}
+	assert.Equal(t, importer.Put(getAddr(11), account, []byte("other code")), ErrCodeHashMismatch)
+	assert.Equal(t, importer.Put(getAddr(11), account, nil), ErrCodeHashMismatch)
+}
+
+func Test_Statedb_GetCopy(t *testing.T) {
+	db, remove := newTestStateDB()
+	defer remove()
+
+	statedb, err := NewStatedb(common.Hash{}, db)
+	if err != nil {
+		panic(err)
+	}
+
+	addr := getAddr(1)
+	statedb.GetOrNewStateObject(addr).SetAmount(big.NewInt(10))
+
+	copied, err := statedb.GetCopy()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, copied.GetBalance(addr), big.NewInt(10))
+
+	// the changes of the copy are not visible in the original statedb
+	copied.GetOrNewStateObject(addr).SetAmount(big.NewInt(20))
+	copied.SetNonce(addr, 1)
+	assert.Equal(t, statedb.GetBalance(addr), big.NewInt(10))
+	assert.Equal(t, statedb.GetNonce(addr), uint64(0))
+}
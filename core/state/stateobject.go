@@ -45,8 +45,12 @@ func newStateObject(address common.Address) *StateObject {
 
 // GetCopy gets a copy of the state object
 func (s *StateObject) GetCopy() *StateObject {
-	codeCloned := make([]byte, len(s.code))
-	copy(codeCloned, s.code)
+	// the nil code is kept to load from the database lazily
+	var codeCloned []byte
+	if s.code != nil {
+		codeCloned = make([]byte, len(s.code))
+		copy(codeCloned, s.code)
+	}
 
 	return &StateObject{
 		address: s.address,
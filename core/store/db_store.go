@@ -17,11 +17,14 @@ import (
 
 var (
 	keyHeadBlockHash = []byte("HeadBlockHash")
-
-	keyPrefixHash   = []byte("H")
-	keyPrefixHeader = []byte("h")
-	keyPrefixTD     = []byte("t")
-	keyPrefixBody   = []byte("b")
+	keySchemaVersion = []byte("SchemaVersion")
+
+	keyPrefixHash         = []byte("H")
+	keyPrefixHeader       = []byte("h")
+	keyPrefixTD           = []byte("t")
+	keyPrefixBody         = []byte("b")
+	keyPrefixReceipts     = []byte("r")
+	keyPrefixReceiptBlock = []byte("R")
 )
 
 // blockBody represents the payload of a block
@@ -41,6 +44,10 @@ type blockchainDatabase struct {
 //   3) keyPrefixHeader + hash => header
 //   4) keyPrefixTD + hash => total difficulty (td for short)
 //   5) keyPrefixBody + hash => block body (transactions)
+//   6) keySchemaVersion => schema version
+//   7) keyPrefixReceipts + hash => block receipts
+//   8) keyPrefixReceiptBlock + tx hash => hash of the block the receipt is produced in
+//   9) keyTxIndexProgress => next height to index when rebuilding the tx indices
 func NewBlockchainDatabase(db database.Database) BlockchainStore {
 	return &blockchainDatabase{db}
 }
@@ -49,6 +56,9 @@ func heightToHashKey(height uint64) []byte { return append(keyPrefixHash, encode
 func hashToHeaderKey(hash []byte) []byte   { return append(keyPrefixHeader, hash...) }
 func hashToTDKey(hash []byte) []byte       { return append(keyPrefixTD, hash...) }
 func hashToBodyKey(hash []byte) []byte     { return append(keyPrefixBody, hash...) }
+func hashToReceiptsKey(hash []byte) []byte { return append(keyPrefixReceipts, hash...) }
+
+func txHashToReceiptBlockKey(txHash []byte) []byte { return append(keyPrefixReceiptBlock, txHash...) }
 
 // GetBlockHash gets the hash of the block with the specified height in the blockchain database
 func (store *blockchainDatabase) GetBlockHash(height uint64) (common.Hash, error) {
@@ -91,6 +101,21 @@ func encodeBlockHeight(height uint64) []byte {
 	return encoded
 }
 
+// GetSchemaVersion gets the schema version in the blockchain database
+func (store *blockchainDatabase) GetSchemaVersion() (uint64, error) {
+	versionBytes, err := store.db.Get(keySchemaVersion)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.BigEndian.Uint64(versionBytes), nil
+}
+
+// PutSchemaVersion puts the schema version to the blockchain database
+func (store *blockchainDatabase) PutSchemaVersion(version uint64) error {
+	return store.db.Put(keySchemaVersion, encodeBlockHeight(version))
+}
+
 // GetHeadBlockHash gets the HEAD block hash in the blockchain database
 func (store *blockchainDatabase) GetHeadBlockHash() (common.Hash, error) {
 	hashBytes, err := store.db.Get(keyHeadBlockHash)
@@ -239,3 +264,43 @@ func (store *blockchainDatabase) GetBlockByHeight(height uint64) (*types.Block,
 	}
 	return block, nil
 }
+
+// PutReceipts puts the receipts of the block with the specified hash, and the tx-hash-to-blockHash
+// index of the receipts in a batch into the blockchain database.
+func (store *blockchainDatabase) PutReceipts(hash common.Hash, receipts []*types.Receipt) error {
+	hashBytes := hash.Bytes()
+
+	batch := store.db.NewBatch()
+	batch.Put(hashToReceiptsKey(hashBytes), common.SerializePanic(receipts))
+
+	for _, receipt := range receipts {
+		batch.Put(txHashToReceiptBlockKey(receipt.TxHash.Bytes()), hashBytes)
+	}
+
+	return batch.Commit()
+}
+
+// GetReceipts gets the receipts of the block with the specified hash in the blockchain database
+func (store *blockchainDatabase) GetReceipts(hash common.Hash) ([]*types.Receipt, error) {
+	receiptsBytes, err := store.db.Get(hashToReceiptsKey(hash.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+
+	var receipts []*types.Receipt
+	if err := common.Deserialize(receiptsBytes, &receipts); err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+// GetReceiptBlockHash gets the hash of the block in which the receipt of the specified tx is produced
+func (store *blockchainDatabase) GetReceiptBlockHash(txHash common.Hash) (common.Hash, error) {
+	hashBytes, err := store.db.Get(txHashToReceiptBlockKey(txHash.Bytes()))
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	return common.BytesToHash(hashBytes), nil
+}
@@ -0,0 +1,104 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/database"
+	leveldbErrors "github.com/syndtr/goleveldb/leveldb/errors"
+)
+
+var (
+	keyTxIndexProgress = []byte("TxIndexProgress")
+
+	// ErrPrefixDeleteNotSupported is returned when rebuilding the indices in the database
+	// that could not delete the entries by prefix.
+	ErrPrefixDeleteNotSupported = errors.New("database does not support deleting by prefix")
+)
+
+// RebuildTxIndices removes all the tx-hash-to-blockHash indices, including the stale ones
+// of the forks, and indexes the receipts of the canonical blocks from genesis again. The
+// rebuild is resumable, in which the next height to index is persisted along with the
+// indices, and onProgress is called with the indexed height and the HEAD height if not nil.
+func RebuildTxIndices(ctx context.Context, db database.Database, onProgress func(height, head uint64)) error {
+	store := &blockchainDatabase{db}
+
+	headHash, err := store.GetHeadBlockHash()
+	if err != nil {
+		return err
+	}
+
+	head, err := store.GetBlockHeader(headHash)
+	if err != nil {
+		return err
+	}
+
+	var next uint64
+	resumed, err := db.Has(keyTxIndexProgress)
+	if err != nil {
+		return err
+	}
+
+	if resumed {
+		encoded, err := db.Get(keyTxIndexProgress)
+		if err != nil {
+			return err
+		}
+
+		if err = common.Deserialize(encoded, &next); err != nil {
+			return err
+		}
+	} else {
+		deleter, ok := db.(database.PrefixDeleter)
+		if !ok {
+			return ErrPrefixDeleteNotSupported
+		}
+
+		// persist the progress before deleting, so that an interrupted deletion is not redone
+		if err = db.Put(keyTxIndexProgress, common.SerializePanic(next)); err != nil {
+			return err
+		}
+
+		if _, err = deleter.DeletePrefix(keyPrefixReceiptBlock); err != nil {
+			return err
+		}
+	}
+
+	for height := next; height <= head.Height; height++ {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		hash, err := store.GetBlockHash(height)
+		if err != nil {
+			return err
+		}
+
+		receipts, err := store.GetReceipts(hash)
+		if err != nil && err != leveldbErrors.ErrNotFound {
+			return err
+		}
+
+		batch := db.NewBatch()
+		for _, receipt := range receipts {
+			batch.Put(txHashToReceiptBlockKey(receipt.TxHash.Bytes()), hash.Bytes())
+		}
+
+		batch.Put(keyTxIndexProgress, common.SerializePanic(height+1))
+		if err = batch.Commit(); err != nil {
+			return err
+		}
+
+		if onProgress != nil {
+			onProgress(height, head.Height)
+		}
+	}
+
+	return db.Delete(keyTxIndexProgress)
+}
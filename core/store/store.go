@@ -12,6 +12,10 @@ import (
 	"github.com/seeleteam/go-seele/core/types"
 )
 
+// SchemaVersion is the version of the blockchain database layout, which should be
+// increased once the layout is changed incompatibly.
+const SchemaVersion uint64 = 13
+
 // BlockchainStore is the interface that wraps the atomic CRUD methods of blockchain.
 type BlockchainStore interface {
 	// GetBlockHash retrieves the block hash for the specified canonical block height.
@@ -48,4 +52,21 @@ type BlockchainStore interface {
 
 	// GetBlockByHeight retrieves the block for the specified block height.
 	GetBlockByHeight(height uint64) (*types.Block, error)
+
+	// PutReceipts writes the receipts of the block with the specified hash, and indexes
+	// the block hash by the tx hash of each receipt.
+	PutReceipts(hash common.Hash, receipts []*types.Receipt) error
+
+	// GetReceipts retrieves the receipts of the block with the specified hash.
+	GetReceipts(hash common.Hash) ([]*types.Receipt, error)
+
+	// GetReceiptBlockHash retrieves the hash of the block in which the receipt of the specified tx
+	// is produced. If the tx is packed in several forks, the block hash of the last written one is returned.
+	GetReceiptBlockHash(txHash common.Hash) (common.Hash, error)
+
+	// GetSchemaVersion retrieves the schema version the database is created with.
+	GetSchemaVersion() (uint64, error)
+
+	// PutSchemaVersion writes the schema version of the database.
+	PutSchemaVersion(version uint64) error
 }
@@ -6,6 +6,7 @@
 package store
 
 import (
+	"context"
 	"io/ioutil"
 	"math/big"
 	"os"
@@ -84,12 +85,15 @@ func newTestTx() *types.Transaction {
 	return &types.Transaction{
 		Hash: common.EmptyHash,
 		Data: &types.TransactionData{
-			From:    *crypto.MustGenerateRandomAddress(),
-			To:      crypto.MustGenerateRandomAddress(),
-			Amount:  big.NewInt(3),
-			Payload: make([]byte, 0),
+			From:     *crypto.MustGenerateRandomAddress(),
+			To:       crypto.MustGenerateRandomAddress(),
+			Amount:   big.NewInt(3),
+			GasPrice: big.NewInt(1),
+			GasLimit: types.TransferGas,
+			Payload:  make([]byte, 0),
 		},
-		Signature: &crypto.Signature{big.NewInt(1), big.NewInt(2)},
+		Signature:    &crypto.Signature{R: big.NewInt(1), S: big.NewInt(2)},
+		CoSignatures: make([]*crypto.Signature, 0),
 	}
 }
 
@@ -110,3 +114,88 @@ func Test_blockchainDatabase_Block(t *testing.T) {
 		assert.Equal(t, storedBlock, block)
 	})
 }
+
+func Test_blockchainDatabase_Receipts(t *testing.T) {
+	blockHash := common.StringToHash("block")
+	receipts := []*types.Receipt{
+		{TxHash: common.StringToHash("tx1"), PostState: common.StringToHash("state1"), UsedGas: 1, Fee: big.NewInt(1)},
+		{TxHash: common.StringToHash("tx2"), PostState: common.StringToHash("state2"), UsedGas: 2, Fee: big.NewInt(2)},
+	}
+
+	testBlockchainDatabase(func(bcStore BlockchainStore) {
+		_, err := bcStore.GetReceipts(blockHash)
+		assert.Equal(t, err != nil, true)
+
+		assert.Equal(t, bcStore.PutReceipts(blockHash, receipts), error(nil))
+
+		storedReceipts, err := bcStore.GetReceipts(blockHash)
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, types.ReceiptMerkleRootHash(storedReceipts), types.ReceiptMerkleRootHash(receipts))
+
+		hash, err := bcStore.GetReceiptBlockHash(receipts[1].TxHash)
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, hash, blockHash)
+	})
+}
+
+func Test_blockchainDatabase_SchemaVersion(t *testing.T) {
+	testBlockchainDatabase(func(bcStore BlockchainStore) {
+		_, err := bcStore.GetSchemaVersion()
+		assert.Equal(t, err != nil, true)
+
+		assert.Equal(t, bcStore.PutSchemaVersion(SchemaVersion), error(nil))
+
+		version, err := bcStore.GetSchemaVersion()
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, version, SchemaVersion)
+	})
+}
+
+func Test_RebuildTxIndices(t *testing.T) {
+	testBlockchainDatabase(func(bcStore BlockchainStore) {
+		db := bcStore.(*blockchainDatabase).db
+
+		var hashes []common.Hash
+		for height := uint64(0); height < 3; height++ {
+			header := newTestBlockHeader(t)
+			header.Height = height
+			hash := header.Hash()
+			hashes = append(hashes, hash)
+
+			assert.Equal(t, bcStore.PutBlockHeader(hash, header, header.Difficulty, true), error(nil))
+			if height > 0 {
+				receipts := []*types.Receipt{{TxHash: common.StringToHash(string([]byte{byte(height)})), Fee: big.NewInt(1)}}
+				assert.Equal(t, bcStore.PutReceipts(hash, receipts), error(nil))
+			}
+		}
+
+		// stale index of a fork block
+		staleTx := common.StringToHash("stale")
+		assert.Equal(t, bcStore.PutReceipts(common.StringToHash("fork"), []*types.Receipt{{TxHash: staleTx, Fee: big.NewInt(1)}}), error(nil))
+
+		var indexed []uint64
+		err := RebuildTxIndices(context.Background(), db, func(height, head uint64) {
+			assert.Equal(t, head, uint64(2))
+			indexed = append(indexed, height)
+		})
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, indexed, []uint64{0, 1, 2})
+
+		_, err = bcStore.GetReceiptBlockHash(staleTx)
+		assert.Equal(t, err != nil, true)
+
+		hash, err := bcStore.GetReceiptBlockHash(common.StringToHash(string([]byte{2})))
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, hash, hashes[2])
+
+		exist, err := db.Has(keyTxIndexProgress)
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, exist, false)
+
+		// resumed from the persisted height
+		assert.Equal(t, db.Put(keyTxIndexProgress, common.SerializePanic(uint64(2))), error(nil))
+		indexed = nil
+		assert.Equal(t, RebuildTxIndices(context.Background(), db, func(height, head uint64) { indexed = append(indexed, height) }), error(nil))
+		assert.Equal(t, indexed, []uint64{2})
+	})
+}
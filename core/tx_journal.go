@@ -0,0 +1,120 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultJournalRotate is the default interval to rotate the journal of the local txs.
+const DefaultJournalRotate = time.Hour
+
+var errNoJournal = errors.New("tx journal is disabled")
+
+// txJournal is the append-only journal of the local txs on disk, so that the local txs are not
+// lost once the node restarts. Each line of the journal is a JSON encoded PooledTransaction, and
+// the journal is rotated to the local txs remaining in the pool to drop the included ones.
+type txJournal struct {
+	path    string
+	writer  *os.File // nil until the journal is loaded
+	rotated time.Time
+}
+
+func newTxJournal(path string) *txJournal {
+	return &txJournal{path: path}
+}
+
+// load reads the txs in the journal, in which a truncated tail, e.g. of a crash, is ignored.
+func (journal *txJournal) load() ([]*PooledTransaction, error) {
+	file, err := os.Open(journal.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var txs []*PooledTransaction
+	decoder := json.NewDecoder(file)
+	for {
+		var tx PooledTransaction
+		if err = decoder.Decode(&tx); err != nil {
+			break
+		}
+
+		if tx.Tx != nil && tx.Tx.Data != nil {
+			txs = append(txs, &tx)
+		}
+	}
+
+	return txs, nil
+}
+
+// insert appends the tx to the journal.
+func (journal *txJournal) insert(tx *PooledTransaction) error {
+	if journal.writer == nil {
+		return errNoJournal
+	}
+
+	return json.NewEncoder(journal.writer).Encode(tx)
+}
+
+// rotate rewrites the journal with the specified txs, and then appends the later txs to it.
+func (journal *txJournal) rotate(txs []*PooledTransaction, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(journal.path), 0700); err != nil {
+		return err
+	}
+
+	temp := journal.path + ".new"
+	file, err := os.OpenFile(temp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, tx := range txs {
+		if err = encoder.Encode(tx); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(temp, journal.path); err != nil {
+		return err
+	}
+
+	if journal.writer != nil {
+		journal.writer.Close()
+	}
+
+	if journal.writer, err = os.OpenFile(journal.path, os.O_WRONLY|os.O_APPEND, 0600); err != nil {
+		return err
+	}
+
+	journal.rotated = now
+	return nil
+}
+
+// close closes the journal, after which the txs are not appended.
+func (journal *txJournal) close() error {
+	if journal.writer == nil {
+		return nil
+	}
+
+	err := journal.writer.Close()
+	journal.writer = nil
+	return err
+}
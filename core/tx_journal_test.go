@@ -0,0 +1,70 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_TransactionPool_Journal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "txjournal")
+	assert.Equal(t, err, error(nil))
+	defer os.RemoveAll(dir)
+
+	config := DefaultTxPoolConfig()
+	config.Journal = filepath.Join(dir, "transactions.journal")
+	chain := newMockBlockchain()
+
+	pool := NewTransactionPool(*config, chain)
+	replayed, err := pool.LoadJournal()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, replayed, 0)
+
+	remoteTx := newTestTx(t, 10, 0)
+	chain.addAccount(remoteTx.Data.From, 20+types.TransferGas, 0)
+	assert.Equal(t, pool.AddTransaction(remoteTx), error(nil))
+
+	localTx1 := newTestTx(t, 10, 0)
+	chain.addAccount(localTx1.Data.From, 20+types.TransferGas, 0)
+	assert.Equal(t, pool.AddLocalTransaction(localTx1), error(nil))
+
+	localTx2 := newTestTx(t, 10, 0)
+	chain.addAccount(localTx2.Data.From, 20+types.TransferGas, 0)
+	assert.Equal(t, pool.AddLocalTransaction(localTx2), error(nil))
+	pool.Stop()
+
+	// only the local txs are replayed on restart, with a truncated tail of a crash
+	file, err := os.OpenFile(config.Journal, os.O_WRONLY|os.O_APPEND, 0600)
+	assert.Equal(t, err, error(nil))
+	file.WriteString(`{"Tx":{"Hash":`)
+	file.Close()
+
+	pool = NewTransactionPool(*config, chain)
+	replayed, err = pool.LoadJournal()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, replayed, 2)
+	assert.Equal(t, pool.GetTransaction(remoteTx.Hash) == nil, true)
+	assert.Equal(t, pool.locals[localTx1.Hash], true)
+	assert.Equal(t, pool.locals[localTx2.Hash], true)
+
+	// the included tx is dropped from the journal once rotated
+	pool.RemoveTransaction(localTx1.Hash)
+	assert.Equal(t, pool.rotateJournal(time.Now(), time.Hour), error(nil))
+	assert.Equal(t, pool.rotateJournal(time.Now().Add(time.Hour), time.Hour), error(nil))
+	pool.Stop()
+
+	txs, err := newTxJournal(config.Journal).load()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(txs), 1)
+	assert.Equal(t, txs[0].Tx.Hash, localTx2.Hash)
+}
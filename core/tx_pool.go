@@ -7,51 +7,122 @@ package core
 
 import (
 	"errors"
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/resource"
 	"github.com/seeleteam/go-seele/core/state"
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/event"
 )
 
 var (
-	errTxHashExists = errors.New("transaction hash already exists")
-	errTxPoolFull   = errors.New("transaction pool is full")
+	errTxHashExists     = errors.New("transaction hash already exists")
+	errTxPoolFull       = errors.New("transaction pool is full")
+	errTxPoolThrottled  = errors.New("transaction pool is throttled for the soft resource limits")
+	errTxGasPriceTooLow = errors.New("transaction gas price is lower than the minimum gas price of the pool")
+	errTxUnderpriced    = errors.New("replacement transaction gas price is not bumped enough")
 )
 
 type blockchain interface {
+	CurrentBlock() (*types.Block, *state.Statedb)
 	CurrentState() *state.Statedb
+	ChainConfig() *types.ChainConfig
+	ValidateTxSigHash(tx *types.Transaction) error
 }
 
 // TransactionPool is a thread-safe container for transactions received
 // from the network or submitted locally. A transaction will be removed from
 // the pool once included in a blockchain.
+//
+// The txs of each sender are split into the pending txs, which are executable with the contiguous
+// nonces from the account nonce of the current state, and the queued txs of the future nonces after
+// a nonce gap. The queued txs are promoted once the gap is filled, so that only the pending txs are
+// processable by the miner.
 type TransactionPool struct {
-	mutex           sync.RWMutex
-	config          TransactionPoolConfig
-	chain           blockchain
-	hashToTxMap     map[common.Hash]*types.Transaction
-	accountToTxsMap map[common.Address]*txCollection // Account address to tx collection mapping.
+	mutex       sync.RWMutex
+	config      TransactionPoolConfig
+	chain       blockchain
+	hashToTxMap map[common.Hash]*types.Transaction
+	arrivals    map[common.Hash]time.Time        // arrivals are the times when the txs in the pool arrived
+	pending     map[common.Address]*txCollection // pending are the executable txs of each sender
+	queued      map[common.Address]*txCollection // queued are the txs after a nonce gap of each sender
+	inclusion   *inclusionTracker
+	seen        *seenTxCache            // seen caches the verdicts of the recent txs received from peers and RPC
+	locals      map[common.Hash]bool    // locals are the txs submitted via RPC of the node
+	localFroms  map[common.Address]bool // localFroms are the senders of the local txs, kept after the txs leave the pool
+	journal     *txJournal              // journal of the local txs, nil if disabled
+	feed        *txFeed                 // feed of the accepted txs to the subscribers
 }
 
 // NewTransactionPool creates and returns a transaction pool.
 func NewTransactionPool(config TransactionPoolConfig, chain blockchain) *TransactionPool {
 	pool := &TransactionPool{
-		config:          config,
-		chain:           chain,
-		hashToTxMap:     make(map[common.Hash]*types.Transaction),
-		accountToTxsMap: make(map[common.Address]*txCollection),
+		config:      config,
+		chain:       chain,
+		hashToTxMap: make(map[common.Hash]*types.Transaction),
+		arrivals:    make(map[common.Hash]time.Time),
+		pending:     make(map[common.Address]*txCollection),
+		queued:      make(map[common.Address]*txCollection),
+		inclusion:   newInclusionTracker(),
+		seen:        newSeenTxCache(config.SeenCacheSize, config.SeenCacheTTL),
+		locals:      make(map[common.Hash]bool),
+		localFroms:  make(map[common.Address]bool),
+		feed:        newTxFeed(),
 	}
 
+	if config.Journal != "" {
+		pool.journal = newTxJournal(config.Journal)
+	}
+
+	event.BlockInsertedEventManager.AddAsyncListener(pool.handleBlockInserted)
+	event.ChainReorgEventManager.AddAsyncListener(pool.handleChainReorg)
+
 	return pool
 }
 
 // AddTransaction adds a single transaction into the pool if it is valid and returns nil.
 // Otherwise, return the concrete error.
 func (pool *TransactionPool) AddTransaction(tx *types.Transaction) error {
-	statedb := pool.chain.CurrentState()
-	if err := tx.Validate(statedb); err != nil {
+	return pool.addTransaction(tx, false, time.Now())
+}
+
+// AddLocalTransaction adds a single transaction submitted locally, e.g. via RPC, into the pool.
+func (pool *TransactionPool) AddLocalTransaction(tx *types.Transaction) error {
+	return pool.addTransaction(tx, true, time.Now())
+}
+
+// addTransaction adds the tx into the pool, which arrived at the specified time.
+func (pool *TransactionPool) addTransaction(tx *types.Transaction, local bool, arrival time.Time) error {
+	head, statedb := pool.chain.CurrentBlock()
+	validate := func() error { return tx.ValidateWithoutState(pool.chain.ChainConfig()) }
+	if err := pool.seen.validate(tx, validate, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.ValidateState(statedb); err != nil {
+		return err
+	}
+
+	if err := pool.chain.ValidateTxSigHash(tx); err != nil {
+		return err
+	}
+
+	if tx.IsExpired(head.Header.Height+1, uint64(time.Now().Unix())) {
+		return types.ErrTxExpired
+	}
+
+	if err := tx.ValidateHTLC(head.Header.Height + 1); err != nil {
+		return err
+	}
+
+	if minPrice := pool.config.MinGasPrice; minPrice != nil && tx.Data.GasPrice.Cmp(minPrice) < 0 {
+		return errTxGasPriceTooLow
+	}
+
+	if err := pool.FeeFloor().validate(tx); err != nil {
 		return err
 	}
 
@@ -62,24 +133,163 @@ func (pool *TransactionPool) AddTransaction(tx *types.Transaction) error {
 		return errTxHashExists
 	}
 
-	if uint(len(pool.hashToTxMap)) >= pool.config.Capacity {
-		return errTxPoolFull
+	// replace the pending or queued tx of the same nonce if the gas price is bumped enough
+	replaced := pool.findTransaction(tx.Data.From, tx.Data.AccountNonce)
+	if replaced != nil && !pool.bumped(tx, replaced) {
+		return errTxUnderpriced
+	}
+
+	// evict a tx of the sender or the pool if the caps are hit
+	var evicted *EvictedTransaction
+	if replaced == nil {
+		var err error
+		if evicted, err = pool.evictionFor(tx); err != nil {
+			return err
+		}
+	}
+
+	if resource.Pool.Throttle() {
+		return errTxPoolThrottled
+	}
+
+	if replaced != nil {
+		pool.evict(&EvictedTransaction{replaced, EvictReasonReplaced})
+	}
+
+	if evicted != nil {
+		pool.evict(evicted)
 	}
 
 	pool.hashToTxMap[tx.Hash] = tx
+	pool.arrivals[tx.Hash] = arrival
+	resource.Pool.Alloc(tx.Size())
+	resource.Pool.SetQueue(len(pool.hashToTxMap))
 
-	if _, ok := pool.accountToTxsMap[tx.Data.From]; !ok {
-		pool.accountToTxsMap[tx.Data.From] = newTxCollection()
+	addToCollections(pool.queued, tx)
+	pool.promote(tx.Data.From, statedb.GetNonce(tx.Data.From))
+	pool.inclusion.enter(tx.Hash, arrival)
+	if local {
+		pool.locals[tx.Hash] = true
+		pool.localFroms[tx.Data.From] = true
+
+		// the tx is journaled again on the next rotation if failed
+		if pool.journal != nil {
+			pool.journal.insert(&PooledTransaction{tx, arrival, true})
+		}
 	}
 
-	pool.accountToTxsMap[tx.Data.From].add(tx)
+	// notify the subscribers, e.g. the miner and the relay to peers
+	pool.feed.send(tx)
 
-	// fire event
-	event.TransactionInsertedEventManager.Fire(tx)
+	return nil
+}
+
+// findTransaction returns the pending or queued tx of the account and nonce, or nil if not found.
+func (pool *TransactionPool) findTransaction(account common.Address, nonce uint64) *types.Transaction {
+	if collection := pool.pending[account]; collection != nil && collection.nonceToTxMap[nonce] != nil {
+		return collection.nonceToTxMap[nonce]
+	}
+
+	if collection := pool.queued[account]; collection != nil {
+		return collection.nonceToTxMap[nonce]
+	}
 
 	return nil
 }
 
+// promote re-splits the txs of the account with the specified account nonce of the current state,
+// which should be called with the pool locked. The queued txs are promoted to pending once the nonce
+// gap is filled, and the pending txs after a gap are demoted to queued. The txs of the nonces lower
+// than the account nonce are dropped since they could never be included.
+func (pool *TransactionPool) promote(account common.Address, stateNonce uint64) {
+	txs := newTxCollection()
+	for _, collections := range []map[common.Address]*txCollection{pool.pending, pool.queued} {
+		if collection := collections[account]; collection != nil {
+			for _, tx := range collection.getTxs() {
+				txs.add(tx)
+			}
+		}
+	}
+
+	delete(pool.pending, account)
+	delete(pool.queued, account)
+
+	for _, tx := range txs.getTxs() {
+		if tx.Data.AccountNonce < stateNonce {
+			txs.remove(tx.Data.AccountNonce)
+			pool.dropTransaction(tx)
+		}
+	}
+
+	for nonce := stateNonce; txs.nonceToTxMap[nonce] != nil; nonce++ {
+		addToCollections(pool.pending, txs.nonceToTxMap[nonce])
+		txs.remove(nonce)
+	}
+
+	if txs.count() > 0 {
+		pool.queued[account] = txs
+	}
+}
+
+// promoteTransactions re-splits the txs of all senders with the current state, see promote.
+func (pool *TransactionPool) promoteTransactions() {
+	statedb := pool.chain.CurrentState()
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	accounts := make(map[common.Address]bool)
+	for account := range pool.pending {
+		accounts[account] = true
+	}
+
+	for account := range pool.queued {
+		accounts[account] = true
+	}
+
+	for account := range accounts {
+		pool.promote(account, statedb.GetNonce(account))
+	}
+}
+
+// addToCollections adds the tx into the collection of its sender.
+func addToCollections(collections map[common.Address]*txCollection, tx *types.Transaction) {
+	if _, ok := collections[tx.Data.From]; !ok {
+		collections[tx.Data.From] = newTxCollection()
+	}
+
+	collections[tx.Data.From].add(tx)
+}
+
+// removeFromCollections removes the tx from the collection of its sender, and returns false if not found.
+func removeFromCollections(collections map[common.Address]*txCollection, tx *types.Transaction) bool {
+	collection := collections[tx.Data.From]
+	if collection == nil || collection.nonceToTxMap[tx.Data.AccountNonce] != tx {
+		return false
+	}
+
+	collection.remove(tx.Data.AccountNonce)
+	if collection.count() == 0 {
+		delete(collections, tx.Data.From)
+	}
+
+	return true
+}
+
+// bumped returns whether the gas price of the tx is at least the bump percentage higher than
+// the gas price of the pending tx to replace.
+func (pool *TransactionPool) bumped(tx, pending *types.Transaction) bool {
+	bump := pool.config.PriceBump
+	if bump == 0 {
+		bump = DefaultPriceBump
+	}
+
+	// gasPrice * 100 >= pendingPrice * (100 + bump)
+	price := new(big.Int).Mul(tx.Data.GasPrice, big.NewInt(100))
+	threshold := new(big.Int).Mul(pending.Data.GasPrice, big.NewInt(int64(100+bump)))
+	return price.Cmp(threshold) >= 0
+}
+
 // GetTransaction returns a transaction if it is contained in the pool and nil otherwise.
 func (pool *TransactionPool) GetTransaction(txHash common.Hash) *types.Transaction {
 	pool.mutex.RLock()
@@ -88,7 +298,49 @@ func (pool *TransactionPool) GetTransaction(txHash common.Hash) *types.Transacti
 	return pool.hashToTxMap[txHash]
 }
 
-// RemoveTransaction removes a transaction with the specified hash
+// status of the tx in the pool
+const (
+	TxStatusPending = "pending" // the tx is executable in the nonce order of its sender
+	TxStatusQueued  = "queued"  // the tx is after a nonce gap of its sender
+	TxStatusUnknown = "unknown" // the tx is not in the pool
+)
+
+// TransactionStatus returns whether the tx of the specified hash is pending or queued in the pool.
+func (pool *TransactionPool) TransactionStatus(txHash common.Hash) string {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	tx := pool.hashToTxMap[txHash]
+	if tx == nil {
+		return TxStatusUnknown
+	}
+
+	if collection := pool.pending[tx.Data.From]; collection != nil && collection.nonceToTxMap[tx.Data.AccountNonce] == tx {
+		return TxStatusPending
+	}
+
+	return TxStatusQueued
+}
+
+// PendingNonce returns the next nonce of the account after its pending txs in the pool, which is
+// the account nonce of the current state if no tx is pending.
+func (pool *TransactionPool) PendingNonce(account common.Address) uint64 {
+	nonce := pool.chain.CurrentState().GetNonce(account)
+
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	if collection := pool.pending[account]; collection != nil {
+		for collection.nonceToTxMap[nonce] != nil {
+			nonce++
+		}
+	}
+
+	return nonce
+}
+
+// RemoveTransaction removes a transaction with the specified hash. The pending txs of the
+// same sender after it are demoted to queued, since they are not executable any more.
 func (pool *TransactionPool) RemoveTransaction(txHash common.Hash) {
 	pool.mutex.Lock()
 	defer pool.mutex.Unlock()
@@ -98,26 +350,96 @@ func (pool *TransactionPool) RemoveTransaction(txHash common.Hash) {
 		return
 	}
 
-	collection := pool.accountToTxsMap[tx.Data.From]
-	if collection != nil {
-		collection.remove(tx.Data.AccountNonce)
-		if collection.count() == 0 {
-			delete(pool.accountToTxsMap, tx.Data.From)
+	pool.removeTransaction(tx)
+	pool.demote(tx)
+}
+
+// demote moves the pending txs of the same sender after the removed tx to queued, which is
+// called with the lock held.
+func (pool *TransactionPool) demote(removed *types.Transaction) {
+	if collection := pool.pending[removed.Data.From]; collection != nil {
+		for _, pending := range collection.getTxs() {
+			if pending.Data.AccountNonce > removed.Data.AccountNonce {
+				removeFromCollections(pool.pending, pending)
+				addToCollections(pool.queued, pending)
+			}
 		}
 	}
+}
 
-	delete(pool.hashToTxMap, txHash)
+// removeTransaction removes the tx in the pool, which is called with the lock held.
+func (pool *TransactionPool) removeTransaction(tx *types.Transaction) {
+	if !removeFromCollections(pool.pending, tx) {
+		removeFromCollections(pool.queued, tx)
+	}
+
+	pool.dropTransaction(tx)
 }
 
-// GetProcessableTransactions retrieves all processable transactions. The returned transactions
-// are grouped by original account addresses and sorted by nonce ASC.
+// dropTransaction drops the tx removed from the collections, which is called with the lock held.
+func (pool *TransactionPool) dropTransaction(tx *types.Transaction) {
+	delete(pool.hashToTxMap, tx.Hash)
+	delete(pool.arrivals, tx.Hash)
+	delete(pool.locals, tx.Hash)
+	resource.Pool.Free(tx.Size())
+	resource.Pool.SetQueue(len(pool.hashToTxMap))
+}
+
+// removeExpiredTransactions removes the transactions that could not be included
+// in the block of the specified height and timestamp.
+func (pool *TransactionPool) removeExpiredTransactions(height, timestamp uint64) {
+	pool.mutex.RLock()
+	var expiredTxs []common.Hash
+	for hash, tx := range pool.hashToTxMap {
+		if tx.IsExpired(height, timestamp) {
+			expiredTxs = append(expiredTxs, hash)
+		}
+	}
+	pool.mutex.RUnlock()
+
+	for _, hash := range expiredTxs {
+		pool.RemoveTransaction(hash)
+	}
+}
+
+// handleChainReorg re-injects the txs dropped from the blocks reorganized out into the pool, in
+// which the txs no longer valid on the new canonical chain, e.g. of the nonces used, are skipped.
+// The txs of the local senders are re-injected as local, so that they are journaled and kept again.
+func (pool *TransactionPool) handleChainReorg(e event.Event) {
+	reorg := e.(*ChainReorg)
+	now := time.Now()
+
+	for _, tx := range reorg.DroppedTxs {
+		pool.mutex.RLock()
+		local := pool.localFroms[tx.Data.From]
+		pool.mutex.RUnlock()
+
+		pool.addTransaction(tx, local, now)
+	}
+}
+
+// GetProcessableTransactions retrieves all processable transactions, i.e. the pending txs. The returned
+// transactions are grouped by original account addresses and sorted by nonce ASC.
 func (pool *TransactionPool) GetProcessableTransactions() map[common.Address][]*types.Transaction {
 	pool.mutex.RLock()
 	defer pool.mutex.RUnlock()
 
+	return sortedTransactions(pool.pending)
+}
+
+// GetQueuedTransactions retrieves all the queued txs after a nonce gap. The returned transactions
+// are grouped by original account addresses and sorted by nonce ASC.
+func (pool *TransactionPool) GetQueuedTransactions() map[common.Address][]*types.Transaction {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	return sortedTransactions(pool.queued)
+}
+
+func sortedTransactions(collections map[common.Address]*txCollection) map[common.Address][]*types.Transaction {
 	allAccountTxs := make(map[common.Address][]*types.Transaction)
 
-	for account, txs := range pool.accountToTxsMap {
+	for account, txs := range collections {
 		allAccountTxs[account] = txs.getTxsOrderByNonceAsc()
 	}
 
@@ -129,8 +451,20 @@ func (pool *TransactionPool) GetProcessableTransactionsCount() int {
 	pool.mutex.RLock()
 	defer pool.mutex.RUnlock()
 
+	return countTransactions(pool.pending)
+}
+
+// GetQueuedTransactionsCount returns the total number of the queued txs after a nonce gap.
+func (pool *TransactionPool) GetQueuedTransactionsCount() int {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	return countTransactions(pool.queued)
+}
+
+func countTransactions(collections map[common.Address]*txCollection) int {
 	status := 0
-	for _, collection := range pool.accountToTxsMap {
+	for _, collection := range collections {
 		if collection != nil {
 			status += collection.count()
 		}
@@ -138,7 +472,52 @@ func (pool *TransactionPool) GetProcessableTransactionsCount() int {
 	return status
 }
 
+// LoadJournal replays the local txs in the journal into the pool, and then rotates the journal to
+// the local txs in the pool, after which the local txs added are journaled. Returns the number of
+// the replayed txs, in which the txs that fail to add, e.g. already included, are skipped.
+func (pool *TransactionPool) LoadJournal() (int, error) {
+	if pool.journal == nil {
+		return 0, nil
+	}
+
+	txs, err := pool.journal.load()
+	if err != nil {
+		return 0, err
+	}
+
+	failures := pool.ImportTransactions(txs)
+	return len(txs) - len(failures), pool.rotateJournal(time.Now(), 0)
+}
+
+// rotateJournal rotates the journal to the local txs in the pool if the journal is not rotated in the
+// specified interval, so that the included or dropped local txs are not replayed on restart.
+func (pool *TransactionPool) rotateJournal(now time.Time, interval time.Duration) error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	// not loaded yet, or rotated recently
+	if (interval > 0 && pool.journal.writer == nil) || now.Sub(pool.journal.rotated) < interval {
+		return nil
+	}
+
+	var locals []*PooledTransaction
+	for _, pooled := range pool.exportTransactions() {
+		if pooled.Local {
+			locals = append(locals, pooled)
+		}
+	}
+
+	return pool.journal.rotate(locals, now)
+}
+
 // Stop terminates the transaction pool.
 func (pool *TransactionPool) Stop() {
-	// TODO remove event listeners
+	event.BlockInsertedEventManager.RemoveListener(pool.handleBlockInserted)
+	event.ChainReorgEventManager.RemoveListener(pool.handleChainReorg)
+
+	if pool.journal != nil {
+		pool.mutex.Lock()
+		pool.journal.close()
+		pool.mutex.Unlock()
+	}
 }
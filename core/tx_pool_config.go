@@ -5,14 +5,45 @@
 
 package core
 
+import (
+	"math/big"
+	"time"
+)
+
+// DefaultTxLifetime is the default maximum duration of the non-local txs in the pool.
+const DefaultTxLifetime = 3 * time.Hour
+
+// DefaultPriceBump is the default minimum percentage of the gas price increase to replace a pending tx.
+const DefaultPriceBump = 10
+
 // TransactionPoolConfig is the configuration of the transaction pool.
 type TransactionPoolConfig struct {
-	Capacity uint // Maximum number of transactions in the pool.
+	Capacity        uint          // Maximum number of transactions in the pool.
+	AccountCapacity uint          // Maximum number of transactions of a sender in the pool, 0 for no limit.
+	Lifetime        time.Duration // Maximum duration of the non-local transactions in the pool, DefaultTxLifetime if 0.
+	MinGasPrice     *big.Int      // Minimum gas price of transactions accepted by the pool, nil to accept any.
+	PriceBump       uint          // Minimum percentage of the gas price increase to replace a pending tx of the same nonce, DefaultPriceBump if 0.
+
+	MinFeePerByte        *big.Int // Minimum fee per byte of transactions accepted by the pool, nil or 0 to accept any, see FeeFloor.
+	MinPayloadFeePerByte *big.Int // Minimum fee per byte of the zero-amount transactions with payload, MinFeePerByte if nil.
+
+	SeenCacheSize int           // Maximum number of the seen txs whose verdicts are cached, DefaultSeenTxCacheSize if 0.
+	SeenCacheTTL  time.Duration // Duration to keep the verdict of a seen tx, DefaultSeenTxTTL if 0.
+
+	Journal       string        // Path of the journal of the local txs, which are replayed on restart, empty to disable.
+	JournalRotate time.Duration // Interval to rotate the journal to the local txs in the pool, DefaultJournalRotate if 0.
 }
 
 // DefaultTxPoolConfig returns the default configuration of the transaction pool.
 func DefaultTxPoolConfig() *TransactionPoolConfig {
 	return &TransactionPoolConfig{
-		Capacity: 1024,
+		Capacity:        1024,
+		AccountCapacity: 64,
+		Lifetime:        DefaultTxLifetime,
+		MinGasPrice:     big.NewInt(1),
+		PriceBump:       DefaultPriceBump,
+		SeenCacheSize:   DefaultSeenTxCacheSize,
+		SeenCacheTTL:    DefaultSeenTxTTL,
+		JournalRotate:   DefaultJournalRotate,
 	}
 }
@@ -0,0 +1,141 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/event"
+)
+
+// reasons of the txs evicted from the pool
+const (
+	EvictReasonReplaced   = "replaced"         // replaced by the tx of the same nonce with a bumped gas price
+	EvictReasonPoolFull   = "pool full"        // the lowest-priced tx evicted for a better-priced one once the pool is full
+	EvictReasonSenderFull = "sender full"      // the tx of the highest nonce evicted for a lower nonce once the sender is full
+	EvictReasonLifetime   = "lifetime expired" // the tx is in the pool beyond the lifetime
+)
+
+var errTxAccountFull = errors.New("transactions of the sender exceed the account capacity of the pool")
+
+// EvictedTransaction is the tx evicted from the pool before included, which is fired with
+// event.TransactionEvictedEventManager.
+type EvictedTransaction struct {
+	Tx     *types.Transaction
+	Reason string
+}
+
+// evictionFor returns the tx to evict for the specified tx if the sender or the pool is full, or an
+// error if the tx should be rejected. The tx of the highest nonce of the sender is evicted for a
+// lower nonce once the sender is full. Once the pool is full, the tx of the lowest gas price is evicted,
+// and the oldest one for the same price, in which only the tx of the highest nonce of each sender is
+// evicted to not leave a nonce gap, and the local txs are kept. It is called with the lock held.
+func (pool *TransactionPool) evictionFor(tx *types.Transaction) (*EvictedTransaction, error) {
+	if capacity := pool.config.AccountCapacity; capacity > 0 {
+		if txs := pool.accountTransactions(tx.Data.From); uint(len(txs)) >= capacity {
+			last := txs[len(txs)-1]
+			if last.Data.AccountNonce < tx.Data.AccountNonce {
+				return nil, errTxAccountFull
+			}
+
+			return &EvictedTransaction{last, EvictReasonSenderFull}, nil
+		}
+	}
+
+	if uint(len(pool.hashToTxMap)) < pool.config.Capacity {
+		return nil, nil
+	}
+
+	var cheapest *types.Transaction
+	var cheapestArrival time.Time
+	for account := range pool.senders() {
+		if account == tx.Data.From {
+			continue
+		}
+
+		txs := pool.accountTransactions(account)
+		last := txs[len(txs)-1]
+		if pool.locals[last.Hash] {
+			continue
+		}
+
+		arrival := pool.arrivals[last.Hash]
+		if cheapest == nil {
+			cheapest, cheapestArrival = last, arrival
+			continue
+		}
+
+		if cmp := last.Data.GasPrice.Cmp(cheapest.Data.GasPrice); cmp < 0 || (cmp == 0 && arrival.Before(cheapestArrival)) {
+			cheapest, cheapestArrival = last, arrival
+		}
+	}
+
+	if cheapest == nil || cheapest.Data.GasPrice.Cmp(tx.Data.GasPrice) >= 0 {
+		return nil, errTxPoolFull
+	}
+
+	return &EvictedTransaction{cheapest, EvictReasonPoolFull}, nil
+}
+
+// senders returns the senders of the txs in the pool, which is called with the lock held.
+func (pool *TransactionPool) senders() map[common.Address]bool {
+	senders := make(map[common.Address]bool)
+	for account := range pool.pending {
+		senders[account] = true
+	}
+
+	for account := range pool.queued {
+		senders[account] = true
+	}
+
+	return senders
+}
+
+// accountTransactions returns the pending and queued txs of the sender sorted by nonce ASC,
+// which is called with the lock held.
+func (pool *TransactionPool) accountTransactions(account common.Address) []*types.Transaction {
+	var txs []*types.Transaction
+	if collection := pool.pending[account]; collection != nil {
+		txs = collection.getTxsOrderByNonceAsc()
+	}
+
+	if collection := pool.queued[account]; collection != nil {
+		txs = append(txs, collection.getTxsOrderByNonceAsc()...)
+	}
+
+	return txs
+}
+
+// evict removes the tx from the pool and fires the eviction event, which is called with the lock held.
+func (pool *TransactionPool) evict(evicted *EvictedTransaction) {
+	pool.removeTransaction(evicted.Tx)
+	pool.demote(evicted.Tx)
+	event.TransactionEvictedEventManager.Fire(evicted)
+}
+
+// evictStaleTransactions evicts the non-local txs in the pool beyond the lifetime since their arrival.
+func (pool *TransactionPool) evictStaleTransactions(now time.Time) {
+	lifetime := pool.config.Lifetime
+	if lifetime == 0 {
+		lifetime = DefaultTxLifetime
+	}
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	for hash, tx := range pool.hashToTxMap {
+		if pool.locals[hash] {
+			continue
+		}
+
+		if now.Sub(pool.arrivals[hash]) > lifetime {
+			pool.evict(&EvictedTransaction{tx, EvictReasonLifetime})
+		}
+	}
+}
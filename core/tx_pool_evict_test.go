@@ -0,0 +1,144 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/event"
+)
+
+// newTestEvictions returns the evicted txs fired by the pool.
+func newTestEvictions(t *testing.T) (*[]*EvictedTransaction, func()) {
+	var evicted []*EvictedTransaction
+	listener := func(e event.Event) { evicted = append(evicted, e.(*EvictedTransaction)) }
+	event.TransactionEvictedEventManager.AddListener(listener)
+
+	return &evicted, func() { event.TransactionEvictedEventManager.RemoveListener(listener) }
+}
+
+func newTestPricedTx(t *testing.T, chain *mockBlockchain, gasPrice int64) *types.Transaction {
+	fromPrivKey, fromAddress := randomAccount(t)
+	_, toAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 10+uint64(gasPrice)*types.TransferGas, 0)
+
+	tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(10), big.NewInt(gasPrice), types.TransferGas, 0)
+	tx.Sign(fromPrivKey)
+	return tx
+}
+
+func Test_TransactionPool_Evict_PoolFull(t *testing.T) {
+	evicted, dispose := newTestEvictions(t)
+	defer dispose()
+
+	config := DefaultTxPoolConfig()
+	config.Capacity = 2
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*config, chain)
+	defer pool.Stop()
+
+	cheap, local, remote := newTestPricedTx(t, chain, 1), newTestPricedTx(t, chain, 1), newTestPricedTx(t, chain, 3)
+	assert.Equal(t, pool.AddTransaction(cheap), error(nil))
+	assert.Equal(t, pool.AddLocalTransaction(local), error(nil))
+
+	// not better priced than the cheapest
+	assert.Equal(t, pool.AddTransaction(newTestPricedTx(t, chain, 1)), errTxPoolFull)
+
+	// the cheapest non-local tx is evicted
+	assert.Equal(t, pool.AddTransaction(remote), error(nil))
+	assert.Equal(t, pool.GetTransaction(cheap.Hash) == nil, true)
+	assert.Equal(t, *evicted, []*EvictedTransaction{{cheap, EvictReasonPoolFull}})
+
+	assert.Equal(t, pool.AddTransaction(newTestPricedTx(t, chain, 2)), errTxPoolFull)
+}
+
+func Test_TransactionPool_Evict_SenderFull(t *testing.T) {
+	evicted, dispose := newTestEvictions(t)
+	defer dispose()
+
+	config := DefaultTxPoolConfig()
+	config.AccountCapacity = 2
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*config, chain)
+	defer pool.Stop()
+
+	account, txs := newTestAccountTxs(t, []int64{1, 2, 3, 4}, []uint64{0, 5, 6, 1})
+	chain.addAccount(account, 10+types.TransferGas, 0)
+	assert.Equal(t, pool.AddTransaction(txs[0]), error(nil))
+	assert.Equal(t, pool.AddTransaction(txs[1]), error(nil))
+	assert.Equal(t, pool.AddTransaction(txs[2]), errTxAccountFull)
+
+	// the tx of the highest nonce is evicted for a lower nonce
+	assert.Equal(t, pool.AddTransaction(txs[3]), error(nil))
+	assert.Equal(t, pool.GetProcessableTransactions()[account], []*types.Transaction{txs[0], txs[3]})
+	assert.Equal(t, *evicted, []*EvictedTransaction{{txs[1], EvictReasonSenderFull}})
+}
+
+func Test_TransactionPool_Evict_Lifetime(t *testing.T) {
+	evicted, dispose := newTestEvictions(t)
+	defer dispose()
+
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	defer pool.Stop()
+
+	remote, local := newTestPricedTx(t, chain, 1), newTestPricedTx(t, chain, 1)
+	assert.Equal(t, pool.AddTransaction(remote), error(nil))
+	assert.Equal(t, pool.AddLocalTransaction(local), error(nil))
+
+	pool.evictStaleTransactions(time.Now())
+	assert.Equal(t, len(pool.hashToTxMap), 2)
+
+	// the local tx is kept
+	pool.evictStaleTransactions(time.Now().Add(DefaultTxLifetime + time.Minute))
+	assert.Equal(t, len(pool.hashToTxMap), 1)
+	assert.Equal(t, pool.GetTransaction(local.Hash), local)
+	assert.Equal(t, *evicted, []*EvictedTransaction{{remote, EvictReasonLifetime}})
+}
+
+func Test_TransactionPool_Evict_LongLifetime(t *testing.T) {
+	config := DefaultTxPoolConfig()
+	config.Lifetime = 2 * txEntryRetention
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*config, chain)
+	defer pool.Stop()
+
+	now := time.Now()
+	tx := newTestPricedTx(t, chain, 1)
+	assert.Equal(t, pool.addTransaction(tx, false, now), error(nil))
+
+	// the arrival is kept beyond the retention of the inclusion tracker
+	pool.inclusion.prune(now.Add(txEntryRetention + time.Minute))
+	pool.evictStaleTransactions(now.Add(txEntryRetention + time.Minute))
+	assert.Equal(t, pool.GetTransaction(tx.Hash), tx)
+
+	pool.evictStaleTransactions(now.Add(config.Lifetime + time.Minute))
+	assert.Equal(t, pool.GetTransaction(tx.Hash) == nil, true)
+}
+
+func Test_TransactionPool_Evict_Readded(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	defer pool.Stop()
+
+	now := time.Now()
+	tx := newTestPricedTx(t, chain, 1)
+	assert.Equal(t, pool.addTransaction(tx, false, now), error(nil))
+	pool.RemoveTransaction(tx.Hash)
+
+	// the lifetime starts from the latest arrival
+	readded := now.Add(DefaultTxLifetime)
+	assert.Equal(t, pool.addTransaction(tx, false, readded), error(nil))
+	pool.evictStaleTransactions(now.Add(DefaultTxLifetime + time.Minute))
+	assert.Equal(t, pool.GetTransaction(tx.Hash), tx)
+
+	pool.evictStaleTransactions(readded.Add(DefaultTxLifetime + time.Minute))
+	assert.Equal(t, pool.GetTransaction(tx.Hash) == nil, true)
+}
@@ -0,0 +1,75 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// PooledTransaction is the tx in the pool with its metadata, which is exported to migrate
+// the pending txs between nodes.
+type PooledTransaction struct {
+	Tx      *types.Transaction
+	Arrival time.Time // Arrival is the time when the tx entered the pool, zero if unknown
+	Local   bool      // Local is true if the tx is submitted via RPC of the node
+}
+
+// ExportTransactions returns all the txs in the pool with the metadata, which are sorted by the
+// arrival time, and by the nonce for the same sender.
+func (pool *TransactionPool) ExportTransactions() []*PooledTransaction {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	return pool.exportTransactions()
+}
+
+// exportTransactions returns the sorted txs in the pool, which should be called with the pool locked.
+func (pool *TransactionPool) exportTransactions() []*PooledTransaction {
+	txs := make([]*PooledTransaction, 0, len(pool.hashToTxMap))
+	for hash, tx := range pool.hashToTxMap {
+		txs = append(txs, &PooledTransaction{tx, pool.arrivals[hash], pool.locals[hash]})
+	}
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		if !txs[i].Arrival.Equal(txs[j].Arrival) {
+			return txs[i].Arrival.Before(txs[j].Arrival)
+		}
+
+		if txs[i].Tx.Data.From.Equal(txs[j].Tx.Data.From) {
+			return txs[i].Tx.Data.AccountNonce < txs[j].Tx.Data.AccountNonce
+		}
+
+		return bytes.Compare(txs[i].Tx.Hash.Bytes(), txs[j].Tx.Hash.Bytes()) < 0
+	})
+
+	return txs
+}
+
+// ImportTransactions adds the exported txs into the pool with their metadata, in which the
+// arrival time is kept. The txs that fail to add, e.g. already included, are skipped and
+// returned with the errors.
+func (pool *TransactionPool) ImportTransactions(txs []*PooledTransaction) map[common.Hash]error {
+	now := time.Now()
+	failures := make(map[common.Hash]error)
+
+	for _, pooled := range txs {
+		arrival := pooled.Arrival
+		if arrival.IsZero() || arrival.After(now) {
+			arrival = now
+		}
+
+		if err := pool.addTransaction(pooled.Tx, pooled.Local, arrival); err != nil {
+			failures[pooled.Tx.Hash] = err
+		}
+	}
+
+	return failures
+}
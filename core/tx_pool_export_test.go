@@ -0,0 +1,65 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_TransactionPool_ExportImport(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+
+	remoteTx := newTestTx(t, 10, 0)
+	chain.addAccount(remoteTx.Data.From, 20+types.TransferGas, 0)
+	assert.Equal(t, pool.AddTransaction(remoteTx), error(nil))
+
+	localTx := newTestTx(t, 10, 0)
+	chain.addAccount(localTx.Data.From, 20+types.TransferGas, 0)
+	assert.Equal(t, pool.AddLocalTransaction(localTx), error(nil))
+
+	arrival := time.Now().Add(-time.Minute)
+	pool.arrivals[remoteTx.Hash] = arrival
+
+	exported := pool.ExportTransactions()
+	assert.Equal(t, len(exported), 2)
+	assert.Equal(t, exported[0].Tx.Hash, remoteTx.Hash)
+	assert.Equal(t, exported[0].Local, false)
+	assert.Equal(t, exported[1].Tx.Hash, localTx.Hash)
+	assert.Equal(t, exported[1].Local, true)
+
+	// migrate via the encoded dump
+	encoded, err := json.Marshal(exported)
+	assert.Equal(t, err, error(nil))
+
+	var decoded []*PooledTransaction
+	assert.Equal(t, json.Unmarshal(encoded, &decoded), error(nil))
+
+	target := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	assert.Equal(t, len(target.ImportTransactions(decoded)), 0)
+	assert.Equal(t, target.GetProcessableTransactionsCount(), 2)
+	assert.Equal(t, target.locals[localTx.Hash], true)
+	assert.Equal(t, target.locals[remoteTx.Hash], false)
+
+	assert.Equal(t, target.arrivals[remoteTx.Hash].Equal(arrival), true)
+	entered, ok := target.inclusion.entered(remoteTx.Hash)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, entered.Equal(arrival), true)
+
+	// duplicate txs are skipped
+	failures := target.ImportTransactions(decoded)
+	assert.Equal(t, failures[remoteTx.Hash], errTxHashExists)
+	assert.Equal(t, failures[localTx.Hash], errTxHashExists)
+
+	// local flag is dropped once the tx is removed
+	target.RemoveTransaction(localTx.Hash)
+	assert.Equal(t, len(target.locals), 0)
+}
@@ -0,0 +1,60 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+var errTxFeeTooLow = errors.New("transaction fee per byte is lower than the minimum fee per byte of the pool")
+
+// FeeFloor is the minimum fee per byte of the txs accepted by the pool against the spam, in which the
+// fee is the min fee the tx certainly pays, i.e. GasPrice * TransferGas, so that the floor could not be
+// passed by raising the gas limit without paying more. The size is of the RLP encoding.
+type FeeFloor struct {
+	FeePerByte        *big.Int // minimum fee per byte of the txs, nil or 0 to accept any
+	PayloadFeePerByte *big.Int // minimum fee per byte of the zero-amount txs with payload, FeePerByte if nil
+}
+
+// validate returns errTxFeeTooLow if the fee per byte of the tx is lower than the floor.
+func (floor FeeFloor) validate(tx *types.Transaction) error {
+	min := floor.FeePerByte
+	if floor.PayloadFeePerByte != nil && tx.Data.Amount.Sign() == 0 && len(tx.Data.Payload) > 0 {
+		min = floor.PayloadFeePerByte
+	}
+
+	if min == nil || min.Sign() <= 0 {
+		return nil
+	}
+
+	// fee >= min * size
+	threshold := new(big.Int).Mul(min, big.NewInt(int64(tx.Size())))
+	if tx.MinFee().Cmp(threshold) < 0 {
+		return errTxFeeTooLow
+	}
+
+	return nil
+}
+
+// FeeFloor returns the minimum fee per byte of the txs accepted by the pool.
+func (pool *TransactionPool) FeeFloor() FeeFloor {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	return FeeFloor{pool.config.MinFeePerByte, pool.config.MinPayloadFeePerByte}
+}
+
+// SetFeeFloor sets the minimum fee per byte of the txs accepted by the pool afterwards, in which
+// the txs already in the pool are kept.
+func (pool *TransactionPool) SetFeeFloor(floor FeeFloor) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	pool.config.MinFeePerByte, pool.config.MinPayloadFeePerByte = floor.FeePerByte, floor.PayloadFeePerByte
+}
@@ -0,0 +1,64 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_FeeFloor_validate(t *testing.T) {
+	account, txs := newTestAccountTxs(t, []int64{1}, []uint64{1})
+	transfer := txs[0]
+	payloadTx := types.NewTransaction(account, *transfer.Data.To, big.NewInt(0), big.NewInt(1), 50000, 2)
+	payloadTx.Data.Payload = make([]byte, 100)
+
+	feePerByte := func(tx *types.Transaction) *big.Int {
+		return new(big.Int).Div(tx.MinFee(), big.NewInt(int64(tx.Size())))
+	}
+
+	// no floor
+	assert.Equal(t, FeeFloor{}.validate(transfer), error(nil))
+	assert.Equal(t, FeeFloor{FeePerByte: big.NewInt(0)}.validate(transfer), error(nil))
+
+	floor := FeeFloor{FeePerByte: feePerByte(transfer)}
+	assert.Equal(t, floor.validate(transfer), error(nil))
+
+	floor.FeePerByte = new(big.Int).Add(floor.FeePerByte, big.NewInt(1))
+	assert.Equal(t, floor.validate(transfer), errTxFeeTooLow)
+
+	// the inflated gas limit is not charged, so it does not pass the floor
+	inflated := types.NewTransaction(account, *transfer.Data.To, big.NewInt(1), big.NewInt(1), 1000*types.TransferGas, 1)
+	floor.FeePerByte = new(big.Int).Add(feePerByte(inflated), big.NewInt(1))
+	assert.Equal(t, inflated.MaxFee().Cmp(new(big.Int).Mul(floor.FeePerByte, big.NewInt(int64(inflated.Size())))) > 0, true)
+	assert.Equal(t, floor.validate(inflated), errTxFeeTooLow)
+
+	// the zero-amount tx with payload is of the separate floor
+	floor = FeeFloor{FeePerByte: big.NewInt(1), PayloadFeePerByte: new(big.Int).Add(feePerByte(payloadTx), big.NewInt(1))}
+	assert.Equal(t, floor.validate(transfer), error(nil))
+	assert.Equal(t, floor.validate(payloadTx), errTxFeeTooLow)
+
+	payloadTx.Data.Amount = big.NewInt(1)
+	assert.Equal(t, floor.validate(payloadTx), error(nil))
+}
+
+func Test_TransactionPool_SetFeeFloor(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1, 2}, []uint64{5, 6})
+	chain.addAccount(account, 10+2*types.TransferGas, 5)
+
+	pool.SetFeeFloor(FeeFloor{FeePerByte: big.NewInt(types.TransferGas)})
+	assert.Equal(t, pool.AddTransaction(txs[0]), errTxFeeTooLow)
+	assert.Equal(t, pool.FeeFloor().FeePerByte, big.NewInt(types.TransferGas))
+
+	pool.SetFeeFloor(FeeFloor{FeePerByte: big.NewInt(1)})
+	assert.Equal(t, pool.AddTransaction(txs[0]), error(nil))
+	assert.Equal(t, pool.AddTransaction(txs[1]), error(nil))
+}
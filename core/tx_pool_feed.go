@@ -0,0 +1,72 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// TxSubscription is the subscription of the txs accepted by the pool, see TransactionPool.SubscribeNewTxs.
+type TxSubscription struct {
+	feed    *txFeed
+	ch      chan<- *types.Transaction
+	dropped uint64
+}
+
+// Unsubscribe stops delivering the txs to the channel of the subscription, which is not closed.
+func (sub *TxSubscription) Unsubscribe() {
+	sub.feed.lock.Lock()
+	defer sub.feed.lock.Unlock()
+
+	delete(sub.feed.subs, sub)
+}
+
+// Dropped returns the number of the txs not delivered since the channel is full.
+func (sub *TxSubscription) Dropped() uint64 {
+	return atomic.LoadUint64(&sub.dropped)
+}
+
+// txFeed delivers the txs accepted by the pool to the subscribed channels.
+type txFeed struct {
+	lock sync.RWMutex
+	subs map[*TxSubscription]struct{}
+}
+
+func newTxFeed() *txFeed {
+	return &txFeed{subs: make(map[*TxSubscription]struct{})}
+}
+
+// send delivers the tx to the subscribed channels without blocking, in which the tx is dropped
+// for the subscriptions of which the channel is full, so that a slow subscriber could not stall
+// the pool.
+func (feed *txFeed) send(tx *types.Transaction) {
+	feed.lock.RLock()
+	defer feed.lock.RUnlock()
+
+	for sub := range feed.subs {
+		select {
+		case sub.ch <- tx:
+		default:
+			atomic.AddUint64(&sub.dropped, 1)
+		}
+	}
+}
+
+// SubscribeNewTxs subscribes the txs accepted by the pool, including the txs submitted locally,
+// received from peers, replacing the txs of the same nonce, and re-injected after the chain
+// reorganization. The channel should be buffered, since the txs are dropped if it is full.
+func (pool *TransactionPool) SubscribeNewTxs(ch chan<- *types.Transaction) *TxSubscription {
+	sub := &TxSubscription{feed: pool.feed, ch: ch}
+
+	pool.feed.lock.Lock()
+	defer pool.feed.lock.Unlock()
+
+	pool.feed.subs[sub] = struct{}{}
+	return sub
+}
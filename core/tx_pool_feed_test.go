@@ -0,0 +1,39 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_TransactionPool_SubscribeNewTxs(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{5, 6, 7})
+	chain.addAccount(account, 10+types.TransferGas, 5)
+
+	ch := make(chan *types.Transaction, 1)
+	sub := pool.SubscribeNewTxs(ch)
+
+	assert.Equal(t, pool.AddTransaction(txs[0]), error(nil))
+	assert.Equal(t, <-ch, txs[0])
+
+	// dropped since the channel is full
+	assert.Equal(t, pool.AddTransaction(txs[1]), error(nil))
+	assert.Equal(t, pool.AddTransaction(txs[2]), error(nil))
+	assert.Equal(t, <-ch, txs[1])
+	assert.Equal(t, sub.Dropped(), uint64(1))
+
+	// not delivered once unsubscribed, nor for the txs refused
+	sub.Unsubscribe()
+	assert.Equal(t, pool.AddTransaction(txs[2]) != nil, true)
+	pool.RemoveTransaction(txs[2].Hash)
+	assert.Equal(t, pool.AddTransaction(txs[2]), error(nil))
+	assert.Equal(t, len(ch), 0)
+}
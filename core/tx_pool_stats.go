@@ -0,0 +1,238 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/event"
+)
+
+const (
+	// maxInclusionSamples is the number of recent included txs to calculate the latency distribution.
+	maxInclusionSamples = 1024
+
+	// txEntryRetention is the duration to keep the entry time of txs that are never included.
+	txEntryRetention = 24 * time.Hour
+
+	// DefaultStuckThreshold is the default pending duration beyond which a tx is considered stuck.
+	DefaultStuckThreshold = 10 * time.Minute
+)
+
+// Reasons why a tx is stuck in the pool.
+const (
+	StuckReasonNonceTooLow = "nonce too low"  // the nonce is already used by another tx
+	StuckReasonNonceGap    = "nonce gap"      // txs of lower nonces are missing
+	StuckReasonLowGasPrice = "low gas price"  // the gas price is lower than the median of the pool
+	StuckReasonNotPacked   = "not packed yet" // no obvious reason, e.g. no block is mined recently
+)
+
+// InclusionStats is the latency distribution of the recent txs from entering
+// the pool to being included in a block.
+type InclusionStats struct {
+	Samples int           // Samples is the number of recent included txs
+	P50     time.Duration // P50 is the median latency
+	P90     time.Duration // P90 is the 90th percentile latency
+	P99     time.Duration // P99 is the 99th percentile latency
+	Max     time.Duration // Max is the maximum latency
+}
+
+// StuckTransaction is a tx pending in the pool beyond the threshold.
+type StuckTransaction struct {
+	Tx      *types.Transaction // Tx is the stuck tx
+	Pending time.Duration      // Pending is the duration since the tx entered the pool
+	Reason  string             // Reason is the most likely reason why the tx is stuck
+}
+
+// inclusionTracker tracks the entry time of txs and the latency once they are included.
+type inclusionTracker struct {
+	lock      sync.Mutex
+	entries   map[common.Hash]time.Time
+	latencies []time.Duration // ring buffer of the recent latencies
+	next      int             // next index of the ring buffer to write
+}
+
+func newInclusionTracker() *inclusionTracker {
+	return &inclusionTracker{
+		entries: make(map[common.Hash]time.Time),
+	}
+}
+
+// enter records the time when the tx enters the pool.
+func (tracker *inclusionTracker) enter(txHash common.Hash, now time.Time) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	if _, ok := tracker.entries[txHash]; !ok {
+		tracker.entries[txHash] = now
+	}
+}
+
+// include records the latency of the tx if it entered the pool before.
+func (tracker *inclusionTracker) include(txHash common.Hash, now time.Time) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	entered, ok := tracker.entries[txHash]
+	if !ok {
+		return
+	}
+
+	delete(tracker.entries, txHash)
+
+	latency := now.Sub(entered)
+	if len(tracker.latencies) < maxInclusionSamples {
+		tracker.latencies = append(tracker.latencies, latency)
+	} else {
+		tracker.latencies[tracker.next] = latency
+	}
+
+	tracker.next = (tracker.next + 1) % maxInclusionSamples
+}
+
+// prune removes the entries that are never included after the retention.
+func (tracker *inclusionTracker) prune(now time.Time) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	for hash, entered := range tracker.entries {
+		if now.Sub(entered) > txEntryRetention {
+			delete(tracker.entries, hash)
+		}
+	}
+}
+
+// entered returns the time when the tx entered the pool.
+func (tracker *inclusionTracker) entered(txHash common.Hash) (time.Time, bool) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	entered, ok := tracker.entries[txHash]
+	return entered, ok
+}
+
+func (tracker *inclusionTracker) stats() InclusionStats {
+	tracker.lock.Lock()
+	latencies := append([]time.Duration{}, tracker.latencies...)
+	tracker.lock.Unlock()
+
+	stats := InclusionStats{Samples: len(latencies)}
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+
+	stats.P50, stats.P90, stats.P99 = percentile(50), percentile(90), percentile(99)
+	stats.Max = latencies[len(latencies)-1]
+
+	return stats
+}
+
+// handleBlockInserted records the inclusion latency of the block txs, and removes them from the pool.
+func (pool *TransactionPool) handleBlockInserted(e event.Event) {
+	block := e.(*types.Block)
+	now := time.Now()
+
+	// the first tx is the miner reward
+	for i := 1; i < len(block.Transactions); i++ {
+		txHash := block.Transactions[i].Hash
+		pool.inclusion.include(txHash, now)
+		pool.RemoveTransaction(txHash)
+	}
+
+	// drop the txs that could not be included in the next block any more
+	pool.removeExpiredTransactions(block.Header.Height+1, uint64(now.Unix()))
+	pool.evictStaleTransactions(now)
+
+	// promote the queued txs of which the nonce gaps are filled by the block
+	pool.promoteTransactions()
+
+	// drop the included local txs from the journal
+	if pool.journal != nil {
+		interval := pool.config.JournalRotate
+		if interval == 0 {
+			interval = DefaultJournalRotate
+		}
+
+		pool.rotateJournal(now, interval)
+	}
+
+	pool.inclusion.prune(now)
+}
+
+// InclusionStats returns the latency distribution of the recent txs from entering the pool to being included.
+func (pool *TransactionPool) InclusionStats() InclusionStats {
+	return pool.inclusion.stats()
+}
+
+// StuckTransactions returns the txs pending in the pool beyond the specified threshold, with the reasons.
+func (pool *TransactionPool) StuckTransactions(threshold time.Duration) []*StuckTransaction {
+	statedb := pool.chain.CurrentState()
+	now := time.Now()
+
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	medianPrice := pool.medianGasPrice()
+
+	var stuckTxs []*StuckTransaction
+	for _, collections := range []map[common.Address]*txCollection{pool.pending, pool.queued} {
+		for account, collection := range collections {
+			stateNonce := statedb.GetNonce(account)
+
+			for _, tx := range collection.getTxs() {
+				var reason string
+				switch {
+				case tx.Data.AccountNonce < stateNonce:
+					reason = StuckReasonNonceTooLow
+				case collection != pool.pending[account]:
+					reason = StuckReasonNonceGap
+				case tx.Data.GasPrice.Cmp(medianPrice) < 0:
+					reason = StuckReasonLowGasPrice
+				default:
+					reason = StuckReasonNotPacked
+				}
+
+				entered, ok := pool.inclusion.entered(tx.Hash)
+				if !ok || now.Sub(entered) < threshold {
+					continue
+				}
+
+				stuckTxs = append(stuckTxs, &StuckTransaction{tx, now.Sub(entered), reason})
+			}
+		}
+	}
+
+	sort.Slice(stuckTxs, func(i, j int) bool { return stuckTxs[i].Pending > stuckTxs[j].Pending })
+
+	return stuckTxs
+}
+
+// medianGasPrice returns the median gas price of the txs in the pool, which should be called with the pool locked.
+func (pool *TransactionPool) medianGasPrice() *big.Int {
+	if len(pool.hashToTxMap) == 0 {
+		return new(big.Int)
+	}
+
+	prices := make([]*big.Int, 0, len(pool.hashToTxMap))
+	for _, tx := range pool.hashToTxMap {
+		prices = append(prices, tx.Data.GasPrice)
+	}
+
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+
+	return prices[len(prices)/2]
+}
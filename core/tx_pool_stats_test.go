@@ -0,0 +1,68 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_InclusionTracker_Stats(t *testing.T) {
+	tracker := newInclusionTracker()
+	now := time.Now()
+
+	for i := 0; i < 100; i++ {
+		hash := common.BigToHash(big.NewInt(int64(i)))
+		tracker.enter(hash, now)
+		tracker.include(hash, now.Add(time.Duration(i+1)*time.Second))
+	}
+
+	// not entered
+	tracker.include(common.StringToHash("unknown"), now)
+
+	stats := tracker.stats()
+	assert.Equal(t, stats.Samples, 100)
+	assert.Equal(t, stats.P50, 50*time.Second)
+	assert.Equal(t, stats.P90, 90*time.Second)
+	assert.Equal(t, stats.P99, 99*time.Second)
+	assert.Equal(t, stats.Max, 100*time.Second)
+	assert.Equal(t, len(tracker.entries), 0)
+}
+
+func Test_TransactionPool_StuckTransactions(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	defer pool.Stop()
+
+	// nonce 5 is missing
+	account, txs := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{3, 4, 6})
+	chain.addAccount(account, 10+types.TransferGas, 3)
+	for _, tx := range txs {
+		assert.Equal(t, pool.AddTransaction(tx), error(nil))
+	}
+
+	assert.Equal(t, len(pool.StuckTransactions(time.Hour)), 0)
+
+	stuckTxs := pool.StuckTransactions(0)
+	assert.Equal(t, len(stuckTxs), 3)
+
+	reasons := make(map[uint64]string)
+	for _, stuck := range stuckTxs {
+		reasons[stuck.Tx.Data.AccountNonce] = stuck.Reason
+	}
+
+	assert.Equal(t, reasons, map[uint64]string{3: StuckReasonNotPacked, 4: StuckReasonNotPacked, 6: StuckReasonNonceGap})
+
+	// included in a block
+	pool.handleBlockInserted(&types.Block{Header: &types.BlockHeader{}, Transactions: []*types.Transaction{nil, txs[0]}})
+	assert.Equal(t, pool.GetTransaction(txs[0].Hash), (*types.Transaction)(nil))
+	assert.Equal(t, pool.InclusionStats().Samples, 1)
+}
@@ -7,8 +7,10 @@ package core
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/magiconair/properties/assert"
 	"github.com/seeleteam/go-seele/common"
@@ -32,7 +34,7 @@ func newTestTx(t *testing.T, amount int64, nonce uint64) *types.Transaction {
 	fromPrivKey, fromAddress := randomAccount(t)
 	_, toAddress := randomAccount(t)
 
-	tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), nonce)
+	tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), big.NewInt(1), types.TransferGas, nonce)
 	tx.Sign(fromPrivKey)
 
 	return tx
@@ -40,6 +42,7 @@ func newTestTx(t *testing.T, amount int64, nonce uint64) *types.Transaction {
 
 type mockBlockchain struct {
 	statedb *state.Statedb
+	head    *types.Block
 }
 
 func newMockBlockchain() *mockBlockchain {
@@ -48,13 +51,25 @@ func newMockBlockchain() *mockBlockchain {
 		panic(err)
 	}
 
-	return &mockBlockchain{statedb}
+	return &mockBlockchain{statedb, &types.Block{Header: &types.BlockHeader{}}}
+}
+
+func (chain mockBlockchain) CurrentBlock() (*types.Block, *state.Statedb) {
+	return chain.head, chain.statedb
 }
 
 func (chain mockBlockchain) CurrentState() *state.Statedb {
 	return chain.statedb
 }
 
+func (chain mockBlockchain) ChainConfig() *types.ChainConfig {
+	return types.DefaultChainConfig()
+}
+
+func (chain mockBlockchain) ValidateTxSigHash(tx *types.Transaction) error {
+	return nil
+}
+
 func (chain mockBlockchain) addAccount(addr common.Address, balance, nonce uint64) {
 	stateObj := chain.statedb.GetOrNewStateObject(addr)
 	stateObj.SetAmount(new(big.Int).SetUint64(balance))
@@ -65,7 +80,7 @@ func Test_TransactionPool_Add_ValidTx(t *testing.T) {
 	chain := newMockBlockchain()
 	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
 	tx := newTestTx(t, 10, 100)
-	chain.addAccount(tx.Data.From, 20, 100)
+	chain.addAccount(tx.Data.From, 20+types.TransferGas, 100)
 
 	err := pool.AddTransaction(tx)
 
@@ -77,7 +92,7 @@ func Test_TransactionPool_Add_InvalidTx(t *testing.T) {
 	chain := newMockBlockchain()
 	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
 	tx := newTestTx(t, 10, 100)
-	chain.addAccount(tx.Data.From, 20, 100)
+	chain.addAccount(tx.Data.From, 20+types.TransferGas, 100)
 
 	// Change the amount in tx.
 	tx.Data.Amount.SetInt64(20)
@@ -88,11 +103,50 @@ func Test_TransactionPool_Add_InvalidTx(t *testing.T) {
 	}
 }
 
+func Test_TransactionPool_Add_RewardTx(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	_, miner := randomAccount(t)
+	tx := types.NewRewardTransaction(miner, big.NewInt(10), nil)
+
+	assert.Equal(t, errors.Is(pool.AddTransaction(tx), types.ErrRewardTxNotAllowed), true)
+	assert.Equal(t, len(pool.hashToTxMap), 0)
+}
+
+func Test_TransactionPool_Add_ExpiredTx(t *testing.T) {
+	chain := newMockBlockchain()
+	chain.head.Header.Height = 9
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	_, toAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 100+3*types.TransferGas, 0)
+
+	newTx := func(nonce, expireAt uint64) *types.Transaction {
+		tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(10), big.NewInt(1), types.TransferGas, nonce)
+		tx.Data.ExpireAt = expireAt
+		tx.Sign(fromPrivKey)
+		return tx
+	}
+
+	// expired for the next block at height 10
+	assert.Equal(t, pool.AddTransaction(newTx(0, 10)), types.ErrTxExpired)
+	assert.Equal(t, pool.AddTransaction(newTx(0, uint64(time.Now().Unix()-1))), types.ErrTxExpired)
+
+	assert.Equal(t, pool.AddTransaction(newTx(0, 11)), error(nil))
+	assert.Equal(t, pool.AddTransaction(newTx(1, 0)), error(nil))
+	assert.Equal(t, len(pool.hashToTxMap), 2)
+
+	// the tx expiring at height 11 is dropped once the block at height 10 inserted
+	pool.handleBlockInserted(&types.Block{Header: &types.BlockHeader{Height: 10}, Transactions: []*types.Transaction{nil}})
+	assert.Equal(t, len(pool.hashToTxMap), 1)
+}
+
 func Test_TransactionPool_Add_DuplicateTx(t *testing.T) {
 	chain := newMockBlockchain()
 	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
 	tx := newTestTx(t, 10, 100)
-	chain.addAccount(tx.Data.From, 20, 100)
+	chain.addAccount(tx.Data.From, 20+types.TransferGas, 100)
 
 	err := pool.AddTransaction(tx)
 	assert.Equal(t, err, error(nil))
@@ -108,9 +162,9 @@ func Test_TransactionPool_Add_PoolFull(t *testing.T) {
 	pool := NewTransactionPool(*config, chain)
 
 	tx1 := newTestTx(t, 10, 100)
-	chain.addAccount(tx1.Data.From, 20, 100)
+	chain.addAccount(tx1.Data.From, 20+types.TransferGas, 100)
 	tx2 := newTestTx(t, 20, 101)
-	chain.addAccount(tx2.Data.From, 20, 101)
+	chain.addAccount(tx2.Data.From, 20+types.TransferGas, 101)
 
 	err := pool.AddTransaction(tx1)
 	assert.Equal(t, err, error(nil))
@@ -119,11 +173,59 @@ func Test_TransactionPool_Add_PoolFull(t *testing.T) {
 	assert.Equal(t, err, errTxPoolFull)
 }
 
+func Test_TransactionPool_Add_GasPriceTooLow(t *testing.T) {
+	config := DefaultTxPoolConfig()
+	config.MinGasPrice = big.NewInt(2)
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*config, chain)
+
+	tx := newTestTx(t, 10, 100)
+	chain.addAccount(tx.Data.From, 20+2*types.TransferGas, 100)
+
+	err := pool.AddTransaction(tx)
+	assert.Equal(t, err, errTxGasPriceTooLow)
+}
+
+func Test_TransactionPool_Add_ReplaceTx(t *testing.T) {
+	config := DefaultTxPoolConfig()
+	config.Capacity = 1
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*config, chain)
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	_, toAddress := randomAccount(t)
+	chain.addAccount(fromAddress, 100+20*types.TransferGas, 0)
+
+	newTx := func(gasPrice int64) *types.Transaction {
+		tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(10), big.NewInt(gasPrice), types.TransferGas, 0)
+		tx.Sign(fromPrivKey)
+		return tx
+	}
+
+	tx := newTx(10)
+	assert.Equal(t, pool.AddLocalTransaction(tx), error(nil))
+
+	assert.Equal(t, pool.AddTransaction(tx), errTxHashExists)
+
+	// the gas price is not bumped by 10%
+	assert.Equal(t, pool.AddTransaction(newTx(10)), errTxUnderpriced)
+	assert.Equal(t, pool.AddTransaction(newTx(9)), errTxUnderpriced)
+
+	// the pending tx is evicted by the replacement, though the pool is full
+	replacement := newTx(11)
+	assert.Equal(t, pool.AddTransaction(replacement), error(nil))
+	assert.Equal(t, len(pool.hashToTxMap), 1)
+	assert.Equal(t, pool.GetTransaction(tx.Hash) == nil, true)
+	assert.Equal(t, pool.GetTransaction(replacement.Hash), replacement)
+	assert.Equal(t, pool.locals[tx.Hash], false)
+	assert.Equal(t, pool.GetProcessableTransactions()[fromAddress], []*types.Transaction{replacement})
+}
+
 func Test_TransactionPool_GetTransaction(t *testing.T) {
 	chain := newMockBlockchain()
 	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
 	tx := newTestTx(t, 10, 100)
-	chain.addAccount(tx.Data.From, 20, 100)
+	chain.addAccount(tx.Data.From, 20+types.TransferGas, 100)
 
 	pool.AddTransaction(tx)
 
@@ -141,7 +243,7 @@ func newTestAccountTxs(t *testing.T, amounts []int64, nonces []uint64) (common.A
 	for i, amount := range amounts {
 		_, toAddress := randomAccount(t)
 
-		tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), nonces[i])
+		tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), big.NewInt(1), types.TransferGas, nonces[i])
 		tx.Sign(fromPrivKey)
 
 		txs = append(txs, tx)
@@ -153,10 +255,10 @@ func newTestAccountTxs(t *testing.T, amounts []int64, nonces []uint64) (common.A
 func Test_TransactionPool_GetProcessableTransactions(t *testing.T) {
 	chain := newMockBlockchain()
 	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
-	account1, txs1 := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{9, 5, 7})
-	chain.addAccount(account1, 10, 5)
-	account2, txs2 := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{7, 9, 5})
-	chain.addAccount(account2, 10, 5)
+	account1, txs1 := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{7, 5, 6})
+	chain.addAccount(account1, 10+types.TransferGas, 5)
+	account2, txs2 := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{6, 7, 5})
+	chain.addAccount(account2, 10+types.TransferGas, 5)
 
 	for _, tx := range append(txs1, txs2...) {
 		pool.AddTransaction(tx)
@@ -176,20 +278,115 @@ func Test_TransactionPool_GetProcessableTransactions(t *testing.T) {
 	assert.Equal(t, processableTxs[account2][2], txs2[1])
 }
 
+func Test_TransactionPool_PendingAndQueued(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1, 2, 3, 4}, []uint64{5, 7, 8, 6})
+	chain.addAccount(account, 10+types.TransferGas, 5)
+
+	// nonce 6 is missing
+	for _, tx := range txs[:3] {
+		assert.Equal(t, pool.AddTransaction(tx), error(nil))
+	}
+
+	assert.Equal(t, pool.GetProcessableTransactions()[account], []*types.Transaction{txs[0]})
+	assert.Equal(t, pool.GetQueuedTransactions()[account], []*types.Transaction{txs[1], txs[2]})
+	assert.Equal(t, pool.GetQueuedTransactionsCount(), 2)
+
+	// promoted once the gap is filled
+	assert.Equal(t, pool.AddTransaction(txs[3]), error(nil))
+	assert.Equal(t, pool.GetProcessableTransactions()[account], []*types.Transaction{txs[0], txs[3], txs[1], txs[2]})
+	assert.Equal(t, len(pool.queued), 0)
+
+	// demoted once the tx of a lower nonce is removed
+	pool.RemoveTransaction(txs[3].Hash)
+	assert.Equal(t, pool.GetProcessableTransactions()[account], []*types.Transaction{txs[0]})
+	assert.Equal(t, pool.GetQueuedTransactions()[account], []*types.Transaction{txs[1], txs[2]})
+
+	// the nonces 5 and 6 are used by the txs included out of the pool
+	chain.addAccount(account, 10+types.TransferGas, 7)
+	pool.handleBlockInserted(&types.Block{Header: &types.BlockHeader{Height: 1}})
+	assert.Equal(t, pool.GetTransaction(txs[0].Hash) == nil, true)
+	assert.Equal(t, pool.GetProcessableTransactions()[account], []*types.Transaction{txs[1], txs[2]})
+	assert.Equal(t, pool.GetQueuedTransactionsCount(), 0)
+	assert.Equal(t, len(pool.hashToTxMap), 2)
+}
+
+func Test_TransactionPool_TransactionStatus(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{5, 7, 6})
+	chain.addAccount(account, 10+types.TransferGas, 5)
+
+	assert.Equal(t, pool.AddTransaction(txs[0]), error(nil))
+	assert.Equal(t, pool.AddTransaction(txs[1]), error(nil))
+	assert.Equal(t, pool.TransactionStatus(txs[0].Hash), TxStatusPending)
+	assert.Equal(t, pool.TransactionStatus(txs[1].Hash), TxStatusQueued)
+	assert.Equal(t, pool.TransactionStatus(txs[2].Hash), TxStatusUnknown)
+
+	// promoted once the gap is filled
+	assert.Equal(t, pool.AddTransaction(txs[2]), error(nil))
+	assert.Equal(t, pool.TransactionStatus(txs[1].Hash), TxStatusPending)
+	assert.Equal(t, pool.TransactionStatus(txs[2].Hash), TxStatusPending)
+}
+
+func Test_TransactionPool_PendingNonce(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1, 2}, []uint64{5, 7})
+	chain.addAccount(account, 10+types.TransferGas, 5)
+	assert.Equal(t, pool.PendingNonce(account), uint64(5))
+
+	// the queued tx after the gap is not counted
+	assert.Equal(t, pool.AddTransaction(txs[0]), error(nil))
+	assert.Equal(t, pool.AddTransaction(txs[1]), error(nil))
+	assert.Equal(t, pool.PendingNonce(account), uint64(6))
+}
+
+func Test_TransactionPool_handleChainReorg(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1, 2, 3}, []uint64{5, 6, 7})
+
+	// the tx of nonce 5 is included in the new canonical chain too
+	chain.addAccount(account, 10+types.TransferGas, 6)
+	pool.handleChainReorg(&ChainReorg{DroppedTxs: txs})
+
+	assert.Equal(t, pool.GetTransaction(txs[0].Hash) == nil, true)
+	assert.Equal(t, pool.GetProcessableTransactions()[account], []*types.Transaction{txs[1], txs[2]})
+	assert.Equal(t, pool.locals[txs[1].Hash], false)
+}
+
+func Test_TransactionPool_handleChainReorg_Local(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	account, txs := newTestAccountTxs(t, []int64{1}, []uint64{5})
+	chain.addAccount(account, 10+types.TransferGas, 5)
+	assert.Equal(t, pool.AddLocalTransaction(txs[0]), error(nil))
+
+	// included in a block, which is reorganized out then
+	pool.RemoveTransaction(txs[0].Hash)
+	assert.Equal(t, pool.locals[txs[0].Hash], false)
+	pool.handleChainReorg(&ChainReorg{DroppedTxs: txs})
+
+	assert.Equal(t, pool.GetTransaction(txs[0].Hash), txs[0])
+	assert.Equal(t, pool.locals[txs[0].Hash], true)
+}
+
 func Test_TransactionPool_Remove(t *testing.T) {
 	config := DefaultTxPoolConfig()
 	chain := newMockBlockchain()
 	pool := NewTransactionPool(*config, chain)
 
 	tx := newTestTx(t, 10, 100)
-	chain.addAccount(tx.Data.From, 20, 100)
+	chain.addAccount(tx.Data.From, 20+types.TransferGas, 100)
 
 	err := pool.AddTransaction(tx)
 	assert.Equal(t, err, nil)
 	assert.Equal(t, len(pool.hashToTxMap), 1)
-	assert.Equal(t, len(pool.accountToTxsMap), 1)
+	assert.Equal(t, len(pool.pending), 1)
 
 	pool.RemoveTransaction(tx.Hash)
 	assert.Equal(t, len(pool.hashToTxMap), 0)
-	assert.Equal(t, len(pool.accountToTxsMap), 0)
+	assert.Equal(t, len(pool.pending), 0)
 }
@@ -0,0 +1,112 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+const (
+	// DefaultSeenTxCacheSize is the default maximum number of the seen txs whose verdicts are cached,
+	// each of which takes about 100 bytes.
+	DefaultSeenTxCacheSize = 16384
+
+	// DefaultSeenTxTTL is the default duration to keep the verdict of a seen tx.
+	DefaultSeenTxTTL = 10 * time.Minute
+)
+
+// SeenTxStats is the duplicate suppression statistics of the seen tx cache.
+type SeenTxStats struct {
+	Entries         int     // Entries is the number of the seen txs cached
+	Lookups         uint64  // Lookups is the number of the txs checked against the cache
+	Duplicates      uint64  // Duplicates is the number of the txs whose validation is skipped for the cached verdict
+	SuppressionRate float64 // SuppressionRate is the ratio of the duplicates to the lookups
+}
+
+// seenVerdict is the cached result of the stateless validation of a tx.
+type seenVerdict struct {
+	err    error
+	expiry time.Time
+}
+
+// seenTxCache caches the verdicts of the stateless validation, i.e. the fields and signature,
+// of the recently received txs, so that the same tx received from many peers or submitted
+// again via RPC is verified only once. The txs are keyed by the hash of the whole encoded tx,
+// since the tx hash and signature are not bound to the data until verified.
+type seenTxCache struct {
+	cache      *lru.Cache
+	ttl        time.Duration
+	lookups    uint64
+	duplicates uint64
+}
+
+// newSeenTxCache creates a seen tx cache of the specified size and ttl, the defaults are used if 0.
+func newSeenTxCache(size int, ttl time.Duration) *seenTxCache {
+	if size <= 0 {
+		size = DefaultSeenTxCacheSize
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultSeenTxTTL
+	}
+
+	cache, err := lru.New(size)
+	if err != nil {
+		panic(err) // only returns error if the size is not positive
+	}
+
+	return &seenTxCache{cache: cache, ttl: ttl}
+}
+
+// validate returns the cached verdict of the tx if seen before, otherwise validates the tx
+// with the specified func and caches the verdict.
+func (c *seenTxCache) validate(tx *types.Transaction, validate func() error, now time.Time) error {
+	atomic.AddUint64(&c.lookups, 1)
+
+	encoded, err := common.Serialize(tx)
+	if err != nil {
+		return validate()
+	}
+
+	key := crypto.HashBytes(encoded)
+	if value, ok := c.cache.Get(key); ok {
+		if verdict := value.(*seenVerdict); now.Before(verdict.expiry) {
+			atomic.AddUint64(&c.duplicates, 1)
+			return verdict.err
+		}
+	}
+
+	err = validate()
+	c.cache.Add(key, &seenVerdict{err, now.Add(c.ttl)})
+
+	return err
+}
+
+// stats returns the duplicate suppression statistics of the cache.
+func (c *seenTxCache) stats() SeenTxStats {
+	stats := SeenTxStats{
+		Entries:    c.cache.Len(),
+		Lookups:    atomic.LoadUint64(&c.lookups),
+		Duplicates: atomic.LoadUint64(&c.duplicates),
+	}
+
+	if stats.Lookups > 0 {
+		stats.SuppressionRate = float64(stats.Duplicates) / float64(stats.Lookups)
+	}
+
+	return stats
+}
+
+// SeenTxStats returns the duplicate suppression statistics of the txs received from peers and RPC.
+func (pool *TransactionPool) SeenTxStats() SeenTxStats {
+	return pool.seen.stats()
+}
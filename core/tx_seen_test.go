@@ -0,0 +1,68 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_SeenTxCache_Validate(t *testing.T) {
+	cache := newSeenTxCache(2, time.Minute)
+	tx := newTestTx(t, 10, 0)
+	validated := 0
+	errInvalid := errors.New("invalid")
+	validate := func() error {
+		validated++
+		return errInvalid
+	}
+
+	now := time.Now()
+	assert.Equal(t, cache.validate(tx, validate, now), errInvalid)
+	assert.Equal(t, cache.validate(tx, validate, now), errInvalid)
+	assert.Equal(t, validated, 1)
+
+	// the verdict expires
+	assert.Equal(t, cache.validate(tx, validate, now.Add(time.Minute)), errInvalid)
+	assert.Equal(t, validated, 2)
+
+	// the least recently seen tx is evicted
+	cache.validate(newTestTx(t, 10, 0), validate, now)
+	cache.validate(newTestTx(t, 10, 0), validate, now)
+	cache.validate(tx, validate, now)
+	assert.Equal(t, validated, 5)
+
+	assert.Equal(t, cache.stats(), SeenTxStats{
+		Entries:         2,
+		Lookups:         6,
+		Duplicates:      1,
+		SuppressionRate: float64(1) / 6,
+	})
+}
+
+func Test_TransactionPool_Add_SeenTx(t *testing.T) {
+	chain := newMockBlockchain()
+	pool := NewTransactionPool(*DefaultTxPoolConfig(), chain)
+	tx := newTestTx(t, 10, 100)
+	chain.addAccount(tx.Data.From, 20+types.TransferGas, 100)
+
+	assert.Equal(t, pool.AddTransaction(tx), error(nil))
+	assert.Equal(t, pool.AddTransaction(tx), errTxHashExists)
+	assert.Equal(t, pool.SeenTxStats().Duplicates, uint64(1))
+
+	// the forged tx with the same hash and signature is validated again
+	data := *tx.Data
+	data.Amount = big.NewInt(20)
+	forged := &types.Transaction{Hash: tx.Hash, Data: &data, Signature: tx.Signature}
+	err := pool.AddTransaction(forged)
+	assert.Equal(t, errors.Is(err, types.ErrHashMismatch), true)
+	assert.Equal(t, pool.SeenTxStats().Duplicates, uint64(1))
+}
@@ -0,0 +1,98 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BatchValidate validates the txs against the chain config, in which the signatures are
+// verified concurrently across a worker pool, and it stops once any tx is invalid. The
+// error of the first invalid tx in order is returned, which is a *TxError.
+//
+// Each tx is validated against the same statedb. If statedb is nil, the state is not
+// validated, e.g. for the block txs that should be validated against the state updated
+// by the previous txs, in which case ValidateState should be called before applying each tx.
+func BatchValidate(txs []*Transaction, statedb stateDB, config *ChainConfig) error {
+	for _, tx := range txs {
+		if err := config.validateTxPayload(tx); err != nil {
+			return newTxError(tx.Hash, err)
+		}
+	}
+
+	if err := batchValidateWithoutState(txs, runtime.NumCPU()); err != nil {
+		return err
+	}
+
+	if statedb == nil {
+		return nil
+	}
+
+	for _, tx := range txs {
+		if err := tx.ValidateState(statedb); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func batchValidateWithoutState(txs []*Transaction, workers int) error {
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+
+	if workers <= 1 {
+		for _, tx := range txs {
+			if err := tx.validateWithoutState(); err != nil {
+				return newTxError(tx.Hash, err)
+			}
+		}
+
+		return nil
+	}
+
+	var (
+		next     int64 = -1 // index of the last dispatched tx, accessed atomically
+		failed   int32      // flag to stop dispatching, accessed atomically
+		lock     sync.Mutex
+		firstErr error
+		firstIdx = len(txs)
+		wg       sync.WaitGroup
+	)
+
+	// txs are dispatched in order, so all txs before the failed one are
+	// always validated, and the first invalid tx in order is reported.
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for atomic.LoadInt32(&failed) == 0 {
+				idx := int(atomic.AddInt64(&next, 1))
+				if idx >= len(txs) {
+					return
+				}
+
+				if err := txs[idx].validateWithoutState(); err != nil {
+					atomic.StoreInt32(&failed, 1)
+
+					lock.Lock()
+					if idx < firstIdx {
+						firstIdx, firstErr = idx, newTxError(txs[idx].Hash, err)
+					}
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
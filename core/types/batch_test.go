@@ -0,0 +1,45 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func newTestBatchTxs(t *testing.T, n int) []*Transaction {
+	txs := make([]*Transaction, n)
+	for i := range txs {
+		txs[i] = newTestTx(t, 100, 38, true)
+	}
+
+	return txs
+}
+
+func Test_BatchValidate(t *testing.T) {
+	txs := newTestBatchTxs(t, 20)
+	assert.Equal(t, BatchValidate(txs, nil, DefaultChainConfig()), error(nil))
+
+	// validate state against the same statedb
+	statedb := newTestStateDB(txs[0].Data.From, 38, 200+TransferGas)
+	assert.Equal(t, BatchValidate(txs[:1], statedb, DefaultChainConfig()), error(nil))
+	assert.Equal(t, errors.Is(BatchValidate(txs[:2], statedb, DefaultChainConfig()), ErrBalanceNotEnough), true)
+}
+
+func Test_BatchValidate_FirstInvalid(t *testing.T) {
+	txs := newTestBatchTxs(t, 20)
+	txs[5].Signature = nil
+	txs[15].Data.Amount.SetInt64(1)
+
+	for workers := 1; workers <= 8; workers++ {
+		assert.Equal(t, errors.Is(batchValidateWithoutState(txs, workers), ErrSigMissing), true)
+	}
+
+	txs[5] = newTestTx(t, 100, 38, true)
+	assert.Equal(t, errors.Is(BatchValidate(txs, nil, DefaultChainConfig()), ErrHashMismatch), true)
+}
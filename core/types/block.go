@@ -6,22 +6,51 @@
 package types
 
 import (
+	"errors"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/seeleteam/go-seele/common"
 	"github.com/seeleteam/go-seele/crypto"
 )
 
+const (
+	// MaxOmmers is the maximum number of ommers referenced by a block.
+	MaxOmmers = 2
+
+	// MaxOmmerDepth is the maximum height distance from a block to the ommers it references.
+	MaxOmmerDepth = 6
+
+	// BlockHeaderVersion is the latest version of the block header format, which should be
+	// increased once a hard fork appends fields to the header. The headers mined before the
+	// versioning are of version 0.
+	BlockHeaderVersion uint64 = 1
+)
+
+var (
+	// ErrOmmersTooMany is returned when a block references more than MaxOmmers ommers.
+	ErrOmmersTooMany = errors.New("too many ommers")
+
+	// ErrOmmerInvalid is returned when an ommer is not a stale sibling of an ancestor within
+	// MaxOmmerDepth, or is referenced twice, or references ommers itself.
+	ErrOmmerInvalid = errors.New("invalid ommer")
+)
+
 // BlockHeader represents the header of a block in the blockchain.
 type BlockHeader struct {
 	PreviousBlockHash common.Hash // PreviousBlockHash represents the hash of the parent block 
 	Creator           common.Address // Creator is the coinbase of the miner which mined the block
 	StateHash         common.Hash // StateHash is the root hash of the state trie
 	TxHash            common.Hash // TxHash is the root hash of the transaction trie
+	ReceiptHash       common.Hash // ReceiptHash is the root hash of the receipt trie
+	LogsBloom         Bloom // LogsBloom is the bloom of the contract addresses and topics of the logs in receipts
 	Difficulty        *big.Int // Difficulty is the difficulty of the block
 	Height            uint64 // Height is the number of the block
 	CreateTimestamp   *big.Int // CreateTimestamp is the timestamp when the block is created
 	Nonce             uint64 // Nonce is the pow of the block
+	Ommers            []*BlockHeader // Ommers are the headers of the stale blocks referenced for partial rewards
+	Version           uint64 // Version is the format version of the header
+	Extensions        []rlp.RawValue `rlp:"tail"` // Extensions are the encoded fields appended by the later versions, which are kept for the hash
 }
 
 // Clone returns a clone of the block header.
@@ -36,6 +65,20 @@ func (header *BlockHeader) Clone() *BlockHeader {
 		clone.CreateTimestamp.Set(header.CreateTimestamp)
 	}
 
+	if len(header.Ommers) > 0 {
+		clone.Ommers = make([]*BlockHeader, len(header.Ommers))
+		for i, ommer := range header.Ommers {
+			clone.Ommers[i] = ommer.Clone()
+		}
+	}
+
+	if len(header.Extensions) > 0 {
+		clone.Extensions = make([]rlp.RawValue, len(header.Extensions))
+		for i, ext := range header.Extensions {
+			clone.Extensions[i] = append(rlp.RawValue{}, ext...)
+		}
+	}
+
 	return &clone
 }
 
@@ -73,6 +116,12 @@ func NewBlock(header *BlockHeader, txs []*Transaction) *Block {
 	return block
 }
 
+// Size returns the size of the block in the RLP encoding, which is limited by the
+// MaxBlockSize of the chain config.
+func (block *Block) Size() int {
+	return len(common.SerializePanic(block))
+}
+
 // FindTransaction returns the transaction of the specified hash if found. Otherwise, it returns nil.
 func (block *Block) FindTransaction(txHash common.Hash) *Transaction {
 	for _, tx := range block.Transactions {
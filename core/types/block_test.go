@@ -65,6 +65,19 @@ func Test_BlockHeader_Hash(t *testing.T) {
 	assert.Equal(t, hash1.Equal(hash2), false)
 }
 
+func Test_Block_Size(t *testing.T) {
+	tx := newTestTx(t, 1, 1, true)
+	block := NewBlock(newTestBlockHeader(t), []*Transaction{tx})
+	empty := NewBlock(newTestBlockHeader(t), nil)
+
+	assert.Equal(t, tx.Size(), len(common.SerializePanic(tx)))
+	assert.Equal(t, block.Size() > empty.Size()+tx.Size()-1, true)
+
+	// the block size limit must hold a tx of the max payload size
+	assert.Equal(t, DefaultChainConfig().Validate(), error(nil))
+	assert.Equal(t, (&ChainConfig{MaxPayloadSize: 10, MaxBlockSize: 10}).Validate(), ErrChainConfigInvalid)
+}
+
 func Test_Block_FindTransaction(t *testing.T) {
 	header := newTestBlockHeader(t)
 	txs := []*Transaction{
@@ -0,0 +1,89 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"fmt"
+
+	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// BloomByteLength is the number of bytes of the logs bloom in block header.
+const BloomByteLength = 256
+
+// Bloom is a 2048-bit bloom filter of the contract addresses and topics of the
+// logs in a block, so that the blocks without relevant logs could be skipped quickly.
+type Bloom [BloomByteLength]byte
+
+// CreateBloom creates the bloom of the logs in the specified receipts.
+func CreateBloom(receipts []*Receipt) Bloom {
+	var bloom Bloom
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			bloom.Add(log.Address.Bytes())
+			for _, topic := range log.Topics {
+				bloom.Add(topic.Bytes())
+			}
+		}
+	}
+
+	return bloom
+}
+
+// bloomBits returns the indices of the 3 bits set for the data, each of which is
+// the low 11 bits of a pair of bytes in the hash of the data.
+func bloomBits(data []byte) [3]uint {
+	hash := crypto.HashBytes(data).Bytes()
+
+	var bits [3]uint
+	for i := range bits {
+		bits[i] = (uint(hash[2*i])<<8 | uint(hash[2*i+1])) & (BloomByteLength*8 - 1)
+	}
+
+	return bits
+}
+
+// Add adds the data, e.g. a contract address or topic, to the bloom.
+func (b *Bloom) Add(data []byte) {
+	for _, bit := range bloomBits(data) {
+		b[BloomByteLength-1-bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Test indicates whether the data may be in the bloom. False positives are possible,
+// but false negatives are not.
+func (b *Bloom) Test(data []byte) bool {
+	for _, bit := range bloomBits(data) {
+		if b[BloomByteLength-1-bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalText implements the encoding.TextMarshaler interface,
+// so that the bloom is encoded in 0x-prefixed hex in JSON.
+func (b Bloom) MarshalText() ([]byte, error) {
+	return []byte(hexutil.BytesToHex(b[:])), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (b *Bloom) UnmarshalText(input []byte) error {
+	decoded, err := hexutil.HexToBytes(string(input))
+	if err != nil {
+		return err
+	}
+
+	if len(decoded) != BloomByteLength {
+		return fmt.Errorf("wrong bloom length, want %d bytes", BloomByteLength)
+	}
+
+	copy(b[:], decoded)
+	return nil
+}
+
@@ -0,0 +1,41 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+)
+
+func Test_Bloom(t *testing.T) {
+	contract := randomAddress(t)
+	topic := common.StringToHash("topic")
+	receipts := []*Receipt{{Logs: []*Log{{Address: contract, Topics: []common.Hash{topic}}}}}
+
+	bloom := CreateBloom(receipts)
+	assert.Equal(t, bloom.Test(contract.Bytes()), true)
+	assert.Equal(t, bloom.Test(topic.Bytes()), true)
+	assert.Equal(t, bloom.Test(common.StringToHash("other").Bytes()), false)
+
+	assert.Equal(t, CreateBloom(nil), Bloom{})
+}
+
+func Test_Bloom_JSON(t *testing.T) {
+	var bloom Bloom
+	bloom.Add([]byte("data"))
+
+	encoded, err := json.Marshal(bloom)
+	assert.Equal(t, err, error(nil))
+
+	var decoded Bloom
+	assert.Equal(t, json.Unmarshal(encoded, &decoded), error(nil))
+	assert.Equal(t, decoded, bloom)
+
+	assert.Equal(t, json.Unmarshal([]byte(`"0x01"`), &decoded) != nil, true)
+}
@@ -0,0 +1,67 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+)
+
+const (
+	// DefaultMaxPayloadSize is the max tx payload size of the public networks.
+	DefaultMaxPayloadSize = 32 * 1024
+
+	// DefaultMaxBlockSize is the max encoded block size of the public networks.
+	DefaultMaxBlockSize = 1024 * 1024
+)
+
+// ErrChainConfigInvalid is returned when the chain config is invalid.
+var ErrChainConfigInvalid = errors.New("invalid chain config")
+
+// ChainConfig is the consensus config of the chain specified in genesis, which is
+// shared by the tx creation, tx pool and block validation.
+type ChainConfig struct {
+	// MaxPayloadSize limits the tx payload size to prevent malicious transactions
+	MaxPayloadSize int
+
+	// MaxBlockSize limits the encoded size of a block, which should hold a tx of the max payload size
+	MaxBlockSize int
+}
+
+// DefaultChainConfig returns the chain config of the public networks.
+func DefaultChainConfig() *ChainConfig {
+	return &ChainConfig{
+		MaxPayloadSize: DefaultMaxPayloadSize,
+		MaxBlockSize:   DefaultMaxBlockSize,
+	}
+}
+
+// Validate validates the chain config.
+func (config *ChainConfig) Validate() error {
+	if config.MaxPayloadSize <= 0 || config.MaxBlockSize <= config.MaxPayloadSize {
+		return ErrChainConfigInvalid
+	}
+
+	return nil
+}
+
+// validateTxPayload validates the tx payload size against the chain config, which is
+// generally checked before verifying the signature since it is cheap.
+func (config *ChainConfig) validateTxPayload(tx *Transaction) error {
+	if tx.Data == nil {
+		return nil
+	}
+
+	return config.validatePayload(tx.Data.Payload)
+}
+
+// validatePayload validates the tx payload size against the chain config.
+func (config *ChainConfig) validatePayload(payload []byte) error {
+	if len(payload) > config.MaxPayloadSize {
+		return ErrPayloadOversized
+	}
+
+	return nil
+}
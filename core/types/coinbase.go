@@ -0,0 +1,54 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+)
+
+const (
+	// CoinbaseExtraKey is the key of the tx extension in the reward tx which holds the extra data
+	// of the block creator, e.g. the pool tag or the miner version.
+	CoinbaseExtraKey = "coinbase.extra"
+
+	// MaxCoinbaseExtraSize limits the size of the coinbase extra data.
+	MaxCoinbaseExtraSize = 32
+)
+
+// ErrCoinbaseExtraOversized is returned when the coinbase extra data exceeds MaxCoinbaseExtraSize.
+var ErrCoinbaseExtraOversized = errors.New("coinbase extra data oversized")
+
+// CoinbaseExtra returns the coinbase extra data of the reward tx, or nil if not set.
+func (tx *Transaction) CoinbaseExtra() []byte {
+	extra, _ := tx.Data.GetExtension(CoinbaseExtraKey)
+	return extra
+}
+
+// SetCoinbaseExtra sets the coinbase extra data of the reward tx and updates the tx hash,
+// which should be called before the tx is packed into a block. The extra data is removed
+// if empty.
+func (tx *Transaction) SetCoinbaseExtra(extra []byte) error {
+	if len(extra) > MaxCoinbaseExtraSize {
+		return ErrCoinbaseExtraOversized
+	}
+
+	if tx.hashCache.Load() != nil {
+		tx.hashCache.Store(txHashCache{})
+	}
+
+	tx.Data.Extensions = nil
+	if len(extra) > 0 {
+		tx.Data.Extensions = []TxExtension{{CoinbaseExtraKey, append([]byte{}, extra...)}}
+	}
+
+	hash, err := tx.SigVersion.sigHash(tx.Data)
+	if err != nil {
+		return err
+	}
+
+	tx.Hash = hash
+	return nil
+}
@@ -0,0 +1,145 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+)
+
+// EncodingVersion is the version of the binary encoding of transactions and blocks,
+// which should be increased once the encoded fields are changed.
+const EncodingVersion byte = 14
+
+var (
+	// ErrEncodingEmpty is returned when decoding from empty bytes.
+	ErrEncodingEmpty = errors.New("encoded bytes are empty")
+
+	// ErrEncodingVersion is returned when the encoding version is not supported.
+	ErrEncodingVersion = errors.New("unsupported encoding version")
+)
+
+// encode encodes the value in RLP prefixed with the encoding version. The RLP
+// encoding is deterministic, and it is also the content hashed via crypto.MustHash.
+func encode(value interface{}) ([]byte, error) {
+	encoded, err := common.Serialize(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{EncodingVersion}, encoded...), nil
+}
+
+// decode checks the encoding version and decodes the RLP bytes into the value.
+func decode(data []byte, value interface{}) error {
+	if len(data) == 0 {
+		return ErrEncodingEmpty
+	}
+
+	if data[0] != EncodingVersion {
+		return ErrEncodingVersion
+	}
+
+	return common.Deserialize(data[1:], value)
+}
+
+// Encode returns the versioned binary encoding of the transaction.
+func (tx *Transaction) Encode() ([]byte, error) {
+	return encode(tx)
+}
+
+// Decode decodes the transaction from the versioned binary encoding.
+func (tx *Transaction) Decode(data []byte) error {
+	return decode(data, tx)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	return tx.Encode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (tx *Transaction) UnmarshalBinary(data []byte) error {
+	return tx.Decode(data)
+}
+
+// DecodeRLP implements the rlp.Decoder interface, in which the empty extensions are
+// decoded as nil, the same as the tx data before encoded.
+func (data *TransactionData) DecodeRLP(s *rlp.Stream) error {
+	type plainData TransactionData
+	if err := s.Decode((*plainData)(data)); err != nil {
+		return err
+	}
+
+	if len(data.Extensions) == 0 {
+		data.Extensions = nil
+	}
+
+	return nil
+}
+
+// Encode returns the versioned binary encoding of the block header.
+func (header *BlockHeader) Encode() ([]byte, error) {
+	return encode(header)
+}
+
+// Decode decodes the block header from the versioned binary encoding.
+func (header *BlockHeader) Decode(data []byte) error {
+	return decode(data, header)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (header *BlockHeader) MarshalBinary() ([]byte, error) {
+	return header.Encode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (header *BlockHeader) UnmarshalBinary(data []byte) error {
+	return header.Decode(data)
+}
+
+// DecodeRLP implements the rlp.Decoder interface, in which the empty ommers and extensions
+// are decoded as nil, the same as the header before encoded. The fields appended by the later
+// header versions are kept in the extensions, so that the header of a newer version could be
+// decoded and re-encoded with the same hash.
+func (header *BlockHeader) DecodeRLP(s *rlp.Stream) error {
+	type plainHeader BlockHeader
+	if err := s.Decode((*plainHeader)(header)); err != nil {
+		return err
+	}
+
+	if len(header.Ommers) == 0 {
+		header.Ommers = nil
+	}
+
+	if len(header.Extensions) == 0 {
+		header.Extensions = nil
+	}
+
+	return nil
+}
+
+// Encode returns the versioned binary encoding of the block.
+func (block *Block) Encode() ([]byte, error) {
+	return encode(block)
+}
+
+// Decode decodes the block from the versioned binary encoding.
+func (block *Block) Decode(data []byte) error {
+	return decode(data, block)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (block *Block) MarshalBinary() ([]byte, error) {
+	return block.Encode()
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (block *Block) UnmarshalBinary(data []byte) error {
+	return block.Decode(data)
+}
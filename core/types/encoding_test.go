@@ -0,0 +1,96 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_Transaction_Encoding(t *testing.T) {
+	tx := newTestTx(t, 100, 38, true)
+
+	encoded, err := tx.MarshalBinary()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, encoded[0], EncodingVersion)
+
+	decoded := new(Transaction)
+	assert.Equal(t, decoded.UnmarshalBinary(encoded), error(nil))
+	assert.Equal(t, decoded, tx)
+	assert.Equal(t, decoded.CalculateHash(), tx.CalculateHash())
+}
+
+func Test_Transaction_Encoding_ContractCreate(t *testing.T) {
+	from, privKey, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+
+	tx, err := NewContractTransaction(DefaultChainConfig(), *from, big.NewInt(0), big.NewInt(1), TransferGas, 1, []byte("code"))
+	assert.Equal(t, err, error(nil))
+	tx.Sign(privKey)
+
+	encoded, err := tx.Encode()
+	assert.Equal(t, err, error(nil))
+
+	// the nil receiver is decoded as nil
+	decoded := new(Transaction)
+	assert.Equal(t, decoded.Decode(encoded), error(nil))
+	assert.Equal(t, decoded.Data.To == nil, true)
+	assert.Equal(t, decoded.CalculateHash(), tx.CalculateHash())
+}
+
+func Test_Block_Encoding(t *testing.T) {
+	block := NewBlock(newTestBlockHeader(t), []*Transaction{newTestTx(t, 1, 1, true), newTestTx(t, 2, 2, true)})
+
+	encoded, err := block.Encode()
+	assert.Equal(t, err, error(nil))
+
+	decoded := new(Block)
+	assert.Equal(t, decoded.Decode(encoded), error(nil))
+	// the tx hashes are cached once computed, as in the block
+	assert.Equal(t, MerkleRootHash(decoded.Transactions), block.Header.TxHash)
+	assert.Equal(t, decoded, block)
+	assert.Equal(t, decoded.Header.Hash(), block.HeaderHash)
+
+	// header only
+	encoded, err = block.Header.Encode()
+	assert.Equal(t, err, error(nil))
+
+	header := new(BlockHeader)
+	assert.Equal(t, header.Decode(encoded), error(nil))
+	assert.Equal(t, header, block.Header)
+}
+
+func Test_BlockHeader_Extensions(t *testing.T) {
+	// a header of a future version with appended fields
+	header := newTestBlockHeader(t)
+	header.Version = BlockHeaderVersion + 1
+	header.Extensions = []rlp.RawValue{common.SerializePanic(uint64(7)), common.SerializePanic("extension")}
+
+	encoded, err := header.Encode()
+	assert.Equal(t, err, error(nil))
+
+	decoded := new(BlockHeader)
+	assert.Equal(t, decoded.Decode(encoded), error(nil))
+	assert.Equal(t, decoded, header)
+	assert.Equal(t, decoded.Hash(), header.Hash())
+	assert.Equal(t, decoded.Clone(), header)
+}
+
+func Test_Decode_Invalid(t *testing.T) {
+	header := new(BlockHeader)
+	assert.Equal(t, header.Decode(nil), ErrEncodingEmpty)
+
+	encoded, err := newTestBlockHeader(t).Encode()
+	assert.Equal(t, err, error(nil))
+
+	encoded[0] = EncodingVersion + 1
+	assert.Equal(t, header.Decode(encoded), ErrEncodingVersion)
+}
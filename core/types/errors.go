@@ -0,0 +1,99 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+// TxError is the validation error of a transaction. It wraps the cause, e.g. ErrSigInvalid
+// or a *NonceError, which could be checked with errors.Is and errors.As.
+type TxError struct {
+	TxHash common.Hash // TxHash is the hash of the invalid transaction
+	Err    error       // Err is the cause of the validation failure
+}
+
+// newTxError wraps the validation error of the transaction of the specified hash,
+// and returns nil if the error is nil.
+func newTxError(txHash common.Hash, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &TxError{txHash, err}
+}
+
+func (e *TxError) Error() string {
+	return fmt.Sprintf("invalid tx %s, %s", e.TxHash.ToHex(), e.Err)
+}
+
+// Unwrap returns the cause of the validation failure.
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+// NonceError is returned when the nonce of a transaction is lower than the sender
+// account nonce, and it is ErrNonceTooLow with errors.Is.
+type NonceError struct {
+	Expected uint64 // Expected is the minimum nonce, i.e. the account nonce
+	Actual   uint64 // Actual is the nonce of the transaction
+}
+
+func (e *NonceError) Error() string {
+	return fmt.Sprintf("%s, expected at least %d, got %d", ErrNonceTooLow, e.Expected, e.Actual)
+}
+
+// Is indicates whether the error is ErrNonceTooLow.
+func (e *NonceError) Is(target error) bool {
+	return target == ErrNonceTooLow
+}
+
+// BalanceError is returned when the balance of the sender or fee payer could not cover the
+// cost of a transaction. It wraps ErrBalanceNotEnough or ErrPayerBalanceNotEnough.
+type BalanceError struct {
+	Account   common.Address // Account is the sender or fee payer
+	Required  *big.Int       // Required is the cost charged to the account
+	Available *big.Int       // Available is the balance of the account
+	Err       error          // Err is ErrBalanceNotEnough or ErrPayerBalanceNotEnough
+}
+
+func (e *BalanceError) Error() string {
+	return fmt.Sprintf("%s, account %s requires %s, has %s", e.Err, e.Account.ToHex(), e.Required, e.Available)
+}
+
+// Unwrap returns ErrBalanceNotEnough or ErrPayerBalanceNotEnough.
+func (e *BalanceError) Unwrap() error {
+	return e.Err
+}
+
+// ChainIDError is returned when a transaction is signed for another chain,
+// and it is ErrChainIDMismatch with errors.Is.
+type ChainIDError struct {
+	Expected uint64 // Expected is the chain id of the node
+	Actual   uint64 // Actual is the chain id of the transaction
+}
+
+func (e *ChainIDError) Error() string {
+	return fmt.Sprintf("%s, expected %d, got %d", ErrChainIDMismatch, e.Expected, e.Actual)
+}
+
+// Is indicates whether the error is ErrChainIDMismatch.
+func (e *ChainIDError) Is(target error) bool {
+	return target == ErrChainIDMismatch
+}
+
+// IsRecoverable indicates whether the transaction failed to validate for the specified error
+// may become valid later without changes, e.g. the balance is not enough until a deposit or
+// the sighash version is not activated yet. Other failures, e.g. invalid signature or nonce
+// too low, are fatal, and the transaction should be dropped.
+func IsRecoverable(err error) bool {
+	return errors.Is(err, ErrBalanceNotEnough) || errors.Is(err, ErrPayerBalanceNotEnough) ||
+		errors.Is(err, ErrSigHashNotActivated)
+}
@@ -0,0 +1,69 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_TxError_Nonce(t *testing.T) {
+	tx := newTestTx(t, 100, 38, true)
+	err := tx.Validate(newTestStateDB(tx.Data.From, 40, 200+TransferGas), DefaultChainConfig())
+
+	var txErr *TxError
+	assert.Equal(t, errors.As(err, &txErr), true)
+	assert.Equal(t, txErr.TxHash, tx.Hash)
+
+	var nonceErr *NonceError
+	assert.Equal(t, errors.As(err, &nonceErr), true)
+	assert.Equal(t, nonceErr.Expected, uint64(40))
+	assert.Equal(t, nonceErr.Actual, uint64(38))
+
+	assert.Equal(t, errors.Is(err, ErrNonceTooLow), true)
+	assert.Equal(t, errors.Is(err, ErrBalanceNotEnough), false)
+	assert.Equal(t, IsRecoverable(err), false)
+}
+
+func Test_TxError_Balance(t *testing.T) {
+	tx := newTestTx(t, 100, 38, true)
+	err := tx.Validate(newTestStateDB(tx.Data.From, 38, 50), DefaultChainConfig())
+
+	var balanceErr *BalanceError
+	assert.Equal(t, errors.As(err, &balanceErr), true)
+	assert.Equal(t, balanceErr.Account, tx.Data.From)
+	assert.Equal(t, balanceErr.Required, big.NewInt(100+TransferGas))
+	assert.Equal(t, balanceErr.Available, big.NewInt(50))
+	assert.Equal(t, IsRecoverable(err), true)
+}
+
+func Test_TxError_Fatal(t *testing.T) {
+	tx := newTestTx(t, 100, 38, false)
+	err := tx.Validate(newTestStateDB(tx.Data.From, 38, 200+TransferGas), DefaultChainConfig())
+
+	var txErr *TxError
+	assert.Equal(t, errors.As(err, &txErr), true)
+	assert.Equal(t, txErr.TxHash, tx.Hash)
+	assert.Equal(t, txErr.Err, ErrSigMissing)
+	assert.Equal(t, IsRecoverable(err), false)
+}
+
+func Test_ChainIDError(t *testing.T) {
+	rules := DefaultSigHashRules(3)
+	tx := newTestTx(t, 10, 1, true)
+
+	err := rules.Validate(tx, 1)
+
+	var chainIDErr *ChainIDError
+	assert.Equal(t, errors.As(err, &chainIDErr), true)
+	assert.Equal(t, chainIDErr.Expected, uint64(3))
+	assert.Equal(t, chainIDErr.Actual, uint64(0))
+	assert.Equal(t, errors.Is(err, ErrChainIDMismatch), true)
+	assert.Equal(t, IsRecoverable(err), false)
+}
@@ -0,0 +1,126 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// MaxRewardExtraSize limits the extra data size in the payload of the reward tx.
+const MaxRewardExtraSize = 1024
+
+var escrowAddressSalt = []byte("escrow")
+
+var (
+	// ErrEscrowMismatch is returned when the sender of the escrow release tx is not the escrow account.
+	ErrEscrowMismatch = errors.New("sender mismatches the escrow account")
+
+	// ErrCoSigMissing is returned when the escrow release tx is not co-signed by the auditor.
+	ErrCoSigMissing = errors.New("co-signature missing")
+
+	// ErrCoSigNotAllowed is returned when a tx other than the escrow release is co-signed.
+	ErrCoSigNotAllowed = errors.New("co-signature not allowed")
+
+	// ErrCoSigInvalid is returned when the co-signature is not signed by the auditor.
+	ErrCoSigInvalid = errors.New("co-signature is invalid")
+)
+
+// EscrowAccount is a native multisig account, whose balance could only be released by the
+// txs signed by both the operator and auditor. Its address is derived from the signers, so
+// that it could be the coinbase of a mining pool without registration.
+type EscrowAccount struct {
+	Operator common.Address // Operator is the signer of the release tx
+	Auditor  common.Address // Auditor is the co-signer of the release tx
+}
+
+// Address returns the address of the escrow account, which is not a public key
+// so that no tx could be signed by the escrow account itself.
+func (account EscrowAccount) Address() common.Address {
+	signersHash := crypto.MustHash(account)
+	return common.BytesToAddress(append(crypto.HashBytes(escrowAddressSalt).Bytes(), signersHash.Bytes()...))
+}
+
+// NewEscrowReleaseTransaction creates a new transaction to release the amount from the escrow
+// account to the receiver. The tx should be signed by the operator, and then co-signed by the auditor.
+func NewEscrowReleaseTransaction(account EscrowAccount, to common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64) (*Transaction, error) {
+	payload, err := common.Serialize(&account)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTx(TxTypeEscrowRelease, account.Address(), &to, amount, gasPrice, gasLimit, nonce, payload)
+}
+
+// CoSign co-signs the hash of the transaction with the specified private key, so the
+// transaction should be signed before co-signed, e.g. the escrow release and multisig txs.
+func (tx *Transaction) CoSign(privKey *ecdsa.PrivateKey) {
+	tx.CoSignatures = append(tx.CoSignatures, crypto.NewSignature(privKey, tx.Hash.Bytes()))
+}
+
+// escrowAccount decodes the escrow account from the payload of the escrow release tx.
+func (data *TransactionData) escrowAccount() (*EscrowAccount, error) {
+	account := new(EscrowAccount)
+	if err := common.Deserialize(data.Payload, account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// verifyEscrowRelease verifies the signature of the operator and the co-signature of the auditor
+// of the escrow account in payload, which should be the sender of the escrow release tx.
+func (tx *Transaction) verifyEscrowRelease(hash common.Hash) error {
+	account, err := tx.Data.escrowAccount()
+	if err != nil {
+		return ErrTxTypeMalformed
+	}
+
+	if !tx.Data.From.Equal(account.Address()) {
+		return ErrEscrowMismatch
+	}
+
+	if !tx.Signature.Verify(&account.Operator, hash.Bytes()) {
+		return ErrSigInvalid
+	}
+
+	if len(tx.CoSignatures) != 1 {
+		return ErrCoSigMissing
+	}
+
+	if !tx.CoSignatures[0].Verify(&account.Auditor, hash.Bytes()) {
+		return ErrCoSigInvalid
+	}
+
+	return nil
+}
+
+// PoolAccounting is the payout accounting data of a mining pool, which is committed in the
+// payload of the reward tx of the blocks mined by the pool.
+type PoolAccounting struct {
+	Escrow     EscrowAccount // Escrow is the escrow account the block reward is paid to
+	Round      uint64        // Round is the payout round of the pool
+	Shares     uint64        // Shares is the total number of the shares submitted in the round
+	SharesRoot common.Hash   // SharesRoot is the merkle root of the shares per miner in the round
+}
+
+// PoolAccounting decodes the pool accounting data from the payload of the reward tx.
+func (tx *Transaction) PoolAccounting() (*PoolAccounting, error) {
+	if err := tx.ValidateReward(); err != nil {
+		return nil, err
+	}
+
+	accounting := new(PoolAccounting)
+	if err := common.Deserialize(tx.Data.Payload, accounting); err != nil {
+		return nil, err
+	}
+
+	return accounting, nil
+}
@@ -0,0 +1,74 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_EscrowRelease(t *testing.T) {
+	operatorKey, operator := randomAccount(t)
+	auditorKey, auditor := randomAccount(t)
+	account := EscrowAccount{operator, auditor}
+
+	tx, err := NewEscrowReleaseTransaction(account, randomAddress(t), big.NewInt(10), big.NewInt(1), TransferGas, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, tx.Data.From, account.Address())
+
+	tx.Sign(operatorKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrCoSigMissing)
+
+	tx.CoSign(operatorKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrCoSigInvalid)
+
+	tx.CoSignatures = nil
+	tx.CoSign(auditorKey)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// signed by the auditor only
+	tx.Sign(auditorKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrSigInvalid)
+
+	// released from another account
+	tx.Data.From = EscrowAccount{auditor, operator}.Address()
+	tx.Sign(operatorKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrTxTypeMalformed)
+}
+
+func Test_CoSig_NotAllowed(t *testing.T) {
+	privKey, from := randomAccount(t)
+	tx := newTestTx(t, 10, 1, false)
+	tx.Data.From = from
+	tx.Sign(privKey)
+	tx.CoSign(privKey)
+
+	assert.Equal(t, tx.validateWithoutState(), ErrCoSigNotAllowed)
+}
+
+func Test_PoolAccounting(t *testing.T) {
+	accounting := &PoolAccounting{
+		Escrow:     EscrowAccount{randomAddress(t), randomAddress(t)},
+		Round:      3,
+		Shares:     100,
+		SharesRoot: crypto.HashBytes([]byte("shares")),
+	}
+
+	extra := common.SerializePanic(accounting)
+	tx := NewRewardTransaction(accounting.Escrow.Address(), big.NewInt(100), extra)
+	assert.Equal(t, tx.ValidateReward(), error(nil))
+
+	decoded, err := tx.PoolAccounting()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, decoded, accounting)
+
+	tx.Data.Payload = make([]byte, MaxRewardExtraSize+1)
+	assert.Equal(t, tx.ValidateReward(), ErrTxTypeMalformed)
+}
@@ -0,0 +1,82 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+)
+
+const (
+	// MaxTxExtensions is the maximum number of extensions of a transaction.
+	MaxTxExtensions = 8
+
+	// MaxTxExtensionKeyLength is the maximum length of an extension key.
+	MaxTxExtensionKeyLength = 32
+
+	// MaxTxExtensionsSize is the maximum total size of the keys and values of the extensions.
+	MaxTxExtensionsSize = 1024
+)
+
+var (
+	// ErrExtensionsTooMany is returned when the transaction has more than MaxTxExtensions extensions.
+	ErrExtensionsTooMany = errors.New("too many extensions")
+
+	// ErrExtensionKeyInvalid is returned when an extension key is empty or longer than MaxTxExtensionKeyLength,
+	// or the keys are not in strictly ascending order, i.e. sorted and unique.
+	ErrExtensionKeyInvalid = errors.New("invalid extension key")
+
+	// ErrExtensionsOversized is returned when the total size of the extensions exceeds MaxTxExtensionsSize.
+	ErrExtensionsOversized = errors.New("oversized extensions")
+
+	// ErrExtensionsNotSigned is returned when the tx extensions are not hashed in the sighash version.
+	ErrExtensionsNotSigned = errors.New("extensions not signed in the sighash version")
+)
+
+// TxExtension is a key/value pair attached to a transaction, e.g. an access list or a
+// routing hint, so that the later features could be added without changing the format
+// of TransactionData. The extensions of unknown keys are kept and ignored.
+type TxExtension struct {
+	Key   string // Key identifies the feature of the extension
+	Value []byte // Value is the feature-specific encoded data
+}
+
+// GetExtension returns the value of the extension with the specified key.
+func (data *TransactionData) GetExtension(key string) ([]byte, bool) {
+	for _, ext := range data.Extensions {
+		if ext.Key == key {
+			return ext.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// validateExtensions validates the consensus rules of the extensions, in which the keys are
+// sorted and unique so that the encoding of the same extensions is canonical.
+func (data *TransactionData) validateExtensions() error {
+	if len(data.Extensions) > MaxTxExtensions {
+		return ErrExtensionsTooMany
+	}
+
+	size := 0
+	for i, ext := range data.Extensions {
+		if len(ext.Key) == 0 || len(ext.Key) > MaxTxExtensionKeyLength {
+			return ErrExtensionKeyInvalid
+		}
+
+		if i > 0 && ext.Key <= data.Extensions[i-1].Key {
+			return ErrExtensionKeyInvalid
+		}
+
+		size += len(ext.Key) + len(ext.Value)
+	}
+
+	if size > MaxTxExtensionsSize {
+		return ErrExtensionsOversized
+	}
+
+	return nil
+}
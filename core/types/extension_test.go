@@ -0,0 +1,70 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_TransactionData_validateExtensions(t *testing.T) {
+	data := &TransactionData{}
+	assert.Equal(t, data.validateExtensions(), error(nil))
+
+	data.Extensions = []TxExtension{{"a", []byte{1}}, {"b", nil}}
+	assert.Equal(t, data.validateExtensions(), error(nil))
+
+	value, found := data.GetExtension("a")
+	assert.Equal(t, found, true)
+	assert.Equal(t, value, []byte{1})
+
+	_, found = data.GetExtension("c")
+	assert.Equal(t, found, false)
+
+	// keys must be sorted and unique
+	data.Extensions = []TxExtension{{"b", nil}, {"a", nil}}
+	assert.Equal(t, data.validateExtensions(), ErrExtensionKeyInvalid)
+
+	data.Extensions = []TxExtension{{"a", nil}, {"a", nil}}
+	assert.Equal(t, data.validateExtensions(), ErrExtensionKeyInvalid)
+
+	data.Extensions = []TxExtension{{"", nil}}
+	assert.Equal(t, data.validateExtensions(), ErrExtensionKeyInvalid)
+
+	data.Extensions = []TxExtension{{strings.Repeat("k", MaxTxExtensionKeyLength+1), nil}}
+	assert.Equal(t, data.validateExtensions(), ErrExtensionKeyInvalid)
+
+	data.Extensions = []TxExtension{{"a", make([]byte, MaxTxExtensionsSize)}}
+	assert.Equal(t, data.validateExtensions(), ErrExtensionsOversized)
+
+	data.Extensions = make([]TxExtension, MaxTxExtensions+1)
+	assert.Equal(t, data.validateExtensions(), ErrExtensionsTooMany)
+}
+
+func Test_Transaction_Extensions_Encoding(t *testing.T) {
+	tx := newTestTx(t, 100, 38, true)
+	data := *tx.Data
+	data.Extensions = []TxExtension{{"accessList", []byte{1, 2}}}
+	tx.Data = &data
+
+	encoded, err := tx.Encode()
+	assert.Equal(t, err, error(nil))
+
+	decoded := new(Transaction)
+	assert.Equal(t, decoded.Decode(encoded), error(nil))
+	assert.Equal(t, decoded.Data, tx.Data)
+
+	encoded, err = json.Marshal(tx.Data.Extensions[0])
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, string(encoded), `{"Key":"accessList","Value":"0x0102"}`)
+
+	var ext TxExtension
+	assert.Equal(t, json.Unmarshal(encoded, &ext), error(nil))
+	assert.Equal(t, ext, tx.Data.Extensions[0])
+}
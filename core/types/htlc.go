@@ -0,0 +1,222 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// HTLCPreimageSize is the size of the secret preimage of the hash lock, as the other chains of the swaps.
+const HTLCPreimageSize = 32
+
+var htlcAddressSalt = []byte("htlc")
+
+var (
+	// HTLCClaimTopic is the first topic of the log emitted by the HTLC claim tx, whose data is the
+	// preimage, so that the counterparty of the swap could monitor the claim via the logs.
+	HTLCClaimTopic = crypto.HashBytes([]byte("htlc.claim"))
+
+	// HTLCRefundTopic is the first topic of the log emitted by the HTLC refund tx.
+	HTLCRefundTopic = crypto.HashBytes([]byte("htlc.refund"))
+)
+
+var (
+	// ErrHTLCInvalid is returned when the sender, receiver or locks of the HTLC are invalid.
+	ErrHTLCInvalid = errors.New("invalid HTLC")
+
+	// ErrHTLCPreimageMismatch is returned when the preimage of the HTLC claim tx mismatches the hash lock.
+	ErrHTLCPreimageMismatch = errors.New("HTLC preimage mismatches the hash lock")
+
+	// ErrHTLCExpired is returned when the HTLC is claimed since the time lock.
+	ErrHTLCExpired = errors.New("HTLC time lock expired")
+
+	// ErrHTLCLocked is returned when the HTLC is refunded before the time lock.
+	ErrHTLCLocked = errors.New("HTLC refund is locked until the time lock")
+)
+
+// HTLC is a native hash-time-locked account for the atomic swaps with other chains. The funds
+// transferred to its address could be claimed by the receiver with the preimage of the hash lock
+// before the time lock, or refunded to the sender since then. Its address is derived from the
+// fields, so that the funds are locked with a plain transfer.
+type HTLC struct {
+	Sender   common.Address // Sender is refunded since the time lock
+	Receiver common.Address // Receiver claims the funds with the preimage before the time lock
+	HashLock common.Hash    // HashLock is the SHA-256 hash of the preimage
+	TimeLock uint64         // TimeLock is the block height since which the funds could only be refunded
+}
+
+// HTLCUnlock is the payload of the HTLC tx, which claims the funds with the preimage, or
+// refunds the funds if the preimage is empty.
+type HTLCUnlock struct {
+	HTLC     HTLC
+	Preimage []byte
+}
+
+// Address returns the address of the HTLC, which is not a public key
+// so that no tx could be signed by the HTLC itself.
+func (htlc *HTLC) Address() common.Address {
+	htlcHash := crypto.MustHash(htlc)
+	return common.BytesToAddress(append(crypto.HashBytes(htlcAddressSalt).Bytes(), htlcHash.Bytes()...))
+}
+
+// Validate validates the sender, receiver and locks of the HTLC.
+func (htlc *HTLC) Validate() error {
+	if htlc.Sender.Equal(common.Address{}) || htlc.Receiver.Equal(common.Address{}) || htlc.Sender.Equal(htlc.Receiver) {
+		return ErrHTLCInvalid
+	}
+
+	if htlc.HashLock.IsEmpty() || htlc.TimeLock == 0 {
+		return ErrHTLCInvalid
+	}
+
+	return nil
+}
+
+// HTLCHashLock returns the hash lock of the specified preimage.
+func HTLCHashLock(preimage []byte) common.Hash {
+	hash := sha256.Sum256(preimage)
+	return common.BytesToHash(hash[:])
+}
+
+// NewHTLCClaimTransaction creates a new transaction to claim the amount from the HTLC to the
+// receiver with the preimage of the hash lock, which should be signed by the receiver.
+func NewHTLCClaimTransaction(htlc *HTLC, preimage []byte, amount, gasPrice *big.Int, gasLimit, nonce uint64) (*Transaction, error) {
+	if err := htlc.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(preimage) != HTLCPreimageSize || !HTLCHashLock(preimage).Equal(htlc.HashLock) {
+		return nil, ErrHTLCPreimageMismatch
+	}
+
+	return newHTLCTx(htlc, preimage, htlc.Receiver, amount, gasPrice, gasLimit, nonce)
+}
+
+// NewHTLCRefundTransaction creates a new transaction to refund the amount from the HTLC to the
+// sender since the time lock, which should be signed by the sender.
+func NewHTLCRefundTransaction(htlc *HTLC, amount, gasPrice *big.Int, gasLimit, nonce uint64) (*Transaction, error) {
+	if err := htlc.Validate(); err != nil {
+		return nil, err
+	}
+
+	return newHTLCTx(htlc, nil, htlc.Sender, amount, gasPrice, gasLimit, nonce)
+}
+
+func newHTLCTx(htlc *HTLC, preimage []byte, to common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64) (*Transaction, error) {
+	payload, err := common.Serialize(&HTLCUnlock{*htlc, preimage})
+	if err != nil {
+		return nil, err
+	}
+
+	return newTx(TxTypeHTLC, htlc.Address(), &to, amount, gasPrice, gasLimit, nonce, payload)
+}
+
+// HTLCUnlock decodes the HTLC and preimage from the payload of the HTLC tx.
+func (tx *Transaction) HTLCUnlock() (*HTLCUnlock, error) {
+	if tx.Data == nil || tx.Data.Type != TxTypeHTLC {
+		return nil, ErrTxTypeMalformed
+	}
+
+	return tx.Data.htlcUnlock()
+}
+
+// IsClaim returns true if the HTLC tx claims the funds with the preimage, otherwise it is a refund.
+func (unlock *HTLCUnlock) IsClaim() bool {
+	return len(unlock.Preimage) > 0
+}
+
+// htlcUnlock decodes the payload of the HTLC tx, and validates it against the receiver.
+func (data *TransactionData) htlcUnlock() (*HTLCUnlock, error) {
+	unlock := new(HTLCUnlock)
+	if err := common.Deserialize(data.Payload, unlock); err != nil {
+		return nil, err
+	}
+
+	if err := unlock.HTLC.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !data.From.Equal(unlock.HTLC.Address()) {
+		return nil, ErrTxTypeMalformed
+	}
+
+	if unlock.IsClaim() {
+		if len(unlock.Preimage) != HTLCPreimageSize || !HTLCHashLock(unlock.Preimage).Equal(unlock.HTLC.HashLock) {
+			return nil, ErrHTLCPreimageMismatch
+		}
+
+		if data.To == nil || !data.To.Equal(unlock.HTLC.Receiver) {
+			return nil, ErrTxTypeMalformed
+		}
+	} else if data.To == nil || !data.To.Equal(unlock.HTLC.Sender) {
+		return nil, ErrTxTypeMalformed
+	}
+
+	return unlock, nil
+}
+
+// verifyHTLC verifies that the HTLC claim tx is signed by the receiver,
+// or the HTLC refund tx is signed by the sender.
+func (tx *Transaction) verifyHTLC(hash common.Hash) error {
+	if _, err := tx.Data.htlcUnlock(); err != nil {
+		return err
+	}
+
+	if len(tx.CoSignatures) > 0 {
+		return ErrCoSigNotAllowed
+	}
+
+	// the receiver of the tx is the signer, i.e. the HTLC receiver for claim or sender for refund
+	if !tx.Signature.Verify(tx.Data.To, hash.Bytes()) {
+		return ErrSigInvalid
+	}
+
+	return nil
+}
+
+// ValidateHTLC validates the time lock of the HTLC tx for the block of the specified height, in
+// which the claim tx should be included before the time lock, and the refund tx since then.
+// It returns nil for the other txs.
+func (tx *Transaction) ValidateHTLC(height uint64) error {
+	if tx.Data.Type != TxTypeHTLC {
+		return nil
+	}
+
+	unlock, err := tx.Data.htlcUnlock()
+	if err != nil {
+		return err
+	}
+
+	if unlock.IsClaim() && height >= unlock.HTLC.TimeLock {
+		return ErrHTLCExpired
+	}
+
+	if !unlock.IsClaim() && height < unlock.HTLC.TimeLock {
+		return ErrHTLCLocked
+	}
+
+	return nil
+}
+
+// Log returns the log of the HTLC tx, which is emitted by the HTLC address with the topics
+// of the claim or refund and the hash lock. The data of the claim log is the preimage.
+func (unlock *HTLCUnlock) Log() *Log {
+	topic := HTLCRefundTopic
+	if unlock.IsClaim() {
+		topic = HTLCClaimTopic
+	}
+
+	return &Log{
+		Address: unlock.HTLC.Address(),
+		Topics:  []common.Hash{topic, unlock.HTLC.HashLock},
+		Data:    unlock.Preimage,
+	}
+}
@@ -0,0 +1,107 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+)
+
+func newTestHTLC(t *testing.T) (*HTLC, []byte) {
+	preimage := make([]byte, HTLCPreimageSize)
+	preimage[0] = 1
+
+	return &HTLC{
+		Sender:   randomAddress(t),
+		Receiver: randomAddress(t),
+		HashLock: HTLCHashLock(preimage),
+		TimeLock: 100,
+	}, preimage
+}
+
+func Test_HTLC_Validate(t *testing.T) {
+	htlc, _ := newTestHTLC(t)
+	assert.Equal(t, htlc.Validate(), error(nil))
+
+	assert.Equal(t, (&HTLC{htlc.Sender, htlc.Sender, htlc.HashLock, 100}).Validate(), ErrHTLCInvalid)
+	assert.Equal(t, (&HTLC{htlc.Sender, htlc.Receiver, common.EmptyHash, 100}).Validate(), ErrHTLCInvalid)
+	assert.Equal(t, (&HTLC{htlc.Sender, htlc.Receiver, htlc.HashLock, 0}).Validate(), ErrHTLCInvalid)
+
+	// the address depends on the time lock
+	assert.Equal(t, htlc.Address() == (&HTLC{htlc.Sender, htlc.Receiver, htlc.HashLock, 101}).Address(), false)
+}
+
+func Test_HTLC_Claim(t *testing.T) {
+	receiverKey, receiver := randomAccount(t)
+	htlc, preimage := newTestHTLC(t)
+	htlc.Receiver = receiver
+
+	_, err := NewHTLCClaimTransaction(htlc, make([]byte, HTLCPreimageSize), big.NewInt(10), big.NewInt(1), TransferGas, 0)
+	assert.Equal(t, err, ErrHTLCPreimageMismatch)
+
+	tx, err := NewHTLCClaimTransaction(htlc, preimage, big.NewInt(10), big.NewInt(1), TransferGas, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, tx.Data.From, htlc.Address())
+	assert.Equal(t, *tx.Data.To, receiver)
+	assert.Equal(t, tx.Data.CallInput(), []byte(nil))
+
+	tx.Sign(receiverKey)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	sender, err := tx.Sender()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sender, receiver)
+
+	// claimed before the time lock
+	assert.Equal(t, tx.ValidateHTLC(99), error(nil))
+	assert.Equal(t, tx.ValidateHTLC(100), ErrHTLCExpired)
+
+	unlock, err := tx.HTLCUnlock()
+	assert.Equal(t, err, error(nil))
+	log := unlock.Log()
+	assert.Equal(t, log.Address, htlc.Address())
+	assert.Equal(t, log.Topics, []common.Hash{HTLCClaimTopic, htlc.HashLock})
+	assert.Equal(t, log.Data, preimage)
+
+	// signed by others
+	otherKey, _ := randomAccount(t)
+	tx.Sign(otherKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrSigInvalid)
+
+	// claimed to others
+	other := randomAddress(t)
+	tx.Data.To = &other
+	tx.Sign(receiverKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrTxTypeMalformed)
+}
+
+func Test_HTLC_Refund(t *testing.T) {
+	senderKey, sender := randomAccount(t)
+	htlc, _ := newTestHTLC(t)
+	htlc.Sender = sender
+
+	tx, err := NewHTLCRefundTransaction(htlc, big.NewInt(10), big.NewInt(1), TransferGas, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, *tx.Data.To, sender)
+
+	tx.Sign(senderKey)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// refunded since the time lock
+	assert.Equal(t, tx.ValidateHTLC(99), ErrHTLCLocked)
+	assert.Equal(t, tx.ValidateHTLC(100), error(nil))
+
+	unlock, err := tx.HTLCUnlock()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, unlock.IsClaim(), false)
+	assert.Equal(t, unlock.Log().Topics[0], HTLCRefundTopic)
+
+	// the other txs are not time locked
+	assert.Equal(t, newTestTx(t, 10, 1, false).ValidateHTLC(0), error(nil))
+}
@@ -0,0 +1,103 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// txDataJSON overrides the big.Int and bytes fields of TransactionData in hex.
+type txDataJSON struct {
+	*txDataFields
+	Amount   *hexutil.Big
+	GasPrice *hexutil.Big
+	Payload  hexutil.Bytes
+}
+
+type txDataFields TransactionData
+
+// MarshalJSON implements the json.Marshaler interface, in which the amount, gas price and
+// payload are encoded in 0x-prefixed hex.
+func (data TransactionData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&txDataJSON{
+		txDataFields: (*txDataFields)(&data),
+		Amount:       (*hexutil.Big)(data.Amount),
+		GasPrice:     (*hexutil.Big)(data.GasPrice),
+		Payload:      data.Payload,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (data *TransactionData) UnmarshalJSON(input []byte) error {
+	dec := txDataJSON{txDataFields: (*txDataFields)(data)}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	data.Amount = (*big.Int)(dec.Amount)
+	data.GasPrice = (*big.Int)(dec.GasPrice)
+	data.Payload = dec.Payload
+
+	return nil
+}
+
+// txExtensionJSON overrides the value of TxExtension in hex.
+type txExtensionJSON struct {
+	Key   string
+	Value hexutil.Bytes
+}
+
+// MarshalJSON implements the json.Marshaler interface, in which the value is encoded in 0x-prefixed hex.
+func (ext TxExtension) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&txExtensionJSON{ext.Key, ext.Value})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (ext *TxExtension) UnmarshalJSON(input []byte) error {
+	var dec txExtensionJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	ext.Key, ext.Value = dec.Key, dec.Value
+
+	return nil
+}
+
+// headerJSON overrides the big.Int fields of BlockHeader in hex.
+type headerJSON struct {
+	*headerFields
+	Difficulty      *hexutil.Big
+	CreateTimestamp *hexutil.Big
+}
+
+type headerFields BlockHeader
+
+// MarshalJSON implements the json.Marshaler interface, in which the difficulty and
+// timestamp are encoded in 0x-prefixed hex.
+func (header BlockHeader) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&headerJSON{
+		headerFields:    (*headerFields)(&header),
+		Difficulty:      (*hexutil.Big)(header.Difficulty),
+		CreateTimestamp: (*hexutil.Big)(header.CreateTimestamp),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (header *BlockHeader) UnmarshalJSON(input []byte) error {
+	dec := headerJSON{headerFields: (*headerFields)(header)}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	header.Difficulty = (*big.Int)(dec.Difficulty)
+	header.CreateTimestamp = (*big.Int)(dec.CreateTimestamp)
+
+	return nil
+}
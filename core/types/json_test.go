@@ -0,0 +1,48 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_Transaction_JSON(t *testing.T) {
+	privKey, from := randomAccount(t)
+	tx, _ := NewMessageTransaction(DefaultChainConfig(), from, randomAddress(t), big.NewInt(255), big.NewInt(1), TransferGas, 1, []byte{1, 2})
+	tx.Sign(privKey)
+
+	encoded, err := json.Marshal(tx)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, strings.Contains(string(encoded), `"Hash":"`+tx.Hash.ToHex()+`"`), true)
+	assert.Equal(t, strings.Contains(string(encoded), `"From":"`+tx.Data.From.ToHex()+`"`), true)
+	assert.Equal(t, strings.Contains(string(encoded), `"Amount":"0xff"`), true)
+	assert.Equal(t, strings.Contains(string(encoded), `"Payload":"0x0102"`), true)
+
+	decoded := new(Transaction)
+	assert.Equal(t, json.Unmarshal(encoded, decoded), error(nil))
+	assert.Equal(t, decoded, tx)
+	assert.Equal(t, decoded.validateWithoutState(), error(nil))
+}
+
+func Test_Block_JSON(t *testing.T) {
+	block := NewBlock(newTestBlockHeader(t), []*Transaction{newTestTx(t, 10, 1, true)})
+
+	encoded, err := json.Marshal(block)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, strings.Contains(string(encoded), `"HeaderHash":"`+block.HeaderHash.ToHex()+`"`), true)
+	assert.Equal(t, strings.Contains(string(encoded), `"Difficulty":"0x`), true)
+
+	decoded := new(Block)
+	assert.Equal(t, json.Unmarshal(encoded, decoded), error(nil))
+	// the tx hashes are cached once computed, as in the block
+	assert.Equal(t, MerkleRootHash(decoded.Transactions), block.Header.TxHash)
+	assert.Equal(t, decoded, block)
+}
@@ -0,0 +1,136 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// MaxMultisigSigners limits the number of the signers of a multisig account.
+const MaxMultisigSigners = 16
+
+var multisigAddressSalt = []byte("multisig")
+
+var (
+	// ErrMultisigAccountInvalid is returned when the threshold or signers of the multisig account are invalid.
+	ErrMultisigAccountInvalid = errors.New("invalid multisig account")
+
+	// ErrMultisigThreshold is returned when the multisig tx is signed by less signers than the threshold.
+	ErrMultisigThreshold = errors.New("not enough signatures of the multisig account")
+)
+
+// MultisigAccount is a native M-of-N multisig account, whose balance could only be transferred
+// by the txs signed by at least Threshold of the Signers. Its address is derived from the
+// threshold and signers, so that no registration is required, e.g. for a shared treasury.
+type MultisigAccount struct {
+	Threshold uint64
+	Signers   []common.Address
+}
+
+// Address returns the address of the multisig account, which is not a public key
+// so that no tx could be signed by the multisig account itself.
+func (account *MultisigAccount) Address() common.Address {
+	accountHash := crypto.MustHash(account)
+	return common.BytesToAddress(append(crypto.HashBytes(multisigAddressSalt).Bytes(), accountHash.Bytes()...))
+}
+
+// Validate validates the threshold and signers of the multisig account.
+func (account *MultisigAccount) Validate() error {
+	if len(account.Signers) == 0 || len(account.Signers) > MaxMultisigSigners {
+		return ErrMultisigAccountInvalid
+	}
+
+	if account.Threshold == 0 || account.Threshold > uint64(len(account.Signers)) {
+		return ErrMultisigAccountInvalid
+	}
+
+	for i, signer := range account.Signers {
+		for _, other := range account.Signers[:i] {
+			if signer.Equal(other) {
+				return ErrMultisigAccountInvalid
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewMultisigTransaction creates a new transaction to transfer the amount from the multisig account
+// to the receiver. The tx should be signed by one of the signers, and then co-signed by the others.
+func NewMultisigTransaction(account *MultisigAccount, to common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64) (*Transaction, error) {
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	payload, err := common.Serialize(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTx(TxTypeMultisig, account.Address(), &to, amount, gasPrice, gasLimit, nonce, payload)
+}
+
+// multisigAccount decodes the multisig account from the payload of the multisig tx.
+func (data *TransactionData) multisigAccount() (*MultisigAccount, error) {
+	account := new(MultisigAccount)
+	if err := common.Deserialize(data.Payload, account); err != nil {
+		return nil, err
+	}
+
+	if err := account.Validate(); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// verifyMultisig verifies that the signature and co-signatures of the multisig tx are
+// signed by at least threshold distinct signers of the multisig account in payload.
+func (tx *Transaction) verifyMultisig(hash common.Hash) error {
+	account, err := tx.Data.multisigAccount()
+	if err != nil {
+		return ErrTxTypeMalformed
+	}
+
+	signatures := append([]*crypto.Signature{tx.Signature}, tx.CoSignatures...)
+	if len(signatures) > len(account.Signers) {
+		return ErrSigInvalid
+	}
+
+	signed := make([]bool, len(account.Signers))
+	for _, sig := range signatures {
+		if !verifyAnySigner(sig, account.Signers, signed, hash) {
+			return ErrSigInvalid
+		}
+	}
+
+	if uint64(len(signatures)) < account.Threshold {
+		return ErrMultisigThreshold
+	}
+
+	return nil
+}
+
+// verifyAnySigner verifies the signature against the signers not signed yet, and marks the signer
+// as signed if verified, so that a signer could not be counted twice.
+func verifyAnySigner(sig *crypto.Signature, signers []common.Address, signed []bool, hash common.Hash) bool {
+	if sig == nil {
+		return false
+	}
+
+	for i := range signers {
+		if !signed[i] && sig.Verify(&signers[i], hash.Bytes()) {
+			signed[i] = true
+			return true
+		}
+	}
+
+	return false
+}
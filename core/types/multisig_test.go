@@ -0,0 +1,71 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_MultisigAccount_Validate(t *testing.T) {
+	signer1, signer2 := randomAddress(t), randomAddress(t)
+
+	assert.Equal(t, (&MultisigAccount{1, nil}).Validate(), ErrMultisigAccountInvalid)
+	assert.Equal(t, (&MultisigAccount{0, []common.Address{signer1}}).Validate(), ErrMultisigAccountInvalid)
+	assert.Equal(t, (&MultisigAccount{2, []common.Address{signer1}}).Validate(), ErrMultisigAccountInvalid)
+	assert.Equal(t, (&MultisigAccount{1, []common.Address{signer1, signer1}}).Validate(), ErrMultisigAccountInvalid)
+	assert.Equal(t, (&MultisigAccount{2, []common.Address{signer1, signer2}}).Validate(), error(nil))
+
+	// the address depends on the threshold
+	assert.Equal(t, (&MultisigAccount{1, []common.Address{signer1, signer2}}).Address() == (&MultisigAccount{2, []common.Address{signer1, signer2}}).Address(), false)
+}
+
+func Test_Multisig_Validate(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	account := &MultisigAccount{Threshold: 2}
+	for i := range keys {
+		var signer common.Address
+		keys[i], signer = randomAccount(t)
+		account.Signers = append(account.Signers, signer)
+	}
+
+	tx, err := NewMultisigTransaction(account, randomAddress(t), big.NewInt(10), big.NewInt(1), TransferGas, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, tx.Data.From, account.Address())
+
+	// 1 of 3
+	tx.Sign(keys[2])
+	assert.Equal(t, tx.validateWithoutState(), ErrMultisigThreshold)
+
+	// signed twice by the same signer
+	tx.CoSign(keys[2])
+	assert.Equal(t, tx.validateWithoutState(), ErrSigInvalid)
+
+	// 2 of 3
+	tx.CoSignatures = nil
+	tx.CoSign(keys[0])
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// 3 of 3
+	tx.CoSign(keys[1])
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// signed by others
+	otherKey, _ := randomAccount(t)
+	tx.CoSignatures[1] = crypto.NewSignature(otherKey, tx.Hash.Bytes())
+	assert.Equal(t, tx.validateWithoutState(), ErrSigInvalid)
+
+	// the signer set is changed
+	account.Threshold = 1
+	tx.Data.Payload = common.SerializePanic(account)
+	tx.Sign(keys[2])
+	assert.Equal(t, tx.validateWithoutState(), ErrTxTypeMalformed)
+}
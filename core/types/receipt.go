@@ -5,7 +5,19 @@
 
 package types
 
-import "github.com/seeleteam/go-seele/common"
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/merkle"
+)
+
+var (
+	// ErrReceiptProofInvalid is returned when the receipt is not proved in the receipt trie of the block.
+	ErrReceiptProofInvalid = errors.New("invalid receipt proof")
+)
 
 // Receipt represents the transaction processing receipt.
 type Receipt struct {
@@ -14,4 +26,115 @@ type Receipt struct {
 	Logs            []*Log // the log objects
 	TxHash          common.Hash // the hash of the executed transaction
 	ContractAddress common.Address // Used when the tx (nil To address) is to create a contract.
+	UsedGas         uint64 // the gas used by the tx, including the intrinsic gas
+	Fee             *big.Int // the fee of the used gas paid to the miner
+	Failed          bool // the tx failed in execution, e.g. reverted or out of gas, and only the fee is charged
+}
+
+// receiptHashData is the preimage of the receipt hash, in which the logs only contain
+// the consensus fields, since the derived fields are filled in by the node afterwards.
+type receiptHashData struct {
+	Result          []byte
+	PostState       common.Hash
+	Logs            []*logHashData
+	TxHash          common.Hash
+	ContractAddress common.Address
+	UsedGas         uint64
+	Fee             *big.Int
+	Failed          bool
+}
+
+type logHashData struct {
+	Address common.Address
+	Topics  []common.Hash
+	Data    []byte
+}
+
+// CalculateHash calculates and returns the receipt hash.
+// This is to implement the merkle.Content interface.
+func (receipt *Receipt) CalculateHash() common.Hash {
+	preimage := &receiptHashData{
+		Result:          receipt.Result,
+		PostState:       receipt.PostState,
+		Logs:            make([]*logHashData, len(receipt.Logs)),
+		TxHash:          receipt.TxHash,
+		ContractAddress: receipt.ContractAddress,
+		UsedGas:         receipt.UsedGas,
+		Fee:             receipt.Fee,
+		Failed:          receipt.Failed,
+	}
+
+	for i, log := range receipt.Logs {
+		preimage.Logs[i] = &logHashData{log.Address, log.Topics, log.Data}
+	}
+
+	return crypto.MustHash(preimage)
+}
+
+// Equals indicates if the receipt is equal to the specified content.
+// This is to implement the merkle.Content interface.
+func (receipt *Receipt) Equals(other merkle.Content) bool {
+	otherReceipt, ok := other.(*Receipt)
+	return ok && receipt.CalculateHash().Equal(otherReceipt.CalculateHash())
+}
+
+// ReceiptMerkleRootHash calculates and returns the merkle root hash of the specified receipts.
+// If the given receipts are empty, return empty hash.
+func ReceiptMerkleRootHash(receipts []*Receipt) common.Hash {
+	if len(receipts) == 0 {
+		return common.EmptyHash
+	}
+
+	bmt, _ := merkle.NewTree(receiptContents(receipts))
+
+	return bmt.MerkleRoot()
+}
+
+func receiptContents(receipts []*Receipt) []merkle.Content {
+	contents := make([]merkle.Content, len(receipts))
+	for i, receipt := range receipts {
+		contents[i] = receipt
+	}
+
+	return contents
+}
+
+// ReceiptProof proves that the receipt is produced in the block, which could be verified
+// against the block header only, e.g. by a light client or a cross-chain bridge.
+type ReceiptProof struct {
+	BlockHash common.Hash        // BlockHash is the hash of the block the receipt is produced in
+	Receipt   *Receipt           // Receipt is the proved receipt
+	Proof     []merkle.ProofNode // Proof is the merkle path from the receipt to the receipt root
+}
+
+// NewReceiptProof creates the proof of the receipt at the specified index of the block receipts.
+func NewReceiptProof(blockHash common.Hash, receipts []*Receipt, index int) (*ReceiptProof, error) {
+	if index < 0 || index >= len(receipts) {
+		return nil, ErrReceiptProofInvalid
+	}
+
+	bmt, err := merkle.NewTree(receiptContents(receipts))
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := bmt.Proof(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReceiptProof{blockHash, receipts[index], proof}, nil
+}
+
+// VerifyReceiptProof verifies the receipt proof against the specified block header.
+func VerifyReceiptProof(header *BlockHeader, proof *ReceiptProof) error {
+	if proof == nil || proof.Receipt == nil || !proof.BlockHash.Equal(header.Hash()) {
+		return ErrReceiptProofInvalid
+	}
+
+	if !merkle.VerifyProof(header.ReceiptHash, proof.Receipt.CalculateHash(), proof.Proof) {
+		return ErrReceiptProofInvalid
+	}
+
+	return nil
 }
@@ -0,0 +1,86 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func newTestReceipts(t *testing.T, count int) []*Receipt {
+	receipts := make([]*Receipt, count)
+	for i := range receipts {
+		receipts[i] = &Receipt{
+			Result:    []byte{byte(i)},
+			PostState: crypto.HashBytes([]byte{byte(i)}),
+			Logs: []*Log{{
+				Address: randomAddress(t),
+				Topics:  []common.Hash{crypto.HashBytes([]byte("topic"))},
+				Data:    []byte("data"),
+			}},
+			TxHash:  crypto.HashBytes([]byte{byte(i), 1}),
+			UsedGas: TransferGas,
+			Fee:     big.NewInt(int64(i)),
+		}
+	}
+
+	return receipts
+}
+
+func Test_Receipt_CalculateHash(t *testing.T) {
+	receipt := newTestReceipts(t, 1)[0]
+	hash := receipt.CalculateHash()
+
+	// derived fields of logs are not hashed
+	receipt.Logs[0].BlockNumber = 10
+	receipt.Logs[0].TxIndex = 1
+	assert.Equal(t, receipt.CalculateHash(), hash)
+
+	receipt.UsedGas++
+	assert.Equal(t, receipt.CalculateHash() == hash, false)
+}
+
+func Test_ReceiptProof(t *testing.T) {
+	assert.Equal(t, ReceiptMerkleRootHash(nil), common.EmptyHash)
+
+	for _, count := range []int{1, 2, 3, 5} {
+		receipts := newTestReceipts(t, count)
+		header := &BlockHeader{
+			ReceiptHash:     ReceiptMerkleRootHash(receipts),
+			Difficulty:      big.NewInt(1),
+			CreateTimestamp: big.NewInt(1),
+		}
+
+		for i := range receipts {
+			proof, err := NewReceiptProof(header.Hash(), receipts, i)
+			assert.Equal(t, err, error(nil))
+			assert.Equal(t, VerifyReceiptProof(header, proof), error(nil))
+		}
+	}
+
+	receipts := newTestReceipts(t, 3)
+	header := &BlockHeader{
+		ReceiptHash:     ReceiptMerkleRootHash(receipts),
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(1),
+	}
+
+	_, err := NewReceiptProof(header.Hash(), receipts, 3)
+	assert.Equal(t, err, ErrReceiptProofInvalid)
+
+	// tampered receipt
+	proof, _ := NewReceiptProof(header.Hash(), receipts, 1)
+	proof.Receipt.Fee = big.NewInt(100)
+	assert.Equal(t, VerifyReceiptProof(header, proof), ErrReceiptProofInvalid)
+
+	// proof of another block
+	proof, _ = NewReceiptProof(common.StringToHash("block"), receipts, 0)
+	assert.Equal(t, VerifyReceiptProof(header, proof), ErrReceiptProofInvalid)
+}
@@ -0,0 +1,301 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// SigHashVersion is the version of the scheme to compute the tx hash to sign.
+type SigHashVersion byte
+
+const (
+	// SigHashLegacy hashes the whole TransactionData implicitly, so that any field
+	// added to TransactionData changes the hash and invalidates the signed txs.
+	// Note, the chain id is also hashed since it is in TransactionData.
+	SigHashLegacy SigHashVersion = 0
+
+	// SigHashV1 hashes the explicit fields in a fixed order, together with the
+	// sighash version, chain id and tx type.
+	SigHashV1 SigHashVersion = 1
+
+	// SigHashV2 hashes the fields of SigHashV1 and the expiry.
+	SigHashV2 SigHashVersion = 2
+
+	// SigHashV3 hashes the fields of SigHashV2 and the fee payer.
+	SigHashV3 SigHashVersion = 3
+
+	// SigHashV4 hashes the fields of SigHashV3 and the extensions.
+	SigHashV4 SigHashVersion = 4
+
+	// LatestSigHashVersion is the latest sighash version supported.
+	LatestSigHashVersion = SigHashV4
+)
+
+var (
+	// ErrSigHashVersion is returned when the sighash version of the tx is unknown.
+	ErrSigHashVersion = errors.New("unknown sighash version")
+
+	// ErrSigHashNotActivated is returned when the sighash version of the tx is not activated at the block height.
+	ErrSigHashNotActivated = errors.New("sighash version not activated")
+
+	// ErrChainIDMismatch is returned when the tx is signed for another chain.
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+
+	// ErrExpiryNotSigned is returned when the tx expiry is not hashed in the sighash version.
+	ErrExpiryNotSigned = errors.New("expiry not signed in the sighash version")
+
+	// ErrFeePayerNotSigned is returned when the tx fee payer is not hashed in the sighash version.
+	ErrFeePayerNotSigned = errors.New("fee payer not signed in the sighash version")
+)
+
+// SigHashScheme specifies how the tx is signed.
+type SigHashScheme struct {
+	Version SigHashVersion // Version is the sighash version
+	ChainID uint64         // ChainID is the id of the chain the tx is signed for
+}
+
+// sigHashV1Data is the preimage of the SigHashV1 hash. Note, the fields and their
+// order must not be changed, any new field should be hashed in a new version instead.
+type sigHashV1Data struct {
+	Version      SigHashVersion
+	ChainID      uint64
+	Type         byte
+	From         common.Address
+	To           []byte // empty for contract creation
+	Amount       *big.Int
+	GasPrice     *big.Int
+	GasLimit     uint64
+	AccountNonce uint64
+	Timestamp    uint64
+	Payload      []byte
+}
+
+// sigHashV2Data is the preimage of the SigHashV2 hash, which appends the expiry to sigHashV1Data.
+type sigHashV2Data struct {
+	Version      SigHashVersion
+	ChainID      uint64
+	Type         byte
+	From         common.Address
+	To           []byte // empty for contract creation
+	Amount       *big.Int
+	GasPrice     *big.Int
+	GasLimit     uint64
+	AccountNonce uint64
+	Timestamp    uint64
+	Payload      []byte
+	ExpireAt     uint64
+}
+
+// sigHashV3Data is the preimage of the SigHashV3 hash, which appends the fee payer to sigHashV2Data.
+type sigHashV3Data struct {
+	Version      SigHashVersion
+	ChainID      uint64
+	Type         byte
+	From         common.Address
+	To           []byte // empty for contract creation
+	Amount       *big.Int
+	GasPrice     *big.Int
+	GasLimit     uint64
+	AccountNonce uint64
+	Timestamp    uint64
+	Payload      []byte
+	ExpireAt     uint64
+	FeePayer     []byte // empty if the sender pays the fee
+}
+
+// sigHashV4Data is the preimage of the SigHashV4 hash, which appends the extensions to sigHashV3Data.
+type sigHashV4Data struct {
+	Version      SigHashVersion
+	ChainID      uint64
+	Type         byte
+	From         common.Address
+	To           []byte // empty for contract creation
+	Amount       *big.Int
+	GasPrice     *big.Int
+	GasLimit     uint64
+	AccountNonce uint64
+	Timestamp    uint64
+	Payload      []byte
+	ExpireAt     uint64
+	FeePayer     []byte // empty if the sender pays the fee
+	Extensions   []TxExtension
+}
+
+// sigHash computes the hash of the specified tx data to sign in the version.
+func (version SigHashVersion) sigHash(data *TransactionData) (common.Hash, error) {
+	switch version {
+	case SigHashLegacy:
+		return crypto.MustHash(data), nil
+	case SigHashV1:
+		// otherwise, the expiry could be changed without invalidating the signature
+		if data.ExpireAt != 0 {
+			return common.EmptyHash, ErrExpiryNotSigned
+		}
+
+		if data.FeePayer != nil {
+			return common.EmptyHash, ErrFeePayerNotSigned
+		}
+
+		if len(data.Extensions) > 0 {
+			return common.EmptyHash, ErrExtensionsNotSigned
+		}
+
+		preimage := &sigHashV1Data{
+			Version:      version,
+			ChainID:      data.ChainID,
+			Type:         byte(data.Type),
+			From:         data.From,
+			To:           toBytes(data.To),
+			Amount:       data.Amount,
+			GasPrice:     data.GasPrice,
+			GasLimit:     data.GasLimit,
+			AccountNonce: data.AccountNonce,
+			Timestamp:    data.Timestamp,
+			Payload:      data.Payload,
+		}
+
+		return crypto.MustHash(preimage), nil
+	case SigHashV2:
+		if data.FeePayer != nil {
+			return common.EmptyHash, ErrFeePayerNotSigned
+		}
+
+		if len(data.Extensions) > 0 {
+			return common.EmptyHash, ErrExtensionsNotSigned
+		}
+
+		preimage := &sigHashV2Data{
+			Version:      version,
+			ChainID:      data.ChainID,
+			Type:         byte(data.Type),
+			From:         data.From,
+			To:           toBytes(data.To),
+			Amount:       data.Amount,
+			GasPrice:     data.GasPrice,
+			GasLimit:     data.GasLimit,
+			AccountNonce: data.AccountNonce,
+			Timestamp:    data.Timestamp,
+			Payload:      data.Payload,
+			ExpireAt:     data.ExpireAt,
+		}
+
+		return crypto.MustHash(preimage), nil
+	case SigHashV3:
+		if len(data.Extensions) > 0 {
+			return common.EmptyHash, ErrExtensionsNotSigned
+		}
+
+		preimage := &sigHashV3Data{
+			Version:      version,
+			ChainID:      data.ChainID,
+			Type:         byte(data.Type),
+			From:         data.From,
+			To:           toBytes(data.To),
+			Amount:       data.Amount,
+			GasPrice:     data.GasPrice,
+			GasLimit:     data.GasLimit,
+			AccountNonce: data.AccountNonce,
+			Timestamp:    data.Timestamp,
+			Payload:      data.Payload,
+			ExpireAt:     data.ExpireAt,
+			FeePayer:     toBytes(data.FeePayer),
+		}
+
+		return crypto.MustHash(preimage), nil
+	case SigHashV4:
+		preimage := &sigHashV4Data{
+			Version:      version,
+			ChainID:      data.ChainID,
+			Type:         byte(data.Type),
+			From:         data.From,
+			To:           toBytes(data.To),
+			Amount:       data.Amount,
+			GasPrice:     data.GasPrice,
+			GasLimit:     data.GasLimit,
+			AccountNonce: data.AccountNonce,
+			Timestamp:    data.Timestamp,
+			Payload:      data.Payload,
+			ExpireAt:     data.ExpireAt,
+			FeePayer:     toBytes(data.FeePayer),
+			Extensions:   data.Extensions,
+		}
+
+		return crypto.MustHash(preimage), nil
+	default:
+		return common.EmptyHash, ErrSigHashVersion
+	}
+}
+
+func toBytes(to *common.Address) []byte {
+	if to == nil {
+		return nil
+	}
+
+	return to.Bytes()
+}
+
+// SigHashFork activates the sighash version from the block height.
+type SigHashFork struct {
+	Version SigHashVersion
+	Height  uint64
+}
+
+// SigHashRules specifies the chain id and the sighash versions activated at fork heights.
+// Txs signed for other chains are rejected to prevent replay across networks.
+// Once activated, a sighash version is always accepted, so that the txs signed in the
+// previous versions are still valid after the fork.
+type SigHashRules struct {
+	ChainID uint64
+	Forks   []SigHashFork
+}
+
+// DefaultSigHashRules returns the rules with all sighash versions activated since genesis.
+func DefaultSigHashRules(chainID uint64) *SigHashRules {
+	return &SigHashRules{
+		ChainID: chainID,
+		Forks: []SigHashFork{
+			{SigHashLegacy, 0},
+			{SigHashV1, 0},
+			{SigHashV2, 0},
+			{SigHashV3, 0},
+			{SigHashV4, 0},
+		},
+	}
+}
+
+// ActivationHeight returns the block height since which the sighash version is activated.
+func (rules *SigHashRules) ActivationHeight(version SigHashVersion) (uint64, bool) {
+	for _, fork := range rules.Forks {
+		if fork.Version == version {
+			return fork.Height, true
+		}
+	}
+
+	return 0, false
+}
+
+// Validate validates the sighash scheme of the tx to be packed in the block of specified height.
+// The returned error is a *TxError which wraps the cause.
+func (rules *SigHashRules) Validate(tx *Transaction, height uint64) error {
+	if tx.SigVersion > LatestSigHashVersion {
+		return newTxError(tx.Hash, ErrSigHashVersion)
+	}
+
+	if activated, ok := rules.ActivationHeight(tx.SigVersion); !ok || height < activated {
+		return newTxError(tx.Hash, ErrSigHashNotActivated)
+	}
+
+	if tx.Data.ChainID != rules.ChainID {
+		return newTxError(tx.Hash, &ChainIDError{rules.ChainID, tx.Data.ChainID})
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_SigHash_V1(t *testing.T) {
+	privKey, from := randomAccount(t)
+	tx := newTestTx(t, 10, 1, false)
+	tx.Data.From = from
+
+	tx.SignWithScheme(privKey, SigHashScheme{SigHashV1, 3})
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+	assert.Equal(t, tx.Hash.Equal(tx.CalculateHash()), true)
+
+	// the legacy hash differs
+	legacyHash, _ := SigHashLegacy.sigHash(tx.Data)
+	assert.Equal(t, legacyHash.Equal(tx.Hash), false)
+
+	// chain id is signed
+	tx.Data.ChainID = 4
+	assert.Equal(t, tx.validateWithoutState(), ErrHashMismatch)
+
+	tx.SigVersion = LatestSigHashVersion + 1
+	assert.Equal(t, tx.validateWithoutState(), ErrSigHashVersion)
+}
+
+func Test_SigHash_V2_Expiry(t *testing.T) {
+	privKey, from := randomAccount(t)
+	tx := newTestTx(t, 10, 1, false)
+	tx.Data.From = from
+	tx.Data.ExpireAt = 100
+
+	// the expiry is not signed in V1
+	_, err := SigHashV1.sigHash(tx.Data)
+	assert.Equal(t, err, ErrExpiryNotSigned)
+
+	tx.SignWithScheme(privKey, SigHashScheme{SigHashV2, 3})
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// the expiry is signed in V2
+	tx.Data.ExpireAt = 200
+	assert.Equal(t, tx.validateWithoutState(), ErrHashMismatch)
+
+	tx.SigVersion = SigHashV1
+	assert.Equal(t, tx.validateWithoutState(), ErrExpiryNotSigned)
+}
+
+func Test_SigHash_V3_FeePayer(t *testing.T) {
+	privKey, from := randomAccount(t)
+	payerKey, payer := randomAccount(t)
+	tx := newTestTx(t, 10, 1, false)
+	tx.Data.From = from
+	tx.Data.FeePayer = &payer
+
+	// the fee payer is not signed in V2
+	_, err := SigHashV2.sigHash(tx.Data)
+	assert.Equal(t, err, ErrFeePayerNotSigned)
+
+	tx.SignWithScheme(privKey, SigHashScheme{SigHashV3, 3})
+	assert.Equal(t, tx.validateWithoutState(), ErrPayerSigMissing)
+
+	tx.SignAsFeePayer(privKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrPayerSigInvalid)
+
+	tx.SignAsFeePayer(payerKey)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// the fee payer is signed in V3
+	other := randomAddress(t)
+	tx.Data.FeePayer = &other
+	assert.Equal(t, tx.validateWithoutState(), ErrHashMismatch)
+}
+
+func Test_SigHashRules_Validate(t *testing.T) {
+	rules := &SigHashRules{
+		ChainID: 3,
+		Forks:   []SigHashFork{{SigHashLegacy, 0}, {SigHashV1, 100}},
+	}
+
+	legacyTx := newTestTx(t, 10, 1, true)
+	assert.Equal(t, errors.Is(rules.Validate(legacyTx, 1), ErrChainIDMismatch), true)
+
+	legacyTx.Data.ChainID = 3
+	assert.Equal(t, rules.Validate(legacyTx, 1), error(nil))
+	assert.Equal(t, rules.Validate(legacyTx, 100), error(nil))
+
+	tx := newTestTx(t, 10, 1, false)
+	tx.SigVersion, tx.Data.ChainID = SigHashV1, 3
+	assert.Equal(t, errors.Is(rules.Validate(tx, 99), ErrSigHashNotActivated), true)
+	assert.Equal(t, rules.Validate(tx, 100), error(nil))
+
+	tx.Data.ChainID = 4
+	assert.Equal(t, errors.Is(rules.Validate(tx, 100), ErrChainIDMismatch), true)
+
+	tx.SigVersion = LatestSigHashVersion + 1
+	assert.Equal(t, errors.Is(rules.Validate(tx, 100), ErrSigHashVersion), true)
+}
+
+func Test_SigHash_V4_Extensions(t *testing.T) {
+	privKey, from := randomAccount(t)
+	tx := newTestTx(t, 10, 1, false)
+	tx.Data.From = from
+	tx.Data.Extensions = []TxExtension{{"accessList", []byte{1, 2}}}
+
+	// the extensions are not signed in V3
+	_, err := SigHashV3.sigHash(tx.Data)
+	assert.Equal(t, err, ErrExtensionsNotSigned)
+
+	tx.SignWithScheme(privKey, SigHashScheme{SigHashV4, 3})
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// the extensions are signed in V4
+	data := *tx.Data
+	data.Extensions = []TxExtension{{"accessList", []byte{1, 3}}}
+	tx.Data = &data
+	assert.Equal(t, tx.validateWithoutState(), ErrHashMismatch)
+}
@@ -9,6 +9,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/seeleteam/go-seele/common"
@@ -17,7 +18,12 @@ import (
 )
 
 const (
-	defaultMaxPayloadSize = 32 * 1024
+	// TransferGas is the intrinsic gas charged for every transaction besides the contract execution.
+	TransferGas = 21000
+
+	// ExpireHeightLimit is the limit below which the ExpireAt of a transaction is a block height,
+	// otherwise it is a unix timestamp in seconds.
+	ExpireHeightLimit = 500000000
 )
 
 var (
@@ -27,6 +33,15 @@ var (
 	// ErrAmountNil is returned when the transation amount is nil.
 	ErrAmountNil = errors.New("amount is null")
 
+	// ErrGasPriceNil is returned when the transaction gas price is nil.
+	ErrGasPriceNil = errors.New("gas price is null")
+
+	// ErrGasPriceNegative is returned when the transaction gas price is negative.
+	ErrGasPriceNegative = errors.New("gas price is negative")
+
+	// ErrIntrinsicGas is returned when the transaction gas limit is lower than the TransferGas.
+	ErrIntrinsicGas = errors.New("gas limit is lower than the intrinsic gas")
+
 	// ErrBalanceNotEnough is returned when the account balance is not enough to transfer to another account.
 	ErrBalanceNotEnough = errors.New("balance not enough")
 
@@ -36,7 +51,7 @@ var (
 	// ErrNonceTooLow is returned when the transaction nonce is lower than the account nonce.
 	ErrNonceTooLow = errors.New("nonce too low")
 
-	// ErrPayloadOversized is returned when the payload size is larger than the MaxPayloadSize.
+	// ErrPayloadOversized is returned when the payload size is larger than the MaxPayloadSize of the chain config.
 	ErrPayloadOversized = errors.New("oversized payload")
 
 	// ErrSigInvalid is returned when the transaction signature is invalid.
@@ -45,20 +60,42 @@ var (
 	// ErrSigMissing is returned when the transaction signature is missing.
 	ErrSigMissing = errors.New("signature missing")
 
-	emptyTxRootHash = crypto.MustHash("empty transaction root hash")
+	// ErrTxExpired is returned when the transaction is expired for the block to include it.
+	ErrTxExpired = errors.New("transaction expired")
+
+	// ErrFeePayerInvalid is returned when the fee payer of the transaction is the sender.
+	ErrFeePayerInvalid = errors.New("fee payer is the sender")
+
+	// ErrPayerSigMissing is returned when the transaction with fee payer is not signed by the fee payer.
+	ErrPayerSigMissing = errors.New("fee payer signature missing")
+
+	// ErrPayerSigNotAllowed is returned when the transaction without fee payer is signed by a fee payer.
+	ErrPayerSigNotAllowed = errors.New("fee payer signature not allowed")
 
-	// MaxPayloadSize limits the payload size to prevent malicious transactions.
-	MaxPayloadSize = defaultMaxPayloadSize
+	// ErrPayerSigInvalid is returned when the fee payer signature is invalid.
+	ErrPayerSigInvalid = errors.New("fee payer signature is invalid")
+
+	// ErrPayerBalanceNotEnough is returned when the fee payer balance is not enough to pay the max fee.
+	ErrPayerBalanceNotEnough = errors.New("fee payer balance not enough")
+
+	emptyTxRootHash = crypto.MustHash("empty transaction root hash")
 )
 
 // TransactionData wraps the data in a transaction.
 type TransactionData struct {
+	Type         TxType // Type is the transaction type
+	ChainID      uint64 // ChainID is the id of the chain the transaction is signed for, to prevent replay on other chains
 	From         common.Address // From is the address of the sender
-	To           *common.Address // To is the receiver address, which is nil for contract creation transaction
+	To           *common.Address `rlp:"nil"` // To is the receiver address, which is nil for contract creation transaction
 	Amount       *big.Int // Amount is the amount to be transferred
+	GasPrice     *big.Int // GasPrice is the fee paid for each unit of gas used
+	GasLimit     uint64 // GasLimit is the maximum gas the transaction could use
 	AccountNonce uint64 // AccountNonce is the nonce of the sender account
 	Timestamp    uint64 // Timestamp is unix nano time when the transaction is created
 	Payload      []byte // Payload is the extra data of the transaction
+	ExpireAt     uint64 // ExpireAt is the block height or unix timestamp in seconds since which the transaction expires, 0 for never
+	FeePayer     *common.Address `rlp:"nil"` // FeePayer is the sponsor account charged for the fee, nil if the sender pays
+	Extensions   []TxExtension // Extensions are the optional key/value pairs in ascending key order, e.g. for access lists
 }
 
 // Transaction represents a transaction in the blockchain.
@@ -66,6 +103,26 @@ type Transaction struct {
 	Hash      common.Hash // Hash is the hash of the transaction data
 	Data      *TransactionData // Data is the transaction data
 	Signature *crypto.Signature // Signature is the signature of the transaction
+	SigVersion SigHashVersion // SigVersion is the sighash version to compute the hash to sign
+	CoSignatures []*crypto.Signature // CoSignatures are the signatures of the co-signers, e.g. the escrow auditor or other multisig signers
+	PayerSignature *crypto.Signature `rlp:"nil"` // PayerSignature is the signature of the fee payer, nil if the sender pays
+
+	hashCache   atomic.Value // hashCache is the cached txHashCache of the data, which is reset by Sign
+	senderCache atomic.Value // senderCache is the cached txSenderCache of the signature
+}
+
+// txHashCache is the hash of the transaction data computed in the sighash version.
+type txHashCache struct {
+	data    *TransactionData
+	version SigHashVersion
+	hash    common.Hash
+}
+
+// txSenderCache is the sender recovered from the signature of the hash.
+type txSenderCache struct {
+	hash      common.Hash
+	signature crypto.Signature
+	sender    common.Address
 }
 
 type stateDB interface {
@@ -75,13 +132,13 @@ type stateDB interface {
 
 // NewTransaction creates a new transaction to transfer asset.
 // The transaction data hash is also calculated.
-// panic if the amount is nil or negative.
-func NewTransaction(from, to common.Address, amount *big.Int, nonce uint64) *Transaction {
-	tx, _ := newTx(from, &to, amount, nonce, nil)
+// panic if the amount or gas price is nil or negative.
+func NewTransaction(from, to common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64) *Transaction {
+	tx, _ := newTx(TxTypeTransfer, from, &to, amount, gasPrice, gasLimit, nonce, nil)
 	return tx
 }
 
-func newTx(from common.Address, to *common.Address, amount *big.Int, nonce uint64, payload []byte) (*Transaction, error) {
+func newTx(txType TxType, from common.Address, to *common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64, payload []byte) (*Transaction, error) {
 	if amount == nil {
 		panic("Failed to create tx, amount is nil.")
 	}
@@ -90,14 +147,21 @@ func newTx(from common.Address, to *common.Address, amount *big.Int, nonce uint6
 		panic("Failed to create tx, amount is negative.")
 	}
 
-	if len(payload) > MaxPayloadSize {
-		return nil, ErrPayloadOversized
+	if gasPrice == nil {
+		panic("Failed to create tx, gas price is nil.")
+	}
+
+	if gasPrice.Sign() < 0 {
+		panic("Failed to create tx, gas price is negative.")
 	}
 
 	txData := &TransactionData{
+		Type:         txType,
 		From:         from,
 		To:           to,
 		Amount:       new(big.Int).Set(amount),
+		GasPrice:     new(big.Int).Set(gasPrice),
+		GasLimit:     gasLimit,
 		Timestamp:    uint64(time.Now().UnixNano()),
 		AccountNonce: nonce,
 	}
@@ -110,67 +174,312 @@ func newTx(from common.Address, to *common.Address, amount *big.Int, nonce uint6
 		txData.Payload = make([]byte, 0)
 	}
 
-	return &Transaction{crypto.MustHash(txData), txData, nil}, nil
+	return &Transaction{
+		Hash:         crypto.MustHash(txData),
+		Data:         txData,
+		SigVersion:   SigHashLegacy,
+		CoSignatures: make([]*crypto.Signature, 0),
+	}, nil
 }
 
-// NewContractTransaction returns a transaction to create a smart contract.
-func NewContractTransaction(from common.Address, amount *big.Int, nonce uint64, code []byte) (*Transaction, error) {
-	return newTx(from, nil, amount, nonce, code)
+// NewContractTransaction returns a transaction to create a smart contract on the chain of the specified config.
+func NewContractTransaction(config *ChainConfig, from common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64, code []byte) (*Transaction, error) {
+	if err := config.validatePayload(code); err != nil {
+		return nil, err
+	}
+
+	return newTx(TxTypeContractCreate, from, nil, amount, gasPrice, gasLimit, nonce, code)
 }
 
-// NewMessageTransaction returns a transation with the specified message.
-func NewMessageTransaction(from, to common.Address, amount *big.Int, nonce uint64, msg []byte) (*Transaction, error) {
-	return newTx(from, &to, amount, nonce, msg)
+// NewMessageTransaction returns a transation with the specified message on the chain of the specified config.
+func NewMessageTransaction(config *ChainConfig, from, to common.Address, amount, gasPrice *big.Int, gasLimit, nonce uint64, msg []byte) (*Transaction, error) {
+	if err := config.validatePayload(msg); err != nil {
+		return nil, err
+	}
+
+	return newTx(TxTypeContractCall, from, &to, amount, gasPrice, gasLimit, nonce, msg)
 }
 
-// Sign signs the transaction with the specified private key.
+// Sign signs the transaction with the specified private key in the sighash version of the tx,
+// which is SigHashLegacy by default. The chain id of the tx is always signed, which is 0 by
+// default and should be set via SignWithScheme for other chains. Panics if the sighash
+// version is unknown.
+//
+// The cached hash of the data is reset, so the data could be changed before signing, but
+// should not be changed once the tx is signed.
 func (tx *Transaction) Sign(privKey *ecdsa.PrivateKey) {
-	tx.Hash = crypto.MustHash(tx.Data)
+	if tx.hashCache.Load() != nil {
+		tx.hashCache.Store(txHashCache{})
+	}
+
+	hash, err := tx.SigVersion.sigHash(tx.Data)
+	if err != nil {
+		panic(err)
+	}
+
+	tx.Hash = hash
 	tx.Signature = crypto.NewSignature(privKey, tx.Hash.Bytes())
 }
 
-// Validate returns true if the transaction is valid, otherwise false.
-func (tx *Transaction) Validate(statedb stateDB) error {
+// SignWithScheme signs the transaction with the specified private key in the sighash scheme,
+// which binds the transaction to the chain id of the scheme.
+func (tx *Transaction) SignWithScheme(privKey *ecdsa.PrivateKey, scheme SigHashScheme) {
+	tx.SigVersion = scheme.Version
+	tx.Data.ChainID = scheme.ChainID
+	tx.Sign(privKey)
+}
+
+// Validate validates the transaction against the specified statedb and chain config.
+// The returned error is a *TxError which wraps the cause.
+func (tx *Transaction) Validate(statedb stateDB, config *ChainConfig) error {
+	if err := tx.ValidateWithoutState(config); err != nil {
+		return err
+	}
+
+	return tx.ValidateState(statedb)
+}
+
+// ValidateWithoutState validates the payload, fields and signature of the transaction against
+// the specified chain config, which is independent of the state. The returned error is a *TxError.
+func (tx *Transaction) ValidateWithoutState(config *ChainConfig) error {
+	if err := config.validateTxPayload(tx); err != nil {
+		return newTxError(tx.Hash, err)
+	}
+
+	return newTxError(tx.Hash, tx.validateWithoutState())
+}
+
+// ValidateState validates the balance and nonce of the sender in the specified statedb.
+// If the tx has a fee payer, the sender balance only covers the amount, while the
+// fee payer balance covers the max fee. The returned error is a *TxError which wraps
+// a *BalanceError or *NonceError.
+func (tx *Transaction) ValidateState(statedb stateDB) error {
+	return newTxError(tx.Hash, tx.validateState(statedb))
+}
+
+func (tx *Transaction) validateState(statedb stateDB) error {
+	cost := new(big.Int).Set(tx.Data.Amount)
+	if tx.Data.FeePayer == nil {
+		cost.Add(cost, tx.MaxFee())
+	} else if balance := statedb.GetBalance(*tx.Data.FeePayer); tx.MaxFee().Cmp(balance) > 0 {
+		return &BalanceError{*tx.Data.FeePayer, tx.MaxFee(), balance, ErrPayerBalanceNotEnough}
+	}
+
+	if balance := statedb.GetBalance(tx.Data.From); cost.Cmp(balance) > 0 {
+		return &BalanceError{tx.Data.From, cost, balance, ErrBalanceNotEnough}
+	}
+
+	if accountNonce := statedb.GetNonce(tx.Data.From); tx.Data.AccountNonce < accountNonce {
+		return &NonceError{accountNonce, tx.Data.AccountNonce}
+	}
+
+	return nil
+}
+
+// validateWithoutState validates the tx fields and signature, which is independent of the state.
+func (tx *Transaction) validateWithoutState() error {
 	if tx.Data == nil || tx.Data.Amount == nil {
 		return ErrAmountNil
 	}
 
+	if err := tx.Data.validateType(); err != nil {
+		return err
+	}
+
 	if tx.Data.Amount.Sign() < 0 {
 		return ErrAmountNegative
 	}
 
-	if balance := statedb.GetBalance(tx.Data.From); tx.Data.Amount.Cmp(balance) > 0 {
-		return ErrBalanceNotEnough
+	if tx.Data.GasPrice == nil {
+		return ErrGasPriceNil
 	}
 
-	if accountNonce := statedb.GetNonce(tx.Data.From); tx.Data.AccountNonce < accountNonce {
-		return ErrNonceTooLow
+	if tx.Data.GasPrice.Sign() < 0 {
+		return ErrGasPriceNegative
+	}
+
+	if tx.Data.GasLimit < TransferGas {
+		return ErrIntrinsicGas
 	}
 
-	if len(tx.Data.Payload) > MaxPayloadSize {
-		return ErrPayloadOversized
+	if err := tx.Data.validateExtensions(); err != nil {
+		return err
 	}
 
 	if tx.Signature == nil {
 		return ErrSigMissing
 	}
 
-	txDataHash := crypto.MustHash(tx.Data)
+	// the hash is always computed, and the cache is refreshed for the merkle root of the block txs
+	txDataHash, err := tx.SigVersion.sigHash(tx.Data)
+	if err != nil {
+		return err
+	}
+
+	tx.hashCache.Store(txHashCache{tx.Data, tx.SigVersion, txDataHash})
+
 	if !txDataHash.Equal(tx.Hash) {
 		return ErrHashMismatch
 	}
 
-	if !tx.Signature.Verify(&tx.Data.From, txDataHash.Bytes()) {
+	if err := tx.verifyFeePayer(txDataHash); err != nil {
+		return err
+	}
+
+	switch tx.Data.Type {
+	case TxTypeEscrowRelease:
+		return tx.verifyEscrowRelease(txDataHash)
+	case TxTypeMultisig:
+		return tx.verifyMultisig(txDataHash)
+	case TxTypeHTLC:
+		return tx.verifyHTLC(txDataHash)
+	}
+
+	if len(tx.CoSignatures) > 0 {
+		return ErrCoSigNotAllowed
+	}
+
+	// the From field is not trusted, but should be the sender recovered from the signature
+	if sender, err := tx.recoverSender(txDataHash); err != nil || !sender.Equal(tx.Data.From) {
 		return ErrSigInvalid
 	}
 
 	return nil
 }
 
+// Sender returns the sender of the transaction recovered from the signature, which is the
+// operator for the escrow release tx, the first signer for the multisig tx, or the receiver
+// of the HTLC tx, i.e. the HTLC receiver for claim or sender for refund. The recovered
+// sender is cached.
+func (tx *Transaction) Sender() (common.Address, error) {
+	if tx.Signature == nil {
+		return common.Address{}, ErrSigMissing
+	}
+
+	hash, err := tx.dataHash()
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	return tx.recoverSender(hash)
+}
+
+// recoverSender recovers the sender from the signature of the specified hash, or returns
+// the cached sender if the hash and signature are not changed.
+func (tx *Transaction) recoverSender(hash common.Hash) (common.Address, error) {
+	if cached, ok := tx.senderCache.Load().(txSenderCache); ok && cached.hash == hash && cached.signature.Equal(tx.Signature) {
+		return cached.sender, nil
+	}
+
+	sender, err := tx.Signature.RecoverAddress(hash.Bytes())
+	if err != nil {
+		return common.Address{}, ErrSigInvalid
+	}
+
+	tx.senderCache.Store(txSenderCache{hash, tx.Signature.Copy(), sender})
+	return sender, nil
+}
+
+// verifyFeePayer verifies the fee payer signature if the tx has a fee payer.
+func (tx *Transaction) verifyFeePayer(hash common.Hash) error {
+	if tx.Data.FeePayer == nil {
+		if tx.PayerSignature != nil {
+			return ErrPayerSigNotAllowed
+		}
+
+		return nil
+	}
+
+	if tx.Data.FeePayer.Equal(tx.Data.From) {
+		return ErrFeePayerInvalid
+	}
+
+	if tx.PayerSignature == nil {
+		return ErrPayerSigMissing
+	}
+
+	if !tx.PayerSignature.Verify(tx.Data.FeePayer, hash.Bytes()) {
+		return ErrPayerSigInvalid
+	}
+
+	return nil
+}
+
+// SignAsFeePayer signs the hash of the transaction with the private key of the fee payer, so the
+// fee payer should be set and the transaction should be signed by the sender before.
+func (tx *Transaction) SignAsFeePayer(privKey *ecdsa.PrivateKey) {
+	tx.PayerSignature = crypto.NewSignature(privKey, tx.Hash.Bytes())
+}
+
+// Payer returns the account charged for the fee of the transaction, which is the
+// fee payer if specified, otherwise the sender.
+func (data *TransactionData) Payer() common.Address {
+	if data.FeePayer != nil {
+		return *data.FeePayer
+	}
+
+	return data.From
+}
+
+// IsExpired indicates whether the transaction is expired for the block of the specified height
+// and timestamp, i.e. it could not be included in the block. The ExpireAt below ExpireHeightLimit
+// is compared with the block height, otherwise with the timestamp, which is the median time past
+// of the parent block for the blocks so that a single miner could not skew it.
+func (tx *Transaction) IsExpired(height, timestamp uint64) bool {
+	expireAt := tx.Data.ExpireAt
+	if expireAt == 0 {
+		return false
+	}
+
+	if expireAt < ExpireHeightLimit {
+		return height >= expireAt
+	}
+
+	return timestamp >= expireAt
+}
+
+// Size returns the size of the transaction in the RLP encoding.
+func (tx *Transaction) Size() int {
+	return len(common.SerializePanic(tx))
+}
+
+// MaxFee returns the maximum fee of the transaction, which is GasPrice * GasLimit.
+func (tx *Transaction) MaxFee() *big.Int {
+	return new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(tx.Data.GasLimit))
+}
+
+// MinFee returns the minimum fee the transaction certainly pays, which is GasPrice * TransferGas,
+// since the intrinsic gas is charged regardless of the gas limit.
+func (tx *Transaction) MinFee() *big.Int {
+	return new(big.Int).Mul(tx.Data.GasPrice, new(big.Int).SetUint64(TransferGas))
+}
+
 // CalculateHash calculates and returns the transaction hash.
 // This is to implement the merkle.Content interface.
 func (tx *Transaction) CalculateHash() common.Hash {
-	return crypto.MustHash(tx.Data)
+	hash, err := tx.dataHash()
+	if err != nil {
+		return common.EmptyHash
+	}
+
+	return hash
+}
+
+// dataHash returns the hash of the data in the sighash version of the tx, which is cached
+// once computed or validated, so the merkle root of the block txs is computed without
+// serializing the txs again.
+func (tx *Transaction) dataHash() (common.Hash, error) {
+	if cached, ok := tx.hashCache.Load().(txHashCache); ok && cached.data == tx.Data && cached.version == tx.SigVersion {
+		return cached.hash, nil
+	}
+
+	hash, err := tx.SigVersion.sigHash(tx.Data)
+	if err != nil {
+		return common.EmptyHash, err
+	}
+
+	tx.hashCache.Store(txHashCache{tx.Data, tx.SigVersion, hash})
+	return hash, nil
 }
 
 // Equals indicates if the transaction is equal to the specified content.
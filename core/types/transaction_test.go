@@ -7,6 +7,8 @@ package types
 
 import (
 	"crypto/ecdsa"
+	"errors"
+	"math"
 	"math/big"
 	"testing"
 
@@ -35,7 +37,7 @@ func newTestTx(t *testing.T, amount int64, nonce uint64, sign bool) *Transaction
 	fromPrivKey, fromAddress := randomAccount(t)
 	toAddress := randomAddress(t)
 
-	tx := NewTransaction(fromAddress, toAddress, big.NewInt(amount), nonce)
+	tx := NewTransaction(fromAddress, toAddress, big.NewInt(amount), big.NewInt(1), TransferGas, nonce)
 
 	if sign {
 		tx.Sign(fromPrivKey)
@@ -75,35 +77,59 @@ func newTestStateDB(address common.Address, nonce, balance uint64) stateDB {
 // Validate successfully if no data changed.
 func Test_Transaction_Validate_NoDataChange(t *testing.T) {
 	tx := newTestTx(t, 100, 38, true)
-	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
 	assert.Equal(t, err, error(nil))
 }
 
 // Validate failed if transaction not signed.
 func Test_Transaction_Validate_NotSigned(t *testing.T) {
 	tx := newTestTx(t, 100, 38, false)
-	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
-	assert.Equal(t, err, ErrSigMissing)
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrSigMissing), true)
 }
 
 // Validate failed if transaction Hash value changed.
 func Test_Transaction_Validate_HashChanged(t *testing.T) {
 	tx := newTestTx(t, 100, 38, true)
 	tx.Hash = crypto.HashBytes([]byte("test"))
-	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
-	assert.Equal(t, err, ErrHashMismatch)
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrHashMismatch), true)
 }
 
 // Validate failed if transaction data changed.
 func Test_Transaction_Validate_TxDataChanged(t *testing.T) {
 	tx := newTestTx(t, 100, 38, true)
 	tx.Data.Amount.SetInt64(200)
-	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
-	assert.Equal(t, err, ErrHashMismatch)
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrHashMismatch), true)
+}
+
+func Test_Transaction_HashCache(t *testing.T) {
+	privKey, from := randomAccount(t)
+	tx := NewTransaction(from, from, big.NewInt(100), big.NewInt(1), TransferGas, 38)
+	tx.Sign(privKey)
+	assert.Equal(t, tx.CalculateHash(), tx.Hash)
+
+	// validated with the hash computed again
+	tx.Data.Amount.SetInt64(200)
+	assert.Equal(t, tx.validateWithoutState(), ErrHashMismatch)
+	assert.Equal(t, tx.CalculateHash() == tx.Hash, false)
+
+	// reset once signed
+	tx.SigVersion = SigHashV1
+	tx.Sign(privKey)
+	assert.Equal(t, tx.CalculateHash(), tx.Hash)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// the data is replaced
+	data := *tx.Data
+	data.AccountNonce++
+	tx.Data = &data
+	assert.Equal(t, tx.CalculateHash() == tx.Hash, false)
 }
 
 // Validate failed if transaction data changed along with Hash updated.
@@ -114,10 +140,36 @@ func Test_Transaction_Validate_SignInvalid(t *testing.T) {
 	tx.Data.Amount.SetInt64(200)
 	tx.Hash = crypto.MustHash(tx.Data)
 
-	statedb := newTestStateDB(tx.Data.From, 38, 200)
-	err := tx.Validate(statedb)
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
+
+	assert.Equal(t, errors.Is(err, ErrSigInvalid), true)
+}
+
+func Test_Transaction_Sender(t *testing.T) {
+	tx := newTestTx(t, 100, 38, false)
+	_, err := tx.Sender()
+	assert.Equal(t, err, ErrSigMissing)
+
+	fromPrivKey, fromAddress := randomAccount(t)
+	tx.Data.From = fromAddress
+	tx.Sign(fromPrivKey)
 
-	assert.Equal(t, err, ErrSigInvalid)
+	sender, err := tx.Sender()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sender, fromAddress)
+
+	// Sender is recovered from the signature instead of the forged From.
+	data := *tx.Data
+	data.From = randomAddress(t)
+	tx.Data = &data
+	tx.Hash = tx.CalculateHash()
+	sender, _ = tx.Sender()
+	assert.Equal(t, sender == tx.Data.From, false)
+
+	statedb := newTestStateDB(tx.Data.From, 38, 100+TransferGas)
+	err = tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrSigInvalid), true)
 }
 
 func Test_MerkleRootHash_Empty(t *testing.T) {
@@ -128,15 +180,58 @@ func Test_MerkleRootHash_Empty(t *testing.T) {
 func Test_Transaction_Validate_BalanceNotEnough(t *testing.T) {
 	tx := newTestTx(t, 100, 38, true)
 	statedb := newTestStateDB(tx.Data.From, 38, 50)
-	err := tx.Validate(statedb)
-	assert.Equal(t, err, ErrBalanceNotEnough)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrBalanceNotEnough), true)
+}
+
+func Test_Transaction_Validate_FeeNotEnough(t *testing.T) {
+	tx := newTestTx(t, 100, 38, true)
+	statedb := newTestStateDB(tx.Data.From, 38, 100+TransferGas-1)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrBalanceNotEnough), true)
+}
+
+func Test_Transaction_Validate_FeePayer(t *testing.T) {
+	privKey, from := randomAccount(t)
+	payerKey, payer := randomAccount(t)
+	tx := newTestTx(t, 100, 38, false)
+	tx.Data.From = from
+	tx.Data.FeePayer = &payer
+	tx.SigVersion = SigHashV3
+	tx.Sign(privKey)
+	tx.SignAsFeePayer(payerKey)
+
+	// the sender balance only covers the amount
+	statedb := &mockStateDB{
+		balances: map[common.Address]*big.Int{tx.Data.From: big.NewInt(100), payer: big.NewInt(TransferGas)},
+		nonces:   map[common.Address]uint64{tx.Data.From: 38},
+	}
+	assert.Equal(t, tx.Validate(statedb, DefaultChainConfig()), error(nil))
+	assert.Equal(t, tx.Data.Payer(), payer)
+
+	statedb.balances[payer] = big.NewInt(TransferGas - 1)
+	assert.Equal(t, errors.Is(tx.Validate(statedb, DefaultChainConfig()), ErrPayerBalanceNotEnough), true)
+
+	// signed by a fee payer without specified
+	tx.Data.FeePayer = nil
+	tx.Sign(privKey)
+	tx.SignAsFeePayer(payerKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrPayerSigNotAllowed)
+}
+
+func Test_Transaction_Validate_IntrinsicGas(t *testing.T) {
+	tx := newTestTx(t, 100, 38, true)
+	tx.Data.GasLimit = TransferGas - 1
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrIntrinsicGas), true)
 }
 
 func Test_Transaction_Validate_NonceTooLow(t *testing.T) {
 	tx := newTestTx(t, 100, 38, true)
-	statedb := newTestStateDB(tx.Data.From, 40, 200)
-	err := tx.Validate(statedb)
-	assert.Equal(t, err, ErrNonceTooLow)
+	statedb := newTestStateDB(tx.Data.From, 40, 200+TransferGas)
+	err := tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrNonceTooLow), true)
 }
 
 func Test_Transaction_Validate_PayloadOversized(t *testing.T) {
@@ -144,16 +239,40 @@ func Test_Transaction_Validate_PayloadOversized(t *testing.T) {
 	to := crypto.MustGenerateRandomAddress()
 
 	// Cannot create a tx with oversized payload.
-	tx, err := NewMessageTransaction(*from, *to, big.NewInt(100), 38, make([]byte, MaxPayloadSize+1))
-	assert.Equal(t, err, ErrPayloadOversized)
+	tx, err := NewMessageTransaction(DefaultChainConfig(), *from, *to, big.NewInt(100), big.NewInt(1), TransferGas, 38, make([]byte, DefaultMaxPayloadSize+1))
+	assert.Equal(t, errors.Is(err, ErrPayloadOversized), true)
 
 	// Create a tx with valid payload
-	tx, err = NewMessageTransaction(*from, *to, big.NewInt(100), 38, []byte("hello"))
+	tx, err = NewMessageTransaction(DefaultChainConfig(), *from, *to, big.NewInt(100), big.NewInt(1), TransferGas, 38, []byte("hello"))
 	assert.Equal(t, err, error(nil))
-	tx.Data.Payload = make([]byte, MaxPayloadSize+1) // modify the payload to invalid size.
+	tx.Data.Payload = make([]byte, DefaultMaxPayloadSize+1) // modify the payload to invalid size.
+
+	statedb := newTestStateDB(tx.Data.From, 38, 200+TransferGas)
+
+	err = tx.Validate(statedb, DefaultChainConfig())
+	assert.Equal(t, errors.Is(err, ErrPayloadOversized), true)
+
+	// the max payload size is specified by the chain config
+	config := &ChainConfig{MaxPayloadSize: 4}
+	_, err = NewMessageTransaction(config, *from, *to, big.NewInt(100), big.NewInt(1), TransferGas, 38, []byte("hello"))
+	assert.Equal(t, errors.Is(err, ErrPayloadOversized), true)
+
+	tx.Data.Payload = []byte("hello")
+	assert.Equal(t, errors.Is(tx.Validate(statedb, config), ErrPayloadOversized), true)
+	assert.Equal(t, errors.Is(BatchValidate([]*Transaction{tx}, nil, config), ErrPayloadOversized), true)
+}
+
+func Test_Transaction_IsExpired(t *testing.T) {
+	tx := newTestTx(t, 10, 1, true)
+	assert.Equal(t, tx.IsExpired(math.MaxUint64, math.MaxUint64), false)
 
-	statedb := newTestStateDB(tx.Data.From, 38, 200)
+	// expire at block height
+	tx.Data.ExpireAt = 10
+	assert.Equal(t, tx.IsExpired(9, math.MaxUint64), false)
+	assert.Equal(t, tx.IsExpired(10, 0), true)
 
-	err = tx.Validate(statedb)
-	assert.Equal(t, err, ErrPayloadOversized)
+	// expire at timestamp
+	tx.Data.ExpireAt = ExpireHeightLimit + 10
+	assert.Equal(t, tx.IsExpired(math.MaxUint64, ExpireHeightLimit+9), false)
+	assert.Equal(t, tx.IsExpired(0, ExpireHeightLimit+10), true)
 }
@@ -0,0 +1,159 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+// TxType is the type of the transaction.
+type TxType byte
+
+const (
+	// TxTypeTransfer transfers the amount to the receiver account without payload.
+	TxTypeTransfer TxType = iota
+
+	// TxTypeContractCreate creates a contract with the code in payload, and the receiver is nil.
+	TxTypeContractCreate
+
+	// TxTypeContractCall calls the receiver contract with the input in payload.
+	TxTypeContractCall
+
+	// TxTypeReward rewards the block creator, which is only the first tx of each block.
+	TxTypeReward
+
+	// TxTypeEscrowRelease transfers the amount from the escrow account in payload to the receiver.
+	TxTypeEscrowRelease
+
+	// TxTypeMultisig transfers the amount from the multisig account in payload to the receiver.
+	TxTypeMultisig
+
+	// TxTypeHTLC claims or refunds the amount from the HTLC in payload to the receiver.
+	TxTypeHTLC
+)
+
+var (
+	// ErrTxTypeUnknown is returned when the transaction type is unknown.
+	ErrTxTypeUnknown = errors.New("unknown transaction type")
+
+	// ErrTxTypeMalformed is returned when the transaction fields mismatch the transaction type.
+	ErrTxTypeMalformed = errors.New("transaction fields mismatch the type")
+
+	// ErrRewardTxNotAllowed is returned when a reward transaction is not the first one of a block,
+	// e.g. submitted to the tx pool.
+	ErrRewardTxNotAllowed = errors.New("reward transaction only allowed as the first one of a block")
+)
+
+var txTypeNames = map[TxType]string{
+	TxTypeTransfer:       "transfer",
+	TxTypeContractCreate: "contractCreate",
+	TxTypeContractCall:   "contractCall",
+	TxTypeReward:         "reward",
+	TxTypeEscrowRelease:  "escrowRelease",
+	TxTypeMultisig:       "multisig",
+	TxTypeHTLC:           "htlc",
+}
+
+// String implements the fmt.Stringer interface.
+func (t TxType) String() string {
+	if name, ok := txTypeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("unknown(%d)", byte(t))
+}
+
+// NewRewardTransaction creates a new transaction to reward the block creator,
+// which has no sender, gas price or gas limit. The extra data, e.g. the encoded
+// PoolAccounting, is committed in the payload.
+func NewRewardTransaction(creator common.Address, amount *big.Int, extra []byte) *Transaction {
+	tx, _ := newTx(TxTypeReward, common.Address{}, &creator, amount, big.NewInt(0), 0, 0, extra)
+	return tx
+}
+
+// validateType validates the fields of a non-reward transaction against its type.
+func (data *TransactionData) validateType() error {
+	switch data.Type {
+	case TxTypeTransfer:
+		if data.To == nil || len(data.Payload) > 0 {
+			return ErrTxTypeMalformed
+		}
+	case TxTypeContractCreate:
+		if data.To != nil || len(data.Payload) == 0 {
+			return ErrTxTypeMalformed
+		}
+	case TxTypeContractCall:
+		if data.To == nil {
+			return ErrTxTypeMalformed
+		}
+	case TxTypeEscrowRelease:
+		if data.To == nil {
+			return ErrTxTypeMalformed
+		}
+
+		if account, err := data.escrowAccount(); err != nil || !data.From.Equal(account.Address()) {
+			return ErrTxTypeMalformed
+		}
+	case TxTypeMultisig:
+		if data.To == nil {
+			return ErrTxTypeMalformed
+		}
+
+		if account, err := data.multisigAccount(); err != nil || !data.From.Equal(account.Address()) {
+			return ErrTxTypeMalformed
+		}
+	case TxTypeHTLC:
+		if _, err := data.htlcUnlock(); err != nil {
+			return err
+		}
+	case TxTypeReward:
+		return ErrRewardTxNotAllowed
+	default:
+		return ErrTxTypeUnknown
+	}
+
+	return nil
+}
+
+// CallInput returns the input to call the receiver, which is nil for the escrow release,
+// multisig and HTLC txs since their payloads are the accounts instead.
+func (data *TransactionData) CallInput() []byte {
+	if data.Type == TxTypeEscrowRelease || data.Type == TxTypeMultisig || data.Type == TxTypeHTLC {
+		return nil
+	}
+
+	return data.Payload
+}
+
+// ValidateReward validates the fields of the reward transaction, which is the first
+// transaction of a block. The receiver and amount are validated against the block.
+// The payload is the extra data of at most MaxRewardExtraSize bytes, and the coinbase extra
+// data in the extensions is at most MaxCoinbaseExtraSize bytes.
+func (tx *Transaction) ValidateReward() error {
+	if tx.Data == nil || tx.Data.Type != TxTypeReward {
+		return ErrTxTypeMalformed
+	}
+
+	data := tx.Data
+	if !data.From.Equal(common.Address{}) || data.To == nil || len(data.Payload) > MaxRewardExtraSize ||
+		(data.GasPrice != nil && data.GasPrice.Sign() != 0) || data.GasLimit != 0 {
+		return ErrTxTypeMalformed
+	}
+
+	if err := data.validateExtensions(); err != nil {
+		return err
+	}
+
+	if len(tx.CoinbaseExtra()) > MaxCoinbaseExtraSize {
+		return ErrCoinbaseExtraOversized
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/**
+* @file
+* @copyright defined in go-seele/LICENSE
+ */
+
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_TxType_Validate(t *testing.T) {
+	privKey, from := randomAccount(t)
+	to := randomAddress(t)
+
+	// transfer with payload
+	tx := newTestTx(t, 10, 1, false)
+	tx.Data.From = from
+	tx.Data.Payload = []byte("msg")
+	tx.Sign(privKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrTxTypeMalformed)
+
+	// contract creation
+	tx, _ = NewContractTransaction(DefaultChainConfig(), from, big.NewInt(0), big.NewInt(1), TransferGas, 1, []byte("code"))
+	tx.Sign(privKey)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	tx.Data.To = &to
+	tx.Sign(privKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrTxTypeMalformed)
+
+	// contract call
+	tx, _ = NewMessageTransaction(DefaultChainConfig(), from, to, big.NewInt(0), big.NewInt(1), TransferGas, 1, []byte("input"))
+	tx.Sign(privKey)
+	assert.Equal(t, tx.validateWithoutState(), error(nil))
+
+	// unknown type
+	tx.Data.Type = TxTypeHTLC + 1
+	tx.Sign(privKey)
+	assert.Equal(t, tx.validateWithoutState(), ErrTxTypeUnknown)
+}
+
+func Test_TxType_Reward(t *testing.T) {
+	creator := randomAddress(t)
+	tx := NewRewardTransaction(creator, big.NewInt(100), nil)
+	assert.Equal(t, tx.Data.Type, TxTypeReward)
+	assert.Equal(t, tx.ValidateReward(), error(nil))
+
+	// reward tx only allowed as the first tx of block
+	assert.Equal(t, tx.validateWithoutState(), ErrRewardTxNotAllowed)
+
+	tx.Data.GasLimit = TransferGas
+	assert.Equal(t, tx.ValidateReward(), ErrTxTypeMalformed)
+
+	assert.Equal(t, newTestTx(t, 10, 1, true).ValidateReward(), ErrTxTypeMalformed)
+}
+
+func Test_TxType_Reward_CoinbaseExtra(t *testing.T) {
+	tx := NewRewardTransaction(randomAddress(t), big.NewInt(100), nil)
+	hash := tx.Hash
+
+	assert.Equal(t, tx.SetCoinbaseExtra([]byte("pool/v1")), error(nil))
+	assert.Equal(t, tx.CoinbaseExtra(), []byte("pool/v1"))
+	assert.Equal(t, tx.Hash != hash, true)
+	assert.Equal(t, tx.CalculateHash(), tx.Hash)
+	assert.Equal(t, tx.ValidateReward(), error(nil))
+
+	assert.Equal(t, tx.SetCoinbaseExtra(make([]byte, MaxCoinbaseExtraSize+1)), ErrCoinbaseExtraOversized)
+
+	// consensus limit of the extra data set by others
+	tx.Data.Extensions = []TxExtension{{CoinbaseExtraKey, make([]byte, MaxCoinbaseExtraSize+1)}}
+	assert.Equal(t, tx.ValidateReward(), ErrCoinbaseExtraOversized)
+
+	// removed if empty
+	assert.Equal(t, tx.SetCoinbaseExtra(nil), error(nil))
+	assert.Equal(t, tx.CoinbaseExtra() == nil, true)
+	assert.Equal(t, tx.Hash, hash)
+}
@@ -0,0 +1,172 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package vectors
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+type namedTx struct {
+	name string
+	key  int // key is the index of the sender key in fixedKeys
+	tx   *types.Transaction
+}
+
+type namedHeader struct {
+	name   string
+	header *types.BlockHeader
+}
+
+type namedReceipt struct {
+	name    string
+	receipt *types.Receipt
+}
+
+// txCorpus returns the txs of each type and sighash version, signed by the fixed keys.
+func txCorpus(keys []*ecdsa.PrivateKey) ([]*namedTx, error) {
+	from, payer := *crypto.MustGetAddress(keys[0]), *crypto.MustGetAddress(keys[1])
+	to := common.BytesToAddress([]byte{0x0a, 0x0b, 0x0c})
+	config := types.DefaultChainConfig()
+
+	transfer := types.NewTransaction(from, to, big.NewInt(100), big.NewInt(1), types.TransferGas, 0)
+
+	create, err := types.NewContractTransaction(config, from, big.NewInt(0), big.NewInt(2), 100000, 1, []byte{0x60, 0x80, 0x60, 0x40, 0x52})
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := types.NewMessageTransaction(config, from, to, big.NewInt(0), big.NewInt(3), 50000, 2, []byte{0xa9, 0x05, 0x9c, 0xbb})
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := types.NewTransaction(from, to, big.NewInt(1), big.NewInt(1), types.TransferGas, 3)
+	expiring.Data.ExpireAt = 1000
+
+	sponsored := types.NewTransaction(from, to, big.NewInt(1), big.NewInt(1), types.TransferGas, 4)
+	sponsored.Data.FeePayer = &payer
+
+	extended := types.NewTransaction(from, to, big.NewInt(1), big.NewInt(1), types.TransferGas, 5)
+	extended.Data.Extensions = []types.TxExtension{{Key: "memo", Value: []byte("test vector")}}
+
+	txs := []*namedTx{
+		{"transfer-legacy", 0, transfer},
+		{"contract-create-v1", 0, create},
+		{"contract-call-v1", 0, call},
+		{"transfer-expiring-v2", 0, expiring},
+		{"transfer-fee-payer-v3", 0, sponsored},
+		{"transfer-extensions-v4", 0, extended},
+	}
+
+	versions := []types.SigHashVersion{types.SigHashLegacy, types.SigHashV1, types.SigHashV1, types.SigHashV2, types.SigHashV3, types.SigHashV4}
+	for i, named := range txs {
+		named.tx.Data.Timestamp = uint64(fixedTimestamp) * 1e9
+		named.tx.SignWithScheme(keys[named.key], types.SigHashScheme{Version: versions[i], ChainID: 1})
+	}
+
+	sponsored.SignAsFeePayer(keys[1])
+
+	return txs, nil
+}
+
+// headerCorpus returns the block headers of the genesis, a block with ommers and a block of
+// a later header version with extensions.
+func headerCorpus(keys []*ecdsa.PrivateKey) []*namedHeader {
+	genesis := &types.BlockHeader{
+		Difficulty:      big.NewInt(1),
+		CreateTimestamp: big.NewInt(0),
+		Version:         types.BlockHeaderVersion,
+	}
+
+	ommer := &types.BlockHeader{
+		PreviousBlockHash: genesis.Hash(),
+		Creator:           *crypto.MustGetAddress(keys[1]),
+		StateHash:         crypto.MustHash("ommer state"),
+		TxHash:            crypto.MustHash("ommer txs"),
+		ReceiptHash:       crypto.MustHash("ommer receipts"),
+		Difficulty:        big.NewInt(10000000),
+		Height:            1,
+		CreateTimestamp:   big.NewInt(fixedTimestamp),
+		Nonce:             7,
+		Version:           types.BlockHeaderVersion,
+	}
+
+	block := &types.BlockHeader{
+		PreviousBlockHash: genesis.Hash(),
+		Creator:           *crypto.MustGetAddress(keys[0]),
+		StateHash:         crypto.MustHash("block state"),
+		TxHash:            crypto.MustHash("block txs"),
+		ReceiptHash:       crypto.MustHash("block receipts"),
+		Difficulty:        big.NewInt(10000000),
+		Height:            2,
+		CreateTimestamp:   big.NewInt(fixedTimestamp + 10),
+		Nonce:             12345,
+		Ommers:            []*types.BlockHeader{ommer},
+		Version:           types.BlockHeaderVersion,
+	}
+	block.LogsBloom.Add(common.BytesToAddress([]byte{0x0a}).Bytes())
+
+	extended := block.Clone()
+	extended.Ommers = nil
+	extended.Version = types.BlockHeaderVersion + 1
+	extended.Extensions = []rlp.RawValue{common.SerializePanic(uint64(42))}
+
+	return []*namedHeader{
+		{"header-genesis", genesis},
+		{"header-ommers", block},
+		{"header-extensions", extended},
+	}
+}
+
+// receiptCorpus returns the receipts of a transfer, a contract call with logs and a failed tx.
+func receiptCorpus(keys []*ecdsa.PrivateKey) []*namedReceipt {
+	contract := crypto.CreateAddress(*crypto.MustGetAddress(keys[0]), 1)
+
+	transfer := &types.Receipt{
+		Result:    []byte{},
+		PostState: crypto.MustHash("transfer state"),
+		Logs:      []*types.Log{},
+		TxHash:    crypto.MustHash("transfer tx"),
+		UsedGas:   types.TransferGas,
+		Fee:       big.NewInt(int64(types.TransferGas)),
+	}
+
+	call := &types.Receipt{
+		Result:    []byte{0x01},
+		PostState: crypto.MustHash("call state"),
+		Logs: []*types.Log{{
+			Address: contract,
+			Topics:  []common.Hash{crypto.MustHash("Transfer(address,address,uint256)")},
+			Data:    []byte{0x00, 0x64},
+		}},
+		TxHash:          crypto.MustHash("call tx"),
+		ContractAddress: contract,
+		UsedGas:         30000,
+		Fee:             big.NewInt(90000),
+	}
+
+	failed := &types.Receipt{
+		Result:    []byte{},
+		PostState: crypto.MustHash("failed state"),
+		Logs:      []*types.Log{},
+		TxHash:    crypto.MustHash("failed tx"),
+		UsedGas:   50000,
+		Fee:       big.NewInt(50000),
+		Failed:    true,
+	}
+
+	return []*namedReceipt{
+		{"receipt-transfer", transfer},
+		{"receipt-logs", call},
+		{"receipt-failed", failed},
+	}
+}
@@ -0,0 +1,10 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package vectors
+
+// goldenDigest is the digest of the published golden vectors, which must only be updated
+// together with types.EncodingVersion once the wire encoding is changed on purpose.
+const goldenDigest = "0x8a6a68c8833468858cf3188c45f4028a2d248935e9c41b99af9dee7af462a28d"
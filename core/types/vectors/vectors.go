@@ -0,0 +1,282 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+// Package vectors generates the golden test vectors of the wire encodings, hashes and
+// signatures of the transactions, block headers and receipts, so that the third-party
+// SDKs could verify their implementations byte by byte.
+package vectors
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+const (
+	// KindTx is the kind of the transaction vectors, which are encoded via Transaction.Encode.
+	KindTx = "tx"
+
+	// KindHeader is the kind of the block header vectors, which are encoded via BlockHeader.Encode.
+	KindHeader = "header"
+
+	// KindReceipt is the kind of the receipt vectors, which are encoded in plain RLP.
+	KindReceipt = "receipt"
+)
+
+// fixedTimestamp is the creation time of the txs and blocks in the corpus, 2018-08-01 00:00:00 UTC.
+const fixedTimestamp = 1533081600
+
+var (
+	// ErrVectorMismatch is returned when a vector mismatches the one generated by this build.
+	ErrVectorMismatch = errors.New("test vector mismatch")
+
+	// ErrVectorMissing is returned when a vector generated by this build is missing in the vectors to verify.
+	ErrVectorMissing = errors.New("test vector missing")
+
+	// fixedKeys are the private keys of the signers in the corpus, which are public and must never hold any fund.
+	fixedKeys = []string{
+		"0x0101010101010101010101010101010101010101010101010101010101010101",
+		"0x0202020202020202020202020202020202020202020202020202020202020202",
+	}
+)
+
+// Vector is a golden test vector of a transaction, block header or receipt.
+type Vector struct {
+	Name      string            `json:"name"`                // Name identifies the vector in the corpus
+	Kind      string            `json:"kind"`                // Kind is the type of the encoded object, e.g. KindTx
+	Encoding  string            `json:"encoding"`            // Encoding is the canonical binary encoding in hex
+	Hash      common.Hash       `json:"hash"`                // Hash is the tx hash to sign, the header hash or the receipt hash
+	Key       string            `json:"key,omitempty"`       // Key is the private key of the tx sender in hex
+	Sender    *common.Address   `json:"sender,omitempty"`    // Sender is the address of Key
+	Signature *crypto.Signature `json:"signature,omitempty"` // Signature is the tx signature of Hash signed by Key
+}
+
+// Vectors is the corpus of the golden test vectors.
+type Vectors struct {
+	EncodingVersion byte      `json:"encodingVersion"` // EncodingVersion is the types.EncodingVersion of the encodings
+	Vectors         []*Vector `json:"vectors"`
+}
+
+// Generate generates the corpus of the golden test vectors from the fixed keys.
+func Generate() (*Vectors, error) {
+	keys := make([]*ecdsa.PrivateKey, len(fixedKeys))
+	for i, hex := range fixedKeys {
+		key, err := crypto.LoadECDSAFromString(hex)
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i] = key
+	}
+
+	vectors := &Vectors{EncodingVersion: types.EncodingVersion}
+
+	txs, err := txCorpus(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, named := range txs {
+		encoded, err := named.tx.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		vectors.Vectors = append(vectors.Vectors, &Vector{
+			Name:      named.name,
+			Kind:      KindTx,
+			Encoding:  hexutil.BytesToHex(encoded),
+			Hash:      named.tx.Hash,
+			Key:       fixedKeys[named.key],
+			Sender:    crypto.MustGetAddress(keys[named.key]),
+			Signature: named.tx.Signature,
+		})
+	}
+
+	for _, named := range headerCorpus(keys) {
+		encoded, err := named.header.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		vectors.Vectors = append(vectors.Vectors, &Vector{
+			Name:     named.name,
+			Kind:     KindHeader,
+			Encoding: hexutil.BytesToHex(encoded),
+			Hash:     named.header.Hash(),
+		})
+	}
+
+	for _, named := range receiptCorpus(keys) {
+		encoded, err := common.Serialize(named.receipt)
+		if err != nil {
+			return nil, err
+		}
+
+		vectors.Vectors = append(vectors.Vectors, &Vector{
+			Name:     named.name,
+			Kind:     KindReceipt,
+			Encoding: hexutil.BytesToHex(encoded),
+			Hash:     named.receipt.CalculateHash(),
+		})
+	}
+
+	return vectors, nil
+}
+
+// Verify verifies the vectors against the ones generated by this build, and also checks that
+// each encoding decodes to an object of the same encoding, hash and signature.
+func (vectors *Vectors) Verify() error {
+	if vectors.EncodingVersion != types.EncodingVersion {
+		return types.ErrEncodingVersion
+	}
+
+	generated, err := Generate()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*Vector)
+	for _, v := range vectors.Vectors {
+		byName[v.Name] = v
+	}
+
+	for _, expected := range generated.Vectors {
+		v := byName[expected.Name]
+		if v == nil {
+			return fmt.Errorf("%s: %s", expected.Name, ErrVectorMissing)
+		}
+
+		if err := v.verify(); err != nil {
+			return fmt.Errorf("%s: %s", v.Name, err)
+		}
+
+		if !v.equal(expected) {
+			return fmt.Errorf("%s: %s", v.Name, ErrVectorMismatch)
+		}
+	}
+
+	return nil
+}
+
+// SelfTest verifies that the vectors generated by this build are consistent and equal to the
+// published golden vectors, which fails if the wire encoding is changed by accident.
+func SelfTest() error {
+	generated, err := Generate()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range generated.Vectors {
+		if err = v.verify(); err != nil {
+			return fmt.Errorf("%s: %s", v.Name, err)
+		}
+	}
+
+	if digest := generated.Digest().ToHex(); digest != goldenDigest {
+		return fmt.Errorf("%s: digest %s, expected %s", ErrVectorMismatch, digest, goldenDigest)
+	}
+
+	return nil
+}
+
+// Digest returns the hash of the encodings, hashes and signatures of the vectors in order.
+func (vectors *Vectors) Digest() common.Hash {
+	return crypto.MustHash(vectors)
+}
+
+// equal indicates whether the vector is byte-accurately equal to the other.
+func (v *Vector) equal(other *Vector) bool {
+	return v.Kind == other.Kind &&
+		v.Encoding == other.Encoding &&
+		v.Hash.Equal(other.Hash) &&
+		v.Key == other.Key &&
+		bytes.Equal(addressBytes(v.Sender), addressBytes(other.Sender)) &&
+		bytes.Equal(signatureBytes(v.Signature), signatureBytes(other.Signature))
+}
+
+// verify decodes the encoding and checks the hash and signature of the decoded object.
+func (v *Vector) verify() error {
+	encoded, err := hexutil.HexToBytes(v.Encoding)
+	if err != nil {
+		return err
+	}
+
+	var reencoded []byte
+	var hash common.Hash
+
+	switch v.Kind {
+	case KindTx:
+		tx := new(types.Transaction)
+		if err = tx.Decode(encoded); err != nil {
+			return err
+		}
+
+		if !bytes.Equal(signatureBytes(tx.Signature), signatureBytes(v.Signature)) {
+			return errors.New("signature mismatch")
+		}
+
+		if sender, err := tx.Sender(); err != nil || v.Sender == nil || !sender.Equal(*v.Sender) {
+			return errors.New("sender mismatch")
+		}
+
+		reencoded, err = tx.Encode()
+		hash = tx.CalculateHash()
+	case KindHeader:
+		header := new(types.BlockHeader)
+		if err = header.Decode(encoded); err != nil {
+			return err
+		}
+
+		reencoded, err = header.Encode()
+		hash = header.Hash()
+	case KindReceipt:
+		receipt := new(types.Receipt)
+		if err = common.Deserialize(encoded, receipt); err != nil {
+			return err
+		}
+
+		reencoded, err = common.Serialize(receipt)
+		hash = receipt.CalculateHash()
+	default:
+		return fmt.Errorf("unknown kind %s", v.Kind)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(reencoded, encoded) {
+		return errors.New("encoding not canonical")
+	}
+
+	if !hash.Equal(v.Hash) {
+		return errors.New("hash mismatch")
+	}
+
+	return nil
+}
+
+func addressBytes(addr *common.Address) []byte {
+	if addr == nil {
+		return nil
+	}
+
+	return addr.Bytes()
+}
+
+func signatureBytes(sig *crypto.Signature) []byte {
+	if sig == nil {
+		return nil
+	}
+
+	return common.SerializePanic(sig)
+}
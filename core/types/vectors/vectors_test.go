@@ -0,0 +1,44 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package vectors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_SelfTest(t *testing.T) {
+	assert.Equal(t, SelfTest(), error(nil))
+}
+
+func Test_Vectors_Verify(t *testing.T) {
+	generated, err := Generate()
+	assert.Equal(t, err, error(nil))
+
+	// the vectors are published in json
+	encoded, err := json.Marshal(generated)
+	assert.Equal(t, err, error(nil))
+
+	vectors := new(Vectors)
+	assert.Equal(t, json.Unmarshal(encoded, vectors), error(nil))
+	assert.Equal(t, vectors.Verify(), error(nil))
+	assert.Equal(t, vectors.Digest(), generated.Digest())
+
+	// tampered hash
+	vectors.Vectors[0].Hash[0]++
+	assert.Equal(t, vectors.Verify() != nil, true)
+	vectors.Vectors[0].Hash[0]--
+
+	// missing vector
+	vectors.Vectors = vectors.Vectors[1:]
+	assert.Equal(t, vectors.Verify() != nil, true)
+
+	vectors.EncodingVersion = types.EncodingVersion + 1
+	assert.Equal(t, vectors.Verify(), types.ErrEncodingVersion)
+}
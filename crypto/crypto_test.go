@@ -33,3 +33,43 @@ func Test_CreateAddress(t *testing.T) {
 	addr2 = CreateAddress(common.BytesToAddress([]byte{6}), 9)
 	assert.Equal(t, true, addr1.Equal(addr2))
 }
+
+func Test_Signature_RecoverAddress(t *testing.T) {
+	privKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := GetAddress(privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := MustHash("signed message")
+
+	sig := NewSignature(privKey, hash.Bytes())
+	recovered, err := sig.RecoverAddress(hash.Bytes())
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, recovered, *address)
+
+	// Recovered address mismatch if the hash changed.
+	recovered, err = sig.RecoverAddress(MustHash("another message").Bytes())
+	assert.Equal(t, err == nil && recovered == *address, false)
+
+	_, err = (&Signature{}).RecoverAddress(hash.Bytes())
+	assert.Equal(t, err, ErrSignatureInvalid)
+}
+
+func Test_Midstate(t *testing.T) {
+	prefix := make([]byte, 300)
+	for i := range prefix {
+		prefix[i] = byte(i)
+	}
+
+	midstate := NewMidstate(prefix[:100], prefix[100:])
+	assert.Equal(t, midstate.HashBytes([]byte{1}, []byte{2, 3}), HashBytes(prefix, []byte{1, 2, 3}))
+	assert.Equal(t, midstate.HashBytes(), HashBytes(prefix))
+
+	// the midstate is not changed by hashing
+	assert.Equal(t, midstate.HashBytes([]byte{1}, []byte{2, 3}), HashBytes(prefix, []byte{1, 2, 3}))
+}
@@ -37,3 +37,32 @@ func HashBytes(data ...[]byte) common.Hash {
 func MustHash(v interface{}) common.Hash {
 	return HashBytes(common.SerializePanic(v))
 }
+
+// Midstate is the hash state after the common prefix of the data is absorbed, so that the
+// hashes of the data of the same prefix are computed without absorbing the prefix again.
+type Midstate struct {
+	state sha3.ShakeHash
+}
+
+// NewMidstate returns the midstate after the specified prefix is absorbed.
+func NewMidstate(prefix ...[]byte) *Midstate {
+	state := sha3.NewKeccak256().(sha3.ShakeHash)
+	for _, b := range prefix {
+		state.Write(b)
+	}
+
+	return &Midstate{state}
+}
+
+// HashBytes returns the hash of the prefix followed by the input data, the same as
+// HashBytes(prefix, data).
+func (m *Midstate) HashBytes(data ...[]byte) common.Hash {
+	d := m.state.Clone()
+	for _, b := range data {
+		d.Write(b)
+	}
+
+	var h common.Hash
+	d.Read(h[:])
+	return h
+}
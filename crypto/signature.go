@@ -7,28 +7,56 @@ package crypto
 
 import (
 	"crypto/ecdsa"
-	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto/secp256k1"
 )
 
+// ErrSignatureInvalid is returned when the signer could not be recovered from the signature.
+var ErrSignatureInvalid = errors.New("invalid signature")
+
 // Signature is a wrapper for signed message, and is serializable.
 type Signature struct {
 	R *big.Int // Signature of elliptic curve cryptography.
 	S *big.Int // Signature of elliptic curve cryptography.
+	V byte     // V is the recovery id to recover the public key of the signer, 0 or 1.
 }
 
-// NewSignature sign the specified hash with private key and returns a signature.
+// NewSignature sign the specified 32-byte hash with private key and returns a signature.
 // Panics if failed to sign hash.
 func NewSignature(privKey *ecdsa.PrivateKey, hash []byte) *Signature {
-	r, s, err := ecdsa.Sign(rand.Reader, privKey, hash)
+	sig, err := secp256k1.Sign(hash, math.PaddedBigBytes(privKey.D, 32))
 	if err != nil {
 		panic(fmt.Errorf("Failed to sign hash, private key = %+v, hash = %v, error = %v", privKey, hash, err.Error()))
 	}
 
-	return &Signature{r, s}
+	return &Signature{new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), sig[64]}
+}
+
+// RecoverAddress recovers the address, i.e. the public key, of the signer from the
+// signature of the specified 32-byte hash.
+func (sig *Signature) RecoverAddress(hash []byte) (common.Address, error) {
+	if sig.R == nil || sig.S == nil || sig.R.BitLen() > 256 || sig.S.BitLen() > 256 {
+		return common.Address{}, ErrSignatureInvalid
+	}
+
+	compact := make([]byte, 65)
+	math.ReadBits(sig.R, compact[:32])
+	math.ReadBits(sig.S, compact[32:64])
+	compact[64] = sig.V
+
+	pubKey, err := secp256k1.RecoverPubkey(hash, compact)
+	if err != nil {
+		return common.Address{}, ErrSignatureInvalid
+	}
+
+	return common.NewAddress(pubKey[1:])
 }
 
 // Verify verifies the signature against the specified hash.
@@ -37,3 +65,56 @@ func (sig *Signature) Verify(signerAddress *common.Address, hash []byte) bool {
 	pubKey := ToECDSAPub(signerAddress.Bytes())
 	return ecdsa.Verify(pubKey, hash, sig.R, sig.S)
 }
+
+// Copy returns a deep copy of the signature.
+func (sig *Signature) Copy() Signature {
+	copied := Signature{V: sig.V}
+	if sig.R != nil {
+		copied.R = new(big.Int).Set(sig.R)
+	}
+
+	if sig.S != nil {
+		copied.S = new(big.Int).Set(sig.S)
+	}
+
+	return copied
+}
+
+// Equal indicates whether the signature is equal to the specified one.
+func (sig *Signature) Equal(other *Signature) bool {
+	return other != nil && sig.V == other.V && equalBig(sig.R, other.R) && equalBig(sig.S, other.S)
+}
+
+func equalBig(x, y *big.Int) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+
+	return x.Cmp(y) == 0
+}
+
+type signatureJSON struct {
+	R *hexutil.Big
+	S *hexutil.Big
+	V hexutil.Uint64
+}
+
+// MarshalJSON implements the json.Marshaler interface, in which R, S and V are encoded in 0x-prefixed hex.
+func (sig Signature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&signatureJSON{(*hexutil.Big)(sig.R), (*hexutil.Big)(sig.S), hexutil.Uint64(sig.V)})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (sig *Signature) UnmarshalJSON(input []byte) error {
+	var dec signatureJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+
+	if dec.V > 1 {
+		return ErrSignatureInvalid
+	}
+
+	sig.R, sig.S, sig.V = (*big.Int)(dec.R), (*big.Int)(dec.S), byte(dec.V)
+	return nil
+}
@@ -5,6 +5,18 @@
 
 package database
 
+import (
+	"errors"
+)
+
+var (
+	// ErrReadOnly is returned when writing to a read-only database, e.g. a snapshot.
+	ErrReadOnly = errors.New("database is read only")
+
+	// ErrReplicaClosed is returned when acquiring the snapshot of a closed replica.
+	ErrReplicaClosed = errors.New("replica is closed")
+)
+
 // Database interface of store
 type Database interface {
 	Close()
@@ -19,6 +31,31 @@ type Database interface {
 	NewBatch() Batch
 }
 
+// Checkpointer is implemented by the database that could copy a consistent
+// point-in-time view into a new database folder without stopping writes.
+type Checkpointer interface {
+	Checkpoint(dir string) error
+}
+
+// Snapshotter is implemented by the database that could open a consistent point-in-time
+// read-only view, which is not affected by the later writes. The returned database should
+// be closed to release the snapshot, and its writes fail with ErrReadOnly.
+type Snapshotter interface {
+	NewSnapshot() (Database, error)
+}
+
+// Compacter is implemented by the database that could compact its storage offline, which
+// discards the deleted and overwritten entries to reclaim the disk space.
+type Compacter interface {
+	Compact() error
+}
+
+// PrefixDeleter is implemented by the database that could delete all entries of which the
+// key starts with the specified prefix, and returns the number of deleted entries.
+type PrefixDeleter interface {
+	DeletePrefix(prefix []byte) (uint64, error)
+}
+
 // Batch interface of batch for database
 type Batch interface {
 	Put(key []byte, value []byte)
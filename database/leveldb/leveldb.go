@@ -10,8 +10,15 @@ import (
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// checkpointBatchSize is the number of entries written in a batch when checkpointing
+// or deleting by prefix.
+const checkpointBatchSize = 1024
+
 // LevelDB level db struct
 type LevelDB struct {
 	db *leveldb.DB
@@ -36,6 +43,17 @@ func NewLevelDB(path string) (database.Database, error) {
 	return result, nil
 }
 
+// NewMemDB news database interface of level db in memory, e.g. for the ephemeral nodes and tests,
+// whose data is lost once closed.
+func NewMemDB() database.Database {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		panic(err) // call panic, in case of the error which never happens on the memory storage.
+	}
+
+	return &LevelDB{db: db}
+}
+
 // Close don't forget to close db when not use
 func (db *LevelDB) Close() {
 	db.db.Close()
@@ -91,3 +109,77 @@ func (db *LevelDB) NewBatch() database.Batch {
 	}
 	return batch
 }
+
+// Checkpoint copies a consistent point-in-time view of the database into a new
+// database in the specified folder, while the database is still writable.
+func (db *LevelDB) Checkpoint(dir string) error {
+	snapshot, err := db.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snapshot.Release()
+
+	target, err := leveldb.OpenFile(dir, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	iter := snapshot.NewIterator(nil, nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Put(iter.Key(), iter.Value())
+
+		if batch.Len() >= checkpointBatchSize {
+			if err = target.Write(batch, nil); err != nil {
+				return err
+			}
+
+			batch.Reset()
+		}
+	}
+
+	if err = iter.Error(); err != nil {
+		return err
+	}
+
+	return target.Write(batch, nil)
+}
+
+// Compact compacts the whole key range of the database.
+func (db *LevelDB) Compact() error {
+	return db.db.CompactRange(util.Range{})
+}
+
+// DeletePrefix deletes all entries of which the key starts with the specified prefix.
+func (db *LevelDB) DeletePrefix(prefix []byte) (uint64, error) {
+	iter := db.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	var deleted uint64
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(iter.Key())
+
+		if batch.Len() >= checkpointBatchSize {
+			if err := db.db.Write(batch, nil); err != nil {
+				return deleted, err
+			}
+
+			deleted += uint64(batch.Len())
+			batch.Reset()
+		}
+	}
+
+	if err := iter.Error(); err != nil {
+		return deleted, err
+	}
+
+	if err := db.db.Write(batch, nil); err != nil {
+		return deleted, err
+	}
+
+	return deleted + uint64(batch.Len()), nil
+}
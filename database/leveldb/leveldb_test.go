@@ -8,6 +8,7 @@ package leveldb
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/magiconair/properties/assert"
@@ -91,6 +92,32 @@ func Test_Delete(t *testing.T) {
 	assert.Equal(t, exist, false)
 }
 
+func Test_MemDB(t *testing.T) {
+	db := NewMemDB()
+	defer db.Close()
+
+	db.PutString("1", "2")
+	value, err := db.GetString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "2")
+
+	batch := db.NewBatch()
+	batch.Put([]byte("3"), []byte("4"))
+	assert.Equal(t, batch.Commit(), nil)
+
+	exist, err := db.HasString("3")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, exist, true)
+
+	// the memory databases are isolated
+	other := NewMemDB()
+	defer other.Close()
+
+	exist, err = other.HasString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, exist, false)
+}
+
 func Test_LevelDB_Newbatch(t *testing.T) {
 	// Init levelDB
 	dir := prepareDbFolder("", "leveldbtest")
@@ -104,6 +131,56 @@ func Test_LevelDB_Newbatch(t *testing.T) {
 	}
 }
 
+func Test_Checkpoint(t *testing.T) {
+	dir := prepareDbFolder("", "leveldbtest")
+	defer os.RemoveAll(dir)
+	db := newDbInstance(dir)
+	defer db.Close()
+
+	db.PutString("1", "1")
+	db.PutString("2", "2")
+
+	checkpointDir := prepareDbFolder("", "leveldbcheckpoint")
+	defer os.RemoveAll(checkpointDir)
+
+	target := filepath.Join(checkpointDir, "db")
+	assert.Equal(t, db.(*LevelDB).Checkpoint(target), nil)
+
+	// target database must not exist
+	assert.Equal(t, db.(*LevelDB).Checkpoint(target) != nil, true)
+
+	checkpoint := newDbInstance(target)
+	defer checkpoint.Close()
+
+	value, err := checkpoint.GetString("2")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "2")
+}
+
+func Test_DeletePrefix(t *testing.T) {
+	dir := prepareDbFolder("", "leveldbtest")
+	defer os.RemoveAll(dir)
+	db := newDbInstance(dir)
+	defer db.Close()
+
+	db.PutString("a1", "1")
+	db.PutString("a2", "2")
+	db.PutString("b1", "1")
+
+	deleted, err := db.(database.PrefixDeleter).DeletePrefix([]byte("a"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, deleted, uint64(2))
+
+	exist, _ := db.HasString("a1")
+	assert.Equal(t, exist, false)
+	exist, _ = db.HasString("b1")
+	assert.Equal(t, exist, true)
+
+	assert.Equal(t, db.(database.Compacter).Compact(), nil)
+	exist, _ = db.HasString("b1")
+	assert.Equal(t, exist, true)
+}
+
 func prepareDbFolder(pathRoot string, subDir string) string {
 	dir, err := ioutil.TempDir(pathRoot, subDir)
 	if err != nil {
@@ -0,0 +1,87 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package leveldb
+
+import (
+	"github.com/seeleteam/go-seele/database"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Snapshot is a read-only point-in-time view of the level db.
+type Snapshot struct {
+	snapshot *leveldb.Snapshot
+}
+
+// NewSnapshot opens a read-only snapshot of the current state of the database,
+// which should be closed once no longer used.
+func (db *LevelDB) NewSnapshot() (database.Database, error) {
+	snapshot, err := db.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{snapshot}, nil
+}
+
+// Close releases the snapshot
+func (s *Snapshot) Close() {
+	s.snapshot.Release()
+}
+
+// GetString gets the value for the given key
+func (s *Snapshot) GetString(key string) (string, error) {
+	value, err := s.Get([]byte(key))
+
+	return string(value), err
+}
+
+// Get gets the value for the given key
+func (s *Snapshot) Get(key []byte) ([]byte, error) {
+	return s.snapshot.Get(key, nil)
+}
+
+// Put returns database.ErrReadOnly
+func (s *Snapshot) Put(key []byte, value []byte) error {
+	return database.ErrReadOnly
+}
+
+// PutString returns database.ErrReadOnly
+func (s *Snapshot) PutString(key string, value string) error {
+	return database.ErrReadOnly
+}
+
+// Has returns true if the snapshot does contain the given key.
+func (s *Snapshot) Has(key []byte) (ret bool, err error) {
+	return s.snapshot.Has(key, nil)
+}
+
+// HasString returns true if the snapshot does contain the given key.
+func (s *Snapshot) HasString(key string) (ret bool, err error) {
+	return s.Has([]byte(key))
+}
+
+// Delete returns database.ErrReadOnly
+func (s *Snapshot) Delete(key []byte) error {
+	return database.ErrReadOnly
+}
+
+// DeleteSring returns database.ErrReadOnly
+func (s *Snapshot) DeleteSring(key string) error {
+	return database.ErrReadOnly
+}
+
+// NewBatch returns a batch which fails to commit with database.ErrReadOnly
+func (s *Snapshot) NewBatch() database.Batch {
+	return readOnlyBatch{}
+}
+
+// readOnlyBatch is the batch of a snapshot, which could not be committed
+type readOnlyBatch struct{}
+
+func (readOnlyBatch) Put(key []byte, value []byte) {}
+func (readOnlyBatch) Delete(key []byte)            {}
+func (readOnlyBatch) Commit() error                { return database.ErrReadOnly }
+func (readOnlyBatch) Rollback()                    {}
@@ -0,0 +1,84 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package leveldb
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/database"
+)
+
+func Test_Snapshot(t *testing.T) {
+	dir := prepareDbFolder("", "leveldbtest")
+	defer os.RemoveAll(dir)
+	db := newDbInstance(dir)
+	defer db.Close()
+
+	db.PutString("1", "1")
+
+	snapshot, err := db.(database.Snapshotter).NewSnapshot()
+	assert.Equal(t, err, nil)
+	defer snapshot.Close()
+
+	// later writes are invisible in the snapshot
+	db.PutString("1", "2")
+	db.PutString("2", "2")
+
+	value, err := snapshot.GetString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "1")
+
+	exist, err := snapshot.HasString("2")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, exist, false)
+
+	assert.Equal(t, snapshot.PutString("3", "3"), database.ErrReadOnly)
+	assert.Equal(t, snapshot.DeleteSring("1"), database.ErrReadOnly)
+	assert.Equal(t, snapshot.NewBatch().Commit(), database.ErrReadOnly)
+}
+
+func Test_Replica(t *testing.T) {
+	dir := prepareDbFolder("", "leveldbtest")
+	defer os.RemoveAll(dir)
+	db := newDbInstance(dir)
+	defer db.Close()
+
+	db.PutString("1", "1")
+
+	replica := database.NewReplica(db, time.Hour)
+	snapshot1, release1, err := replica.Acquire()
+	assert.Equal(t, err, nil)
+
+	// shared until invalidated
+	db.PutString("1", "2")
+	snapshot2, release2, err := replica.Acquire()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, snapshot2 == snapshot1, true)
+	release2()
+
+	replica.Invalidate()
+	snapshot3, release3, err := replica.Acquire()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, snapshot3 == snapshot1, false)
+
+	// the retired snapshot is still readable until released
+	value, err := snapshot1.GetString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "1")
+	release1()
+
+	value, err = snapshot3.GetString("1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, value, "2")
+	release3()
+
+	replica.Close()
+	_, _, err = replica.Acquire()
+	assert.Equal(t, err, database.ErrReplicaClosed)
+}
@@ -0,0 +1,118 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// sharedSnapshot is a snapshot shared by the concurrent readers.
+type sharedSnapshot struct {
+	db      Database
+	created time.Time
+	refs    int  // number of readers using the snapshot
+	stale   bool // stale indicates the snapshot is replaced, and is closed once not used
+}
+
+// Replica provides the read-only snapshots of a database for the heavy analytical
+// queries, e.g. log queries over large height ranges, so that the queries read a
+// consistent view without holding the locks of the writers. The snapshot is shared
+// by the concurrent queries, and is refreshed once invalidated or older than maxAge.
+// If the database does not support snapshots, the database itself is used.
+type Replica struct {
+	db     Database
+	maxAge time.Duration
+
+	lock    sync.Mutex
+	current *sharedSnapshot // nil if not opened or invalidated
+	closed  bool
+}
+
+// NewReplica creates a replica of the specified database, of which the snapshot
+// is refreshed at most every maxAge.
+func NewReplica(db Database, maxAge time.Duration) *Replica {
+	return &Replica{
+		db:     db,
+		maxAge: maxAge,
+	}
+}
+
+// Acquire returns the current snapshot along with the function to release it,
+// which should be called once the reads are done.
+func (replica *Replica) Acquire() (Database, func(), error) {
+	snapshotter, ok := replica.db.(Snapshotter)
+	if !ok {
+		return replica.db, func() {}, nil
+	}
+
+	replica.lock.Lock()
+	defer replica.lock.Unlock()
+
+	if replica.closed {
+		return nil, nil, ErrReplicaClosed
+	}
+
+	if replica.current != nil && time.Since(replica.current.created) >= replica.maxAge {
+		replica.retire()
+	}
+
+	if replica.current == nil {
+		db, err := snapshotter.NewSnapshot()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		replica.current = &sharedSnapshot{db: db, created: time.Now()}
+	}
+
+	snapshot := replica.current
+	snapshot.refs++
+
+	var once sync.Once
+	return snapshot.db, func() { once.Do(func() { replica.release(snapshot) }) }, nil
+}
+
+// Invalidate refreshes the snapshot for the next reads, e.g. after the writes that
+// the readers should observe.
+func (replica *Replica) Invalidate() {
+	replica.lock.Lock()
+	defer replica.lock.Unlock()
+
+	replica.retire()
+}
+
+// Close closes the current snapshot once not used, and the replica could not be acquired any more.
+func (replica *Replica) Close() {
+	replica.lock.Lock()
+	defer replica.lock.Unlock()
+
+	replica.retire()
+	replica.closed = true
+}
+
+// retire replaces the current snapshot, which is closed if not used.
+func (replica *Replica) retire() {
+	if replica.current == nil {
+		return
+	}
+
+	replica.current.stale = true
+	if replica.current.refs == 0 {
+		replica.current.db.Close()
+	}
+
+	replica.current = nil
+}
+
+func (replica *Replica) release(snapshot *sharedSnapshot) {
+	replica.lock.Lock()
+	defer replica.lock.Unlock()
+
+	if snapshot.refs--; snapshot.refs == 0 && snapshot.stale {
+		snapshot.db.Close()
+	}
+}
@@ -15,11 +15,22 @@ const (
 	DownloaderFailedEvent = 2
 )
 
+// DownloaderBehindEvent is fired once the head of the sync target is fetched, with the number
+// of blocks the local chain is behind it.
+type DownloaderBehindEvent struct {
+	Blocks uint64
+}
+
 // BlockMinedEventManager is event of new mined block
 var BlockMinedEventManager = NewEventManager()
 
-// TransactionInsertedEventManager is event of new transaction inserted into txpool
-var TransactionInsertedEventManager = NewEventManager()
+// TransactionEvictedEventManager is event of transaction evicted from txpool before included,
+// e.g. for the pool limits or replacement
+var TransactionEvictedEventManager = NewEventManager()
 
 // BlockInsertedEventManager is event of new block inserted into blockchain
 var BlockInsertedEventManager = NewEventManager()
+
+// ChainReorgEventManager is event of the canonical chain reorganized, with the txs dropped
+// from the blocks reorganized out
+var ChainReorgEventManager = NewEventManager()
@@ -15,7 +15,8 @@ import (
 )
 
 var (
-	errNoContent = errors.New("Error: cannot construct tree with no content.")
+	errNoContent  = errors.New("Error: cannot construct tree with no content.")
+	errProofIndex = errors.New("Error: content index out of range.")
 )
 
 // Content represents the data that is stored and verified by the tree. A type that
@@ -199,6 +200,46 @@ func (m *MerkleTree) VerifyContent(expectedMerkleRoot []byte, content Content) b
 	return false
 }
 
+// ProofNode is a sibling hash on the path from a leaf to the root of the tree.
+type ProofNode struct {
+	Hash common.Hash // Hash is the hash of the sibling node
+	Left bool        // Left indicates whether the sibling node is the left child of the parent
+}
+
+// Proof returns the sibling hashes on the path from the leaf of the specified content index to
+// the root, so that the content could be verified against the merkle root without the tree.
+func (m *MerkleTree) Proof(index int) ([]ProofNode, error) {
+	if index < 0 || index >= len(m.Leafs) || m.Leafs[index].dup {
+		return nil, errProofIndex
+	}
+
+	var proof []ProofNode
+	for current := m.Leafs[index]; current.Parent != nil; current = current.Parent {
+		if parent := current.Parent; parent.Left == current {
+			proof = append(proof, ProofNode{parent.Right.Hash, false})
+		} else {
+			proof = append(proof, ProofNode{parent.Left.Hash, true})
+		}
+	}
+
+	return proof, nil
+}
+
+// VerifyProof indicates whether the content hash is in the tree of the specified merkle root,
+// by hashing the content hash with the sibling hashes in the proof up to the root.
+func VerifyProof(merkleRoot common.Hash, contentHash common.Hash, proof []ProofNode) bool {
+	hash := contentHash
+	for _, sibling := range proof {
+		if sibling.Left {
+			hash = crypto.HashBytes(append(sibling.Hash.Bytes(), hash.Bytes()...))
+		} else {
+			hash = crypto.HashBytes(append(hash.Bytes(), sibling.Hash.Bytes()...))
+		}
+	}
+
+	return hash.Equal(merkleRoot)
+}
+
 // String returns a string representation of the tree. Only leaf nodes are included
 // in the output.
 func (m *MerkleTree) String() string {
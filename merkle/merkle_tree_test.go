@@ -181,6 +181,30 @@ func Test_MerkleTree_VerifyContent(t *testing.T) {
 	}
 }
 
+func Test_MerkleTree_Proof(t *testing.T) {
+	for i := 0; i < len(table); i++ {
+		tree, err := NewTree(table[i].contents)
+		if err != nil {
+			t.Fatalf("error: unexpected error:  %v", err)
+		}
+		for j, content := range table[i].contents {
+			proof, err := tree.Proof(j)
+			if err != nil {
+				t.Fatalf("error: unexpected error:  %v", err)
+			}
+			if !VerifyProof(tree.MerkleRoot(), content.CalculateHash(), proof) {
+				t.Error("error: expected valid proof")
+			}
+			if VerifyProof(tree.MerkleRoot(), hash("NotInTestTable"), proof) {
+				t.Error("error: expected invalid proof")
+			}
+		}
+		if _, err := tree.Proof(len(table[i].contents) + 1); err == nil {
+			t.Error("error: expected error for index out of range")
+		}
+	}
+}
+
 func Test_MerkleTree_String(t *testing.T) {
 	for i := 0; i < len(table); i++ {
 		tree, err := NewTree(table[i].contents)
@@ -0,0 +1,20 @@
+// +build linux
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// setAffinity pins the calling OS thread to the specified cpu, which should be locked by the goroutine.
+func setAffinity(cpu int) error {
+	var set unix.CPUSet
+	set.Set(cpu)
+
+	return unix.SchedSetaffinity(0, &set)
+}
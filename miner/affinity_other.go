@@ -0,0 +1,17 @@
+// +build !linux
+
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"errors"
+)
+
+// setAffinity pins the calling OS thread to the specified cpu, which is only supported on Linux.
+func setAffinity(cpu int) error {
+	return errors.New("cpu affinity is only supported on Linux")
+}
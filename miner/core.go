@@ -20,12 +20,19 @@ import (
 // result represents the founded nonce will be set in the result block
 // abort is a channel by closing which you can stop mining
 // isNonceFound is a flag to mark nonce is found by other threads
-func StartMining(task *Task, seed uint64, min uint64, max uint64, result chan<- *Result, abort <-chan struct{}, isNonceFound *int32, log *log.SeeleLog) {
+// meter measures the hashrate of the thread, which is optional
+func StartMining(task *Task, seed uint64, min uint64, max uint64, result chan<- *Result, abort <-chan struct{}, isNonceFound *int32, meter *hashrateMeter, log *log.SeeleLog) {
 	block := task.generateBlock()
 
+	var hashes uint64
+	if meter != nil {
+		defer func() { meter.mark(hashes) }()
+	}
+
 	var nonce = seed
 	var hashInt big.Int
 	target := pow.GetMiningTarget(block.Header.Difficulty)
+	hasher := pow.NewHeaderHasher(block.Header)
 
 miner:
 	for {
@@ -39,12 +46,16 @@ miner:
 				log.Info("exist mining as nonce is found in other process")
 				break miner
 			}
-			block.Header.Nonce = nonce
-			hash := block.Header.Hash()
+			hash := hasher.Hash(nonce)
+			if hashes++; hashes == hashMarkBatch && meter != nil {
+				meter.mark(hashes)
+				hashes = 0
+			}
 			hashInt.SetBytes(hash.Bytes())
 
 			// found
 			if hashInt.Cmp(target) <= 0 {
+				block.Header.Nonce = nonce
 				block.HeaderHash = hash
 				found := &Result{
 					task:  task,
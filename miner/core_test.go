@@ -24,6 +24,7 @@ func getTask(difficult int64) *Task {
 		header: &types.BlockHeader{
 			Difficulty: big.NewInt(difficult),
 		},
+		pow: true,
 	}
 }
 
@@ -34,7 +35,7 @@ func Test_Worker(t *testing.T) {
 	abort := make(chan struct{}, 1)
 	isNonceFound := new(int32)
 
-	go StartMining(task, 0, 0, math.MaxUint64, result, abort, isNonceFound, logger)
+	go StartMining(task, 0, 0, math.MaxUint64, result, abort, isNonceFound, nil, logger)
 
 	select {
 	case found := <-result:
@@ -59,7 +60,7 @@ func Test_WorkerStop(t *testing.T) {
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
-		StartMining(task, 0, 0, math.MaxUint64, result, abort, isNonceFound, logger)
+		StartMining(task, 0, 0, math.MaxUint64, result, abort, isNonceFound, nil, logger)
 		wg.Done()
 	}()
 
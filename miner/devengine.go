@@ -0,0 +1,54 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// DevEngine instantly seals the tasks once they pack any tx, for the ephemeral dev and test
+// chains of the tiny difficulty, see DevDifficultyConfig. The tasks without tx are not sealed,
+// so that no empty block is mined, and the task is rebuilt once the first tx arrives.
+type DevEngine struct{}
+
+// DevDifficultyConfig returns the difficulty config of the dev chains, in which the difficulty
+// stays around 1 so that the blocks are sealed in a few hashes.
+func DevDifficultyConfig() *pow.DifficultyConfig {
+	return &pow.DifficultyConfig{
+		BlockPeriod:   1,
+		MinDifficulty: big.NewInt(1),
+		BoundDivisor:  2048,
+		MaxDownSteps:  99,
+	}
+}
+
+// Prepare implements Engine.
+func (engine *DevEngine) Prepare(task *Task) error {
+	return nil
+}
+
+// Seal implements Engine, which searches the nonce from 0 if the task packs any tx.
+func (engine *DevEngine) Seal(task *Task, abort <-chan struct{}) (*Result, error) {
+	// the reward tx is always packed
+	if len(task.txs) <= 1 {
+		<-abort
+		return nil, nil
+	}
+
+	for nonce := uint64(0); ; nonce++ {
+		select {
+		case <-abort:
+			return nil, nil
+		default:
+		}
+
+		if result, err := task.Result(nonce); err == nil {
+			return result, nil
+		}
+	}
+}
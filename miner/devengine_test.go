@@ -0,0 +1,79 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+func Test_DevEngine_Seal(t *testing.T) {
+	engine := &DevEngine{}
+
+	// the task without tx except the reward one is not sealed until aborted
+	task := getTask(1)
+	task.txs = []*types.Transaction{&types.Transaction{}}
+	abort := make(chan struct{})
+	done := make(chan *Result, 1)
+	go func() {
+		result, _ := engine.Seal(task, abort)
+		done <- result
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the empty task should not be sealed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(abort)
+	assert.Equal(t, <-done == nil, true)
+
+	// the task with txs is sealed at once
+	task = getTask(DevDifficultyConfig().MinDifficulty.Int64())
+	task.txs = []*types.Transaction{&types.Transaction{}, &types.Transaction{}}
+	result, err := engine.Seal(task, make(chan struct{}))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, result.task, task)
+}
+
+func Test_Miner_DevEngineRecommit(t *testing.T) {
+	alice := testutil.NewAccount()
+	chain := testutil.NewChain(t, testutil.Alloc(big.NewInt(1000000), alice))
+	defer chain.Close()
+
+	pool := core.NewTransactionPool(*core.DefaultTxPoolConfig(), chain.Blockchain())
+	defer pool.Stop()
+
+	miner := &Miner{
+		mining:       1,
+		seele:        &testBackend{chain.Blockchain(), pool, chain.Coinbase().Address},
+		recv:         make(chan *Result, 1),
+		isNonceFound: new(int32),
+		hashrate:     newHashrateMeter(),
+		log:          logger,
+	}
+	miner.SetEngine(&DevEngine{})
+	defer miner.stopWorkers()
+
+	miner.prepareNewBlock()
+	task := miner.currentTask()
+	assert.Equal(t, len(task.txs), 1)
+
+	// the empty task is rebuilt at once for the first tx
+	tx := newTestTransfer(alice, 0, 1)
+	assert.Equal(t, pool.AddTransaction(tx), error(nil))
+	miner.markRecommit(tx)
+	assert.Equal(t, len(miner.currentTask().txs), 2)
+	assert.Equal(t, atomic.LoadInt32(&miner.recommitPending), int32(0))
+}
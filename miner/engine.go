@@ -0,0 +1,143 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// Engine seals the mining tasks instead of the in-process CPU threads, e.g. with the GPU or
+// remote workers. Once a new task is prepared, the sealing of the previous task is aborted.
+type Engine interface {
+	// Prepare is called once a new task is prepared before sealing it, e.g. to upload the work to the devices.
+	Prepare(task *Task) error
+
+	// Seal searches the nonce of the task, see Task.Work, until found or the abort channel is closed.
+	// It returns the result of the nonce found via Task.Result, or nil if aborted.
+	Seal(task *Task, abort <-chan struct{}) (*Result, error)
+}
+
+// HashrateEngine is the optional interface of the Engine that measures its hashes per second.
+type HashrateEngine interface {
+	Hashrate() float64
+}
+
+// consensusEngine adapts the consensus engine of the chain to seal the tasks, which is used
+// if the chain is not of POW, e.g. PoA, and no miner engine is set.
+type consensusEngine struct {
+	engine consensus.Engine
+}
+
+// Prepare implements Engine.
+func (adapter *consensusEngine) Prepare(task *Task) error {
+	return nil
+}
+
+// Seal implements Engine.
+func (adapter *consensusEngine) Seal(task *Task, abort <-chan struct{}) (*Result, error) {
+	block, err := adapter.engine.Seal(task.generateBlock(), abort)
+	if err != nil || block == nil {
+		return nil, err
+	}
+
+	return &Result{task, block}, nil
+}
+
+// Work returns the work to find the nonce of the task, or ErrNoWork if the block is not sealed
+// with the nonce of the header hash, e.g. of the memhash POW or BFT.
+func (task *Task) Work() (*Work, error) {
+	if !task.pow {
+		return nil, ErrNoWork
+	}
+
+	return newWork(task.generateBlock())
+}
+
+// Result returns the mining result of the task with the specified nonce, or ErrNonceInvalid
+// if the nonce does not meet the target, or ErrNoWork if the block is not sealed with the
+// nonce of the header hash.
+func (task *Task) Result(nonce uint64) (*Result, error) {
+	if !task.pow {
+		return nil, ErrNoWork
+	}
+
+	block := task.generateBlock()
+	block.Header.Nonce = nonce
+	block.HeaderHash = block.Header.Hash()
+
+	if block.HeaderHash.Big().Cmp(pow.GetMiningTarget(block.Header.Difficulty)) > 0 {
+		return nil, ErrNonceInvalid
+	}
+
+	return &Result{task, block}, nil
+}
+
+// SetEngine sets the engine to seal the tasks instead of the mining threads, nil for the threads.
+// It takes effect since the next task.
+func (miner *Miner) SetEngine(engine Engine) {
+	miner.workersLock.Lock()
+	defer miner.workersLock.Unlock()
+
+	miner.engine = engine
+}
+
+// getEngine returns the engine to seal the tasks, nil for the mining threads.
+func (miner *Miner) getEngine() Engine {
+	miner.workersLock.Lock()
+	defer miner.workersLock.Unlock()
+
+	return miner.engine
+}
+
+// startEngine restarts the sealing of the engine on the specified task, in which the previous
+// one is aborted first.
+func (miner *Miner) startEngine(task *Task, engine Engine) {
+	miner.workersLock.Lock()
+	defer miner.workersLock.Unlock()
+
+	if miner.workers != nil {
+		miner.workers.stop()
+	}
+
+	group := &workerGroup{abort: make(chan struct{})}
+	miner.workers = group
+
+	group.wg.Add(1)
+	go func() {
+		defer group.wg.Done()
+
+		if err := engine.Prepare(task); err != nil {
+			miner.log.Warn("failed to prepare the task for the miner engine, %s", err)
+			return
+		}
+
+		result, err := engine.Seal(task, group.abort)
+		if err != nil {
+			miner.log.Warn("failed to seal the task by the miner engine, %s", err)
+			return
+		}
+
+		if result == nil {
+			return
+		}
+
+		select {
+		case miner.recv <- result:
+		case <-group.abort:
+		}
+	}()
+}
+
+// isPowEngine returns true if the consensus engine is of POW, whose tasks are sealed by the mining threads.
+func isPowEngine(engine consensus.Engine) bool {
+	switch engine.(type) {
+	case pow.Engine, *pow.Engine:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,105 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// testEngine searches the nonce from 0 and aborts if not found within the max attempts.
+type testEngine struct {
+	prepared    *Task
+	maxAttempts uint64
+}
+
+func (engine *testEngine) Prepare(task *Task) error {
+	engine.prepared = task
+	return nil
+}
+
+func (engine *testEngine) Seal(task *Task, abort <-chan struct{}) (*Result, error) {
+	for nonce := uint64(0); nonce < engine.maxAttempts; nonce++ {
+		select {
+		case <-abort:
+			return nil, nil
+		default:
+		}
+
+		if result, err := task.Result(nonce); err == nil {
+			return result, nil
+		}
+	}
+
+	<-abort
+	return nil, nil
+}
+
+func (engine *testEngine) Hashrate() float64 {
+	return 100
+}
+
+func Test_Task_Result(t *testing.T) {
+	task := getTask(1)
+	result, err := task.Result(0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, result.task, task)
+	assert.Equal(t, result.block.Header.Nonce, uint64(0))
+
+	work, err := task.Work()
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, work.ID, task.generateBlock().HeaderHash)
+
+	// the nonce is unlikely to meet the max difficulty
+	_, err = getTask(math.MaxInt64).Result(0)
+	assert.Equal(t, err, ErrNonceInvalid)
+}
+
+func Test_Miner_StartEngine(t *testing.T) {
+	engine := &testEngine{maxAttempts: 1000}
+	miner := &Miner{
+		recv:     make(chan *Result, 1),
+		hashrate: newHashrateMeter(),
+		threads:  2,
+		log:      logger,
+	}
+	miner.SetEngine(engine)
+	assert.Equal(t, miner.Hashrate(), float64(100))
+	assert.Equal(t, len(miner.ThreadHashrates()), 0)
+
+	task := getTask(10)
+	miner.startEngine(task, engine)
+
+	result := <-miner.recv
+	assert.Equal(t, engine.prepared, task)
+	assert.Equal(t, result.task, task)
+
+	// the sealing of the previous task is aborted
+	miner.startEngine(getTask(math.MaxInt64), engine)
+	previous := miner.workers
+	miner.startEngine(getTask(math.MaxInt64), engine)
+	_, ok := <-previous.abort
+	assert.Equal(t, ok, false)
+
+	miner.stopWorkers()
+	assert.Equal(t, miner.workers == nil, true)
+}
+
+func Test_ConsensusEngine_Seal(t *testing.T) {
+	assert.Equal(t, isPowEngine(pow.Engine{}), true)
+	assert.Equal(t, isPowEngine(pow.NewEngine(nil, nil)), true)
+
+	task := getTask(1)
+	adapter := &consensusEngine{pow.Engine{}}
+	result, err := adapter.Seal(task, make(chan struct{}))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, result.task, task)
+	assert.Equal(t, result.block.HeaderHash, result.block.Header.Hash())
+	assert.Equal(t, result.block.Header.TxHash, task.generateBlock().Header.TxHash)
+}
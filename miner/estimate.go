@@ -0,0 +1,90 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+const (
+	// feeSampleBlocks is the number of recent blocks to calculate the average fees per block.
+	feeSampleBlocks = 100
+
+	secondsPerDay = 24 * 60 * 60
+)
+
+// Earnings is the estimated mining earnings at the current network difficulty.
+type Earnings struct {
+	Hashrate     float64  `json:"hashrate"`     // Hashrate is the hashes per second used for the estimation
+	Difficulty   *big.Int `json:"difficulty"`   // Difficulty is the difficulty of the HEAD block
+	BlockReward  int64    `json:"blockReward"`  // BlockReward is the reward of the next block
+	AverageFees  *big.Int `json:"averageFees"`  // AverageFees is the average fees per block of the recent blocks
+	BlocksPerDay float64  `json:"blocksPerDay"` // BlocksPerDay is the expected number of mined blocks per day
+	CoinsPerDay  float64  `json:"coinsPerDay"`  // CoinsPerDay is the expected earnings per day
+}
+
+// EstimateEarnings estimates the mining earnings of the specified hashrate on top of the HEAD block,
+// with the block reward of the specified reward schedule.
+func EstimateEarnings(bcStore store.BlockchainStore, rewards *pow.RewardConfig, head *types.Block, hashrate float64) (*Earnings, error) {
+	fees, err := averageFees(bcStore, head)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEarnings(hashrate, head.Header.Difficulty, rewards.GetReward(head.Header.Height+1), fees), nil
+}
+
+// newEarnings calculates the earnings, in which the expected hashes to mine a
+// block equals to the difficulty, since the mining target is 2^256 / difficulty.
+func newEarnings(hashrate float64, difficulty *big.Int, reward int64, fees *big.Int) *Earnings {
+	earnings := &Earnings{
+		Hashrate:    hashrate,
+		Difficulty:  new(big.Int).Set(difficulty),
+		BlockReward: reward,
+		AverageFees: new(big.Int).Set(fees),
+	}
+
+	if difficulty.Sign() <= 0 || hashrate <= 0 {
+		return earnings
+	}
+
+	diff, _ := new(big.Float).SetInt(difficulty).Float64()
+	earnings.BlocksPerDay = hashrate * secondsPerDay / diff
+
+	coinsPerBlock, _ := new(big.Float).SetInt(new(big.Int).Add(fees, big.NewInt(reward))).Float64()
+	earnings.CoinsPerDay = earnings.BlocksPerDay * coinsPerBlock
+
+	return earnings
+}
+
+// averageFees returns the average fees per block of the recent blocks. Note, the receipts
+// are not stored, so the fee of each tx is estimated with the intrinsic gas.
+func averageFees(bcStore store.BlockchainStore, head *types.Block) (*big.Int, error) {
+	total, blocks := new(big.Int), int64(0)
+	intrinsicGas := big.NewInt(types.TransferGas)
+
+	for block := head; block.Header.Height > 0 && blocks < feeSampleBlocks; blocks++ {
+		// the first tx is the miner reward
+		for i := 1; i < len(block.Transactions); i++ {
+			total.Add(total, new(big.Int).Mul(block.Transactions[i].Data.GasPrice, intrinsicGas))
+		}
+
+		var err error
+		if block, err = bcStore.GetBlock(block.Header.PreviousBlockHash); err != nil {
+			return nil, err
+		}
+	}
+
+	if blocks == 0 {
+		return total, nil
+	}
+
+	return total.Div(total, big.NewInt(blocks)), nil
+}
@@ -0,0 +1,25 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_newEarnings(t *testing.T) {
+	// 1 block per 100 seconds
+	earnings := newEarnings(1000, big.NewInt(100000), 200, big.NewInt(10))
+	assert.Equal(t, earnings.BlocksPerDay, float64(864))
+	assert.Equal(t, earnings.CoinsPerDay, float64(864*210))
+
+	// not mining
+	earnings = newEarnings(0, big.NewInt(100000), 200, big.NewInt(10))
+	assert.Equal(t, earnings.BlocksPerDay, float64(0))
+	assert.Equal(t, earnings.CoinsPerDay, float64(0))
+}
@@ -0,0 +1,88 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// hashrateInterval is the minimum interval to refresh the measured hashrate.
+	hashrateInterval = 5 * time.Second
+
+	// hashMarkBatch is the number of hashes a mining thread calculates before marking them to the meter.
+	hashMarkBatch = 1024
+)
+
+// hashrateMeter measures the hashrate of a mining thread, or all mining threads if
+// it is the parent of the thread meters.
+type hashrateMeter struct {
+	hashes uint64         // total hashes calculated, accessed atomically
+	parent *hashrateMeter // parent aggregates the hashes of the thread meters
+
+	lock      sync.Mutex
+	lastCount uint64
+	lastTime  time.Time
+	rate      float64
+
+	threadsLock sync.Mutex
+	threads     []*hashrateMeter
+}
+
+func newHashrateMeter() *hashrateMeter {
+	return &hashrateMeter{lastTime: time.Now()}
+}
+
+// mark records the specified number of calculated hashes, which are also marked to the parent.
+func (meter *hashrateMeter) mark(n uint64) {
+	atomic.AddUint64(&meter.hashes, n)
+
+	if meter.parent != nil {
+		meter.parent.mark(n)
+	}
+}
+
+// thread returns the meter of the i-th mining thread, which is created if not exist.
+func (meter *hashrateMeter) thread(i int) *hashrateMeter {
+	meter.threadsLock.Lock()
+	defer meter.threadsLock.Unlock()
+
+	for len(meter.threads) <= i {
+		thread := newHashrateMeter()
+		thread.parent = meter
+		meter.threads = append(meter.threads, thread)
+	}
+
+	return meter.threads[i]
+}
+
+// threadRates returns the hashrates of the first n mining threads.
+func (meter *hashrateMeter) threadRates(n int) []float64 {
+	rates := make([]float64, 0, n)
+	for i := 0; i < n; i++ {
+		rates = append(rates, meter.thread(i).Rate())
+	}
+
+	return rates
+}
+
+// Rate returns the average hashes per second since the last refresh,
+// which happens at most once per hashrateInterval.
+func (meter *hashrateMeter) Rate() float64 {
+	meter.lock.Lock()
+	defer meter.lock.Unlock()
+
+	now := time.Now()
+	if elapsed := now.Sub(meter.lastTime); elapsed >= hashrateInterval {
+		count := atomic.LoadUint64(&meter.hashes)
+		meter.rate = float64(count-meter.lastCount) / elapsed.Seconds()
+		meter.lastCount, meter.lastTime = count, now
+	}
+
+	return meter.rate
+}
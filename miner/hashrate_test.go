@@ -0,0 +1,33 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_HashrateMeter_Threads(t *testing.T) {
+	meter := newHashrateMeter()
+	assert.Equal(t, meter.thread(1), meter.thread(1))
+
+	meter.thread(0).mark(100)
+	meter.thread(1).mark(300)
+	assert.Equal(t, meter.hashes, uint64(400))
+
+	// refresh the rates as if a hashrate interval elapsed
+	for _, m := range []*hashrateMeter{meter, meter.thread(0), meter.thread(1)} {
+		m.lastTime = time.Now().Add(-2 * hashrateInterval)
+	}
+
+	assert.Equal(t, meter.Rate() > 0, true)
+	rates := meter.threadRates(2)
+	assert.Equal(t, len(rates), 2)
+	assert.Equal(t, rates[0] > 0 && rates[1] > rates[0], true)
+	assert.Equal(t, len(meter.threadRates(0)), 0)
+}
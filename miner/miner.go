@@ -7,10 +7,9 @@ package miner
 
 import (
 	"errors"
-	"math"
 	"math/big"
-	"math/rand"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,6 +24,9 @@ var (
 	// ErrMinerIsRunning is returned when start miner is running
 	ErrMinerIsRunning = errors.New("miner is running")
 
+	// ErrEscrowNotCoinbase is returned when the escrow account of the pool accounting is not the coinbase.
+	ErrEscrowNotCoinbase = errors.New("escrow account is not the coinbase")
+
 	// ErrMinerIsStop is returned when stop miner is stopped
 	ErrMinerIsStop = errors.New("miner is stopped")
 
@@ -32,6 +34,10 @@ var (
 	ErrNodeIsSyncing = errors.New("can not start miner when syncing")
 )
 
+// txChanSize is the size of the channel of the new txs accepted by the pool, the later txs
+// are dropped if the miner falls behind, which are still packed on the next task.
+const txChanSize = 1024
+
 // SeeleBackend wraps all methods required for minier.
 type SeeleBackend interface {
 	TxPool() *core.TransactionPool
@@ -45,9 +51,13 @@ type Miner struct {
 	mining   int32
 	canStart int32
 
-	stopChan chan struct{}
-	current  *Task
-	recv     chan *Result
+	stopChan     chan struct{}
+	currentLock  sync.RWMutex // protects current and taskHandlers, since the task is also read by the external miners
+	current      *Task
+	taskHandlers []func(*Task) // taskHandlers are called once a new task is prepared, e.g. to notify the stratum workers
+	recv         chan *Result
+	txs          chan *types.Transaction // txs are the new txs accepted by the pool
+	txSub        *core.TxSubscription
 
 	seele SeeleBackend
 	log   *log.SeeleLog
@@ -55,8 +65,25 @@ type Miner struct {
 	isFirstDownloader int32
 
 	threads              int
+	affinity             bool // affinity pins the mining threads to the cpu cores
+	workersLock          sync.Mutex
+	workers              *workerGroup // workers are the mining threads or the engine sealing of the current task
+	engine               Engine       // engine seals the tasks instead of the mining threads if not nil
 	isFirstBlockPrepared int32
 	isNonceFound         *int32
+	hashrate             *hashrateMeter
+
+	prepareLock      sync.Mutex // prepareLock serializes the task preparation, e.g. to recommit
+	recommitInterval int64      // recommitInterval is the time.Duration to rebuild the task, accessed atomically
+	recommitPending  int32      // recommitPending is 1 if the higher-fee txs arrived, accessed atomically
+
+	syncPauseBlocks uint64 // syncPauseBlocks is the number of blocks behind the sync target to pause the mining
+	blocksBehind    uint64 // blocksBehind is the number of blocks behind the sync target when paused
+	syncPaused      int32  // syncPaused is 1 if the mining is paused by the sync and resumed once done
+
+	rewardExtraLock sync.Mutex
+	rewardExtra     []byte // rewardExtra is the encoded pool accounting committed in the reward tx
+	coinbaseExtra   []byte // coinbaseExtra is the extra data of the operator in the reward tx, e.g. the pool tag
 }
 
 // NewMiner constructs and returns a miner instance
@@ -67,25 +94,100 @@ func NewMiner(addr common.Address, seele SeeleBackend, log *log.SeeleLog) *Miner
 		seele:                seele,
 		stopChan:             make(chan struct{}, 1),
 		recv:                 make(chan *Result, 1),
+		txs:                  make(chan *types.Transaction, txChanSize),
 		log:                  log,
 		isFirstDownloader:    1,
 		isFirstBlockPrepared: 0,
 		isNonceFound:         new(int32),
+		hashrate:             newHashrateMeter(),
+		syncPauseBlocks:      DefaultSyncPauseBlocks,
 	}
 
-	event.BlockDownloaderEventManager.AddAsyncListener(miner.downloadEventCallback)
-	event.TransactionInsertedEventManager.AddAsyncListener(miner.newTxCallback)
+	// the downloader events are handled in order, so that the miner is not paused after the sync completes
+	event.BlockDownloaderEventManager.AddListener(miner.downloadEventCallback)
+	event.BlockInsertedEventManager.AddAsyncListener(miner.newHeadCallback)
+
+	miner.txSub = seele.TxPool().SubscribeNewTxs(miner.txs)
+	go miner.txLoop()
 
 	return miner
 }
 
-// SetThreads set the number of mining threads.
-func (miner *Miner) SetThreads(threads int) {
+// SetAffinity sets whether to pin each mining thread to a cpu core, which is only supported on Linux.
+func (miner *Miner) SetAffinity(affinity bool) {
+	miner.affinity = affinity
+}
+
+// SetPoolAccounting sets the pool accounting data to commit in the reward tx of the
+// blocks mined afterwards, whose escrow account should be the coinbase. The data is
+// cleared if accounting is nil.
+func (miner *Miner) SetPoolAccounting(accounting *types.PoolAccounting) error {
+	var extra []byte
+	if accounting != nil {
+		if !miner.coinbase.Equal(accounting.Escrow.Address()) {
+			return ErrEscrowNotCoinbase
+		}
+
+		var err error
+		if extra, err = common.Serialize(accounting); err != nil {
+			return err
+		}
+	}
+
+	miner.rewardExtraLock.Lock()
+	defer miner.rewardExtraLock.Unlock()
+
+	miner.rewardExtra = extra
+	return nil
+}
+
+func (miner *Miner) getRewardExtra() []byte {
+	miner.rewardExtraLock.Lock()
+	defer miner.rewardExtraLock.Unlock()
+
+	return miner.rewardExtra
+}
+
+// SetCoinbaseExtra sets the short extra data, e.g. the pool tag or version string, to embed in
+// the reward tx of the blocks mined afterwards, which is at most types.MaxCoinbaseExtraSize bytes.
+// The extra data is cleared if empty.
+func (miner *Miner) SetCoinbaseExtra(extra []byte) error {
+	if len(extra) > types.MaxCoinbaseExtraSize {
+		return types.ErrCoinbaseExtraOversized
+	}
+
+	miner.rewardExtraLock.Lock()
+	defer miner.rewardExtraLock.Unlock()
+
+	miner.coinbaseExtra = append([]byte(nil), extra...)
+	return nil
+}
+
+func (miner *Miner) getCoinbaseExtra() []byte {
+	miner.rewardExtraLock.Lock()
+	defer miner.rewardExtraLock.Unlock()
+
+	return miner.coinbaseExtra
+}
+
+// Start starts the miner with the specified number of mining threads, 0 for the number of CPUs,
+// and negative to mine by the external miners only. The threads are restarted on each new task.
+func (miner *Miner) Start(threads int) error {
+	if atomic.LoadInt32(&miner.mining) == 1 {
+		miner.log.Info("Miner is running")
+		return ErrMinerIsRunning
+	}
+
+	if threads == 0 {
+		threads = runtime.NumCPU()
+	}
 	miner.threads = threads
+
+	return miner.start()
 }
 
-// Start is used to start the miner
-func (miner *Miner) Start() error {
+// start starts the miner with the configured number of mining threads.
+func (miner *Miner) start() error {
 	if atomic.LoadInt32(&miner.mining) == 1 {
 		miner.log.Info("Miner is running")
 		return ErrMinerIsRunning
@@ -111,14 +213,17 @@ func (miner *Miner) Start() error {
 // Stop is used to stop the miner
 func (miner *Miner) Stop() {
 	atomic.StoreInt32(&miner.mining, 0)
-	for i := 0; i < miner.threads; i++ {
-		miner.stopChan <- struct{}{}
-	}
+	miner.stopWorkers()
+
+	// stop waiting for the mined blocks
+	miner.stopChan <- struct{}{}
 	miner.log.Info("Miner is stopped.")
 }
 
 // Close closes the miner
 func (miner *Miner) Close() {
+	miner.txSub.Unsubscribe()
+	close(miner.txs)
 	close(miner.stopChan)
 	close(miner.recv)
 }
@@ -128,9 +233,38 @@ func (miner *Miner) IsMining() bool {
 	return atomic.LoadInt32(&miner.mining) == 1
 }
 
+// Hashrate returns the measured hashes per second of the miner, or of the engine if it
+// implements HashrateEngine.
+func (miner *Miner) Hashrate() float64 {
+	if engine, ok := miner.getEngine().(HashrateEngine); ok {
+		return engine.Hashrate()
+	}
+
+	return miner.hashrate.Rate()
+}
+
+// ThreadHashrates returns the measured hashes per second of each mining thread,
+// which is empty if the tasks are sealed by the engine
+func (miner *Miner) ThreadHashrates() []float64 {
+	if miner.threads <= 0 || miner.getEngine() != nil {
+		return []float64{}
+	}
+
+	return miner.hashrate.threadRates(miner.threads)
+}
+
 // downloadEventCallback handles events which indicate the downloader state
 func (miner *Miner) downloadEventCallback(e event.Event) {
+	if behind, ok := e.(*event.DownloaderBehindEvent); ok {
+		miner.pauseForSync(behind)
+		return
+	}
+
 	if atomic.LoadInt32(&miner.isFirstDownloader) == 0 {
+		if e.(int) != event.DownloaderStartEvent {
+			miner.resumeAfterSync()
+		}
+
 		return
 	}
 
@@ -143,26 +277,41 @@ func (miner *Miner) downloadEventCallback(e event.Event) {
 	case event.DownloaderDoneEvent, event.DownloaderFailedEvent:
 		atomic.StoreInt32(&miner.isFirstDownloader, 0)
 		atomic.StoreInt32(&miner.canStart, 1)
-		miner.Start()
+		miner.start()
 	}
 }
 
-// newTxCallback handles the new tx event
-func (miner *Miner) newTxCallback(e event.Event) {
+// txLoop handles the new txs accepted by the pool until the miner is closed.
+func (miner *Miner) txLoop() {
+	for tx := range miner.txs {
+		miner.newTxCallback(tx)
+	}
+}
+
+// newTxCallback handles the new tx, which is nil once the block is mined to loop mining
+func (miner *Miner) newTxCallback(tx *types.Transaction) {
 	miner.log.Debug("got the new tx event")
 	// if not mining, start mining
 	if atomic.LoadInt32(&miner.canStart) == 1 && atomic.CompareAndSwapInt32(&miner.mining, 0, 1) {
 		miner.prepareNewBlock()
+		return
 	}
+
+	miner.markRecommit(tx)
 }
 
 // waitBlock waits for blocks to be mined continuously
 func (miner *Miner) waitBlock() {
+	recommit := time.NewTicker(recommitCheckInterval)
+	defer recommit.Stop()
+
 out:
 	for {
 		select {
+		case <-recommit.C:
+			miner.checkRecommit()
 		case result := <-miner.recv:
-			if result == nil || result.task != miner.current {
+			if result == nil || result.task != miner.currentTask() {
 				continue
 			}
 
@@ -178,7 +327,7 @@ out:
 			atomic.StoreInt32(&miner.mining, 0)
 
 			// loop mining after mining completed
-			miner.newTxCallback(event.EmptyEvent)
+			miner.newTxCallback(nil)
 		case <-miner.stopChan:
 			break out
 		}
@@ -187,7 +336,11 @@ out:
 
 // prepareNewBlock prepares a new block to be mined
 func (miner *Miner) prepareNewBlock() {
+	miner.prepareLock.Lock()
+	defer miner.prepareLock.Unlock()
+
 	miner.log.Debug("starting mining the new block")
+	atomic.StoreInt32(&miner.recommitPending, 0)
 
 	timestamp := time.Now().Unix()
 	parent, stateDB := miner.seele.BlockChain().CurrentBlock()
@@ -203,25 +356,15 @@ func (miner *Miner) prepareNewBlock() {
 		time.Sleep(wait)
 	}
 
-	height := parent.Header.Height
-	header := &types.BlockHeader{
-		PreviousBlockHash: parent.HeaderHash,
-		Creator:           miner.coinbase,
-		Height:            height + 1,
-		CreateTimestamp:   big.NewInt(timestamp),
-		Difficulty:        big.NewInt(10000000), //TODO find a way to decide difficulty
-	}
-
-	miner.current = &Task{
-		header:    header,
-		createdAt: time.Now(),
+	task, err := miner.newTask(parent, timestamp)
+	if err != nil {
+		miner.log.Warn("failed to prepare the task, %s", err)
+		atomic.StoreInt32(&miner.mining, 0)
+		return
 	}
+	header := task.header
 
 	txs := miner.seele.TxPool().GetProcessableTransactions()
-	txSlice := make([]*types.Transaction, 0)
-	for _, value := range txs {
-		txSlice = append(txSlice, value...)
-	}
 
 	cpyStateDB, err := stateDB.GetCopy()
 	if err != nil {
@@ -229,15 +372,70 @@ func (miner *Miner) prepareNewBlock() {
 		atomic.StoreInt32(&miner.mining, 0)
 		return
 	}
-	err = miner.current.applyTransactions(miner.seele, cpyStateDB, header.Height, txSlice, miner.log)
+	err = task.applyTransactions(miner.seele, cpyStateDB, header.Height, txs, miner.log)
 	if err != nil {
 		miner.log.Warn(err.Error())
 		atomic.StoreInt32(&miner.mining, 0)
 		return
 	}
 
+	miner.setCurrentTask(task)
+
 	miner.log.Info("committing a new task to engine, height=%d", header.Height)
-	miner.commitTask(miner.current)
+	miner.commitTask(task)
+}
+
+// setCurrentTask sets the task being mined, which is also the work of the external miners.
+func (miner *Miner) setCurrentTask(task *Task) {
+	miner.currentLock.Lock()
+	miner.current = task
+	handlers := miner.taskHandlers
+	miner.currentLock.Unlock()
+
+	for _, handler := range handlers {
+		handler(task)
+	}
+}
+
+// onNewTask registers the handler called once a new task is prepared.
+func (miner *Miner) onNewTask(handler func(*Task)) {
+	miner.currentLock.Lock()
+	defer miner.currentLock.Unlock()
+
+	miner.taskHandlers = append(miner.taskHandlers, handler)
+}
+
+// currentTask returns the task being mined, or nil if not prepared yet.
+func (miner *Miner) currentTask() *Task {
+	miner.currentLock.RLock()
+	defer miner.currentLock.RUnlock()
+
+	return miner.current
+}
+
+// newTask creates the task of the block created at the specified timestamp upon the parent block,
+// the consensus fields of which are prepared by the consensus engine of the chain.
+func (miner *Miner) newTask(parent *types.Block, timestamp int64) (*Task, error) {
+	header := &types.BlockHeader{
+		PreviousBlockHash: parent.HeaderHash,
+		Creator:           miner.coinbase,
+		Height:            parent.Header.Height + 1,
+		CreateTimestamp:   big.NewInt(timestamp),
+		Ommers:            miner.seele.BlockChain().GetOmmerCandidates(parent),
+		Version:           types.BlockHeaderVersion,
+	}
+
+	if err := miner.seele.BlockChain().Engine().Prepare(header, parent.Header); err != nil {
+		return nil, err
+	}
+
+	return &Task{
+		header:        header,
+		rewardExtra:   miner.getRewardExtra(),
+		coinbaseExtra: miner.getCoinbaseExtra(),
+		pow:           isPowEngine(miner.seele.BlockChain().Engine()),
+		createdAt:     time.Now(),
+	}, nil
 }
 
 // saveBlock saves the block in the given result to the blockchain
@@ -252,39 +450,22 @@ func (miner *Miner) commitTask(task *Task) {
 		return
 	}
 
-	threads := miner.threads
-
-	if threads <= 0 {
-		threads = runtime.NumCPU()
-		miner.threads = threads
+	if engine := miner.getEngine(); engine != nil {
+		miner.startEngine(task, engine)
+		return
 	}
-	miner.log.Debug("miner threads num:%d", threads)
 
-	var step uint64
-	var seed uint64
-	if threads != 0 {
-		step = math.MaxUint64 / uint64(threads)
+	// the tasks of the other consensus are sealed by its engine instead of the POW threads
+	if engine := miner.seele.BlockChain().Engine(); !isPowEngine(engine) {
+		miner.startEngine(task, &consensusEngine{engine})
+		return
 	}
 
-	atomic.StoreInt32(miner.isNonceFound, 0)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for i := 0; i < threads; i++ {
-		if threads == 1 {
-			seed = r.Uint64()
-		} else {
-			seed = uint64(r.Int63n(int64(step)))
-		}
-		tSeed := seed + uint64(i)*step
-		var min uint64
-		var max uint64
-		min = uint64(i) * step
-
-		if i != threads-1 {
-			max = min + step - 1
-		} else {
-			max = math.MaxUint64
-		}
-
-		go StartMining(task, tSeed, min, max, miner.recv, miner.stopChan, miner.isNonceFound, miner.log)
+	// the task is only mined by the external miners, see GetWork
+	if miner.threads < 0 {
+		return
 	}
+
+	miner.log.Debug("miner threads num:%d", miner.threads)
+	miner.startWorkers(task, miner.threads)
 }
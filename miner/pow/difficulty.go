@@ -0,0 +1,105 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// MaxFutureBlockTime is the default max seconds the block timestamp could be ahead of the local
+// clock, since the miners could lower the difficulty with the later timestamps.
+const MaxFutureBlockTime = 15
+
+var (
+	errDifficultyConfigInvalid = errors.New("invalid difficulty config")
+	errBlockTimestampInvalid   = errors.New("block timestamp not later than the parent")
+	errBlockDifficultyInvalid  = errors.New("invalid block difficulty")
+	errBlockTimestampFuture    = errors.New("block timestamp too far in the future")
+)
+
+// DifficultyConfig specifies the target block period and the bounds of the difficulty adjustment,
+// e.g. a private chain could target 1-second blocks with a low difficulty floor.
+type DifficultyConfig struct {
+	// BlockPeriod is the target interval in seconds between blocks
+	BlockPeriod uint64
+
+	// MinDifficulty is the floor of the block difficulty
+	MinDifficulty *big.Int
+
+	// BoundDivisor bounds each adjustment step to 1/BoundDivisor of the parent difficulty
+	BoundDivisor uint64
+
+	// MaxDownSteps bounds the difficulty decrease of a block to MaxDownSteps adjustment steps,
+	// while the increase is always one step
+	MaxDownSteps uint64
+
+	// MaxFutureDrift is the max seconds the block timestamp could be ahead of the local clock,
+	// MaxFutureBlockTime if 0
+	MaxFutureDrift uint64
+}
+
+// DefaultDifficultyConfig returns the difficulty config of the public networks.
+func DefaultDifficultyConfig() *DifficultyConfig {
+	return &DifficultyConfig{
+		BlockPeriod:   10,
+		MinDifficulty: big.NewInt(10000000),
+		BoundDivisor:  2048,
+		MaxDownSteps:  99,
+	}
+}
+
+// Validate validates the difficulty config.
+func (config *DifficultyConfig) Validate() error {
+	if config.BlockPeriod == 0 || config.MinDifficulty == nil || config.MinDifficulty.Sign() <= 0 ||
+		config.BoundDivisor == 0 || config.MaxDownSteps == 0 {
+		return errDifficultyConfigInvalid
+	}
+
+	return nil
+}
+
+// maxFutureDrift returns the max seconds the block timestamp could be ahead of the local clock.
+func (config *DifficultyConfig) maxFutureDrift() uint64 {
+	if config.MaxFutureDrift == 0 {
+		return MaxFutureBlockTime
+	}
+
+	return config.MaxFutureDrift
+}
+
+// CalcDifficulty returns the difficulty of the block created at the specified timestamp upon the
+// parent block. The difficulty increases by one step if the block is created within the block
+// period, otherwise decreases by one step for each block period elapsed beyond the first one.
+func (config *DifficultyConfig) CalcDifficulty(timestamp uint64, parent *types.BlockHeader) *big.Int {
+	var elapsed uint64
+	if parentTimestamp := parent.CreateTimestamp.Uint64(); timestamp > parentTimestamp {
+		elapsed = timestamp - parentTimestamp
+	}
+
+	steps := int64(1)
+	if periods := elapsed / config.BlockPeriod; periods > 0 {
+		if periods-1 > config.MaxDownSteps {
+			periods = config.MaxDownSteps + 1
+		}
+
+		steps -= int64(periods)
+	}
+
+	step := new(big.Int).Div(parent.Difficulty, new(big.Int).SetUint64(config.BoundDivisor))
+	if step.Sign() == 0 {
+		step.SetInt64(1)
+	}
+
+	difficulty := new(big.Int).Add(parent.Difficulty, step.Mul(step, big.NewInt(steps)))
+	if difficulty.Cmp(config.MinDifficulty) < 0 {
+		difficulty.Set(config.MinDifficulty)
+	}
+
+	return difficulty
+}
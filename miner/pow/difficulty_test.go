@@ -0,0 +1,75 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func newTestDifficultyConfig() *DifficultyConfig {
+	return &DifficultyConfig{
+		BlockPeriod:   2,
+		MinDifficulty: big.NewInt(1000),
+		BoundDivisor:  100,
+		MaxDownSteps:  3,
+	}
+}
+
+func Test_DifficultyConfig_CalcDifficulty(t *testing.T) {
+	config := newTestDifficultyConfig()
+	parent := &types.BlockHeader{Difficulty: big.NewInt(10000), CreateTimestamp: big.NewInt(100)}
+
+	// faster than the block period
+	assert.Equal(t, config.CalcDifficulty(101, parent), big.NewInt(10100))
+
+	// within one block period
+	assert.Equal(t, config.CalcDifficulty(103, parent), big.NewInt(10000))
+
+	// slower than the block period
+	assert.Equal(t, config.CalcDifficulty(104, parent), big.NewInt(9900))
+	assert.Equal(t, config.CalcDifficulty(106, parent), big.NewInt(9800))
+
+	// bounded by the max down steps
+	assert.Equal(t, config.CalcDifficulty(1000, parent), big.NewInt(9700))
+
+	// bounded by the min difficulty
+	parent.Difficulty = big.NewInt(1000)
+	assert.Equal(t, config.CalcDifficulty(1000, parent), big.NewInt(1000))
+}
+
+func Test_Engine_ValidateDifficulty(t *testing.T) {
+	parent := &types.BlockHeader{Difficulty: big.NewInt(10000), CreateTimestamp: big.NewInt(100)}
+	header := &types.BlockHeader{Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(101)}
+
+	// skipped by default
+	assert.Equal(t, Engine{}.ValidateDifficulty(header, parent), error(nil))
+
+	engine := NewEngine(newTestDifficultyConfig(), nil)
+	assert.Equal(t, engine.ValidateDifficulty(header, parent) != nil, true)
+
+	header.Difficulty = big.NewInt(10100)
+	assert.Equal(t, engine.ValidateDifficulty(header, parent), error(nil))
+
+	header.CreateTimestamp = big.NewInt(100)
+	assert.Equal(t, engine.ValidateDifficulty(header, parent), errBlockTimestampInvalid)
+
+	// the later timestamp lowers the difficulty, which is bounded by the local clock
+	future := time.Now().Unix() + MaxFutureBlockTime + 10
+	header.CreateTimestamp = big.NewInt(future)
+	header.Difficulty = engine.CalcDifficulty(uint64(future), parent)
+	assert.Equal(t, engine.ValidateDifficulty(header, parent), errBlockTimestampFuture)
+
+	// the drift is configurable
+	config := newTestDifficultyConfig()
+	config.MaxFutureDrift = MaxFutureBlockTime + 60
+	engine = NewEngine(config, nil)
+	assert.Equal(t, engine.ValidateDifficulty(header, parent), error(nil))
+}
@@ -9,10 +9,16 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/state"
 	"github.com/seeleteam/go-seele/core/types"
 )
 
+// Algorithm is the name of the POW algorithm that hashes the header in the configs, which is the default one.
+const Algorithm = "hash"
+
 var (
 	// maxUint256 is a big integer representing 2^256
 	maxUint256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
@@ -20,8 +26,16 @@ var (
 	errBlockNonceInvalid = errors.New("invalid block nonce")
 )
 
-// Engine provides the consensus operations based on POW.
-type Engine struct{}
+// Engine provides the consensus operations based on POW, which implements consensus.Engine.
+type Engine struct {
+	difficulty *DifficultyConfig // nil to skip the difficulty validation
+	reward     *RewardConfig     // nil to use the default reward schedule
+}
+
+// NewEngine creates a POW engine that validates the block difficulty and reward with the specified configs.
+func NewEngine(difficulty *DifficultyConfig, reward *RewardConfig) *Engine {
+	return &Engine{difficulty, reward}
+}
 
 // ValidateHeader validates the specified header and returns error if validation failed.
 func (engine Engine) ValidateHeader(blockHeader *types.BlockHeader) error {
@@ -38,9 +52,43 @@ func (engine Engine) ValidateHeader(blockHeader *types.BlockHeader) error {
 	return nil
 }
 
+// ValidateDifficulty validates the timestamp and difficulty of the specified header against its parent,
+// and the timestamp against the local clock, see DifficultyConfig.MaxFutureDrift.
+func (engine Engine) ValidateDifficulty(blockHeader, parent *types.BlockHeader) error {
+	if engine.difficulty == nil {
+		return nil
+	}
+
+	timestamp := blockHeader.CreateTimestamp.Uint64()
+	if timestamp <= parent.CreateTimestamp.Uint64() {
+		return errBlockTimestampInvalid
+	}
+
+	if timestamp > uint64(time.Now().Unix())+engine.difficulty.maxFutureDrift() {
+		return errBlockTimestampFuture
+	}
+
+	if expected := engine.difficulty.CalcDifficulty(timestamp, parent); expected.Cmp(blockHeader.Difficulty) != 0 {
+		return fmt.Errorf("%s, want %s, got %s", errBlockDifficultyInvalid, expected, blockHeader.Difficulty)
+	}
+
+	return nil
+}
+
+// CalcDifficulty returns the difficulty of the block created at the specified timestamp upon the
+// parent block, which is calculated with the default config if the difficulty validation is skipped.
+func (engine Engine) CalcDifficulty(timestamp uint64, parent *types.BlockHeader) *big.Int {
+	config := engine.difficulty
+	if config == nil {
+		config = DefaultDifficultyConfig()
+	}
+
+	return config.CalcDifficulty(timestamp, parent)
+}
+
 // ValidateRewardAmount validates the specified amount and returns error if validation failed.
 func (engine Engine) ValidateRewardAmount(blockHeight uint64, amount *big.Int) error {
-	reward := big.NewInt(GetReward(blockHeight))
+	reward := engine.BlockReward(blockHeight)
 
 	if amount == nil || amount.Cmp(reward) != 0 {
 		return fmt.Errorf("invalid reward amount, block height %d, want %s, got %s", blockHeight, reward, amount)
@@ -49,6 +97,77 @@ func (engine Engine) ValidateRewardAmount(blockHeight uint64, amount *big.Int) e
 	return nil
 }
 
+// VerifyHeader implements consensus.Engine, which validates the timestamp and difficulty against
+// the parent, and the nonce.
+func (engine Engine) VerifyHeader(header, parent *types.BlockHeader) error {
+	if err := engine.ValidateDifficulty(header, parent); err != nil {
+		return err
+	}
+
+	return engine.ValidateHeader(header)
+}
+
+// Prepare implements consensus.Engine, which sets the difficulty of the header.
+func (engine Engine) Prepare(header, parent *types.BlockHeader) error {
+	header.Difficulty = engine.CalcDifficulty(header.CreateTimestamp.Uint64(), parent)
+	return nil
+}
+
+// Seal implements consensus.Engine, which searches the nonce from 0 in the current goroutine.
+// The miner searches the nonce with the mining threads instead.
+func (engine Engine) Seal(block *types.Block, abort <-chan struct{}) (*types.Block, error) {
+	header := block.Header.Clone()
+	target := GetMiningTarget(header.Difficulty)
+	hasher := NewHeaderHasher(header)
+
+	var hashInt big.Int
+	for nonce := uint64(0); ; nonce++ {
+		select {
+		case <-abort:
+			return nil, nil
+		default:
+		}
+
+		if hash := hasher.Hash(nonce); hashInt.SetBytes(hash.Bytes()).Cmp(target) <= 0 {
+			header.Nonce = nonce
+			return &types.Block{HeaderHash: hash, Header: header, Transactions: block.Transactions}, nil
+		}
+	}
+}
+
+// BlockReward implements consensus.Engine, which returns the reward of the schedule.
+func (engine Engine) BlockReward(height uint64) *big.Int {
+	return big.NewInt(engine.rewardConfig().GetReward(height))
+}
+
+// Finalize implements consensus.Engine, which rewards the creators of the ommers referenced by the
+// header, as well as the block creator for each ommer referenced.
+func (engine Engine) Finalize(statedb *state.Statedb, header *types.BlockHeader) []*consensus.Reward {
+	config := engine.rewardConfig()
+	rewards := make([]*consensus.Reward, 0, 2*len(header.Ommers))
+
+	for _, ommer := range header.Ommers {
+		reward := big.NewInt(config.GetOmmerReward(header.Height, ommer.Height))
+		statedb.GetOrNewStateObject(ommer.Creator).AddAmount(reward)
+
+		bonus := big.NewInt(config.GetOmmerInclusionReward(header.Height))
+		statedb.GetOrNewStateObject(header.Creator).AddAmount(bonus)
+
+		rewards = append(rewards, &consensus.Reward{To: ommer.Creator, Amount: reward}, &consensus.Reward{To: header.Creator, Amount: bonus})
+	}
+
+	return rewards
+}
+
+// rewardConfig returns the reward schedule, the default one if not specified.
+func (engine Engine) rewardConfig() *RewardConfig {
+	if engine.reward == nil {
+		return DefaultRewardConfig()
+	}
+
+	return engine.reward
+}
+
 // GetMiningTarget returns the mining target for the specified difficulty.
 func GetMiningTarget(difficulty *big.Int) *big.Int {
 	return new(big.Int).Div(maxUint256, difficulty)
@@ -0,0 +1,70 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database/leveldb"
+)
+
+// Engine implements consensus.Engine
+var _ consensus.Engine = Engine{}
+
+func Test_Engine_PrepareAndSeal(t *testing.T) {
+	engine := NewEngine(newTestDifficultyConfig(), nil)
+	parent := &types.BlockHeader{Difficulty: big.NewInt(1000), CreateTimestamp: big.NewInt(100)}
+	header := &types.BlockHeader{Height: 1, CreateTimestamp: big.NewInt(103)}
+
+	assert.Equal(t, engine.Prepare(header, parent), error(nil))
+	assert.Equal(t, header.Difficulty, engine.CalcDifficulty(103, parent))
+
+	sealed, err := engine.Seal(types.NewBlock(header, nil), make(chan struct{}))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sealed.HeaderHash, sealed.Header.Hash())
+	assert.Equal(t, engine.VerifyHeader(sealed.Header, parent), error(nil))
+
+	// the header itself is not changed
+	assert.Equal(t, header.Nonce, uint64(0))
+
+	// aborted
+	abort := make(chan struct{})
+	close(abort)
+	header.Difficulty = new(big.Int).Set(maxUint256)
+	sealed, err = engine.Seal(types.NewBlock(header, nil), abort)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, sealed == nil, true)
+}
+
+func Test_Engine_Finalize(t *testing.T) {
+	db := leveldb.NewMemDB()
+	defer db.Close()
+
+	statedb, err := state.NewStatedb(common.EmptyHash, db)
+	assert.Equal(t, err, error(nil))
+
+	ommer := &types.BlockHeader{Height: 9, Creator: common.BytesToAddress([]byte{1})}
+	header := &types.BlockHeader{Height: 10, Creator: common.BytesToAddress([]byte{2}), Ommers: []*types.BlockHeader{ommer}}
+
+	engine := Engine{}
+	rewards := engine.Finalize(statedb, header)
+	assert.Equal(t, len(rewards), 2)
+	assert.Equal(t, *rewards[0], consensus.Reward{To: ommer.Creator, Amount: big.NewInt(GetOmmerReward(10, 9))})
+	assert.Equal(t, *rewards[1], consensus.Reward{To: header.Creator, Amount: big.NewInt(GetOmmerInclusionReward(10))})
+	assert.Equal(t, statedb.GetBalance(ommer.Creator), rewards[0].Amount)
+	assert.Equal(t, statedb.GetBalance(header.Creator), rewards[1].Amount)
+	assert.Equal(t, engine.BlockReward(10), big.NewInt(GetReward(10)))
+
+	// no ommer
+	header.Ommers = nil
+	assert.Equal(t, len(engine.Finalize(statedb, header)), 0)
+}
@@ -0,0 +1,107 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// nonceField is the index of the Nonce field in the encoded header.
+const nonceField = 9
+
+// HeaderHasher hashes a header of different nonces, the same as the header hash. The fields before
+// the nonce are absorbed into the midstate once, so that each nonce only hashes the nonce and the
+// fields after it. The midstate is recomputed once the encoded size of the nonce changes, since the
+// size of the encoded header changes as well.
+type HeaderHasher struct {
+	before   []byte // encoded fields before the nonce
+	after    []byte // encoded fields after the nonce
+	midstate *crypto.Midstate
+	size     int // encoded size of the nonce of the midstate
+
+	nonce [9]byte // buffer of the encoded nonce
+}
+
+// NewHeaderHasher creates a hasher of the header, which is not changed by the hasher.
+// Note the hashes are not updated with the header changes other than the nonce.
+func NewHeaderHasher(header *types.BlockHeader) *HeaderHasher {
+	content, _, err := rlp.SplitList(common.SerializePanic(header))
+	if err != nil {
+		panic(err)
+	}
+
+	rest := content
+	for i := 0; i < nonceField; i++ {
+		if _, _, rest, err = rlp.Split(rest); err != nil {
+			panic(err)
+		}
+	}
+
+	before := content[:len(content)-len(rest)]
+	_, _, after, err := rlp.Split(rest)
+	if err != nil {
+		panic(err)
+	}
+
+	return &HeaderHasher{before: before, after: after}
+}
+
+// Hash returns the hash of the header of the specified nonce.
+func (hasher *HeaderHasher) Hash(nonce uint64) common.Hash {
+	encoded := hasher.encodeNonce(nonce)
+	if len(encoded) != hasher.size {
+		hasher.size = len(encoded)
+		size := len(hasher.before) + hasher.size + len(hasher.after)
+		hasher.midstate = crypto.NewMidstate(listHeader(size), hasher.before)
+	}
+
+	return hasher.midstate.HashBytes(encoded, hasher.after)
+}
+
+// encodeNonce returns the RLP encoding of the nonce in the buffer of the hasher.
+func (hasher *HeaderHasher) encodeNonce(nonce uint64) []byte {
+	switch {
+	case nonce == 0:
+		hasher.nonce[0] = 0x80
+		return hasher.nonce[:1]
+	case nonce < 0x80:
+		hasher.nonce[0] = byte(nonce)
+		return hasher.nonce[:1]
+	}
+
+	binary.BigEndian.PutUint64(hasher.nonce[1:], nonce)
+	size := 8
+	for hasher.nonce[9-size] == 0 {
+		size--
+	}
+
+	encoded := hasher.nonce[8-size:]
+	encoded[0] = 0x80 + byte(size)
+	return encoded
+}
+
+// listHeader returns the RLP header of the list of the specified content size.
+func listHeader(size int) []byte {
+	if size < 56 {
+		return []byte{0xC0 + byte(size)}
+	}
+
+	var buf [9]byte
+	binary.BigEndian.PutUint64(buf[1:], uint64(size))
+	n := 8
+	for buf[9-n] == 0 {
+		n--
+	}
+
+	header := buf[8-n:]
+	header[0] = 0xF7 + byte(n)
+	return header
+}
@@ -0,0 +1,44 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_HeaderHasher(t *testing.T) {
+	header := &types.BlockHeader{
+		PreviousBlockHash: common.StringToHash("parent"),
+		Difficulty:        big.NewInt(1000),
+		Height:            10,
+		CreateTimestamp:   big.NewInt(100),
+		Ommers:            []*types.BlockHeader{{Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}},
+		Version:           types.BlockHeaderVersion,
+		Extensions:        []rlp.RawValue{{0x83, 1, 2, 3}},
+	}
+
+	hasher := NewHeaderHasher(header)
+	nonces := []uint64{0, 1, 0x7f, 0x80, 0xff, 0x100, 0xffff, 0x10000, 1 << 32, math.MaxUint64 - 1, math.MaxUint64, 5}
+	for _, nonce := range nonces {
+		header.Nonce = nonce
+		assert.Equal(t, hasher.Hash(nonce), header.Hash())
+	}
+
+	// header of the zero fields
+	header = &types.BlockHeader{}
+	hasher = NewHeaderHasher(header)
+	for _, nonce := range nonces {
+		header.Nonce = nonce
+		assert.Equal(t, hasher.Hash(nonce), header.Hash())
+	}
+}
@@ -5,6 +5,10 @@
 
 package pow
 
+import (
+	"errors"
+)
+
 var (
 	// rewardTable the reward value is per year. Which means the first value is for first year, second value is for second year, etc...
 	rewardTable = [...]int64{200, 100, 50, 40, 30}
@@ -16,13 +20,135 @@ var (
 	blockNumberPerEra uint64 = 525000
 )
 
+var errRewardConfigInvalid = errors.New("invalid reward config")
+
+// maxHalvings is the number of halvings after which the halving reward is 0.
+const maxHalvings = 63
+
+// RewardConfig specifies the reward schedule of the blocks, e.g. a private chain could
+// pre-fund the accounts in genesis and pay a constant reward. The schedule is either the
+// table of the rewards per era, or the halving curve if HalvingInterval is specified.
+type RewardConfig struct {
+	// Rewards are the block rewards per era, the first value is for the first era, etc.
+	Rewards []int64
+
+	// TailReward is the block reward after the eras of Rewards, or the minimum block reward of the halving curve
+	TailReward int64
+
+	// BlocksPerEra is the number of blocks of a reward era
+	BlocksPerEra uint64
+
+	// InitialReward is the block reward of the halving curve before the first halving
+	InitialReward int64
+
+	// HalvingInterval is the number of blocks after which the reward of the halving curve halves, 0 to use Rewards
+	HalvingInterval uint64
+}
+
+// DefaultRewardConfig returns the reward schedule of the public networks.
+func DefaultRewardConfig() *RewardConfig {
+	return &RewardConfig{
+		Rewards:      append([]int64(nil), rewardTable[:]...),
+		TailReward:   tailReward,
+		BlocksPerEra: blockNumberPerEra,
+	}
+}
+
+// Validate validates the reward config.
+func (config *RewardConfig) Validate() error {
+	if config.TailReward < 0 || config.InitialReward < 0 {
+		return errRewardConfigInvalid
+	}
+
+	// the halving curve is exclusive with the reward table
+	if config.HalvingInterval > 0 {
+		if len(config.Rewards) > 0 {
+			return errRewardConfigInvalid
+		}
+
+		return nil
+	}
+
+	if config.BlocksPerEra == 0 || config.InitialReward > 0 {
+		return errRewardConfigInvalid
+	}
+
+	for _, reward := range config.Rewards {
+		if reward < 0 {
+			return errRewardConfigInvalid
+		}
+	}
+
+	return nil
+}
+
 // GetReward get reward amount according to block height
+func (config *RewardConfig) GetReward(blockHeight uint64) int64 {
+	if config.HalvingInterval > 0 {
+		return config.getHalvingReward(blockHeight)
+	}
+
+	era := blockHeight / config.BlocksPerEra
+
+	if era < uint64(len(config.Rewards)) {
+		return config.Rewards[era]
+	}
+
+	return config.TailReward
+}
+
+// getHalvingReward returns the reward of the halving curve, which is the initial reward halved
+// every HalvingInterval blocks, but not less than the tail reward.
+func (config *RewardConfig) getHalvingReward(blockHeight uint64) int64 {
+	var reward int64
+	if halvings := blockHeight / config.HalvingInterval; halvings < maxHalvings {
+		reward = config.InitialReward >> halvings
+	}
+
+	if reward < config.TailReward {
+		return config.TailReward
+	}
+
+	return reward
+}
+
+// GetReward get reward amount according to block height of the public networks
 func GetReward(blockHeight uint64) int64 {
-	era := int(blockHeight / blockNumberPerEra)
+	return DefaultRewardConfig().GetReward(blockHeight)
+}
+
+const (
+	// ommerRewardDenominator divides the reward of an ommer by the height distance, i.e. an
+	// ommer at distance d is rewarded (ommerRewardDenominator - d) / ommerRewardDenominator
+	// of the block reward at its height.
+	ommerRewardDenominator = 8
 
-	if era < len(rewardTable) {
-		return rewardTable[era]
+	// ommerInclusionDenominator divides the block reward as the bonus of referencing an ommer.
+	ommerInclusionDenominator = 32
+)
+
+// GetOmmerReward get reward amount of the ommer at ommerHeight referenced by the block at
+// blockHeight, which decreases with the height distance.
+func (config *RewardConfig) GetOmmerReward(blockHeight, ommerHeight uint64) int64 {
+	distance := int64(blockHeight - ommerHeight)
+	if distance <= 0 || distance >= ommerRewardDenominator {
+		return 0
 	}
 
-	return tailReward
+	return config.GetReward(ommerHeight) * (ommerRewardDenominator - distance) / ommerRewardDenominator
+}
+
+// GetOmmerInclusionReward get the bonus amount of the block at blockHeight for each ommer referenced.
+func (config *RewardConfig) GetOmmerInclusionReward(blockHeight uint64) int64 {
+	return config.GetReward(blockHeight) / ommerInclusionDenominator
+}
+
+// GetOmmerReward get reward amount of the ommer of the public networks, see RewardConfig.GetOmmerReward.
+func GetOmmerReward(blockHeight, ommerHeight uint64) int64 {
+	return DefaultRewardConfig().GetOmmerReward(blockHeight, ommerHeight)
+}
+
+// GetOmmerInclusionReward get the bonus amount for each ommer of the public networks.
+func GetOmmerInclusionReward(blockHeight uint64) int64 {
+	return DefaultRewardConfig().GetOmmerInclusionReward(blockHeight)
 }
@@ -22,3 +22,53 @@ func Test_Reward(t *testing.T) {
 
 	assert.Equal(t, GetReward(blockNumberPerEra*uint64(len(rewardTable))), tailReward)
 }
+
+func Test_OmmerReward(t *testing.T) {
+	assert.Equal(t, GetOmmerReward(10, 9), rewardTable[0]*7/8)
+	assert.Equal(t, GetOmmerReward(10, 4), rewardTable[0]*2/8)
+	assert.Equal(t, GetOmmerReward(10, 10), int64(0))
+	assert.Equal(t, GetOmmerReward(10, 2), int64(0))
+
+	// rewarded by the era of the ommer height
+	assert.Equal(t, GetOmmerReward(blockNumberPerEra, blockNumberPerEra-1), rewardTable[0]*7/8)
+
+	assert.Equal(t, GetOmmerInclusionReward(10), rewardTable[0]/32)
+}
+
+func Test_RewardConfig(t *testing.T) {
+	config := &RewardConfig{Rewards: []int64{80, 40}, TailReward: 8, BlocksPerEra: 100}
+	assert.Equal(t, config.Validate(), error(nil))
+
+	assert.Equal(t, config.GetReward(99), int64(80))
+	assert.Equal(t, config.GetReward(100), int64(40))
+	assert.Equal(t, config.GetReward(200), int64(8))
+	assert.Equal(t, config.GetOmmerReward(101, 100), int64(40*7/8))
+	assert.Equal(t, config.GetOmmerInclusionReward(0), int64(80/32))
+
+	config.BlocksPerEra = 0
+	assert.Equal(t, config.Validate(), errRewardConfigInvalid)
+
+	config = &RewardConfig{Rewards: []int64{-1}, BlocksPerEra: 1}
+	assert.Equal(t, config.Validate(), errRewardConfigInvalid)
+}
+
+func Test_RewardConfig_Halving(t *testing.T) {
+	config := &RewardConfig{InitialReward: 100, HalvingInterval: 10, TailReward: 20}
+	assert.Equal(t, config.Validate(), error(nil))
+
+	assert.Equal(t, config.GetReward(0), int64(100))
+	assert.Equal(t, config.GetReward(9), int64(100))
+	assert.Equal(t, config.GetReward(10), int64(50))
+	assert.Equal(t, config.GetReward(20), int64(25))
+	assert.Equal(t, config.GetReward(30), int64(20))
+	assert.Equal(t, config.GetReward(10000), int64(20))
+
+	config.TailReward = 0
+	assert.Equal(t, config.GetReward(10*maxHalvings), int64(0))
+
+	config.Rewards = []int64{10}
+	assert.Equal(t, config.Validate(), errRewardConfigInvalid)
+
+	config = &RewardConfig{InitialReward: 100, BlocksPerEra: 10}
+	assert.Equal(t, config.Validate(), errRewardConfigInvalid)
+}
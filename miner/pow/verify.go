@@ -0,0 +1,69 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// VerifyHeaders implements consensus.BatchVerifier, which hashes the headers concurrently, and then
+// checks the linkage, timestamps, difficulty and nonce of each header in one pass, so that each
+// header is hashed once.
+func (engine Engine) VerifyHeaders(headers []*types.BlockHeader) error {
+	if len(headers) < 2 {
+		return nil
+	}
+
+	hashes := hashHeaders(headers)
+	for i := 1; i < len(headers); i++ {
+		header, parent := headers[i], headers[i-1]
+		if !consensus.Linked(header, parent, hashes[i-1]) {
+			return consensus.ErrHeaderNotLinked
+		}
+
+		if err := engine.ValidateDifficulty(header, parent); err != nil {
+			return err
+		}
+
+		if header.Difficulty == nil || header.Difficulty.Sign() <= 0 {
+			return errBlockDifficultyInvalid
+		}
+
+		if hashes[i].Big().Cmp(GetMiningTarget(header.Difficulty)) > 0 {
+			return errBlockNonceInvalid
+		}
+	}
+
+	return nil
+}
+
+// hashHeaders returns the hashes of the headers, which are computed by the workers of the CPUs.
+func hashHeaders(headers []*types.BlockHeader) []common.Hash {
+	hashes := make([]common.Hash, len(headers))
+	workers := runtime.NumCPU()
+	if workers > len(headers) {
+		workers = len(headers)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(headers); i += workers {
+				hashes[i] = headers[i].Hash()
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	return hashes
+}
@@ -0,0 +1,70 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package pow
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/consensus"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// Engine implements consensus.BatchVerifier
+var _ consensus.BatchVerifier = Engine{}
+
+// newTestHeaders returns the chain of the sealed headers after the parent.
+func newTestHeaders(t *testing.T, engine *Engine, parent *types.BlockHeader, n int) []*types.BlockHeader {
+	headers := []*types.BlockHeader{parent}
+	for i := 0; i < n; i++ {
+		header := &types.BlockHeader{
+			PreviousBlockHash: parent.Hash(),
+			Height:            parent.Height + 1,
+			CreateTimestamp:   new(big.Int).Add(parent.CreateTimestamp, big.NewInt(2)),
+		}
+		assert.Equal(t, engine.Prepare(header, parent), error(nil))
+
+		sealed, err := engine.Seal(types.NewBlock(header, nil), make(chan struct{}))
+		assert.Equal(t, err, error(nil))
+
+		headers = append(headers, sealed.Header)
+		parent = sealed.Header
+	}
+
+	return headers
+}
+
+func Test_Engine_VerifyHeaders(t *testing.T) {
+	engine := NewEngine(newTestDifficultyConfig(), nil)
+	parent := &types.BlockHeader{Difficulty: big.NewInt(1000), CreateTimestamp: big.NewInt(100)}
+	headers := newTestHeaders(t, engine, parent, 20)
+
+	assert.Equal(t, engine.VerifyHeaders(headers), error(nil))
+	assert.Equal(t, engine.VerifyHeaders(headers[:1]), error(nil))
+
+	// the same result as the headers verified one by one
+	assert.Equal(t, consensus.VerifyHeaders(engine, headers), error(nil))
+	for i := 1; i < len(headers); i++ {
+		assert.Equal(t, engine.VerifyHeader(headers[i], headers[i-1]), error(nil))
+	}
+
+	// gap in the chain
+	gapped := append(append([]*types.BlockHeader{}, headers[:5]...), headers[6:]...)
+	assert.Equal(t, engine.VerifyHeaders(gapped), consensus.ErrHeaderNotLinked)
+
+	// tampered header, which fails the nonce or the link to its child
+	tampered := append([]*types.BlockHeader{}, headers...)
+	tampered[10] = headers[10].Clone()
+	tampered[10].Creator[0]++
+	assert.Equal(t, engine.VerifyHeaders(tampered) != nil, true)
+
+	// difficulty not of the config
+	tampered = append([]*types.BlockHeader{}, headers[:3]...)
+	tampered[2] = headers[2].Clone()
+	tampered[2].Difficulty = new(big.Int).Mul(tampered[2].Difficulty, big.NewInt(1000000))
+	assert.Equal(t, engine.VerifyHeaders(tampered) != nil, true)
+}
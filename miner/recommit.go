@@ -0,0 +1,99 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/event"
+)
+
+const (
+	// recommitCheckInterval is the interval to check whether the task should be rebuilt.
+	recommitCheckInterval = time.Second
+
+	// minRecommitInterval is the min age of the task to rebuild for the higher-fee txs,
+	// so that the mining threads are not restarted on each tx.
+	minRecommitInterval = time.Second
+)
+
+// SetRecommitInterval sets the interval to rebuild the task with the latest txs and timestamp,
+// 0 to rebuild only for the higher-fee txs and the new head.
+func (miner *Miner) SetRecommitInterval(interval time.Duration) {
+	atomic.StoreInt64(&miner.recommitInterval, int64(interval))
+}
+
+// worthRecommit returns true if the tx pays more than the txs packed in the task, or the task packs no tx.
+func (task *Task) worthRecommit(tx *types.Transaction) bool {
+	return task.minGasPrice == nil || tx.Data.GasPrice.Cmp(task.minGasPrice) > 0
+}
+
+// markRecommit marks the task to be rebuilt on the next check if the new tx is worth it.
+// The empty task of the dev engine is rebuilt at once.
+func (miner *Miner) markRecommit(tx *types.Transaction) {
+	if tx == nil || !miner.IsMining() {
+		return
+	}
+
+	task := miner.currentTask()
+	if task == nil || !task.worthRecommit(tx) {
+		return
+	}
+
+	// the empty task is not sealed by the dev engine, which is rebuilt at once
+	if _, ok := miner.getEngine().(*DevEngine); ok && len(task.txs) <= 1 {
+		miner.prepareNewBlock()
+		return
+	}
+
+	atomic.StoreInt32(&miner.recommitPending, 1)
+}
+
+// checkRecommit rebuilds the task if the higher-fee txs arrived or the recommit interval elapsed,
+// in which the mining threads of the previous task are aborted.
+func (miner *Miner) checkRecommit() {
+	task := miner.currentTask()
+	if task == nil || !miner.IsMining() {
+		return
+	}
+
+	age := time.Since(task.createdAt)
+	interval := time.Duration(atomic.LoadInt64(&miner.recommitInterval))
+
+	switch {
+	case atomic.LoadInt32(&miner.recommitPending) == 1 && age >= minRecommitInterval:
+		miner.log.Debug("recommitting the task of height %d for the higher-fee txs", task.header.Height)
+	case interval > 0 && age >= interval:
+		miner.log.Debug("recommitting the task of height %d for the interval %s", task.header.Height, interval)
+	default:
+		return
+	}
+
+	miner.prepareNewBlock()
+}
+
+// newHeadCallback rebuilds the task upon the new head inserted, e.g. the block mined by the
+// other nodes, since the current task is stale.
+func (miner *Miner) newHeadCallback(e event.Event) {
+	block := e.(*types.Block)
+	if !miner.IsMining() {
+		return
+	}
+
+	head, _ := miner.seele.BlockChain().CurrentBlock()
+	if !head.HeaderHash.Equal(block.HeaderHash) {
+		return
+	}
+
+	if task := miner.currentTask(); task == nil || task.header.PreviousBlockHash.Equal(block.HeaderHash) {
+		return
+	}
+
+	miner.log.Debug("recommitting the task upon the new head %d", block.Header.Height)
+	miner.prepareNewBlock()
+}
@@ -0,0 +1,108 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+type testBackend struct {
+	chain    *core.Blockchain
+	pool     *core.TransactionPool
+	coinbase common.Address
+}
+
+func (backend *testBackend) TxPool() *core.TransactionPool { return backend.pool }
+func (backend *testBackend) BlockChain() *core.Blockchain  { return backend.chain }
+func (backend *testBackend) GetCoinbase() common.Address   { return backend.coinbase }
+
+func newTestTransfer(from *testutil.Account, nonce uint64, gasPrice int64) *types.Transaction {
+	tx := types.NewTransaction(from.Address, testutil.NewAccount().Address, big.NewInt(1), big.NewInt(gasPrice), types.TransferGas, nonce)
+	tx.Sign(from.PrivateKey)
+	return tx
+}
+
+func Test_Task_WorthRecommit(t *testing.T) {
+	alice := testutil.NewAccount()
+	task := getTask(1)
+	assert.Equal(t, task.worthRecommit(newTestTransfer(alice, 0, 1)), true)
+
+	task.minGasPrice = big.NewInt(10)
+	assert.Equal(t, task.worthRecommit(newTestTransfer(alice, 0, 10)), false)
+	assert.Equal(t, task.worthRecommit(newTestTransfer(alice, 0, 11)), true)
+}
+
+func Test_Miner_Recommit(t *testing.T) {
+	alice := testutil.NewAccount()
+	chain := testutil.NewChain(t, testutil.Alloc(big.NewInt(1000000), alice))
+	defer chain.Close()
+
+	pool := core.NewTransactionPool(*core.DefaultTxPoolConfig(), chain.Blockchain())
+	defer pool.Stop()
+
+	miner := &Miner{
+		mining:       1,
+		threads:      -1,
+		seele:        &testBackend{chain.Blockchain(), pool, chain.Coinbase().Address},
+		stopChan:     make(chan struct{}, 1),
+		recv:         make(chan *Result, 1),
+		isNonceFound: new(int32),
+		hashrate:     newHashrateMeter(),
+		log:          logger,
+	}
+
+	tx1 := newTestTransfer(alice, 0, 10)
+	assert.Equal(t, pool.AddTransaction(tx1), error(nil))
+	miner.prepareNewBlock()
+	task := miner.currentTask()
+	assert.Equal(t, len(task.txs), 2)
+	assert.Equal(t, task.minGasPrice, big.NewInt(10))
+
+	// the packed tx is kept in the pool until the block is inserted
+	assert.Equal(t, pool.GetTransaction(tx1.Hash) != nil, true)
+
+	// the task is not rebuilt for the lower-fee tx
+	tx2 := newTestTransfer(alice, 1, 5)
+	assert.Equal(t, pool.AddTransaction(tx2), error(nil))
+	miner.markRecommit(tx2)
+	miner.checkRecommit()
+	assert.Equal(t, miner.currentTask(), task)
+
+	// the task is rebuilt for the higher-fee tx once it is old enough
+	tx3 := newTestTransfer(testutil.NewAccount(), 0, 20)
+	miner.markRecommit(tx3)
+	miner.checkRecommit()
+	assert.Equal(t, miner.currentTask(), task)
+
+	task.createdAt = time.Now().Add(-minRecommitInterval)
+	miner.checkRecommit()
+	assert.Equal(t, miner.currentTask() != task, true)
+	assert.Equal(t, len(miner.currentTask().txs), 3)
+
+	// the task is rebuilt on the recommit interval
+	task = miner.currentTask()
+	miner.SetRecommitInterval(time.Minute)
+	miner.checkRecommit()
+	assert.Equal(t, miner.currentTask(), task)
+
+	task.createdAt = time.Now().Add(-time.Minute)
+	miner.checkRecommit()
+	assert.Equal(t, miner.currentTask() != task, true)
+
+	// the task is rebuilt upon the new head
+	task = miner.currentTask()
+	block := chain.Mine()
+	miner.newHeadCallback(block)
+	assert.Equal(t, miner.currentTask().header.PreviousBlockHash, block.HeaderHash)
+}
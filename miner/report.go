@@ -0,0 +1,172 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+// MaxReportBlocks is the max number of blocks scanned for an earnings report.
+const MaxReportBlocks = 100000
+
+var (
+	// ErrReportRangeInvalid is returned when the height range of the report is invalid.
+	ErrReportRangeInvalid = errors.New("invalid height range of the report")
+
+	// ErrReportRangeTooLarge is returned when the report scans more than MaxReportBlocks blocks.
+	ErrReportRangeTooLarge = errors.New("height range of the report too large")
+)
+
+// BlockEarnings is the income of the coinbase addresses in a block.
+type BlockEarnings struct {
+	Height       uint64         `json:"height"`
+	Hash         common.Hash    `json:"hash"`
+	Timestamp    uint64         `json:"timestamp"`
+	Creator      common.Address `json:"creator"`      // Creator is the coinbase of the block
+	Reward       *big.Int       `json:"reward"`       // Reward is the amount of the reward tx if the block is mined by the coinbases
+	Fees         *big.Int       `json:"fees"`         // Fees are the tx fees paid to the creator if the block is mined by the coinbases
+	OmmerRewards *big.Int       `json:"ommerRewards"` // OmmerRewards are the rewards of the ommers created by the coinbases and the inclusion bonus
+}
+
+// Total returns the total income of the block.
+func (earnings *BlockEarnings) Total() *big.Int {
+	total := new(big.Int).Add(earnings.Reward, earnings.Fees)
+	return total.Add(total, earnings.OmmerRewards)
+}
+
+// EarningsReport is the income of the coinbase addresses within the height range of the
+// canonical chain, e.g. for the accounting of the mining operations.
+type EarningsReport struct {
+	From              uint64           `json:"from"`
+	To                uint64           `json:"to"`
+	Coinbases         []common.Address `json:"coinbases"`
+	Blocks            []*BlockEarnings `json:"blocks"` // Blocks are the blocks of any income in height order
+	TotalReward       *big.Int         `json:"totalReward"`
+	TotalFees         *big.Int         `json:"totalFees"`
+	TotalOmmerRewards *big.Int         `json:"totalOmmerRewards"`
+	Total             *big.Int         `json:"total"`
+}
+
+// BuildEarningsReport scans the canonical blocks within the height range [from, to], and sums
+// the rewards and fees paid to the coinbase addresses. The fees are the actual fees of the tx
+// receipts, and the ommer rewards are calculated with the reward schedule.
+func BuildEarningsReport(bcStore store.BlockchainStore, rewards *pow.RewardConfig, from, to uint64, coinbases []common.Address) (*EarningsReport, error) {
+	if from == 0 || from > to {
+		return nil, ErrReportRangeInvalid
+	}
+
+	if to-from >= MaxReportBlocks {
+		return nil, ErrReportRangeTooLarge
+	}
+
+	mine := make(map[common.Address]bool)
+	for _, coinbase := range coinbases {
+		mine[coinbase] = true
+	}
+
+	report := &EarningsReport{
+		From:              from,
+		To:                to,
+		Coinbases:         coinbases,
+		Blocks:            make([]*BlockEarnings, 0),
+		TotalReward:       new(big.Int),
+		TotalFees:         new(big.Int),
+		TotalOmmerRewards: new(big.Int),
+		Total:             new(big.Int),
+	}
+
+	for height := from; height <= to; height++ {
+		block, err := bcStore.GetBlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+
+		header := block.Header
+		earnings := &BlockEarnings{
+			Height:       header.Height,
+			Hash:         block.HeaderHash,
+			Timestamp:    header.CreateTimestamp.Uint64(),
+			Creator:      header.Creator,
+			Reward:       new(big.Int),
+			Fees:         new(big.Int),
+			OmmerRewards: new(big.Int),
+		}
+
+		if mine[header.Creator] {
+			// the first tx is the miner reward
+			if len(block.Transactions) > 0 {
+				earnings.Reward.Set(block.Transactions[0].Data.Amount)
+			}
+
+			receipts, err := bcStore.GetReceipts(block.HeaderHash)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, receipt := range receipts {
+				if receipt.Fee != nil {
+					earnings.Fees.Add(earnings.Fees, receipt.Fee)
+				}
+			}
+		}
+
+		for _, ommer := range header.Ommers {
+			if mine[ommer.Creator] {
+				earnings.OmmerRewards.Add(earnings.OmmerRewards, big.NewInt(rewards.GetOmmerReward(header.Height, ommer.Height)))
+			}
+
+			if mine[header.Creator] {
+				earnings.OmmerRewards.Add(earnings.OmmerRewards, big.NewInt(rewards.GetOmmerInclusionReward(header.Height)))
+			}
+		}
+
+		total := earnings.Total()
+		if total.Sign() == 0 && !mine[header.Creator] {
+			continue
+		}
+
+		report.Blocks = append(report.Blocks, earnings)
+		report.TotalReward.Add(report.TotalReward, earnings.Reward)
+		report.TotalFees.Add(report.TotalFees, earnings.Fees)
+		report.TotalOmmerRewards.Add(report.TotalOmmerRewards, earnings.OmmerRewards)
+		report.Total.Add(report.Total, total)
+	}
+
+	return report, nil
+}
+
+// WriteCSV writes the blocks of the report in CSV with a header row, followed by the total row.
+func (report *EarningsReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"height", "hash", "timestamp", "creator", "reward", "fees", "ommerRewards", "total"})
+
+	for _, block := range report.Blocks {
+		writer.Write([]string{
+			strconv.FormatUint(block.Height, 10),
+			block.Hash.ToHex(),
+			strconv.FormatUint(block.Timestamp, 10),
+			block.Creator.ToHex(),
+			block.Reward.String(),
+			block.Fees.String(),
+			block.OmmerRewards.String(),
+			block.Total().String(),
+		})
+	}
+
+	writer.Write([]string{"total", "", "", "", report.TotalReward.String(), report.TotalFees.String(),
+		report.TotalOmmerRewards.String(), report.Total.String()})
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,62 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/miner/pow"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+func Test_BuildEarningsReport(t *testing.T) {
+	chain := testutil.NewChain(t, nil)
+	defer chain.Close()
+
+	tx := chain.Fund(testutil.NewAccount().Address, big.NewInt(100))
+	chain.MineBlocks(2)
+
+	receipt, err := chain.Receipt(tx.Hash)
+	assert.Equal(t, err, error(nil))
+
+	bcStore := chain.Blockchain().GetStore()
+	coinbases := []common.Address{chain.Coinbase().Address}
+	report, err := BuildEarningsReport(bcStore, pow.DefaultRewardConfig(), 1, 2, coinbases)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(report.Blocks), 2)
+	assert.Equal(t, report.Blocks[0].Fees, receipt.Fee)
+	assert.Equal(t, report.Blocks[1].Fees, big.NewInt(0))
+
+	reward := big.NewInt(pow.GetReward(1) + pow.GetReward(2))
+	assert.Equal(t, report.TotalReward, reward)
+	assert.Equal(t, report.TotalFees, receipt.Fee)
+	assert.Equal(t, report.Total, new(big.Int).Add(reward, receipt.Fee))
+
+	var buf bytes.Buffer
+	assert.Equal(t, report.WriteCSV(&buf), error(nil))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, len(lines), 4)
+	assert.Equal(t, strings.HasPrefix(lines[3], "total,,,,"+reward.String()+","), true)
+
+	// the blocks of the other coinbases
+	report, err = BuildEarningsReport(bcStore, pow.DefaultRewardConfig(), 1, 2, []common.Address{testutil.NewAccount().Address})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(report.Blocks), 0)
+	assert.Equal(t, report.Total, big.NewInt(0))
+
+	// invalid ranges
+	_, err = BuildEarningsReport(bcStore, pow.DefaultRewardConfig(), 0, 2, coinbases)
+	assert.Equal(t, err, ErrReportRangeInvalid)
+	_, err = BuildEarningsReport(bcStore, pow.DefaultRewardConfig(), 2, 1, coinbases)
+	assert.Equal(t, err, ErrReportRangeInvalid)
+	_, err = BuildEarningsReport(bcStore, pow.DefaultRewardConfig(), 1, MaxReportBlocks+1, coinbases)
+	assert.Equal(t, err, ErrReportRangeTooLarge)
+}
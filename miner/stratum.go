@@ -0,0 +1,497 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+const (
+	// stratumMaxLineSize is the maximum size of a request line, so that a client could not exhaust the memory.
+	stratumMaxLineSize = 4096
+
+	// stratumWriteTimeout is the timeout to write a message to a client.
+	stratumWriteTimeout = 10 * time.Second
+
+	// stratumMaxSessionWorkers is the maximum number of the workers authorized in a connection.
+	stratumMaxSessionWorkers = 16
+
+	// stratumMaxWorkers is the maximum number of the workers whose statistics are kept, the
+	// statistics of the disconnected workers are evicted once exceeded.
+	stratumMaxWorkers = 1024
+
+	// stratumMaxJobShares is the maximum number of the valid shares of a job, so that the nonces
+	// kept to reject the duplicate shares are bounded.
+	stratumMaxJobShares = 64 * 1024
+)
+
+// The errors returned to the stratum clients, whose codes follow the stratum convention.
+var (
+	errStratumUnknown        = &stratumError{20, "other/unknown"}
+	errStratumJobNotFound    = &stratumError{21, "job not found"}
+	errStratumDuplicate      = &stratumError{22, "duplicate share"}
+	errStratumLowDifficulty  = &stratumError{23, "low difficulty share"}
+	errStratumUnauthorized   = &stratumError{24, "unauthorized worker"}
+	errStratumNotSubscribed  = &stratumError{25, "not subscribed"}
+	errStratumTooManyWorkers = &stratumError{24, "too many workers"}
+	errStratumJobFull        = &stratumError{20, "too many shares of the job"}
+
+	// ErrShareDifficultyInvalid is returned when the share difficulty of the stratum server is not positive.
+	ErrShareDifficultyInvalid = errors.New("share difficulty should be positive")
+)
+
+// StratumConfig is the config of the stratum server.
+type StratumConfig struct {
+	// Addr is the TCP address to listen, empty to disable the stratum server
+	Addr string
+
+	// ShareDifficulty is the difficulty of the shares submitted by the workers, which should be
+	// much lower than the block difficulty so that the workers submit shares frequently
+	ShareDifficulty *big.Int
+}
+
+// WorkerStats is the share statistics of a stratum worker.
+type WorkerStats struct {
+	Worker    string `json:"worker"`    // Worker is the name authorized by the worker, e.g. <account>.<rig>
+	Accepted  uint64 `json:"accepted"`  // Accepted is the number of valid shares
+	Rejected  uint64 `json:"rejected"`  // Rejected is the number of duplicate or low difficulty shares
+	Stale     uint64 `json:"stale"`     // Stale is the number of shares of the previous jobs
+	Blocks    uint64 `json:"blocks"`    // Blocks is the number of the shares that meet the block target
+	LastShare int64  `json:"lastShare"` // LastShare is the unix time of the last valid share
+}
+
+// StratumServer publishes the mining jobs derived from the tasks of the miner to the workers connected
+// via the stratum protocol, i.e. line-delimited JSON-RPC over TCP, and validates the submitted shares.
+//
+// The workers subscribe via "mining.subscribe", authorize via "mining.authorize" with params
+// [<worker>, <password>], and submit via "mining.submit" with params [<worker>, <job id>, <nonce>].
+// The server notifies "mining.set_difficulty" with params [<share difficulty>] and "mining.notify"
+// with params [<job id>, <RLP encoded header in hex>, <height>, <clean jobs>].
+type StratumServer struct {
+	miner           *Miner
+	addr            string
+	shareDifficulty *big.Int
+	shareTarget     *big.Int
+	log             *log.SeeleLog
+
+	lock     sync.Mutex
+	listener net.Listener
+	sessions map[*stratumSession]struct{}
+	job      *stratumJob
+	stats    map[string]*WorkerStats
+	nextID   uint64
+}
+
+// stratumJob is the job of a task published to the workers.
+type stratumJob struct {
+	work   *Work
+	header *types.BlockHeader // header is the header of the task with zero nonce
+	nonces map[uint64]bool    // nonces are the nonces of the valid shares, to reject the duplicate shares
+}
+
+// stratumSession is the connection of a worker.
+type stratumSession struct {
+	id         string
+	conn       net.Conn
+	writeLock  sync.Mutex
+	subscribed bool
+	workers    map[string]bool // workers are the authorized workers of the connection
+}
+
+type stratumRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type stratumResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  *stratumError   `json:"error"`
+}
+
+type stratumNotification struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []interface{}   `json:"params"`
+}
+
+// stratumError is the error returned to the clients, which is encoded as [<code>, <message>, null].
+type stratumError struct {
+	code    int
+	message string
+}
+
+func (err *stratumError) Error() string {
+	return err.message
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (err *stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{err.code, err.message, nil})
+}
+
+// NewStratumServer creates the stratum server which publishes the tasks of the miner.
+func NewStratumServer(miner *Miner, config StratumConfig, log *log.SeeleLog) (*StratumServer, error) {
+	if config.ShareDifficulty == nil || config.ShareDifficulty.Sign() <= 0 {
+		return nil, ErrShareDifficultyInvalid
+	}
+
+	server := &StratumServer{
+		miner:           miner,
+		addr:            config.Addr,
+		shareDifficulty: new(big.Int).Set(config.ShareDifficulty),
+		shareTarget:     pow.GetMiningTarget(config.ShareDifficulty),
+		log:             log,
+		sessions:        make(map[*stratumSession]struct{}),
+		stats:           make(map[string]*WorkerStats),
+	}
+
+	miner.onNewTask(server.publish)
+
+	return server, nil
+}
+
+// Start starts to listen and serve the workers.
+func (server *StratumServer) Start() error {
+	listener, err := net.Listen("tcp", server.addr)
+	if err != nil {
+		return err
+	}
+
+	server.lock.Lock()
+	server.listener = listener
+	server.lock.Unlock()
+
+	server.log.Info("stratum server started, address: %s", listener.Addr())
+	go server.serve(listener)
+
+	return nil
+}
+
+// Stop stops the server and disconnects the workers.
+func (server *StratumServer) Stop() {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if server.listener != nil {
+		server.listener.Close()
+		server.listener = nil
+	}
+
+	for session := range server.sessions {
+		session.conn.Close()
+	}
+}
+
+// Addr returns the listening address, or nil if not started.
+func (server *StratumServer) Addr() net.Addr {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if server.listener == nil {
+		return nil
+	}
+
+	return server.listener.Addr()
+}
+
+// Stats returns the share statistics of the workers in the order of the worker names.
+func (server *StratumServer) Stats() []*WorkerStats {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	stats := make([]*WorkerStats, 0, len(server.stats))
+	for _, s := range server.stats {
+		copied := *s
+		stats = append(stats, &copied)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Worker < stats[j].Worker
+	})
+
+	return stats
+}
+
+func (server *StratumServer) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			server.log.Debug("stratum server stops accepting, %s", err)
+			return
+		}
+
+		server.lock.Lock()
+		server.nextID++
+		session := &stratumSession{
+			id:      strconv.FormatUint(server.nextID, 16),
+			conn:    conn,
+			workers: make(map[string]bool),
+		}
+		server.sessions[session] = struct{}{}
+		server.lock.Unlock()
+
+		go server.handle(session)
+	}
+}
+
+// handle reads and handles the requests of the session until disconnected.
+func (server *StratumServer) handle(session *stratumSession) {
+	defer func() {
+		server.lock.Lock()
+		delete(server.sessions, session)
+		server.lock.Unlock()
+
+		session.conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(session.conn)
+	scanner.Buffer(make([]byte, stratumMaxLineSize), stratumMaxLineSize)
+
+	for scanner.Scan() {
+		var request stratumRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			server.log.Debug("invalid stratum request from %s, %s", session.conn.RemoteAddr(), err)
+			return
+		}
+
+		result, err := server.handleRequest(session, &request)
+		response := &stratumResponse{ID: request.ID, Result: result}
+		if err != nil {
+			response.Error = err
+		}
+
+		if session.write(response) != nil {
+			return
+		}
+
+		// the job is sent once subscribed
+		if request.Method == "mining.subscribe" && err == nil {
+			server.lock.Lock()
+			job := server.job
+			server.lock.Unlock()
+
+			session.write(&stratumNotification{Method: "mining.set_difficulty", Params: []interface{}{server.shareDifficulty}})
+			if job != nil {
+				session.write(job.notification())
+			}
+		}
+	}
+}
+
+func (server *StratumServer) handleRequest(session *stratumSession, request *stratumRequest) (interface{}, *stratumError) {
+	switch request.Method {
+	case "mining.subscribe":
+		server.lock.Lock()
+		session.subscribed = true
+		server.lock.Unlock()
+
+		return []interface{}{[]interface{}{"mining.notify", session.id}}, nil
+	case "mining.authorize":
+		var worker string
+		if len(request.Params) < 1 || json.Unmarshal(request.Params[0], &worker) != nil || worker == "" {
+			return false, errStratumUnauthorized
+		}
+
+		server.lock.Lock()
+		defer server.lock.Unlock()
+
+		if !session.subscribed {
+			return false, errStratumNotSubscribed
+		}
+
+		if !session.workers[worker] && len(session.workers) >= stratumMaxSessionWorkers {
+			return false, errStratumTooManyWorkers
+		}
+
+		if server.stats[worker] == nil {
+			if len(server.stats) >= stratumMaxWorkers && !server.evictStats() {
+				return false, errStratumTooManyWorkers
+			}
+
+			server.stats[worker] = &WorkerStats{Worker: worker}
+		}
+
+		session.workers[worker] = true
+		return true, nil
+	case "mining.submit":
+		var worker, jobID, nonce string
+		if len(request.Params) < 3 || json.Unmarshal(request.Params[0], &worker) != nil ||
+			json.Unmarshal(request.Params[1], &jobID) != nil || json.Unmarshal(request.Params[2], &nonce) != nil {
+			return false, errStratumUnknown
+		}
+
+		value, err := strconv.ParseUint(nonce, 0, 64)
+		if err != nil {
+			return false, errStratumUnknown
+		}
+
+		if err := server.submit(session, worker, jobID, value); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	default:
+		return nil, errStratumUnknown
+	}
+}
+
+// submit validates the share of the worker, and submits the block if the share meets the block target.
+func (server *StratumServer) submit(session *stratumSession, worker, jobID string, nonce uint64) *stratumError {
+	server.lock.Lock()
+
+	if !session.workers[worker] {
+		server.lock.Unlock()
+		return errStratumUnauthorized
+	}
+
+	stats, job := server.stats[worker], server.job
+	if job == nil || job.work.ID.ToHex() != jobID {
+		stats.Stale++
+		server.lock.Unlock()
+		return errStratumJobNotFound
+	}
+
+	if job.nonces[nonce] {
+		stats.Rejected++
+		server.lock.Unlock()
+		return errStratumDuplicate
+	}
+
+	header := job.header.Clone()
+	header.Nonce = nonce
+	hash := header.Hash().Big()
+
+	// the share of the block target is valid even if the block difficulty is lower than the share difficulty
+	if hash.Cmp(server.shareTarget) > 0 && hash.Cmp(job.work.Target) > 0 {
+		stats.Rejected++
+		server.lock.Unlock()
+		return errStratumLowDifficulty
+	}
+
+	// the block is still submitted once the job is full of shares
+	if len(job.nonces) >= stratumMaxJobShares && hash.Cmp(job.work.Target) > 0 {
+		stats.Rejected++
+		server.lock.Unlock()
+		return errStratumJobFull
+	}
+
+	job.nonces[nonce] = true
+	stats.Accepted++
+	stats.LastShare = time.Now().Unix()
+	server.lock.Unlock()
+
+	if hash.Cmp(job.work.Target) > 0 {
+		return nil
+	}
+
+	if err := server.miner.SubmitWork(job.work.ID, nonce); err != nil {
+		server.log.Warn("submitting the block of worker %s failed, %s", worker, err)
+		return nil
+	}
+
+	server.log.Info("block found by stratum worker %s, height:%d", worker, job.work.Height)
+
+	server.lock.Lock()
+	stats.Blocks++
+	server.lock.Unlock()
+
+	return nil
+}
+
+// evictStats removes the statistics of the disconnected worker of the earliest last share, and
+// returns false if all the workers are connected. It should be called with the lock held.
+func (server *StratumServer) evictStats() bool {
+	connected := make(map[string]bool)
+	for session := range server.sessions {
+		for worker := range session.workers {
+			connected[worker] = true
+		}
+	}
+
+	var evicted *WorkerStats
+	for worker, stats := range server.stats {
+		if !connected[worker] && (evicted == nil || stats.LastShare < evicted.LastShare) {
+			evicted = stats
+		}
+	}
+
+	if evicted == nil {
+		return false
+	}
+
+	delete(server.stats, evicted.Worker)
+	return true
+}
+
+// publish publishes the job of the new task to the subscribed workers.
+func (server *StratumServer) publish(task *Task) {
+	// no job if the block is not sealed with the nonce of the header hash, see Task.Work
+	if !task.pow {
+		return
+	}
+
+	block := task.generateBlock()
+	work, err := newWork(block)
+	if err != nil {
+		server.log.Warn("creating the stratum job failed, %s", err)
+		return
+	}
+
+	job := &stratumJob{work: work, header: block.Header, nonces: make(map[uint64]bool)}
+
+	server.lock.Lock()
+	server.job = job
+	sessions := make([]*stratumSession, 0, len(server.sessions))
+	for session := range server.sessions {
+		if session.subscribed {
+			sessions = append(sessions, session)
+		}
+	}
+	server.lock.Unlock()
+
+	notification := job.notification()
+	for _, session := range sessions {
+		session.write(notification)
+	}
+}
+
+// notification returns the "mining.notify" notification of the job, which cleans the previous jobs.
+func (job *stratumJob) notification() *stratumNotification {
+	return &stratumNotification{
+		Method: "mining.notify",
+		Params: []interface{}{job.work.ID.ToHex(), job.work.Header, job.work.Height, true},
+	}
+}
+
+// write writes the message in a line to the session.
+func (session *stratumSession) write(msg interface{}) error {
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	session.writeLock.Lock()
+	defer session.writeLock.Unlock()
+
+	session.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+	_, err = session.conn.Write(append(encoded, '\n'))
+
+	return err
+}
@@ -0,0 +1,233 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+type testStratumClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int
+}
+
+type testStratumMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params []interface{}   `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  []interface{}   `json:"error"`
+}
+
+func newTestStratumServer(t *testing.T, shareDifficulty int64) (*Miner, *StratumServer, *testStratumClient) {
+	miner := &Miner{
+		mining:       1,
+		stopChan:     make(chan struct{}, 1),
+		recv:         make(chan *Result, 1),
+		isNonceFound: new(int32),
+		log:          logger,
+	}
+
+	server, err := NewStratumServer(miner, StratumConfig{Addr: "127.0.0.1:0", ShareDifficulty: big.NewInt(shareDifficulty)}, logger)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, server.Start(), error(nil))
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	assert.Equal(t, err, error(nil))
+
+	return miner, server, &testStratumClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+func (client *testStratumClient) read() *testStratumMessage {
+	client.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := client.reader.ReadBytes('\n')
+	assert.Equal(client.t, err, error(nil))
+
+	msg := new(testStratumMessage)
+	assert.Equal(client.t, json.Unmarshal(line, msg), error(nil))
+	return msg
+}
+
+// call sends the request and returns the response, and the error code if any.
+func (client *testStratumClient) call(method string, params ...interface{}) (json.RawMessage, int) {
+	client.nextID++
+	request, _ := json.Marshal(map[string]interface{}{"id": client.nextID, "method": method, "params": params})
+	_, err := client.conn.Write(append(request, '\n'))
+	assert.Equal(client.t, err, error(nil))
+
+	response := client.read()
+	assert.Equal(client.t, *response.ID, client.nextID)
+	if response.Error != nil {
+		return nil, int(response.Error[0].(float64))
+	}
+
+	return response.Result, 0
+}
+
+func Test_StratumServer(t *testing.T) {
+	_, err := NewStratumServer(&Miner{}, StratumConfig{}, logger)
+	assert.Equal(t, err, ErrShareDifficultyInvalid)
+
+	miner, server, client := newTestStratumServer(t, 1)
+	defer server.Stop()
+
+	_, code := client.call("mining.authorize", "account.rig", "")
+	assert.Equal(t, code, errStratumNotSubscribed.code)
+
+	_, code = client.call("mining.subscribe")
+	assert.Equal(t, code, 0)
+
+	msg := client.read()
+	assert.Equal(t, msg.Method, "mining.set_difficulty")
+	assert.Equal(t, msg.Params[0], float64(1))
+
+	// publish the job of the new task
+	miner.setCurrentTask(getTask(1))
+	msg = client.read()
+	assert.Equal(t, msg.Method, "mining.notify")
+	jobID := msg.Params[0].(string)
+
+	result, code := client.call("mining.authorize", "account.rig", "")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, string(result), "true")
+
+	_, code = client.call("mining.submit", "other.rig", jobID, "0x0")
+	assert.Equal(t, code, errStratumUnauthorized.code)
+
+	_, code = client.call("mining.submit", "account.rig", "0x01", "0x0")
+	assert.Equal(t, code, errStratumJobNotFound.code)
+
+	// any nonce meets the block target of difficulty 1
+	_, code = client.call("mining.submit", "account.rig", jobID, "0x0")
+	assert.Equal(t, code, 0)
+
+	found := <-miner.recv
+	assert.Equal(t, found.block.Header.Nonce, uint64(0))
+
+	_, code = client.call("mining.submit", "account.rig", jobID, "0x0")
+	assert.Equal(t, code, errStratumDuplicate.code)
+
+	stats := server.Stats()
+	assert.Equal(t, len(stats), 1)
+	assert.Equal(t, stats[0].Worker, "account.rig")
+	assert.Equal(t, stats[0].Accepted, uint64(1))
+	assert.Equal(t, stats[0].Rejected, uint64(1))
+	assert.Equal(t, stats[0].Stale, uint64(1))
+	assert.Equal(t, stats[0].Blocks, uint64(1))
+}
+
+func Test_StratumServer_LowDifficulty(t *testing.T) {
+	miner, server, client := newTestStratumServer(t, 1<<62)
+	defer server.Stop()
+
+	client.call("mining.subscribe")
+	client.read()
+
+	miner.setCurrentTask(getTask(1 << 62))
+	jobID := client.read().Params[0].(string)
+
+	client.call("mining.authorize", "account.rig", "")
+
+	// the hash of nonce 0 is unlikely to meet the target of 2^194
+	_, code := client.call("mining.submit", "account.rig", jobID, "0x0")
+	assert.Equal(t, code, errStratumLowDifficulty.code)
+	assert.Equal(t, server.Stats()[0].Rejected, uint64(1))
+}
+
+func Test_StratumServer_MaxWorkers(t *testing.T) {
+	_, server, client := newTestStratumServer(t, 1)
+	defer server.Stop()
+
+	client.call("mining.subscribe")
+	client.read()
+
+	for i := 0; i < stratumMaxSessionWorkers; i++ {
+		_, code := client.call("mining.authorize", fmt.Sprintf("account.rig%d", i), "")
+		assert.Equal(t, code, 0)
+	}
+
+	_, code := client.call("mining.authorize", "account.other", "")
+	assert.Equal(t, code, errStratumTooManyWorkers.code)
+
+	// the authorized worker is authorized again
+	_, code = client.call("mining.authorize", "account.rig0", "")
+	assert.Equal(t, code, 0)
+
+	// the stats of the disconnected worker of the earliest last share are evicted
+	server.lock.Lock()
+	for i := len(server.stats); i < stratumMaxWorkers; i++ {
+		worker := fmt.Sprintf("disconnected.rig%d", i)
+		server.stats[worker] = &WorkerStats{Worker: worker, LastShare: int64(i)}
+	}
+	server.lock.Unlock()
+
+	client2, err := net.Dial("tcp", server.Addr().String())
+	assert.Equal(t, err, error(nil))
+	other := &testStratumClient{t: t, conn: client2, reader: bufio.NewReader(client2)}
+	other.call("mining.subscribe")
+	other.read()
+
+	_, code = other.call("mining.authorize", "account.other", "")
+	assert.Equal(t, code, 0)
+	assert.Equal(t, len(server.Stats()), stratumMaxWorkers)
+
+	server.lock.Lock()
+	_, found := server.stats[fmt.Sprintf("disconnected.rig%d", stratumMaxSessionWorkers)]
+	server.lock.Unlock()
+	assert.Equal(t, found, false)
+
+	// no stats to evict once all the workers are connected
+	server.lock.Lock()
+	connected := &stratumSession{workers: make(map[string]bool)}
+	for worker := range server.stats {
+		connected.workers[worker] = true
+	}
+	server.sessions[connected] = struct{}{}
+	server.lock.Unlock()
+
+	_, code = other.call("mining.authorize", "account.another", "")
+	assert.Equal(t, code, errStratumTooManyWorkers.code)
+
+	server.lock.Lock()
+	delete(server.sessions, connected)
+	server.lock.Unlock()
+}
+
+func Test_StratumServer_MaxJobShares(t *testing.T) {
+	miner, server, client := newTestStratumServer(t, 1)
+	defer server.Stop()
+
+	client.call("mining.subscribe")
+	client.read()
+
+	miner.setCurrentTask(getTask(1 << 62))
+	jobID := client.read().Params[0].(string)
+	client.call("mining.authorize", "account.rig", "")
+
+	server.lock.Lock()
+	for nonce := uint64(1); nonce <= stratumMaxJobShares; nonce++ {
+		server.job.nonces[nonce] = true
+	}
+	server.lock.Unlock()
+
+	// the hash of nonce 0 is unlikely to meet the block target of 2^194
+	_, code := client.call("mining.submit", "account.rig", jobID, "0x0")
+	assert.Equal(t, code, errStratumJobFull.code)
+
+	server.lock.Lock()
+	assert.Equal(t, len(server.job.nonces), stratumMaxJobShares)
+	server.lock.Unlock()
+}
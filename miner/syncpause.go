@@ -0,0 +1,73 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"sync/atomic"
+
+	"github.com/seeleteam/go-seele/event"
+)
+
+// DefaultSyncPauseBlocks is the default number of blocks behind the sync target to pause the mining.
+const DefaultSyncPauseBlocks = 16
+
+// Status is the mining state of the miner.
+type Status struct {
+	Mining          bool   `json:"mining"`
+	PausedForSync   bool   `json:"pausedForSync"`   // PausedForSync is true if the miner can not start until the chain sync completes
+	BlocksBehind    uint64 `json:"blocksBehind"`    // BlocksBehind is the number of blocks behind the sync target when paused
+	SyncPauseBlocks uint64 `json:"syncPauseBlocks"` // SyncPauseBlocks is the number of blocks behind to pause the mining
+}
+
+// SetSyncPauseBlocks sets the number of blocks behind the sync target to pause the mining,
+// which is resumed once the sync completes.
+func (miner *Miner) SetSyncPauseBlocks(blocks uint64) {
+	atomic.StoreUint64(&miner.syncPauseBlocks, blocks)
+}
+
+// Status returns the mining state of the miner.
+func (miner *Miner) Status() *Status {
+	status := &Status{
+		Mining:          miner.IsMining(),
+		PausedForSync:   atomic.LoadInt32(&miner.canStart) == 0,
+		SyncPauseBlocks: atomic.LoadUint64(&miner.syncPauseBlocks),
+	}
+
+	if status.PausedForSync {
+		status.BlocksBehind = atomic.LoadUint64(&miner.blocksBehind)
+	}
+
+	return status
+}
+
+// pauseForSync pauses the mining if the chain is too far behind the sync target, since the
+// blocks mined meanwhile are likely to be orphans.
+func (miner *Miner) pauseForSync(e *event.DownloaderBehindEvent) {
+	if e.Blocks <= atomic.LoadUint64(&miner.syncPauseBlocks) {
+		return
+	}
+
+	atomic.StoreUint64(&miner.blocksBehind, e.Blocks)
+	atomic.StoreInt32(&miner.canStart, 0)
+
+	if miner.IsMining() {
+		miner.log.Info("pausing the miner during the sync, %d blocks behind", e.Blocks)
+		atomic.StoreInt32(&miner.syncPaused, 1)
+		miner.Stop()
+	}
+}
+
+// resumeAfterSync allows to start the miner once the sync completes, and resumes the mining
+// if paused by the sync.
+func (miner *Miner) resumeAfterSync() {
+	atomic.StoreUint64(&miner.blocksBehind, 0)
+	atomic.StoreInt32(&miner.canStart, 1)
+
+	if atomic.CompareAndSwapInt32(&miner.syncPaused, 1, 0) {
+		miner.log.Info("resuming the miner after the sync")
+		miner.start()
+	}
+}
@@ -0,0 +1,54 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/event"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+func Test_Miner_SyncPause(t *testing.T) {
+	chain := testutil.NewChain(t, testutil.Alloc(big.NewInt(1000000), testutil.NewAccount()))
+	defer chain.Close()
+
+	pool := core.NewTransactionPool(*core.DefaultTxPoolConfig(), chain.Blockchain())
+	defer pool.Stop()
+
+	miner := &Miner{
+		canStart:        1,
+		seele:           &testBackend{chain.Blockchain(), pool, chain.Coinbase().Address},
+		stopChan:        make(chan struct{}, 1),
+		recv:            make(chan *Result, 1),
+		isNonceFound:    new(int32),
+		hashrate:        newHashrateMeter(),
+		log:             logger,
+		syncPauseBlocks: DefaultSyncPauseBlocks,
+	}
+	assert.Equal(t, miner.Start(-1), error(nil))
+
+	// the mining is not paused if slightly behind
+	miner.downloadEventCallback(&event.DownloaderBehindEvent{Blocks: DefaultSyncPauseBlocks})
+	assert.Equal(t, *miner.Status(), Status{Mining: true, SyncPauseBlocks: DefaultSyncPauseBlocks})
+
+	// the mining is paused if far behind until the sync completes
+	miner.downloadEventCallback(&event.DownloaderBehindEvent{Blocks: 100})
+	assert.Equal(t, *miner.Status(), Status{PausedForSync: true, BlocksBehind: 100, SyncPauseBlocks: DefaultSyncPauseBlocks})
+	assert.Equal(t, miner.Start(-1), ErrNodeIsSyncing)
+
+	miner.downloadEventCallback(event.DownloaderDoneEvent)
+	assert.Equal(t, *miner.Status(), Status{Mining: true, SyncPauseBlocks: DefaultSyncPauseBlocks})
+
+	// the stopped miner is not resumed after the sync
+	miner.Stop()
+	miner.downloadEventCallback(&event.DownloaderBehindEvent{Blocks: 100})
+	miner.downloadEventCallback(event.DownloaderFailedEvent)
+	assert.Equal(t, miner.IsMining(), false)
+}
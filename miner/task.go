@@ -14,50 +14,152 @@ import (
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/crypto"
 	"github.com/seeleteam/go-seele/log"
-	"github.com/seeleteam/go-seele/miner/pow"
 )
 
+// blockSizeReserve is the block size reserved for the header fields set after the txs are
+// packed, e.g. the nonce, and the growth of the RLP list prefixes.
+const blockSizeReserve = 64
+
 // Task is a mining work for engine, containing block header, transactions, and transaction receipts.
 type Task struct {
-	header *types.BlockHeader
-	txs    []*types.Transaction
+	header        *types.BlockHeader
+	txs           []*types.Transaction
+	receipts      []*types.Receipt
+	rewardExtra   []byte   // rewardExtra is the extra data committed in the reward tx
+	coinbaseExtra []byte   // coinbaseExtra is the coinbase extra data of the reward tx, e.g. the pool tag
+	simulation    bool     // simulation keeps the invalid txs in the pool, e.g. to build a block template
+	minGasPrice   *big.Int // minGasPrice is the lowest gas price of the txs packed, nil if none
+	pow           bool     // pow indicates the block is sealed with the nonce of the header hash, see Task.Work
 
 	createdAt time.Time
 }
 
-// applyTransactions TODO need to check more about the transactions, such as gas limit
+// applyTransactions applies the txs of senders on the statedb and packs the succeeded ones into
+// the task, the fees of which are paid to the coinbase. The txs of a sender are applied in nonce
+// order, and the senders take turns by the fee per byte, see txsByFeeAndNonce.
 func (task *Task) applyTransactions(seele SeeleBackend, statedb *state.Statedb, blockHeight uint64,
-	txs []*types.Transaction, log *log.SeeleLog) error {
+	accountTxs map[common.Address][]*types.Transaction, log *log.SeeleLog) error {
 	// the reward tx will always be at the first of the block's transactions
-	rewardValue := big.NewInt(pow.GetReward(blockHeight))
-	reward := types.NewTransaction(common.Address{}, seele.GetCoinbase(), rewardValue, 0)
+	engine := seele.BlockChain().Engine()
+	medianTime, err := seele.BlockChain().MedianTimePast(task.header.PreviousBlockHash)
+	if err != nil {
+		return err
+	}
+
+	rewardValue := engine.BlockReward(blockHeight)
+	reward := types.NewRewardTransaction(seele.GetCoinbase(), rewardValue, task.rewardExtra)
+	if err := reward.SetCoinbaseExtra(task.coinbaseExtra); err != nil {
+		return err
+	}
 	reward.Signature = &crypto.Signature{}
 	stateObj := statedb.GetOrNewStateObject(seele.GetCoinbase())
 	stateObj.AddAmount(rewardValue)
 	task.txs = append(task.txs, reward)
 
-	for _, tx := range txs {
-		seele.TxPool().RemoveTransaction(tx.Hash)
+	// the engine applies the rewards of the consensus, e.g. the ommer rewards of POW
+	engine.Finalize(statedb, task.header)
+
+	// the block size is accumulated with the txs packed, which is limited by the chain config
+	maxBlockSize := seele.BlockChain().ChainConfig().MaxBlockSize
+	blockSize := task.generateBlock().Size() + blockSizeReserve
+
+	txs := newTxsByFeeAndNonce(accountTxs)
+	for tx, txSize := txs.peek(); tx != nil; tx, txSize = txs.peek() {
+		// the later txs of the sender are not packed either, while smaller txs of others may fit
+		if blockSize+txSize > maxBlockSize {
+			log.Debug("tx not packed for now, for block size limit")
+			txs.pop()
+
+			// the block is full once none of the remaining txs fits
+			if minSize := txs.minSize(); minSize == 0 || blockSize+minSize > maxBlockSize {
+				break
+			}
+
+			continue
+		}
+
+		if tx.IsExpired(blockHeight, medianTime) {
+			task.removeTransaction(seele, tx.Hash)
+			log.Info("tx %s expired, dropped", tx.Hash.ToHex())
+			txs.shift()
+			continue
+		}
+
+		// the HTLC claim could not be packed since the time lock
+		if err := tx.ValidateHTLC(blockHeight); err != nil {
+			task.removeTransaction(seele, tx.Hash)
+			log.Info("HTLC tx %s dropped, for %s", tx.Hash.ToHex(), err.Error())
+			txs.shift()
+			continue
+		}
+
+		// the tx is already packed in the chain, but not removed from the pool yet
+		if tx.Data.AccountNonce < statedb.GetNonce(tx.Data.From) {
+			task.removeTransaction(seele, tx.Hash)
+			log.Debug("tx %s already packed, dropped", tx.Hash.ToHex())
+			txs.shift()
+			continue
+		}
+
+		// the later txs of the sender wait for the missing nonce in the next blocks
+		if tx.Data.AccountNonce > statedb.GetNonce(tx.Data.From) {
+			log.Debug("tx not packed for now, for nonce gap, expected %d, got %d", statedb.GetNonce(tx.Data.From), tx.Data.AccountNonce)
+			txs.pop()
+			continue
+		}
+
+		// the tx is kept in the pool if it may become valid later, e.g. the sender balance is deposited
+		err := tx.Validate(statedb, seele.BlockChain().ChainConfig())
+		if err != nil && types.IsRecoverable(err) {
+			log.Debug("tx not packed for now, for %s", err.Error())
+			txs.pop()
+			continue
+		}
 
-		err := tx.Validate(statedb)
+		// the packed txs are kept in the pool until the block is inserted, so that the task could be rebuilt
 		if err != nil {
+			task.removeTransaction(seele, tx.Hash)
 			log.Error("validating tx failed, for %s", err.Error())
+			txs.shift()
 			continue
 		}
 
-		seele.BlockChain().ApplyTransaction(tx, seele.GetCoinbase(), statedb, task.header)
+		snapshot := statedb.Snapshot()
+		receipt, err := seele.BlockChain().ApplyTransaction(tx, seele.GetCoinbase(), statedb, task.header)
+		if err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			log.Error("applying tx failed, for %s", err.Error())
+			txs.shift()
+			continue
+		}
 
 		task.txs = append(task.txs, tx)
+		task.receipts = append(task.receipts, receipt)
+		if task.minGasPrice == nil || tx.Data.GasPrice.Cmp(task.minGasPrice) < 0 {
+			task.minGasPrice = tx.Data.GasPrice
+		}
+		blockSize += txSize
+		txs.shift()
 	}
 
-	log.Info("mining block height:%d, reward:%s, transaction number:%d", blockHeight, rewardValue, len(task.txs))
+	log.Info("mining block height:%d, reward:%s, ommers:%d, transaction number:%d", blockHeight, rewardValue,
+		len(task.header.Ommers), len(task.txs))
 
 	root := statedb.Commit(nil)
 	task.header.StateHash = root
+	task.header.ReceiptHash = types.ReceiptMerkleRootHash(task.receipts)
+	task.header.LogsBloom = types.CreateBloom(task.receipts)
 
 	return nil
 }
 
+// removeTransaction removes the invalid or already packed tx from the pool unless simulating.
+func (task *Task) removeTransaction(seele SeeleBackend, hash common.Hash) {
+	if !task.simulation {
+		seele.TxPool().RemoveTransaction(hash)
+	}
+}
+
 // generateBlock builds a block from task
 func (task *Task) generateBlock() *types.Block {
 	return types.NewBlock(task.header, task.txs)
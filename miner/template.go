@@ -0,0 +1,107 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// TemplateOptions is the options to build the block template.
+type TemplateOptions struct {
+	Timestamp uint64 `json:"timestamp"` // Timestamp is the creation time of the block, 0 for the current time
+}
+
+// BlockTemplate is the block the miner would build upon the HEAD block right now, which
+// is computed exactly like the mining task, but neither mined nor removing the txs from
+// the pool.
+type BlockTemplate struct {
+	Height      uint64        `json:"height"`      // Height is the height of the block
+	ParentHash  common.Hash   `json:"parentHash"`  // ParentHash is the hash of the HEAD block
+	Timestamp   uint64        `json:"timestamp"`   // Timestamp is the creation time of the block
+	Difficulty  *big.Int      `json:"difficulty"`  // Difficulty is the difficulty of the block
+	Ommers      int           `json:"ommers"`      // Ommers is the number of ommers referenced
+	Txs         []common.Hash `json:"txs"`         // Txs are the packed txs in order, excluding the reward tx
+	Reward      *big.Int      `json:"reward"`      // Reward is the block reward paid in the reward tx
+	OmmerBonus  *big.Int      `json:"ommerBonus"`  // OmmerBonus is the bonus of referencing the ommers
+	Fees        *big.Int      `json:"fees"`        // Fees is the sum of the fees of the packed txs
+	GasUsed     uint64        `json:"gasUsed"`     // GasUsed is the sum of the gas used by the packed txs
+	Size        int           `json:"size"`        // Size is the encoded size of the block
+	StateRoot   common.Hash   `json:"stateRoot"`   // StateRoot is the state root after the block is applied
+	TxRoot      common.Hash   `json:"txRoot"`      // TxRoot is the merkle root of the txs
+	ReceiptRoot common.Hash   `json:"receiptRoot"` // ReceiptRoot is the merkle root of the receipts
+
+	Block *types.Block `json:"-"` // Block is the would-be block whose nonce is not found yet
+}
+
+// BuildBlockTemplate builds the block the miner would mine upon the HEAD block with the
+// processable txs in the pool, without mining it.
+func (miner *Miner) BuildBlockTemplate(options *TemplateOptions) (*BlockTemplate, error) {
+	parent, stateDB := miner.seele.BlockChain().CurrentBlock()
+
+	timestamp := time.Now().Unix()
+	if options != nil && options.Timestamp > 0 {
+		timestamp = int64(options.Timestamp)
+	}
+
+	if parent.Header.CreateTimestamp.Cmp(big.NewInt(timestamp)) >= 0 {
+		timestamp = parent.Header.CreateTimestamp.Int64() + 1
+	}
+
+	task, err := miner.newTask(parent, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	task.simulation = true
+
+	statedb, err := stateDB.GetCopy()
+	if err != nil {
+		return nil, err
+	}
+
+	txs := miner.seele.TxPool().GetProcessableTransactions()
+	if err = task.applyTransactions(miner.seele, statedb, task.header.Height, txs, miner.log); err != nil {
+		return nil, err
+	}
+
+	block := task.generateBlock()
+	rewards := miner.seele.BlockChain().RewardConfig()
+	bonus := rewards.GetOmmerInclusionReward(block.Header.Height) * int64(len(block.Header.Ommers))
+
+	template := &BlockTemplate{
+		Height:      block.Header.Height,
+		ParentHash:  block.Header.PreviousBlockHash,
+		Timestamp:   block.Header.CreateTimestamp.Uint64(),
+		Difficulty:  block.Header.Difficulty,
+		Ommers:      len(block.Header.Ommers),
+		Txs:         make([]common.Hash, 0, len(block.Transactions)),
+		Reward:      block.Transactions[0].Data.Amount,
+		OmmerBonus:  big.NewInt(bonus),
+		Fees:        new(big.Int),
+		Size:        block.Size(),
+		StateRoot:   block.Header.StateHash,
+		TxRoot:      block.Header.TxHash,
+		ReceiptRoot: block.Header.ReceiptHash,
+		Block:       block,
+	}
+
+	for _, tx := range block.Transactions[1:] {
+		template.Txs = append(template.Txs, tx.Hash)
+	}
+
+	for _, receipt := range task.receipts {
+		if receipt.Fee != nil {
+			template.Fees.Add(template.Fees, receipt.Fee)
+		}
+
+		template.GasUsed += receipt.UsedGas
+	}
+
+	return template, nil
+}
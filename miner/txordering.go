@@ -0,0 +1,128 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"bytes"
+	"container/heap"
+	"math/big"
+	"sort"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// txEntry is a tx along with its min fee and encoded size, which are calculated once. The min fee,
+// i.e. GasPrice * TransferGas, is the fee the tx certainly pays, so that a tx could not jump ahead by
+// raising the gas limit without paying more.
+type txEntry struct {
+	tx   *types.Transaction
+	fee  *big.Int
+	size int
+}
+
+func newTxEntry(tx *types.Transaction) *txEntry {
+	return &txEntry{tx, tx.MinFee(), tx.Size()}
+}
+
+// txHeap is a max heap of the next txs of senders by fee per byte, and the tx hash breaks
+// the tie so that the order is deterministic.
+type txHeap []*txEntry
+
+func (h txHeap) Len() int      { return len(h) }
+func (h txHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h txHeap) Less(i, j int) bool {
+	// fee_i / size_i > fee_j / size_j, compared without division
+	rateI := new(big.Int).Mul(h[i].fee, big.NewInt(int64(h[j].size)))
+	rateJ := new(big.Int).Mul(h[j].fee, big.NewInt(int64(h[i].size)))
+	if cmp := rateI.Cmp(rateJ); cmp != 0 {
+		return cmp > 0
+	}
+
+	return bytes.Compare(h[i].tx.Hash.Bytes(), h[j].tx.Hash.Bytes()) < 0
+}
+
+func (h *txHeap) Push(x interface{}) { *h = append(*h, x.(*txEntry)) }
+
+func (h *txHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	*h = old[:n-1]
+	return entry
+}
+
+// txsByFeeAndNonce orders the txs to pack into a block, in which the txs of each sender
+// are in nonce order, and the senders take turns by the fee per byte of their next tx, so
+// that the limited block size earns the most fees. A tx is never tried before the
+// lower-nonce txs of the same sender it depends on.
+type txsByFeeAndNonce struct {
+	queues map[common.Address][]*types.Transaction // sender => txs after the head in nonce order
+	heads  txHeap                                  // next tx of each sender
+}
+
+func newTxsByFeeAndNonce(accountTxs map[common.Address][]*types.Transaction) *txsByFeeAndNonce {
+	txs := &txsByFeeAndNonce{
+		queues: make(map[common.Address][]*types.Transaction),
+	}
+
+	for account, accTxs := range accountTxs {
+		if len(accTxs) == 0 {
+			continue
+		}
+
+		sorted := append([]*types.Transaction{}, accTxs...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Data.AccountNonce < sorted[j].Data.AccountNonce })
+
+		txs.heads = append(txs.heads, newTxEntry(sorted[0]))
+		txs.queues[account] = sorted[1:]
+	}
+
+	heap.Init(&txs.heads)
+
+	return txs
+}
+
+// peek returns the next tx to pack along with its encoded size, or nil if no more txs.
+func (txs *txsByFeeAndNonce) peek() (*types.Transaction, int) {
+	if len(txs.heads) == 0 {
+		return nil, 0
+	}
+
+	return txs.heads[0].tx, txs.heads[0].size
+}
+
+// shift replaces the next tx with the next one of the same sender, e.g. the tx is packed.
+func (txs *txsByFeeAndNonce) shift() {
+	from := txs.heads[0].tx.Data.From
+	if queue := txs.queues[from]; len(queue) > 0 {
+		txs.heads[0], txs.queues[from] = newTxEntry(queue[0]), queue[1:]
+		heap.Fix(&txs.heads, 0)
+		return
+	}
+
+	txs.pop()
+}
+
+// pop removes the next tx along with the remaining txs of the same sender, e.g. the
+// later txs could not be packed once the tx is not.
+func (txs *txsByFeeAndNonce) pop() {
+	delete(txs.queues, txs.heads[0].tx.Data.From)
+	heap.Pop(&txs.heads)
+}
+
+// minSize returns the smallest encoded size of the next txs of senders, or 0 if no more txs.
+func (txs *txsByFeeAndNonce) minSize() int {
+	size := 0
+	for i, entry := range txs.heads {
+		if i == 0 || entry.size < size {
+			size = entry.size
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,107 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func newOrderingTestTx(from common.Address, nonce, price uint64) *types.Transaction {
+	return newOrderingTestTxWithPayload(from, nonce, price, nil)
+}
+
+func newOrderingTestTxWithPayload(from common.Address, nonce, price uint64, payload []byte) *types.Transaction {
+	return &types.Transaction{
+		Hash: common.StringToHash(from.ToHex() + string(rune(nonce))),
+		Data: &types.TransactionData{
+			From:         from,
+			AccountNonce: nonce,
+			GasPrice:     new(big.Int).SetUint64(price),
+			GasLimit:     1000,
+			Payload:      payload,
+		},
+	}
+}
+
+func Test_TxsByFeeAndNonce(t *testing.T) {
+	a, b := common.BytesToAddress([]byte{1}), common.BytesToAddress([]byte{2})
+
+	// the later nonce of a with a higher price waits for the lower nonce
+	txs := newTxsByFeeAndNonce(map[common.Address][]*types.Transaction{
+		a: {newOrderingTestTx(a, 5, 10), newOrderingTestTx(a, 4, 2)},
+		b: {newOrderingTestTx(b, 0, 5), newOrderingTestTx(b, 1, 1)},
+	})
+
+	var order []string
+	for tx, _ := txs.peek(); tx != nil; tx, _ = txs.peek() {
+		order = append(order, string([]byte{tx.Data.From[63] + '0', byte(tx.Data.AccountNonce) + '0'}))
+		txs.shift()
+	}
+
+	assert.Equal(t, order, []string{"20", "14", "15", "21"})
+
+	// popping drops the remaining txs of the sender
+	txs = newTxsByFeeAndNonce(map[common.Address][]*types.Transaction{
+		a: {newOrderingTestTx(a, 4, 10), newOrderingTestTx(a, 5, 10)},
+		b: {newOrderingTestTx(b, 0, 5)},
+	})
+
+	txs.pop()
+	tx, _ := txs.peek()
+	assert.Equal(t, tx.Data.From, b)
+	txs.shift()
+	tx, size := txs.peek()
+	assert.Equal(t, tx == nil, true)
+	assert.Equal(t, size, 0)
+	assert.Equal(t, txs.minSize(), 0)
+}
+
+func Test_TxsByFeeAndNonce_FeePerByte(t *testing.T) {
+	a, b := common.BytesToAddress([]byte{1}), common.BytesToAddress([]byte{2})
+
+	// a pays a higher gas price, but less per byte for the large payload
+	large := newOrderingTestTxWithPayload(a, 0, 10, make([]byte, 4096))
+	small := newOrderingTestTx(b, 0, 8)
+	txs := newTxsByFeeAndNonce(map[common.Address][]*types.Transaction{
+		a: {large},
+		b: {small},
+	})
+
+	tx, size := txs.peek()
+	assert.Equal(t, tx, small)
+	assert.Equal(t, size, small.Size())
+	assert.Equal(t, txs.minSize(), small.Size())
+
+	txs.shift()
+	tx, size = txs.peek()
+	assert.Equal(t, tx, large)
+	assert.Equal(t, size, large.Size())
+	assert.Equal(t, txs.minSize(), large.Size())
+}
+
+func Test_TxsByFeeAndNonce_GasLimit(t *testing.T) {
+	a, b := common.BytesToAddress([]byte{1}), common.BytesToAddress([]byte{2})
+
+	// a raises the gas limit at a lower price, which is not charged for a transfer
+	inflated := newOrderingTestTx(a, 0, 5)
+	inflated.Data.GasLimit = 1000 * types.TransferGas
+	txs := newTxsByFeeAndNonce(map[common.Address][]*types.Transaction{
+		a: {inflated},
+		b: {newOrderingTestTx(b, 0, 8)},
+	})
+
+	tx, _ := txs.peek()
+	assert.Equal(t, tx.Data.From, b)
+
+	txs.shift()
+	tx, _ = txs.peek()
+	assert.Equal(t, tx, inflated)
+}
@@ -0,0 +1,93 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/miner/pow"
+)
+
+var (
+	// ErrNoWork is returned when the miner is not mining any block.
+	ErrNoWork = errors.New("no mining work")
+
+	// ErrWorkStale is returned when the submitted work is not the block being mined.
+	ErrWorkStale = errors.New("stale mining work")
+
+	// ErrNonceInvalid is returned when the submitted nonce does not meet the target.
+	ErrNonceInvalid = errors.New("invalid nonce")
+)
+
+// Work is the block being mined for the external miners, which should find a nonce so that
+// the hash of the header with the nonce is not larger than the target.
+type Work struct {
+	ID         common.Hash `json:"id"`         // ID is the header hash with zero nonce, which identifies the work
+	Header     string      `json:"header"`     // Header is the RLP encoded header with zero nonce in hex
+	Height     uint64      `json:"height"`     // Height is the height of the block
+	Difficulty *big.Int    `json:"difficulty"` // Difficulty is the difficulty of the block
+	Target     *big.Int    `json:"target"`     // Target is the mining target, i.e. 2^256 / difficulty
+}
+
+// GetWork returns the block being mined for the external miners, or ErrNoWork if the block
+// is not sealed with the nonce of the header hash, e.g. of the memhash POW or BFT.
+func (miner *Miner) GetWork() (*Work, error) {
+	task := miner.currentTask()
+	if task == nil || !miner.IsMining() {
+		return nil, ErrNoWork
+	}
+
+	return task.Work()
+}
+
+// newWork returns the work to find the nonce of the block, whose nonce should be zero.
+func newWork(block *types.Block) (*Work, error) {
+	encoded, err := common.Serialize(block.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Work{
+		ID:         block.HeaderHash,
+		Header:     hexutil.BytesToHex(encoded),
+		Height:     block.Header.Height,
+		Difficulty: block.Header.Difficulty,
+		Target:     pow.GetMiningTarget(block.Header.Difficulty),
+	}, nil
+}
+
+// SubmitWork submits the nonce found by the external miners for the work of the specified id,
+// and the block is saved and broadcast as if it is mined by the miner. It returns ErrNoWork if
+// the block is not sealed with the nonce of the header hash.
+func (miner *Miner) SubmitWork(id common.Hash, nonce uint64) error {
+	task := miner.currentTask()
+	if task == nil || !task.pow || !miner.IsMining() {
+		return ErrNoWork
+	}
+
+	if !task.generateBlock().HeaderHash.Equal(id) {
+		return ErrWorkStale
+	}
+
+	result, err := task.Result(nonce)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-miner.stopChan:
+		return ErrNoWork
+	case miner.recv <- result:
+		// stop the in-process mining threads of the task
+		atomic.StoreInt32(miner.isNonceFound, 1)
+		return nil
+	}
+}
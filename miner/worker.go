@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/seeleteam/go-seele/common/resource"
+)
+
+// workerGroup is the mining threads of a task, which are aborted together once a new task arrives.
+type workerGroup struct {
+	abort chan struct{}
+	wg    sync.WaitGroup
+}
+
+// stop aborts the mining threads and waits for them to quit.
+func (group *workerGroup) stop() {
+	close(group.abort)
+	group.wg.Wait()
+}
+
+// nonceRange returns the nonce range [min, max] of the i-th of the specified number of threads,
+// in which the nonce space is partitioned evenly, and the last range ends at math.MaxUint64.
+func nonceRange(i, threads int) (min uint64, max uint64) {
+	step := math.MaxUint64 / uint64(threads)
+	min = uint64(i) * step
+
+	if i != threads-1 {
+		max = min + step - 1
+	} else {
+		max = math.MaxUint64
+	}
+
+	return min, max
+}
+
+// startWorkers restarts the mining threads on the specified task, in which the previous ones are
+// aborted first. Each thread searches a partition of the nonce space from a random seed.
+func (miner *Miner) startWorkers(task *Task, threads int) {
+	miner.workersLock.Lock()
+	defer miner.workersLock.Unlock()
+
+	if miner.workers != nil {
+		miner.workers.stop()
+	}
+
+	group := &workerGroup{abort: make(chan struct{})}
+	miner.workers = group
+
+	atomic.StoreInt32(miner.isNonceFound, 0)
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < threads; i++ {
+		min, max := nonceRange(i, threads)
+		seed := min + r.Uint64()%(max-min)
+		meter := miner.hashrate.thread(i)
+		cpu := i % runtime.NumCPU()
+
+		group.wg.Add(1)
+		resource.Miner.Go(func() {
+			defer group.wg.Done()
+
+			if miner.affinity {
+				// the thread is kept pinned until the worker quits
+				runtime.LockOSThread()
+				defer runtime.UnlockOSThread()
+
+				if err := setAffinity(cpu); err != nil {
+					miner.log.Warn("failed to pin the mining thread to cpu %d, %s", cpu, err)
+				}
+			}
+
+			StartMining(task, seed, min, max, miner.recv, group.abort, miner.isNonceFound, meter, miner.log)
+		})
+	}
+}
+
+// stopWorkers aborts the mining threads if any.
+func (miner *Miner) stopWorkers() {
+	miner.workersLock.Lock()
+	defer miner.workersLock.Unlock()
+
+	if miner.workers != nil {
+		miner.workers.stop()
+		miner.workers = nil
+	}
+}
@@ -0,0 +1,51 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package miner
+
+import (
+	"math"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_NonceRange(t *testing.T) {
+	min, max := nonceRange(0, 1)
+	assert.Equal(t, min, uint64(0))
+	assert.Equal(t, max, uint64(math.MaxUint64))
+
+	var next uint64
+	for i := 0; i < 3; i++ {
+		min, max = nonceRange(i, 3)
+		assert.Equal(t, min, next)
+		assert.Equal(t, max > min, true)
+		next = max + 1
+	}
+
+	assert.Equal(t, max, uint64(math.MaxUint64))
+}
+
+func Test_Miner_StartWorkers(t *testing.T) {
+	miner := &Miner{
+		recv:         make(chan *Result, 1),
+		isNonceFound: new(int32),
+		hashrate:     newHashrateMeter(),
+		log:          logger,
+	}
+
+	// the nonce is unlikely to be found in the max difficulty
+	miner.startWorkers(getTask(math.MaxInt64), 2)
+	previous := miner.workers
+
+	// the workers of the previous task are aborted
+	miner.startWorkers(getTask(math.MaxInt64), 2)
+	_, ok := <-previous.abort
+	assert.Equal(t, ok, false)
+	assert.Equal(t, len(miner.hashrate.threads), 2)
+
+	miner.stopWorkers()
+	assert.Equal(t, miner.workers == nil, true)
+}
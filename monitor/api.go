@@ -8,6 +8,8 @@ package monitor
 import (
 	"errors"
 	"runtime"
+
+	"github.com/seeleteam/go-seele/common/resource"
 )
 
 // error infos
@@ -71,3 +73,9 @@ func (api *PublicMonitorAPI) NodeStats(arg int, result *NodeStats) error {
 
 	return nil
 }
+
+// SubsystemStats returns the resources used by the subsystems of the local node.
+func (api *PublicMonitorAPI) SubsystemStats(arg int, result *[]*resource.Stats) error {
+	*result = resource.AllStats()
+	return nil
+}
@@ -36,7 +36,7 @@ func createTestAPI() *PublicMonitorAPI {
 	testConf := node.Config{
 		Name:    "Node for test",
 		Version: "Test 1.0",
-		DataDir: "node1",
+		DataDir: common.GetTempFolder() + "/monitor1",
 		P2P: p2p.Config{
 			PrivateKey: key,
 			ListenAddr: "0.0.0.0:39007",
@@ -78,7 +78,7 @@ func createTestAPI() *PublicMonitorAPI {
 		return nil
 	}
 
-	seeleService.Miner().Start()
+	seeleService.Miner().Start(0)
 
 	return api
 }
@@ -93,7 +93,7 @@ func createTestAPIErr(errBranch int) *PublicMonitorAPI {
 		testConf = node.Config{
 			Name:    "Node for test2",
 			Version: "Test 1.0",
-			DataDir: "node1",
+			DataDir: common.GetTempFolder() + "/monitor2",
 			P2P: p2p.Config{
 				PrivateKey: key,
 				ListenAddr: "0.0.0.0:39008",
@@ -106,7 +106,7 @@ func createTestAPIErr(errBranch int) *PublicMonitorAPI {
 		testConf = node.Config{
 			Name:    "Node for test3",
 			Version: "Test 1.0",
-			DataDir: "node1",
+			DataDir: common.GetTempFolder() + "/monitor2",
 			P2P: p2p.Config{
 				PrivateKey: key,
 				ListenAddr: "0.0.0.0:39009",
@@ -147,7 +147,7 @@ func createTestAPIErr(errBranch int) *PublicMonitorAPI {
 	if errBranch != 1 {
 		seeleNode.Start()
 	} else {
-		seeleService.Miner().Start()
+		seeleService.Miner().Start(0)
 	}
 
 	return api
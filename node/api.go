@@ -0,0 +1,31 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package node
+
+import (
+	"github.com/seeleteam/go-seele/rpc"
+)
+
+// PublicCacheAPI provides an API to access the response caches of the HTTP rpc listeners.
+type PublicCacheAPI struct {
+	n *Node
+}
+
+// NewPublicCacheAPI creates a new PublicCacheAPI object for rpc service.
+func NewPublicCacheAPI(n *Node) *PublicCacheAPI {
+	return &PublicCacheAPI{n}
+}
+
+// GetStats gets the metrics of the response caches keyed by the listener address.
+func (api *PublicCacheAPI) GetStats(input interface{}, result *map[string]rpc.CacheStats) error {
+	stats := make(map[string]rpc.CacheStats)
+	for addr, cache := range api.n.caches {
+		stats[addr] = cache.Stats()
+	}
+
+	*result = stats
+	return nil
+}
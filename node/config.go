@@ -6,6 +6,9 @@
 package node
 
 import (
+	"time"
+
+	"github.com/seeleteam/go-seele/common/resource"
 	"github.com/seeleteam/go-seele/p2p"
 	"github.com/seeleteam/go-seele/seele"
 )
@@ -27,6 +30,17 @@ type Config struct {
 	// The RPCAddr is the address on which to start RPC server.
 	RPCAddr string
 
+	// AdminAddr is the address of the JSON rpc listener of the APIs not safe for public use, e.g. admin,
+	// which should be a loopback address, empty to disable. These APIs are never served on the other listeners.
+	AdminAddr string
+
+	// RPCStampBits is the proof-of-work stamp difficulty (leading zero bits) required
+	// by the RPC server for unauthenticated tx submission, 0 to disable.
+	RPCStampBits uint
+
+	// RPCRequestTimeout is the deadline of long-running RPC requests, e.g. range scans, 0 for no deadline.
+	RPCRequestTimeout time.Duration
+
 	// The HTTPAddr is the address of HTTP rpc service
 	HTTPAddr string
 
@@ -38,6 +52,62 @@ type Config struct {
 	// HTTPHostFilter is the whitelist of hostnames which are allowed on incoming requests.
 	HTTPWhiteHost []string
 
+	// HTTPStampBits is the proof-of-work stamp difficulty (leading zero bits) required
+	// by the HTTP rpc service for unauthenticated tx submission, 0 to disable.
+	HTTPStampBits uint
+
+	// HTTPCacheSize is the max bytes of the cached responses of the historical data
+	// by the HTTP rpc service, 0 to disable.
+	HTTPCacheSize int
+
+	// RPCCacheConfirmations is the number of confirmations of the data to cache its
+	// responses on the HTTP rpc listeners, rpc.DefaultCacheConfirmations if 0.
+	RPCCacheConfirmations uint64
+
+	// HTTPListeners are the additional HTTP rpc listeners with distinct policies,
+	// e.g. an internal listener with all APIs and a public read-only one.
+	HTTPListeners []HTTPListenerConfig
+
+	// SubsystemLimits are the soft limits of the subsystems by name, e.g. "p2p", "pool", "rpc"
+	// and "sync", which throttle themselves once any limit is reached.
+	SubsystemLimits map[string]resource.Limits
+
 	// The SeeleConfig is the configuration to create seele service.
 	SeeleConfig seele.Config
 }
+
+// HTTPListenerConfig is the configuration of a HTTP rpc listener.
+type HTTPListenerConfig struct {
+	// Addr is the address to listen on.
+	Addr string
+
+	// Namespaces are the API namespaces served by the listener, empty for all APIs.
+	Namespaces []string
+
+	// Cors is the Cross-Origin Resource Sharing header to send to requesting clients.
+	Cors []string
+
+	// WhiteHost is the whitelist of hostnames which are allowed on incoming requests.
+	WhiteHost []string
+
+	// TLSCertFile and TLSKeyFile are the certificate and key files to serve HTTPS, empty to serve HTTP.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// AuthToken is the bearer token required in the Authorization header, empty to disable.
+	AuthToken string
+
+	// RateLimit is the maximum requests per second of each client IP, 0 to disable.
+	RateLimit int
+
+	// APIKeys indicates whether the requests require an API key in the X-Api-Key header,
+	// which are authorized against the allowlists and quotas of the keys.
+	APIKeys bool
+
+	// StampBits is the proof-of-work stamp difficulty (leading zero bits) required
+	// for unauthenticated tx submission, 0 to disable.
+	StampBits uint
+
+	// CacheSize is the max bytes of the cached responses of the historical data, 0 to disable.
+	CacheSize int
+}
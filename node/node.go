@@ -10,11 +10,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	netrpc "net/rpc"
 	"reflect"
 	"sync"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/flock"
+	"github.com/seeleteam/go-seele/common/resource"
 	"github.com/seeleteam/go-seele/log"
 	"github.com/seeleteam/go-seele/p2p"
 	"github.com/seeleteam/go-seele/rpc"
@@ -28,6 +29,8 @@ var (
 	ErrNodeStopped        = errors.New("node is not started")
 	ErrServiceStartFailed = errors.New("node service start failed")
 	ErrServiceStopFailed  = errors.New("node service stop failed")
+	ErrAPIKeysUnavailable = errors.New("no service manages the API keys")
+	ErrAdminAddrPublic    = errors.New("admin rpc address is not a loopback address")
 )
 
 // StopError represents an error which is returned when a node fails to stop any registered service
@@ -50,6 +53,9 @@ type Node struct {
 	services []Service
 
 	rpcAPIs []rpc.API
+	caches  map[string]*rpc.ResponseCache // listener address => response cache of the HTTP rpc listeners
+
+	dirLock *flock.Lock // exclusive lock of the data folder, nil if no data folder
 
 	log  *log.SeeleLog
 	lock sync.RWMutex
@@ -61,13 +67,46 @@ func New(conf *Config) (*Node, error) {
 	conf = &confCopy
 	nlog := log.GetLogger("node", common.PrintLog)
 
+	if err := resource.SetLimits(conf.SubsystemLimits); err != nil {
+		return nil, err
+	}
+
+	// Lock the data folder to prevent another node from corrupting the database.
+	var dirLock *flock.Lock
+	if len(conf.DataDir) > 0 {
+		var err error
+		if dirLock, err = flock.New(conf.DataDir); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Node{
 		config:   conf,
 		services: []Service{},
+		dirLock:  dirLock,
 		log:      nlog,
 	}, nil
 }
 
+// Close releases the lock of the data folder, the node should be stopped first.
+func (n *Node) Close() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server != nil {
+		return ErrNodeRunning
+	}
+
+	if n.dirLock == nil {
+		return nil
+	}
+
+	err := n.dirLock.Release()
+	n.dirLock = nil
+
+	return err
+}
+
 // Register append a new service into the node's stack.
 func (n *Node) Register(service Service) error {
 	n.lock.Lock()
@@ -93,7 +132,11 @@ func (n *Node) Start() error {
 	n.serverConfig = n.config.P2P
 	running := &p2p.Server{Config: n.serverConfig}
 	for _, service := range n.services {
-		running.Protocols = append(running.Protocols, service.Protocols()...)
+		for _, proto := range service.Protocols() {
+			if err := running.RegisterProtocol(proto); err != nil {
+				return err
+			}
+		}
 	}
 
 	if err := running.Start(); err != nil {
@@ -133,26 +176,70 @@ func (n *Node) Start() error {
 
 // startRPC starts all RPC
 func (n *Node) startRPC(services []Service, conf *Config) error {
+	if len(conf.AdminAddr) > 0 && !isLoopbackAddr(conf.AdminAddr) {
+		return ErrAdminAddrPublic
+	}
+
 	apis := []rpc.API{}
 	for _, service := range services {
 		apis = append(apis, service.APIs()...)
 	}
 
-	if err := n.startJSONRPC(apis); err != nil {
+	n.caches = make(map[string]*rpc.ResponseCache)
+	apis = append(apis, rpc.API{
+		Namespace: "rpccache",
+		Version:   "1.0",
+		Service:   NewPublicCacheAPI(n),
+		Public:    true,
+	})
+
+	// the APIs not safe for public use are only served on the admin listener
+	public, private := splitAPIs(apis)
+	if err := n.startJSONRPC(public, conf.RPCAddr, conf.RPCStampBits); err != nil {
 		n.log.Error("startProc err", err)
 		return err
 	}
 
-	if err := n.startHTTPRPC(apis, conf.HTTPWhiteHost, conf.HTTPCors); err != nil {
-		n.log.Error("start http rpc err", err)
-		return err
+	listeners := append([]HTTPListenerConfig{{
+		Addr:      conf.HTTPAddr,
+		Cors:      conf.HTTPCors,
+		WhiteHost: conf.HTTPWhiteHost,
+		StampBits: conf.HTTPStampBits,
+		CacheSize: conf.HTTPCacheSize,
+	}}, conf.HTTPListeners...)
+
+	var keys rpc.KeyAuthorizer
+	var chain rpc.CacheChain
+	for _, service := range services {
+		if keyService, ok := service.(KeyService); ok {
+			keys = keyService.APIKeys()
+		}
+
+		if cacheService, ok := service.(CacheService); ok {
+			chain = cacheService.ResponseCacheChain()
+		}
+	}
+
+	for _, listener := range listeners {
+		if err := n.startHTTPRPC(public, listener, keys, chain); err != nil {
+			n.log.Error("start http rpc err", err)
+			return err
+		}
+	}
+
+	if len(conf.AdminAddr) > 0 {
+		if err := n.startJSONRPC(private, conf.AdminAddr, 0); err != nil {
+			n.log.Error("start admin rpc err %s", err)
+			return err
+		}
 	}
 
 	return nil
 }
 
-// startJSONRPC starts JSONRPC server
-func (n *Node) startJSONRPC(apis []rpc.API) error {
+// startJSONRPC starts JSONRPC server on the specified address, which requires the stamps of
+// the specified difficulty for the stamp methods.
+func (n *Node) startJSONRPC(apis []rpc.API, addr string, stampBits uint) error {
 	handler := rpc.NewServer()
 	for _, api := range apis {
 		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -167,12 +254,13 @@ func (n *Node) startJSONRPC(apis []rpc.API) error {
 		err       error
 	)
 
-	if listerner, err = net.Listen("tcp", n.config.RPCAddr); err != nil {
+	if listerner, err = net.Listen("tcp", addr); err != nil {
 		n.log.Error("Listen failed", "err", err)
 		return err
 	}
 
 	n.log.Debug("Listerner address %s", listerner.Addr().String())
+	stamp := newStampPolicy(stampBits, apis)
 	go func() {
 		for {
 			conn, err := listerner.Accept()
@@ -180,16 +268,46 @@ func (n *Node) startJSONRPC(apis []rpc.API) error {
 				n.log.Error("RPC accept failed", "err", err)
 				continue
 			}
-			go handler.ServeCodec(rpc.NewJsonCodec(conn))
+
+			if resource.RPC.Throttle() {
+				n.log.Warn("rpc subsystem throttled, refuse the connection from %s", conn.RemoteAddr())
+				conn.Close()
+				continue
+			}
+
+			resource.RPC.Go(func() {
+				handler.ServeCodec(rpc.NewJsonCodecWithConfig(conn, rpc.CodecConfig{
+					Stamp:   stamp,
+					Timeout: n.config.RPCRequestTimeout,
+				}))
+			})
 		}
 	}()
 
 	return nil
 }
 
-// startHTTPRPC starts http rpc server
-func (n *Node) startHTTPRPC(apis []rpc.API, whitehosts []string, corsList []string) error {
-	httpServer, httpHandler := rpc.NewHTTPServer(whitehosts, corsList)
+// startHTTPRPC starts http rpc server with the policies of the specified listener config,
+// and the specified authorizer is used if the listener requires API keys. The responses of
+// the historical data of the chain are cached if the listener cache is enabled.
+func (n *Node) startHTTPRPC(apis []rpc.API, conf HTTPListenerConfig, keys rpc.KeyAuthorizer, chain rpc.CacheChain) error {
+	apis = filterAPIs(apis, conf.Namespaces)
+
+	if conf.APIKeys && keys == nil {
+		return ErrAPIKeysUnavailable
+	}
+
+	httpServer, httpHandler := rpc.NewHTTPServer(conf.WhiteHost, conf.Cors)
+	httpServer.SetStampPolicy(newStampPolicy(conf.StampBits, apis))
+	httpServer.SetRequestTimeout(n.config.RPCRequestTimeout)
+	if conf.APIKeys {
+		httpServer.SetKeyAuthorizer(keys)
+	}
+	if cache := rpc.NewResponseCache(chain, conf.CacheSize, n.config.RPCCacheConfirmations); cache != nil {
+		cache.CacheAPIs(apis)
+		httpServer.SetResponseCache(cache)
+		n.caches[conf.Addr] = cache
+	}
 	for _, api := range apis {
 		if err := httpServer.RegisterName(api.Namespace, api.Service); err != nil {
 			n.log.Error("Api registered failed", "service", api.Service, "namespace", api.Namespace)
@@ -202,17 +320,80 @@ func (n *Node) startHTTPRPC(apis []rpc.API, whitehosts []string, corsList []stri
 		listerner net.Listener
 		err       error
 	)
-	httpServer.HandleHTTP(netrpc.DefaultRPCPath, netrpc.DefaultDebugPath)
-	if listerner, err = net.Listen("tcp", n.config.HTTPAddr); err != nil {
+	if listerner, err = net.Listen("tcp", conf.Addr); err != nil {
 		n.log.Error("HTTP listen failed", "err", err)
 		return err
 	}
 
-	go http.Serve(listerner, httpHandler)
+	handler := rpc.NewResourceHandler(resource.RPC, rpc.NewRateLimitHandler(conf.RateLimit, rpc.NewAuthHandler(conf.AuthToken, httpHandler)))
+	if len(conf.TLSCertFile) > 0 {
+		go http.ServeTLS(listerner, handler, conf.TLSCertFile, conf.TLSKeyFile)
+	} else {
+		go http.Serve(listerner, handler)
+	}
 
 	return nil
 }
 
+// filterAPIs returns the APIs of the specified namespaces, or all APIs if namespaces is empty.
+func filterAPIs(apis []rpc.API, namespaces []string) []rpc.API {
+	if len(namespaces) == 0 {
+		return apis
+	}
+
+	var result []rpc.API
+	for _, api := range apis {
+		for _, namespace := range namespaces {
+			if api.Namespace == namespace {
+				result = append(result, api)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// splitAPIs splits the APIs into the public ones, and the private ones not safe for public use, e.g.
+// admin, which are never served on the public JSON and HTTP rpc listeners.
+func splitAPIs(apis []rpc.API) (public, private []rpc.API) {
+	for _, api := range apis {
+		if api.Public {
+			public = append(public, api)
+		} else {
+			private = append(private, api)
+		}
+	}
+
+	return public, private
+}
+
+// isLoopbackAddr returns whether the host of the address is a loopback address, e.g. 127.0.0.1 or localhost.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newStampPolicy returns the stamp policy with the specified difficulty for
+// the stamp methods declared in apis, or nil if the difficulty is 0.
+func newStampPolicy(bits uint, apis []rpc.API) *rpc.StampPolicy {
+	policy := rpc.NewStampPolicy(bits)
+	if policy != nil {
+		policy.RequireAPIs(apis)
+	}
+
+	return policy
+}
+
 // Stop terminates the running the node and the services registered.
 func (n *Node) Stop() error {
 	n.lock.Lock()
@@ -6,11 +6,24 @@
 package node
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/rpc/jsonrpc"
+	"os"
 	"testing"
 
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
 	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/log"
 	"github.com/seeleteam/go-seele/p2p"
 	"github.com/seeleteam/go-seele/rpc"
+	"github.com/seeleteam/go-seele/seele"
 )
 
 var (
@@ -49,6 +62,22 @@ func (s TestServiceC) APIs() []rpc.API           { return nil }
 func (s TestServiceC) Start(*p2p.Server) error   { return nil }
 func (s TestServiceC) Stop() error               { return nil }
 
+// TestAPIService is a test implementation of the Service interface with the specified APIs.
+type TestAPIService struct{ apis []rpc.API }
+
+func (s TestAPIService) Protocols() []p2p.Protocol { return nil }
+func (s TestAPIService) APIs() []rpc.API           { return s.apis }
+func (s TestAPIService) Start(*p2p.Server) error   { return nil }
+func (s TestAPIService) Stop() error               { return nil }
+
+// TestAPI is a test API of both the public and private namespaces.
+type TestAPI struct{}
+
+func (api *TestAPI) Echo(input *string, result *string) error {
+	*result = *input
+	return nil
+}
+
 var testServiceA TestServiceA
 var testServiceB TestServiceB
 var testServiceC TestServiceC
@@ -72,3 +101,329 @@ func Test_ServiceRegistry(t *testing.T) {
 		t.Fatalf("failed to stop service stack: %v", err)
 	}
 }
+
+func Test_FilterAPIs(t *testing.T) {
+	apis := []rpc.API{{Namespace: "seele"}, {Namespace: "debug"}, {Namespace: "miner"}}
+
+	if len(filterAPIs(apis, nil)) != 3 {
+		t.Fatal("all APIs should be returned if namespaces is empty")
+	}
+
+	filtered := filterAPIs(apis, []string{"seele", "miner"})
+	if len(filtered) != 2 || filtered[0].Namespace != "seele" || filtered[1].Namespace != "miner" {
+		t.Fatalf("unexpected filtered APIs %v", filtered)
+	}
+}
+
+func Test_DataDirLock(t *testing.T) {
+	conf := testNodeConfig()
+	conf.DataDir = common.GetTempFolder() + "/nodelock"
+	defer os.RemoveAll(conf.DataDir)
+
+	stack, err := New(conf)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	if _, err = New(conf); err == nil {
+		t.Fatal("the data folder should be locked")
+	}
+
+	if err = stack.Close(); err != nil {
+		t.Fatalf("failed to close node: %v", err)
+	}
+
+	stack, err = New(conf)
+	if err != nil {
+		t.Fatalf("failed to create node after the lock released: %v", err)
+	}
+	stack.Close()
+}
+
+// newTestAddr returns a free loopback address to listen on.
+func newTestAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+// callJSONRPC calls the method on the JSON rpc listener of the address.
+func callJSONRPC(addr, method string, input, result interface{}) error {
+	client, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.Call(method, input, result)
+}
+
+// callHTTPRPC calls the method on the HTTP rpc listener of the address, and returns the error of the response.
+func callHTTPRPC(addr, method string, input interface{}) error {
+	return callHTTPRPCWithKey(addr, "", method, input)
+}
+
+// callHTTPRPCWithKey calls the method with the API key on the HTTP rpc listener of the address.
+func callHTTPRPCWithKey(addr, apiKey, method string, input interface{}) error {
+	request, _ := json.Marshal(map[string]interface{}{"method": method, "params": []interface{}{input}, "id": 1})
+	req, err := http.NewRequest(http.MethodPost, "http://"+addr, bytes.NewReader(request))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(apiKey) > 0 {
+		req.Header.Set(rpc.APIKeyHeader, apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct{ Error interface{} }
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return err
+	}
+
+	if response.Error != nil {
+		return errors.New(fmt.Sprint(response.Error))
+	}
+
+	return nil
+}
+
+func Test_PrivateAPIs(t *testing.T) {
+	conf := testNodeConfig()
+	conf.RPCAddr, conf.HTTPAddr, conf.AdminAddr = newTestAddr(t), newTestAddr(t), newTestAddr(t)
+
+	stack, err := New(conf)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	stack.Register(TestAPIService{[]rpc.API{
+		{Namespace: "public", Service: &TestAPI{}, Public: true},
+		{Namespace: "private", Service: &TestAPI{}, Public: false},
+	}})
+	if err = stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	defer stack.Stop()
+
+	// the private APIs are only served on the admin listener
+	if err = callJSONRPC(conf.RPCAddr, "public.Echo", "hello", new(string)); err != nil {
+		t.Fatalf("failed to call the public API: %v", err)
+	}
+
+	if err = callJSONRPC(conf.RPCAddr, "private.Echo", "hello", new(string)); err == nil {
+		t.Fatal("the private API should not be served on the JSON rpc listener")
+	}
+
+	if err = callHTTPRPC(conf.HTTPAddr, "public.Echo", "hello"); err != nil {
+		t.Fatalf("failed to call the public API via HTTP: %v", err)
+	}
+
+	if err = callHTTPRPC(conf.HTTPAddr, "private.Echo", "hello"); err == nil {
+		t.Fatal("the private API should not be served on the HTTP rpc listener")
+	}
+
+	if err = callJSONRPC(conf.AdminAddr, "private.Echo", "hello", new(string)); err != nil {
+		t.Fatalf("failed to call the private API on the admin listener: %v", err)
+	}
+}
+
+func Test_AdminAddr(t *testing.T) {
+	for addr, loopback := range map[string]bool{
+		"127.0.0.1:8047": true,
+		"localhost:8047": true,
+		"[::1]:8047":     true,
+		"0.0.0.0:8047":   false,
+		"10.0.0.1:8047":  false,
+		":8047":          false,
+	} {
+		if isLoopbackAddr(addr) != loopback {
+			t.Fatalf("unexpected loopback of %s, want %v", addr, loopback)
+		}
+	}
+
+	conf := testNodeConfig()
+	conf.AdminAddr = "0.0.0.0:0"
+	stack, err := New(conf)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	if err = stack.Start(); err != ErrAdminAddrPublic {
+		t.Fatalf("unexpected error %v, want %v", err, ErrAdminAddrPublic)
+	}
+}
+
+// testSeeleService serves the APIs and the API keys of a seele service without starting it.
+type testSeeleService struct {
+	TestAPIService
+	keys rpc.KeyAuthorizer
+}
+
+func (s testSeeleService) APIKeys() rpc.KeyAuthorizer { return s.keys }
+
+// startTestSeeleNode starts a node serving the APIs of a seele service on the specified additional
+// HTTP rpc listeners, of which the services are not started. The returned function stops the node
+// and removes the data folder.
+func startTestSeeleNode(t *testing.T, listeners ...HTTPListenerConfig) (*Config, func()) {
+	dataDir := common.GetTempFolder()
+	ctx := context.WithValue(context.Background(), "ServiceContext", seele.ServiceContext{DataDir: dataDir})
+	ss, err := seele.NewSeeleService(ctx, &seele.Config{
+		TxConf:    *core.DefaultTxPoolConfig(),
+		NetworkID: 1,
+		Coinbase:  *crypto.MustGenerateRandomAddress(),
+		MemoryDB:  true,
+	}, log.GetLogger("seele", true))
+	if err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatalf("failed to create seele service: %v", err)
+	}
+
+	conf := testNodeConfig()
+	conf.RPCAddr, conf.HTTPAddr, conf.AdminAddr = newTestAddr(t), newTestAddr(t), newTestAddr(t)
+	conf.HTTPListeners = listeners
+	stack, err := New(conf)
+	if err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	stack.Register(testSeeleService{TestAPIService{ss.APIs()}, ss.APIKeys()})
+	if err = stack.Start(); err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatalf("failed to start node: %v", err)
+	}
+
+	return conf, func() {
+		stack.Stop()
+		os.RemoveAll(dataDir)
+	}
+}
+
+func Test_SchedulerAPIs_Private(t *testing.T) {
+	conf, dispose := startTestSeeleNode(t)
+	defer dispose()
+
+	key := "0x0de1ffef23f127f53b7d784bc78887465f7802de1ab8b02a8243b7c60cc32f58"
+	var addr common.Address
+	if err := callJSONRPC(conf.RPCAddr, "scheduler.UnlockAccount", &key, &addr); err == nil {
+		t.Fatal("scheduler.UnlockAccount should not be served on the JSON rpc listener")
+	}
+
+	if err := callHTTPRPC(conf.HTTPAddr, "scheduler.UnlockAccount", &key); err == nil {
+		t.Fatal("scheduler.UnlockAccount should not be served on the HTTP rpc listener")
+	}
+
+	if err := callJSONRPC(conf.AdminAddr, "scheduler.UnlockAccount", &key, &addr); err != nil {
+		t.Fatalf("failed to call scheduler.UnlockAccount on the admin listener: %v", err)
+	}
+}
+
+func Test_SpendingLimitAPIs_Private(t *testing.T) {
+	conf, dispose := startTestSeeleNode(t)
+	defer dispose()
+
+	request := map[string]interface{}{"Address": crypto.MustGenerateRandomAddress(), "Amount": 1000}
+	for _, method := range []string{"scheduler.SetSpendingLimit", "scheduler.GrantSpendingAllowance"} {
+		var result bool
+		if err := callJSONRPC(conf.RPCAddr, method, request, &result); err == nil {
+			t.Fatalf("%s should not be served on the JSON rpc listener", method)
+		}
+
+		if err := callHTTPRPC(conf.HTTPAddr, method, request); err == nil {
+			t.Fatalf("%s should not be served on the HTTP rpc listener", method)
+		}
+
+		if err := callJSONRPC(conf.AdminAddr, method, request, &result); err != nil || !result {
+			t.Fatalf("failed to call %s on the admin listener: %v", method, err)
+		}
+	}
+}
+
+func Test_APIKeyManagement_Private(t *testing.T) {
+	keyAddr := newTestAddr(t)
+	conf, dispose := startTestSeeleNode(t, HTTPListenerConfig{Addr: keyAddr, APIKeys: true})
+	defer dispose()
+
+	// the key of all methods
+	var created struct{ Secret string }
+	request := map[string]interface{}{"Name": "partner"}
+	if err := callJSONRPC(conf.AdminAddr, "admin.CreateAPIKey", request, &created); err != nil {
+		t.Fatalf("failed to create the API key on the admin listener: %v", err)
+	}
+
+	if err := callHTTPRPCWithKey(keyAddr, created.Secret, "seele.GetBlockHeight", nil); err != nil {
+		t.Fatalf("failed to call the public API with the API key: %v", err)
+	}
+
+	for _, method := range []string{"admin.CreateAPIKey", "admin.RevokeAPIKey", "apikey.CreateKey"} {
+		if err := callHTTPRPCWithKey(keyAddr, created.Secret, method, request); err == nil {
+			t.Fatalf("%s should not be served on the API key listener", method)
+		}
+
+		if err := callHTTPRPC(conf.HTTPAddr, method, request); err == nil {
+			t.Fatalf("%s should not be served on the HTTP rpc listener", method)
+		}
+	}
+}
+
+func Test_DebugSetHead_Private(t *testing.T) {
+	conf, dispose := startTestSeeleNode(t)
+	defer dispose()
+
+	var genesis map[string]interface{}
+	request := map[string]interface{}{"Height": 0, "FullTx": false}
+	if err := callJSONRPC(conf.RPCAddr, "seele.GetBlockByHeight", request, &genesis); err != nil {
+		t.Fatalf("failed to get the genesis block: %v", err)
+	}
+
+	hash := genesis["hash"].(string)
+	var result bool
+	if err := callJSONRPC(conf.RPCAddr, "debug.SetHead", &hash, &result); err == nil {
+		t.Fatal("debug.SetHead should not be served on the JSON rpc listener")
+	}
+
+	if err := callHTTPRPC(conf.HTTPAddr, "debug.SetHead", &hash); err == nil {
+		t.Fatal("debug.SetHead should not be served on the HTTP rpc listener")
+	}
+
+	if err := callJSONRPC(conf.AdminAddr, "debug.SetHead", &hash, &result); err != nil || !result {
+		t.Fatalf("failed to call debug.SetHead on the admin listener: %v", err)
+	}
+}
+
+func Test_LabelAPIs_Private(t *testing.T) {
+	conf, dispose := startTestSeeleNode(t)
+	defer dispose()
+
+	addr := crypto.MustGenerateRandomAddress().ToHex()
+	request := map[string]interface{}{"AddressHex": addr, "Label": "exchange"}
+	var result bool
+	if err := callJSONRPC(conf.RPCAddr, "label.AddAddressLabel", request, &result); err == nil {
+		t.Fatal("label.AddAddressLabel should not be served on the JSON rpc listener")
+	}
+
+	if err := callHTTPRPC(conf.HTTPAddr, "label.RemoveAddressLabel", request); err == nil {
+		t.Fatal("label.RemoveAddressLabel should not be served on the HTTP rpc listener")
+	}
+
+	if err := callJSONRPC(conf.AdminAddr, "label.AddAddressLabel", request, &result); err != nil || !result {
+		t.Fatalf("failed to call label.AddAddressLabel on the admin listener: %v", err)
+	}
+
+	// the labels are still public to read
+	var labels []string
+	if err := callJSONRPC(conf.RPCAddr, "label.GetAddressLabels", &addr, &labels); err != nil || len(labels) != 1 {
+		t.Fatalf("failed to get the address labels %v: %v", labels, err)
+	}
+}
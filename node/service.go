@@ -21,3 +21,15 @@ type Service interface {
 
 	Stop() error
 }
+
+// KeyService is implemented by the services which manage the API keys
+// required by the HTTP rpc listeners, see HTTPListenerConfig.APIKeys.
+type KeyService interface {
+	APIKeys() rpc.KeyAuthorizer
+}
+
+// CacheService is implemented by the services of the chain, of which the responses of the
+// historical data are cached by the HTTP rpc listeners, see HTTPListenerConfig.CacheSize.
+type CacheService interface {
+	ResponseCacheChain() rpc.CacheChain
+}
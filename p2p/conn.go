@@ -31,6 +31,23 @@ type connection struct {
 
 	rmutux sync.Mutex // read msg lock
 	wmutux sync.Mutex // write msg lock
+
+	traceLock sync.RWMutex
+	tracer    *msgTracer // records the messages if not nil
+}
+
+func (c *connection) setTracer(tracer *msgTracer) {
+	c.traceLock.Lock()
+	defer c.traceLock.Unlock()
+
+	c.tracer = tracer
+}
+
+func (c *connection) getTracer() *msgTracer {
+	c.traceLock.RLock()
+	defer c.traceLock.RUnlock()
+
+	return c.tracer
 }
 
 // readFull receive from fd till outBuf is full
@@ -105,6 +122,10 @@ func (c *connection) ReadMsg() (msgRecv Message, err error) {
 		}
 	}
 
+	if tracer := c.getTracer(); tracer != nil {
+		tracer.record(true, msgRecv)
+	}
+
 	return msgRecv, nil
 }
 
@@ -129,5 +150,9 @@ func (c *connection) WriteMsg(msg Message) error {
 		}
 	}
 
+	if tracer := c.getTracer(); tracer != nil {
+		tracer.record(false, msg)
+	}
+
 	return nil
 }
@@ -0,0 +1,190 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package p2p
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/p2p/discovery"
+)
+
+// Stages of the dial test, see DialResult.
+const (
+	DialStageParse     = "parse"     // parsing the node string
+	DialStageDial      = "dial"      // connecting to the TCP address
+	DialStageHandshake = "handshake" // exchanging the encrypted handshake
+	DialStageIdentity  = "identity"  // checking the node id of the handshake
+	DialStageProtocol  = "protocol"  // matching the sub protocols
+	DialStageDone      = "done"      // all stages passed
+)
+
+// ErrPingTimeout is returned when the pong of the peer is not received in time.
+var ErrPingTimeout = errors.New("ping timeout")
+
+// pingTracker matches the pongs to the pending pings by the nonce echoed by the peer.
+type pingTracker struct {
+	lock    sync.Mutex
+	pending map[uint64]chan struct{}
+}
+
+// add registers the ping of the nonce, and returns the channel closed once the pong arrives.
+func (t *pingTracker) add(nonce uint64) chan struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.pending == nil {
+		t.pending = make(map[uint64]chan struct{})
+	}
+
+	done := make(chan struct{})
+	t.pending[nonce] = done
+	return done
+}
+
+// remove drops the ping of the nonce, e.g. timed out.
+func (t *pingTracker) remove(nonce uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.pending, nonce)
+}
+
+// pong resolves the ping of the nonce in the payload. The pong without payload is from the peers
+// that do not echo the nonce, which resolves all pending pings.
+func (t *pingTracker) pong(payload []byte) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(payload) != 8 {
+		for nonce, done := range t.pending {
+			close(done)
+			delete(t.pending, nonce)
+		}
+
+		return
+	}
+
+	nonce := binary.BigEndian.Uint64(payload)
+	if done := t.pending[nonce]; done != nil {
+		close(done)
+		delete(t.pending, nonce)
+	}
+}
+
+// Ping sends the ping control message to the peer and returns the round trip time once
+// the pong is received, or ErrPingTimeout if not received within the timeout.
+func (p *Peer) Ping(timeout time.Duration) (time.Duration, error) {
+	var nonce uint64
+	binary.Read(rand.Reader, binary.BigEndian, &nonce)
+
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint64(payload, nonce)
+
+	done := p.pings.add(nonce)
+	defer p.pings.remove(nonce)
+
+	start := time.Now()
+	if err := p.rw.WriteMsg(Message{Code: ctlMsgPingCode, Payload: payload}); err != nil {
+		return 0, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return time.Since(start), nil
+	case <-timer.C:
+		return 0, ErrPingTimeout
+	case <-p.closed:
+		return 0, errors.New("peer connection closed")
+	}
+}
+
+// PingPeer measures the protocol-level round trip time of the connected peer.
+func (srv *Server) PingPeer(id common.Address, timeout time.Duration) (time.Duration, error) {
+	peer := srv.peer(id)
+	if peer == nil {
+		return 0, ErrPeerNotFound
+	}
+
+	return peer.Ping(timeout)
+}
+
+// DialResult is the result of the dial test, in which Stage is the stage failed at, or
+// DialStageDone if succeeded.
+type DialResult struct {
+	Node      string
+	Stage     string
+	Error     string   `json:",omitempty"`
+	Address   string   `json:",omitempty"` // Address is the TCP address dialed
+	Protocols []string `json:",omitempty"` // Protocols are the sub protocols shared with the node
+	Elapsed   int64    // Elapsed is the duration of the test in milliseconds
+}
+
+// DialTest dials the node of the string "snode://<id>@<ip>:<port>" and performs the full
+// handshake without adding it as a peer, and reports the stage where it failed. Note, the
+// remote node may refuse the connection after the handshake if it has not discovered this
+// node yet, which does not fail the test.
+func (srv *Server) DialTest(nodeStr string, timeout time.Duration) *DialResult {
+	start := time.Now()
+	result := &DialResult{Node: nodeStr}
+	fail := func(stage string, err error) *DialResult {
+		result.Stage = stage
+		result.Error = err.Error()
+		result.Elapsed = int64(time.Since(start) / time.Millisecond)
+		return result
+	}
+
+	node, err := discovery.NewNodeFromString(nodeStr)
+	if err != nil {
+		return fail(DialStageParse, err)
+	}
+
+	//TODO UDPPort==> TCPPort, the same as addNode
+	result.Address = (&net.TCPAddr{IP: node.IP, Port: node.UDPPort}).String()
+	fd, err := net.DialTimeout("tcp", result.Address, timeout)
+	if err != nil {
+		return fail(DialStageDial, err)
+	}
+	defer fd.Close()
+
+	fd.SetDeadline(time.Now().Add(timeout))
+	peer := NewPeer(&connection{fd: fd}, srv.Protocols, srv.log, node)
+
+	var caps []Cap
+	for _, proto := range srv.Protocols {
+		caps = append(caps, proto.cap())
+	}
+
+	recvMsg, _, _, err := srv.doHandShake(caps, peer, outboundConn, node)
+	if err != nil {
+		return fail(DialStageHandshake, err)
+	}
+
+	if !recvMsg.NodeID.Equal(node.ID) {
+		return fail(DialStageIdentity, errors.New("node id mismatch"))
+	}
+
+	matched := matchProtocols(srv.Protocols, recvMsg.Caps)
+	if len(matched) == 0 {
+		return fail(DialStageProtocol, errors.New("no shared protocol"))
+	}
+
+	for _, proto := range matched {
+		result.Protocols = append(result.Protocols, proto.cap().String())
+	}
+
+	result.Stage = DialStageDone
+	result.Elapsed = int64(time.Since(start) / time.Millisecond)
+	return result
+}
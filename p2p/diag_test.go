@@ -0,0 +1,71 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/log"
+)
+
+func newTestPeerPair() (*Peer, *Peer) {
+	c1, c2 := net.Pipe()
+	testLog := log.GetLogger("p2p", false)
+	p1 := NewPeer(&connection{fd: c1}, nil, testLog, nil)
+	p2 := NewPeer(&connection{fd: c2}, nil, testLog, nil)
+
+	for _, p := range []*Peer{p1, p2} {
+		p.wg.Add(1)
+		go p.readLoop(make(chan error, 1))
+	}
+
+	return p1, p2
+}
+
+func Test_Peer_Ping(t *testing.T) {
+	p1, p2 := newTestPeerPair()
+	defer p1.rw.close()
+	defer p2.rw.close()
+
+	rtt, err := p1.Ping(time.Second)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, rtt > 0, true)
+	assert.Equal(t, len(p1.pings.pending), 0)
+
+	// the pong of the other nonce is ignored
+	done := p1.pings.add(1)
+	p1.pings.pong([]byte{0, 0, 0, 0, 0, 0, 0, 2})
+	select {
+	case <-done:
+		t.Fatal("resolved by the pong of the other nonce")
+	default:
+	}
+
+	// the pong without nonce resolves all
+	p1.pings.pong(nil)
+	<-done
+}
+
+func Test_Server_DialTest(t *testing.T) {
+	srv := &Server{}
+	result := srv.DialTest("invalid", time.Second)
+	assert.Equal(t, result.Stage, DialStageParse)
+	assert.Equal(t, result.Error != "", true)
+
+	// nothing listening on the port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, err, error(nil))
+	addr := listener.Addr().String()
+	listener.Close()
+
+	node := "snode://c03ff3c956d0a320b153a097c3d04efa488d43d7d7e05a44791492c9979ff558f9956c0a6b0c414783476f02ad8557349d35ba9373dadfa9a7a44fd88328189f@" + addr
+	result = srv.DialTest(node, time.Second)
+	assert.Equal(t, result.Stage, DialStageDial)
+	assert.Equal(t, result.Address, addr)
+}
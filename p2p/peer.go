@@ -8,9 +8,11 @@ package p2p
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
+	"github.com/seeleteam/go-seele/common/resource"
 	"github.com/seeleteam/go-seele/log"
 	"github.com/seeleteam/go-seele/p2p/discovery"
 )
@@ -29,45 +31,70 @@ type Peer struct {
 	disconnection chan uint
 	protocolMap   map[string]protocolRW // protocol cap => protocol read write wrapper
 	rw            *connection
+	pings         pingTracker // pings are the pending pings sent by Ping
 
 	wg  sync.WaitGroup
 	log *log.SeeleLog
 }
 
 func NewPeer(conn *connection, protocols []Protocol, log *log.SeeleLog, node *discovery.Node) *Peer {
-	closed := make(chan struct{})
+	peer := &Peer{
+		rw:            conn,
+		disconnection: make(chan uint),
+		closed:        make(chan struct{}),
+		log:           log,
+		protocolErr:   make(chan error),
+		Node:          node,
+	}
+
+	peer.setProtocols(protocols)
+
+	return peer
+}
+
+// setProtocols assigns the message code offsets to the protocols in order, which should
+// be the protocols shared with the remote peer, see matchProtocols.
+func (p *Peer) setProtocols(protocols []Protocol) {
 	offset := baseProtoCode
 	protoMap := make(map[string]protocolRW)
-	for _, p := range protocols {
+	for _, proto := range protocols {
 		protoRW := protocolRW{
-			rw:       conn,
+			rw:       p.rw,
 			offset:   offset,
-			Protocol: p,
+			Protocol: proto,
 			in:       make(chan Message, 1),
-			close:closed,
+			close:    p.closed,
 		}
 
-		protoMap[p.cap().String()] = protoRW
-		offset += p.Length
+		protoMap[proto.cap().String()] = protoRW
+		offset += proto.Length
 	}
 
-	return &Peer{
-		rw:            conn,
-		protocolMap:   protoMap,
-		disconnection: make(chan uint),
-		closed:        closed,
-		log:           log,
-		protocolErr:   make(chan error),
-		Node:          node,
-	}
+	p.protocolMap = protoMap
 }
 
 // run assumes that SubProtocol will never quit, otherwise proto.DelPeerCh may be closed before peer.run quits?
+// RemoteIP returns the IP address of the remote end of the connection, which
+// is the IP address of the node if not connected.
+func (p *Peer) RemoteIP() net.IP {
+	if p.rw != nil && p.rw.fd != nil {
+		if addr, ok := p.rw.fd.RemoteAddr().(*net.TCPAddr); ok {
+			return addr.IP
+		}
+	}
+
+	if p.Node != nil {
+		return p.Node.IP
+	}
+
+	return nil
+}
+
 func (p *Peer) run() (err error) {
 	var readErr = make(chan error, 1)
 	p.wg.Add(2)
-	go p.readLoop(readErr)
-	go p.pingLoop()
+	resource.P2P.Go(func() { p.readLoop(readErr) })
+	resource.P2P.Go(p.pingLoop)
 
 	p.notifyProtocols()
 	// Wait for an error or disconnect.
@@ -132,13 +159,13 @@ func (p *Peer) readLoop(readErr chan<- error) {
 func (p *Peer) notifyProtocols() {
 	p.wg.Add(len(p.protocolMap))
 	for _, proto := range p.protocolMap {
-		go func() {
+		go func(proto protocolRW) {
 			defer p.wg.Done()
 
 			if proto.AddPeer != nil {
 				proto.AddPeer(p, &proto)
 			}
-		}()
+		}(proto)
 	}
 }
 
@@ -147,9 +174,10 @@ func (p *Peer) handle(msgRecv Message) error {
 	if msgRecv.Code < baseProtoCode {
 		switch {
 		case msgRecv.Code == ctlMsgPingCode:
-			go p.sendCtlMsg(ctlMsgPongCode)
+			// the nonce of the ping is echoed, see Ping
+			go p.rw.WriteMsg(Message{Code: ctlMsgPongCode, Payload: msgRecv.Payload})
 		case msgRecv.Code == ctlMsgPongCode:
-			//p.log.Debug("peer handle Ping msg.")
+			p.pings.pong(msgRecv.Payload)
 			return nil
 		case msgRecv.Code == ctlMsgDiscCode:
 			return fmt.Errorf("error=%d", ctlMsgDiscCode)
@@ -172,7 +200,10 @@ func (p *Peer) handle(msgRecv Message) error {
 		return fmt.Errorf(fmt.Sprintf("could not found mapping proto with code %d", msgRecv.Code))
 	}
 
+	// the messages blocked to deliver are accounted, which are queued if the protocol handles slowly
+	resource.P2P.Enqueue(len(msgRecv.Payload))
 	protocolTarget.in <- msgRecv
+	resource.P2P.Dequeue(len(msgRecv.Payload))
 
 	return nil
 }
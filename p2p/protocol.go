@@ -6,7 +6,10 @@
 package p2p
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"sort"
 )
 
 const (
@@ -14,6 +17,20 @@ const (
 	ctlProtoCode  uint16 = 1  //control protoCode. For example, handshake ping pong message etc
 )
 
+var (
+	// ErrServerRunning is returned when registering a protocol after the server starts.
+	ErrServerRunning = errors.New("server already running")
+
+	// ErrProtocolInvalid is returned when registering a protocol without name, message codes or AddPeer handler.
+	ErrProtocolInvalid = errors.New("invalid protocol")
+
+	// ErrProtocolRegistered is returned when registering a protocol of the same name and version twice.
+	ErrProtocolRegistered = errors.New("protocol already registered")
+
+	// ErrProtocolCodesExhausted is returned when the message codes of all protocols exceed the code space.
+	ErrProtocolCodesExhausted = errors.New("protocol message codes exhausted")
+)
+
 //Protocol base class for high level transfer protocol.
 type Protocol struct {
 	// Name should contain the official protocol name,
@@ -31,12 +48,76 @@ type Protocol struct {
 
 	// DeletePeer this method will be called when a peer is disconnected
 	DeletePeer func(peer *Peer)
+
+	// DescribeMsg returns the summary of the message for tracing, optional
+	DescribeMsg func(code uint16, payload []byte) string
 }
 
 func (p *Protocol) cap() Cap {
 	return Cap{p.Name, p.Version}
 }
 
+// RegisterProtocol registers a sub-protocol, e.g. of an extension to gossip oracle data, which
+// runs on the peer connections along with the other protocols. The protocol handles the messages
+// of codes [0, Length) in the AddPeer handler, which reads from and writes to the peer via the
+// specified MsgReadWriter. Protocols should be registered before the server starts.
+func (srv *Server) RegisterProtocol(proto Protocol) error {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if srv.running {
+		return ErrServerRunning
+	}
+
+	if len(proto.Name) == 0 || proto.Length == 0 || proto.AddPeer == nil {
+		return ErrProtocolInvalid
+	}
+
+	codes := uint(baseProtoCode) + uint(proto.Length)
+	for _, p := range srv.Protocols {
+		if p.cap() == proto.cap() {
+			return ErrProtocolRegistered
+		}
+
+		codes += uint(p.Length)
+	}
+
+	if codes > math.MaxUint16 {
+		return ErrProtocolCodesExhausted
+	}
+
+	srv.Protocols = append(srv.Protocols, proto)
+
+	return nil
+}
+
+// matchProtocols returns the protocols supported by the remote peer of the specified caps,
+// which are sorted by name and version, so that both sides assign the same message code
+// offsets even if they have different protocols registered.
+func matchProtocols(protocols []Protocol, caps []Cap) []Protocol {
+	supported := make(map[Cap]bool)
+	for _, cap := range caps {
+		supported[cap] = true
+	}
+
+	var matched []Protocol
+	for _, p := range protocols {
+		if supported[p.cap()] {
+			matched = append(matched, p)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].Name != matched[j].Name {
+			return matched[i].Name < matched[j].Name
+		}
+
+		return matched[i].Version < matched[j].Version
+	})
+
+	return matched
+}
+
 // Cap is the structure of a peer capability.
 type Cap struct {
 	Name    string
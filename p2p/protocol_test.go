@@ -0,0 +1,60 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func newTestProtocol(name string, version uint, length uint16) Protocol {
+	return Protocol{
+		Name:    name,
+		Version: version,
+		Length:  length,
+		AddPeer: func(peer *Peer, rw MsgReadWriter) {},
+	}
+}
+
+func Test_Server_RegisterProtocol(t *testing.T) {
+	srv := &Server{}
+
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("seele", 1, 8)), error(nil))
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("oracle", 1, 4)), error(nil))
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("oracle", 2, 4)), error(nil))
+	assert.Equal(t, len(srv.Protocols), 3)
+
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("oracle", 1, 4)), ErrProtocolRegistered)
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("", 1, 4)), ErrProtocolInvalid)
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("empty", 1, 0)), ErrProtocolInvalid)
+	assert.Equal(t, srv.RegisterProtocol(Protocol{Name: "nohandler", Version: 1, Length: 1}), ErrProtocolInvalid)
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("huge", 1, 65535)), ErrProtocolCodesExhausted)
+
+	srv.running = true
+	assert.Equal(t, srv.RegisterProtocol(newTestProtocol("late", 1, 1)), ErrServerRunning)
+}
+
+func Test_MatchProtocols(t *testing.T) {
+	protocols := []Protocol{
+		newTestProtocol("seele", 1, 8),
+		newTestProtocol("oracle", 1, 4),
+		newTestProtocol("light", 1, 2),
+	}
+
+	caps := []Cap{{"seele", 1}, {"oracle", 1}, {"oracle", 2}}
+	matched := matchProtocols(protocols, caps)
+	assert.Equal(t, len(matched), 2)
+	assert.Equal(t, matched[0].cap(), Cap{"oracle", 1})
+	assert.Equal(t, matched[1].cap(), Cap{"seele", 1})
+
+	// offsets are assigned in the matched order
+	peer := NewPeer(nil, matched, nil, nil)
+	assert.Equal(t, peer.protocolMap["oracle/1"].offset, baseProtoCode)
+	assert.Equal(t, peer.protocolMap["seele/1"].offset, baseProtoCode+4)
+
+	assert.Equal(t, len(matchProtocols(protocols, nil)), 0)
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/resource"
 	"github.com/seeleteam/go-seele/crypto"
 	"github.com/seeleteam/go-seele/crypto/ecies"
 	"github.com/seeleteam/go-seele/crypto/secp256k1"
@@ -97,8 +98,9 @@ type Server struct {
 	delpeer chan *Peer
 	loopWG  sync.WaitGroup // loop, listenLoop
 
-	peers map[common.Address]*Peer
-	log   *log.SeeleLog
+	peerLock sync.RWMutex // protects the peers for the access out of the run loop
+	peers    map[common.Address]*Peer
+	log      *log.SeeleLog
 }
 
 // PeerCount return the count of peers
@@ -109,6 +111,14 @@ func (srv *Server) PeerCount() int {
 	return 0
 }
 
+// peer returns the connected peer of the specified node id, or nil if not found.
+func (srv *Server) peer(id common.Address) *Peer {
+	srv.peerLock.RLock()
+	defer srv.peerLock.RUnlock()
+
+	return srv.peers[id]
+}
+
 // Start starts running the server.
 func (srv *Server) Start() (err error) {
 	srv.lock.Lock()
@@ -194,14 +204,18 @@ running:
 				srv.log.Info("server.run  <-srv.addpeer, len(peers)=%d. nodeid already connected", len(peers))
 				c.Disconnect(discAlreadyConnected)
 			} else {
+				srv.peerLock.Lock()
 				peers[c.Node.ID] = c
+				srv.peerLock.Unlock()
 				//srv.log.Info("server.run  <-srv.addpeer, len(peers)=%d, len(srv.peers)=%d", len(peers), len(srv.peers))
 				srv.log.Info("server.run  <-srv.addpeer %s", c.Node.ID.ToHex())
 			}
 		case pd := <-srv.delpeer:
 			curPeer, ok := peers[pd.Node.ID]
 			if ok && curPeer == pd {
+				srv.peerLock.Lock()
 				delete(peers, pd.Node.ID)
+				srv.peerLock.Unlock()
 				srv.log.Info("server.run delpeer recved. peer match. remove peer. peers num=%d", len(peers))
 			} else {
 				srv.log.Info("server.run delpeer recved. peer not match")
@@ -216,7 +230,9 @@ running:
 
 	for len(peers) > 0 {
 		p := <-srv.delpeer
+		srv.peerLock.Lock()
 		delete(peers, p.Node.ID)
+		srv.peerLock.Unlock()
 	}
 }
 
@@ -273,6 +289,13 @@ func (srv *Server) listenLoop() {
 			}
 			break
 		}
+		if resource.P2P.Throttle() {
+			srv.log.Warn("p2p subsystem throttled, refuse the connection from %s", fd.RemoteAddr())
+			fd.Close()
+			slots <- struct{}{}
+			continue
+		}
+
 		go func() {
 			srv.log.Info("Accept new connection from, %s", fd.RemoteAddr())
 			err := srv.setupConn(fd, inboundConn, nil)
@@ -302,6 +325,7 @@ func (srv *Server) setupConn(fd net.Conn, flags int, dialDest *discovery.Node) e
 	}
 
 	peerCaps, peerNodeID := recvMsg.Caps, recvMsg.NodeID
+	peer.setProtocols(matchProtocols(srv.Protocols, peerCaps))
 	if flags == inboundConn {
 		peerNode, ok := srv.kadDB.FindByNodeID(peerNodeID)
 		if !ok {
@@ -0,0 +1,157 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package p2p
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+// msgTraceCapacity is the number of the latest messages kept for a traced peer.
+const msgTraceCapacity = 512
+
+var (
+	// ErrPeerNotFound is returned when the peer is not connected.
+	ErrPeerNotFound = errors.New("peer not found")
+
+	// ErrPeerNotTraced is returned when the message tracing of the peer is not enabled.
+	ErrPeerNotTraced = errors.New("peer not traced")
+)
+
+// MsgTrace is the summary of a message sent to or received from a peer.
+type MsgTrace struct {
+	Time    time.Time
+	Inbound bool   // Inbound is true if the message is received from the peer
+	Code    uint16 // Code is the message code on wire, including the protocol offset
+	Size    int    // Size is the payload size in bytes
+	Summary string // Summary is the decoded message summary
+	Raw     string `json:",omitempty"` // Raw is the payload in hex, only captured if enabled
+}
+
+// msgTracer records the latest messages of a peer in a ring buffer.
+type msgTracer struct {
+	lock     sync.Mutex
+	raw      bool
+	describe func(code uint16, payload []byte) string
+	traces   []*MsgTrace
+	next     int // index in traces to write the next trace
+}
+
+func newMsgTracer(raw bool, describe func(code uint16, payload []byte) string) *msgTracer {
+	return &msgTracer{
+		raw:      raw,
+		describe: describe,
+		traces:   make([]*MsgTrace, 0, msgTraceCapacity),
+	}
+}
+
+func (t *msgTracer) record(inbound bool, msg Message) {
+	trace := &MsgTrace{
+		Time:    time.Now(),
+		Inbound: inbound,
+		Code:    msg.Code,
+		Size:    len(msg.Payload),
+		Summary: t.describe(msg.Code, msg.Payload),
+	}
+
+	if t.raw {
+		trace.Raw = hex.EncodeToString(msg.Payload)
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.traces) < msgTraceCapacity {
+		t.traces = append(t.traces, trace)
+	} else {
+		t.traces[t.next] = trace
+	}
+
+	t.next = (t.next + 1) % msgTraceCapacity
+}
+
+// list returns the recorded traces from the oldest to the latest.
+func (t *msgTracer) list() []*MsgTrace {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if len(t.traces) < msgTraceCapacity {
+		return append([]*MsgTrace(nil), t.traces...)
+	}
+
+	return append(append([]*MsgTrace(nil), t.traces[t.next:]...), t.traces[:t.next]...)
+}
+
+// SetTrace enables or disables the message tracing of the peer. The raw payloads are
+// captured in hex if raw is true. Note, the previous traces are dropped once enabled.
+func (p *Peer) SetTrace(enabled, raw bool) {
+	var tracer *msgTracer
+	if enabled {
+		tracer = newMsgTracer(raw, p.describeMsg)
+	}
+
+	p.rw.setTracer(tracer)
+}
+
+// Traces returns the traced messages of the peer from the oldest to the latest.
+func (p *Peer) Traces() ([]*MsgTrace, error) {
+	tracer := p.rw.getTracer()
+	if tracer == nil {
+		return nil, ErrPeerNotTraced
+	}
+
+	return tracer.list(), nil
+}
+
+// describeMsg returns the summary of the message with the code on wire.
+func (p *Peer) describeMsg(code uint16, payload []byte) string {
+	switch code {
+	case ctlMsgProtoHandshake:
+		return "handshake"
+	case ctlMsgPingCode:
+		return "ping"
+	case ctlMsgPongCode:
+		return "pong"
+	}
+
+	for _, proto := range p.protocolMap {
+		if code >= proto.offset && code < proto.offset+proto.Length {
+			if proto.DescribeMsg != nil {
+				return fmt.Sprintf("%s %s", proto.cap(), proto.DescribeMsg(code-proto.offset, payload))
+			}
+
+			return fmt.Sprintf("%s code %d", proto.cap(), code-proto.offset)
+		}
+	}
+
+	return fmt.Sprintf("unknown code %d", code)
+}
+
+// SetPeerTrace enables or disables the message tracing of the connected peer.
+func (srv *Server) SetPeerTrace(id common.Address, enabled, raw bool) error {
+	peer := srv.peer(id)
+	if peer == nil {
+		return ErrPeerNotFound
+	}
+
+	peer.SetTrace(enabled, raw)
+	return nil
+}
+
+// PeerTraces returns the traced messages of the connected peer.
+func (srv *Server) PeerTraces(id common.Address) ([]*MsgTrace, error) {
+	peer := srv.peer(id)
+	if peer == nil {
+		return nil, ErrPeerNotFound
+	}
+
+	return peer.Traces()
+}
@@ -0,0 +1,34 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_MsgTracer_Ring(t *testing.T) {
+	tracer := newMsgTracer(true, func(code uint16, payload []byte) string { return "test" })
+
+	tracer.record(true, Message{Code: 0, Payload: []byte{1, 2}})
+	traces := tracer.list()
+	assert.Equal(t, len(traces), 1)
+	assert.Equal(t, traces[0].Inbound, true)
+	assert.Equal(t, traces[0].Size, 2)
+	assert.Equal(t, traces[0].Summary, "test")
+	assert.Equal(t, traces[0].Raw, "0102")
+
+	// the oldest traces are overwritten
+	for i := 1; i <= msgTraceCapacity; i++ {
+		tracer.record(false, Message{Code: uint16(i)})
+	}
+
+	traces = tracer.list()
+	assert.Equal(t, len(traces), msgTraceCapacity)
+	assert.Equal(t, traces[0].Code, uint16(1))
+	assert.Equal(t, traces[msgTraceCapacity-1].Code, uint16(msgTraceCapacity))
+}
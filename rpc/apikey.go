@@ -0,0 +1,38 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"errors"
+)
+
+// APIKeyHeader is the HTTP header to carry the API key on the listeners requiring API keys.
+const APIKeyHeader = "X-Api-Key"
+
+// ErrAPIKeyMissing is returned when the request requires an API key but none is provided.
+var ErrAPIKeyMissing = errors.New("API key missing")
+
+// KeyAuthorizer authorizes and accounts the requests of the API keys, e.g. for the
+// partners of a public gateway with individual method allowlists and quotas.
+type KeyAuthorizer interface {
+	// Authorize returns an error if the request of the specified service method,
+	// e.g. seele.GetBalance, is not allowed for the API key, otherwise the request is
+	// accounted to the key.
+	Authorize(key string, serviceMethod string) error
+}
+
+// authorizeKey authorizes the request of the specified service method if required.
+func authorizeKey(authorizer KeyAuthorizer, key string, serviceMethod string) error {
+	if authorizer == nil {
+		return nil
+	}
+
+	if len(key) == 0 {
+		return ErrAPIKeyMissing
+	}
+
+	return authorizer.Authorize(key, serviceMethod)
+}
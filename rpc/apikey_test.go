@@ -0,0 +1,36 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+)
+
+var errTestKeyDenied = errors.New("denied")
+
+type testKeyAuthorizer map[string]string // key => allowed service method
+
+func (authorizer testKeyAuthorizer) Authorize(key string, serviceMethod string) error {
+	if authorizer[key] != serviceMethod {
+		return errTestKeyDenied
+	}
+
+	return nil
+}
+
+func Test_AuthorizeKey(t *testing.T) {
+	// no authorizer requires nothing
+	assert.Equal(t, authorizeKey(nil, "", "Arith.Add"), error(nil))
+
+	authorizer := testKeyAuthorizer{"key1": "Arith.Add"}
+	assert.Equal(t, authorizeKey(authorizer, "", "Arith.Add"), ErrAPIKeyMissing)
+	assert.Equal(t, authorizeKey(authorizer, "key1", "Arith.Mul"), errTestKeyDenied)
+	assert.Equal(t, authorizeKey(authorizer, "key2", "Arith.Add"), errTestKeyDenied)
+	assert.Equal(t, authorizeKey(authorizer, "key1", "Arith.Add"), error(nil))
+}
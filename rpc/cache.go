@@ -0,0 +1,229 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+// DefaultCacheConfirmations is the default number of confirmations of the data to cache its responses.
+const DefaultCacheConfirmations = 120
+
+// CacheHeight returns the height of the block that the response of the raw request params is
+// derived from, and false if the response should not be cached, e.g. the HEAD block requested.
+type CacheHeight func(params json.RawMessage, result interface{}) (uint64, bool)
+
+// CacheChain is the chain of which the responses of the historical data are cached.
+type CacheChain interface {
+	// HeadHeight returns the height of the HEAD block.
+	HeadHeight() uint64
+
+	// CanonicalHash returns the hash of the canonical block at the height.
+	CanonicalHash(height uint64) (common.Hash, error)
+}
+
+// CacheStats is the metrics of a response cache.
+type CacheStats struct {
+	Entries       int    // number of the cached responses
+	Bytes         int    // total size of the cached responses
+	MaxBytes      int    // size bound of the cached responses
+	Hits          uint64 // number of the requests served from the cache
+	Misses        uint64 // number of the requests of the cache methods not served from the cache
+	Evictions     uint64 // number of the responses evicted for the size bound
+	Invalidations uint64 // number of the responses dropped since the block is reorganized out of the canonical chain
+}
+
+// cacheEntry is the encoded result of the request, derived from the canonical block of the height and hash.
+type cacheEntry struct {
+	key    string
+	result json.RawMessage
+	height uint64
+	hash   common.Hash
+}
+
+// ResponseCache caches the encoded responses of the registered methods keyed by the method and
+// params, once the data is at least the number of confirmations deep, so that the identical
+// queries of the historical blocks are not recomputed. The least recently used responses are
+// evicted beyond the size bound, and the cached response is dropped once its block is no longer
+// canonical, e.g. reorganized deeper than the confirmations.
+type ResponseCache struct {
+	chain         CacheChain
+	confirmations uint64
+	maxBytes      int
+	methods       map[string]CacheHeight // service method => height of the response
+
+	lock    sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is the most recently used
+	stats   CacheStats
+}
+
+// NewResponseCache creates the response cache of the chain with the size bound in bytes, and the
+// number of confirmations, DefaultCacheConfirmations if 0. Returns nil if maxBytes is 0.
+func NewResponseCache(chain CacheChain, maxBytes int, confirmations uint64) *ResponseCache {
+	if maxBytes <= 0 || chain == nil {
+		return nil
+	}
+
+	if confirmations == 0 {
+		confirmations = DefaultCacheConfirmations
+	}
+
+	return &ResponseCache{
+		chain:         chain,
+		confirmations: confirmations,
+		maxBytes:      maxBytes,
+		methods:       make(map[string]CacheHeight),
+		entries:       make(map[string]*list.Element),
+		lru:           list.New(),
+	}
+}
+
+// Cache caches the responses of the specified service method, e.g. seele.GetBlockByHeight,
+// with the height derived by the hook.
+func (cache *ResponseCache) Cache(serviceMethod string, height CacheHeight) {
+	cache.methods[serviceMethod] = height
+}
+
+// CacheAPIs caches the responses of all cache methods declared in the specified APIs.
+func (cache *ResponseCache) CacheAPIs(apis []API) {
+	for _, api := range apis {
+		for method, height := range api.CacheMethods {
+			cache.Cache(api.Namespace+"."+method, height)
+		}
+	}
+}
+
+// cacheable returns whether the responses of the service method are cached.
+func (cache *ResponseCache) cacheable(serviceMethod string) bool {
+	if cache == nil {
+		return false
+	}
+
+	_, ok := cache.methods[serviceMethod]
+	return ok
+}
+
+// cacheKey returns the key of the request, in which the params are compacted.
+func cacheKey(serviceMethod string, params *json.RawMessage) string {
+	var buf bytes.Buffer
+	buf.WriteString(serviceMethod)
+	buf.WriteByte(0)
+	if params != nil && json.Compact(&buf, *params) != nil {
+		buf.Truncate(len(serviceMethod) + 1)
+		buf.Write(*params)
+	}
+
+	return buf.String()
+}
+
+// get returns the cached result of the request, or nil if not cached or its block is no longer canonical.
+func (cache *ResponseCache) get(serviceMethod string, params *json.RawMessage) json.RawMessage {
+	key := cacheKey(serviceMethod, params)
+
+	cache.lock.Lock()
+	elem, ok := cache.entries[key]
+	cache.lock.Unlock()
+
+	if !ok {
+		cache.miss()
+		return nil
+	}
+
+	// the canonical hash is read without the lock
+	entry := elem.Value.(*cacheEntry)
+	if hash, err := cache.chain.CanonicalHash(entry.height); err != nil || !hash.Equal(entry.hash) {
+		cache.lock.Lock()
+		if cache.entries[key] == elem {
+			cache.remove(elem)
+			cache.stats.Invalidations++
+		}
+		cache.stats.Misses++
+		cache.lock.Unlock()
+		return nil
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if cache.entries[key] == elem {
+		cache.lru.MoveToFront(elem)
+	}
+	cache.stats.Hits++
+
+	return entry.result
+}
+
+func (cache *ResponseCache) miss() {
+	cache.lock.Lock()
+	cache.stats.Misses++
+	cache.lock.Unlock()
+}
+
+// put caches the encoded result of the request if the data is deep enough.
+func (cache *ResponseCache) put(serviceMethod string, params *json.RawMessage, x interface{}, result json.RawMessage) {
+	hook, ok := cache.methods[serviceMethod]
+	if !ok || params == nil {
+		return
+	}
+
+	height, ok := hook(*params, x)
+	if !ok || height+cache.confirmations > cache.chain.HeadHeight() {
+		return
+	}
+
+	hash, err := cache.chain.CanonicalHash(height)
+	if err != nil {
+		return
+	}
+
+	entry := &cacheEntry{cacheKey(serviceMethod, params), result, height, hash}
+	if entry.size() > cache.maxBytes {
+		return
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if elem, ok := cache.entries[entry.key]; ok {
+		cache.remove(elem)
+	}
+
+	cache.entries[entry.key] = cache.lru.PushFront(entry)
+	cache.stats.Bytes += entry.size()
+
+	for cache.stats.Bytes > cache.maxBytes {
+		cache.remove(cache.lru.Back())
+		cache.stats.Evictions++
+	}
+}
+
+// remove removes the cached entry, which is called with the lock held.
+func (cache *ResponseCache) remove(elem *list.Element) {
+	entry := cache.lru.Remove(elem).(*cacheEntry)
+	delete(cache.entries, entry.key)
+	cache.stats.Bytes -= entry.size()
+}
+
+func (entry *cacheEntry) size() int {
+	return len(entry.key) + len(entry.result)
+}
+
+// Stats returns the metrics of the cache.
+func (cache *ResponseCache) Stats() CacheStats {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	stats := cache.stats
+	stats.Entries = len(cache.entries)
+	stats.MaxBytes = cache.maxBytes
+	return stats
+}
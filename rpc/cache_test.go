@@ -0,0 +1,153 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+)
+
+type testCacheChain struct {
+	head   uint64
+	hashes map[uint64]common.Hash
+}
+
+func (c *testCacheChain) HeadHeight() uint64 { return c.head }
+
+func (c *testCacheChain) CanonicalHash(height uint64) (common.Hash, error) {
+	hash, ok := c.hashes[height]
+	if !ok {
+		return common.EmptyHash, errors.New("not found")
+	}
+
+	return hash, nil
+}
+
+func newTestCacheChain(head uint64) *testCacheChain {
+	chain := &testCacheChain{head, make(map[uint64]common.Hash)}
+	for i := uint64(0); i <= head; i++ {
+		chain.hashes[i] = common.StringToHash(string(rune('a' + i)))
+	}
+
+	return chain
+}
+
+// testCacheHeight returns the height in the params of the request.
+func testCacheHeight(params json.RawMessage, result interface{}) (uint64, bool) {
+	var args [1]uint64
+	return args[0], json.Unmarshal(params, &args) == nil
+}
+
+type testCacheService struct {
+	calls int
+}
+
+func (s *testCacheService) GetBlock(height *uint64, result *string) error {
+	s.calls++
+	*result = strings.Repeat("b", int(*height)+1)
+	return nil
+}
+
+func Test_NewResponseCache(t *testing.T) {
+	assert.Equal(t, NewResponseCache(newTestCacheChain(10), 0, 1) == nil, true)
+	assert.Equal(t, NewResponseCache(nil, 1024, 1) == nil, true)
+
+	cache := NewResponseCache(newTestCacheChain(10), 1024, 0)
+	assert.Equal(t, cache.confirmations, uint64(DefaultCacheConfirmations))
+
+	var nilCache *ResponseCache
+	assert.Equal(t, nilCache.cacheable("test.GetBlock"), false)
+}
+
+func Test_ResponseCache(t *testing.T) {
+	chain := newTestCacheChain(10)
+	cache := NewResponseCache(chain, 1024, 5)
+	cache.CacheAPIs([]API{{Namespace: "test", CacheMethods: map[string]CacheHeight{"GetBlock": testCacheHeight}}})
+	assert.Equal(t, cache.cacheable("test.GetBlock"), true)
+	assert.Equal(t, cache.cacheable("test.GetOther"), false)
+
+	deep, shallow := json.RawMessage(`[ 5 ]`), json.RawMessage(`[6]`)
+	cache.put("test.GetBlock", &deep, nil, json.RawMessage(`"deep"`))
+	cache.put("test.GetBlock", &shallow, nil, json.RawMessage(`"shallow"`))
+
+	// the params are compacted in the key, and the data not deep enough is not cached
+	params := json.RawMessage(`[5]`)
+	assert.Equal(t, string(cache.get("test.GetBlock", &params)), `"deep"`)
+	assert.Equal(t, cache.get("test.GetBlock", &shallow) == nil, true)
+
+	stats := cache.Stats()
+	assert.Equal(t, stats.Entries, 1)
+	assert.Equal(t, stats.Hits, uint64(1))
+	assert.Equal(t, stats.Misses, uint64(1))
+
+	// invalidated once the block is reorganized out of the canonical chain
+	chain.hashes[5] = common.StringToHash("reorg")
+	assert.Equal(t, cache.get("test.GetBlock", &params) == nil, true)
+
+	stats = cache.Stats()
+	assert.Equal(t, stats.Entries, 0)
+	assert.Equal(t, stats.Bytes, 0)
+	assert.Equal(t, stats.Invalidations, uint64(1))
+}
+
+func Test_ResponseCache_Evict(t *testing.T) {
+	chain := newTestCacheChain(10)
+	cache := NewResponseCache(chain, 60, 1)
+	cache.Cache("test.GetBlock", testCacheHeight)
+
+	first, second, third := json.RawMessage(`[1]`), json.RawMessage(`[2]`), json.RawMessage(`[3]`)
+	cache.put("test.GetBlock", &first, nil, json.RawMessage(`"first"`))
+	cache.put("test.GetBlock", &second, nil, json.RawMessage(`"second"`))
+	assert.Equal(t, cache.get("test.GetBlock", &first) != nil, true)
+
+	// the least recently used is evicted beyond the size bound
+	cache.put("test.GetBlock", &third, nil, json.RawMessage(`"third"`))
+	assert.Equal(t, cache.get("test.GetBlock", &second) == nil, true)
+	assert.Equal(t, cache.get("test.GetBlock", &first) != nil, true)
+	assert.Equal(t, cache.get("test.GetBlock", &third) != nil, true)
+
+	stats := cache.Stats()
+	assert.Equal(t, stats.Evictions, uint64(1))
+	assert.Equal(t, stats.Bytes <= stats.MaxBytes, true)
+
+	// the response larger than the bound is not cached
+	cache.put("test.GetBlock", &second, nil, json.RawMessage(strings.Repeat("x", 61)))
+	assert.Equal(t, cache.get("test.GetBlock", &second) == nil, true)
+}
+
+func Test_HTTPServer_ResponseCache(t *testing.T) {
+	server, _ := NewHTTPServer(nil, nil)
+	service := &testCacheService{}
+	assert.Equal(t, server.RegisterName("test", service), error(nil))
+
+	cache := NewResponseCache(newTestCacheChain(10), 1024, 5)
+	cache.Cache("test.GetBlock", testCacheHeight)
+	server.SetResponseCache(cache)
+
+	serve := func(request string) string {
+		req := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(request))
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// served by the service once, and then from the cache with the id of the request
+	assert.Equal(t, serve(`{"method":"test.GetBlock","params":[2],"id":1}`), `{"jsonrpc":"1.0","id":1,"result":"bbb","error":null}`+"\n")
+	assert.Equal(t, serve(`{"method":"test.GetBlock","params":[2],"id":2}`), `{"jsonrpc":"1.0","id":2,"result":"bbb","error":null}`+"\n")
+	assert.Equal(t, service.calls, 1)
+
+	// not deep enough to cache
+	serve(`{"method":"test.GetBlock","params":[8],"id":3}`)
+	serve(`{"method":"test.GetBlock","params":[8],"id":4}`)
+	assert.Equal(t, service.calls, 3)
+}
@@ -0,0 +1,37 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"context"
+)
+
+// contextReceiver is implemented by the request params that receive the request context.
+type contextReceiver interface {
+	setContext(ctx context.Context)
+}
+
+// RequestContext could be embedded in the request params of a RPC method to receive
+// the request context, which is cancelled when the client hangs up, the response
+// is written or the request deadline exceeds. Long-running methods should pass the
+// context to the query paths so that the work could be aborted promptly.
+type RequestContext struct {
+	ctx context.Context
+}
+
+func (c *RequestContext) setContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// Context returns the request context, or context.Background() if not set,
+// e.g. the method is called directly instead of via RPC.
+func (c *RequestContext) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
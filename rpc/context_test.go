@@ -0,0 +1,64 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net/rpc"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+type testContextArgs struct {
+	RequestContext
+	A int
+}
+
+type testConn struct {
+	*strings.Reader
+	bytes.Buffer
+}
+
+func (c *testConn) Read(p []byte) (int, error) { return c.Reader.Read(p) }
+func (c *testConn) Close() error               { return nil }
+
+func Test_RequestContext(t *testing.T) {
+	conn := &testConn{Reader: strings.NewReader(`{"method":"Test.Query","params":[{"A":1}],"id":1}`)}
+	codec := NewJsonCodecWithConfig(conn, CodecConfig{Timeout: time.Minute})
+
+	var req rpc.Request
+	assert.Equal(t, codec.ReadRequestHeader(&req), nil)
+
+	var args testContextArgs
+	assert.Equal(t, codec.ReadRequestBody(&args), nil)
+	assert.Equal(t, args.A, 1)
+
+	ctx := args.Context()
+	_, ok := ctx.Deadline()
+	assert.Equal(t, ok, true)
+	assert.Equal(t, ctx.Err(), nil)
+
+	// cancelled once the response is written
+	assert.Equal(t, codec.WriteResponse(&rpc.Response{Seq: req.Seq}, 1), nil)
+	assert.Equal(t, ctx.Err(), context.Canceled)
+
+	// cancelled once the client hangs up
+	codec = NewJsonCodecWithConfig(&testConn{Reader: strings.NewReader(`{"method":"Test.Query","params":[{"A":1}],"id":1}`)}, CodecConfig{})
+	codec.ReadRequestHeader(&req)
+	codec.ReadRequestBody(&args)
+	ctx = args.Context()
+	assert.Equal(t, codec.ReadRequestHeader(&req) != nil, true)
+	assert.Equal(t, ctx.Err(), context.Canceled)
+}
+
+func Test_RequestContext_Default(t *testing.T) {
+	var args testContextArgs
+	assert.Equal(t, args.Context(), context.Background())
+}
@@ -0,0 +1,155 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common/resource"
+)
+
+const rateLimitWindow = time.Second
+
+var (
+	// ErrUnauthorized will be returned when the request does not carry the expected auth token
+	ErrUnauthorized = errors.New("Unauthorized request.")
+
+	// ErrRateLimited will be returned when the client sends too many requests
+	ErrRateLimited = errors.New("Too many requests.")
+
+	// ErrThrottled will be returned when the soft limits of the RPC subsystem are reached
+	ErrThrottled = errors.New("Server is busy.")
+)
+
+// authFilter handles the incoming requests and validates the bearer token
+// in the Authorization header.
+type authFilter struct {
+	token   []byte
+	handler http.Handler
+}
+
+// NewAuthHandler returns a http handler which only passes the requests with the
+// specified bearer token to the handler. If token is empty, the handler is returned.
+func NewAuthHandler(token string, handler http.Handler) http.Handler {
+	if len(token) == 0 {
+		return handler
+	}
+
+	return &authFilter{[]byte(token), handler}
+}
+
+// ServeHTTP handles the incoming requests and validates the Authorization header
+func (f *authFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") ||
+		subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), f.token) != 1 {
+		http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	f.handler.ServeHTTP(w, r)
+}
+
+// rateWindow is the requests count of a client in the current window
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// rateLimitFilter handles the incoming requests and limits the requests
+// per second of each client IP.
+type rateLimitFilter struct {
+	limit   int
+	handler http.Handler
+
+	lock    sync.Mutex
+	windows map[string]*rateWindow
+}
+
+// NewRateLimitHandler returns a http handler which limits each client IP to send at most
+// limit requests per second to the handler. If limit is 0, the handler is returned.
+func NewRateLimitHandler(limit int, handler http.Handler) http.Handler {
+	if limit <= 0 {
+		return handler
+	}
+
+	return &rateLimitFilter{
+		limit:   limit,
+		handler: handler,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+// ServeHTTP handles the incoming requests and rejects the ones exceed the rate limit
+func (f *rateLimitFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !f.allow(host, time.Now()) {
+		http.Error(w, ErrRateLimited.Error(), http.StatusTooManyRequests)
+		return
+	}
+
+	f.handler.ServeHTTP(w, r)
+}
+
+func (f *rateLimitFilter) allow(host string, now time.Time) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	window := f.windows[host]
+	if window == nil || now.Sub(window.start) >= rateLimitWindow {
+		// remove the expired windows to bound the memory
+		for h, w := range f.windows {
+			if now.Sub(w.start) >= rateLimitWindow {
+				delete(f.windows, h)
+			}
+		}
+
+		window = &rateWindow{start: now}
+		f.windows[host] = window
+	}
+
+	if window.count >= f.limit {
+		return false
+	}
+
+	window.count++
+	return true
+}
+
+// resourceFilter handles the incoming requests and accounts them to a subsystem.
+type resourceFilter struct {
+	subsystem *resource.Subsystem
+	handler   http.Handler
+}
+
+// NewResourceHandler returns a http handler which accounts the requests being served to the
+// specified subsystem, and rejects the new requests when its soft limits are reached.
+func NewResourceHandler(subsystem *resource.Subsystem, handler http.Handler) http.Handler {
+	return &resourceFilter{subsystem, handler}
+}
+
+// ServeHTTP handles the incoming requests and rejects them if the subsystem is throttled
+func (f *resourceFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.subsystem.Throttle() {
+		http.Error(w, ErrThrottled.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	done := f.subsystem.Track()
+	defer done()
+
+	f.handler.ServeHTTP(w, r)
+}
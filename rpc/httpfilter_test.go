@@ -0,0 +1,66 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seeleteam/go-seele/common/resource"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+func testFilterStatus(t *testing.T, handler http.Handler, token string, expected int) {
+	req := httptest.NewRequest(http.MethodPost, "http://url.com", strings.NewReader(""))
+	if len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != expected {
+		t.Fatalf("unexpected status code %d, want %d", w.Code, expected)
+	}
+}
+
+func Test_AuthHandler(t *testing.T) {
+	testFilterStatus(t, NewAuthHandler("", okHandler), "", http.StatusOK)
+
+	handler := NewAuthHandler("secret", okHandler)
+	testFilterStatus(t, handler, "", http.StatusUnauthorized)
+	testFilterStatus(t, handler, "wrong", http.StatusUnauthorized)
+	testFilterStatus(t, handler, "secret", http.StatusOK)
+}
+
+func Test_RateLimitHandler(t *testing.T) {
+	handler := NewRateLimitHandler(2, okHandler)
+	testFilterStatus(t, handler, "", http.StatusOK)
+	testFilterStatus(t, handler, "", http.StatusOK)
+	testFilterStatus(t, handler, "", http.StatusTooManyRequests)
+
+	// new window
+	filter := handler.(*rateLimitFilter)
+	if !filter.allow("192.0.2.1", time.Now().Add(rateLimitWindow)) {
+		t.Fatal("rate limit should be reset in new window")
+	}
+}
+
+func Test_ResourceHandler(t *testing.T) {
+	subsystem := resource.Register("rpc_test")
+	handler := NewResourceHandler(subsystem, okHandler)
+	testFilterStatus(t, handler, "", http.StatusOK)
+
+	subsystem.SetLimits(resource.Limits{Goroutines: 1})
+	done := subsystem.Track()
+	testFilterStatus(t, handler, "", http.StatusServiceUnavailable)
+
+	done()
+	testFilterStatus(t, handler, "", http.StatusOK)
+}
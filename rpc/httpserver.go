@@ -6,12 +6,16 @@
 package rpc
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/rpc"
 	"strings"
+	"time"
 
 	"github.com/rs/cors"
 )
@@ -24,12 +28,17 @@ var (
 // HTTPServer represents a HTTP RPC server
 type HTTPServer struct {
 	rpc.Server
+
+	stamp   *StampPolicy   // proof-of-work stamp policy, nil if not required
+	keys    KeyAuthorizer  // authorizer of the API keys, nil if not required
+	timeout time.Duration  // deadline of each request, 0 for no deadline
+	cache   *ResponseCache // cache of the historical responses, nil if disabled
 }
 
 // NewHTTPServer returns a new HttpServer and a http handler used by cors
 func NewHTTPServer(whitehosts []string, corsList []string) (*HTTPServer, *hostFilter) {
 	server := &HTTPServer{
-		rpc.Server{},
+		Server: rpc.Server{},
 	}
 	// cors
 	c := cors.New(cors.Options{
@@ -49,18 +58,64 @@ func NewHTTPServer(whitehosts []string, corsList []string) (*HTTPServer, *hostFi
 	return server, &hFilter
 }
 
+// SetStampPolicy sets the proof-of-work stamp policy for the JSON requests.
+// Note, the CONNECT method is disabled if the policy is not nil, since the
+// gob encoded requests cannot carry a stamp.
+func (server *HTTPServer) SetStampPolicy(policy *StampPolicy) {
+	server.stamp = policy
+}
+
+// SetKeyAuthorizer requires the JSON requests to carry an API key in the APIKeyHeader
+// header, which is authorized by the specified authorizer. Note, the CONNECT method is
+// disabled if the authorizer is not nil, since the gob encoded requests are not authorized.
+func (server *HTTPServer) SetKeyAuthorizer(authorizer KeyAuthorizer) {
+	server.keys = authorizer
+}
+
+// SetRequestTimeout sets the deadline of each request passed via RequestContext.
+func (server *HTTPServer) SetRequestTimeout(timeout time.Duration) {
+	server.timeout = timeout
+}
+
+// SetResponseCache sets the cache of the historical responses for the JSON requests.
+func (server *HTTPServer) SetResponseCache(cache *ResponseCache) {
+	server.cache = cache
+}
+
 // ServeHTTP implements an http.Handler that answers RPC requests.
 // Supports POST and CONNECT http method.
 // POST handles requests from the browser
 // CONNECT handles requests form other go rpc.Client
 func (server *HTTPServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	switch req.Method {
-	case http.MethodConnect:
+	switch {
+	case req.Method == http.MethodConnect && server.stamp == nil && server.keys == nil:
 		server.Server.ServeHTTP(w, req)
-	case http.MethodPost:
+	case req.Method == http.MethodPost:
 		w.Header().Set("Content-Type", "application/json")
-		conn := &httpReadWriteCloser{req.Body, w}
-		server.ServeRequest(NewJsonCodec(conn))
+		body := io.Reader(req.Body)
+		if server.cache != nil {
+			encoded, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if server.serveCached(w, encoded, req.Header.Get(APIKeyHeader)) {
+				return
+			}
+
+			body = bytes.NewReader(encoded)
+		}
+
+		conn := &httpReadWriteCloser{body, w}
+		server.ServeRequest(NewJsonCodecWithConfig(conn, CodecConfig{
+			Stamp:   server.stamp,
+			Context: req.Context(),
+			Timeout: server.timeout,
+			Keys:    server.keys,
+			APIKey:  req.Header.Get(APIKeyHeader),
+			Cache:   server.cache,
+		}))
 	default:
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -68,6 +123,33 @@ func (server *HTTPServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// serveCached writes the cached response of the request if any, which is authorized the same
+// as the requests served by the codec, and returns false if not served.
+func (server *HTTPServer) serveCached(w io.Writer, body []byte, apiKey string) bool {
+	var req jsonRequest
+	if err := json.Unmarshal(body, &req); err != nil || !server.cache.cacheable(req.Method) {
+		return false
+	}
+
+	result := server.cache.get(req.Method, req.Params)
+	if result == nil {
+		return false
+	}
+
+	// the request is authorized and accounted once served, otherwise by the codec
+	if authorizeKey(server.keys, apiKey, req.Method) != nil || server.stamp.verify(req.Method, req.Params, req.Stamp) != nil {
+		return false
+	}
+
+	id := req.Id
+	if id == nil {
+		id = &null
+	}
+
+	json.NewEncoder(w).Encode(jsonResponse{Version: jsonrpcVersion, Id: id, Result: result})
+	return true
+}
+
 // httpReadWriteCloser wraps a io.Reader and io.Writer
 type httpReadWriteCloser struct {
 	io.Reader
@@ -5,11 +5,13 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/rpc"
 	"sync"
+	"time"
 )
 
 var errMissingParams = errors.New("jsonrpc: request body missing params")
@@ -32,18 +34,78 @@ type jsonCodec struct {
 	// but save the original request ID in the pending map.
 	// When rpc responds, we use the sequence number in
 	// the response to find the original request ID.
-	mutex   sync.Mutex // protects seq, pending
+	mutex   sync.Mutex // protects seq, pending, cancels
 	seq     uint64
 	pending map[uint64]*json.RawMessage
+	cancels map[uint64]context.CancelFunc // cancels the context of pending requests
+
+	// stamp is the proof-of-work stamp policy, nil if not required.
+	stamp *StampPolicy
+
+	// keys authorizes the requests with apiKey, nil if not required.
+	keys   KeyAuthorizer
+	apiKey string
+
+	// cache caches the responses of the historical data, nil if disabled.
+	cache  *ResponseCache
+	cached map[uint64]*jsonRequest // requests of the cache methods, protected by mutex
+
+	// ctx is the context of the connection, which is cancelled when the client hangs up.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+// CodecConfig is the configuration of the JSON-RPC codec.
+type CodecConfig struct {
+	// Stamp is the proof-of-work stamp policy, nil if not required.
+	Stamp *StampPolicy
+
+	// Context is the parent context of requests, e.g. the HTTP request context. Default is context.Background().
+	Context context.Context
+
+	// Timeout is the deadline of each request passed via RequestContext, 0 for no deadline.
+	Timeout time.Duration
+
+	// Keys authorizes the requests of APIKey, nil if API keys are not required.
+	Keys KeyAuthorizer
+
+	// APIKey is the API key of the requests, e.g. from the HTTP header.
+	APIKey string
+
+	// Cache caches the responses of the historical data, nil if disabled.
+	Cache *ResponseCache
 }
 
 // NewJsonCodec returns a new rpc.ServerCodec using JSON-RPC on conn.
 func NewJsonCodec(conn io.ReadWriteCloser) rpc.ServerCodec {
+	return NewJsonCodecWithConfig(conn, CodecConfig{})
+}
+
+// NewJsonCodecWithConfig returns a new rpc.ServerCodec using JSON-RPC on conn
+// with the specified config.
+func NewJsonCodecWithConfig(conn io.ReadWriteCloser, config CodecConfig) rpc.ServerCodec {
+	parent := config.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+
 	return &jsonCodec{
 		dec:     json.NewDecoder(conn),
 		enc:     json.NewEncoder(conn),
 		c:       conn,
 		pending: make(map[uint64]*json.RawMessage),
+		cancels: make(map[uint64]context.CancelFunc),
+		stamp:   config.Stamp,
+		keys:    config.Keys,
+		apiKey:  config.APIKey,
+		cache:   config.Cache,
+		cached:  make(map[uint64]*jsonRequest),
+		ctx:     ctx,
+		cancel:  cancel,
+		timeout: config.Timeout,
 	}
 }
 
@@ -52,12 +114,14 @@ type jsonRequest struct {
 	Method  string           `json:"method"`
 	Params  *json.RawMessage `json:"params"`
 	Id      *json.RawMessage `json:"id"`
+	Stamp   *uint64          `json:"stamp"`
 }
 
 func (r *jsonRequest) reset() {
 	r.Method = ""
 	r.Params = nil
 	r.Id = nil
+	r.Stamp = nil
 }
 
 type jsonResponse struct {
@@ -70,6 +134,8 @@ type jsonResponse struct {
 func (c *jsonCodec) ReadRequestHeader(r *rpc.Request) error {
 	c.req.reset()
 	if err := c.dec.Decode(&c.req); err != nil {
+		// client hangs up, abort the pending requests
+		c.cancel()
 		return err
 	}
 	r.ServiceMethod = c.req.Method
@@ -80,6 +146,9 @@ func (c *jsonCodec) ReadRequestHeader(r *rpc.Request) error {
 	c.mutex.Lock()
 	c.seq++
 	c.pending[c.seq] = c.req.Id
+	if c.cache.cacheable(c.req.Method) {
+		c.cached[c.seq] = &jsonRequest{Method: c.req.Method, Params: c.req.Params}
+	}
 	c.req.Id = nil
 	r.Seq = c.seq
 	c.mutex.Unlock()
@@ -94,13 +163,48 @@ func (c *jsonCodec) ReadRequestBody(x interface{}) error {
 	if c.req.Params == nil {
 		return errMissingParams
 	}
+	if err := authorizeKey(c.keys, c.apiKey, c.req.Method); err != nil {
+		return err
+	}
+	if err := c.stamp.verify(c.req.Method, c.req.Params, c.req.Stamp); err != nil {
+		return err
+	}
 	// JSON params is array value.
 	// RPC params is struct.
 	// Unmarshal into array containing struct for now.
 	// Should think about making RPC more general.
 	var params [1]interface{}
 	params[0] = x
-	return json.Unmarshal(*c.req.Params, &params)
+	if err := json.Unmarshal(*c.req.Params, &params); err != nil {
+		return err
+	}
+
+	if receiver, ok := x.(contextReceiver); ok {
+		receiver.setContext(c.newRequestContext())
+	}
+
+	return nil
+}
+
+// newRequestContext creates the context of the current request, which is
+// cancelled after the response is written.
+func (c *jsonCodec) newRequestContext() context.Context {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(c.ctx, c.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(c.ctx)
+	}
+
+	c.mutex.Lock()
+	c.cancels[c.seq] = cancel
+	c.mutex.Unlock()
+
+	return ctx
 }
 
 var null = json.RawMessage([]byte("null"))
@@ -113,6 +217,12 @@ func (c *jsonCodec) WriteResponse(r *rpc.Response, x interface{}) error {
 		return errors.New("invalid sequence number in response")
 	}
 	delete(c.pending, r.Seq)
+	if cancel, ok := c.cancels[r.Seq]; ok {
+		cancel()
+		delete(c.cancels, r.Seq)
+	}
+	cached := c.cached[r.Seq]
+	delete(c.cached, r.Seq)
 	c.mutex.Unlock()
 
 	if b == nil {
@@ -122,6 +232,13 @@ func (c *jsonCodec) WriteResponse(r *rpc.Response, x interface{}) error {
 	resp := jsonResponse{Version: jsonrpcVersion, Id: b}
 	if r.Error == "" {
 		resp.Result = x
+		if cached != nil {
+			// encode the result once for both the cache and the response
+			if result, err := json.Marshal(x); err == nil {
+				c.cache.put(cached.Method, cached.Params, x, result)
+				resp.Result = json.RawMessage(result)
+			}
+		}
 	} else {
 		resp.Error = r.Error
 	}
@@ -129,6 +246,7 @@ func (c *jsonCodec) WriteResponse(r *rpc.Response, x interface{}) error {
 }
 
 func (c *jsonCodec) Close() error {
+	c.cancel()
 	return c.c.Close()
 }
 
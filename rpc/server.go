@@ -27,6 +27,10 @@ type API struct {
 	Service interface{}
 	// indication if the methods must be considered safe for public use
 	Public bool
+	// methods that require a proof-of-work stamp on endpoints with a stamp policy
+	StampMethods map[string]StampHasher
+	// methods of which the responses of the historical data are cached on endpoints with a response cache
+	CacheMethods map[string]CacheHeight
 }
 
 // RPCService offers meta information of the server.
@@ -0,0 +1,130 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+const maxStampBits = 64
+
+var (
+	// ErrStampMissing is returned when the request requires a stamp but none is provided.
+	ErrStampMissing = errors.New("proof-of-work stamp missing")
+
+	// ErrStampInvalid is returned when the stamp does not satisfy the required difficulty.
+	ErrStampInvalid = errors.New("proof-of-work stamp is invalid")
+)
+
+// StampHasher derives the hash that the stamp is computed on from the raw request params.
+type StampHasher func(params json.RawMessage) (common.Hash, error)
+
+// StampPolicy requires the requests of the registered methods to carry a small
+// proof-of-work stamp, so that unauthenticated callers have to pay some CPU time
+// for each request, e.g. transaction submission on a public gateway.
+type StampPolicy struct {
+	bits    uint
+	methods map[string]StampHasher // service method => hasher
+}
+
+// NewStampPolicy creates a stamp policy with the specified difficulty, which is the
+// number of leading zero bits required in the stamp hash. Returns nil if bits is 0.
+func NewStampPolicy(bits uint) *StampPolicy {
+	if bits == 0 {
+		return nil
+	}
+
+	if bits > maxStampBits {
+		bits = maxStampBits
+	}
+
+	return &StampPolicy{
+		bits:    bits,
+		methods: make(map[string]StampHasher),
+	}
+}
+
+// Require requires the specified service method, e.g. seele.AddTx, to carry a stamp
+// over the hash derived by the hasher.
+func (policy *StampPolicy) Require(serviceMethod string, hasher StampHasher) {
+	policy.methods[serviceMethod] = hasher
+}
+
+// RequireAPIs requires all stamp methods declared in the specified APIs.
+func (policy *StampPolicy) RequireAPIs(apis []API) {
+	for _, api := range apis {
+		for method, hasher := range api.StampMethods {
+			policy.Require(api.Namespace+"."+method, hasher)
+		}
+	}
+}
+
+// verify verifies the stamp of the specified request if required.
+func (policy *StampPolicy) verify(serviceMethod string, params *json.RawMessage, stamp *uint64) error {
+	if policy == nil {
+		return nil
+	}
+
+	hasher, ok := policy.methods[serviceMethod]
+	if !ok {
+		return nil
+	}
+
+	if stamp == nil {
+		return ErrStampMissing
+	}
+
+	if params == nil {
+		return errMissingParams
+	}
+
+	hash, err := hasher(*params)
+	if err != nil {
+		return err
+	}
+
+	if !VerifyStamp(hash, *stamp, policy.bits) {
+		return ErrStampInvalid
+	}
+
+	return nil
+}
+
+// stampHash returns the hash of the specified hash and stamp.
+func stampHash(hash common.Hash, stamp uint64) common.Hash {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, stamp)
+	return crypto.HashBytes(hash.Bytes(), encoded)
+}
+
+// stampTarget returns the maximum stamp hash value for the specified difficulty bits.
+func stampTarget(bits uint) *big.Int {
+	return new(big.Int).Rsh(new(big.Int).Lsh(big.NewInt(1), 256), bits)
+}
+
+// VerifyStamp verifies that the stamp hash over the specified hash
+// has at least the specified number of leading zero bits.
+func VerifyStamp(hash common.Hash, stamp uint64, bits uint) bool {
+	return stampHash(hash, stamp).Big().Cmp(stampTarget(bits)) < 0
+}
+
+// ComputeStamp searches and returns a stamp for the specified hash
+// that satisfies the specified number of leading zero bits.
+func ComputeStamp(hash common.Hash, bits uint) uint64 {
+	target := stampTarget(bits)
+
+	for stamp := uint64(0); ; stamp++ {
+		if stampHash(hash, stamp).Big().Cmp(target) < 0 {
+			return stamp
+		}
+	}
+}
@@ -0,0 +1,56 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func testStampHasher(params json.RawMessage) (common.Hash, error) {
+	return crypto.HashBytes(params), nil
+}
+
+func Test_ComputeStamp(t *testing.T) {
+	hash := crypto.HashBytes([]byte("test stamp"))
+
+	stamp := ComputeStamp(hash, 8)
+	assert.Equal(t, VerifyStamp(hash, stamp, 8), true)
+	assert.Equal(t, VerifyStamp(crypto.HashBytes([]byte("other")), stamp, 20), false)
+}
+
+func Test_NewStampPolicy(t *testing.T) {
+	assert.Equal(t, NewStampPolicy(0) == nil, true)
+	assert.Equal(t, NewStampPolicy(100).bits, uint(maxStampBits))
+
+	// nil policy requires nothing
+	var policy *StampPolicy
+	assert.Equal(t, policy.verify("Arith.Add", nil, nil), error(nil))
+}
+
+func Test_StampPolicy_Verify(t *testing.T) {
+	policy := NewStampPolicy(8)
+	policy.RequireAPIs([]API{
+		{Namespace: "Arith", StampMethods: map[string]StampHasher{"Add": testStampHasher}},
+	})
+
+	params := json.RawMessage(`[{"A":1,"B":2}]`)
+	stamp := ComputeStamp(crypto.HashBytes(params), 8)
+	invalid := stamp + 1
+	for VerifyStamp(crypto.HashBytes(params), invalid, 8) {
+		invalid++
+	}
+
+	assert.Equal(t, policy.verify("Arith.Mul", &params, nil), error(nil))
+	assert.Equal(t, policy.verify("Arith.Add", &params, nil), ErrStampMissing)
+	assert.Equal(t, policy.verify("Arith.Add", &params, &invalid), ErrStampInvalid)
+	assert.Equal(t, policy.verify("Arith.Add", nil, &stamp), errMissingParams)
+	assert.Equal(t, policy.verify("Arith.Add", &params, &stamp), error(nil))
+}
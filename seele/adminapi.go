@@ -0,0 +1,290 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/p2p"
+	"github.com/seeleteam/go-seele/seele/apikey"
+	"github.com/seeleteam/go-seele/seele/backup"
+)
+
+const (
+	// pingPeerTimeout is the max time to wait for the pong of the peer.
+	pingPeerTimeout = 5 * time.Second
+
+	// dialTestTimeout is the max time of each stage of the dial test.
+	dialTestTimeout = 15 * time.Second
+)
+
+// errNegativeTxFeeFloor is returned when the minimum fee per byte of the tx pool is negative.
+var errNegativeTxFeeFloor = errors.New("negative tx fee floor")
+
+// PrivateAdminAPI provides an API to administrate the node.
+type PrivateAdminAPI struct {
+	s *SeeleService
+}
+
+// NewPrivateAdminAPI creates a new PrivateAdminAPI object for rpc service.
+func NewPrivateAdminAPI(s *SeeleService) *PrivateAdminAPI {
+	return &PrivateAdminAPI{s}
+}
+
+// SetPeerTraceRequest request param for SetPeerTrace api
+type SetPeerTraceRequest struct {
+	Peer    string // node id of the connected peer in hex
+	Enabled bool
+	Raw     bool // capture the raw payloads in hex
+}
+
+// SetPeerTrace enables or disables the message tracing of the connected peer,
+// and the traced messages could be retrieved via debug.P2pTrace.
+func (api *PrivateAdminAPI) SetPeerTrace(request *SetPeerTraceRequest, result *bool) error {
+	id, err := common.HexToAddress(request.Peer)
+	if err != nil {
+		return err
+	}
+
+	if err = api.s.p2pServer.SetPeerTrace(id, request.Enabled, request.Raw); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// Backup takes a consistent backup of the chain databases into the specified folder while
+// the node is running, and returns the manifest of the verified backup.
+func (api *PrivateAdminAPI) Backup(path *string, result *backup.Manifest) error {
+	manifest, err := api.s.backuper.Backup(*path)
+	if err != nil {
+		return err
+	}
+
+	*result = *manifest
+	return nil
+}
+
+// NetworkSummary returns the counts of the client versions and fork ids of the connected peers,
+// which helps to schedule the forks once most of the network upgrades.
+func (api *PrivateAdminAPI) NetworkSummary(input interface{}, result *NetworkSummary) error {
+	*result = *api.s.telemetry.summary(api.s.seeleProtocol.peerSet)
+	return nil
+}
+
+// PingResult is the protocol-level round trip time of the connected peer.
+type PingResult struct {
+	Peer string  // node id of the connected peer in hex
+	RTT  float64 // RTT is the round trip time in milliseconds
+}
+
+// PingPeer measures the protocol-level round trip time of the connected peer of the node id
+// in hex, via the ping and pong control messages.
+func (api *PrivateAdminAPI) PingPeer(peer *string, result *PingResult) error {
+	id, err := common.HexToAddress(*peer)
+	if err != nil {
+		return err
+	}
+
+	rtt, err := api.s.p2pServer.PingPeer(id, pingPeerTimeout)
+	if err != nil {
+		return err
+	}
+
+	*result = PingResult{*peer, float64(rtt) / float64(time.Millisecond)}
+	return nil
+}
+
+// DialTest dials the node of "snode://<id>@<ip>:<port>" and performs the full handshake without
+// adding it as a peer, and reports the stage where it failed, see p2p.DialResult.
+func (api *PrivateAdminAPI) DialTest(node *string, result *p2p.DialResult) error {
+	*result = *api.s.p2pServer.DialTest(*node, dialTestTimeout)
+	return nil
+}
+
+// AddCheckpoint finalizes the canonical block of the checkpoint signed by the checkpoint authority.
+func (api *PrivateAdminAPI) AddCheckpoint(checkpoint *core.Checkpoint, result *bool) error {
+	if err := api.s.chain.AddCheckpoint(checkpoint); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// SetTxFeeFloor sets the minimum fee per byte of the txs accepted by the tx pool at runtime,
+// see core.FeeFloor, in which the txs already in the pool are kept.
+func (api *PrivateAdminAPI) SetTxFeeFloor(floor *core.FeeFloor, result *bool) error {
+	for _, fee := range []*big.Int{floor.FeePerByte, floor.PayloadFeePerByte} {
+		if fee != nil && fee.Sign() < 0 {
+			return errNegativeTxFeeFloor
+		}
+	}
+
+	api.s.TxPool().SetFeeFloor(*floor)
+	*result = true
+	return nil
+}
+
+// CreateAPIKey creates an API key of the HTTP rpc listeners requiring API keys, and returns the key
+// along with the secret. The admin APIs are never reachable with the API keys, even for all methods.
+func (api *PrivateAdminAPI) CreateAPIKey(request *apikey.CreateKeyRequest, result *apikey.CreatedKey) error {
+	key, secret, err := api.s.apiKeys.Create(request.Name, request.Methods, request.RateLimit, request.DailyQuota)
+	if err != nil {
+		return err
+	}
+
+	*result = apikey.CreatedKey{Key: key, Secret: secret}
+	return nil
+}
+
+// RevokeAPIKey revokes the API key of the specified id.
+func (api *PrivateAdminAPI) RevokeAPIKey(id *uint64, result *bool) error {
+	if err := api.s.apiKeys.Revoke(*id); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// GetAPIKeys returns all API keys, including the revoked ones.
+func (api *PrivateAdminAPI) GetAPIKeys(input interface{}, result *[]*apikey.Key) error {
+	*result = api.s.apiKeys.Keys()
+	return nil
+}
+
+// GetAPIKeyUsage returns the usage of the API key of the specified id.
+func (api *PrivateAdminAPI) GetAPIKeyUsage(id *uint64, result *apikey.Usage) error {
+	usage, err := api.s.apiKeys.Usage(*id)
+	if err != nil {
+		return err
+	}
+
+	*result = *usage
+	return nil
+}
+
+// GetAPIKeyUsages returns the usage of all API keys.
+func (api *PrivateAdminAPI) GetAPIKeyUsages(input interface{}, result *[]*apikey.Usage) error {
+	keys := api.s.apiKeys.Keys()
+	usages := make([]*apikey.Usage, 0, len(keys))
+	for _, key := range keys {
+		usage, err := api.s.apiKeys.Usage(key.ID)
+		if err != nil {
+			return err
+		}
+
+		usages = append(usages, usage)
+	}
+
+	*result = usages
+	return nil
+}
+
+// txPoolDumpVersion is the version of the tx pool dump file format.
+const txPoolDumpVersion = 1
+
+// errInvalidDumpName is returned when the tx pool dump file name is not a plain file name.
+var errInvalidDumpName = errors.New("invalid tx pool dump file name, should be a file name without directory")
+
+// TxPoolDump is the dump file of the txs in the pool.
+type TxPoolDump struct {
+	Version      uint
+	Transactions []*core.PooledTransaction
+}
+
+// TxPoolImportResult is the result to import the tx pool dump.
+type TxPoolImportResult struct {
+	Imported int
+	Failures map[string]string // Failures are the errors of the txs failed to import by the tx hash in hex
+}
+
+// txPoolDumpPath returns the path of the tx pool dump file of the specified name,
+// which is always in the tx pool dump folder under the data folder of the node.
+func (api *PrivateAdminAPI) txPoolDumpPath(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || filepath.IsAbs(name) || filepath.Base(name) != name {
+		return "", errInvalidDumpName
+	}
+
+	return filepath.Join(api.s.dataDir, TxPoolDumpDir, name), nil
+}
+
+// ExportTxPool dumps all the txs in the pool with the arrival time and local flag into the file
+// of the specified name in the tx pool dump folder of the node, and returns the number of the exported txs.
+func (api *PrivateAdminAPI) ExportTxPool(name *string, result *int) error {
+	path, err := api.txPoolDumpPath(*name)
+	if err != nil {
+		return err
+	}
+
+	dump := TxPoolDump{
+		Version:      txPoolDumpVersion,
+		Transactions: api.s.TxPool().ExportTransactions(),
+	}
+
+	encoded, err := json.MarshalIndent(dump, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(path, encoded, 0600); err != nil {
+		return err
+	}
+
+	*result = len(dump.Transactions)
+	return nil
+}
+
+// ImportTxPool loads the txs from the dump file of the specified name in the tx pool dump folder
+// of the node into the pool, e.g. to migrate the pending txs from another node. The invalid txs are skipped.
+func (api *PrivateAdminAPI) ImportTxPool(name *string, result *TxPoolImportResult) error {
+	path, err := api.txPoolDumpPath(*name)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var dump TxPoolDump
+	if err = json.Unmarshal(encoded, &dump); err != nil {
+		return err
+	}
+
+	if dump.Version != txPoolDumpVersion {
+		return fmt.Errorf("unsupported tx pool dump version %d", dump.Version)
+	}
+
+	failures := api.s.TxPool().ImportTransactions(dump.Transactions)
+
+	*result = TxPoolImportResult{
+		Imported: len(dump.Transactions) - len(failures),
+		Failures: make(map[string]string),
+	}
+
+	for hash, err := range failures {
+		result.Failures[hash.ToHex()] = err.Error()
+	}
+
+	return nil
+}
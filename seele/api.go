@@ -6,6 +6,8 @@
 package seele
 
 import (
+	"encoding/json"
+	"errors"
 	"math/big"
 
 	"github.com/seeleteam/go-seele/common"
@@ -14,6 +16,19 @@ import (
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/miner"
 	"github.com/seeleteam/go-seele/p2p"
+	"github.com/seeleteam/go-seele/rpc"
+	"github.com/seeleteam/go-seele/seele/balance"
+	"github.com/seeleteam/go-seele/seele/hashrate"
+	"github.com/seeleteam/go-seele/seele/label"
+	"github.com/seeleteam/go-seele/seele/logindex"
+)
+
+var (
+	errInvalidTxParams     = errors.New("invalid transaction params")
+	errNoPoolAccounting    = errors.New("no pool accounting data in the block")
+	errEscrowNotConfigured = errors.New("escrow account not configured")
+	errStratumDisabled     = errors.New("stratum server disabled")
+	errReceiptNotFound     = errors.New("receipt not found in the canonical chain")
 )
 
 // PublicSeeleAPI provides an API to access full node-related information.
@@ -28,6 +43,7 @@ func NewPublicSeeleAPI(s *SeeleService) *PublicSeeleAPI {
 
 // MinerInfo miner simple info
 type MinerInfo struct {
+	NetworkID          uint64
 	Coinbase           common.Address
 	CurrentBlockHeight uint64
 	HeaderHash         common.Hash
@@ -45,11 +61,91 @@ type GetBlockByHashRequest struct {
 	FullTx  bool
 }
 
+// PoolAccountingInfo is the pool accounting data committed in the reward tx of a block
+type PoolAccountingInfo struct {
+	Height     uint64
+	Creator    common.Address
+	Accounting *types.PoolAccounting
+
+	// EscrowVerified is true if the block creator is the escrow account of the accounting data
+	EscrowVerified bool
+}
+
+// HTLCInfo is the state of the HTLC at the chain head.
+type HTLCInfo struct {
+	Address common.Address // Address is the HTLC address to lock the funds by transfer
+	Balance *big.Int       // Balance is the locked funds
+	Nonce   uint64         // Nonce is the number of the claim and refund txs of the HTLC
+	Height  uint64         // Height is the height of the chain head
+	Expired bool           // Expired is true if the funds could only be refunded in the next block
+}
+
+// SubmitWorkRequest is the nonce found by an external miner for the work of ID.
+type SubmitWorkRequest struct {
+	ID    common.Hash `json:"id"`
+	Nonce uint64      `json:"nonce"`
+}
+
+// GetWork API returns the block being mined for the external miners, which
+// find the nonce of the block and submit it via SubmitWork.
+func (api *PublicMinerAPI) GetWork(input interface{}, result *miner.Work) error {
+	work, err := api.s.miner.GetWork()
+	if err != nil {
+		return err
+	}
+
+	*result = *work
+	return nil
+}
+
+// SubmitWork API submits the nonce found by an external miner.
+func (api *PublicMinerAPI) SubmitWork(request *SubmitWorkRequest, result *bool) error {
+	if err := api.s.miner.SubmitWork(request.ID, request.Nonce); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// GetStratumStats API returns the share statistics of the workers of the stratum server.
+func (api *PublicMinerAPI) GetStratumStats(input interface{}, result *[]*miner.WorkerStats) error {
+	if api.s.stratum == nil {
+		return errStratumDisabled
+	}
+
+	*result = api.s.stratum.Stats()
+	return nil
+}
+
+// SetPoolAccountingRequest request param for SetPoolAccounting api
+type SetPoolAccountingRequest struct {
+	Round      uint64
+	Shares     uint64
+	SharesRoot common.Hash
+}
+
+// NewBalanceFilterRequest request param for NewBalanceFilter api
+type NewBalanceFilterRequest struct {
+	Addresses []common.Address
+	MinDelta  *big.Int
+}
+
+// AddressValidation is the result of ValidateAddress api
+type AddressValidation struct {
+	Valid       bool           // Valid indicates the address is a well-formed hex of an address
+	Checksummed bool           // Checksummed indicates the address hex is in mixed case
+	Address     common.Address // Address is the parsed address, which is encoded in the checksummed form
+	Checksum    string         // Checksum is the checksummed hex of the address
+	Error       string         // Error is the reason why the address is invalid
+}
+
 // GetInfo gets the account address that mining rewards will be send to.
 func (api *PublicSeeleAPI) GetInfo(input interface{}, info *MinerInfo) error {
 	block, _ := api.s.chain.CurrentBlock()
 
 	*info = MinerInfo{
+		NetworkID:          api.s.networkID,
 		Coinbase:           api.s.Coinbase,
 		CurrentBlockHeight: block.Header.Height,
 		HeaderHash:         block.HeaderHash,
@@ -70,9 +166,32 @@ func (api *PublicSeeleAPI) GetBalance(account *common.Address, result *big.Int)
 	return nil
 }
 
+// ValidateAddress validates the format and checksum of the address hex. A mixed-case hex
+// is invalid if it does not match the checksum, while a single-case hex is valid but not checksummed.
+func (api *PublicSeeleAPI) ValidateAddress(address *string, result *AddressValidation) error {
+	if address == nil {
+		return errors.New("address is required")
+	}
+
+	addr, checksummed, err := common.HexToChecksummedAddress(*address)
+	if err != nil {
+		*result = AddressValidation{Checksummed: checksummed, Error: err.Error()}
+		return nil
+	}
+
+	*result = AddressValidation{
+		Valid:       true,
+		Checksummed: checksummed,
+		Address:     addr,
+		Checksum:    addr.ToChecksumHex(),
+	}
+
+	return nil
+}
+
 // AddTx add a tx to miner
 func (api *PublicSeeleAPI) AddTx(tx *types.Transaction, result *bool) error {
-	err := api.s.txPool.AddTransaction(tx)
+	err := api.s.txPool.AddLocalTransaction(tx)
 	if err != nil {
 		*result = false
 		return err
@@ -82,6 +201,21 @@ func (api *PublicSeeleAPI) AddTx(tx *types.Transaction, result *bool) error {
 	return nil
 }
 
+// txStampHasher returns the hash of the tx in the raw params of AddTx,
+// which the proof-of-work stamp of the request is computed on.
+func txStampHasher(params json.RawMessage) (common.Hash, error) {
+	var args [1]*types.Transaction
+	if err := json.Unmarshal(params, &args); err != nil {
+		return common.EmptyHash, err
+	}
+
+	if args[0] == nil || args[0].Data == nil {
+		return common.EmptyHash, errInvalidTxParams
+	}
+
+	return args[0].CalculateHash(), nil
+}
+
 // GetAccountNonce get account next used nonce
 func (api *PublicSeeleAPI) GetAccountNonce(account *common.Address, nonce *uint64) error {
 	state := api.s.chain.CurrentState()
@@ -98,6 +232,19 @@ func (api *PublicSeeleAPI) GetBlockHeight(input interface{}, height *uint64) err
 	return nil
 }
 
+// GetMedianTimePast returns the median timestamp of the block of the specified height and its
+// recent ancestors, against which the time-dependent rules of the next block are evaluated.
+// When height is -1 the chain head is used.
+func (api *PublicSeeleAPI) GetMedianTimePast(height *int64, result *uint64) error {
+	block, err := getBlock(api.s.chain, *height)
+	if err != nil {
+		return err
+	}
+
+	*result, err = api.s.chain.MedianTimePast(block.HeaderHash)
+	return err
+}
+
 // GetBlockByHeight returns the requested block. When blockNr is -1 the chain head is returned. When fullTx is true all
 // transactions in the block are returned in full detail, otherwise only the transaction hash is returned
 func (api *PublicSeeleAPI) GetBlockByHeight(request *GetBlockByHeightRequest, result *map[string]interface{}) error {
@@ -106,7 +253,7 @@ func (api *PublicSeeleAPI) GetBlockByHeight(request *GetBlockByHeightRequest, re
 		return err
 	}
 
-	response, err := rpcOutputBlock(block, request.FullTx)
+	response, err := rpcOutputBlock(block, request.FullTx, api.s.labels)
 	if err != nil {
 		return err
 	}
@@ -130,7 +277,7 @@ func (api *PublicSeeleAPI) GetBlockByHash(request *GetBlockByHashRequest, result
 		return err
 	}
 
-	response, err := rpcOutputBlock(block, request.FullTx)
+	response, err := rpcOutputBlock(block, request.FullTx, api.s.labels)
 	if err != nil {
 		return err
 	}
@@ -139,6 +286,296 @@ func (api *PublicSeeleAPI) GetBlockByHash(request *GetBlockByHashRequest, result
 	return nil
 }
 
+// GetPoolAccounting returns the pool accounting data committed in the reward tx of the block
+// at the specified height. When height is -1 the chain head is used.
+func (api *PublicSeeleAPI) GetPoolAccounting(height *int64, result *PoolAccountingInfo) error {
+	block, err := getBlock(api.s.chain, *height)
+	if err != nil {
+		return err
+	}
+
+	if len(block.Transactions) == 0 || len(block.Transactions[0].Data.Payload) == 0 {
+		return errNoPoolAccounting
+	}
+
+	accounting, err := block.Transactions[0].PoolAccounting()
+	if err != nil {
+		return err
+	}
+
+	*result = PoolAccountingInfo{
+		Height:         block.Header.Height,
+		Creator:        block.Header.Creator,
+		Accounting:     accounting,
+		EscrowVerified: block.Header.Creator.Equal(accounting.Escrow.Address()),
+	}
+
+	return nil
+}
+
+// GetHTLC returns the address and locked funds of the specified HTLC. The claim and refund of
+// the HTLC could be monitored via GetLogs with the HTLC address, in which the data of the log
+// with topic types.HTLCClaimTopic is the preimage.
+func (api *PublicSeeleAPI) GetHTLC(htlc *types.HTLC, result *HTLCInfo) error {
+	if err := htlc.Validate(); err != nil {
+		return err
+	}
+
+	head, state := api.s.chain.CurrentBlock()
+	address := htlc.Address()
+
+	*result = HTLCInfo{
+		Address: address,
+		Balance: state.GetBalance(address),
+		Nonce:   state.GetNonce(address),
+		Height:  head.Header.Height,
+		Expired: head.Header.Height+1 >= htlc.TimeLock,
+	}
+
+	return nil
+}
+
+// canonicalReceipt returns the receipts of the block in the canonical chain that packs the tx,
+// and the index of the receipt of the tx, or errReceiptNotFound if not packed.
+func (api *PublicSeeleAPI) canonicalReceipt(txHash common.Hash) (*types.BlockHeader, []*types.Receipt, int, error) {
+	store := api.s.chain.GetStore()
+	blockHash, err := store.GetReceiptBlockHash(txHash)
+	if err != nil {
+		return nil, nil, 0, errReceiptNotFound
+	}
+
+	header, err := store.GetBlockHeader(blockHash)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if canonicalHash, err := store.GetBlockHash(header.Height); err != nil || !canonicalHash.Equal(blockHash) {
+		return nil, nil, 0, errReceiptNotFound
+	}
+
+	receipts, err := store.GetReceipts(blockHash)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	for i, receipt := range receipts {
+		if receipt.TxHash.Equal(txHash) {
+			return header, receipts, i, nil
+		}
+	}
+
+	return nil, nil, 0, errReceiptNotFound
+}
+
+// GetReceiptProof returns the receipt of the specified tx in the canonical chain along with its merkle
+// proof, which could be verified against the receipt root in the block header via types.VerifyReceiptProof.
+func (api *PublicSeeleAPI) GetReceiptProof(txHash *common.Hash, result *types.ReceiptProof) error {
+	header, receipts, index, err := api.canonicalReceipt(*txHash)
+	if err != nil {
+		return err
+	}
+
+	proof, err := types.NewReceiptProof(header.Hash(), receipts, index)
+	if err != nil {
+		return err
+	}
+
+	*result = *proof
+	return nil
+}
+
+// Status of the tx, see TxStatus.
+const (
+	TxStatusUnknown  = "unknown"  // the tx is neither in the pool nor packed in the canonical chain
+	TxStatusPending  = "pending"  // the tx is in the pool
+	TxStatusIncluded = "included" // the tx is packed in the canonical chain
+)
+
+// TxStatus is the inclusion status of the tx in the canonical chain.
+type TxStatus struct {
+	Status        string
+	BlockHash     common.Hash    // BlockHash is the hash of the block that packs the tx, if included
+	BlockHeight   uint64         // BlockHeight is the height of the block that packs the tx, if included
+	Confirmations uint64         // Confirmations is the number of blocks since the block that packs the tx, including itself
+	Finalized     bool           // Finalized is true if the block that packs the tx is final
+	Receipt       *types.Receipt // Receipt is the receipt of the tx, if included
+}
+
+// GetTxStatus returns the inclusion status of the tx in the canonical chain, which is polled to
+// wait for the confirmations of the tx. The block that packs the tx changes on reorganization.
+func (api *PublicSeeleAPI) GetTxStatus(txHash *common.Hash, result *TxStatus) error {
+	header, receipts, index, err := api.canonicalReceipt(*txHash)
+	if err == errReceiptNotFound {
+		result.Status = TxStatusUnknown
+		if api.s.txPool.GetTransaction(*txHash) != nil {
+			result.Status = TxStatusPending
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*result = TxStatus{
+		Status:      TxStatusIncluded,
+		BlockHash:   header.Hash(),
+		BlockHeight: header.Height,
+		Receipt:     receipts[index],
+	}
+
+	// the HEAD block may change since the lookup
+	if head, _ := api.s.chain.CurrentBlock(); head.Header.Height >= header.Height {
+		result.Confirmations = head.Header.Height - header.Height + 1
+	}
+
+	finality, err := api.s.chain.Finality()
+	if err != nil {
+		return err
+	}
+
+	result.Finalized = header.Height <= finality.Height
+	return nil
+}
+
+// GetFinality returns the finality status of the canonical chain, in which the blocks not higher
+// than the finalized block could not be reorganized.
+func (api *PublicSeeleAPI) GetFinality(input interface{}, result *core.Finality) error {
+	finality, err := api.s.chain.Finality()
+	if err != nil {
+		return err
+	}
+
+	*result = *finality
+	return nil
+}
+
+// NewBalanceFilter creates a filter to track the balance changes of the specified accounts
+// upon block import or reorg, and only the changes not less than MinDelta are recorded.
+func (api *PublicSeeleAPI) NewBalanceFilter(request *NewBalanceFilterRequest, id *uint64) error {
+	*id = api.s.balanceWatcher.NewFilter(request.Addresses, request.MinDelta)
+	return nil
+}
+
+// GetBalanceFilterChanges returns the balance changes of the specified filter since last poll
+func (api *PublicSeeleAPI) GetBalanceFilterChanges(id *uint64, result *[]*balance.Change) error {
+	changes, err := api.s.balanceWatcher.GetFilterChanges(*id)
+	if err != nil {
+		return err
+	}
+
+	*result = changes
+	return nil
+}
+
+// UninstallBalanceFilter removes the balance filter of the specified id
+func (api *PublicSeeleAPI) UninstallBalanceFilter(id *uint64, result *bool) error {
+	*result = api.s.balanceWatcher.UninstallFilter(*id)
+	return nil
+}
+
+// GetDifficultyHistoryRequest request param for GetDifficultyHistory api
+type GetDifficultyHistoryRequest struct {
+	FromHeight uint64 // FromHeight is the first height to query
+	ToHeight   uint64 // ToHeight is the last height to query
+	Step       uint64 // Step is the number of blocks per point, rounded up to a multiple of hashrate.EpochSize
+}
+
+// GetDifficultyHistory returns the average difficulty and estimated network hashrate of the blocks
+// in the specified height range, in which each point aggregates the blocks of a step. Only the
+// completed epochs of hashrate.EpochSize blocks are served from the precomputed index.
+func (api *PublicSeeleAPI) GetDifficultyHistory(request *GetDifficultyHistoryRequest, result *[]*hashrate.Point) error {
+	points, err := api.s.hashrateHistory.Query(request.FromHeight, request.ToHeight, request.Step)
+	if err != nil {
+		return err
+	}
+
+	*result = points
+	return nil
+}
+
+// GetDifficulty returns the difficulty of the block of the specified height.
+// When height is -1 the chain head is used.
+func (api *PublicSeeleAPI) GetDifficulty(height *int64, result *big.Int) error {
+	block, err := getBlock(api.s.chain, *height)
+	if err != nil {
+		return err
+	}
+
+	result.Set(block.Header.Difficulty)
+	return nil
+}
+
+// EstimateNetworkHashrateRequest request param for EstimateNetworkHashrate api
+type EstimateNetworkHashrateRequest struct {
+	Height int64  // Height is the last height of the blocks, -1 for the chain head
+	Blocks uint64 // Blocks is the number of the recent blocks, hashrate.DefaultEstimateBlocks if 0
+}
+
+// EstimateNetworkHashrate returns the average difficulty, block time and estimated network hashrate
+// of the recent blocks, see hashrate.Estimate.
+func (api *PublicSeeleAPI) EstimateNetworkHashrate(request *EstimateNetworkHashrateRequest, result *hashrate.Point) error {
+	block, err := getBlock(api.s.chain, request.Height)
+	if err != nil {
+		return err
+	}
+
+	point, err := hashrate.Estimate(api.s.chain.GetStore(), block.Header.Height, request.Blocks)
+	if err != nil {
+		return err
+	}
+
+	*result = *point
+	return nil
+}
+
+// GetLogsRequest request param for GetLogs api
+type GetLogsRequest struct {
+	rpc.RequestContext
+
+	FromHeight int64            // FromHeight is the first height to query, -1 for the chain head
+	ToHeight   int64            // ToHeight is the last height to query, -1 for the chain head
+	Addresses  []common.Address // Addresses matches the logs of any of the contracts, empty to match all
+	Topics     []common.Hash    // Topics matches the logs that contain all the topics
+}
+
+// GetLogs returns the contract logs in the specified height range that match the addresses and topics.
+// The logs are queried from the log indices if enabled, otherwise the blocks in range are re-executed,
+// which is limited to logindex.MaxScanRange blocks.
+func (api *PublicSeeleAPI) GetLogs(request *GetLogsRequest, result *[]*types.Log) error {
+	head, _ := api.s.chain.CurrentBlock()
+	filter := &logindex.Filter{
+		FromHeight: uint64(request.FromHeight),
+		ToHeight:   uint64(request.ToHeight),
+		Addresses:  request.Addresses,
+		Topics:     request.Topics,
+	}
+
+	if request.FromHeight == -1 {
+		filter.FromHeight = head.Header.Height
+	}
+
+	if request.ToHeight == -1 || filter.ToHeight > head.Header.Height {
+		filter.ToHeight = head.Header.Height
+	}
+
+	var logs []*types.Log
+	var err error
+	if api.s.logIndexer != nil {
+		logs, err = api.s.logIndexer.GetLogs(request.Context(), filter)
+	} else {
+		logs, err = logindex.ScanLogs(request.Context(), api.s.firehose, filter)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	*result = logs
+	return nil
+}
+
 // PublicNetworkAPI provides an API to access network information.
 type PublicNetworkAPI struct {
 	p2pServer      *p2p.Server
@@ -177,13 +614,8 @@ func (api *PublicMinerAPI) Start(threads *int, result *string) error {
 	if threads == nil {
 		threads = new(int)
 	}
-	api.s.miner.SetThreads(*threads)
 
-	if api.s.miner.IsMining() {
-		return miner.ErrMinerIsRunning
-	}
-
-	return api.s.miner.Start()
+	return api.s.miner.Start(*threads)
 }
 
 // Stop API is used to stop the miner.
@@ -196,26 +628,205 @@ func (api *PublicMinerAPI) Stop(input *string, result *string) error {
 	return nil
 }
 
-// rpcOutputBlock converts the given block to the RPC output which depends on fullTx
-func rpcOutputBlock(b *types.Block, fullTx bool) (map[string]interface{}, error) {
+// GetStatus API returns the mining state of the miner, e.g. whether paused during the chain sync.
+func (api *PublicMinerAPI) GetStatus(input *string, result *miner.Status) error {
+	*result = *api.s.miner.Status()
+	return nil
+}
+
+// GetHashrate API returns the measured hashes per second of the miner.
+func (api *PublicMinerAPI) GetHashrate(input *string, result *float64) error {
+	*result = api.s.miner.Hashrate()
+	return nil
+}
+
+// HashrateInfo is the measured hashrate of the miner and each mining thread.
+type HashrateInfo struct {
+	Total   float64   `json:"total"`   // Total is the hashes per second of all mining threads
+	Threads []float64 `json:"threads"` // Threads are the hashes per second of each mining thread
+}
+
+// Hashrate API returns the measured hashes per second of the miner and each mining thread.
+func (api *PublicMinerAPI) Hashrate(input *string, result *HashrateInfo) error {
+	*result = HashrateInfo{
+		Total:   api.s.miner.Hashrate(),
+		Threads: api.s.miner.ThreadHashrates(),
+	}
+
+	return nil
+}
+
+// EstimateEarnings API estimates the mining earnings at the current network difficulty
+// with the given hashrate, or the measured hashrate of the miner if not specified.
+func (api *PublicMinerAPI) EstimateEarnings(hashrate *float64, result *miner.Earnings) error {
+	rate := api.s.miner.Hashrate()
+	if hashrate != nil && *hashrate > 0 {
+		rate = *hashrate
+	}
+
+	head, _ := api.s.chain.CurrentBlock()
+	earnings, err := miner.EstimateEarnings(api.s.chain.GetStore(), api.s.chain.RewardConfig(), head, rate)
+	if err != nil {
+		return err
+	}
+
+	*result = *earnings
+	return nil
+}
+
+// EarningsReportRequest is the request of the earnings report.
+type EarningsReportRequest struct {
+	From      uint64   // From is the first block height
+	To        uint64   // To is the last block height, the HEAD block if 0
+	Coinbases []string // Coinbases are the addresses in hex, the coinbase of the node if empty
+}
+
+// EarningsReport API returns the rewards and fees paid to the coinbase addresses within the
+// height range of the canonical chain, e.g. for the accounting of the mining operations.
+func (api *PublicMinerAPI) EarningsReport(request *EarningsReportRequest, result *miner.EarningsReport) error {
+	to := request.To
+	if to == 0 {
+		head, _ := api.s.chain.CurrentBlock()
+		to = head.Header.Height
+	}
+
+	coinbases := []common.Address{api.s.Coinbase}
+	if len(request.Coinbases) > 0 {
+		coinbases = make([]common.Address, len(request.Coinbases))
+		for i, hex := range request.Coinbases {
+			addr, err := common.HexToAddress(hex)
+			if err != nil {
+				return err
+			}
+
+			coinbases[i] = addr
+		}
+	}
+
+	report, err := miner.BuildEarningsReport(api.s.chain.GetStore(), api.s.chain.RewardConfig(), request.From, to, coinbases)
+	if err != nil {
+		return err
+	}
+
+	*result = *report
+	return nil
+}
+
+// BuildBlockTemplate API returns the block the miner would build upon the HEAD block right now,
+// including the ordered txs, expected reward and fees, gas used and resulting state root, without
+// mining it or changing the tx pool.
+func (api *PublicMinerAPI) BuildBlockTemplate(options *miner.TemplateOptions, result *miner.BlockTemplate) error {
+	template, err := api.s.miner.BuildBlockTemplate(options)
+	if err != nil {
+		return err
+	}
+
+	*result = *template
+	return nil
+}
+
+// GetBlockTemplate API returns the full block the miner would build upon the HEAD block right now
+// without sealing it, including the txs, reward, fees and state root, together with the encoded
+// block, e.g. to audit the fee selection or for the external block producers.
+func (api *PublicMinerAPI) GetBlockTemplate(options *miner.TemplateOptions, result *map[string]interface{}) error {
+	template, err := api.s.miner.BuildBlockTemplate(options)
+	if err != nil {
+		return err
+	}
+
+	fields, err := rpcOutputBlock(template.Block, true, nil)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := common.Serialize(template.Block)
+	if err != nil {
+		return err
+	}
+
+	fields["reward"] = template.Reward
+	fields["ommerBonus"] = template.OmmerBonus
+	fields["fees"] = template.Fees
+	fields["gasUsed"] = template.GasUsed
+	fields["size"] = template.Size
+	fields["raw"] = hexutil.BytesToHex(encoded)
+
+	*result = fields
+	return nil
+}
+
+// SetPoolAccounting API sets the pool accounting data to commit in the reward tx of the blocks
+// mined afterwards, which requires the escrow account as the coinbase.
+func (api *PublicMinerAPI) SetPoolAccounting(request *SetPoolAccountingRequest, result *bool) error {
+	if api.s.escrow == nil {
+		return errEscrowNotConfigured
+	}
+
+	accounting := &types.PoolAccounting{
+		Escrow:     *api.s.escrow,
+		Round:      request.Round,
+		Shares:     request.Shares,
+		SharesRoot: request.SharesRoot,
+	}
+
+	if err := api.s.miner.SetPoolAccounting(accounting); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// SetCoinbaseExtra API sets the extra data, e.g. the pool tag, to embed in the reward tx of the
+// blocks mined afterwards, which is cleared if empty.
+func (api *PublicMinerAPI) SetCoinbaseExtra(extra *string, result *bool) error {
+	if err := api.s.miner.SetCoinbaseExtra([]byte(*extra)); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// rpcOutputBlock converts the given block to the RPC output which depends on fullTx,
+// the full txs are output with their local labels if labels is not nil
+func rpcOutputBlock(b *types.Block, fullTx bool, labels *label.Store) (map[string]interface{}, error) {
 	head := b.Header
 	fields := map[string]interface{}{
-		"height":     head.Height,
-		"hash":       b.HeaderHash.ToHex(),
-		"parentHash": head.PreviousBlockHash.ToHex(),
-		"nonce":      head.Nonce,
-		"stateHash":  head.StateHash.ToHex(),
-		"txHash":     head.TxHash.ToHex(),
-		"creator":    head.Creator.ToHex(),
-		"timestamp":  head.CreateTimestamp,
-		"difficulty": head.Difficulty,
+		"height":      head.Height,
+		"hash":        b.HeaderHash.ToHex(),
+		"parentHash":  head.PreviousBlockHash.ToHex(),
+		"nonce":       head.Nonce,
+		"stateHash":   head.StateHash.ToHex(),
+		"txHash":      head.TxHash.ToHex(),
+		"receiptHash": head.ReceiptHash.ToHex(),
+		"logsBloom":   hexutil.BytesToHex(head.LogsBloom[:]),
+		"creator":     head.Creator.ToHex(),
+		"timestamp":   head.CreateTimestamp,
+		"difficulty":  head.Difficulty,
 	}
 
+	ommers := make([]interface{}, len(head.Ommers))
+	for i, ommer := range head.Ommers {
+		ommers[i] = map[string]interface{}{
+			"height":  ommer.Height,
+			"hash":    ommer.Hash().ToHex(),
+			"creator": ommer.Creator.ToHex(),
+		}
+	}
+	fields["ommers"] = ommers
+
 	txs := b.Transactions
 	transactions := make([]interface{}, len(txs))
 	for i, tx := range txs {
 		if fullTx {
-			transactions[i] = rpcOutputTx(tx)
+			transaction := rpcOutputTx(tx)
+			if labels != nil {
+				if err := rpcOutputTxLabels(transaction, tx, labels); err != nil {
+					return nil, err
+				}
+			}
+			transactions[i] = transaction
 		} else {
 			transactions[i] = tx.Hash.ToHex()
 		}
@@ -229,13 +840,26 @@ func rpcOutputBlock(b *types.Block, fullTx bool) (map[string]interface{}, error)
 func rpcOutputTx(tx *types.Transaction) map[string]interface{} {
 	transaction := map[string]interface{}{
 		"hash":         tx.Hash.ToHex(),
+		"type":         tx.Data.Type.String(),
 		"from":         tx.Data.From.ToHex(),
 		"to":           tx.Data.To.ToHex(),
 		"amount":       tx.Data.Amount,
+		"gasPrice":     tx.Data.GasPrice,
+		"gasLimit":     tx.Data.GasLimit,
 		"accountNonce": tx.Data.AccountNonce,
 		"payload":      tx.Data.Payload,
 		"timestamp":    tx.Data.Timestamp,
+		"expireAt":     tx.Data.ExpireAt,
 	}
+
+	if tx.Data.FeePayer != nil {
+		transaction["feePayer"] = tx.Data.FeePayer.ToHex()
+	}
+
+	if len(tx.Data.Extensions) > 0 {
+		transaction["extensions"] = tx.Data.Extensions
+	}
+
 	return transaction
 }
 
@@ -7,13 +7,23 @@ package seele
 import (
 	"bytes"
 	"context"
+	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/consensus/memhash"
 	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/crypto"
 	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/miner"
+	"github.com/seeleteam/go-seele/miner/pow"
 )
 
 func getTmpConfig() *Config {
@@ -49,3 +59,307 @@ func Test_PublicSeeleAPI(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_PublicSeeleAPI_ValidateAddress(t *testing.T) {
+	api := NewPublicSeeleAPI(nil)
+	addr := *crypto.MustGenerateRandomAddress()
+
+	var result AddressValidation
+	checksum := addr.ToChecksumHex()
+	if err := api.ValidateAddress(&checksum, &result); err != nil || !result.Valid || !result.Checksummed || result.Address != addr {
+		t.Fatalf("invalid validation of checksummed address, %+v, %v", result, err)
+	}
+
+	lower := addr.ToHex()
+	if err := api.ValidateAddress(&lower, &result); err != nil || !result.Valid || result.Checksummed || result.Checksum != checksum {
+		t.Fatalf("invalid validation of lower-case address, %+v, %v", result, err)
+	}
+
+	short := "0x1234"
+	if err := api.ValidateAddress(&short, &result); err != nil || result.Valid || result.Error == "" {
+		t.Fatalf("invalid validation of short address, %+v, %v", result, err)
+	}
+}
+
+func Test_PublicMinerAPI_GetWork(t *testing.T) {
+	conf := getTmpConfig()
+	conf.DifficultyConf = pow.DefaultDifficultyConfig()
+	conf.DifficultyConf.MinDifficulty = big.NewInt(1)
+	conf.CoinbaseExtra = []byte("pool/v1")
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewPublicMinerAPI(ss)
+
+	var work miner.Work
+	assert.Equal(t, api.GetWork(nil, &work), miner.ErrNoWork)
+
+	// mine by the external miners only
+	threads := -1
+	var started string
+	if err = api.Start(&threads, &started); err != nil {
+		t.Fatal(err)
+	}
+	defer ss.miner.Stop()
+
+	if err = api.GetWork(nil, &work); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, work.Height, uint64(1))
+
+	encoded, err := hexutil.HexToBytes(work.Header)
+	assert.Equal(t, err, error(nil))
+
+	var header types.BlockHeader
+	assert.Equal(t, common.Deserialize(encoded, &header), error(nil))
+	assert.Equal(t, header.Hash(), work.ID)
+
+	var accepted bool
+	assert.Equal(t, api.SubmitWork(&SubmitWorkRequest{ID: common.StringToHash("stale")}, &accepted), miner.ErrWorkStale)
+
+	for header.Nonce = 0; header.Hash().Big().Cmp(work.Target) > 0; header.Nonce++ {
+	}
+
+	if err = api.SubmitWork(&SubmitWorkRequest{ID: work.ID, Nonce: header.Nonce}, &accepted); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, accepted, true)
+
+	for i := 0; i < 100; i++ {
+		if block, _ := ss.chain.CurrentBlock(); block.Header.Height == 1 {
+			assert.Equal(t, block.HeaderHash, header.Hash())
+			assert.Equal(t, block.Transactions[0].CoinbaseExtra(), []byte("pool/v1"))
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("submitted work not saved")
+}
+
+func Test_PublicMinerAPI_GetWork_MemHash(t *testing.T) {
+	conf := getTmpConfig()
+	conf.MemoryDB = true
+	conf.PowAlgorithm = memhash.Algorithm
+	conf.MemHashConf = &memhash.Config{CacheSize: 1024, DatasetSize: 8192}
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewPublicMinerAPI(ss)
+	threads := -1
+	var started string
+	if err = api.Start(&threads, &started); err != nil {
+		t.Fatal(err)
+	}
+	defer ss.miner.Stop()
+
+	// the memhash blocks are not sealed with the nonce of the header hash
+	var work miner.Work
+	assert.Equal(t, api.GetWork(nil, &work), miner.ErrNoWork)
+
+	var accepted bool
+	assert.Equal(t, api.SubmitWork(&SubmitWorkRequest{ID: common.StringToHash("work")}, &accepted), miner.ErrNoWork)
+}
+
+func Test_PublicMinerAPI_BuildBlockTemplate(t *testing.T) {
+	conf := getTmpConfig()
+	from, privKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.GenesisAccounts = map[common.Address]*big.Int{*from: big.NewInt(1000000)}
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := types.NewTransaction(*from, *crypto.MustGenerateRandomAddress(), big.NewInt(10), big.NewInt(1), types.TransferGas, 0)
+	tx.SignWithScheme(privKey, types.SigHashScheme{Version: types.LatestSigHashVersion, ChainID: conf.NetworkID})
+	if err = ss.TxPool().AddTransaction(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	var template miner.BlockTemplate
+	if err = NewPublicMinerAPI(ss).BuildBlockTemplate(&miner.TemplateOptions{}, &template); err != nil {
+		t.Fatal(err)
+	}
+
+	if template.Height != 1 || len(template.Txs) != 1 || template.Txs[0] != tx.Hash {
+		t.Fatalf("unexpected template txs, %+v", template)
+	}
+
+	if template.Fees.Sign() <= 0 || template.GasUsed != types.TransferGas || template.StateRoot.IsEmpty() {
+		t.Fatalf("unexpected template fees or state, %+v", template)
+	}
+
+	// the full block of the same txs and state
+	var block map[string]interface{}
+	if err = NewPublicMinerAPI(ss).GetBlockTemplate(&miner.TemplateOptions{Timestamp: template.Timestamp}, &block); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(block["transactions"].([]interface{})), 2)
+	assert.Equal(t, block["stateHash"], template.StateRoot.ToHex())
+	assert.Equal(t, block["fees"], template.Fees)
+
+	raw, err := hexutil.HexToBytes(block["raw"].(string))
+	assert.Equal(t, err, error(nil))
+	var decoded types.Block
+	assert.Equal(t, common.Deserialize(raw, &decoded), error(nil))
+	assert.Equal(t, decoded.Header.StateHash, template.StateRoot)
+
+	// the txs are kept in the pool
+	if ss.TxPool().GetTransaction(tx.Hash) == nil {
+		t.Fatal("tx removed from the pool")
+	}
+}
+
+func Test_PublicSeeleAPI_GetTxStatus(t *testing.T) {
+	conf := getTmpConfig()
+	conf.MemoryDB = true
+	conf.DevSeal = true
+	from, privKey, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+	conf.GenesisAccounts = map[common.Address]*big.Int{*from: big.NewInt(1000000)}
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err, error(nil))
+
+	api := NewPublicSeeleAPI(ss)
+	tx := types.NewTransaction(*from, *crypto.MustGenerateRandomAddress(), big.NewInt(10), big.NewInt(1), types.TransferGas, 0)
+	tx.SignWithScheme(privKey, types.SigHashScheme{Version: types.LatestSigHashVersion, ChainID: conf.NetworkID})
+
+	var status TxStatus
+	assert.Equal(t, api.GetTxStatus(&tx.Hash, &status), error(nil))
+	assert.Equal(t, status.Status, TxStatusUnknown)
+
+	assert.Equal(t, ss.TxPool().AddTransaction(tx), error(nil))
+	assert.Equal(t, api.GetTxStatus(&tx.Hash, &status), error(nil))
+	assert.Equal(t, status.Status, TxStatusPending)
+
+	// seal the block of the tx
+	for i := 0; i < 2; i++ {
+		template, err := ss.miner.BuildBlockTemplate(&miner.TemplateOptions{})
+		assert.Equal(t, err, error(nil))
+		block, err := pow.Engine{}.Seal(template.Block, make(chan struct{}))
+		assert.Equal(t, err, error(nil))
+		assert.Equal(t, ss.chain.WriteBlock(block), error(nil))
+	}
+
+	assert.Equal(t, api.GetTxStatus(&tx.Hash, &status), error(nil))
+	assert.Equal(t, status.Status, TxStatusIncluded)
+	assert.Equal(t, status.BlockHeight, uint64(1))
+	assert.Equal(t, status.Confirmations, uint64(2))
+	assert.Equal(t, status.Receipt.TxHash, tx.Hash)
+}
+
+func Test_PublicTransactionPoolAPI_PendingTransactionFilter(t *testing.T) {
+	conf := getTmpConfig()
+	conf.MemoryDB = true
+	conf.DevSeal = true
+	from, privKey, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+	conf.GenesisAccounts = map[common.Address]*big.Int{*from: big.NewInt(1000000)}
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err, error(nil))
+
+	api := NewPublicTransactionPoolAPI(ss)
+	var id uint64
+	assert.Equal(t, api.NewPendingTransactionFilter(nil, &id), error(nil))
+
+	tx := types.NewTransaction(*from, *crypto.MustGenerateRandomAddress(), big.NewInt(10), big.NewInt(1), types.TransferGas, 0)
+	tx.SignWithScheme(privKey, types.SigHashScheme{Version: types.LatestSigHashVersion, ChainID: conf.NetworkID})
+	assert.Equal(t, ss.TxPool().AddTransaction(tx), error(nil))
+
+	var hashes []common.Hash
+	assert.Equal(t, api.GetPendingTransactionFilterChanges(&id, &hashes), error(nil))
+	assert.Equal(t, hashes, []common.Hash{tx.Hash})
+
+	// cleared since last poll
+	assert.Equal(t, api.GetPendingTransactionFilterChanges(&id, &hashes), error(nil))
+	assert.Equal(t, len(hashes), 0)
+
+	var uninstalled bool
+	assert.Equal(t, api.UninstallPendingTransactionFilter(&id, &uninstalled), error(nil))
+	assert.Equal(t, uninstalled, true)
+	assert.Equal(t, api.GetPendingTransactionFilterChanges(&id, &hashes), errPendingTxFilterNotFound)
+
+	// uninstalled if not polled in time
+	now := time.Now()
+	id = ss.txFilters.newFilter(now)
+	ss.txFilters.expire(now.Add(pendingTxFilterTimeout))
+	assert.Equal(t, api.GetPendingTransactionFilterChanges(&id, &hashes), error(nil))
+
+	ss.txFilters.expire(now.Add(2 * pendingTxFilterTimeout))
+	assert.Equal(t, api.GetPendingTransactionFilterChanges(&id, &hashes), errPendingTxFilterNotFound)
+}
+
+func Test_PrivateAdminAPI_TxPoolDump(t *testing.T) {
+	conf := getTmpConfig()
+	conf.MemoryDB = true
+	conf.DevSeal = true
+	from, privKey, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+	conf.GenesisAccounts = map[common.Address]*big.Int{*from: big.NewInt(1000000)}
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err, error(nil))
+
+	tx := types.NewTransaction(*from, *crypto.MustGenerateRandomAddress(), big.NewInt(10), big.NewInt(1), types.TransferGas, 0)
+	tx.SignWithScheme(privKey, types.SigHashScheme{Version: types.LatestSigHashVersion, ChainID: conf.NetworkID})
+	assert.Equal(t, ss.TxPool().AddTransaction(tx), error(nil))
+
+	api := NewPrivateAdminAPI(ss)
+
+	// only the plain file names in the tx pool dump folder are allowed
+	var exported int
+	var imported TxPoolImportResult
+	for _, name := range []string{"", ".", "..", "../pool.json", "txs/pool.json", filepath.Join(dataDir, "pool.json")} {
+		assert.Equal(t, api.ExportTxPool(&name, &exported), errInvalidDumpName)
+		assert.Equal(t, api.ImportTxPool(&name, &imported), errInvalidDumpName)
+	}
+
+	name := "pool.json"
+	assert.Equal(t, api.ExportTxPool(&name, &exported), error(nil))
+	assert.Equal(t, exported, 1)
+
+	_, err = os.Stat(filepath.Join(dataDir, TxPoolDumpDir, name))
+	assert.Equal(t, err, error(nil))
+
+	// the tx is already in the pool
+	assert.Equal(t, api.ImportTxPool(&name, &imported), error(nil))
+	assert.Equal(t, imported.Imported, 0)
+	assert.Equal(t, len(imported.Failures), 1)
+	_, found := imported.Failures[tx.Hash.ToHex()]
+	assert.Equal(t, found, true)
+}
@@ -0,0 +1,20 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package apikey
+
+// CreateKeyRequest request param for admin.CreateAPIKey api
+type CreateKeyRequest struct {
+	Name       string
+	Methods    []string
+	RateLimit  uint64
+	DailyQuota uint64
+}
+
+// CreatedKey is the result of admin.CreateAPIKey api, of which the secret could not be retrieved later.
+type CreatedKey struct {
+	Key    *Key
+	Secret string
+}
@@ -0,0 +1,432 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/log"
+)
+
+const (
+	// secretLength is the number of random bytes of an API key.
+	secretLength = 32
+
+	// rateWindow is the window of the rate limit of API keys.
+	rateWindow = time.Second
+
+	// flushInterval is the interval to persist the usage of API keys.
+	flushInterval = time.Minute
+
+	// secondsPerDay is the length of the daily quota window in UTC.
+	secondsPerDay = 24 * 60 * 60
+)
+
+var (
+	keyAPIKeys        = []byte("apiKeys")
+	keyNextAPIKeyID   = []byte("apiKeyNextID")
+	keyPrefixKeyUsage = []byte("apiKeyUsage")
+
+	// ErrKeyNotFound is returned when the API key id does not exist.
+	ErrKeyNotFound = errors.New("API key not found")
+
+	// ErrInvalidMethod is returned when a method in the allowlist is not in the form of namespace.method.
+	ErrInvalidMethod = errors.New("invalid method in allowlist")
+
+	// ErrKeyInvalid is returned when the API key of a request is unknown or revoked.
+	ErrKeyInvalid = errors.New("invalid API key")
+
+	// ErrMethodNotAllowed is returned when the method is not in the allowlist of the API key.
+	ErrMethodNotAllowed = errors.New("method not allowed for the API key")
+
+	// ErrRateLimited is returned when the API key sends too many requests per second.
+	ErrRateLimited = errors.New("API key rate limited")
+
+	// ErrQuotaExceeded is returned when the API key runs out of the daily quota.
+	ErrQuotaExceeded = errors.New("API key daily quota exceeded")
+)
+
+// Key is an API key issued to a partner, of which only the hash of the secret is persisted.
+type Key struct {
+	ID         uint64      // ID is assigned when the key is created
+	Name       string      // Name is the description of the key, e.g. the partner name
+	Hash       common.Hash // Hash is the hash of the key secret
+	Methods    []string    // Methods are the allowed methods, e.g. seele.GetBalance or seele.*, empty for all
+	RateLimit  uint64      // RateLimit is the maximum requests per second, 0 for no limit
+	DailyQuota uint64      // DailyQuota is the maximum requests per UTC day, 0 for no quota
+	Created    uint64      // Created is the unix time when the key is created
+	Revoked    bool        // Revoked indicates whether the key is revoked
+}
+
+// allow indicates whether the specified service method is in the allowlist.
+func (key *Key) allow(serviceMethod string) bool {
+	if len(key.Methods) == 0 {
+		return true
+	}
+
+	for _, method := range key.Methods {
+		if method == serviceMethod {
+			return true
+		}
+
+		if strings.HasSuffix(method, ".*") && strings.HasPrefix(serviceMethod, method[:len(method)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Usage is the request accounting of an API key.
+type Usage struct {
+	KeyID    uint64 // KeyID is the id of the API key
+	Total    uint64 // Total is the number of accepted requests
+	Rejected uint64 // Rejected is the number of requests rejected by the allowlist, rate limit or quota
+	Day      uint64 // Day is the UTC day of DayCount, i.e. days since the unix epoch
+	DayCount uint64 // DayCount is the number of accepted requests in the day
+}
+
+// keyState is the API key in use along with the accounting.
+type keyState struct {
+	key         *Key
+	usage       Usage
+	windowStart time.Time // start of the rate limit window
+	windowCount uint64    // number of requests in the rate limit window
+	dirty       bool      // dirty indicates the usage is not persisted
+}
+
+// Manager manages the API keys for the public RPC, and authorizes and accounts the
+// requests of the keys. The keys and their usage are persisted in database, and there
+// are following mappings:
+//  1. keyAPIKeys => keys, including the revoked ones
+//  2. keyNextAPIKeyID => next key id
+//  3. keyPrefixKeyUsage + key id => usage of the key
+type Manager struct {
+	db  database.Database
+	log *log.SeeleLog
+
+	lock   sync.Mutex // protects the fields below
+	keys   []*Key
+	states map[common.Hash]*keyState // key hash => state of the keys not revoked
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewManager creates an API key manager, and loads the keys from the database.
+func NewManager(db database.Database, log *log.SeeleLog) (*Manager, error) {
+	manager := &Manager{
+		db:     db,
+		log:    log,
+		states: make(map[common.Hash]*keyState),
+	}
+
+	if err := manager.getValue(keyAPIKeys, &manager.keys); err != nil {
+		return nil, err
+	}
+
+	for _, key := range manager.keys {
+		if key.Revoked {
+			continue
+		}
+
+		state := &keyState{key: key, usage: Usage{KeyID: key.ID}}
+		if err := manager.getValue(usageKey(key.ID), &state.usage); err != nil {
+			return nil, err
+		}
+
+		manager.states[key.Hash] = state
+	}
+
+	return manager, nil
+}
+
+// Start starts to persist the usage of API keys periodically.
+func (manager *Manager) Start() {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	if manager.quit != nil {
+		return
+	}
+
+	manager.quit = make(chan struct{})
+	manager.wg.Add(1)
+	go manager.loop(manager.quit)
+}
+
+// Stop stops the background persistence, and persists the usage not flushed yet.
+func (manager *Manager) Stop() {
+	manager.lock.Lock()
+	quit := manager.quit
+	manager.quit = nil
+	manager.lock.Unlock()
+
+	if quit != nil {
+		close(quit)
+		manager.wg.Wait()
+	}
+
+	manager.flush()
+}
+
+func (manager *Manager) loop(quit chan struct{}) {
+	defer manager.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			manager.flush()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// flush persists the usage changed since the last flush.
+func (manager *Manager) flush() {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	batch := manager.db.NewBatch()
+	var flushed []*keyState
+	for _, state := range manager.states {
+		if state.dirty {
+			batch.Put(usageKey(state.key.ID), common.SerializePanic(state.usage))
+			flushed = append(flushed, state)
+		}
+	}
+
+	if len(flushed) == 0 {
+		return
+	}
+
+	if err := batch.Commit(); err != nil {
+		manager.log.Warn("failed to persist the usage of API keys, %s", err)
+		return
+	}
+
+	for _, state := range flushed {
+		state.dirty = false
+	}
+}
+
+// Create creates an API key with the specified allowlist, rate limit and daily quota,
+// and returns the key along with the secret, which is not persisted and could not be
+// retrieved later.
+func (manager *Manager) Create(name string, methods []string, rateLimit, dailyQuota uint64) (*Key, string, error) {
+	for _, method := range methods {
+		if !strings.Contains(method, ".") {
+			return nil, "", ErrInvalidMethod
+		}
+	}
+
+	secretBytes := make([]byte, secretLength)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", err
+	}
+
+	secret := hex.EncodeToString(secretBytes)
+
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	var id uint64
+	if err := manager.getValue(keyNextAPIKeyID, &id); err != nil {
+		return nil, "", err
+	}
+
+	key := &Key{
+		ID:         id,
+		Name:       name,
+		Hash:       secretHash(secret),
+		Methods:    append([]string{}, methods...),
+		RateLimit:  rateLimit,
+		DailyQuota: dailyQuota,
+		Created:    uint64(time.Now().Unix()),
+	}
+
+	batch := manager.db.NewBatch()
+	batch.Put(keyNextAPIKeyID, common.SerializePanic(id+1))
+	batch.Put(keyAPIKeys, common.SerializePanic(append(manager.keys, key)))
+	if err := batch.Commit(); err != nil {
+		return nil, "", err
+	}
+
+	manager.keys = append(manager.keys, key)
+	manager.states[key.Hash] = &keyState{key: key, usage: Usage{KeyID: id}}
+
+	copied := *key
+	return &copied, secret, nil
+}
+
+// Revoke revokes the API key, while its usage is kept.
+func (manager *Manager) Revoke(id uint64) error {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	for i, key := range manager.keys {
+		if key.ID != id {
+			continue
+		}
+
+		if key.Revoked {
+			return nil
+		}
+
+		revoked := *key
+		revoked.Revoked = true
+
+		keys := append([]*Key{}, manager.keys...)
+		keys[i] = &revoked
+
+		batch := manager.db.NewBatch()
+		batch.Put(keyAPIKeys, common.SerializePanic(keys))
+		if state := manager.states[key.Hash]; state != nil && state.dirty {
+			batch.Put(usageKey(id), common.SerializePanic(state.usage))
+		}
+
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+
+		manager.keys = keys
+		delete(manager.states, key.Hash)
+		return nil
+	}
+
+	return ErrKeyNotFound
+}
+
+// Keys returns the copies of all API keys, including the revoked ones.
+func (manager *Manager) Keys() []*Key {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	keys := make([]*Key, len(manager.keys))
+	for i, key := range manager.keys {
+		copied := *key
+		keys[i] = &copied
+	}
+
+	return keys
+}
+
+// Usage returns the usage of the specified API key.
+func (manager *Manager) Usage(id uint64) (*Usage, error) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	for _, key := range manager.keys {
+		if key.ID != id {
+			continue
+		}
+
+		if state := manager.states[key.Hash]; state != nil {
+			usage := state.usage
+			if day := utcDay(time.Now()); usage.Day != day {
+				usage.Day, usage.DayCount = day, 0
+			}
+
+			return &usage, nil
+		}
+
+		usage := &Usage{KeyID: id}
+		err := manager.getValue(usageKey(id), usage)
+		return usage, err
+	}
+
+	return nil, ErrKeyNotFound
+}
+
+// Authorize implements the rpc.KeyAuthorizer interface.
+func (manager *Manager) Authorize(secret string, serviceMethod string) error {
+	return manager.authorize(secret, serviceMethod, time.Now())
+}
+
+// authorize authorizes the request of the specified service method at the specified time,
+// and accounts the request to the API key.
+func (manager *Manager) authorize(secret string, serviceMethod string, now time.Time) error {
+	hash := secretHash(secret)
+
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	state := manager.states[hash]
+	if state == nil {
+		return ErrKeyInvalid
+	}
+
+	state.dirty = true
+
+	if !state.key.allow(serviceMethod) {
+		state.usage.Rejected++
+		return ErrMethodNotAllowed
+	}
+
+	if now.Sub(state.windowStart) >= rateWindow {
+		state.windowStart, state.windowCount = now, 0
+	}
+
+	if state.key.RateLimit > 0 && state.windowCount >= state.key.RateLimit {
+		state.usage.Rejected++
+		return ErrRateLimited
+	}
+
+	if day := utcDay(now); state.usage.Day != day {
+		state.usage.Day, state.usage.DayCount = day, 0
+	}
+
+	if state.key.DailyQuota > 0 && state.usage.DayCount >= state.key.DailyQuota {
+		state.usage.Rejected++
+		return ErrQuotaExceeded
+	}
+
+	state.windowCount++
+	state.usage.DayCount++
+	state.usage.Total++
+
+	return nil
+}
+
+func (manager *Manager) getValue(key []byte, value interface{}) error {
+	exist, err := manager.db.Has(key)
+	if err != nil || !exist {
+		return err
+	}
+
+	encoded, err := manager.db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return common.Deserialize(encoded, value)
+}
+
+func secretHash(secret string) common.Hash {
+	return crypto.HashBytes([]byte(secret))
+}
+
+func utcDay(t time.Time) uint64 {
+	return uint64(t.Unix()) / secondsPerDay
+}
+
+func usageKey(id uint64) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, id)
+	return append(append([]byte{}, keyPrefixKeyUsage...), encoded...)
+}
@@ -0,0 +1,119 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package apikey
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/log"
+)
+
+func newTestManager(t *testing.T) (*Manager, database.Database, func()) {
+	dir, err := ioutil.TempDir("", "apikey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	manager, err := NewManager(db, log.GetLogger("apikey", common.PrintLog))
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return manager, db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func Test_Manager_Create(t *testing.T) {
+	manager, db, dispose := newTestManager(t)
+	defer dispose()
+
+	_, _, err := manager.Create("partner", []string{"GetBalance"}, 0, 0)
+	assert.Equal(t, err, ErrInvalidMethod)
+
+	key, secret, err := manager.Create("partner", []string{"seele.GetBalance"}, 10, 100)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, key.ID, uint64(0))
+	assert.Equal(t, key.Hash, secretHash(secret))
+
+	key, _, err = manager.Create("partner2", nil, 0, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, key.ID, uint64(1))
+
+	// reloaded from database
+	reloaded, err := NewManager(db, manager.log)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(reloaded.Keys()), 2)
+	assert.Equal(t, reloaded.authorize(secret, "seele.GetBalance", time.Now()), error(nil))
+}
+
+func Test_Manager_Authorize(t *testing.T) {
+	manager, _, dispose := newTestManager(t)
+	defer dispose()
+
+	key, secret, err := manager.Create("partner", []string{"seele.*", "debug.GetTxByHash"}, 2, 3)
+	assert.Equal(t, err, error(nil))
+
+	now := time.Unix(secondsPerDay*100, 0)
+	assert.Equal(t, manager.authorize("unknown", "seele.GetBalance", now), ErrKeyInvalid)
+	assert.Equal(t, manager.authorize(secret, "debug.DumpHeap", now), ErrMethodNotAllowed)
+	assert.Equal(t, manager.authorize(secret, "seele.GetBalance", now), error(nil))
+	assert.Equal(t, manager.authorize(secret, "debug.GetTxByHash", now), error(nil))
+	assert.Equal(t, manager.authorize(secret, "seele.GetBalance", now), ErrRateLimited)
+
+	// next rate window
+	now = now.Add(rateWindow)
+	assert.Equal(t, manager.authorize(secret, "seele.GetBlock", now), error(nil))
+	assert.Equal(t, manager.authorize(secret, "seele.GetBlock", now), ErrQuotaExceeded)
+
+	// next day
+	now = now.Add(secondsPerDay * time.Second)
+	assert.Equal(t, manager.authorize(secret, "seele.GetBlock", now), error(nil))
+
+	usage, err := manager.Usage(key.ID)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, usage.Total, uint64(4))
+	assert.Equal(t, usage.Rejected, uint64(3))
+}
+
+func Test_Manager_Revoke(t *testing.T) {
+	manager, db, dispose := newTestManager(t)
+	defer dispose()
+
+	key, secret, err := manager.Create("partner", nil, 0, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, manager.Authorize(secret, "seele.GetBalance"), error(nil))
+
+	assert.Equal(t, manager.Revoke(key.ID+1), ErrKeyNotFound)
+	assert.Equal(t, manager.Revoke(key.ID), error(nil))
+	assert.Equal(t, manager.Authorize(secret, "seele.GetBalance"), ErrKeyInvalid)
+	assert.Equal(t, manager.Keys()[0].Revoked, true)
+
+	// usage is kept after revoked
+	usage, err := manager.Usage(key.ID)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, usage.Total, uint64(1))
+
+	reloaded, err := NewManager(db, manager.log)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, reloaded.Authorize(secret, "seele.GetBalance"), ErrKeyInvalid)
+}
@@ -0,0 +1,318 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/log"
+)
+
+const (
+	// the same layout as the node data folder, so that a backup could be used as the DataDir directly
+	chainDBDir        = "db/blockchain"
+	accountStateDBDir = "db/accountState"
+
+	// ManifestFile is the name of the file that describes the backup.
+	ManifestFile = "manifest.json"
+
+	defaultInterval = 24 * time.Hour
+	backupPrefix    = "backup-"
+)
+
+var (
+	// ErrBackupExists is returned when the backup folder already exists.
+	ErrBackupExists = errors.New("backup folder already exists")
+
+	// ErrCheckpointNotSupported is returned when the database does not support checkpoints.
+	ErrCheckpointNotSupported = errors.New("database checkpoint not supported")
+
+	// ErrBackupMismatch is returned when the backup mismatches its manifest or the current chain.
+	ErrBackupMismatch = errors.New("backup mismatches the chain")
+)
+
+// Config is the configuration of the backup scheduler.
+type Config struct {
+	// Dir is the folder to write the scheduled backups, empty to disable the scheduler.
+	Dir string
+
+	// Interval is the interval to take a new backup, default is 24 hours.
+	Interval time.Duration
+
+	// Keep is the number of the latest scheduled backups to keep, 0 to keep all.
+	Keep int
+}
+
+// Manifest describes the HEAD block of a backup.
+type Manifest struct {
+	Height    uint64
+	HeadHash  common.Hash
+	StateHash common.Hash
+	TD        *big.Int
+	CreatedAt int64 // unix time in seconds
+}
+
+type blockchain interface {
+	GetStore() store.BlockchainStore
+}
+
+// Backuper takes consistent backups of the blockchain and account state databases
+// on a schedule or on demand, while the node is running.
+type Backuper struct {
+	chain          blockchain
+	chainDB        database.Database
+	accountStateDB database.Database
+	config         Config
+	log            *log.SeeleLog
+
+	lock sync.Mutex // serializes the backups
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewBackuper creates a backuper of the specified chain databases.
+func NewBackuper(chain blockchain, chainDB, accountStateDB database.Database, config Config, log *log.SeeleLog) *Backuper {
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+
+	return &Backuper{
+		chain:          chain,
+		chainDB:        chainDB,
+		accountStateDB: accountStateDB,
+		config:         config,
+		log:            log,
+		quit:           make(chan struct{}),
+	}
+}
+
+// Start starts the backup schedule if the backup folder is configured.
+func (b *Backuper) Start() {
+	if len(b.config.Dir) == 0 {
+		return
+	}
+
+	b.wg.Add(1)
+	go b.loop()
+}
+
+// Stop terminates the backup schedule.
+func (b *Backuper) Stop() {
+	close(b.quit)
+	b.wg.Wait()
+}
+
+func (b *Backuper) loop() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			path := filepath.Join(b.config.Dir, fmt.Sprintf("%s%d", backupPrefix, time.Now().Unix()))
+			if manifest, err := b.Backup(path); err != nil {
+				b.log.Warn("failed to backup the chain, %s", err)
+			} else {
+				b.log.Info("chain backup created in %s, height:%d, hash:%s", path, manifest.Height, manifest.HeadHash.ToHex())
+				b.prune()
+			}
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+// Backup takes a consistent backup of the chain into the specified folder, which
+// is then verified against the current chain.
+func (b *Backuper) Backup(path string) (*Manifest, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return nil, ErrBackupExists
+	}
+
+	manifest, err := b.checkpoint(path)
+	if err != nil {
+		os.RemoveAll(path)
+		return nil, err
+	}
+
+	if err = b.verify(path, manifest); err != nil {
+		os.RemoveAll(path)
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// checkpoint copies the databases into the folder and writes the manifest. The blockchain
+// database is copied before the account state database, since the state of a block is always
+// written before the block, so that the state of the HEAD block in the copy is always available.
+func (b *Backuper) checkpoint(path string) (*Manifest, error) {
+	chainDB, ok := b.chainDB.(database.Checkpointer)
+	if !ok {
+		return nil, ErrCheckpointNotSupported
+	}
+
+	accountStateDB, ok := b.accountStateDB.(database.Checkpointer)
+	if !ok {
+		return nil, ErrCheckpointNotSupported
+	}
+
+	if err := os.MkdirAll(filepath.Join(path, "db"), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	if err := chainDB.Checkpoint(filepath.Join(path, chainDBDir)); err != nil {
+		return nil, err
+	}
+
+	if err := accountStateDB.Checkpoint(filepath.Join(path, accountStateDBDir)); err != nil {
+		return nil, err
+	}
+
+	manifest, err := readManifest(path)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(path, ManifestFile), encoded, 0644); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// readManifest reads the HEAD block of the backup from the copied databases.
+func readManifest(path string) (*Manifest, error) {
+	db, err := leveldb.NewLevelDB(filepath.Join(path, chainDBDir))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	bcStore := store.NewBlockchainDatabase(db)
+	headHash, err := bcStore.GetHeadBlockHash()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := bcStore.GetBlockHeader(headHash)
+	if err != nil {
+		return nil, err
+	}
+
+	td, err := bcStore.GetBlockTotalDifficulty(headHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		Height:    header.Height,
+		HeadHash:  headHash,
+		StateHash: header.StateHash,
+		TD:        td,
+		CreatedAt: time.Now().Unix(),
+	}, nil
+}
+
+// verify verifies the backup against its manifest and the canonical chain.
+func (b *Backuper) verify(path string, manifest *Manifest) error {
+	if err := Verify(path, manifest); err != nil {
+		return err
+	}
+
+	canonicalHash, err := b.chain.GetStore().GetBlockHash(manifest.Height)
+	if err != nil {
+		return err
+	}
+
+	// the HEAD block may be reorganized right after the checkpoint, which is rare
+	if !canonicalHash.Equal(manifest.HeadHash) {
+		return fmt.Errorf("%s, the canonical block at height %d is %s", ErrBackupMismatch, manifest.Height, canonicalHash.ToHex())
+	}
+
+	return nil
+}
+
+// Verify verifies that the HEAD block of the backup in the folder matches the manifest, and
+// the account state of the HEAD block is available. The manifest is read from the backup if nil.
+func Verify(path string, manifest *Manifest) error {
+	if manifest == nil {
+		encoded, err := ioutil.ReadFile(filepath.Join(path, ManifestFile))
+		if err != nil {
+			return err
+		}
+
+		manifest = new(Manifest)
+		if err = json.Unmarshal(encoded, manifest); err != nil {
+			return err
+		}
+	}
+
+	actual, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+
+	if actual.Height != manifest.Height || !actual.HeadHash.Equal(manifest.HeadHash) || !actual.StateHash.Equal(manifest.StateHash) {
+		return ErrBackupMismatch
+	}
+
+	db, err := leveldb.NewLevelDB(filepath.Join(path, accountStateDBDir))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err = state.NewStatedb(manifest.StateHash, db); err != nil {
+		return fmt.Errorf("%s, state of the HEAD block unavailable: %s", ErrBackupMismatch, err)
+	}
+
+	return nil
+}
+
+// prune removes the oldest scheduled backups to keep the configured number of backups.
+func (b *Backuper) prune() {
+	if b.config.Keep <= 0 {
+		return
+	}
+
+	backups, err := filepath.Glob(filepath.Join(b.config.Dir, backupPrefix+"*"))
+	if err != nil || len(backups) <= b.config.Keep {
+		return
+	}
+
+	// names contain the unix time of the same length, so the lexical order is the time order
+	sort.Strings(backups)
+	for _, path := range backups[:len(backups)-b.config.Keep] {
+		if err = os.RemoveAll(path); err != nil {
+			b.log.Warn("failed to remove the backup %s, %s", path, err)
+		}
+	}
+}
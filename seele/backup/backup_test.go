@@ -0,0 +1,113 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package backup
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/log"
+)
+
+func newTestBackuper(t *testing.T, config Config) (*Backuper, *core.Blockchain, func()) {
+	dir, err := ioutil.TempDir("", "backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainDB, err := leveldb.NewLevelDB(filepath.Join(dir, chainDBDir))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	accountStateDB, err := leveldb.NewLevelDB(filepath.Join(dir, accountStateDBDir))
+	if err != nil {
+		chainDB.Close()
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	dispose := func() {
+		chainDB.Close()
+		accountStateDB.Close()
+		os.RemoveAll(dir)
+	}
+
+	accounts := map[common.Address]*big.Int{*crypto.MustGenerateRandomAddress(): big.NewInt(100)}
+	bcStore := store.NewBlockchainDatabase(chainDB)
+	if err = core.GetGenesis(accounts).InitializeAndValidate(bcStore, accountStateDB); err != nil {
+		dispose()
+		t.Fatal(err)
+	}
+
+	chain, err := core.NewBlockchain(bcStore, accountStateDB)
+	if err != nil {
+		dispose()
+		t.Fatal(err)
+	}
+
+	return NewBackuper(chain, chainDB, accountStateDB, config, log.GetLogger("backup", false)), chain, dispose
+}
+
+func Test_Backuper_Backup(t *testing.T) {
+	backuper, chain, dispose := newTestBackuper(t, Config{})
+	defer dispose()
+
+	dir, err := ioutil.TempDir("", "backuptarget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "latest")
+	manifest, err := backuper.Backup(path)
+	assert.Equal(t, err, error(nil))
+
+	head, _ := chain.CurrentBlock()
+	assert.Equal(t, manifest.Height, head.Header.Height)
+	assert.Equal(t, manifest.HeadHash, head.HeaderHash)
+	assert.Equal(t, manifest.StateHash, head.Header.StateHash)
+
+	// verify with the manifest file
+	assert.Equal(t, Verify(path, nil), error(nil))
+
+	// tampered manifest
+	manifest.HeadHash = common.StringToHash("tampered")
+	assert.Equal(t, Verify(path, manifest), ErrBackupMismatch)
+
+	_, err = backuper.Backup(path)
+	assert.Equal(t, err, ErrBackupExists)
+}
+
+func Test_Backuper_Prune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backuptarget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	backuper, _, dispose := newTestBackuper(t, Config{Dir: dir, Keep: 2})
+	defer dispose()
+
+	for _, name := range []string{"backup-1500000001", "backup-1500000002", "backup-1500000003"} {
+		assert.Equal(t, os.Mkdir(filepath.Join(dir, name), os.ModePerm), error(nil))
+	}
+
+	backuper.prune()
+
+	backups, _ := filepath.Glob(filepath.Join(dir, backupPrefix+"*"))
+	assert.Equal(t, backups, []string{filepath.Join(dir, "backup-1500000002"), filepath.Join(dir, "backup-1500000003")})
+}
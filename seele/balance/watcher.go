@@ -0,0 +1,197 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package balance
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/event"
+	"github.com/seeleteam/go-seele/log"
+)
+
+// maxPendingChanges is the maximum number of changes buffered for a filter,
+// the oldest changes are dropped if the filter is not polled in time.
+const maxPendingChanges = 1024
+
+// ErrFilterNotFound is returned when the filter id does not exist.
+var ErrFilterNotFound = errors.New("balance filter not found")
+
+type blockchain interface {
+	CurrentBlock() (*types.Block, *state.Statedb)
+}
+
+// Change represents a balance change of a tracked account when the HEAD block changes.
+type Change struct {
+	Address    common.Address // Address is the tracked account
+	Height     uint64         // Height is the height of the new HEAD block
+	BlockHash  common.Hash    // BlockHash is the hash of the new HEAD block
+	OldBalance *big.Int       // OldBalance is the balance at the previous HEAD block
+	NewBalance *big.Int       // NewBalance is the balance at the new HEAD block
+}
+
+// filter tracks the balance changes of a set of accounts.
+type filter struct {
+	addresses []common.Address
+	minDelta  *big.Int
+	changes   []*Change
+}
+
+// Watcher computes the balance changes of the tracked accounts from the state
+// diff between the previous and the new HEAD block, upon block import or reorg.
+type Watcher struct {
+	chain   blockchain
+	stateDB database.Database
+	log     *log.SeeleLog
+
+	lock      sync.Mutex // protects the fields below
+	headHash  common.Hash
+	stateHash common.Hash
+	filters   map[uint64]*filter
+	nextID    uint64
+}
+
+// NewWatcher creates a balance watcher of the specified chain and its account state DB.
+func NewWatcher(chain blockchain, stateDB database.Database, log *log.SeeleLog) *Watcher {
+	head, _ := chain.CurrentBlock()
+
+	return &Watcher{
+		chain:     chain,
+		stateDB:   stateDB,
+		log:       log,
+		headHash:  head.HeaderHash,
+		stateHash: head.Header.StateHash,
+		filters:   make(map[uint64]*filter),
+	}
+}
+
+// Start starts to watch the block insertion.
+func (w *Watcher) Start() {
+	event.BlockInsertedEventManager.AddAsyncListener(w.handleBlockInserted)
+}
+
+// Stop stops to watch the block insertion.
+func (w *Watcher) Stop() {
+	event.BlockInsertedEventManager.RemoveListener(w.handleBlockInserted)
+}
+
+// NewFilter creates a filter that tracks the balance changes of the specified accounts,
+// and only the changes whose absolute delta is not less than minDelta are recorded.
+// Returns the filter id to poll the changes.
+func (w *Watcher) NewFilter(addresses []common.Address, minDelta *big.Int) uint64 {
+	if minDelta == nil || minDelta.Sign() < 0 {
+		minDelta = big.NewInt(0)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.nextID++
+	w.filters[w.nextID] = &filter{
+		addresses: append([]common.Address{}, addresses...),
+		minDelta:  new(big.Int).Set(minDelta),
+	}
+
+	return w.nextID
+}
+
+// UninstallFilter removes the filter of the specified id.
+func (w *Watcher) UninstallFilter(id uint64) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if _, ok := w.filters[id]; !ok {
+		return false
+	}
+
+	delete(w.filters, id)
+	return true
+}
+
+// GetFilterChanges returns and clears the balance changes of the specified filter since last poll.
+func (w *Watcher) GetFilterChanges(id uint64) ([]*Change, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	f, ok := w.filters[id]
+	if !ok {
+		return nil, ErrFilterNotFound
+	}
+
+	changes := f.changes
+	f.changes = nil
+
+	return changes, nil
+}
+
+func (w *Watcher) handleBlockInserted(e event.Event) {
+	if err := w.update(); err != nil {
+		w.log.Warn("failed to update balance changes, %s", err)
+	}
+}
+
+// update records the balance changes between the last and the current HEAD block.
+// Note, the HEAD block may be switched to another branch if reorg happens.
+func (w *Watcher) update() error {
+	head, _ := w.chain.CurrentBlock()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if head.HeaderHash.Equal(w.headHash) {
+		return nil
+	}
+
+	if len(w.filters) > 0 {
+		oldState, err := state.NewStatedb(w.stateHash, w.stateDB)
+		if err != nil {
+			return err
+		}
+
+		newState, err := state.NewStatedb(head.Header.StateHash, w.stateDB)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range w.filters {
+			for _, addr := range f.addresses {
+				oldBalance := oldState.GetBalance(addr)
+				newBalance := newState.GetBalance(addr)
+
+				delta := new(big.Int).Sub(newBalance, oldBalance)
+				if delta.Sign() == 0 || delta.Abs(delta).Cmp(f.minDelta) < 0 {
+					continue
+				}
+
+				f.add(&Change{
+					Address:    addr,
+					Height:     head.Header.Height,
+					BlockHash:  head.HeaderHash,
+					OldBalance: new(big.Int).Set(oldBalance),
+					NewBalance: new(big.Int).Set(newBalance),
+				})
+			}
+		}
+	}
+
+	w.headHash = head.HeaderHash
+	w.stateHash = head.Header.StateHash
+
+	return nil
+}
+
+func (f *filter) add(change *Change) {
+	if len(f.changes) >= maxPendingChanges {
+		f.changes = f.changes[1:]
+	}
+
+	f.changes = append(f.changes, change)
+}
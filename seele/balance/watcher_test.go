@@ -0,0 +1,118 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package balance
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/log"
+)
+
+type mockChain struct {
+	head *types.Block
+}
+
+func (chain *mockChain) CurrentBlock() (*types.Block, *state.Statedb) {
+	return chain.head, nil
+}
+
+// setHead commits the specified balances on top of the HEAD state and updates the HEAD block.
+func (chain *mockChain) setHead(t *testing.T, db database.Database, balances map[common.Address]int64) {
+	statedb, err := state.NewStatedb(chain.head.Header.StateHash, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for addr, balance := range balances {
+		statedb.GetOrNewStateObject(addr)
+		statedb.SetBalance(addr, big.NewInt(balance))
+	}
+
+	batch := db.NewBatch()
+	root := statedb.Commit(batch)
+	if err = batch.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	header := &types.BlockHeader{
+		PreviousBlockHash: chain.head.HeaderHash,
+		Height:            chain.head.Header.Height + 1,
+		StateHash:         root,
+		Difficulty:        big.NewInt(1),
+	}
+
+	chain.head = &types.Block{HeaderHash: header.Hash(), Header: header}
+}
+
+func newTestWatcher(t *testing.T) (*Watcher, *mockChain, database.Database, func()) {
+	dir, err := ioutil.TempDir("", "balance")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	chain := &mockChain{
+		head: &types.Block{Header: &types.BlockHeader{Difficulty: big.NewInt(1)}},
+	}
+
+	watcher := NewWatcher(chain, db, log.GetLogger("balance", true))
+
+	return watcher, chain, db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func Test_Watcher_Changes(t *testing.T) {
+	watcher, chain, db, dispose := newTestWatcher(t)
+	defer dispose()
+
+	addr1 := *crypto.MustGenerateRandomAddress()
+	addr2 := *crypto.MustGenerateRandomAddress()
+
+	id := watcher.NewFilter([]common.Address{addr1, addr2}, big.NewInt(10))
+
+	chain.setHead(t, db, map[common.Address]int64{addr1: 100, addr2: 5})
+	assert.Equal(t, watcher.update(), error(nil))
+
+	changes, err := watcher.GetFilterChanges(id)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(changes), 1)
+	assert.Equal(t, changes[0].Address, addr1)
+	assert.Equal(t, changes[0].Height, uint64(1))
+	assert.Equal(t, changes[0].OldBalance, big.NewInt(0))
+	assert.Equal(t, changes[0].NewBalance, big.NewInt(100))
+
+	// changes are cleared after poll
+	changes, _ = watcher.GetFilterChanges(id)
+	assert.Equal(t, len(changes), 0)
+
+	// decrease is tracked as well
+	chain.setHead(t, db, map[common.Address]int64{addr1: 80})
+	assert.Equal(t, watcher.update(), error(nil))
+	changes, _ = watcher.GetFilterChanges(id)
+	assert.Equal(t, len(changes), 1)
+	assert.Equal(t, changes[0].NewBalance, big.NewInt(80))
+
+	assert.Equal(t, watcher.UninstallFilter(id), true)
+	_, err = watcher.GetFilterChanges(id)
+	assert.Equal(t, err, ErrFilterNotFound)
+}
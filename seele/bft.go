@@ -0,0 +1,131 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/bft"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/p2p"
+)
+
+const (
+	// bftProtoName is the sub-protocol to exchange the consensus messages of the bft validators,
+	// which are relayed by the validators, so that each validator need not connect to all the others.
+	bftProtoName = "bft"
+
+	bftVersion uint = 1
+
+	bftMsgCode        uint16 = 0
+	bftMsgCodeLength  uint16 = 1
+	bftSendQueueLimit        = 1024
+)
+
+// bftProtocol broadcasts the consensus messages of the bft engine to the peers, and relays the
+// new messages received.
+type bftProtocol struct {
+	p2p.Protocol
+
+	engine *bft.Engine
+	log    *log.SeeleLog
+
+	lock  sync.RWMutex
+	peers map[common.Address]chan []byte // node id => send queue of the connected peers
+}
+
+func newBftProtocol(engine *bft.Engine, log *log.SeeleLog) *bftProtocol {
+	p := &bftProtocol{
+		Protocol: p2p.Protocol{
+			Name:    bftProtoName,
+			Version: bftVersion,
+			Length:  bftMsgCodeLength,
+		},
+		engine: engine,
+		log:    log,
+		peers:  make(map[common.Address]chan []byte),
+	}
+
+	p.Protocol.AddPeer = p.handleAddPeer
+	return p
+}
+
+// Broadcast implements bft.Broadcaster, which queues the message to all the peers without blocking
+// the engine, and drops it for the peers of the full queue.
+func (p *bftProtocol) Broadcast(payload []byte) {
+	p.broadcast(payload, common.Address{})
+}
+
+// broadcast queues the message to the peers except the specified one.
+func (p *bftProtocol) broadcast(payload []byte, except common.Address) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	for id, queue := range p.peers {
+		if id == except {
+			continue
+		}
+
+		select {
+		case queue <- payload:
+		default:
+			p.log.Debug("bft send queue of %s is full, message dropped", id.ToHex())
+		}
+	}
+}
+
+func (p *bftProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) {
+	id := p2pPeer.Node.ID
+	queue := make(chan []byte, bftSendQueueLimit)
+	quit := make(chan struct{})
+
+	p.lock.Lock()
+	p.peers[id] = queue
+	p.lock.Unlock()
+
+	defer func() {
+		p.lock.Lock()
+		delete(p.peers, id)
+		p.lock.Unlock()
+		close(quit)
+	}()
+
+	go func() {
+		for {
+			select {
+			case payload := <-queue:
+				if err := p2p.SendMessage(rw, bftMsgCode, payload); err != nil {
+					p.log.Debug("failed to send bft message to %s, %s", id.ToHex(), err)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	// keep reading until the peer disconnects, so that the messages are not blocked
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return
+		}
+
+		if msg.Code != bftMsgCode {
+			continue
+		}
+
+		relay, err := p.engine.HandleMessage(msg.Payload)
+		if err != nil {
+			p.log.Debug("failed to handle bft message from %s, %s", id.ToHex(), err)
+			continue
+		}
+
+		if relay {
+			p.broadcast(msg.Payload, id)
+		}
+	}
+}
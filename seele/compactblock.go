@@ -0,0 +1,168 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+var errCompactBlockInvalid = errors.New("invalid compact block")
+
+// compactBlock is the network packet to relay a block with the tx hashes instead of the
+// txs, so that peers could reconstruct the block from their pools and only request the
+// missing txs.
+type compactBlock struct {
+	HeaderHash common.Hash
+	Header     *types.BlockHeader
+	Prefilled  []*types.Transaction // leading txs not in the pools of peers, i.e. the reward tx
+	TxHashes   []common.Hash        // hashes of the remaining txs in order
+}
+
+// newCompactBlock creates the compact block of the specified block, in which the reward tx is prefilled.
+func newCompactBlock(block *types.Block) *compactBlock {
+	compact := &compactBlock{
+		HeaderHash: block.HeaderHash,
+		Header:     block.Header,
+	}
+
+	txs := block.Transactions
+	if len(txs) > 0 {
+		compact.Prefilled, txs = txs[:1], txs[1:]
+	}
+
+	compact.TxHashes = make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		compact.TxHashes[i] = tx.Hash
+	}
+
+	return compact
+}
+
+// blockTxsRequest is the network packet to request the missing txs of a compact block.
+type blockTxsRequest struct {
+	BlockHash common.Hash
+	Indexes   []uint64 // indexes of the missing txs in the block
+}
+
+// blockTxs is the network packet of the txs requested by blockTxsRequest, in the order of the indexes.
+type blockTxs struct {
+	BlockHash common.Hash
+	Txs       []*types.Transaction
+}
+
+// partialBlock is a compact block being reconstructed, whose missing txs are requested.
+type partialBlock struct {
+	block   *types.Block
+	missing []uint64 // indexes of the missing txs
+	created time.Time
+}
+
+// compactBlockPool reconstructs the compact blocks from the tx pool, and caches
+// the partial blocks until the missing txs are received.
+type compactBlockPool struct {
+	capacity int
+	timeout  time.Duration
+	lock     sync.Mutex
+	partials map[common.Hash]*partialBlock
+}
+
+func newCompactBlockPool(capacity int, timeout time.Duration) *compactBlockPool {
+	return &compactBlockPool{
+		capacity: capacity,
+		timeout:  timeout,
+		partials: make(map[common.Hash]*partialBlock),
+	}
+}
+
+// reconstruct fills the txs of the compact block with the specified tx lookup, and returns the
+// block if all txs are found. Otherwise, the partial block is cached, and the indexes of the
+// missing txs are returned.
+func (pool *compactBlockPool) reconstruct(compact *compactBlock, lookup func(common.Hash) *types.Transaction,
+	now time.Time) (*types.Block, []uint64, error) {
+	if compact.Header == nil || compact.Header.Hash() != compact.HeaderHash {
+		return nil, nil, errCompactBlockInvalid
+	}
+
+	block := &types.Block{
+		HeaderHash:   compact.HeaderHash,
+		Header:       compact.Header,
+		Transactions: make([]*types.Transaction, 0, len(compact.Prefilled)+len(compact.TxHashes)),
+	}
+
+	block.Transactions = append(block.Transactions, compact.Prefilled...)
+
+	var missing []uint64
+	for _, hash := range compact.TxHashes {
+		tx := lookup(hash)
+		if tx == nil {
+			missing = append(missing, uint64(len(block.Transactions)))
+			tx = &types.Transaction{Hash: hash}
+		}
+
+		block.Transactions = append(block.Transactions, tx)
+	}
+
+	if len(missing) == 0 {
+		return block, nil, nil
+	}
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for hash, partial := range pool.partials {
+		if now.Sub(partial.created) > pool.timeout {
+			delete(pool.partials, hash)
+		}
+	}
+
+	if len(pool.partials) < pool.capacity {
+		pool.partials[block.HeaderHash] = &partialBlock{block, missing, now}
+	}
+
+	return nil, missing, nil
+}
+
+// fill fills the missing txs of the cached partial block, and returns the block if succeeded.
+// The partial block is removed in any case, and it returns false if the txs mismatch.
+func (pool *compactBlockPool) fill(response *blockTxs) (*types.Block, bool) {
+	pool.lock.Lock()
+	partial := pool.partials[response.BlockHash]
+	delete(pool.partials, response.BlockHash)
+	pool.lock.Unlock()
+
+	if partial == nil {
+		return nil, true
+	}
+
+	if len(response.Txs) != len(partial.missing) {
+		return nil, false
+	}
+
+	for i, index := range partial.missing {
+		tx := response.Txs[i]
+		if tx == nil || tx.Hash != partial.block.Transactions[index].Hash {
+			return nil, false
+		}
+
+		partial.block.Transactions[index] = tx
+	}
+
+	return partial.block, true
+}
+
+// has indicates whether the partial block of the specified hash is cached.
+func (pool *compactBlockPool) has(hash common.Hash) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	_, ok := pool.partials[hash]
+	return ok
+}
@@ -0,0 +1,117 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func newTestCompactBlock(t *testing.T, txCount int) (*types.Block, map[common.Hash]*types.Transaction) {
+	reward := types.NewRewardTransaction(*crypto.MustGenerateRandomAddress(), big.NewInt(100), nil)
+	reward.Signature = &crypto.Signature{}
+	txs := []*types.Transaction{reward}
+
+	pool := make(map[common.Hash]*types.Transaction)
+	for i := 0; i < txCount; i++ {
+		tx := types.NewTransaction(*crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress(),
+			big.NewInt(1), big.NewInt(1), types.TransferGas, uint64(i))
+		txs = append(txs, tx)
+		pool[tx.Hash] = tx
+	}
+
+	header := &types.BlockHeader{Height: 1, Difficulty: big.NewInt(1), CreateTimestamp: big.NewInt(1)}
+	return types.NewBlock(header, txs), pool
+}
+
+func Test_CompactBlock_Reconstruct(t *testing.T) {
+	block, txs := newTestCompactBlock(t, 3)
+	compact := newCompactBlock(block)
+	assert.Equal(t, len(compact.Prefilled), 1)
+	assert.Equal(t, len(compact.TxHashes), 3)
+
+	// decoded from the network packet
+	var decoded compactBlock
+	assert.Equal(t, common.Deserialize(common.SerializePanic(compact), &decoded), error(nil))
+
+	pool := newCompactBlockPool(2, time.Minute)
+	lookup := func(hash common.Hash) *types.Transaction { return txs[hash] }
+
+	reconstructed, missing, err := pool.reconstruct(&decoded, lookup, time.Now())
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(missing), 0)
+	assert.Equal(t, reconstructed.HeaderHash, block.HeaderHash)
+	assert.Equal(t, types.MerkleRootHash(reconstructed.Transactions), block.Header.TxHash)
+	assert.Equal(t, pool.has(block.HeaderHash), false)
+
+	// header changed
+	decoded.Header.Height = 2
+	_, _, err = pool.reconstruct(&decoded, lookup, time.Now())
+	assert.Equal(t, err, errCompactBlockInvalid)
+}
+
+func Test_CompactBlock_MissingTxs(t *testing.T) {
+	block, txs := newTestCompactBlock(t, 3)
+	compact := newCompactBlock(block)
+
+	delete(txs, block.Transactions[1].Hash)
+	delete(txs, block.Transactions[3].Hash)
+
+	pool := newCompactBlockPool(2, time.Minute)
+	lookup := func(hash common.Hash) *types.Transaction { return txs[hash] }
+
+	reconstructed, missing, err := pool.reconstruct(compact, lookup, time.Now())
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, reconstructed == nil, true)
+	assert.Equal(t, missing, []uint64{1, 3})
+	assert.Equal(t, pool.has(block.HeaderHash), true)
+
+	// mismatched txs
+	filled, ok := pool.fill(&blockTxs{block.HeaderHash, []*types.Transaction{block.Transactions[1], block.Transactions[2]}})
+	assert.Equal(t, filled == nil, true)
+	assert.Equal(t, ok, false)
+	assert.Equal(t, pool.has(block.HeaderHash), false)
+
+	pool.reconstruct(compact, lookup, time.Now())
+	filled, ok = pool.fill(&blockTxs{block.HeaderHash, []*types.Transaction{block.Transactions[1], block.Transactions[3]}})
+	assert.Equal(t, ok, true)
+	assert.Equal(t, filled.HeaderHash, block.HeaderHash)
+	assert.Equal(t, types.MerkleRootHash(filled.Transactions), block.Header.TxHash)
+
+	// unknown block
+	filled, ok = pool.fill(&blockTxs{block.HeaderHash, nil})
+	assert.Equal(t, filled == nil, true)
+	assert.Equal(t, ok, true)
+}
+
+func Test_CompactBlockPool_Expire(t *testing.T) {
+	block1, _ := newTestCompactBlock(t, 1)
+	block2, _ := newTestCompactBlock(t, 1)
+	block3, _ := newTestCompactBlock(t, 1)
+
+	pool := newCompactBlockPool(2, time.Minute)
+	lookup := func(hash common.Hash) *types.Transaction { return nil }
+	now := time.Now()
+
+	pool.reconstruct(newCompactBlock(block1), lookup, now)
+	pool.reconstruct(newCompactBlock(block2), lookup, now.Add(30*time.Second))
+
+	// full
+	pool.reconstruct(newCompactBlock(block3), lookup, now.Add(30*time.Second))
+	assert.Equal(t, pool.has(block3.HeaderHash), false)
+
+	// block1 expired
+	pool.reconstruct(newCompactBlock(block3), lookup, now.Add(61*time.Second))
+	assert.Equal(t, pool.has(block1.HeaderHash), false)
+	assert.Equal(t, pool.has(block2.HeaderHash), true)
+	assert.Equal(t, pool.has(block3.HeaderHash), true)
+}
@@ -6,10 +6,19 @@
 package seele
 
 import (
+	"crypto/ecdsa"
 	"math/big"
+	"time"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/bft"
+	"github.com/seeleteam/go-seele/consensus/memhash"
 	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/miner"
+	"github.com/seeleteam/go-seele/miner/pow"
+	"github.com/seeleteam/go-seele/seele/backup"
+	"github.com/seeleteam/go-seele/seele/snapshot"
 )
 
 // Config is the seele's configuration to create seele service
@@ -20,6 +29,114 @@ type Config struct {
 
 	Coinbase common.Address
 
+	// Escrow is the escrow account to pay the mining rewards to instead of the Coinbase, nil to disable
+	Escrow *types.EscrowAccount
+
+	// CoinbaseExtra is the extra data embedded in the reward tx of the mined blocks, e.g. the pool tag
+	CoinbaseExtra []byte
+
+	// MinerAffinity pins each mining thread to a cpu core, which is only supported on Linux
+	MinerAffinity bool
+
+	// MinerRecommit is the interval to rebuild the mining task with the latest txs, 0 to rebuild only
+	// for the higher-fee txs and the new head
+	MinerRecommit time.Duration
+
+	// MinerSyncPauseBlocks is the number of blocks behind the sync target to pause the mining until
+	// the sync completes, miner.DefaultSyncPauseBlocks if 0
+	MinerSyncPauseBlocks uint64
+
+	// MinerEnginePlugin is the path of the Go plugin that exports an Engine to seal the blocks
+	// instead of the mining threads, e.g. with the GPU or remote workers, empty to disable
+	MinerEnginePlugin string
+
+	// MemoryDB keeps the chain, state and indices in memory, e.g. for the ephemeral CI and fuzzing
+	// runs, which are lost once the node stops
+	MemoryDB bool
+
+	// DevSeal seals the blocks at once when the txs arrive, in which the difficulty stays around 1 unless
+	// DifficultyConf is specified, and no empty block is mined. It is ignored if MinerEnginePlugin is specified.
+	DevSeal bool
+
 	// genesis accounts balance info for test
 	GenesisAccounts map[common.Address]*big.Int
+
+	// GenesisDifficulty is the difficulty of the genesis block, 1 if nil
+	GenesisDifficulty *big.Int
+
+	// DebugStateDiff logs the account diff on block state root mismatch, which is expensive
+	DebugStateDiff bool
+
+	// MaxReorgDepth is the maximum depth of the automatic chain reorganization, 0 for unlimited
+	MaxReorgDepth uint64
+
+	// FinalityDepth is the number of confirmations after which the canonical blocks are final, 0 to disable
+	FinalityDepth uint64
+
+	// CheckpointAuthority is the address to sign the checkpoints that finalize the blocks, nil to disable
+	CheckpointAuthority *common.Address
+
+	// MinSyncSubnets is the number of distinct peer subnets that should claim a head before syncing to it, 0 to disable
+	MinSyncSubnets int
+
+	// ChainConf is the consensus config of the chain specified in genesis, the default config is used if nil
+	ChainConf *types.ChainConfig
+
+	// DifficultyConf is the config of the block period and difficulty adjustment, the default config is used if nil
+	DifficultyConf *pow.DifficultyConfig
+
+	// RewardConf is the reward schedule of the blocks, the default schedule is used if nil
+	RewardConf *pow.RewardConfig
+
+	// PowAlgorithm is the POW algorithm of the chain specified in genesis, pow.Algorithm if empty,
+	// memhash.Algorithm or bft.Algorithm, the latter two of which are not supported by DevSeal and MinerEnginePlugin
+	PowAlgorithm string
+
+	// MemHashConf is the config of the memhash algorithm specified in genesis, the default config is used if nil
+	MemHashConf *memhash.Config
+
+	// BftConf is the config of the bft consensus specified in genesis, required for bft.Algorithm
+	BftConf *bft.Config
+
+	// BftKey is the private key of the local bft validator, nil if not a validator
+	BftKey *ecdsa.PrivateKey
+
+	// SigHashForks are the heights to activate the tx sighash versions, all versions are activated since genesis if empty
+	SigHashForks []types.SigHashFork
+
+	// LogIndex builds the contract log indices in background for fast log queries over large height ranges
+	LogIndex bool
+
+	// ExternalSigner is the address of the external signer to sign the scheduled txs, e.g. the
+	// signer process holding the private keys, empty to sign with the unlocked accounts
+	ExternalSigner string
+
+	// ExternalSignerSecret is the secret shared with the external signer, which is generated
+	// for each launch of the signer process and passed to it on the stdin pipe
+	ExternalSignerSecret string
+
+	// SpendingLimits are the maximum values of the txs signed by the node for the addresses within
+	// the SpendingWindow, the addresses not listed are not limited
+	SpendingLimits map[common.Address]*big.Int
+
+	// SpendingWindow is the time window of the SpendingLimits, scheduler.DefaultSpendingWindow if 0
+	SpendingWindow time.Duration
+
+	// ExecutionPlugins are the paths of Go plugins that export an Observer to observe the tx execution
+	ExecutionPlugins []string
+
+	// ClientVersion is the client version advertised to the peers, see ClientVersion
+	ClientVersion string
+
+	// NetworkReportURL is the crawler endpoint to post the network summary of the peers hourly, empty to disable
+	NetworkReportURL string
+
+	// SnapshotConf is the configuration to publish chain snapshots
+	SnapshotConf snapshot.Config
+
+	// BackupConf is the configuration to backup the chain databases on schedule
+	BackupConf backup.Config
+
+	// StratumConf is the configuration of the stratum server for the mining pools, disabled if Addr is empty
+	StratumConf miner.StratumConfig
 }
@@ -9,6 +9,8 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/seeleteam/go-seele/common"
 	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/common/resource"
+	"github.com/seeleteam/go-seele/p2p"
 )
 
 // PublicDebugAPI provides an API to access full node-related information for debug.
@@ -72,3 +74,80 @@ func (api *PublicDebugAPI) GetTxPoolTxCount(input interface{}, result *uint64) e
 	*result = uint64(txPool.GetProcessableTransactionsCount())
 	return nil
 }
+
+// SubsystemStats returns the goroutines, estimated bytes and queue depths of the subsystems,
+// with their soft limits and whether they are throttled.
+func (api *PublicDebugAPI) SubsystemStats(input interface{}, result *[]*resource.Stats) error {
+	*result = resource.AllStats()
+	return nil
+}
+
+// P2pTrace returns the traced messages of the connected peer of the specified node id,
+// in which the tracing should be enabled via admin.SetPeerTrace.
+func (api *PublicDebugAPI) P2pTrace(peer *string, result *[]*p2p.MsgTrace) error {
+	id, err := common.HexToAddress(*peer)
+	if err != nil {
+		return err
+	}
+
+	traces, err := api.s.p2pServer.PeerTraces(id)
+	if err != nil {
+		return err
+	}
+
+	*result = traces
+	return nil
+}
+
+// GetHeadConsensus returns the agreement of the peers on the chain head, which is diverged if the
+// best head is claimed by the peers of fewer distinct subnets than required to sync to it.
+func (api *PublicDebugAPI) GetHeadConsensus(input interface{}, result *HeadConsensus) error {
+	*result = *api.s.seeleProtocol.peerSet.headConsensus(api.s.seeleProtocol.minSyncSubnets)
+	return nil
+}
+
+// GetBlockPropagationStats returns the latency percentiles in seconds of the recent blocks from
+// being first seen to being fully received, and the counters of the compact blocks.
+func (api *PublicDebugAPI) GetBlockPropagationStats(input interface{}, result *map[string]interface{}) error {
+	stats := api.s.seeleProtocol.propagation.stats()
+
+	*result = map[string]interface{}{
+		"samples":       stats.Samples,
+		"p50":           stats.P50.Seconds(),
+		"p90":           stats.P90.Seconds(),
+		"p99":           stats.P99.Seconds(),
+		"max":           stats.Max.Seconds(),
+		"compact":       stats.Compact,
+		"reconstructed": stats.Reconstructed,
+		"missingTxs":    stats.MissingTxs,
+	}
+
+	return nil
+}
+
+// PrivateDebugAPI provides an API of the debug namespace only for the operator of the node,
+// which is served on the admin rpc listener.
+type PrivateDebugAPI struct {
+	s *SeeleService
+}
+
+// NewPrivateDebugAPI creates a new PrivateDebugAPI object for rpc service.
+func NewPrivateDebugAPI(s *SeeleService) *PrivateDebugAPI {
+	return &PrivateDebugAPI{s}
+}
+
+// SetHead forcibly switches the HEAD block to the block of the specified hash, e.g. to accept
+// a fork refused for the deep chain reorganization.
+func (api *PrivateDebugAPI) SetHead(hash *string, result *bool) error {
+	headHash, err := common.HexToHash(*hash)
+	if err != nil {
+		return err
+	}
+
+	if err = api.s.chain.SetHead(headHash); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
@@ -26,8 +26,19 @@ const (
 
 	txsyncPackSize = 100 * 1024
 
+	orphanBlockCapacity = 256 // maximum number of cached blocks whose parent is unknown
+
+	compactBlockCapacity = 64               // maximum number of cached compact blocks whose txs are missing
+	compactBlockTimeout  = 30 * time.Second // duration to wait for the missing txs of compact blocks
+
 	// AccountStateDir account state info directory based on config.DataRoot
 	AccountStateDir = "/db/accountState"
+
+	// TxJournalFile journal file of the local txs based on config.DataRoot
+	TxJournalFile = "/transactions.journal"
+
+	// TxPoolDumpDir directory of the tx pool dump files based on config.DataRoot
+	TxPoolDumpDir = "/txpool"
 )
 
 // statusData the structure for peers to exchange status
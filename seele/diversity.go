@@ -0,0 +1,95 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"math/big"
+	"net"
+	"sort"
+)
+
+const (
+	// subnetBitsIPv4 is the prefix length of the IPv4 subnets, peers in the same /16 are likely run by the same operator.
+	subnetBitsIPv4 = 16
+
+	// subnetBitsIPv6 is the prefix length of the IPv6 subnets.
+	subnetBitsIPv6 = 32
+)
+
+// HeadConsensus is the agreement of the peers on the chain head, in which a peer supports
+// a head if it claims the same or a higher total difficulty. Since an attacker generally
+// controls the peers of a few subnets, the head claimed by the peers of fewer subnets than
+// required is not synchronised to, so that the node could not be eclipsed onto a fake chain.
+type HeadConsensus struct {
+	MinSubnets  int      `json:"minSubnets"`  // MinSubnets is the number of distinct subnets required to sync to a head
+	Peers       int      `json:"peers"`       // Peers is the number of connected peers
+	Subnets     int      `json:"subnets"`     // Subnets is the number of distinct subnets of the connected peers
+	BestTD      *big.Int `json:"bestTD"`      // BestTD is the highest total difficulty claimed by any peer
+	BestSubnets int      `json:"bestSubnets"` // BestSubnets is the number of distinct subnets claiming BestTD
+	SyncTD      *big.Int `json:"syncTD"`      // SyncTD is the highest total difficulty supported by MinSubnets subnets, nil if none
+	Diverged    bool     `json:"diverged"`    // Diverged is true if BestTD is supported by fewer than MinSubnets subnets
+
+	syncPeer *peer // syncPeer is the peer claiming SyncTD
+}
+
+// subnetOf returns the subnet of the specified IP address, or empty if nil.
+func subnetOf(ip net.IP) string {
+	if ip == nil {
+		return ""
+	}
+
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(subnetBitsIPv4, 32)).String()
+	}
+
+	return ip.Mask(net.CIDRMask(subnetBitsIPv6, 128)).String()
+}
+
+// headConsensus returns the agreement of the peers on the chain head, which requires the
+// specified number of distinct subnets to sync to a head. Any claimed head is supported if
+// minSubnets is not more than 1.
+func (p *peerSet) headConsensus(minSubnets int) *HeadConsensus {
+	p.lock.RLock()
+	peers := make([]*peer, 0, len(p.peers))
+	for _, pe := range p.peers {
+		peers = append(peers, pe)
+	}
+	p.lock.RUnlock()
+
+	tds := make(map[*peer]*big.Int, len(peers))
+	for _, pe := range peers {
+		_, tds[pe] = pe.Head()
+	}
+
+	// the peers supporting the head of a peer are the ones before it in the descending order
+	sort.SliceStable(peers, func(i, j int) bool {
+		return tds[peers[i]].Cmp(tds[peers[j]]) > 0
+	})
+
+	consensus := &HeadConsensus{MinSubnets: minSubnets, Peers: len(peers)}
+	subnets := make(map[string]bool)
+	for i, pe := range peers {
+		subnets[subnetOf(pe.RemoteIP())] = true
+
+		// peers of the same total difficulty support each other
+		if i+1 < len(peers) && tds[peers[i+1]].Cmp(tds[pe]) == 0 {
+			continue
+		}
+
+		if consensus.BestTD == nil {
+			consensus.BestTD, consensus.BestSubnets = tds[pe], len(subnets)
+		}
+
+		if consensus.syncPeer == nil && len(subnets) >= minSubnets {
+			consensus.SyncTD, consensus.syncPeer = tds[pe], pe
+		}
+	}
+
+	consensus.Subnets = len(subnets)
+	consensus.Diverged = consensus.BestTD != nil && consensus.BestSubnets < minSubnets
+
+	return consensus
+}
@@ -0,0 +1,73 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/p2p"
+	"github.com/seeleteam/go-seele/p2p/discovery"
+)
+
+func newTestSubnetPeer(ip string, td int64) *peer {
+	addr := crypto.MustGenerateRandomAddress()
+	node := discovery.NewNode(*addr, net.ParseIP(ip), 8057)
+	peer := newPeer(1, p2p.NewPeer(nil, nil, nil, node), nil)
+	peer.SetHead(common.StringToHash(ip), big.NewInt(td))
+
+	return peer
+}
+
+func Test_SubnetOf(t *testing.T) {
+	assert.Equal(t, subnetOf(net.ParseIP("10.1.2.3")), "10.1.0.0")
+	assert.Equal(t, subnetOf(net.ParseIP("10.1.200.3")), subnetOf(net.ParseIP("10.1.2.3")))
+	assert.Equal(t, subnetOf(net.ParseIP("10.2.2.3")) == subnetOf(net.ParseIP("10.1.2.3")), false)
+	assert.Equal(t, subnetOf(net.ParseIP("2001:db8:1::1")), subnetOf(net.ParseIP("2001:db8:2::1")))
+	assert.Equal(t, subnetOf(nil), "")
+}
+
+func Test_PeerSet_HeadConsensus(t *testing.T) {
+	set := newPeerSet()
+	assert.Equal(t, set.headConsensus(2).syncPeer == nil, true)
+	assert.Equal(t, set.headConsensus(2).Diverged, false)
+
+	// the attacker claims the best head from a single subnet
+	set.Add(newTestSubnetPeer("6.6.1.1", 100))
+	set.Add(newTestSubnetPeer("6.6.2.2", 100))
+	honest1 := newTestSubnetPeer("1.1.1.1", 50)
+	set.Add(honest1)
+
+	consensus := set.headConsensus(2)
+	assert.Equal(t, consensus.Peers, 3)
+	assert.Equal(t, consensus.Subnets, 2)
+	assert.Equal(t, consensus.BestTD, big.NewInt(100))
+	assert.Equal(t, consensus.BestSubnets, 1)
+	assert.Equal(t, consensus.SyncTD, big.NewInt(50))
+	assert.Equal(t, consensus.syncPeer, honest1)
+	assert.Equal(t, consensus.Diverged, true)
+
+	// not enough subnets to support any head
+	consensus = set.headConsensus(3)
+	assert.Equal(t, consensus.syncPeer == nil, true)
+	assert.Equal(t, consensus.SyncTD == nil, true)
+
+	// disabled
+	consensus = set.headConsensus(0)
+	assert.Equal(t, consensus.SyncTD, big.NewInt(100))
+	assert.Equal(t, consensus.Diverged, false)
+
+	// the best head is supported by another subnet of the same total difficulty
+	set.Add(newTestSubnetPeer("2.2.2.2", 100))
+	consensus = set.headConsensus(2)
+	assert.Equal(t, consensus.BestSubnets, 2)
+	assert.Equal(t, consensus.SyncTD, big.NewInt(100))
+	assert.Equal(t, consensus.Diverged, false)
+}
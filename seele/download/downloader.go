@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/resource"
 	"github.com/seeleteam/go-seele/core"
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/event"
@@ -49,6 +50,7 @@ var (
 	errIsSynchronising     = errors.New("Is synchronising")
 	errMaxForkAncestor     = errors.New("Can not find ancestor when reached MaxForkAncestry")
 	errPeerNotFound        = errors.New("Peer not found")
+	errSyncThrottled       = errors.New("Sync throttled for the soft resource limits")
 	errSyncErr             = errors.New("Err occurs when syncing")
 )
 
@@ -111,6 +113,11 @@ func (d *Downloader) getSyncInfo(info *SyncInfo) {
 
 // Synchronise try to sync with remote peer.
 func (d *Downloader) Synchronise(id string, head common.Hash, td *big.Int, localTD *big.Int) error {
+	// skip the sync round until the goroutines of the last round quit
+	if resource.Sync.Throttle() {
+		return errSyncThrottled
+	}
+
 	// Make sure only one routine can pass at once
 	d.lock.Lock()
 	if d.syncStatus != statusNone {
@@ -153,6 +160,9 @@ func (d *Downloader) doSynchronise(conn *peerConn, head common.Hash, td *big.Int
 		return err
 	}
 	height := latest.Height
+	if local, _ := d.chain.CurrentBlock(); height > local.Header.Height {
+		event.BlockDownloaderEventManager.Fire(&event.DownloaderBehindEvent{Blocks: height - local.Header.Height})
+	}
 
 	ancestor, err := d.findCommonAncestorHeight(conn, height)
 	if err != nil {
@@ -170,7 +180,8 @@ func (d *Downloader) doSynchronise(conn *peerConn, head common.Hash, td *big.Int
 		}
 		d.sessionWG.Add(1)
 
-		go d.peerDownload(c, tm)
+		c := c
+		resource.Sync.Go(func() { d.peerDownload(c, tm) })
 	}
 	d.lock.Unlock()
 	d.sessionWG.Wait()
@@ -288,7 +299,8 @@ func (d *Downloader) RegisterPeer(peerID string, peer Peer) {
 
 	if d.syncStatus == statusFetching {
 		d.sessionWG.Add(1)
-		go d.peerDownload(newConn, d.tm)
+		tm := d.tm
+		resource.Sync.Go(func() { d.peerDownload(newConn, tm) })
 	}
 }
 
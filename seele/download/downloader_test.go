@@ -56,7 +56,7 @@ func newTestTx(t *testing.T, amount int64, nonce uint64) *types.Transaction {
 	fromPrivKey, fromAddress := randomAccount(t)
 	_, toAddress := randomAccount(t)
 
-	tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), nonce)
+	tx := types.NewTransaction(fromAddress, toAddress, big.NewInt(amount), big.NewInt(1), types.TransferGas, nonce)
 	tx.Sign(fromPrivKey)
 
 	return tx
@@ -76,7 +76,7 @@ func newTestBlock(t *testing.T, parentHash common.Hash, height uint64, db databa
 
 	for _, tx := range txs {
 		stateObj := statedb.GetOrNewStateObject(tx.Data.From)
-		stateObj.SetAmount(big.NewInt(10))
+		stateObj.SetAmount(big.NewInt(10 + types.TransferGas))
 		stateObj.SetNonce(nonce)
 	}
 
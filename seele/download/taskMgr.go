@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/seeleteam/go-seele/consensus"
 	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/log"
 )
@@ -240,6 +241,10 @@ func (t *taskMgr) deliverHeaderMsg(peerID string, headers []*types.BlockHeader)
 		if lastNo != headers[0].Height {
 			return errMasterHeadersNotMatch
 		}
+
+		if err := t.verifyMasterHeaders(headers); err != nil {
+			return err
+		}
 		for _, h := range headers {
 			t.masterHeaderList = append(t.masterHeaderList, &masterHeadInfo{
 				header: h,
@@ -263,6 +268,27 @@ func (t *taskMgr) deliverHeaderMsg(peerID string, headers []*types.BlockHeader)
 	return nil
 }
 
+// verifyMasterHeaders verifies the headers of the master peer in batch against the last master
+// header, or the local ancestor for the first batch, before the blocks are downloaded.
+func (t *taskMgr) verifyMasterHeaders(headers []*types.BlockHeader) error {
+	var parent *types.BlockHeader
+	if n := len(t.masterHeaderList); n > 0 {
+		parent = t.masterHeaderList[n-1].header
+	} else {
+		bcStore := t.downloader.chain.GetStore()
+		hash, err := bcStore.GetBlockHash(t.fromNo - 1)
+		if err != nil {
+			return err
+		}
+
+		if parent, err = bcStore.GetBlockHeader(hash); err != nil {
+			return err
+		}
+	}
+
+	return consensus.VerifyHeaders(t.downloader.chain.Engine(), append([]*types.BlockHeader{parent}, headers...))
+}
+
 // deliverBlockPreMsg recved blocks-pre msg from peer.
 func (t *taskMgr) deliverBlockPreMsg(peerID string, blockNums []uint64) {
 	t.lock.Lock()
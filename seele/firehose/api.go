@@ -0,0 +1,40 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package firehose
+
+import (
+	"github.com/seeleteam/go-seele/rpc"
+)
+
+// PublicFirehoseAPI provides an API to stream the block execution results for indexers.
+type PublicFirehoseAPI struct {
+	f *Firehose
+}
+
+// NewPublicFirehoseAPI creates a new PublicFirehoseAPI object for rpc service.
+func NewPublicFirehoseAPI(f *Firehose) *PublicFirehoseAPI {
+	return &PublicFirehoseAPI{f}
+}
+
+// GetRecordsRequest request param for GetRecords api
+type GetRecordsRequest struct {
+	rpc.RequestContext
+
+	Cursor Cursor // Cursor of the last consumed block, only Height is required for the first request
+	Max    int    // Max is the maximum number of records to return, at most MaxRecords
+}
+
+// GetRecords returns the records after the specified cursor. Consumers resume
+// the stream with the Cursor of the last returned record.
+func (api *PublicFirehoseAPI) GetRecords(request *GetRecordsRequest, result *[]*Record) error {
+	records, err := api.f.Records(request.Context(), request.Cursor, request.Max)
+	if err != nil {
+		return err
+	}
+
+	*result = records
+	return nil
+}
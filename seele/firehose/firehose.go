@@ -0,0 +1,270 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package firehose
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database"
+)
+
+// MaxRecords is the maximum number of records returned in a single query.
+const MaxRecords = 64
+
+// ErrCursorNotFound is returned when the block of the cursor does not exist.
+var ErrCursorNotFound = errors.New("cursor block not found")
+
+type blockchain interface {
+	GetStore() store.BlockchainStore
+	ExecuteBlock(block *types.Block, statedb *state.Statedb, observer core.ExecutionObserver) ([]*types.Receipt, error)
+}
+
+// Cursor identifies the last consumed block of the stream, so that the consumer
+// could resume from it and detect the reorg if the block is no longer canonical.
+type Cursor struct {
+	Height uint64      // Height is the height of the last consumed block
+	Hash   common.Hash // Hash is the hash of the last consumed block
+}
+
+// AccountDiff is the state change of an account in a block.
+type AccountDiff struct {
+	Address    common.Address
+	OldBalance *big.Int
+	NewBalance *big.Int
+	OldNonce   uint64
+	NewNonce   uint64
+}
+
+// Record is the complete execution result of a block in the stream.
+type Record struct {
+	Undo       bool             // Undo indicates the block is reverted from the canonical chain due to reorg
+	Block      *types.Block     // Block is the block with all txs
+	Receipts   []*types.Receipt // Receipts are the receipts of txs except the miner reward tx
+	StateDiffs []*AccountDiff   // StateDiffs are the changes of accounts touched in the block
+	Cursor     Cursor           // Cursor is the position to resume after this record
+}
+
+// Firehose streams the canonical blocks in order along with their execution results.
+// The records are re-computed on demand from the stored blocks and account states.
+type Firehose struct {
+	chain   blockchain
+	stateDB database.Database
+}
+
+// New creates a firehose of the specified chain and its account state DB.
+func New(chain blockchain, stateDB database.Database) *Firehose {
+	return &Firehose{chain, stateDB}
+}
+
+// Records returns at most max records after the specified cursor. If the block
+// of cursor has been reverted due to reorg, the undo records of the reverted blocks
+// are returned first in height DESC order, followed by the new canonical blocks.
+// The query is aborted with the context error once the context is done.
+func (f *Firehose) Records(ctx context.Context, cursor Cursor, max int) ([]*Record, error) {
+	if max <= 0 || max > MaxRecords {
+		max = MaxRecords
+	}
+
+	bcStore := f.chain.GetStore()
+	if cursor.Hash.IsEmpty() {
+		hash, err := bcStore.GetBlockHash(cursor.Height)
+		if err != nil {
+			return nil, ErrCursorNotFound
+		}
+
+		cursor.Hash = hash
+	}
+
+	var records []*Record
+
+	// revert the blocks that are not canonical anymore
+	for len(records) < max {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		canonical, err := f.isCanonical(cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if canonical {
+			break
+		}
+
+		block, err := bcStore.GetBlock(cursor.Hash)
+		if err != nil {
+			return nil, ErrCursorNotFound
+		}
+
+		cursor = Cursor{block.Header.Height - 1, block.Header.PreviousBlockHash}
+		record, err := f.newRecord(block, cursor, true)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	headHash, err := bcStore.GetHeadBlockHash()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := bcStore.GetBlockHeader(headHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply the new canonical blocks
+	for height := cursor.Height + 1; height <= head.Height && len(records) < max; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		block, err := bcStore.GetBlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := f.newRecord(block, Cursor{height, block.HeaderHash}, false)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// BlockHeader returns the header of the canonical block at the specified height.
+func (f *Firehose) BlockHeader(height uint64) (*types.BlockHeader, error) {
+	bcStore := f.chain.GetStore()
+
+	hash, err := bcStore.GetBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return bcStore.GetBlockHeader(hash)
+}
+
+// isCanonical indicates whether the block of the specified cursor is in the canonical chain.
+func (f *Firehose) isCanonical(cursor Cursor) (bool, error) {
+	bcStore := f.chain.GetStore()
+
+	exist, err := bcStore.HasBlock(cursor.Hash)
+	if err != nil {
+		return false, err
+	}
+
+	if !exist {
+		return false, ErrCursorNotFound
+	}
+
+	hash, err := bcStore.GetBlockHash(cursor.Height)
+	if err != nil {
+		// the height is larger than the canonical HEAD
+		return false, nil
+	}
+
+	return hash.Equal(cursor.Hash), nil
+}
+
+// touchObserver collects the accounts touched in the execution of a block in order.
+type touchObserver struct {
+	core.BaseExecutionObserver
+	touched    []common.Address
+	touchedSet map[common.Address]bool
+}
+
+func (o *touchObserver) touch(addr common.Address) {
+	if !o.touchedSet[addr] {
+		o.touchedSet[addr] = true
+		o.touched = append(o.touched, addr)
+	}
+}
+
+// OnTxStart implements core.ExecutionObserver.
+func (o *touchObserver) OnTxStart(tx *types.Transaction, blockHeader *types.BlockHeader) {
+	o.touch(tx.Data.From)
+	if tx.Data.FeePayer != nil {
+		o.touch(*tx.Data.FeePayer)
+	}
+	if tx.Data.To != nil {
+		o.touch(*tx.Data.To)
+	}
+}
+
+// OnTransfer implements core.ExecutionObserver, which touches the receivers of the miner reward,
+// the rewards of the consensus engine, e.g. the ommer creators, and the internal transfers.
+func (o *touchObserver) OnTransfer(from, to common.Address, amount *big.Int) {
+	if from != (common.Address{}) {
+		o.touch(from)
+	}
+	o.touch(to)
+}
+
+// OnTxEnd implements core.ExecutionObserver.
+func (o *touchObserver) OnTxEnd(tx *types.Transaction, receipt *types.Receipt, err error) {
+	if receipt != nil && tx.Data.To == nil {
+		o.touch(receipt.ContractAddress)
+	}
+}
+
+// newRecord re-executes the specified block on its parent state in the same way as the
+// blockchain to get the tx receipts and the state diffs of the touched accounts.
+func (f *Firehose) newRecord(block *types.Block, cursor Cursor, undo bool) (*Record, error) {
+	record := &Record{
+		Undo:   undo,
+		Block:  block,
+		Cursor: cursor,
+	}
+
+	if block.Header.Height == 0 || len(block.Transactions) == 0 {
+		return record, nil
+	}
+
+	parent, err := f.chain.GetStore().GetBlockHeader(block.Header.PreviousBlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	parentState, err := state.NewStatedb(parent.StateHash, f.stateDB)
+	if err != nil {
+		return nil, err
+	}
+
+	statedb, err := state.NewStatedb(parent.StateHash, f.stateDB)
+	if err != nil {
+		return nil, err
+	}
+
+	observer := &touchObserver{touchedSet: make(map[common.Address]bool)}
+	if record.Receipts, err = f.chain.ExecuteBlock(block, statedb, observer); err != nil {
+		return nil, err
+	}
+
+	for _, addr := range observer.touched {
+		record.StateDiffs = append(record.StateDiffs, &AccountDiff{
+			Address:    addr,
+			OldBalance: new(big.Int).Set(parentState.GetBalance(addr)),
+			NewBalance: new(big.Int).Set(statedb.GetBalance(addr)),
+			OldNonce:   parentState.GetNonce(addr),
+			NewNonce:   statedb.GetNonce(addr),
+		})
+	}
+
+	return record, nil
+}
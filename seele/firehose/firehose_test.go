@@ -0,0 +1,151 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package firehose
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/miner/pow"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+// newTestBlock creates a block on the specified parent with a tx that transfers
+// the specified amount from the sender to a random account.
+func newTestBlock(t *testing.T, chain *core.Blockchain, db database.Database, parent *types.Block, from common.Address, privKey *ecdsa.PrivateKey, amount, nonce uint64) *types.Block {
+	height := parent.Header.Height + 1
+	coinbase, minerKey, _ := crypto.GenerateKeyPair()
+	rewardTx := types.NewRewardTransaction(*coinbase, big.NewInt(pow.GetReward(height)), nil)
+	rewardTx.Sign(minerKey)
+
+	tx := types.NewTransaction(from, *crypto.MustGenerateRandomAddress(), new(big.Int).SetUint64(amount), big.NewInt(1), types.TransferGas, nonce)
+	tx.Sign(privKey)
+
+	txs := []*types.Transaction{rewardTx, tx}
+	header := &types.BlockHeader{
+		PreviousBlockHash: parent.HeaderHash,
+		Creator:           *coinbase,
+		TxHash:            types.MerkleRootHash(txs),
+		Height:            height,
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   new(big.Int).SetUint64(height),
+		Nonce:             10,
+	}
+
+	statedb, err := state.NewStatedb(parent.Header.StateHash, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statedb.GetOrNewStateObject(*coinbase).AddAmount(rewardTx.Data.Amount)
+	receipt, err := chain.ApplyTransaction(tx, *coinbase, statedb, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header.StateHash = statedb.Commit(nil)
+	header.ReceiptHash = types.ReceiptMerkleRootHash([]*types.Receipt{receipt})
+	header.LogsBloom = types.CreateBloom([]*types.Receipt{receipt})
+
+	return &types.Block{
+		HeaderHash:   header.Hash(),
+		Header:       header,
+		Transactions: txs,
+	}
+}
+
+func Test_Firehose_Records(t *testing.T) {
+	from, privKey, _ := crypto.GenerateKeyPair()
+	chain, db, dispose := testutil.NewBlockchain(t, map[common.Address]*big.Int{*from: big.NewInt(100000)})
+	defer dispose()
+
+	genesis, _ := chain.CurrentBlock()
+	block1 := newTestBlock(t, chain, db, genesis, *from, privKey, 10, 0)
+	assert.Equal(t, chain.WriteBlock(block1), error(nil))
+
+	f := New(chain, db)
+	ctx := context.Background()
+
+	// start from genesis
+	records, err := f.Records(ctx, Cursor{Height: 0}, 10)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(records), 1)
+	assert.Equal(t, records[0].Undo, false)
+	assert.Equal(t, records[0].Block.HeaderHash, block1.HeaderHash)
+	assert.Equal(t, records[0].Cursor, Cursor{1, block1.HeaderHash})
+	assert.Equal(t, len(records[0].Receipts), 1)
+	assert.Equal(t, len(records[0].StateDiffs), 3)
+	assert.Equal(t, records[0].StateDiffs[1].Address, *from)
+	assert.Equal(t, records[0].StateDiffs[1].OldBalance, big.NewInt(100000))
+	assert.Equal(t, records[0].StateDiffs[1].NewBalance, big.NewInt(100000-10-types.TransferGas))
+
+	// resume from the HEAD
+	records, err = f.Records(ctx, records[0].Cursor, 10)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(records), 0)
+
+	// reorg to a longer fork from genesis
+	fork1 := newTestBlock(t, chain, db, genesis, *from, privKey, 20, 0)
+	assert.Equal(t, chain.WriteBlock(fork1), error(nil))
+	fork2 := newTestBlock(t, chain, db, fork1, *from, privKey, 20, 1)
+	assert.Equal(t, chain.WriteBlock(fork2), error(nil))
+
+	records, err = f.Records(ctx, Cursor{1, block1.HeaderHash}, 10)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(records), 3)
+	assert.Equal(t, records[0].Undo, true)
+	assert.Equal(t, records[0].Block.HeaderHash, block1.HeaderHash)
+	assert.Equal(t, records[0].Cursor, Cursor{0, genesis.HeaderHash})
+	assert.Equal(t, records[1].Block.HeaderHash, fork1.HeaderHash)
+	assert.Equal(t, records[2].Block.HeaderHash, fork2.HeaderHash)
+
+	// unknown cursor
+	_, err = f.Records(ctx, Cursor{1, common.StringToHash("unknown")}, 10)
+	assert.Equal(t, err, ErrCursorNotFound)
+
+	// cancelled query
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = f.Records(cancelledCtx, Cursor{Height: 0}, 10)
+	assert.Equal(t, err, context.Canceled)
+}
+
+func Test_Firehose_OmmerRewards(t *testing.T) {
+	from, privKey, _ := crypto.GenerateKeyPair()
+	chain, db, dispose := testutil.NewBlockchain(t, map[common.Address]*big.Int{*from: big.NewInt(100000)})
+	defer dispose()
+
+	genesis, _ := chain.CurrentBlock()
+	block1 := newTestBlock(t, chain, db, genesis, *from, privKey, 10, 0)
+	assert.Equal(t, chain.WriteBlock(block1), error(nil))
+	stale := newTestBlock(t, chain, db, genesis, *from, privKey, 20, 0)
+
+	// the rewards of the ommers are applied by the engine in the block execution
+	block2 := newTestBlock(t, chain, db, block1, *from, privKey, 10, 1)
+	block2.Header.Ommers = []*types.BlockHeader{stale.Header}
+
+	record, err := New(chain, db).newRecord(block2, Cursor{2, block2.HeaderHash}, false)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(record.Receipts), 1)
+
+	diffs := make(map[common.Address]*AccountDiff)
+	for _, diff := range record.StateDiffs {
+		diffs[diff.Address] = diff
+	}
+
+	assert.Equal(t, diffs[stale.Header.Creator].NewBalance, big.NewInt(pow.GetOmmerReward(2, 1)))
+	assert.Equal(t, diffs[block2.Header.Creator].NewBalance,
+		new(big.Int).Add(big.NewInt(pow.GetReward(2)+pow.GetOmmerInclusionReward(2)), record.Receipts[0].Fee))
+}
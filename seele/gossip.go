@@ -0,0 +1,144 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+const (
+	txGossipInterval   = 200 * time.Millisecond // interval to flush the batched tx announcements to peers
+	txGossipBudgetRate = 64 * 1024              // outgoing tx gossip bytes per second of each peer
+	txGossipBudgetMax  = 128 * 1024             // maximum accumulated tx gossip budget in bytes of each peer
+	maxTxGossipBatch   = 256                    // maximum tx hashes announced or requested in a single message
+	maxTxGossipQueue   = 4096                   // maximum pending txs to announce of each peer
+	newTxChanSize      = 4096                   // size of the channel of the new txs accepted by the pool to relay
+)
+
+// txGossipQueue batches the tx announcements to a peer under an outgoing
+// bandwidth budget. When the budget is tight, txs with higher priority are
+// announced first and the rest are kept for the next round.
+type txGossipQueue struct {
+	lock       sync.Mutex
+	pending    []*types.Transaction
+	budget     int // available budget in bytes
+	lastRefill time.Time
+}
+
+func newTxGossipQueue() *txGossipQueue {
+	return &txGossipQueue{
+		budget:     txGossipBudgetMax,
+		lastRefill: time.Now(),
+	}
+}
+
+// push queues the tx to announce. The tx with lowest priority is dropped if the queue is full.
+func (q *txGossipQueue) push(tx *types.Transaction) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.pending = append(q.pending, tx)
+	if len(q.pending) > maxTxGossipQueue {
+		sortTxsByGossipPriority(q.pending)
+		q.pending = q.pending[:maxTxGossipQueue]
+	}
+}
+
+// charge consumes the budget of the specified bytes sent to the peer, e.g. the requested tx bodies.
+func (q *txGossipQueue) charge(size int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.budget -= size
+}
+
+// refill accumulates the budget since the last refill till the specified time.
+// It should be called with the lock held.
+func (q *txGossipQueue) refill(now time.Time) {
+	q.budget += int(now.Sub(q.lastRefill).Seconds() * txGossipBudgetRate)
+	if q.budget > txGossipBudgetMax {
+		q.budget = txGossipBudgetMax
+	}
+	q.lastRefill = now
+}
+
+// serve returns the txs of the hashes requested by the peer to send within the budget at the
+// specified time. At most maxTxGossipBatch hashes are served, and the rest txs are not sent once
+// the budget is spent, so that the peer could not drain the bandwidth with the large requests.
+func (q *txGossipQueue) serve(hashes []common.Hash, getTx func(common.Hash) *types.Transaction, now time.Time) []*types.Transaction {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.refill(now)
+
+	if len(hashes) > maxTxGossipBatch {
+		hashes = hashes[:maxTxGossipBatch]
+	}
+
+	var txs []*types.Transaction
+	for _, hash := range hashes {
+		if q.budget <= 0 {
+			break
+		}
+
+		if tx := getTx(hash); tx != nil {
+			txs = append(txs, tx)
+			q.budget -= tx.Size()
+		}
+	}
+
+	return txs
+}
+
+// pop returns the hashes of txs to announce within the budget at the specified time.
+func (q *txGossipQueue) pop(now time.Time) []common.Hash {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.refill(now)
+
+	count := q.budget / common.HashLength
+	if count > maxTxGossipBatch {
+		count = maxTxGossipBatch
+	}
+
+	if count <= 0 || len(q.pending) == 0 {
+		return nil
+	}
+
+	if count < len(q.pending) {
+		sortTxsByGossipPriority(q.pending)
+	} else {
+		count = len(q.pending)
+	}
+
+	hashes := make([]common.Hash, count)
+	for i, tx := range q.pending[:count] {
+		hashes[i] = tx.Hash
+	}
+
+	q.pending = q.pending[count:]
+	q.budget -= count * common.HashLength
+
+	return hashes
+}
+
+// sortTxsByGossipPriority sorts the txs in gossip priority DESC order, i.e. the
+// txs with higher gas price are prior, and then the earlier created txs.
+func sortTxsByGossipPriority(txs []*types.Transaction) {
+	sort.SliceStable(txs, func(i, j int) bool {
+		if cmp := txs[i].Data.GasPrice.Cmp(txs[j].Data.GasPrice); cmp != 0 {
+			return cmp > 0
+		}
+
+		return txs[i].Data.Timestamp < txs[j].Data.Timestamp
+	})
+}
@@ -0,0 +1,88 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func newTestGossipTx(timestamp uint64) *types.Transaction {
+	tx := types.NewTransaction(*crypto.MustGenerateRandomAddress(), *crypto.MustGenerateRandomAddress(), big.NewInt(1), big.NewInt(1), types.TransferGas, 0)
+	tx.Data.Timestamp = timestamp
+	tx.Hash = crypto.MustHash(tx.Data)
+	return tx
+}
+
+func Test_TxGossipQueue_Batch(t *testing.T) {
+	q := newTxGossipQueue()
+	tx1, tx2 := newTestGossipTx(2), newTestGossipTx(1)
+	q.push(tx1)
+	q.push(tx2)
+
+	hashes := q.pop(q.lastRefill)
+	assert.Equal(t, hashes, []common.Hash{tx1.Hash, tx2.Hash})
+	assert.Equal(t, len(q.pop(q.lastRefill)), 0)
+}
+
+func Test_TxGossipQueue_Budget(t *testing.T) {
+	q := newTxGossipQueue()
+	now := q.lastRefill
+
+	// only 1 hash could be announced within the budget
+	q.charge(txGossipBudgetMax - common.HashLength)
+	tx1, tx2 := newTestGossipTx(2), newTestGossipTx(1)
+	q.push(tx1)
+	q.push(tx2)
+
+	assert.Equal(t, q.pop(now), []common.Hash{tx2.Hash})
+	assert.Equal(t, len(q.pop(now)), 0)
+
+	// budget refilled
+	assert.Equal(t, q.pop(now.Add(time.Second)), []common.Hash{tx1.Hash})
+}
+
+func Test_TxGossipQueue_Serve(t *testing.T) {
+	q := newTxGossipQueue()
+	now := q.lastRefill
+
+	txs := make(map[common.Hash]*types.Transaction)
+	var hashes []common.Hash
+	for i := 0; i < maxTxGossipBatch+10; i++ {
+		tx := newTestGossipTx(uint64(i))
+		txs[tx.Hash] = tx
+		hashes = append(hashes, tx.Hash)
+	}
+	getTx := func(hash common.Hash) *types.Transaction { return txs[hash] }
+
+	// at most maxTxGossipBatch hashes are served
+	served := q.serve(append([]common.Hash{common.StringToHash("unknown")}, hashes...), getTx, now)
+	assert.Equal(t, len(served), maxTxGossipBatch-1)
+	assert.Equal(t, served[0], txs[hashes[0]])
+
+	// no tx is served once the budget is spent
+	q.charge(txGossipBudgetMax)
+	assert.Equal(t, len(q.serve(hashes, getTx, now)), 0)
+
+	// budget refilled
+	served = q.serve(hashes, getTx, now.Add(2*time.Second))
+	assert.Equal(t, len(served), maxTxGossipBatch)
+}
+
+func Test_SortTxsByGossipPriority(t *testing.T) {
+	tx1, tx2, tx3 := newTestGossipTx(1), newTestGossipTx(2), newTestGossipTx(3)
+	tx3.Data.GasPrice = big.NewInt(2)
+
+	txs := []*types.Transaction{tx1, tx2, tx3}
+	sortTxsByGossipPriority(txs)
+	assert.Equal(t, txs, []*types.Transaction{tx3, tx1, tx2})
+}
@@ -0,0 +1,59 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package hashrate
+
+import (
+	"math/big"
+
+	"github.com/seeleteam/go-seele/core/store"
+)
+
+// Estimate returns the average difficulty, block time and network hashrate of the specified number
+// of the recent canonical blocks till the specified height, which is DefaultEstimateBlocks if 0.
+// Unlike the History, the recent blocks of the incomplete epoch are estimated as well, and the blocks
+// are fewer if the height is less than the number of blocks, i.e. the genesis block is excluded.
+func Estimate(bcStore store.BlockchainStore, height, blocks uint64) (*Point, error) {
+	if blocks == 0 {
+		blocks = DefaultEstimateBlocks
+	}
+
+	if blocks > MaxEstimateBlocks {
+		return nil, ErrTooManyBlocks
+	}
+
+	if blocks > height {
+		blocks = height
+	}
+
+	difficulty := new(big.Int)
+	var startTime, endTime uint64
+	for h := height - blocks; h <= height; h++ {
+		hash, err := bcStore.GetBlockHash(h)
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := bcStore.GetBlockHeader(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if h == height-blocks {
+			startTime = header.CreateTimestamp.Uint64()
+			if blocks == 0 {
+				// only the genesis block, of which the difficulty is not mined
+				return &Point{Difficulty: new(big.Int).Set(header.Difficulty), Hashrate: new(big.Int)}, nil
+			}
+
+			continue
+		}
+
+		difficulty.Add(difficulty, header.Difficulty)
+		endTime = header.CreateTimestamp.Uint64()
+	}
+
+	return newPoint(height-blocks+1, height, difficulty, startTime, endTime), nil
+}
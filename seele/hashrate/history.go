@@ -0,0 +1,335 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package hashrate
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/event"
+	"github.com/seeleteam/go-seele/log"
+)
+
+const (
+	// EpochSize is the number of blocks aggregated in an epoch of the index.
+	EpochSize = 120
+
+	// MaxPoints is the maximum number of points returned in a single query.
+	MaxPoints = 1000
+
+	// DefaultEstimateBlocks is the default number of recent blocks to estimate the network hashrate.
+	DefaultEstimateBlocks = 120
+
+	// MaxEstimateBlocks is the maximum number of recent blocks to estimate the network hashrate.
+	MaxEstimateBlocks = 10 * EpochSize
+)
+
+var (
+	keyProgress    = []byte("hashrateProgress")
+	keyPrefixEpoch = []byte("hashrateEpoch")
+
+	// ErrInvalidRange is returned when the from height is larger than the to height.
+	ErrInvalidRange = errors.New("invalid height range")
+
+	// ErrTooManyPoints is returned when the query returns more than MaxPoints points.
+	ErrTooManyPoints = errors.New("too many points, please increase the step")
+
+	// ErrTooManyBlocks is returned when the estimation covers more than MaxEstimateBlocks blocks.
+	ErrTooManyBlocks = errors.New("too many blocks to estimate")
+)
+
+// epoch is the aggregation of the blocks of an epoch. The epoch n consists of the blocks
+// of heights (n*EpochSize, (n+1)*EpochSize], so that the genesis block is excluded.
+type epoch struct {
+	LastHash   common.Hash // LastHash is the hash of the last block, to detect the reorg
+	Difficulty *big.Int    // Difficulty is the sum of the block difficulties
+	StartTime  uint64      // StartTime is the timestamp of the block before the epoch
+	EndTime    uint64      // EndTime is the timestamp of the last block
+}
+
+// Point is the difficulty and estimated network hashrate of a height range.
+type Point struct {
+	FromHeight uint64   // FromHeight is the first height of the range
+	ToHeight   uint64   // ToHeight is the last height of the range
+	Difficulty *big.Int // Difficulty is the average block difficulty
+	BlockTime  float64  // BlockTime is the average block time in seconds
+	Hashrate   *big.Int // Hashrate is the estimated network hashes per second
+}
+
+// History maintains the per-epoch index of the block difficulties and timestamps of the
+// canonical chain, from which the difficulty history and network hashrate are served.
+// Since the expected hashes to mine a block equal its difficulty, the network hashrate
+// is estimated as the sum of difficulties divided by the time to mine the blocks.
+// There are following mappings in database:
+//  1. keyProgress => number of indexed epochs
+//  2. keyPrefixEpoch + epoch number => epoch
+type History struct {
+	bcStore store.BlockchainStore
+	db      database.Database
+	log     *log.SeeleLog
+
+	lock   sync.Mutex // protects the index updates
+	epochs uint64     // number of indexed epochs
+}
+
+// NewHistory creates the difficulty history of the specified blockchain store, of which
+// the index is persisted in the specified database.
+func NewHistory(bcStore store.BlockchainStore, db database.Database, log *log.SeeleLog) (*History, error) {
+	history := &History{
+		bcStore: bcStore,
+		db:      db,
+		log:     log,
+	}
+
+	if err := getValue(db, keyProgress, &history.epochs); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// Start catches up the index in background and then indexes upon block insertion.
+func (history *History) Start() {
+	event.BlockInsertedEventManager.AddAsyncListener(history.handleBlockInserted)
+	go history.handleBlockInserted(nil)
+}
+
+// Stop stops to index upon block insertion.
+func (history *History) Stop() {
+	event.BlockInsertedEventManager.RemoveListener(history.handleBlockInserted)
+}
+
+func (history *History) handleBlockInserted(e event.Event) {
+	if err := history.Sync(); err != nil {
+		history.log.Warn("failed to index the difficulty history, %s", err)
+	}
+}
+
+// Sync removes the epochs reverted due to reorg, and indexes the completed epochs of the
+// canonical chain till the HEAD block.
+func (history *History) Sync() error {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+
+	headHash, err := history.bcStore.GetHeadBlockHash()
+	if err != nil {
+		return err
+	}
+
+	head, err := history.bcStore.GetBlockHeader(headHash)
+	if err != nil {
+		return err
+	}
+
+	for history.epochs > 0 {
+		var last epoch
+		if err = getValue(history.db, epochKey(history.epochs-1), &last); err != nil {
+			return err
+		}
+
+		height := history.epochs * EpochSize
+		if height <= head.Height {
+			hash, err := history.bcStore.GetBlockHash(height)
+			if err != nil {
+				return err
+			}
+
+			if hash.Equal(last.LastHash) {
+				break
+			}
+		}
+
+		batch := history.db.NewBatch()
+		batch.Delete(epochKey(history.epochs - 1))
+		if err = history.commit(batch, history.epochs-1); err != nil {
+			return err
+		}
+	}
+
+	for (history.epochs+1)*EpochSize <= head.Height {
+		e, err := history.newEpoch(history.epochs)
+		if err != nil {
+			return err
+		}
+
+		batch := history.db.NewBatch()
+		batch.Put(epochKey(history.epochs), common.SerializePanic(e))
+		if err = history.commit(batch, history.epochs+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newEpoch aggregates the canonical blocks of the specified epoch.
+func (history *History) newEpoch(number uint64) (*epoch, error) {
+	e := &epoch{Difficulty: new(big.Int)}
+
+	for height := number * EpochSize; height <= (number+1)*EpochSize; height++ {
+		hash, err := history.bcStore.GetBlockHash(height)
+		if err != nil {
+			return nil, err
+		}
+
+		header, err := history.bcStore.GetBlockHeader(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if height == number*EpochSize {
+			e.StartTime = header.CreateTimestamp.Uint64()
+			continue
+		}
+
+		e.Difficulty.Add(e.Difficulty, header.Difficulty)
+		e.LastHash, e.EndTime = hash, header.CreateTimestamp.Uint64()
+	}
+
+	return e, nil
+}
+
+func (history *History) commit(batch database.Batch, epochs uint64) error {
+	batch.Put(keyProgress, common.SerializePanic(epochs))
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	history.epochs = epochs
+	return nil
+}
+
+// IndexedHeight returns the last height of the indexed epochs.
+func (history *History) IndexedHeight() uint64 {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+
+	return history.epochs * EpochSize
+}
+
+// Query returns the difficulty history of the indexed epochs in the specified height range,
+// in which each point aggregates step blocks. The step is rounded up to a multiple of EpochSize,
+// and the range is aligned to the epochs that cover it.
+func (history *History) Query(from, to, step uint64) ([]*Point, error) {
+	if from > to {
+		return nil, ErrInvalidRange
+	}
+
+	epochsPerPoint := (step + EpochSize - 1) / EpochSize
+	if epochsPerPoint == 0 {
+		epochsPerPoint = 1
+	}
+
+	history.lock.Lock()
+	defer history.lock.Unlock()
+
+	first := uint64(0)
+	if from > 0 {
+		first = (from - 1) / EpochSize
+	}
+
+	last := uint64(0)
+	if to > 0 {
+		last = (to - 1) / EpochSize
+	}
+
+	if history.epochs == 0 || first >= history.epochs {
+		return nil, nil
+	}
+
+	if last >= history.epochs {
+		last = history.epochs - 1
+	}
+
+	if (last-first)/epochsPerPoint+1 > MaxPoints {
+		return nil, ErrTooManyPoints
+	}
+
+	var points []*Point
+	for start := first; start <= last; start += epochsPerPoint {
+		end := start + epochsPerPoint - 1
+		if end > last {
+			end = last
+		}
+
+		point, err := history.newPoint(start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// newPoint aggregates the epochs in the range [start, end].
+func (history *History) newPoint(start, end uint64) (*Point, error) {
+	difficulty := new(big.Int)
+	var startTime, endTime uint64
+
+	for number := start; number <= end; number++ {
+		var e epoch
+		if err := getValue(history.db, epochKey(number), &e); err != nil {
+			return nil, err
+		}
+
+		if number == start {
+			startTime = e.StartTime
+		}
+
+		difficulty.Add(difficulty, e.Difficulty)
+		endTime = e.EndTime
+	}
+
+	return newPoint(start*EpochSize+1, (end+1)*EpochSize, difficulty, startTime, endTime), nil
+}
+
+// newPoint returns the point of the blocks in the height range [from, to], of which the sum of the
+// difficulties is specified, and mined from the timestamp of the block before the range to the
+// timestamp of the last block.
+func newPoint(from, to uint64, difficulty *big.Int, startTime, endTime uint64) *Point {
+	blocks := to - from + 1
+	point := &Point{
+		FromHeight: from,
+		ToHeight:   to,
+		Difficulty: new(big.Int).Div(difficulty, new(big.Int).SetUint64(blocks)),
+		Hashrate:   new(big.Int),
+	}
+
+	if endTime > startTime {
+		duration := endTime - startTime
+		point.BlockTime = float64(duration) / float64(blocks)
+		point.Hashrate.Div(difficulty, new(big.Int).SetUint64(duration))
+	}
+
+	return point
+}
+
+func getValue(db database.Database, key []byte, value interface{}) error {
+	exist, err := db.Has(key)
+	if err != nil || !exist {
+		return err
+	}
+
+	encoded, err := db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return common.Deserialize(encoded, value)
+}
+
+func epochKey(number uint64) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, number)
+	return append(append([]byte{}, keyPrefixEpoch...), encoded...)
+}
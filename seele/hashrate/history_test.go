@@ -0,0 +1,138 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package hashrate
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database/leveldb"
+)
+
+func newTestHistory(t *testing.T) (*History, store.BlockchainStore, func()) {
+	dir, err := ioutil.TempDir("", "hashrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	bcStore := store.NewBlockchainDatabase(db)
+	history, err := NewHistory(bcStore, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return history, bcStore, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// putTestBlocks puts the canonical headers of the specified heights with the difficulty,
+// which are mined every 10 seconds.
+func putTestBlocks(t *testing.T, bcStore store.BlockchainStore, from, to uint64, difficulty int64) {
+	for height := from; height <= to; height++ {
+		header := &types.BlockHeader{
+			PreviousBlockHash: common.StringToHash("parent"),
+			Difficulty:        big.NewInt(difficulty),
+			Height:            height,
+			CreateTimestamp:   new(big.Int).SetUint64(height * 10),
+		}
+
+		assert.Equal(t, bcStore.PutBlockHeader(header.Hash(), header, header.Difficulty, true), error(nil))
+	}
+}
+
+func Test_History_Query(t *testing.T) {
+	history, bcStore, dispose := newTestHistory(t)
+	defer dispose()
+
+	putTestBlocks(t, bcStore, 0, 3*EpochSize+5, 1000)
+	assert.Equal(t, history.Sync(), error(nil))
+	assert.Equal(t, history.IndexedHeight(), uint64(3*EpochSize))
+
+	points, err := history.Query(1, 3*EpochSize+5, 1)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(points), 3)
+	assert.Equal(t, points[1].FromHeight, uint64(EpochSize+1))
+	assert.Equal(t, points[1].ToHeight, uint64(2*EpochSize))
+	assert.Equal(t, points[1].Difficulty, big.NewInt(1000))
+	assert.Equal(t, points[1].BlockTime, float64(10))
+	assert.Equal(t, points[1].Hashrate, big.NewInt(100))
+
+	// aggregated by step
+	points, err = history.Query(EpochSize+1, 3*EpochSize, 2*EpochSize)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(points), 1)
+	assert.Equal(t, points[0].ToHeight, uint64(3*EpochSize))
+
+	_, err = history.Query(2, 1, 1)
+	assert.Equal(t, err, ErrInvalidRange)
+
+	points, err = history.Query(3*EpochSize+1, 3*EpochSize+5, 1)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(points), 0)
+}
+
+func Test_History_Reorg(t *testing.T) {
+	history, bcStore, dispose := newTestHistory(t)
+	defer dispose()
+
+	putTestBlocks(t, bcStore, 0, 2*EpochSize, 1000)
+	assert.Equal(t, history.Sync(), error(nil))
+	assert.Equal(t, history.IndexedHeight(), uint64(2*EpochSize))
+
+	// the second epoch is reverted and mined with a higher difficulty
+	putTestBlocks(t, bcStore, EpochSize+1, 2*EpochSize+1, 2000)
+	assert.Equal(t, history.Sync(), error(nil))
+
+	points, err := history.Query(1, 2*EpochSize, 1)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(points), 2)
+	assert.Equal(t, points[0].Difficulty, big.NewInt(1000))
+	assert.Equal(t, points[1].Difficulty, big.NewInt(2000))
+}
+
+func Test_Estimate(t *testing.T) {
+	_, bcStore, dispose := newTestHistory(t)
+	defer dispose()
+
+	putTestBlocks(t, bcStore, 0, 50, 1000)
+	putTestBlocks(t, bcStore, 51, 60, 3000)
+
+	point, err := Estimate(bcStore, 60, 10)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, point.FromHeight, uint64(51))
+	assert.Equal(t, point.ToHeight, uint64(60))
+	assert.Equal(t, point.Difficulty, big.NewInt(3000))
+	assert.Equal(t, point.BlockTime, float64(10))
+	assert.Equal(t, point.Hashrate, big.NewInt(300))
+
+	// fewer blocks near the genesis block
+	point, err = Estimate(bcStore, 20, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, point.FromHeight, uint64(1))
+	assert.Equal(t, point.ToHeight, uint64(20))
+	assert.Equal(t, point.Hashrate, big.NewInt(100))
+
+	point, err = Estimate(bcStore, 0, 0)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, point.Hashrate, big.NewInt(0))
+
+	_, err = Estimate(bcStore, 60, MaxEstimateBlocks+1)
+	assert.Equal(t, err, ErrTooManyBlocks)
+}
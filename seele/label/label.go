@@ -0,0 +1,147 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package label
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/database"
+)
+
+var (
+	keyPrefixTx      = []byte("labelTx")
+	keyPrefixAddress = []byte("labelAddr")
+
+	// ErrEmptyLabel is returned when the label to add is empty.
+	ErrEmptyLabel = errors.New("label is empty")
+)
+
+// Store is a local (non-consensus) store of the labels attached to tx hashes
+// and account addresses, e.g. deposit, withdrawal or sweep for accounting.
+// There are following mappings in database:
+//  1. keyPrefixTx + tx hash => labels
+//  2. keyPrefixAddress + address => labels
+type Store struct {
+	db   database.Database
+	lock sync.Mutex // protects the read-modify-write of labels
+}
+
+// NewStore returns a label store persisted in the specified database.
+func NewStore(db database.Database) *Store {
+	return &Store{db: db}
+}
+
+func txKey(hash common.Hash) []byte {
+	return append(append([]byte{}, keyPrefixTx...), hash.Bytes()...)
+}
+
+func addressKey(addr common.Address) []byte {
+	return append(append([]byte{}, keyPrefixAddress...), addr.Bytes()...)
+}
+
+// AddTxLabel attaches the label to the specified tx hash.
+func (store *Store) AddTxLabel(hash common.Hash, label string) error {
+	return store.add(txKey(hash), label)
+}
+
+// RemoveTxLabel detaches the label from the specified tx hash.
+func (store *Store) RemoveTxLabel(hash common.Hash, label string) error {
+	return store.remove(txKey(hash), label)
+}
+
+// GetTxLabels returns the labels of the specified tx hash.
+func (store *Store) GetTxLabels(hash common.Hash) ([]string, error) {
+	return store.get(txKey(hash))
+}
+
+// AddAddressLabel attaches the label to the specified account address.
+func (store *Store) AddAddressLabel(addr common.Address, label string) error {
+	return store.add(addressKey(addr), label)
+}
+
+// RemoveAddressLabel detaches the label from the specified account address.
+func (store *Store) RemoveAddressLabel(addr common.Address, label string) error {
+	return store.remove(addressKey(addr), label)
+}
+
+// GetAddressLabels returns the labels of the specified account address.
+func (store *Store) GetAddressLabels(addr common.Address) ([]string, error) {
+	return store.get(addressKey(addr))
+}
+
+func (store *Store) get(key []byte) ([]string, error) {
+	exist, err := store.db.Has(key)
+	if err != nil || !exist {
+		return nil, err
+	}
+
+	value, err := store.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	if err = common.Deserialize(value, &labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+func (store *Store) put(key []byte, labels []string) error {
+	if len(labels) == 0 {
+		return store.db.Delete(key)
+	}
+
+	value, err := common.Serialize(labels)
+	if err != nil {
+		return err
+	}
+
+	return store.db.Put(key, value)
+}
+
+func (store *Store) add(key []byte, label string) error {
+	if len(label) == 0 {
+		return ErrEmptyLabel
+	}
+
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	labels, err := store.get(key)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range labels {
+		if l == label {
+			return nil
+		}
+	}
+
+	return store.put(key, append(labels, label))
+}
+
+func (store *Store) remove(key []byte, label string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	labels, err := store.get(key)
+	if err != nil {
+		return err
+	}
+
+	for i, l := range labels {
+		if l == label {
+			return store.put(key, append(labels[:i], labels[i+1:]...))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package label
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database/leveldb"
+)
+
+func newTestStore(t *testing.T) (*Store, func()) {
+	dir, err := ioutil.TempDir("", "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return NewStore(db), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func Test_Store_TxLabels(t *testing.T) {
+	store, dispose := newTestStore(t)
+	defer dispose()
+
+	hash := common.StringToHash("tx")
+
+	labels, err := store.GetTxLabels(hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(labels), 0)
+
+	assert.Equal(t, store.AddTxLabel(hash, ""), ErrEmptyLabel)
+	assert.Equal(t, store.AddTxLabel(hash, "deposit"), error(nil))
+	assert.Equal(t, store.AddTxLabel(hash, "sweep"), error(nil))
+	assert.Equal(t, store.AddTxLabel(hash, "deposit"), error(nil))
+
+	labels, err = store.GetTxLabels(hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, labels, []string{"deposit", "sweep"})
+
+	assert.Equal(t, store.RemoveTxLabel(hash, "deposit"), error(nil))
+	assert.Equal(t, store.RemoveTxLabel(hash, "unknown"), error(nil))
+	labels, _ = store.GetTxLabels(hash)
+	assert.Equal(t, labels, []string{"sweep"})
+
+	assert.Equal(t, store.RemoveTxLabel(hash, "sweep"), error(nil))
+	labels, _ = store.GetTxLabels(hash)
+	assert.Equal(t, len(labels), 0)
+}
+
+func Test_Store_AddressLabels(t *testing.T) {
+	store, dispose := newTestStore(t)
+	defer dispose()
+
+	addr := *crypto.MustGenerateRandomAddress()
+	assert.Equal(t, store.AddAddressLabel(addr, "withdrawal"), error(nil))
+
+	labels, err := store.GetAddressLabels(addr)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, labels, []string{"withdrawal"})
+
+	// tx labels are separated from address labels
+	labels, _ = store.GetTxLabels(common.BytesToHash(addr.Bytes()))
+	assert.Equal(t, len(labels), 0)
+}
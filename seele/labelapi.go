@@ -0,0 +1,163 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/seele/label"
+)
+
+// PublicLabelAPI provides an API to get the labels of the txs and accounts in local node database.
+type PublicLabelAPI struct {
+	s *SeeleService
+}
+
+// NewPublicLabelAPI creates a new PublicLabelAPI object for rpc service.
+func NewPublicLabelAPI(s *SeeleService) *PublicLabelAPI {
+	return &PublicLabelAPI{s}
+}
+
+// TxLabelRequest request param for AddTxLabel and RemoveTxLabel api
+type TxLabelRequest struct {
+	HashHex string
+	Label   string
+}
+
+// AddressLabelRequest request param for AddAddressLabel and RemoveAddressLabel api
+type AddressLabelRequest struct {
+	AddressHex string
+	Label      string
+}
+
+// GetTxLabels returns the labels of the specified tx hash
+func (api *PublicLabelAPI) GetTxLabels(hashHex *string, result *[]string) error {
+	hash, err := common.HexToHash(*hashHex)
+	if err != nil {
+		return err
+	}
+
+	labels, err := api.s.labels.GetTxLabels(hash)
+	if err != nil {
+		return err
+	}
+
+	*result = labels
+	return nil
+}
+
+// GetAddressLabels returns the labels of the specified account address
+func (api *PublicLabelAPI) GetAddressLabels(addressHex *string, result *[]string) error {
+	addr, err := common.HexToAddress(*addressHex)
+	if err != nil {
+		return err
+	}
+
+	labels, err := api.s.labels.GetAddressLabels(addr)
+	if err != nil {
+		return err
+	}
+
+	*result = labels
+	return nil
+}
+
+// PrivateLabelAPI provides an API to label the txs and accounts in local node database for accounting,
+// which is only served on the admin rpc listener.
+type PrivateLabelAPI struct {
+	s *SeeleService
+}
+
+// NewPrivateLabelAPI creates a new PrivateLabelAPI object for rpc service.
+func NewPrivateLabelAPI(s *SeeleService) *PrivateLabelAPI {
+	return &PrivateLabelAPI{s}
+}
+
+// AddTxLabel attaches the label to the specified tx hash
+func (api *PrivateLabelAPI) AddTxLabel(request *TxLabelRequest, result *bool) error {
+	hash, err := common.HexToHash(request.HashHex)
+	if err != nil {
+		return err
+	}
+
+	if err = api.s.labels.AddTxLabel(hash, request.Label); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// RemoveTxLabel detaches the label from the specified tx hash
+func (api *PrivateLabelAPI) RemoveTxLabel(request *TxLabelRequest, result *bool) error {
+	hash, err := common.HexToHash(request.HashHex)
+	if err != nil {
+		return err
+	}
+
+	if err = api.s.labels.RemoveTxLabel(hash, request.Label); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// AddAddressLabel attaches the label to the specified account address
+func (api *PrivateLabelAPI) AddAddressLabel(request *AddressLabelRequest, result *bool) error {
+	addr, err := common.HexToAddress(request.AddressHex)
+	if err != nil {
+		return err
+	}
+
+	if err = api.s.labels.AddAddressLabel(addr, request.Label); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// RemoveAddressLabel detaches the label from the specified account address
+func (api *PrivateLabelAPI) RemoveAddressLabel(request *AddressLabelRequest, result *bool) error {
+	addr, err := common.HexToAddress(request.AddressHex)
+	if err != nil {
+		return err
+	}
+
+	if err = api.s.labels.RemoveAddressLabel(addr, request.Label); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// rpcOutputTxLabels adds the local labels of the given tx and its accounts to the tx RPC output
+func rpcOutputTxLabels(transaction map[string]interface{}, tx *types.Transaction, labels *label.Store) error {
+	txLabels, err := labels.GetTxLabels(tx.Hash)
+	if err != nil {
+		return err
+	}
+
+	fromLabels, err := labels.GetAddressLabels(tx.Data.From)
+	if err != nil {
+		return err
+	}
+
+	var toLabels []string
+	if tx.Data.To != nil {
+		if toLabels, err = labels.GetAddressLabels(*tx.Data.To); err != nil {
+			return err
+		}
+	}
+
+	transaction["labels"] = txLabels
+	transaction["fromLabels"] = fromLabels
+	transaction["toLabels"] = toLabels
+
+	return nil
+}
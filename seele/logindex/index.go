@@ -0,0 +1,592 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package logindex
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/event"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/seele/firehose"
+)
+
+const (
+	// bucketSize is the number of heights covered by a posting list, so that
+	// a range query reads at most one posting list per bucket for each key.
+	bucketSize = 4096
+
+	// MaxLogs is the maximum number of logs returned in a single query.
+	MaxLogs = 10000
+
+	// snapshotMaxAge is the maximum age of the database snapshot shared by the queries.
+	snapshotMaxAge = 10 * time.Second
+)
+
+var (
+	keyProgress      = []byte("logIdxProgress")
+	keyPrefixLogs    = []byte("logIdxLogs")
+	keyPrefixAddress = []byte("logIdxAddr")
+	keyPrefixTopic   = []byte("logIdxTopic")
+
+	// ErrInvalidRange is returned when the from height is larger than the to height.
+	ErrInvalidRange = errors.New("invalid height range")
+)
+
+// Filter specifies the logs to query.
+type Filter struct {
+	FromHeight uint64           // FromHeight is the first height to query
+	ToHeight   uint64           // ToHeight is the last height to query
+	Addresses  []common.Address // Addresses matches the logs of any of the contracts, empty to match all
+	Topics     []common.Hash    // Topics matches the logs that contain all the topics
+}
+
+// Match indicates whether the specified log matches the filter.
+func (filter *Filter) Match(l *types.Log) bool {
+	if l.BlockNumber < filter.FromHeight || l.BlockNumber > filter.ToHeight {
+		return false
+	}
+
+	if len(filter.Addresses) > 0 {
+		found := false
+		for _, addr := range filter.Addresses {
+			if addr.Equal(l.Address) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	for _, topic := range filter.Topics {
+		found := false
+		for _, t := range l.Topics {
+			if t.Equal(topic) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchBloom indicates whether the block of the specified logs bloom may have the logs
+// matching the addresses and topics of the filter.
+func (filter *Filter) MatchBloom(bloom *types.Bloom) bool {
+	if len(filter.Addresses) > 0 {
+		found := false
+		for _, addr := range filter.Addresses {
+			if bloom.Test(addr.Bytes()) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	for _, topic := range filter.Topics {
+		if !bloom.Test(topic.Bytes()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Indexer builds the inverted indices from contract addresses and log topics to block
+// heights for the canonical chain, so that logs over large height ranges could be queried
+// without re-executing the blocks. There are following mappings in database:
+//  1. keyProgress => cursor of the last indexed block
+//  2. keyPrefixLogs + height => logs of the indexed block
+//  3. keyPrefixAddress + address + bucket => heights of the logs of the contract
+//  4. keyPrefixTopic + topic + bucket => heights of the logs with the topic
+//
+// The queries read from the snapshot of the database refreshed once indexed,
+// so that they don't block the indexing upon block insertion.
+type Indexer struct {
+	hose    *firehose.Firehose
+	db      database.Database
+	replica *database.Replica
+	log     *log.SeeleLog
+
+	lock       sync.Mutex // protects the index updates
+	progress   firehose.Cursor
+	onProgress func(progress firehose.Cursor)
+}
+
+// NewIndexer creates a log indexer that reads the blocks from the specified firehose
+// and persists the indices in the specified database.
+func NewIndexer(hose *firehose.Firehose, db database.Database, log *log.SeeleLog) (*Indexer, error) {
+	indexer := &Indexer{
+		hose:    hose,
+		db:      db,
+		replica: database.NewReplica(db, snapshotMaxAge),
+		log:     log,
+	}
+
+	exist, err := db.Has(keyProgress)
+	if err != nil {
+		return nil, err
+	}
+
+	if exist {
+		value, err := db.Get(keyProgress)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = common.Deserialize(value, &indexer.progress); err != nil {
+			return nil, err
+		}
+	}
+
+	return indexer, nil
+}
+
+// Start catches up the indices in background and then indexes upon block insertion.
+func (indexer *Indexer) Start() {
+	event.BlockInsertedEventManager.AddAsyncListener(indexer.handleBlockInserted)
+	go indexer.handleBlockInserted(nil)
+}
+
+// Stop stops to index upon block insertion.
+func (indexer *Indexer) Stop() {
+	event.BlockInsertedEventManager.RemoveListener(indexer.handleBlockInserted)
+	indexer.replica.Invalidate()
+}
+
+func (indexer *Indexer) handleBlockInserted(e event.Event) {
+	if err := indexer.Sync(context.Background()); err != nil {
+		indexer.log.Warn("failed to index logs, %s", err)
+	}
+}
+
+// Progress returns the cursor of the last indexed block.
+func (indexer *Indexer) Progress() firehose.Cursor {
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	return indexer.progress
+}
+
+// SetProgressHandler sets the handler called with the progress once a block is indexed or
+// unindexed, e.g. to report the progress of Reindex. The handler is called with the index
+// updates locked, and should not call the indexer.
+func (indexer *Indexer) SetProgressHandler(handler func(progress firehose.Cursor)) {
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	indexer.onProgress = handler
+}
+
+// Sync indexes the canonical blocks after the last indexed block till the HEAD block.
+// The indices of blocks reverted due to reorg are removed first.
+func (indexer *Indexer) Sync(ctx context.Context) error {
+	indexer.lock.Lock()
+	defer indexer.lock.Unlock()
+
+	for {
+		records, err := indexer.hose.Records(ctx, indexer.progress, firehose.MaxRecords)
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			return nil
+		}
+
+		for _, record := range records {
+			if err = indexer.apply(record); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Reindex removes all indices and indexes the canonical chain from genesis again.
+func (indexer *Indexer) Reindex(ctx context.Context) error {
+	indexer.lock.Lock()
+	for height := indexer.progress.Height; height > 0; height-- {
+		if err := ctx.Err(); err != nil {
+			indexer.lock.Unlock()
+			return err
+		}
+
+		batch := indexer.db.NewBatch()
+		if err := indexer.unindex(batch, height); err != nil {
+			indexer.lock.Unlock()
+			return err
+		}
+
+		if err := indexer.commit(batch, firehose.Cursor{Height: height - 1}); err != nil {
+			indexer.lock.Unlock()
+			return err
+		}
+	}
+	indexer.lock.Unlock()
+
+	return indexer.Sync(ctx)
+}
+
+// apply indexes or unindexes the block of the specified record along with the progress in a batch.
+func (indexer *Indexer) apply(record *firehose.Record) error {
+	batch := indexer.db.NewBatch()
+	height := record.Block.Header.Height
+
+	if record.Undo {
+		if err := indexer.unindex(batch, height); err != nil {
+			return err
+		}
+	} else if err := indexer.index(batch, height, record.Receipts); err != nil {
+		return err
+	}
+
+	return indexer.commit(batch, record.Cursor)
+}
+
+func (indexer *Indexer) commit(batch database.Batch, progress firehose.Cursor) error {
+	batch.Put(keyProgress, common.SerializePanic(progress))
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	indexer.progress = progress
+	indexer.replica.Invalidate()
+
+	if indexer.onProgress != nil {
+		indexer.onProgress(progress)
+	}
+	return nil
+}
+
+func (indexer *Indexer) index(batch database.Batch, height uint64, receipts []*types.Receipt) error {
+	var logs []*types.Log
+	for i, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			l.BlockNumber = height
+			l.TxIndex = uint(i + 1) // the miner reward tx has no receipt
+			logs = append(logs, l)
+		}
+	}
+
+	if len(logs) == 0 {
+		return nil
+	}
+
+	batch.Put(logsKey(height), common.SerializePanic(logs))
+
+	for _, key := range postingKeys(logs, height) {
+		heights, err := getHeights(indexer.db, key)
+		if err != nil {
+			return err
+		}
+
+		batch.Put(key, common.SerializePanic(append(heights, height)))
+	}
+
+	return nil
+}
+
+func (indexer *Indexer) unindex(batch database.Batch, height uint64) error {
+	logs, err := getLogs(indexer.db, height)
+	if err != nil || len(logs) == 0 {
+		return err
+	}
+
+	batch.Delete(logsKey(height))
+
+	for _, key := range postingKeys(logs, height) {
+		heights, err := getHeights(indexer.db, key)
+		if err != nil {
+			return err
+		}
+
+		var remains []uint64
+		for _, h := range heights {
+			if h != height {
+				remains = append(remains, h)
+			}
+		}
+
+		if len(remains) == 0 {
+			batch.Delete(key)
+		} else {
+			batch.Put(key, common.SerializePanic(remains))
+		}
+	}
+
+	return nil
+}
+
+// GetLogs returns the logs that match the specified filter from the indices in height
+// ASC order. The heights that are not indexed yet are ignored.
+func (indexer *Indexer) GetLogs(ctx context.Context, filter *Filter) ([]*types.Log, error) {
+	if filter.FromHeight > filter.ToHeight {
+		return nil, ErrInvalidRange
+	}
+
+	db, release, err := indexer.replica.Acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// the progress of the snapshot is consistent with the indices in it
+	var progress firehose.Cursor
+	if err = getValue(db, keyProgress, &progress); err != nil {
+		return nil, err
+	}
+
+	if filter.ToHeight > progress.Height {
+		filter.ToHeight = progress.Height
+	}
+
+	heights, err := candidateHeights(ctx, db, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*types.Log
+	for _, height := range heights {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		logs, err := getLogs(db, height)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range logs {
+			if filter.Match(l) {
+				result = append(result, l)
+			}
+		}
+
+		if len(result) >= MaxLogs {
+			return result[:MaxLogs], nil
+		}
+	}
+
+	return result, nil
+}
+
+// candidateHeights returns the heights in range that may have the logs matching the filter,
+// i.e. the union of the address postings intersected with each of the topic postings.
+func candidateHeights(ctx context.Context, db database.Database, filter *Filter) ([]uint64, error) {
+	var candidates map[uint64]bool
+
+	if len(filter.Addresses) > 0 {
+		candidates = make(map[uint64]bool)
+		for _, addr := range filter.Addresses {
+			heights, err := rangeHeights(ctx, db, keyPrefixAddress, addr.Bytes(), filter)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, h := range heights {
+				candidates[h] = true
+			}
+		}
+	}
+
+	for _, topic := range filter.Topics {
+		heights, err := rangeHeights(ctx, db, keyPrefixTopic, topic.Bytes(), filter)
+		if err != nil {
+			return nil, err
+		}
+
+		matched := make(map[uint64]bool)
+		for _, h := range heights {
+			if candidates == nil || candidates[h] {
+				matched[h] = true
+			}
+		}
+
+		candidates = matched
+	}
+
+	// neither address nor topic specified, check every block in range
+	if candidates == nil {
+		var heights []uint64
+		for h := filter.FromHeight; h <= filter.ToHeight; h++ {
+			heights = append(heights, h)
+		}
+
+		return heights, nil
+	}
+
+	heights := make([]uint64, 0, len(candidates))
+	for h := range candidates {
+		heights = append(heights, h)
+	}
+
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+// rangeHeights returns the heights in range from the posting lists of the specified key.
+func rangeHeights(ctx context.Context, db database.Database, prefix, key []byte, filter *Filter) ([]uint64, error) {
+	var result []uint64
+	for bucket := filter.FromHeight / bucketSize; bucket <= filter.ToHeight/bucketSize; bucket++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		heights, err := getHeights(db, postingKey(prefix, key, bucket))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range heights {
+			if h >= filter.FromHeight && h <= filter.ToHeight {
+				result = append(result, h)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func getLogs(db database.Database, height uint64) ([]*types.Log, error) {
+	var logs []*types.Log
+	err := getValue(db, logsKey(height), &logs)
+	return logs, err
+}
+
+func getHeights(db database.Database, key []byte) ([]uint64, error) {
+	var heights []uint64
+	err := getValue(db, key, &heights)
+	return heights, err
+}
+
+func getValue(db database.Database, key []byte, value interface{}) error {
+	exist, err := db.Has(key)
+	if err != nil || !exist {
+		return err
+	}
+
+	encoded, err := db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return common.Deserialize(encoded, value)
+}
+
+func encodeHeight(height uint64) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, height)
+	return encoded
+}
+
+func logsKey(height uint64) []byte {
+	return append(append([]byte{}, keyPrefixLogs...), encodeHeight(height)...)
+}
+
+func postingKey(prefix, key []byte, bucket uint64) []byte {
+	result := append(append([]byte{}, prefix...), key...)
+	return append(result, encodeHeight(bucket)...)
+}
+
+// postingKeys returns the distinct posting keys of the addresses and topics in the specified logs.
+func postingKeys(logs []*types.Log, height uint64) [][]byte {
+	bucket := height / bucketSize
+	set := make(map[string]bool)
+
+	var keys [][]byte
+	add := func(key []byte) {
+		if !set[string(key)] {
+			set[string(key)] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, l := range logs {
+		add(postingKey(keyPrefixAddress, l.Address.Bytes(), bucket))
+		for _, topic := range l.Topics {
+			add(postingKey(keyPrefixTopic, topic.Bytes(), bucket))
+		}
+	}
+
+	return keys
+}
+
+// MaxScanRange is the maximum number of blocks to re-execute in a single query without indices.
+const MaxScanRange = 1024
+
+// ErrScanRangeTooLarge is returned when the range to scan without indices is too large.
+var ErrScanRangeTooLarge = errors.New("height range too large to query without log indices")
+
+// ScanLogs returns the logs that match the specified filter by re-executing the canonical
+// blocks in range, which is slow and only used if the indexer is disabled. The blocks whose
+// logs bloom mismatches the filter are skipped without re-execution.
+func ScanLogs(ctx context.Context, hose *firehose.Firehose, filter *Filter) ([]*types.Log, error) {
+	if filter.FromHeight > filter.ToHeight {
+		return nil, ErrInvalidRange
+	}
+
+	if filter.ToHeight-filter.FromHeight >= MaxScanRange {
+		return nil, ErrScanRangeTooLarge
+	}
+
+	var result []*types.Log
+	if filter.FromHeight == 0 {
+		filter.FromHeight = 1 // genesis block has no tx
+	}
+
+	for height := filter.FromHeight; height <= filter.ToHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		header, err := hose.BlockHeader(height)
+		if err != nil {
+			return nil, err
+		}
+
+		if !filter.MatchBloom(&header.LogsBloom) {
+			continue
+		}
+
+		records, err := hose.Records(ctx, firehose.Cursor{Height: height - 1}, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			for i, receipt := range record.Receipts {
+				for _, l := range receipt.Logs {
+					l.BlockNumber = record.Block.Header.Height
+					l.TxIndex = uint(i + 1)
+					if filter.Match(l) {
+						result = append(result, l)
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
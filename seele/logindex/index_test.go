@@ -0,0 +1,128 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package logindex
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/seele/firehose"
+)
+
+func newTestIndexer(t *testing.T) (*Indexer, func()) {
+	dir, err := ioutil.TempDir("", "logindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	indexer, err := NewIndexer(nil, db, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return indexer, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func indexTestLogs(t *testing.T, indexer *Indexer, height uint64, logs ...*types.Log) {
+	batch := indexer.db.NewBatch()
+	receipts := []*types.Receipt{{Logs: logs}}
+	assert.Equal(t, indexer.index(batch, height, receipts), error(nil))
+	assert.Equal(t, indexer.commit(batch, firehose.Cursor{Height: height}), error(nil))
+}
+
+func Test_Indexer_GetLogs(t *testing.T) {
+	indexer, dispose := newTestIndexer(t)
+	defer dispose()
+
+	contract1 := *crypto.MustGenerateRandomAddress()
+	contract2 := *crypto.MustGenerateRandomAddress()
+	topic1 := common.StringToHash("topic1")
+	topic2 := common.StringToHash("topic2")
+
+	indexTestLogs(t, indexer, 1, &types.Log{Address: contract1, Topics: []common.Hash{topic1}})
+	indexTestLogs(t, indexer, 2, &types.Log{Address: contract2, Topics: []common.Hash{topic1, topic2}})
+	indexTestLogs(t, indexer, bucketSize+1, &types.Log{Address: contract1, Topics: []common.Hash{topic2}})
+
+	ctx := context.Background()
+	all := &Filter{FromHeight: 0, ToHeight: bucketSize + 1}
+
+	logs, err := indexer.GetLogs(ctx, all)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(logs), 3)
+	assert.Equal(t, logs[0].TxIndex, uint(1))
+
+	// by address across buckets
+	logs, err = indexer.GetLogs(ctx, &Filter{ToHeight: bucketSize + 1, Addresses: []common.Address{contract1}})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(logs), 2)
+	assert.Equal(t, logs[1].BlockNumber, uint64(bucketSize+1))
+
+	// by address and topic
+	logs, err = indexer.GetLogs(ctx, &Filter{ToHeight: bucketSize + 1, Addresses: []common.Address{contract1}, Topics: []common.Hash{topic1}})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(logs), 1)
+	assert.Equal(t, logs[0].BlockNumber, uint64(1))
+
+	// by topics in range
+	logs, err = indexer.GetLogs(ctx, &Filter{FromHeight: 2, ToHeight: 2, Topics: []common.Hash{topic2}})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(logs), 1)
+	assert.Equal(t, logs[0].Address, contract2)
+
+	// invalid range
+	_, err = indexer.GetLogs(ctx, &Filter{FromHeight: 2, ToHeight: 1})
+	assert.Equal(t, err, ErrInvalidRange)
+}
+
+func Test_Indexer_Unindex(t *testing.T) {
+	indexer, dispose := newTestIndexer(t)
+	defer dispose()
+
+	contract := *crypto.MustGenerateRandomAddress()
+	indexTestLogs(t, indexer, 1, &types.Log{Address: contract})
+	indexTestLogs(t, indexer, 2, &types.Log{Address: contract})
+
+	// revert the block 2
+	batch := indexer.db.NewBatch()
+	assert.Equal(t, indexer.unindex(batch, 2), error(nil))
+	assert.Equal(t, indexer.commit(batch, firehose.Cursor{Height: 1}), error(nil))
+
+	heights, err := getHeights(indexer.db, postingKey(keyPrefixAddress, contract.Bytes(), 0))
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, heights, []uint64{1})
+
+	logs, err := indexer.GetLogs(context.Background(), &Filter{ToHeight: 2, Addresses: []common.Address{contract}})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(logs), 1)
+}
+
+func Test_Filter_MatchBloom(t *testing.T) {
+	contract := *crypto.MustGenerateRandomAddress()
+	topic := common.StringToHash("topic")
+	receipts := []*types.Receipt{{Logs: []*types.Log{{Address: contract, Topics: []common.Hash{topic}}}}}
+	bloom := types.CreateBloom(receipts)
+
+	assert.Equal(t, (&Filter{}).MatchBloom(&bloom), true)
+	assert.Equal(t, (&Filter{Addresses: []common.Address{contract}, Topics: []common.Hash{topic}}).MatchBloom(&bloom), true)
+	assert.Equal(t, (&Filter{Topics: []common.Hash{topic, common.StringToHash("other")}}).MatchBloom(&bloom), false)
+	assert.Equal(t, (&Filter{Addresses: []common.Address{*crypto.MustGenerateRandomAddress()}}).MatchBloom(&bloom), false)
+}
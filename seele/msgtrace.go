@@ -0,0 +1,125 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"fmt"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/seele/download"
+)
+
+var msgCodeNames = map[uint16]string{
+	transactionHashMsgCode:        "transactionHash",
+	transactionRequestMsgCode:     "transactionRequest",
+	transactionsMsgCode:           "transactions",
+	blockHashMsgCode:              "blockHash",
+	blockRequestMsgCode:           "blockRequest",
+	blockMsgCode:                  "block",
+	statusDataMsgCode:             "statusData",
+	statusChainHeadMsgCode:        "statusChainHead",
+	downloader.GetBlockHeadersMsg: "getBlockHeaders",
+	downloader.BlockHeadersMsg:    "blockHeaders",
+	downloader.GetBlocksMsg:       "getBlocks",
+	downloader.BlocksPreMsg:       "blocksPre",
+	downloader.BlocksMsg:          "blocks",
+	transactionHashesMsgCode:      "transactionHashes",
+	transactionsRequestMsgCode:    "transactionsRequest",
+	compactBlockMsgCode:           "compactBlock",
+	blockTxsRequestMsgCode:        "blockTxsRequest",
+	blockTxsMsgCode:               "blockTxs",
+}
+
+// describeMsg returns the summary of the seele protocol message for the p2p message tracing.
+// The payload is decoded for the common messages, and only the name is returned for others.
+func describeMsg(code uint16, payload []byte) string {
+	name, ok := msgCodeNames[code]
+	if !ok {
+		return fmt.Sprintf("unknown code %d", code)
+	}
+
+	detail, err := describeMsgPayload(code, payload)
+	if err != nil {
+		return fmt.Sprintf("%s, decoding failed: %s", name, err.Error())
+	}
+
+	if len(detail) == 0 {
+		return name
+	}
+
+	return fmt.Sprintf("%s, %s", name, detail)
+}
+
+func describeMsgPayload(code uint16, payload []byte) (string, error) {
+	switch code {
+	case transactionHashMsgCode, transactionRequestMsgCode, blockHashMsgCode, blockRequestMsgCode:
+		var hash common.Hash
+		if err := common.Deserialize(payload, &hash); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("hash:%s", hash.ToHex()), nil
+	case transactionHashesMsgCode, transactionsRequestMsgCode:
+		var hashes []common.Hash
+		if err := common.Deserialize(payload, &hashes); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("hashes:%d", len(hashes)), nil
+	case transactionsMsgCode:
+		var txs []*types.Transaction
+		if err := common.Deserialize(payload, &txs); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("txs:%d", len(txs)), nil
+	case blockMsgCode:
+		var block types.Block
+		if err := common.Deserialize(payload, &block); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("height:%d, hash:%s, txs:%d", block.Header.Height, block.HeaderHash.ToHex(), len(block.Transactions)), nil
+	case compactBlockMsgCode:
+		var compact compactBlock
+		if err := common.Deserialize(payload, &compact); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("hash:%s, txs:%d", compact.HeaderHash.ToHex(), len(compact.Prefilled)+len(compact.TxHashes)), nil
+	case blockTxsRequestMsgCode:
+		var request blockTxsRequest
+		if err := common.Deserialize(payload, &request); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("hash:%s, txs:%d", request.BlockHash.ToHex(), len(request.Indexes)), nil
+	case blockTxsMsgCode:
+		var response blockTxs
+		if err := common.Deserialize(payload, &response); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("hash:%s, txs:%d", response.BlockHash.ToHex(), len(response.Txs)), nil
+	case statusDataMsgCode:
+		var status statusData
+		if err := common.Deserialize(payload, &status); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("version:%d, network:%d, td:%s, head:%s", status.ProtocolVersion, status.NetworkID, status.TD, status.CurrentBlock.ToHex()), nil
+	case statusChainHeadMsgCode:
+		var status chainHeadStatus
+		if err := common.Deserialize(payload, &status); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("td:%s, head:%s", status.TD, status.CurrentBlock.ToHex()), nil
+	default:
+		return "", nil
+	}
+}
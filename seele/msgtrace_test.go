@@ -0,0 +1,30 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_DescribeMsg(t *testing.T) {
+	hash := common.StringToHash("test")
+	assert.Equal(t, describeMsg(blockHashMsgCode, common.SerializePanic(hash)), "blockHash, hash:"+hash.ToHex())
+
+	from, privKey, _ := crypto.GenerateKeyPair()
+	tx := types.NewTransaction(*from, common.Address{}, big.NewInt(1), big.NewInt(1), types.TransferGas, 0)
+	tx.Sign(privKey)
+
+	txs := []*types.Transaction{tx}
+	assert.Equal(t, describeMsg(transactionsMsgCode, common.SerializePanic(txs)), "transactions, txs:1")
+
+	assert.Equal(t, describeMsg(protocolMsgCodeLength, nil), "unknown code 18")
+}
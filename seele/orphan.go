@@ -0,0 +1,120 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"sync"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// orphanBlockPool caches the blocks whose parent is unknown yet, so that they
+// could be imported once the parent arrives instead of being re-downloaded.
+type orphanBlockPool struct {
+	capacity int
+	lock     sync.Mutex
+	blocks   map[common.Hash]*types.Block  // orphan block hash => block
+	children map[common.Hash][]common.Hash // parent block hash => orphan block hashes
+	order    []common.Hash                 // orphan block hashes in insertion order for eviction
+}
+
+func newOrphanBlockPool(capacity int) *orphanBlockPool {
+	return &orphanBlockPool{
+		capacity: capacity,
+		blocks:   make(map[common.Hash]*types.Block),
+		children: make(map[common.Hash][]common.Hash),
+	}
+}
+
+// add caches the orphan block, the oldest orphan block is evicted if the pool is full.
+// Returns false if the block is already cached.
+func (pool *orphanBlockPool) add(block *types.Block) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if _, ok := pool.blocks[block.HeaderHash]; ok {
+		return false
+	}
+
+	for len(pool.blocks) >= pool.capacity && len(pool.order) > 0 {
+		pool.remove(pool.order[0])
+	}
+
+	parentHash := block.Header.PreviousBlockHash
+	pool.blocks[block.HeaderHash] = block
+	pool.children[parentHash] = append(pool.children[parentHash], block.HeaderHash)
+	pool.order = append(pool.order, block.HeaderHash)
+
+	return true
+}
+
+// has indicates whether the block of the specified hash is cached.
+func (pool *orphanBlockPool) has(hash common.Hash) bool {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	_, ok := pool.blocks[hash]
+	return ok
+}
+
+// takeChildren removes and returns the cached blocks of the specified parent block.
+func (pool *orphanBlockPool) takeChildren(parentHash common.Hash) []*types.Block {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	var blocks []*types.Block
+	for _, hash := range pool.children[parentHash] {
+		if block := pool.blocks[hash]; block != nil {
+			blocks = append(blocks, block)
+			pool.remove(hash)
+		}
+	}
+
+	delete(pool.children, parentHash)
+
+	return blocks
+}
+
+// remove removes the orphan block of the specified hash, must be called with the lock held.
+func (pool *orphanBlockPool) remove(hash common.Hash) {
+	block := pool.blocks[hash]
+	if block == nil {
+		return
+	}
+
+	delete(pool.blocks, hash)
+
+	parentHash := block.Header.PreviousBlockHash
+	siblings := pool.children[parentHash]
+	for i, h := range siblings {
+		if h.Equal(hash) {
+			siblings = append(siblings[:i], siblings[i+1:]...)
+			break
+		}
+	}
+
+	if len(siblings) == 0 {
+		delete(pool.children, parentHash)
+	} else {
+		pool.children[parentHash] = siblings
+	}
+
+	for i, h := range pool.order {
+		if h.Equal(hash) {
+			pool.order = append(pool.order[:i], pool.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// len returns the number of cached orphan blocks.
+func (pool *orphanBlockPool) len() int {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	return len(pool.blocks)
+}
@@ -0,0 +1,53 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func newTestOrphanBlock(hash, parentHash string) *types.Block {
+	return &types.Block{
+		HeaderHash: common.StringToHash(hash),
+		Header:     &types.BlockHeader{PreviousBlockHash: common.StringToHash(parentHash)},
+	}
+}
+
+func Test_OrphanBlockPool(t *testing.T) {
+	pool := newOrphanBlockPool(3)
+
+	assert.Equal(t, pool.add(newTestOrphanBlock("b1", "a")), true)
+	assert.Equal(t, pool.add(newTestOrphanBlock("b1", "a")), false)
+	assert.Equal(t, pool.add(newTestOrphanBlock("b2", "a")), true)
+	assert.Equal(t, pool.add(newTestOrphanBlock("c1", "b1")), true)
+	assert.Equal(t, pool.has(common.StringToHash("b1")), true)
+
+	children := pool.takeChildren(common.StringToHash("a"))
+	assert.Equal(t, len(children), 2)
+	assert.Equal(t, pool.len(), 1)
+
+	children = pool.takeChildren(common.StringToHash("b1"))
+	assert.Equal(t, len(children), 1)
+	assert.Equal(t, pool.len(), 0)
+	assert.Equal(t, len(pool.children), 0)
+	assert.Equal(t, len(pool.order), 0)
+}
+
+func Test_OrphanBlockPool_Evict(t *testing.T) {
+	pool := newOrphanBlockPool(2)
+
+	pool.add(newTestOrphanBlock("b1", "a"))
+	pool.add(newTestOrphanBlock("b2", "a"))
+	pool.add(newTestOrphanBlock("b3", "a"))
+
+	assert.Equal(t, pool.len(), 2)
+	assert.Equal(t, pool.has(common.StringToHash("b1")), false)
+	assert.Equal(t, pool.has(common.StringToHash("b3")), true)
+}
@@ -52,6 +52,8 @@ type peer struct {
 
 	knownTxs    *set.Set // Set of transaction hashes known by this peer
 	knownBlocks *set.Set // Set of block hashes known by this peer
+
+	txGossip *txGossipQueue // batched tx announcements to this peer
 }
 
 func newPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
@@ -63,6 +65,7 @@ func newPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 		peerStrID:   fmt.Sprintf("%x", p.Node.ID[:8]),
 		knownTxs:    set.New(),
 		knownBlocks: set.New(),
+		txGossip:    newTxGossipQueue(),
 		rw:          rw,
 	}
 }
@@ -100,6 +103,23 @@ func (p *peer) sendTransactionHash(txHash common.Hash) error {
 	return err
 }
 
+// sendTransactionHashes announces a batch of tx hashes to the peer.
+func (p *peer) sendTransactionHashes(txHashes []common.Hash) error {
+	err := p2p.SendMessage(p.rw, transactionHashesMsgCode, common.SerializePanic(txHashes))
+	if err == nil {
+		for _, hash := range txHashes {
+			p.markTransaction(hash)
+		}
+	}
+
+	return err
+}
+
+// sendTransactionsRequest requests a batch of txs from the peer.
+func (p *peer) sendTransactionsRequest(txHashes []common.Hash) error {
+	return p2p.SendMessage(p.rw, transactionsRequestMsgCode, common.SerializePanic(txHashes))
+}
+
 func (p *peer) sendTransactionRequest(txHash common.Hash) error {
 	return p2p.SendMessage(p.rw, transactionRequestMsgCode, common.SerializePanic(txHash))
 }
@@ -125,6 +145,29 @@ func (p *peer) SendBlockRequest(blockHash common.Hash) error {
 	return p2p.SendMessage(p.rw, blockRequestMsgCode, common.SerializePanic(blockHash))
 }
 
+// sendCompactBlock relays the block with the tx hashes instead of the txs.
+func (p *peer) sendCompactBlock(compact *compactBlock) error {
+	if p.knownBlocks.Has(compact.HeaderHash) {
+		return nil
+	}
+
+	err := p2p.SendMessage(p.rw, compactBlockMsgCode, common.SerializePanic(compact))
+	if err == nil {
+		p.knownBlocks.Add(compact.HeaderHash)
+	}
+
+	return err
+}
+
+// sendBlockTxsRequest requests the missing txs of the compact block from the peer.
+func (p *peer) sendBlockTxsRequest(request *blockTxsRequest) error {
+	return p2p.SendMessage(p.rw, blockTxsRequestMsgCode, common.SerializePanic(request))
+}
+
+func (p *peer) sendBlockTxs(response *blockTxs) error {
+	return p2p.SendMessage(p.rw, blockTxsMsgCode, common.SerializePanic(response))
+}
+
 func (p *peer) sendTransactions(txs []*types.Transaction) error {
 	return p2p.SendMessage(p.rw, transactionsMsgCode, common.SerializePanic(txs))
 }
@@ -6,7 +6,6 @@
 package seele
 
 import (
-	"math/big"
 	"sync"
 
 	"github.com/seeleteam/go-seele/common"
@@ -28,22 +27,6 @@ func newPeerSet() *peerSet {
 	}
 }
 
-func (p *peerSet) bestPeer() *peer {
-	p.lock.RLock()
-	defer p.lock.RUnlock()
-	var (
-		bestPeer *peer
-		bestTd   *big.Int
-	)
-	for _, p := range p.peers {
-		if _, td := p.Head(); bestPeer == nil || td.Cmp(bestTd) > 0 {
-			bestPeer, bestTd = p, td
-		}
-	}
-
-	return bestPeer
-}
-
 func (p *peerSet) Find(address common.Address) *peer {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
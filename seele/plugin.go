@@ -0,0 +1,74 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"fmt"
+	"plugin"
+
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/miner"
+)
+
+const (
+	// executionPluginSymbol is the exported variable of the Go plugin that implements core.ExecutionObserver.
+	executionPluginSymbol = "Observer"
+
+	// minerEnginePluginSymbol is the exported variable of the Go plugin that implements miner.Engine.
+	minerEnginePluginSymbol = "Engine"
+)
+
+// loadExecutionPlugins opens the specified Go plugins (built with -buildmode=plugin)
+// and returns their execution observers.
+func loadExecutionPlugins(paths []string) ([]core.ExecutionObserver, error) {
+	var observers []core.ExecutionObserver
+
+	for _, path := range paths {
+		p, err := plugin.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		symbol, err := p.Lookup(executionPluginSymbol)
+		if err != nil {
+			return nil, err
+		}
+
+		switch observer := symbol.(type) {
+		case *core.ExecutionObserver:
+			observers = append(observers, *observer)
+		case core.ExecutionObserver:
+			observers = append(observers, observer)
+		default:
+			return nil, fmt.Errorf("symbol %s of plugin %s does not implement core.ExecutionObserver", executionPluginSymbol, path)
+		}
+	}
+
+	return observers, nil
+}
+
+// loadMinerEngine opens the specified Go plugin (built with -buildmode=plugin) and returns
+// its miner engine, e.g. to seal the blocks with the GPU or remote workers.
+func loadMinerEngine(path string) (miner.Engine, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := p.Lookup(minerEnginePluginSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	switch engine := symbol.(type) {
+	case *miner.Engine:
+		return *engine, nil
+	case miner.Engine:
+		return engine, nil
+	default:
+		return nil, fmt.Errorf("symbol %s of plugin %s does not implement miner.Engine", minerEnginePluginSymbol, path)
+	}
+}
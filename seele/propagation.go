@@ -0,0 +1,126 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+const (
+	// maxPropagationSamples is the number of recent blocks to calculate the latency distribution.
+	maxPropagationSamples = 256
+
+	// blockSeenRetention is the duration to keep the first-seen time of blocks that are never received.
+	blockSeenRetention = 10 * time.Minute
+)
+
+// PropagationStats is the latency distribution of the recent blocks from being first seen,
+// i.e. announced by any peer, to being fully received, and the counters of compact blocks.
+type PropagationStats struct {
+	Samples       int           // Samples is the number of recent received blocks
+	P50           time.Duration // P50 is the median latency
+	P90           time.Duration // P90 is the 90th percentile latency
+	P99           time.Duration // P99 is the 99th percentile latency
+	Max           time.Duration // Max is the maximum latency
+	Compact       uint64        // Compact is the number of compact blocks received
+	Reconstructed uint64        // Reconstructed is the number of compact blocks reconstructed from the tx pool only
+	MissingTxs    uint64        // MissingTxs is the number of txs requested for the compact blocks
+}
+
+// propagationTracker tracks the first-seen time of blocks and the latency once they are fully received.
+type propagationTracker struct {
+	lock      sync.Mutex
+	seen      map[common.Hash]time.Time
+	latencies []time.Duration // ring buffer of the recent latencies
+	next      int             // next index of the ring buffer to write
+	counters  PropagationStats
+}
+
+func newPropagationTracker() *propagationTracker {
+	return &propagationTracker{
+		seen: make(map[common.Hash]time.Time),
+	}
+}
+
+// see records the time when the block is first seen.
+func (tracker *propagationTracker) see(hash common.Hash, now time.Time) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	if _, ok := tracker.seen[hash]; ok {
+		return
+	}
+
+	for seenHash, seen := range tracker.seen {
+		if now.Sub(seen) > blockSeenRetention {
+			delete(tracker.seen, seenHash)
+		}
+	}
+
+	tracker.seen[hash] = now
+}
+
+// receive records the latency of the block if it is seen before.
+func (tracker *propagationTracker) receive(hash common.Hash, now time.Time) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	seen, ok := tracker.seen[hash]
+	if !ok {
+		return
+	}
+
+	delete(tracker.seen, hash)
+
+	latency := now.Sub(seen)
+	if len(tracker.latencies) < maxPropagationSamples {
+		tracker.latencies = append(tracker.latencies, latency)
+	} else {
+		tracker.latencies[tracker.next] = latency
+	}
+
+	tracker.next = (tracker.next + 1) % maxPropagationSamples
+}
+
+// compact counts the compact block, and the number of its txs missing in the tx pool.
+func (tracker *propagationTracker) compact(missingTxs int) {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+
+	tracker.counters.Compact++
+	if missingTxs == 0 {
+		tracker.counters.Reconstructed++
+	}
+
+	tracker.counters.MissingTxs += uint64(missingTxs)
+}
+
+func (tracker *propagationTracker) stats() PropagationStats {
+	tracker.lock.Lock()
+	latencies := append([]time.Duration{}, tracker.latencies...)
+	stats := tracker.counters
+	tracker.lock.Unlock()
+
+	stats.Samples = len(latencies)
+	if len(latencies) == 0 {
+		return stats
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+
+	stats.P50, stats.P90, stats.P99 = percentile(50), percentile(90), percentile(99)
+	stats.Max = latencies[len(latencies)-1]
+
+	return stats
+}
@@ -0,0 +1,52 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+)
+
+func Test_PropagationTracker(t *testing.T) {
+	tracker := newPropagationTracker()
+	now := time.Now()
+
+	for i := 0; i < 10; i++ {
+		hash := common.BigToHash(big.NewInt(int64(i + 1)))
+		tracker.see(hash, now)
+		tracker.see(hash, now.Add(time.Second)) // first seen only
+		tracker.receive(hash, now.Add(time.Duration(i+1)*time.Second))
+		tracker.receive(hash, now.Add(time.Hour)) // received already
+	}
+
+	// never seen
+	tracker.receive(common.StringToHash("unknown"), now)
+
+	tracker.compact(0)
+	tracker.compact(2)
+
+	stats := tracker.stats()
+	assert.Equal(t, stats.Samples, 10)
+	assert.Equal(t, stats.P50, 5*time.Second)
+	assert.Equal(t, stats.P90, 9*time.Second)
+	assert.Equal(t, stats.Max, 10*time.Second)
+	assert.Equal(t, stats.Compact, uint64(2))
+	assert.Equal(t, stats.Reconstructed, uint64(1))
+	assert.Equal(t, stats.MissingTxs, uint64(2))
+}
+
+func Test_PropagationTracker_Retention(t *testing.T) {
+	tracker := newPropagationTracker()
+	now := time.Now()
+
+	tracker.see(common.StringToHash("b1"), now)
+	tracker.see(common.StringToHash("b2"), now.Add(blockSeenRetention+time.Second))
+	assert.Equal(t, len(tracker.seen), 1)
+}
@@ -0,0 +1,83 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"encoding/json"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/rpc"
+)
+
+// cacheChain is the canonical chain of the cached rpc responses.
+type cacheChain struct {
+	chain *core.Blockchain
+}
+
+func (c cacheChain) HeadHeight() uint64 {
+	head, _ := c.chain.CurrentBlock()
+	return head.Header.Height
+}
+
+func (c cacheChain) CanonicalHash(height uint64) (common.Hash, error) {
+	return c.chain.GetStore().GetBlockHash(height)
+}
+
+// ResponseCacheChain implements node.CacheService, returning the chain of the cached rpc responses.
+func (s *SeeleService) ResponseCacheChain() rpc.CacheChain {
+	return cacheChain{s.chain}
+}
+
+// decodeParams decodes the raw params of the JSON request, which is an array of the argument.
+func decodeParams(params json.RawMessage, arg interface{}) bool {
+	args := [1]interface{}{arg}
+	return json.Unmarshal(params, &args) == nil
+}
+
+// cacheHeightOfRequest returns the height of the blocks requested by height, in which the HEAD block
+// requested by -1 is not cached.
+func cacheHeightOfRequest(params json.RawMessage, result interface{}) (uint64, bool) {
+	var height int64
+	if !decodeParams(params, &height) || height < 0 {
+		return 0, false
+	}
+
+	return uint64(height), true
+}
+
+// cacheHeightOfBlock returns the height of the block output, in which the full txs are not cached
+// since they carry the local labels that could change.
+func cacheHeightOfBlock(params json.RawMessage, result interface{}) (uint64, bool) {
+	var request struct{ FullTx bool }
+	if !decodeParams(params, &request) || request.FullTx {
+		return 0, false
+	}
+
+	block, ok := result.(*map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+
+	height, ok := (*block)["height"].(uint64)
+	return height, ok
+}
+
+// cacheHeightOfReceiptProof returns the height of the block of the receipt proof.
+func (s *SeeleService) cacheHeightOfReceiptProof(params json.RawMessage, result interface{}) (uint64, bool) {
+	proof, ok := result.(*types.ReceiptProof)
+	if !ok {
+		return 0, false
+	}
+
+	header, err := s.chain.GetStore().GetBlockHeader(proof.BlockHash)
+	if err != nil {
+		return 0, false
+	}
+
+	return header.Height, true
+}
@@ -0,0 +1,147 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// errAllowanceInvalid is returned when granting a non-positive spending allowance.
+var errAllowanceInvalid = errors.New("allowance should be positive")
+
+// PrivateSchedulerAPI provides an API to manage the scheduled txs and the spending limits of the node,
+// which is only served on the admin rpc listener.
+type PrivateSchedulerAPI struct {
+	s *Scheduler
+}
+
+// NewPrivateSchedulerAPI creates a new PrivateSchedulerAPI object for rpc service.
+func NewPrivateSchedulerAPI(s *Scheduler) *PrivateSchedulerAPI {
+	return &PrivateSchedulerAPI{s}
+}
+
+// AddTemplate adds the tx template, and returns the assigned template id.
+func (api *PrivateSchedulerAPI) AddTemplate(template *Template, result *uint64) error {
+	id, err := api.s.AddTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	*result = id
+	return nil
+}
+
+// RemoveTemplate removes the template of the specified id.
+func (api *PrivateSchedulerAPI) RemoveTemplate(id *uint64, result *bool) error {
+	if err := api.s.RemoveTemplate(*id); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// EnableTemplateRequest request param for EnableTemplate api
+type EnableTemplateRequest struct {
+	ID      uint64
+	Enabled bool
+}
+
+// EnableTemplate enables or disables the schedule of the template.
+func (api *PrivateSchedulerAPI) EnableTemplate(request *EnableTemplateRequest, result *bool) error {
+	if err := api.s.EnableTemplate(request.ID, request.Enabled); err != nil {
+		return err
+	}
+
+	*result = true
+	return nil
+}
+
+// GetTemplates returns all templates.
+func (api *PrivateSchedulerAPI) GetTemplates(input interface{}, result *[]*Template) error {
+	*result = api.s.Templates()
+	return nil
+}
+
+// GetSubmissions returns the audit log of the submissions of the specified template.
+func (api *PrivateSchedulerAPI) GetSubmissions(id *uint64, result *[]*Submission) error {
+	submissions, err := api.s.Submissions(*id)
+	if err != nil {
+		return err
+	}
+
+	*result = submissions
+	return nil
+}
+
+// UnlockAccount unlocks the account of the specified private key in hex to sign the
+// scheduled txs, and returns the account address. The key is only kept in memory, and
+// rejected if the keys are held by the signer process.
+func (api *PrivateSchedulerAPI) UnlockAccount(privKey *string, result *common.Address) error {
+	if len(api.s.DefaultSigner()) > 0 {
+		return ErrKeysSeparated
+	}
+
+	key, err := crypto.LoadECDSAFromString(*privKey)
+	if err != nil {
+		return err
+	}
+
+	addr, err := api.s.Accounts().Unlock(key)
+	if err != nil {
+		return err
+	}
+
+	*result = addr
+	return nil
+}
+
+// LockAccount locks the account, and returns false if the account is not unlocked.
+func (api *PrivateSchedulerAPI) LockAccount(addr *common.Address, result *bool) error {
+	*result = api.s.Accounts().Lock(*addr)
+	return nil
+}
+
+// SpendingLimitRequest request param for SetSpendingLimit and GrantSpendingAllowance api
+type SpendingLimitRequest struct {
+	Address common.Address
+	Amount  *big.Int
+}
+
+// SetSpendingLimit sets the maximum value of the txs signed for the address per window,
+// and the limit is removed if Amount is nil.
+func (api *PrivateSchedulerAPI) SetSpendingLimit(request *SpendingLimitRequest, result *bool) error {
+	api.s.SpendingPolicy().SetLimit(request.Address, request.Amount)
+	api.s.log.Warn("spending limit of %s set to %v by admin", request.Address.ToHex(), request.Amount)
+
+	*result = true
+	return nil
+}
+
+// GrantSpendingAllowance grants the one-off allowance for the address to sign beyond its limit,
+// e.g. to approve a large withdrawal.
+func (api *PrivateSchedulerAPI) GrantSpendingAllowance(request *SpendingLimitRequest, result *bool) error {
+	if request.Amount == nil || request.Amount.Sign() <= 0 {
+		return errAllowanceInvalid
+	}
+
+	api.s.SpendingPolicy().Grant(request.Address, request.Amount)
+	api.s.log.Warn("spending allowance %v granted to %s by admin", request.Amount, request.Address.ToHex())
+
+	*result = true
+	return nil
+}
+
+// GetSpending returns the spending of the address within the current window.
+func (api *PrivateSchedulerAPI) GetSpending(addr *common.Address, result *SpendingStatus) error {
+	*result = *api.s.SpendingPolicy().Status(*addr, time.Now())
+	return nil
+}
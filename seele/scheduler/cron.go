@@ -0,0 +1,153 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxScheduleYears bounds the search of the next run time, e.g. for "0 0 30 2 *" that never runs.
+const maxScheduleYears = 5
+
+// ErrInvalidSchedule is returned when the cron-style schedule could not be parsed.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// fieldBounds are the min and max values of the schedule fields.
+var fieldBounds = [5][2]uint{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 6},  // day of week, 0 is Sunday
+}
+
+// Schedule is a cron-style schedule of 5 fields separated by spaces: minute, hour,
+// day of month, month and day of week. Each field is "*", a number, a range "a-b",
+// a step "*/n" or "a-b/n", or a list of them separated by ",", e.g. "0 12 * * 1"
+// runs at 12:00 every Monday. As in cron, if both the day of month and day of week
+// are restricted, the schedule runs on the days matching either of them.
+type Schedule struct {
+	fields [5]uint64 // bit sets of the matched values of each field
+	anyDom bool      // anyDom indicates the day of month is "*"
+	anyDow bool      // anyDow indicates the day of week is "*"
+}
+
+// ParseSchedule parses the cron-style schedule.
+func ParseSchedule(spec string) (*Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(fieldBounds) {
+		return nil, ErrInvalidSchedule
+	}
+
+	schedule := &Schedule{
+		anyDom: parts[2] == "*",
+		anyDow: parts[4] == "*",
+	}
+
+	for i, part := range parts {
+		bits, err := parseField(part, fieldBounds[i][0], fieldBounds[i][1])
+		if err != nil {
+			return nil, err
+		}
+
+		schedule.fields[i] = bits
+	}
+
+	return schedule, nil
+}
+
+// parseField parses a schedule field into the bit set of the matched values.
+func parseField(field string, min, max uint) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		step := uint(1)
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.ParseUint(item[i+1:], 10, 8)
+			if err != nil || n == 0 {
+				return 0, ErrInvalidSchedule
+			}
+
+			step, item = uint(n), item[:i]
+		}
+
+		from, to := min, max
+		if item != "*" {
+			bounds := strings.SplitN(item, "-", 2)
+			n, err := strconv.ParseUint(bounds[0], 10, 8)
+			if err != nil {
+				return 0, ErrInvalidSchedule
+			}
+
+			from, to = uint(n), uint(n)
+			if len(bounds) == 2 {
+				if n, err = strconv.ParseUint(bounds[1], 10, 8); err != nil {
+					return 0, ErrInvalidSchedule
+				}
+
+				to = uint(n)
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, ErrInvalidSchedule
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+func (schedule *Schedule) match(field int, value int) bool {
+	return schedule.fields[field]&(1<<uint(value)) != 0
+}
+
+func (schedule *Schedule) matchDay(t time.Time) bool {
+	dom, dow := schedule.match(2, t.Day()), schedule.match(4, int(t.Weekday()))
+	if schedule.anyDom || schedule.anyDow {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// Next returns the first run time of the schedule later than the specified time, in the
+// location of the specified time. It returns the zero time if the schedule never runs.
+func (schedule *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxScheduleYears, 0, 0)
+
+	for t.Before(limit) {
+		if !schedule.match(3, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !schedule.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !schedule.match(1, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !schedule.match(0, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,49 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+)
+
+func Test_ParseSchedule_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 7", "5-1 * * * *", "*/0 * * * *", "a * * * *", "1-2-3 * * * *", "1, * * * *"} {
+		_, err := ParseSchedule(spec)
+		assert.Equal(t, err, ErrInvalidSchedule, spec)
+	}
+}
+
+func Test_Schedule_Next(t *testing.T) {
+	// Friday
+	after := time.Date(2026, 10, 16, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2026, 10, 16, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 10, 15, 0, 0, time.UTC)},
+		{"7 10 * * *", time.Date(2026, 10, 17, 10, 7, 0, 0, time.UTC)},
+		{"0 12 * * 1", time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)},
+		{"30 8,20 * * *", time.Date(2026, 10, 16, 20, 30, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or day of week matches
+		{"0 0 1 * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.spec)
+		assert.Equal(t, err, error(nil), c.spec)
+		assert.Equal(t, schedule.Next(after), c.next, c.spec)
+	}
+}
@@ -0,0 +1,442 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/log"
+)
+
+const (
+	// tickInterval is the interval to check the due templates.
+	tickInterval = 10 * time.Second
+
+	// MaxSubmissions is the maximum number of submissions kept in the audit log of a
+	// template, and the oldest submissions are dropped.
+	MaxSubmissions = 1000
+)
+
+var (
+	keyTemplates         = []byte("schedTemplates")
+	keyNextTemplateID    = []byte("schedNextID")
+	keyPrefixSubmissions = []byte("schedLog")
+
+	// ErrTemplateNotFound is returned when the template id does not exist.
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrInvalidTemplate is returned when the amount, gas price or gas limit of the template is invalid.
+	ErrInvalidTemplate = errors.New("invalid template")
+)
+
+type blockchain interface {
+	CurrentBlock() (*types.Block, *state.Statedb)
+	ChainConfig() *types.ChainConfig
+}
+
+type txPool interface {
+	AddTransaction(tx *types.Transaction) error
+}
+
+// Template is the tx template submitted by the scheduler at each run of the schedule.
+type Template struct {
+	ID       uint64         // ID is assigned when the template is added
+	Name     string         // Name is the description of the template, e.g. weekly treasury distribution
+	Schedule string         // Schedule is the cron-style schedule, see Schedule
+	From     common.Address // From is the sender of the txs
+	To       common.Address // To is the receiver of the txs
+	Amount   *big.Int       // Amount is the amount of each tx
+	GasPrice *big.Int       // GasPrice is the gas price of each tx
+	GasLimit uint64         // GasLimit is the gas limit of each tx
+	Payload  []byte         // Payload is the message of each tx, empty for transfer
+	Signer   string         // Signer is the address of the external signer, empty to sign with the unlocked sender
+	Enabled  bool           // Enabled indicates whether the template is scheduled
+	Created  uint64         // Created is the unix time when the template is added
+	LastRun  uint64         // LastRun is the unix time of the last run, 0 if never run
+}
+
+// Submission is the audit record of a tx submitted for a template.
+type Submission struct {
+	TemplateID uint64      // TemplateID is the id of the template
+	Time       uint64      // Time is the unix time of the submission
+	Nonce      uint64      // Nonce is the nonce of the tx
+	TxHash     common.Hash // TxHash is the hash of the tx, empty if failed to sign
+	Error      string      // Error is the failure to sign or add the tx into the pool, empty if succeeded
+}
+
+// Scheduler submits the txs of the templates in their schedules, e.g. the recurring payouts.
+// The templates and their audit logs are persisted in database. There are following mappings:
+//  1. keyTemplates => templates
+//  2. keyNextTemplateID => next template id
+//  3. keyPrefixSubmissions + template id => submissions of the template
+type Scheduler struct {
+	db       database.Database
+	chain    blockchain
+	pool     txPool
+	accounts *Accounts
+	spending *SpendingPolicy
+	scheme   types.SigHashScheme
+	log      *log.SeeleLog
+
+	defaultSigner       string // address of the external signer of the templates without Signer, empty to sign with the unlocked sender
+	defaultSignerSecret string // secret shared with the default external signer
+
+	lock      sync.Mutex // protects the fields below
+	templates []*Template
+	nonces    map[common.Address]uint64 // next nonces of the senders, which may be ahead of the state
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New creates a scheduler which signs the txs for the specified chain id, and loads the templates from the database.
+func New(db database.Database, chain blockchain, pool txPool, chainID uint64, log *log.SeeleLog) (*Scheduler, error) {
+	scheduler := &Scheduler{
+		db:       db,
+		chain:    chain,
+		pool:     pool,
+		accounts: NewAccounts(),
+		spending: NewSpendingPolicy(DefaultSpendingWindow, nil),
+		scheme:   types.SigHashScheme{Version: types.LatestSigHashVersion, ChainID: chainID},
+		log:      log,
+		nonces:   make(map[common.Address]uint64),
+	}
+
+	if err := scheduler.getValue(keyTemplates, &scheduler.templates); err != nil {
+		return nil, err
+	}
+
+	return scheduler, nil
+}
+
+// SetDefaultSigner sets the external signer of the templates without Signer, e.g. the signer
+// process holding the private keys out of the node process, and the secret shared with it.
+// It should be set before Start.
+func (scheduler *Scheduler) SetDefaultSigner(address string, secret string) {
+	scheduler.defaultSigner = address
+	scheduler.defaultSignerSecret = secret
+}
+
+// DefaultSigner returns the external signer of the templates without Signer, empty if not set.
+func (scheduler *Scheduler) DefaultSigner() string {
+	return scheduler.defaultSigner
+}
+
+// SetSpendingPolicy sets the spending limits of the senders of the signed txs. It should be set before Start.
+func (scheduler *Scheduler) SetSpendingPolicy(policy *SpendingPolicy) {
+	scheduler.spending = policy
+}
+
+// SpendingPolicy returns the spending limits of the senders of the signed txs.
+func (scheduler *Scheduler) SpendingPolicy() *SpendingPolicy {
+	return scheduler.spending
+}
+
+// Accounts returns the unlocked accounts to sign the txs.
+func (scheduler *Scheduler) Accounts() *Accounts {
+	return scheduler.accounts
+}
+
+// Start starts to submit the txs of the due templates in background.
+func (scheduler *Scheduler) Start() {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	if scheduler.quit != nil {
+		return
+	}
+
+	scheduler.quit = make(chan struct{})
+	scheduler.wg.Add(1)
+	go scheduler.loop(scheduler.quit)
+}
+
+// Stop stops to submit the txs.
+func (scheduler *Scheduler) Stop() {
+	scheduler.lock.Lock()
+	quit := scheduler.quit
+	scheduler.quit = nil
+	scheduler.lock.Unlock()
+
+	if quit != nil {
+		close(quit)
+		scheduler.wg.Wait()
+	}
+}
+
+func (scheduler *Scheduler) loop(quit chan struct{}) {
+	defer scheduler.wg.Done()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			scheduler.run(now)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// AddTemplate validates and adds the template, and returns the assigned template id.
+func (scheduler *Scheduler) AddTemplate(template *Template) (uint64, error) {
+	if _, err := ParseSchedule(template.Schedule); err != nil {
+		return 0, err
+	}
+
+	if template.Amount == nil || template.Amount.Sign() < 0 || template.GasPrice == nil ||
+		template.GasPrice.Sign() < 0 || template.GasLimit < types.TransferGas {
+		return 0, ErrInvalidTemplate
+	}
+
+	if len(template.Payload) > 0 {
+		if _, err := types.NewMessageTransaction(scheduler.chain.ChainConfig(), template.From, template.To,
+			template.Amount, template.GasPrice, template.GasLimit, 0, template.Payload); err != nil {
+			return 0, err
+		}
+	}
+
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	var id uint64
+	if err := scheduler.getValue(keyNextTemplateID, &id); err != nil {
+		return 0, err
+	}
+
+	added := *template
+	added.ID = id
+	added.Created = uint64(time.Now().Unix())
+	added.LastRun = 0
+
+	batch := scheduler.db.NewBatch()
+	batch.Put(keyNextTemplateID, common.SerializePanic(id+1))
+	batch.Put(keyTemplates, common.SerializePanic(append(scheduler.templates, &added)))
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	scheduler.templates = append(scheduler.templates, &added)
+	return id, nil
+}
+
+// RemoveTemplate removes the template, while its audit log is kept.
+func (scheduler *Scheduler) RemoveTemplate(id uint64) error {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	for i, template := range scheduler.templates {
+		if template.ID == id {
+			templates := append(append([]*Template{}, scheduler.templates[:i]...), scheduler.templates[i+1:]...)
+			return scheduler.putTemplates(templates)
+		}
+	}
+
+	return ErrTemplateNotFound
+}
+
+// EnableTemplate enables or disables the schedule of the template.
+func (scheduler *Scheduler) EnableTemplate(id uint64, enabled bool) error {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	for i, template := range scheduler.templates {
+		if template.ID == id {
+			updated := *template
+			updated.Enabled = enabled
+
+			templates := append([]*Template{}, scheduler.templates...)
+			templates[i] = &updated
+			return scheduler.putTemplates(templates)
+		}
+	}
+
+	return ErrTemplateNotFound
+}
+
+// Templates returns the copies of all templates.
+func (scheduler *Scheduler) Templates() []*Template {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	templates := make([]*Template, len(scheduler.templates))
+	for i, template := range scheduler.templates {
+		copied := *template
+		templates[i] = &copied
+	}
+
+	return templates
+}
+
+// Submissions returns the audit log of the specified template in time ASC order.
+func (scheduler *Scheduler) Submissions(id uint64) ([]*Submission, error) {
+	var submissions []*Submission
+	err := scheduler.getValue(submissionsKey(id), &submissions)
+	return submissions, err
+}
+
+// run submits the txs of the enabled templates that are due at the specified time.
+func (scheduler *Scheduler) run(now time.Time) {
+	scheduler.lock.Lock()
+	defer scheduler.lock.Unlock()
+
+	templates := append([]*Template{}, scheduler.templates...)
+	changed := false
+
+	for i, template := range templates {
+		if !template.Enabled {
+			continue
+		}
+
+		schedule, err := ParseSchedule(template.Schedule)
+		if err != nil {
+			continue
+		}
+
+		// the missed runs while the node is down are submitted only once
+		last := template.LastRun
+		if last == 0 {
+			last = template.Created
+		}
+
+		if next := schedule.Next(time.Unix(int64(last), 0)); next.IsZero() || next.After(now) {
+			continue
+		}
+
+		submission := scheduler.submit(template, now)
+		if err := scheduler.appendSubmission(submission); err != nil {
+			scheduler.log.Warn("failed to write the submission of template %d, %s", template.ID, err)
+		}
+
+		updated := *template
+		updated.LastRun = uint64(now.Unix())
+		templates[i] = &updated
+		changed = true
+	}
+
+	if changed {
+		if err := scheduler.putTemplates(templates); err != nil {
+			scheduler.log.Warn("failed to update the templates, %s", err)
+		}
+	}
+}
+
+// submit signs the tx of the template and adds it into the pool.
+func (scheduler *Scheduler) submit(template *Template, now time.Time) *Submission {
+	_, statedb := scheduler.chain.CurrentBlock()
+	nonce := statedb.GetNonce(template.From)
+	if next := scheduler.nonces[template.From]; next > nonce {
+		nonce = next
+	}
+
+	submission := &Submission{
+		TemplateID: template.ID,
+		Time:       uint64(now.Unix()),
+		Nonce:      nonce,
+	}
+
+	tx, err := scheduler.signTx(template, nonce, now)
+	if err == nil {
+		submission.TxHash = tx.Hash
+		err = scheduler.pool.AddTransaction(tx)
+	}
+
+	if err != nil {
+		scheduler.log.Warn("failed to submit the tx of template %d, %s", template.ID, err)
+		submission.Error = err.Error()
+		return submission
+	}
+
+	scheduler.nonces[template.From] = nonce + 1
+	scheduler.log.Info("submitted tx %s of template %d", tx.Hash.ToHex(), template.ID)
+
+	return submission
+}
+
+// signTx signs the tx of the template, which is refused if beyond the spending limit of the sender.
+func (scheduler *Scheduler) signTx(template *Template, nonce uint64, now time.Time) (*types.Transaction, error) {
+	var tx *types.Transaction
+	if len(template.Payload) == 0 {
+		tx = types.NewTransaction(template.From, template.To, template.Amount, template.GasPrice, template.GasLimit, nonce)
+	} else {
+		var err error
+		if tx, err = types.NewMessageTransaction(scheduler.chain.ChainConfig(), template.From, template.To,
+			template.Amount, template.GasPrice, template.GasLimit, nonce, template.Payload); err != nil {
+			return nil, err
+		}
+	}
+
+	var signer Signer = scheduler.accounts
+	if len(template.Signer) > 0 {
+		signer = &externalSigner{template.Signer, ""}
+	} else if len(scheduler.defaultSigner) > 0 {
+		signer = &externalSigner{scheduler.defaultSigner, scheduler.defaultSignerSecret}
+	}
+
+	value := new(big.Int).Add(tx.Data.Amount, tx.MaxFee())
+	if err := scheduler.spending.spend(tx.Data.From, value, now); err != nil {
+		return nil, err
+	}
+
+	signed, err := signer.SignTx(tx, scheduler.scheme)
+	if err != nil {
+		scheduler.spending.refund(tx.Data.From, now)
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+func (scheduler *Scheduler) appendSubmission(submission *Submission) error {
+	submissions, err := scheduler.Submissions(submission.TemplateID)
+	if err != nil {
+		return err
+	}
+
+	if submissions = append(submissions, submission); len(submissions) > MaxSubmissions {
+		submissions = submissions[len(submissions)-MaxSubmissions:]
+	}
+
+	return scheduler.db.Put(submissionsKey(submission.TemplateID), common.SerializePanic(submissions))
+}
+
+func (scheduler *Scheduler) putTemplates(templates []*Template) error {
+	if err := scheduler.db.Put(keyTemplates, common.SerializePanic(templates)); err != nil {
+		return err
+	}
+
+	scheduler.templates = templates
+	return nil
+}
+
+func (scheduler *Scheduler) getValue(key []byte, value interface{}) error {
+	exist, err := scheduler.db.Has(key)
+	if err != nil || !exist {
+		return err
+	}
+
+	encoded, err := scheduler.db.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return common.Deserialize(encoded, value)
+}
+
+func submissionsKey(id uint64) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, id)
+	return append(append([]byte{}, keyPrefixSubmissions...), encoded...)
+}
@@ -0,0 +1,215 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+	"github.com/seeleteam/go-seele/log"
+)
+
+type mockChain struct {
+	statedb *state.Statedb
+}
+
+func (chain *mockChain) CurrentBlock() (*types.Block, *state.Statedb) {
+	return nil, chain.statedb
+}
+
+func (chain *mockChain) ChainConfig() *types.ChainConfig {
+	return types.DefaultChainConfig()
+}
+
+type mockPool struct {
+	txs []*types.Transaction
+	err error
+}
+
+func (pool *mockPool) AddTransaction(tx *types.Transaction) error {
+	if pool.err != nil {
+		return pool.err
+	}
+
+	pool.txs = append(pool.txs, tx)
+	return nil
+}
+
+func newTestScheduler(t *testing.T) (*Scheduler, *mockPool, database.Database, func()) {
+	dir, err := ioutil.TempDir("", "scheduler")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	dispose := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	statedb, err := state.NewStatedb(common.EmptyHash, db)
+	if err != nil {
+		dispose()
+		t.Fatal(err)
+	}
+
+	pool := &mockPool{}
+	scheduler, err := New(db, &mockChain{statedb}, pool, 1, log.GetLogger("scheduler", true))
+	if err != nil {
+		dispose()
+		t.Fatal(err)
+	}
+
+	return scheduler, pool, db, dispose
+}
+
+func newTestTemplate(from common.Address) *Template {
+	return &Template{
+		Name:     "payout",
+		Schedule: "* * * * *",
+		From:     from,
+		To:       *crypto.MustGenerateRandomAddress(),
+		Amount:   big.NewInt(100),
+		GasPrice: big.NewInt(1),
+		GasLimit: types.TransferGas,
+		Enabled:  true,
+	}
+}
+
+func Test_Scheduler_AddTemplate(t *testing.T) {
+	scheduler, _, db, dispose := newTestScheduler(t)
+	defer dispose()
+
+	template := newTestTemplate(*crypto.MustGenerateRandomAddress())
+
+	invalid := *template
+	invalid.Schedule = "* * *"
+	_, err := scheduler.AddTemplate(&invalid)
+	assert.Equal(t, err, ErrInvalidSchedule)
+
+	invalid = *template
+	invalid.GasLimit = 0
+	_, err = scheduler.AddTemplate(&invalid)
+	assert.Equal(t, err, ErrInvalidTemplate)
+
+	invalid = *template
+	invalid.Amount = big.NewInt(-1)
+	_, err = scheduler.AddTemplate(&invalid)
+	assert.Equal(t, err, ErrInvalidTemplate)
+
+	id, err := scheduler.AddTemplate(template)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, id, uint64(0))
+
+	id, err = scheduler.AddTemplate(template)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, id, uint64(1))
+
+	assert.Equal(t, scheduler.EnableTemplate(0, false), error(nil))
+	assert.Equal(t, scheduler.RemoveTemplate(1), error(nil))
+	assert.Equal(t, scheduler.RemoveTemplate(1), ErrTemplateNotFound)
+	assert.Equal(t, scheduler.EnableTemplate(1, true), ErrTemplateNotFound)
+
+	// templates are reloaded from the database
+	reloaded, err := New(db, scheduler.chain, scheduler.pool, 1, scheduler.log)
+	assert.Equal(t, err, error(nil))
+
+	templates := reloaded.Templates()
+	assert.Equal(t, len(templates), 1)
+	assert.Equal(t, templates[0].ID, uint64(0))
+	assert.Equal(t, templates[0].Enabled, false)
+	assert.Equal(t, templates[0].To, template.To)
+	assert.Equal(t, templates[0].Amount, template.Amount)
+
+	// the removed template id is not reused
+	id, err = reloaded.AddTemplate(template)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, id, uint64(2))
+}
+
+func Test_Scheduler_Run(t *testing.T) {
+	scheduler, pool, _, dispose := newTestScheduler(t)
+	defer dispose()
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := scheduler.Accounts().Unlock(privKey)
+	assert.Equal(t, err, error(nil))
+
+	id, err := scheduler.AddTemplate(newTestTemplate(from))
+	assert.Equal(t, err, error(nil))
+
+	created := time.Unix(int64(scheduler.Templates()[0].Created), 0)
+
+	// not due yet
+	scheduler.run(created)
+	assert.Equal(t, len(pool.txs), 0)
+
+	// the missed runs are submitted only once
+	now := created.Add(3 * time.Minute)
+	scheduler.run(now)
+	scheduler.run(now)
+	assert.Equal(t, len(pool.txs), 1)
+	assert.Equal(t, pool.txs[0].Data.From, from)
+	assert.Equal(t, pool.txs[0].Data.AccountNonce, uint64(0))
+	assert.Equal(t, pool.txs[0].Data.ChainID, uint64(1))
+	assert.Equal(t, pool.txs[0].Signature != nil, true)
+	assert.Equal(t, scheduler.Templates()[0].LastRun, uint64(now.Unix()))
+
+	// the nonce is tracked before the txs are packed
+	now = now.Add(time.Minute)
+	scheduler.run(now)
+	assert.Equal(t, len(pool.txs), 2)
+	assert.Equal(t, pool.txs[1].Data.AccountNonce, uint64(1))
+
+	// failed to add into the pool
+	pool.err = errors.New("pool full")
+	now = now.Add(time.Minute)
+	scheduler.run(now)
+
+	// failed to sign
+	pool.err = nil
+	assert.Equal(t, scheduler.Accounts().Lock(from), true)
+	assert.Equal(t, scheduler.Accounts().Lock(from), false)
+	now = now.Add(time.Minute)
+	scheduler.run(now)
+	assert.Equal(t, len(pool.txs), 2)
+
+	// disabled
+	assert.Equal(t, scheduler.EnableTemplate(id, false), error(nil))
+	scheduler.run(now.Add(time.Minute))
+
+	submissions, err := scheduler.Submissions(id)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(submissions), 4)
+	assert.Equal(t, submissions[0].TxHash, pool.txs[0].Hash)
+	assert.Equal(t, submissions[0].Error, "")
+	assert.Equal(t, submissions[1].TxHash, pool.txs[1].Hash)
+	assert.Equal(t, submissions[2].Nonce, uint64(2))
+	assert.Equal(t, submissions[2].Error, "pool full")
+	assert.Equal(t, submissions[3].Nonce, uint64(2))
+	assert.Equal(t, submissions[3].Error, ErrAccountLocked.Error())
+	assert.Equal(t, submissions[3].TxHash, common.EmptyHash)
+}
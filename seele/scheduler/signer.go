@@ -0,0 +1,189 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// signTimeout is the timeout of the request to the external signer.
+const signTimeout = 30 * time.Second
+
+var (
+	// ErrAccountLocked is returned when the sender of the tx to sign is not unlocked.
+	ErrAccountLocked = errors.New("account locked")
+
+	// ErrSignerMismatch is returned when the tx signed by the external signer is changed or not signed.
+	ErrSignerMismatch = errors.New("tx changed or not signed by the external signer")
+
+	// ErrKeysSeparated is returned when unlocking an account in the node process while the private
+	// keys are held by the signer process.
+	ErrKeysSeparated = errors.New("private keys are held by the signer process")
+
+	// ErrSignRequestInvalid is returned when the external signer receives a request without tx.
+	ErrSignRequestInvalid = errors.New("invalid sign request")
+
+	// ErrSignRequestUnauthorized is returned when the external signer receives a request without
+	// the secret shared with the node, e.g. from an unrelated local process.
+	ErrSignRequestUnauthorized = errors.New("unauthorized sign request")
+)
+
+// Signer signs the txs submitted by the scheduler in the specified sighash scheme.
+type Signer interface {
+	SignTx(tx *types.Transaction, scheme types.SigHashScheme) (*types.Transaction, error)
+}
+
+// Accounts is the set of the unlocked accounts, whose private keys are only kept
+// in memory, so the accounts should be unlocked again once the node restarts.
+type Accounts struct {
+	lock sync.RWMutex
+	keys map[common.Address]*ecdsa.PrivateKey
+}
+
+// NewAccounts returns an empty set of the unlocked accounts.
+func NewAccounts() *Accounts {
+	return &Accounts{keys: make(map[common.Address]*ecdsa.PrivateKey)}
+}
+
+// Unlock unlocks the account of the specified private key, and returns its address.
+func (accounts *Accounts) Unlock(privKey *ecdsa.PrivateKey) (common.Address, error) {
+	addr, err := crypto.GetAddress(privKey)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	accounts.lock.Lock()
+	defer accounts.lock.Unlock()
+
+	accounts.keys[*addr] = privKey
+	return *addr, nil
+}
+
+// Lock locks the account, and returns false if the account is not unlocked.
+func (accounts *Accounts) Lock(addr common.Address) bool {
+	accounts.lock.Lock()
+	defer accounts.lock.Unlock()
+
+	_, found := accounts.keys[addr]
+	delete(accounts.keys, addr)
+	return found
+}
+
+// SignTx signs the tx with the private key of the unlocked sender.
+func (accounts *Accounts) SignTx(tx *types.Transaction, scheme types.SigHashScheme) (*types.Transaction, error) {
+	accounts.lock.RLock()
+	privKey := accounts.keys[tx.Data.From]
+	accounts.lock.RUnlock()
+
+	if privKey == nil {
+		return nil, ErrAccountLocked
+	}
+
+	tx.SignWithScheme(privKey, scheme)
+	return tx, nil
+}
+
+// ExternalSignRequest is the request param of the signer.SignTx method of the external signer.
+type ExternalSignRequest struct {
+	Tx     *types.Transaction  // Tx is the tx to sign
+	Scheme types.SigHashScheme // Scheme is the sighash scheme to sign the tx in
+	Secret string              // Secret is the secret shared with the signer, empty if the signer requires none
+}
+
+// externalSigner signs the txs via the signer.SignTx JSON-RPC method of the external signer,
+// e.g. a signing service backed by the hardware wallet, so that no private key is kept in node.
+type externalSigner struct {
+	address string
+	secret  string
+}
+
+// SignTx sends the tx to the external signer and verifies the signed tx.
+func (signer *externalSigner) SignTx(tx *types.Transaction, scheme types.SigHashScheme) (*types.Transaction, error) {
+	tx.SigVersion = scheme.Version
+	tx.Data.ChainID = scheme.ChainID
+
+	conn, err := net.DialTimeout("tcp", signer.address, signTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Now().Add(signTimeout))
+	client := jsonrpc.NewClient(conn)
+	defer client.Close()
+
+	signed := new(types.Transaction)
+	if err = client.Call("signer.SignTx", &ExternalSignRequest{tx, scheme, signer.secret}, signed); err != nil {
+		return nil, err
+	}
+
+	// the signature is verified when the tx is added into the pool
+	if signed.Data == nil || signed.Signature == nil || signed.CalculateHash() != tx.CalculateHash() {
+		return nil, ErrSignerMismatch
+	}
+
+	return signed, nil
+}
+
+// SignerService serves the signer.SignTx JSON-RPC method of the external signer with the
+// unlocked accounts, e.g. in the signer process separated from the network-facing node.
+type SignerService struct {
+	accounts *Accounts
+	secret   string // secret shared with the node, the requests without it are rejected
+}
+
+// NewSignerService creates the signer service of the specified unlocked accounts, which
+// only signs the requests of the specified secret.
+func NewSignerService(accounts *Accounts, secret string) *SignerService {
+	return &SignerService{accounts, secret}
+}
+
+// SignTx signs the tx with the private key of the unlocked sender.
+func (service *SignerService) SignTx(request *ExternalSignRequest, result *types.Transaction) error {
+	if subtle.ConstantTimeCompare([]byte(request.Secret), []byte(service.secret)) != 1 {
+		return ErrSignRequestUnauthorized
+	}
+
+	if request.Tx == nil || request.Tx.Data == nil {
+		return ErrSignRequestInvalid
+	}
+
+	signed, err := service.accounts.SignTx(request.Tx, request.Scheme)
+	if err != nil {
+		return err
+	}
+
+	*result = *signed
+	return nil
+}
+
+// Serve accepts the connections of the listener and serves the signer.SignTx method on
+// them, until the listener is closed.
+func (service *SignerService) Serve(listener net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("signer", service); err != nil {
+		return err
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
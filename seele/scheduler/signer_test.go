@@ -0,0 +1,91 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common/hexutil"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_SignerService_DefaultSigner(t *testing.T) {
+	scheduler, pool, _, dispose := newTestScheduler(t)
+	defer dispose()
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the key is only unlocked in the signer service
+	accounts := NewAccounts()
+	from, err := accounts.Unlock(privKey)
+	assert.Equal(t, err, error(nil))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go NewSignerService(accounts, "secret").Serve(listener)
+	scheduler.SetDefaultSigner(listener.Addr().String(), "secret")
+
+	_, err = scheduler.AddTemplate(newTestTemplate(from))
+	assert.Equal(t, err, error(nil))
+
+	created := time.Unix(int64(scheduler.Templates()[0].Created), 0)
+	scheduler.run(created.Add(time.Minute))
+	assert.Equal(t, len(pool.txs), 1)
+	assert.Equal(t, pool.txs[0].Data.From, from)
+	assert.Equal(t, pool.txs[0].Data.ChainID, uint64(1))
+	assert.Equal(t, pool.txs[0].Signature != nil, true)
+
+	// the keys could not be unlocked in the node process
+	key := hexutil.BytesToHex(crypto.FromECDSA(privKey))
+	err = NewPrivateSchedulerAPI(scheduler).UnlockAccount(&key, nil)
+	assert.Equal(t, err, ErrKeysSeparated)
+}
+
+func Test_SignerService_Secret(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accounts := NewAccounts()
+	from, err := accounts.Unlock(privKey)
+	assert.Equal(t, err, error(nil))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go NewSignerService(accounts, "secret").Serve(listener)
+
+	newTx := func() *types.Transaction {
+		return types.NewTransaction(from, *crypto.MustGenerateRandomAddress(), big.NewInt(1), big.NewInt(1), types.TransferGas, 0)
+	}
+	scheme := types.SigHashScheme{Version: types.LatestSigHashVersion, ChainID: 1}
+
+	// the unrelated clients without the secret could not sign
+	for _, secret := range []string{"", "wrong"} {
+		_, err = (&externalSigner{listener.Addr().String(), secret}).SignTx(newTx(), scheme)
+		assert.Equal(t, err.Error(), ErrSignRequestUnauthorized.Error())
+	}
+
+	signed, err := (&externalSigner{listener.Addr().String(), "secret"}).SignTx(newTx(), scheme)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, signed.Signature != nil, true)
+}
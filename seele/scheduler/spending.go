@@ -0,0 +1,173 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+)
+
+// DefaultSpendingWindow is the default time window of the spending limits.
+const DefaultSpendingWindow = 24 * time.Hour
+
+// ErrSpendingLimitExceeded is returned when signing a tx beyond the spending limit of the sender.
+var ErrSpendingLimitExceeded = errors.New("spending limit exceeded")
+
+// SpendingStatus is the spending of an address within the current window.
+type SpendingStatus struct {
+	Address   common.Address // Address is the sender of the signed txs
+	Window    uint64         // Window is the time window of the limit in seconds
+	Limit     *big.Int       // Limit is the maximum value to sign within the window, nil for no limit
+	Spent     *big.Int       // Spent is the value signed within the window, excluding the value covered by the allowance
+	Allowance *big.Int       // Allowance is the value granted by admin to sign beyond the limit
+}
+
+// spending is the value signed at the time.
+type spending struct {
+	time  time.Time
+	value *big.Int
+}
+
+// SpendingPolicy limits the outgoing value of the txs signed by the node for each address
+// within a sliding time window, e.g. the withdrawal velocity of the custodial accounts. The
+// value of a tx is its amount plus the max fee. The spending is only tracked in memory.
+type SpendingPolicy struct {
+	lock       sync.Mutex
+	window     time.Duration
+	limits     map[common.Address]*big.Int   // the addresses without limit are not restricted
+	allowances map[common.Address]*big.Int   // allowances are granted by admin to override the limits
+	spent      map[common.Address][]spending // spent are the values signed within the window in time order
+}
+
+// NewSpendingPolicy creates the policy of the specified limits per window, DefaultSpendingWindow if 0.
+func NewSpendingPolicy(window time.Duration, limits map[common.Address]*big.Int) *SpendingPolicy {
+	if window <= 0 {
+		window = DefaultSpendingWindow
+	}
+
+	policy := &SpendingPolicy{
+		window:     window,
+		limits:     make(map[common.Address]*big.Int),
+		allowances: make(map[common.Address]*big.Int),
+		spent:      make(map[common.Address][]spending),
+	}
+
+	for addr, limit := range limits {
+		policy.limits[addr] = new(big.Int).Set(limit)
+	}
+
+	return policy
+}
+
+// SetLimit sets the spending limit of the address per window, nil to remove the limit.
+func (policy *SpendingPolicy) SetLimit(addr common.Address, limit *big.Int) {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+
+	if limit == nil {
+		delete(policy.limits, addr)
+	} else {
+		policy.limits[addr] = new(big.Int).Set(limit)
+	}
+}
+
+// Grant grants the one-off allowance to sign beyond the limit of the address, which is
+// consumed once the spending exceeds the limit.
+func (policy *SpendingPolicy) Grant(addr common.Address, allowance *big.Int) {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+
+	if current := policy.allowances[addr]; current != nil {
+		policy.allowances[addr] = new(big.Int).Add(current, allowance)
+	} else {
+		policy.allowances[addr] = new(big.Int).Set(allowance)
+	}
+}
+
+// Status returns the spending of the address within the window till now.
+func (policy *SpendingPolicy) Status(addr common.Address, now time.Time) *SpendingStatus {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+
+	status := &SpendingStatus{
+		Address:   addr,
+		Window:    uint64(policy.window / time.Second),
+		Spent:     policy.spentWithin(addr, now),
+		Allowance: new(big.Int),
+	}
+
+	if limit := policy.limits[addr]; limit != nil {
+		status.Limit = new(big.Int).Set(limit)
+	}
+
+	if allowance := policy.allowances[addr]; allowance != nil {
+		status.Allowance.Set(allowance)
+	}
+
+	return status
+}
+
+// spend records the value to sign for the address at the time, or returns ErrSpendingLimitExceeded
+// if the spending within the window would exceed the limit and the allowance.
+func (policy *SpendingPolicy) spend(addr common.Address, value *big.Int, now time.Time) error {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+
+	recorded := new(big.Int).Set(value)
+	if limit := policy.limits[addr]; limit != nil {
+		spent := new(big.Int).Add(policy.spentWithin(addr, now), value)
+		if beyond := spent.Sub(spent, limit); beyond.Sign() > 0 {
+			allowance := policy.allowances[addr]
+			if allowance == nil || allowance.Cmp(beyond) < 0 {
+				return ErrSpendingLimitExceeded
+			}
+
+			// the value beyond the limit is covered by the allowance instead
+			policy.allowances[addr] = new(big.Int).Sub(allowance, beyond)
+			recorded.Sub(recorded, beyond)
+		}
+	}
+
+	policy.spent[addr] = append(policy.spent[addr], spending{now, recorded})
+	return nil
+}
+
+// refund removes the latest value recorded at the time for the address, e.g. failed to
+// sign the tx. The consumed allowance is not refunded.
+func (policy *SpendingPolicy) refund(addr common.Address, now time.Time) {
+	policy.lock.Lock()
+	defer policy.lock.Unlock()
+
+	records := policy.spent[addr]
+	if last := len(records) - 1; last >= 0 && records[last].time.Equal(now) {
+		policy.spent[addr] = records[:last]
+	}
+}
+
+// spentWithin drops the records out of the window till now, and returns the sum of the rest.
+func (policy *SpendingPolicy) spentWithin(addr common.Address, now time.Time) *big.Int {
+	records := policy.spent[addr]
+	for len(records) > 0 && !records[0].time.After(now.Add(-policy.window)) {
+		records = records[1:]
+	}
+
+	if len(records) == 0 {
+		delete(policy.spent, addr)
+	} else {
+		policy.spent[addr] = records
+	}
+
+	sum := new(big.Int)
+	for _, record := range records {
+		sum.Add(sum, record.value)
+	}
+
+	return sum
+}
@@ -0,0 +1,88 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package scheduler
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+func Test_SpendingPolicy_Spend(t *testing.T) {
+	limited := *crypto.MustGenerateRandomAddress()
+	unlimited := *crypto.MustGenerateRandomAddress()
+	policy := NewSpendingPolicy(time.Hour, map[common.Address]*big.Int{limited: big.NewInt(100)})
+
+	now := time.Now()
+	assert.Equal(t, policy.spend(limited, big.NewInt(60), now), error(nil))
+	assert.Equal(t, policy.spend(limited, big.NewInt(50), now), ErrSpendingLimitExceeded)
+	assert.Equal(t, policy.spend(limited, big.NewInt(40), now.Add(time.Minute)), error(nil))
+	assert.Equal(t, policy.spend(unlimited, big.NewInt(1000), now), error(nil))
+
+	// the allowance covers the value beyond the limit
+	policy.Grant(limited, big.NewInt(30))
+	assert.Equal(t, policy.spend(limited, big.NewInt(20), now.Add(time.Minute)), error(nil))
+	assert.Equal(t, policy.spend(limited, big.NewInt(20), now.Add(time.Minute)), ErrSpendingLimitExceeded)
+	assert.Equal(t, policy.spend(limited, big.NewInt(10), now.Add(time.Minute)), error(nil))
+
+	status := policy.Status(limited, now.Add(time.Minute))
+	assert.Equal(t, status.Spent, big.NewInt(100))
+	assert.Equal(t, status.Allowance, big.NewInt(0))
+	assert.Equal(t, status.Window, uint64(3600))
+
+	// the spending out of the window is dropped
+	assert.Equal(t, policy.spend(limited, big.NewInt(60), now.Add(time.Hour)), error(nil))
+	assert.Equal(t, policy.Status(limited, now.Add(time.Hour)).Spent, big.NewInt(100))
+
+	// the spending is refunded if failed to sign
+	policy.refund(limited, now.Add(time.Hour))
+	assert.Equal(t, policy.Status(limited, now.Add(time.Hour)).Spent, big.NewInt(40))
+
+	policy.SetLimit(limited, nil)
+	assert.Equal(t, policy.spend(limited, big.NewInt(1000), now.Add(time.Hour)), error(nil))
+	assert.Equal(t, policy.Status(limited, now).Limit == nil, true)
+}
+
+func Test_Scheduler_SpendingLimit(t *testing.T) {
+	scheduler, pool, _, dispose := newTestScheduler(t)
+	defer dispose()
+
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := scheduler.Accounts().Unlock(privKey)
+	assert.Equal(t, err, error(nil))
+
+	// each tx spends the amount 100 and the max fee
+	template := newTestTemplate(from)
+	value := new(big.Int).Add(template.Amount, big.NewInt(int64(template.GasLimit)))
+	scheduler.SpendingPolicy().SetLimit(from, value)
+
+	id, err := scheduler.AddTemplate(template)
+	assert.Equal(t, err, error(nil))
+
+	now := time.Unix(int64(scheduler.Templates()[0].Created), 0).Add(time.Minute)
+	scheduler.run(now)
+	scheduler.run(now.Add(time.Minute))
+	assert.Equal(t, len(pool.txs), 1)
+
+	submissions, err := scheduler.Submissions(id)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, submissions[1].Error, ErrSpendingLimitExceeded.Error())
+
+	// signed again once approved by admin
+	api := NewPrivateSchedulerAPI(scheduler)
+	var granted bool
+	assert.Equal(t, api.GrantSpendingAllowance(&SpendingLimitRequest{from, value}, &granted), error(nil))
+	scheduler.run(now.Add(2 * time.Minute))
+	assert.Equal(t, len(pool.txs), 2)
+}
@@ -8,6 +8,7 @@ package seele
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/seeleteam/go-seele/common"
@@ -34,7 +35,14 @@ var (
 	statusDataMsgCode      uint16 = 6
 	statusChainHeadMsgCode uint16 = 7
 
-	protocolMsgCodeLength uint16 = 13
+	transactionHashesMsgCode   uint16 = 13
+	transactionsRequestMsgCode uint16 = 14
+
+	compactBlockMsgCode    uint16 = 15
+	blockTxsRequestMsgCode uint16 = 16
+	blockTxsMsgCode        uint16 = 17
+
+	protocolMsgCodeLength uint16 = 18
 )
 
 // SeeleProtocol service implementation of seele
@@ -42,10 +50,18 @@ type SeeleProtocol struct {
 	p2p.Protocol
 	peerSet *peerSet
 
-	networkID  uint64
-	downloader *downloader.Downloader
-	txPool     *core.TransactionPool
-	chain      *core.Blockchain
+	networkID   uint64
+	downloader  *downloader.Downloader
+	txPool      *core.TransactionPool
+	chain       *core.Blockchain
+	orphans     *orphanBlockPool
+	compacts    *compactBlockPool
+	propagation *propagationTracker
+	newTxs      chan *types.Transaction // newTxs are the txs accepted by the pool to relay
+	newTxSub    *core.TxSubscription
+
+	minSyncSubnets int   // number of distinct subnets of the peers required to sync to a head
+	diverged       int32 // 1 if the best head is claimed by fewer subnets than required, accessed atomically
 
 	wg     sync.WaitGroup
 	quitCh chan struct{}
@@ -64,21 +80,26 @@ func NewSeeleProtocol(seele *SeeleService, log *log.SeeleLog) (s *SeeleProtocol,
 			Version: SeeleVersion,
 			Length:  protocolMsgCodeLength,
 		},
-		networkID:  seele.networkID,
-		txPool:     seele.TxPool(),
-		chain:      seele.BlockChain(),
-		downloader: downloader.NewDownloader(seele.BlockChain()),
-		log:        log,
-		quitCh:     make(chan struct{}),
-		syncCh:     make(chan struct{}),
+		networkID:   seele.networkID,
+		txPool:      seele.TxPool(),
+		chain:       seele.BlockChain(),
+		orphans:     newOrphanBlockPool(orphanBlockCapacity),
+		compacts:    newCompactBlockPool(compactBlockCapacity, compactBlockTimeout),
+		propagation: newPropagationTracker(),
+		newTxs:      make(chan *types.Transaction, newTxChanSize),
+		downloader:  downloader.NewDownloader(seele.BlockChain()),
+		log:         log,
+		quitCh:      make(chan struct{}),
+		syncCh:      make(chan struct{}),
 
 		peerSet: newPeerSet(),
 	}
 
 	s.Protocol.AddPeer = s.handleAddPeer
 	s.Protocol.DeletePeer = s.handleDelPeer
+	s.Protocol.DescribeMsg = describeMsg
 
-	event.TransactionInsertedEventManager.AddAsyncListener(s.handleNewTx)
+	s.newTxSub = s.txPool.SubscribeNewTxs(s.newTxs)
 	event.BlockMinedEventManager.AddAsyncListener(s.handleNewMinedBlock)
 	return s, nil
 }
@@ -86,12 +107,13 @@ func NewSeeleProtocol(seele *SeeleService, log *log.SeeleLog) (s *SeeleProtocol,
 func (sp *SeeleProtocol) Start() {
 	sp.log.Info("SeeleProtocol.Start called!")
 	go sp.syncer()
+	go sp.txGossiper()
 }
 
 // Stop stops protocol, called when seeleService quits.
 func (sp *SeeleProtocol) Stop() {
 	event.BlockMinedEventManager.RemoveListener(sp.handleNewMinedBlock)
-	event.TransactionInsertedEventManager.RemoveListener(sp.handleNewTx)
+	sp.newTxSub.Unsubscribe()
 	close(sp.quitCh)
 	close(sp.syncCh)
 	sp.wg.Wait()
@@ -107,15 +129,62 @@ func (sp *SeeleProtocol) syncer() {
 	for {
 		select {
 		case <-sp.syncCh:
-			go sp.synchronise(sp.peerSet.bestPeer())
+			go sp.synchronise(sp.syncPeer())
 		case <-forceSync.C:
-			go sp.synchronise(sp.peerSet.bestPeer())
+			go sp.synchronise(sp.syncPeer())
 		case <-sp.quitCh:
 			return
 		}
 	}
 }
 
+// txGossiper periodically flushes the batched tx announcements to peers.
+func (sp *SeeleProtocol) txGossiper() {
+	defer sp.wg.Done()
+	sp.wg.Add(1)
+
+	ticker := time.NewTicker(txGossipInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case tx := <-sp.newTxs:
+			sp.handleNewTx(tx)
+		case now := <-ticker.C:
+			sp.peerSet.ForEach(func(peer *peer) bool {
+				hashes := peer.txGossip.pop(now)
+				if len(hashes) == 0 {
+					return true
+				}
+
+				if err := peer.sendTransactionHashes(hashes); err != nil {
+					sp.log.Warn("send transaction hashes failed %s", err.Error())
+				}
+				return true
+			})
+		case <-sp.quitCh:
+			return
+		}
+	}
+}
+
+// syncPeer returns the peer of the best head supported by the peers of enough distinct subnets,
+// and alerts when the head consensus among peers diverges.
+func (sp *SeeleProtocol) syncPeer() *peer {
+	consensus := sp.peerSet.headConsensus(sp.minSyncSubnets)
+
+	if consensus.Diverged {
+		if atomic.CompareAndSwapInt32(&sp.diverged, 0, 1) {
+			sp.log.Warn("head consensus diverged, the best td %s is claimed by peers of %d subnets, less than %d required, sync to td %s instead",
+				consensus.BestTD, consensus.BestSubnets, consensus.MinSubnets, consensus.SyncTD)
+		}
+	} else if atomic.CompareAndSwapInt32(&sp.diverged, 1, 0) {
+		sp.log.Info("head consensus recovered, the best td %s is claimed by peers of %d subnets", consensus.BestTD, consensus.BestSubnets)
+	}
+
+	return consensus.syncPeer
+}
+
 func (sp *SeeleProtocol) synchronise(p *peer) {
 	sp.log.Info("sp.synchronise called.")
 	if p == nil {
@@ -216,14 +285,13 @@ loopOut:
 	close(resultCh)
 }
 
-func (p *SeeleProtocol) handleNewTx(e event.Event) {
+// handleNewTx queues the new tx accepted by the pool to announce to the peers not knowing it.
+func (p *SeeleProtocol) handleNewTx(tx *types.Transaction) {
 	p.log.Debug("find new tx")
-	tx := e.(*types.Transaction)
 
 	p.peerSet.ForEach(func(peer *peer) bool {
-
-		if err := peer.sendTransactionHash(tx.Hash); err != nil {
-			p.log.Warn("send transaction failed %s", err.Error())
+		if !peer.knownTxs.Has(tx.Hash) {
+			peer.txGossip.push(tx)
 		}
 		return true
 	})
@@ -233,10 +301,11 @@ func (p *SeeleProtocol) handleNewMinedBlock(e event.Event) {
 	p.log.Debug("find new mined block")
 	block := e.(*types.Block)
 
+	compact := newCompactBlock(block)
 	p.peerSet.ForEach(func(peer *peer) bool {
-		err := peer.SendBlockHash(block.HeaderHash)
+		err := peer.sendCompactBlock(compact)
 		if err != nil {
-			p.log.Warn("send mined block hash failed %s", err.Error())
+			p.log.Warn("send mined compact block failed %s", err.Error())
 		}
 		return true
 	})
@@ -247,6 +316,105 @@ func (p *SeeleProtocol) handleNewMinedBlock(e event.Event) {
 	p.broadcastChainHead()
 }
 
+// handleNewBlock writes the block received from the specified peer into the blockchain.
+// If the parent block is unknown, the block is cached and the parent is requested from the peer.
+// Otherwise, the cached descendant blocks are imported as well.
+func (p *SeeleProtocol) handleNewBlock(peer *peer, block *types.Block) {
+	err := p.chain.WriteBlock(block)
+	if err == core.ErrBlockInvalidParentHash {
+		if !p.orphans.add(block) {
+			return
+		}
+
+		parentHash := block.Header.PreviousBlockHash
+		p.log.Debug("cache orphan block %s, request parent %s", block.HeaderHash.ToHex(), parentHash.ToHex())
+		if p.orphans.has(parentHash) {
+			return
+		}
+
+		if err = peer.SendBlockRequest(parentHash); err != nil {
+			p.log.Warn("send parent block request msg failed %s", err.Error())
+		}
+
+		return
+	}
+
+	if err != nil {
+		p.log.Debug("write block %s failed %s", block.HeaderHash.ToHex(), err.Error())
+		return
+	}
+
+	p.importOrphans(block.HeaderHash)
+}
+
+// handleCompactBlock reconstructs the compact block received from the specified peer with the txs
+// in the pool, and requests the missing txs from the peer if any.
+func (p *SeeleProtocol) handleCompactBlock(peer *peer, compact *compactBlock) {
+	if p.compacts.has(compact.HeaderHash) {
+		return
+	}
+
+	if exist, err := p.chain.GetStore().HasBlock(compact.HeaderHash); err != nil || exist {
+		return
+	}
+
+	block, missing, err := p.compacts.reconstruct(compact, p.txPool.GetTransaction, time.Now())
+	if err != nil {
+		p.log.Debug("reconstruct compact block %s failed %s", compact.HeaderHash.ToHex(), err.Error())
+		return
+	}
+
+	p.propagation.compact(len(missing))
+
+	if block != nil {
+		p.propagation.receive(block.HeaderHash, time.Now())
+		p.handleNewBlock(peer, block)
+		return
+	}
+
+	p.log.Debug("request %d missing txs of compact block %s", len(missing), compact.HeaderHash.ToHex())
+	if err = peer.sendBlockTxsRequest(&blockTxsRequest{compact.HeaderHash, missing}); err != nil {
+		p.log.Warn("send block txs request msg failed %s", err.Error())
+	}
+}
+
+// handleBlockTxs fills the missing txs of the compact block received from the specified peer,
+// and requests the full block instead if the txs mismatch.
+func (p *SeeleProtocol) handleBlockTxs(peer *peer, response *blockTxs) {
+	block, ok := p.compacts.fill(response)
+	if !ok {
+		p.log.Debug("missing txs of compact block %s mismatch, request full block", response.BlockHash.ToHex())
+		if err := peer.SendBlockRequest(response.BlockHash); err != nil {
+			p.log.Warn("send block request msg failed %s", err.Error())
+		}
+
+		return
+	}
+
+	if block != nil {
+		p.propagation.receive(block.HeaderHash, time.Now())
+		p.handleNewBlock(peer, block)
+	}
+}
+
+// importOrphans imports the cached orphan blocks descended from the specified block.
+func (p *SeeleProtocol) importOrphans(hash common.Hash) {
+	parents := []common.Hash{hash}
+	for len(parents) > 0 {
+		parentHash := parents[0]
+		parents = parents[1:]
+
+		for _, block := range p.orphans.takeChildren(parentHash) {
+			if err := p.chain.WriteBlock(block); err != nil {
+				p.log.Debug("import orphan block %s failed %s", block.HeaderHash.ToHex(), err.Error())
+				continue
+			}
+
+			parents = append(parents, block.HeaderHash)
+		}
+	}
+}
+
 func (p *SeeleProtocol) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) {
 	newPeer := newPeer(SeeleVersion, p2pPeer, rw)
 
@@ -320,6 +488,52 @@ handler:
 				break handler
 			}
 
+		case transactionHashesMsgCode:
+			var txHashes []common.Hash
+			err := common.Deserialize(msg.Payload, &txHashes)
+			if err != nil {
+				p.log.Warn("deserialize transaction hashes msg failed %s", err.Error())
+				continue
+			}
+
+			p.log.Debug("got %d tx hashes", len(txHashes))
+
+			var unknownHashes []common.Hash
+			for _, txHash := range txHashes {
+				if !peer.knownTxs.Has(txHash) && p.txPool.GetTransaction(txHash) == nil {
+					unknownHashes = append(unknownHashes, txHash)
+				}
+				peer.markTransaction(txHash)
+			}
+
+			if len(unknownHashes) > 0 {
+				if err := peer.sendTransactionsRequest(unknownHashes); err != nil {
+					p.log.Warn("send transactions request msg failed %s", err.Error())
+					break handler
+				}
+			}
+
+		case transactionsRequestMsgCode:
+			var txHashes []common.Hash
+			err := common.Deserialize(msg.Payload, &txHashes)
+			if err != nil {
+				p.log.Warn("deserialize transactions request msg failed %s", err.Error())
+				continue
+			}
+
+			p.log.Debug("got %d txs request", len(txHashes))
+
+			txs := peer.txGossip.serve(txHashes, p.txPool.GetTransaction, time.Now())
+			if len(txs) == 0 {
+				continue
+			}
+
+			payload := common.SerializePanic(txs)
+			if err = p2p.SendMessage(peer.rw, transactionsMsgCode, payload); err != nil {
+				p.log.Warn("send transactions msg failed %s", err.Error())
+				break handler
+			}
+
 		case transactionsMsgCode:
 			var txs []*types.Transaction
 			err := common.Deserialize(msg.Payload, &txs)
@@ -343,6 +557,7 @@ handler:
 			}
 
 			p.log.Debug("got block hash msg %s", blockHash.ToHex())
+			p.propagation.see(blockHash, time.Now())
 
 			if !peer.knownBlocks.Has(blockHash) {
 				peer.knownBlocks.Add(blockHash)
@@ -382,8 +597,59 @@ handler:
 			}
 
 			p.log.Debug("got block msg %s", block.HeaderHash.ToHex())
+			p.propagation.receive(block.HeaderHash, time.Now())
 			// @todo need to make sure WriteBlock handle block fork
-			p.chain.WriteBlock(&block)
+			p.handleNewBlock(peer, &block)
+
+		case compactBlockMsgCode:
+			var compact compactBlock
+			err := common.Deserialize(msg.Payload, &compact)
+			if err != nil {
+				p.log.Warn("deserialize compact block msg failed %s", err.Error())
+				continue
+			}
+
+			p.log.Debug("got compact block msg %s", compact.HeaderHash.ToHex())
+			p.propagation.see(compact.HeaderHash, time.Now())
+			peer.knownBlocks.Add(compact.HeaderHash)
+			p.handleCompactBlock(peer, &compact)
+
+		case blockTxsRequestMsgCode:
+			var request blockTxsRequest
+			err := common.Deserialize(msg.Payload, &request)
+			if err != nil {
+				p.log.Warn("deserialize block txs request msg failed %s", err.Error())
+				continue
+			}
+
+			p.log.Debug("got block txs request msg %s", request.BlockHash.ToHex())
+			block, err := p.chain.GetStore().GetBlock(request.BlockHash)
+			if err != nil {
+				p.log.Warn("not found request block %s", err.Error())
+				continue
+			}
+
+			response := &blockTxs{BlockHash: request.BlockHash}
+			for _, index := range request.Indexes {
+				if index < uint64(len(block.Transactions)) {
+					response.Txs = append(response.Txs, block.Transactions[index])
+				}
+			}
+
+			if err = peer.sendBlockTxs(response); err != nil {
+				p.log.Warn("send block txs msg failed %s", err.Error())
+			}
+
+		case blockTxsMsgCode:
+			var response blockTxs
+			err := common.Deserialize(msg.Payload, &response)
+			if err != nil {
+				p.log.Warn("deserialize block txs msg failed %s", err.Error())
+				continue
+			}
+
+			p.log.Debug("got %d block txs of %s", len(response.Txs), response.BlockHash.ToHex())
+			p.handleBlockTxs(peer, &response)
 
 		case downloader.GetBlockHeadersMsg:
 			var query blockHeadersQuery
@@ -7,33 +7,65 @@ package seele
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/bft"
+	"github.com/seeleteam/go-seele/consensus/memhash"
 	"github.com/seeleteam/go-seele/core"
 	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
 	"github.com/seeleteam/go-seele/database"
 	"github.com/seeleteam/go-seele/database/leveldb"
 	"github.com/seeleteam/go-seele/log"
 	"github.com/seeleteam/go-seele/miner"
+	"github.com/seeleteam/go-seele/miner/pow"
 	"github.com/seeleteam/go-seele/p2p"
 	"github.com/seeleteam/go-seele/rpc"
+	"github.com/seeleteam/go-seele/seele/apikey"
+	"github.com/seeleteam/go-seele/seele/backup"
+	"github.com/seeleteam/go-seele/seele/balance"
 	"github.com/seeleteam/go-seele/seele/download"
+	"github.com/seeleteam/go-seele/seele/firehose"
+	"github.com/seeleteam/go-seele/seele/hashrate"
+	"github.com/seeleteam/go-seele/seele/label"
+	"github.com/seeleteam/go-seele/seele/logindex"
+	"github.com/seeleteam/go-seele/seele/scheduler"
+	"github.com/seeleteam/go-seele/seele/snapshot"
 )
 
 // SeeleService implements full node service.
 type SeeleService struct {
 	networkID     uint64
+	dataDir       string // data folder of the node, in which the tx pool dumps and downloaded snapshots are stored
 	p2pServer     *p2p.Server
 	seeleProtocol *SeeleProtocol
+	telemetry     *telemetry
+	bft           *bftProtocol // nil if the chain is not of the bft consensus
 	log           *log.SeeleLog
-	Coinbase      common.Address // account address that mining rewards will be send to.
+	Coinbase      common.Address       // account address that mining rewards will be send to.
+	escrow        *types.EscrowAccount // escrow account as the Coinbase, nil if disabled.
 
 	txPool         *core.TransactionPool
+	txFilters      *pendingTxFilters // filters of the new txs accepted by txPool
 	chain          *core.Blockchain
 	chainDB        database.Database // database used to store blocks.
 	accountStateDB database.Database // database used to store account state info.
 	miner          *miner.Miner
+	stratum        *miner.StratumServer // nil if the stratum server is disabled
+	labels         *label.Store         // local labels of txs and accounts, persisted in chainDB.
+
+	snapshotPublisher *snapshot.Publisher
+	backuper          *backup.Backuper
+	balanceWatcher    *balance.Watcher
+	firehose          *firehose.Firehose
+	logIndexer        *logindex.Indexer        // nil if the log indices are disabled
+	hashrateHistory   *hashrate.History        // difficulty history, persisted in chainDB.
+	scheduler         *scheduler.Scheduler     // scheduled txs, persisted in chainDB.
+	apiKeys           *apikey.Manager          // API keys of the public RPC, persisted in chainDB.
+	observers         []core.ExecutionObserver // observers loaded from the execution plugins
 }
 
 // ServiceContext is a collection of service configuration inherited from node
@@ -45,7 +77,11 @@ func (s *SeeleService) TxPool() *core.TransactionPool { return s.txPool }
 func (s *SeeleService) BlockChain() *core.Blockchain  { return s.chain }
 func (s *SeeleService) NetVersion() uint64            { return s.networkID }
 func (s *SeeleService) Miner() *miner.Miner           { return s.miner }
+func (s *SeeleService) Labels() *label.Store          { return s.labels }
 func (s *SeeleService) GetCoinbase() common.Address   { return s.Coinbase }
+
+// APIKeys implements node.KeyService, returning the authorizer of the API keys.
+func (s *SeeleService) APIKeys() rpc.KeyAuthorizer { return s.apiKeys }
 func (s *SeeleService) Downloader() *downloader.Downloader {
 	return s.seeleProtocol.Downloader()
 }
@@ -57,29 +93,43 @@ func NewSeeleService(ctx context.Context, conf *Config, log *log.SeeleLog) (s *S
 		log:       log,
 	}
 	s.Coinbase = conf.Coinbase
+	if s.escrow = conf.Escrow; s.escrow != nil {
+		s.Coinbase = s.escrow.Address()
+		log.Info("mining rewards are paid to the escrow account %s", s.Coinbase.ToHex())
+	}
 	serviceContext := ctx.Value("ServiceContext").(ServiceContext)
+	s.dataDir = serviceContext.DataDir
 
-	// Initialize blockchain DB.
-	chainDBPath := filepath.Join(serviceContext.DataDir, BlockChainDir)
-	log.Info("NewSeeleService BlockChain datadir is %s", chainDBPath)
-	s.chainDB, err = leveldb.NewLevelDB(chainDBPath)
-	if err != nil {
-		log.Error("NewSeeleService Create BlockChain err. %s", err)
-		return nil, err
-	}
+	if conf.MemoryDB {
+		// the chain, state and indices are lost once the node stops
+		log.Info("NewSeeleService BlockChain and account state are in memory")
+		s.chainDB = leveldb.NewMemDB()
+		s.accountStateDB = leveldb.NewMemDB()
+	} else {
+		// Initialize blockchain DB.
+		chainDBPath := filepath.Join(serviceContext.DataDir, BlockChainDir)
+		log.Info("NewSeeleService BlockChain datadir is %s", chainDBPath)
+		s.chainDB, err = leveldb.NewLevelDB(chainDBPath)
+		if err != nil {
+			log.Error("NewSeeleService Create BlockChain err. %s", err)
+			return nil, err
+		}
 
-	// Initialize account state info DB.
-	accountStateDBPath := filepath.Join(serviceContext.DataDir, AccountStateDir)
-	log.Info("NewSeeleService account state datadir is %s", accountStateDBPath)
-	s.accountStateDB, err = leveldb.NewLevelDB(accountStateDBPath)
-	if err != nil {
-		s.chainDB.Close()
-		log.Error("NewSeeleService Create BlockChain err: failed to create account state DB, %s", err)
-		return nil, err
+		// Initialize account state info DB.
+		accountStateDBPath := filepath.Join(serviceContext.DataDir, AccountStateDir)
+		log.Info("NewSeeleService account state datadir is %s", accountStateDBPath)
+		s.accountStateDB, err = leveldb.NewLevelDB(accountStateDBPath)
+		if err != nil {
+			s.chainDB.Close()
+			log.Error("NewSeeleService Create BlockChain err: failed to create account state DB, %s", err)
+			return nil, err
+		}
 	}
 
+	s.labels = label.NewStore(s.chainDB)
+
 	bcStore := store.NewBlockchainDatabase(s.chainDB)
-	genesis := core.GetGenesis(conf.GenesisAccounts)
+	genesis := core.NewGenesis(conf.GenesisAccounts, conf.GenesisDifficulty)
 	err = genesis.InitializeAndValidate(bcStore, s.accountStateDB)
 	if err != nil {
 		s.chainDB.Close()
@@ -96,7 +146,145 @@ func NewSeeleService(ctx context.Context, conf *Config, log *log.SeeleLog) (s *S
 		return nil, err
 	}
 
+	if conf.DebugStateDiff {
+		s.chain.EnableStateDiff(log)
+	}
+
+	if conf.MaxReorgDepth > 0 {
+		s.chain.SetMaxReorgDepth(conf.MaxReorgDepth, log)
+	}
+
+	if conf.FinalityDepth > 0 || conf.CheckpointAuthority != nil {
+		s.chain.SetFinality(conf.FinalityDepth, conf.CheckpointAuthority)
+	}
+
+	chainConf := conf.ChainConf
+	if chainConf == nil {
+		chainConf = types.DefaultChainConfig()
+	}
+
+	if err = chainConf.Validate(); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService invalid chain config. %s", err)
+		return nil, err
+	}
+	s.chain.SetChainConfig(chainConf)
+
+	difficultyConf := conf.DifficultyConf
+	if difficultyConf == nil && conf.DevSeal {
+		difficultyConf = miner.DevDifficultyConfig()
+	} else if difficultyConf == nil {
+		difficultyConf = pow.DefaultDifficultyConfig()
+	}
+
+	if err = difficultyConf.Validate(); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService invalid difficulty config. %s", err)
+		return nil, err
+	}
+	s.chain.SetDifficultyConfig(difficultyConf)
+
+	rewardConf := conf.RewardConf
+	if rewardConf == nil {
+		rewardConf = pow.DefaultRewardConfig()
+	}
+
+	if err = rewardConf.Validate(); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService invalid reward config. %s", err)
+		return nil, err
+	}
+	s.chain.SetRewardConfig(rewardConf)
+
+	switch conf.PowAlgorithm {
+	case "", pow.Algorithm:
+	case memhash.Algorithm:
+		engine, err := newMemHashEngine(conf, difficultyConf, rewardConf, log)
+		if err != nil {
+			s.chainDB.Close()
+			s.accountStateDB.Close()
+			log.Error("NewSeeleService create memhash engine err. %s", err)
+			return nil, err
+		}
+
+		s.chain.SetEngine(engine)
+	case bft.Algorithm:
+		engine, err := newBftEngine(conf, rewardConf, log)
+		if err != nil {
+			s.chainDB.Close()
+			s.accountStateDB.Close()
+			log.Error("NewSeeleService create bft engine err. %s", err)
+			return nil, err
+		}
+
+		s.chain.SetEngine(engine)
+		s.bft = newBftProtocol(engine, log)
+	default:
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		return nil, fmt.Errorf("unsupported POW algorithm %s", conf.PowAlgorithm)
+	}
+
+	// txs signed since SigHashV1 are bound to the network id
+	sigHashRules := types.DefaultSigHashRules(conf.NetworkID)
+	if len(conf.SigHashForks) > 0 {
+		sigHashRules.Forks = conf.SigHashForks
+	}
+	s.chain.SetSigHashRules(sigHashRules)
+	s.telemetry = newTelemetry(conf.ClientVersion, forkHeights(sigHashRules), s.chain, conf.NetworkReportURL, log)
+
+	if s.observers, err = loadExecutionPlugins(conf.ExecutionPlugins); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService load execution plugins err. %s", err)
+		return nil, err
+	}
+
 	s.txPool = core.NewTransactionPool(conf.TxConf, s.chain)
+	if replayed, err := s.txPool.LoadJournal(); err != nil {
+		log.Warn("NewSeeleService failed to load the tx journal, %s", err)
+	} else if replayed > 0 {
+		log.Info("NewSeeleService replayed %d local txs from the journal", replayed)
+	}
+	s.txFilters = newPendingTxFilters(s.txPool)
+	s.balanceWatcher = balance.NewWatcher(s.chain, s.accountStateDB, log)
+	s.firehose = firehose.New(s.chain, s.accountStateDB)
+
+	if conf.LogIndex {
+		if s.logIndexer, err = logindex.NewIndexer(s.firehose, s.chainDB, log); err != nil {
+			s.chainDB.Close()
+			s.accountStateDB.Close()
+			log.Error("NewSeeleService create log indexer err. %s", err)
+			return nil, err
+		}
+	}
+
+	if s.hashrateHistory, err = hashrate.NewHistory(bcStore, s.chainDB, log); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService create difficulty history err. %s", err)
+		return nil, err
+	}
+
+	if s.scheduler, err = scheduler.New(s.chainDB, s.chain, s.txPool, conf.NetworkID, log); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService create scheduler err. %s", err)
+		return nil, err
+	}
+	s.scheduler.SetDefaultSigner(conf.ExternalSigner, conf.ExternalSignerSecret)
+	s.scheduler.SetSpendingPolicy(scheduler.NewSpendingPolicy(conf.SpendingWindow, conf.SpendingLimits))
+
+	if s.apiKeys, err = apikey.NewManager(s.chainDB, log); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		log.Error("NewSeeleService create API key manager err. %s", err)
+		return nil, err
+	}
+
 	s.seeleProtocol, err = NewSeeleProtocol(s, log)
 	if err != nil {
 		s.chainDB.Close()
@@ -104,16 +292,101 @@ func NewSeeleService(ctx context.Context, conf *Config, log *log.SeeleLog) (s *S
 		log.Error("NewSeeleService create seeleProtocol err. %s", err)
 		return nil, err
 	}
+	s.seeleProtocol.minSyncSubnets = conf.MinSyncSubnets
 
 	s.miner = miner.NewMiner(s.Coinbase, s, s.log)
+	s.miner.SetAffinity(conf.MinerAffinity)
+	s.miner.SetRecommitInterval(conf.MinerRecommit)
+	if conf.MinerSyncPauseBlocks > 0 {
+		s.miner.SetSyncPauseBlocks(conf.MinerSyncPauseBlocks)
+	}
+	if len(conf.MinerEnginePlugin) > 0 {
+		engine, err := loadMinerEngine(conf.MinerEnginePlugin)
+		if err != nil {
+			s.chainDB.Close()
+			s.accountStateDB.Close()
+			log.Error("NewSeeleService load miner engine plugin err. %s", err)
+			return nil, err
+		}
+
+		s.miner.SetEngine(engine)
+	} else if conf.DevSeal {
+		s.miner.SetEngine(&miner.DevEngine{})
+	}
+
+	if err = s.miner.SetCoinbaseExtra(conf.CoinbaseExtra); err != nil {
+		s.chainDB.Close()
+		s.accountStateDB.Close()
+		return nil, err
+	}
+
+	if conf.StratumConf.Addr != "" {
+		if s.stratum, err = miner.NewStratumServer(s.miner, conf.StratumConf, log); err != nil {
+			s.chainDB.Close()
+			s.accountStateDB.Close()
+			return nil, err
+		}
+	}
+
+	if conf.SnapshotConf.Enabled() {
+		s.snapshotPublisher = snapshot.NewPublisher(s.chain, s.accountStateDB, conf.SnapshotConf, log)
+	}
+
+	s.backuper = backup.NewBackuper(s.chain, s.chainDB, s.accountStateDB, conf.BackupConf, log)
 
 	return s, nil
 }
 
+// newMemHashEngine creates the consensus engine of the memhash algorithm, which seals the blocks
+// by itself, so that the miners of the header hashing are not supported.
+func newMemHashEngine(conf *Config, difficulty *pow.DifficultyConfig, reward *pow.RewardConfig, log *log.SeeleLog) (*memhash.Engine, error) {
+	if conf.DevSeal || len(conf.MinerEnginePlugin) > 0 || conf.StratumConf.Addr != "" {
+		return nil, fmt.Errorf("dev seal, miner engine plugin and stratum server are not supported by %s", memhash.Algorithm)
+	}
+
+	return memhash.NewEngine(difficulty, reward, conf.MemHashConf, log)
+}
+
+// newBftEngine creates the consensus engine of the bft validators, which seals the blocks once
+// committed by the quorum, so that the miners of the header hashing are not supported.
+func newBftEngine(conf *Config, reward *pow.RewardConfig, log *log.SeeleLog) (*bft.Engine, error) {
+	if conf.DevSeal || len(conf.MinerEnginePlugin) > 0 || conf.StratumConf.Addr != "" {
+		return nil, fmt.Errorf("dev seal, miner engine plugin and stratum server are not supported by %s", bft.Algorithm)
+	}
+
+	return bft.NewEngine(conf.BftConf, reward, conf.BftKey, log)
+}
+
+// BootstrapFromURL downloads the signed chain snapshot from the specified URL into the
+// data folder, verifies it against the trusted publisher and imports it into the blockchain.
+// It should be called before the service starts to join the p2p network.
+func (s *SeeleService) BootstrapFromURL(url string, publisher common.Address) error {
+	file := filepath.Join(s.dataDir, snapshot.FileName)
+	defer os.Remove(file)
+
+	s.log.Info("downloading snapshot from %s", url)
+	if err := snapshot.Download(url, file); err != nil {
+		return err
+	}
+
+	imported, err := snapshot.Import(s.chain, s.accountStateDB, file, publisher)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("snapshot imported, height:%d, hash:%s", imported.Height, imported.HeadHash.ToHex())
+
+	return nil
+}
+
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *SeeleService) Protocols() (protos []p2p.Protocol) {
-	protos = append(protos, s.seeleProtocol.Protocol)
+	protos = append(protos, s.seeleProtocol.Protocol, s.telemetry.Protocol)
+	if s.bft != nil {
+		protos = append(protos, s.bft.Protocol)
+	}
+
 	return
 }
 
@@ -121,16 +394,107 @@ func (s *SeeleService) Protocols() (protos []p2p.Protocol) {
 func (s *SeeleService) Start(srvr *p2p.Server) error {
 	s.p2pServer = srvr
 
+	// observe the blocks synchronized once the protocol starts
+	for _, observer := range s.observers {
+		core.RegisterExecutionObserver(observer)
+	}
+
 	s.seeleProtocol.Start()
+	s.balanceWatcher.Start()
+	s.hashrateHistory.Start()
+
+	if s.logIndexer != nil {
+		s.logIndexer.Start()
+	}
+
+	if s.snapshotPublisher != nil {
+		if err := s.snapshotPublisher.Start(); err != nil {
+			if s.logIndexer != nil {
+				s.logIndexer.Stop()
+			}
+
+			s.hashrateHistory.Stop()
+			s.balanceWatcher.Stop()
+			s.seeleProtocol.Stop()
+
+			for _, observer := range s.observers {
+				core.UnregisterExecutionObserver(observer)
+			}
+
+			return err
+		}
+	}
+
+	if s.stratum != nil {
+		if err := s.stratum.Start(); err != nil {
+			if s.snapshotPublisher != nil {
+				s.snapshotPublisher.Stop()
+			}
+
+			if s.logIndexer != nil {
+				s.logIndexer.Stop()
+			}
+
+			s.hashrateHistory.Stop()
+			s.balanceWatcher.Stop()
+			s.seeleProtocol.Stop()
+
+			for _, observer := range s.observers {
+				core.UnregisterExecutionObserver(observer)
+			}
+
+			return err
+		}
+	}
+
+	s.backuper.Start()
+	s.scheduler.Start()
+	s.apiKeys.Start()
+	s.txFilters.start()
+	s.telemetry.start(s.seeleProtocol.peerSet)
+
+	if s.bft != nil {
+		s.bft.engine.Start(s.chain, s.bft)
+	}
+
 	return nil
 }
 
 // Stop implements node.Service, terminating all internal goroutines.
 func (s *SeeleService) Stop() error {
+	if s.bft != nil {
+		s.bft.engine.Stop()
+	}
+
+	s.telemetry.stop()
+	s.txFilters.stop()
+	s.apiKeys.Stop()
+	s.scheduler.Stop()
+	s.backuper.Stop()
+
+	if s.stratum != nil {
+		s.stratum.Stop()
+	}
+
+	if s.snapshotPublisher != nil {
+		s.snapshotPublisher.Stop()
+	}
+
+	if s.logIndexer != nil {
+		s.logIndexer.Stop()
+	}
+
+	for _, observer := range s.observers {
+		core.UnregisterExecutionObserver(observer)
+	}
+
+	s.hashrateHistory.Stop()
+	s.balanceWatcher.Stop()
 	s.seeleProtocol.Stop()
+	s.txPool.Stop()
 
 	//TODO
-	// s.txPool.Stop() s.chain.Stop()
+	// s.chain.Stop()
 	// retries? leave it to future
 	s.chainDB.Close()
 	s.accountStateDB.Close()
@@ -145,6 +509,14 @@ func (s *SeeleService) APIs() (apis []rpc.API) {
 			Version:   "1.0",
 			Service:   NewPublicSeeleAPI(s),
 			Public:    true,
+			StampMethods: map[string]rpc.StampHasher{
+				"AddTx": txStampHasher,
+			},
+			CacheMethods: map[string]rpc.CacheHeight{
+				"GetBlockByHeight": cacheHeightOfBlock,
+				"GetBlockByHash":   cacheHeightOfBlock,
+				"GetReceiptProof":  s.cacheHeightOfReceiptProof,
+			},
 		},
 		{
 			Namespace: "download",
@@ -163,6 +535,45 @@ func (s *SeeleService) APIs() (apis []rpc.API) {
 			Version:   "1.0",
 			Service:   NewPublicDebugAPI(s),
 			Public:    true,
+			CacheMethods: map[string]rpc.CacheHeight{
+				"GetBlockRlp": cacheHeightOfRequest,
+			},
+		},
+		{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   NewPrivateDebugAPI(s),
+			Public:    false,
+		},
+		{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewPrivateAdminAPI(s),
+			Public:    false,
+		},
+		{
+			Namespace: "scheduler",
+			Version:   "1.0",
+			Service:   scheduler.NewPrivateSchedulerAPI(s.scheduler),
+			Public:    false,
+		},
+		{
+			Namespace: "firehose",
+			Version:   "1.0",
+			Service:   firehose.NewPublicFirehoseAPI(s.firehose),
+			Public:    true,
+		},
+		{
+			Namespace: "label",
+			Version:   "1.0",
+			Service:   NewPublicLabelAPI(s),
+			Public:    true,
+		},
+		{
+			Namespace: "label",
+			Version:   "1.0",
+			Service:   NewPrivateLabelAPI(s),
+			Public:    false,
 		},
 		{
 			Namespace: "miner",
@@ -170,5 +581,11 @@ func (s *SeeleService) APIs() (apis []rpc.API) {
 			Service:   NewPublicMinerAPI(s),
 			Public:    true,
 		},
+		{
+			Namespace: "txpool",
+			Version:   "1.0",
+			Service:   NewPublicTransactionPoolAPI(s),
+			Public:    true,
+		},
 	}...)
 }
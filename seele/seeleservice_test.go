@@ -0,0 +1,77 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/consensus/memhash"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/log"
+)
+
+func Test_SeeleService_MemoryDB(t *testing.T) {
+	addr, key, err := crypto.GenerateKeyPair()
+	assert.Equal(t, err, error(nil))
+
+	conf := getTmpConfig()
+	conf.MemoryDB = true
+	conf.DevSeal = true
+	conf.GenesisAccounts = map[common.Address]*big.Int{*addr: big.NewInt(1000000)}
+
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err, error(nil))
+
+	// nothing is persisted in the data folder
+	_, err = os.Stat(filepath.Join(dataDir, BlockChainDir))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	// the chain and state work as usual
+	tx := types.NewTransaction(*addr, *crypto.MustGenerateRandomAddress(), big.NewInt(1), big.NewInt(1), types.TransferGas, 0)
+	tx.Data.ChainID = conf.NetworkID
+	tx.Sign(key)
+	assert.Equal(t, ss.TxPool().AddTransaction(tx), error(nil))
+
+	block, statedb := ss.BlockChain().CurrentBlock()
+	assert.Equal(t, block.Header.Difficulty, big.NewInt(1))
+	assert.Equal(t, statedb.GetBalance(*addr), big.NewInt(1000000))
+}
+
+func Test_SeeleService_PowAlgorithm(t *testing.T) {
+	dataDir := common.GetTempFolder()
+	defer os.RemoveAll(dataDir)
+	ctx := context.WithValue(context.Background(), "ServiceContext", ServiceContext{DataDir: dataDir})
+
+	conf := getTmpConfig()
+	conf.MemoryDB = true
+	conf.PowAlgorithm = memhash.Algorithm
+	conf.MemHashConf = &memhash.Config{CacheSize: 1024, DatasetSize: 8192}
+	ss, err := NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err, error(nil))
+	_, ok := ss.BlockChain().Engine().(*memhash.Engine)
+	assert.Equal(t, ok, true)
+
+	// the dev seal mines with the header hashing
+	conf.DevSeal = true
+	_, err = NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err != nil, true)
+
+	conf.DevSeal = false
+	conf.PowAlgorithm = "unknown"
+	_, err = NewSeeleService(ctx, conf, log.GetLogger("seele", true))
+	assert.Equal(t, err != nil, true)
+}
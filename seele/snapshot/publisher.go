@@ -0,0 +1,249 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package snapshot
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/log"
+)
+
+const (
+	// FileName is the name of the snapshot archive in the publish folder.
+	FileName = "snapshot.rlp"
+
+	// HTTPPath is the HTTP path to download the latest snapshot archive.
+	HTTPPath = "/snapshot"
+
+	defaultPublishInterval = 10 * time.Minute
+)
+
+var errSnapshotNotReady = errors.New("snapshot is not published yet")
+
+// Config is the configuration of the snapshot publisher.
+type Config struct {
+	// PublishDir is the folder to write the latest snapshot archive, empty to write
+	// into a temp folder which is removed once the publisher stops.
+	PublishDir string
+
+	// PublishAddr is the HTTP address to serve the latest snapshot archive, empty to disable.
+	PublishAddr string
+
+	// PublishInterval is the interval to publish a new snapshot, default is 10 minutes.
+	PublishInterval time.Duration
+
+	// PrivateKey is the key to sign the published snapshots.
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// Enabled indicates whether the snapshot publisher should be started.
+func (config *Config) Enabled() bool {
+	return config.PrivateKey != nil && (len(config.PublishDir) > 0 || len(config.PublishAddr) > 0)
+}
+
+// Publisher periodically publishes the signed snapshot of the chain
+// to a local folder and/or a HTTP endpoint.
+type Publisher struct {
+	chain          blockchain
+	accountStateDB database.Database
+	config         Config
+	log            *log.SeeleLog
+
+	tempDir  string // temp publish folder if PublishDir is not configured
+	lock     sync.RWMutex
+	latest   string // latest published archive file, empty if not published yet
+	listener net.Listener
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPublisher creates a snapshot publisher of the specified chain.
+func NewPublisher(chain blockchain, accountStateDB database.Database, config Config, log *log.SeeleLog) *Publisher {
+	if config.PublishInterval <= 0 {
+		config.PublishInterval = defaultPublishInterval
+	}
+
+	return &Publisher{
+		chain:          chain,
+		accountStateDB: accountStateDB,
+		config:         config,
+		log:            log,
+		quit:           make(chan struct{}),
+	}
+}
+
+// Start starts the HTTP endpoint if configured and the publish loop.
+func (p *Publisher) Start() error {
+	if len(p.config.PublishAddr) > 0 {
+		listener, err := net.Listen("tcp", p.config.PublishAddr)
+		if err != nil {
+			return err
+		}
+
+		p.listener = listener
+
+		mux := http.NewServeMux()
+		mux.Handle(HTTPPath, p)
+		go http.Serve(listener, mux)
+	}
+
+	p.wg.Add(1)
+	go p.loop()
+
+	return nil
+}
+
+// Stop terminates the publish loop and the HTTP endpoint.
+func (p *Publisher) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+
+	if p.listener != nil {
+		p.listener.Close()
+	}
+
+	if len(p.tempDir) > 0 {
+		os.RemoveAll(p.tempDir)
+	}
+}
+
+func (p *Publisher) loop() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.PublishInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Publish(); err != nil {
+			p.log.Warn("failed to publish snapshot, %s", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// Publish creates, signs and publishes a snapshot of the current chain. The archive is
+// written into a temp file and then renamed, so that the archive being served is intact.
+func (p *Publisher) Publish() error {
+	dir, err := p.publishDir()
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, FileName)
+	signed, err := New(p.chain, p.accountStateDB, file, p.config.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	p.latest = file
+	p.lock.Unlock()
+
+	p.log.Info("snapshot published, height:%d, hash:%s", signed.Snapshot.Height, signed.Snapshot.HeadHash.ToHex())
+
+	return nil
+}
+
+// publishDir returns the folder to publish the snapshot archive, which is created if not exists.
+func (p *Publisher) publishDir() (string, error) {
+	if len(p.config.PublishDir) > 0 {
+		return p.config.PublishDir, os.MkdirAll(p.config.PublishDir, os.ModePerm)
+	}
+
+	if len(p.tempDir) == 0 {
+		dir, err := ioutil.TempDir("", "snapshot")
+		if err != nil {
+			return "", err
+		}
+
+		p.tempDir = dir
+	}
+
+	return p.tempDir, nil
+}
+
+// ServeHTTP implements the http.Handler to serve the latest snapshot archive.
+func (p *Publisher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	p.lock.RLock()
+	latest := p.latest
+	p.lock.RUnlock()
+
+	if len(latest) == 0 {
+		http.Error(w, errSnapshotNotReady.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	// the opened archive is still readable even if replaced by a newly published one
+	f, err := os.Open(latest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, FileName, stat.ModTime(), f)
+}
+
+// Download streams the snapshot archive from the specified URL into the specified file.
+// Note, the snapshot is not verified and should be imported via Import.
+func Download(url, file string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download snapshot, status: %s", resp.Status)
+	}
+
+	tmpFile := file + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
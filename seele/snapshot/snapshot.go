@@ -0,0 +1,474 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package snapshot
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/crypto/sha3"
+	"github.com/seeleteam/go-seele/database"
+)
+
+var (
+	// ErrSignatureMissing is returned when the snapshot is not signed.
+	ErrSignatureMissing = errors.New("snapshot signature missing")
+
+	// ErrSignatureInvalid is returned when the snapshot signature does not match the publisher.
+	ErrSignatureInvalid = errors.New("snapshot signature is invalid")
+
+	// ErrUntrustedPublisher is returned when the snapshot is signed by an unexpected publisher.
+	ErrUntrustedPublisher = errors.New("snapshot publisher is not trusted")
+
+	// ErrGenesisMismatch is returned when the snapshot is built on a different genesis block.
+	ErrGenesisMismatch = errors.New("snapshot genesis block mismatch")
+
+	// ErrContentHashMismatch is returned when the archived blocks and accounts do not match the snapshot content hash.
+	ErrContentHashMismatch = errors.New("snapshot content hash mismatch")
+
+	// ErrBlockInvalid is returned when the archived block does not link to its parent block.
+	ErrBlockInvalid = errors.New("snapshot block is invalid")
+
+	// ErrHeadMismatch is returned when the imported HEAD block does not match the snapshot HEAD.
+	ErrHeadMismatch = errors.New("snapshot HEAD block mismatch")
+
+	// ErrStateRootMismatch is returned when the imported state root does not match the snapshot state root.
+	ErrStateRootMismatch = errors.New("snapshot state root mismatch")
+
+	errManifestMissing       = errors.New("snapshot manifest missing")
+	errCanonicalChainChanged = errors.New("canonical chain changed while the snapshot is taken")
+)
+
+type blockchain interface {
+	CurrentBlock() (*types.Block, *state.Statedb)
+	GetStore() store.BlockchainStore
+	SetHead(hash common.Hash) error
+}
+
+// Snapshot is the manifest of a chain archive, which is followed by the canonical blocks
+// after genesis in height ASC order and then the accounts of the HEAD state. The blocks are
+// imported without replay, and the account state is rebuilt from the archived accounts,
+// which is then checked against the StateHash recorded by the publisher.
+type Snapshot struct {
+	GenesisHash common.Hash // GenesisHash is the hash of the genesis block the snapshot is built on
+	HeadHash    common.Hash // HeadHash is the hash of the HEAD block when the snapshot is taken
+	Height      uint64      // Height is the height of the HEAD block
+	StateHash   common.Hash // StateHash is the state root hash of the HEAD block
+	Accounts    uint64      // Accounts is the number of accounts in the HEAD state
+	ContentHash common.Hash // ContentHash is the hash of the encoded blocks and accounts following the manifest
+}
+
+// SignedSnapshot wraps a snapshot with the signature of its publisher.
+type SignedSnapshot struct {
+	Snapshot  *Snapshot         // Snapshot is the signed archive manifest
+	Publisher common.Address    // Publisher is the address of the key that signs the snapshot
+	Signature *crypto.Signature `rlp:"nil"` // Signature is the publisher signature of the snapshot hash, nil if not signed
+}
+
+// blockRecord is a canonical block in the archive with its receipts.
+type blockRecord struct {
+	Block    *types.Block
+	Receipts []*types.Receipt
+}
+
+// accountRecord is an account of the HEAD state in the archive.
+type accountRecord struct {
+	Address common.Address
+	Account *state.Account
+	Code    []byte
+}
+
+// New writes the archive of the canonical chain up to the current HEAD block into the
+// specified file, and signs it with the private key of the publisher if not nil. Blocks
+// and accounts are streamed to the disk one by one, so the chain is never held in memory.
+func New(chain blockchain, accountStateDB database.Database, file string, privKey *ecdsa.PrivateKey) (*SignedSnapshot, error) {
+	bcStore := chain.GetStore()
+
+	genesisHash, err := bcStore.GetBlockHash(0)
+	if err != nil {
+		return nil, err
+	}
+
+	head, _ := chain.CurrentBlock()
+	statedb, err := state.NewStatedb(head.Header.StateHash, accountStateDB)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		GenesisHash: genesisHash,
+		HeadHash:    head.HeaderHash,
+		Height:      head.Header.Height,
+		StateHash:   head.Header.StateHash,
+	}
+
+	contentFile := file + ".content.tmp"
+	defer os.Remove(contentFile)
+
+	if err = snapshot.writeContent(bcStore, statedb, contentFile); err != nil {
+		return nil, err
+	}
+
+	signed := &SignedSnapshot{Snapshot: snapshot}
+	if privKey != nil {
+		signed = snapshot.Sign(privKey)
+	}
+
+	if err = writeArchive(signed, contentFile, file); err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+// writeContent writes the blocks and accounts of the snapshot into the specified file,
+// and fills the number of accounts and the content hash of the snapshot.
+func (snapshot *Snapshot) writeContent(bcStore store.BlockchainStore, statedb *state.Statedb, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hasher := sha3.NewKeccak256()
+	w := bufio.NewWriter(io.MultiWriter(f, hasher))
+
+	prevHash := snapshot.GenesisHash
+	for height := uint64(1); height <= snapshot.Height; height++ {
+		block, err := bcStore.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+
+		// the height-to-hash mappings are overwritten on chain reorganization
+		if !block.Header.PreviousBlockHash.Equal(prevHash) {
+			return errCanonicalChainChanged
+		}
+
+		receipts, err := bcStore.GetReceipts(block.HeaderHash)
+		if err != nil {
+			return err
+		}
+
+		if err = rlp.Encode(w, &blockRecord{block, receipts}); err != nil {
+			return err
+		}
+
+		prevHash = block.HeaderHash
+	}
+
+	if !prevHash.Equal(snapshot.HeadHash) {
+		return errCanonicalChainChanged
+	}
+
+	snapshot.Accounts = 0
+	err = statedb.ForEachAccount(func(addr common.Address, account *state.Account, code []byte) error {
+		snapshot.Accounts++
+		return rlp.Encode(w, &accountRecord{addr, account, code})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	snapshot.ContentHash = common.BytesToHash(hasher.Sum(nil))
+
+	return nil
+}
+
+// writeArchive atomically writes the signed manifest followed by the content file into the archive file.
+func writeArchive(signed *SignedSnapshot, contentFile, file string) error {
+	content, err := os.Open(contentFile)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	tmpFile := file + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+
+	w := bufio.NewWriter(f)
+	if err = rlp.Encode(w, signed); err == nil {
+		if _, err = io.Copy(w, content); err == nil {
+			err = w.Flush()
+		}
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
+
+// Hash returns the hash of the snapshot which is signed by the publisher.
+func (snapshot *Snapshot) Hash() common.Hash {
+	return crypto.MustHash(snapshot)
+}
+
+// Sign signs the snapshot with the specified private key of the publisher.
+func (snapshot *Snapshot) Sign(privKey *ecdsa.PrivateKey) *SignedSnapshot {
+	return &SignedSnapshot{
+		Snapshot:  snapshot,
+		Publisher: *crypto.MustGetAddress(privKey),
+		Signature: crypto.NewSignature(privKey, snapshot.Hash().Bytes()),
+	}
+}
+
+// Verify verifies the signature of the snapshot against the trusted publisher.
+func (signed *SignedSnapshot) Verify(trusted common.Address) error {
+	if signed.Snapshot == nil || signed.Signature == nil {
+		return ErrSignatureMissing
+	}
+
+	if !signed.Publisher.Equal(trusted) {
+		return ErrUntrustedPublisher
+	}
+
+	if !signed.Signature.Verify(&signed.Publisher, signed.Snapshot.Hash().Bytes()) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// Open reads the signed manifest of the archive in the specified file.
+func Open(file string) (*SignedSnapshot, error) {
+	f, _, signed, err := openArchive(file)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Close()
+
+	return signed, nil
+}
+
+// openArchive opens the archive file, and returns the stream positioned at the archive content.
+func openArchive(file string) (*os.File, *bufio.Reader, *SignedSnapshot, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	r := bufio.NewReader(f)
+	signed := new(SignedSnapshot)
+	if err = rlp.NewStream(r, 0).Decode(signed); err != nil {
+		f.Close()
+		return nil, nil, nil, err
+	}
+
+	if signed.Snapshot == nil {
+		f.Close()
+		return nil, nil, nil, errManifestMissing
+	}
+
+	return f, r, signed, nil
+}
+
+// Import verifies the archive in the specified file against the trusted publisher,
+// and imports it into the specified chain.
+func Import(chain blockchain, accountStateDB database.Database, file string, trusted common.Address) (*Snapshot, error) {
+	signed, err := Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = signed.Verify(trusted); err != nil {
+		return nil, err
+	}
+
+	return signed.Snapshot, restore(chain, accountStateDB, file, signed.Snapshot)
+}
+
+// Restore imports the archive in the specified file into the specified chain without any
+// signature verification, so it should only be used for the snapshots created locally.
+func Restore(chain blockchain, accountStateDB database.Database, file string) (*Snapshot, error) {
+	signed, err := Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return signed.Snapshot, restore(chain, accountStateDB, file, signed.Snapshot)
+}
+
+// restore writes the archived blocks into the chain store without replay, rebuilds the
+// HEAD state from the archived accounts and then switches the HEAD block of the chain.
+// Blocks that already exist in the chain are skipped, and it is a no-op if the chain
+// is already at the snapshot HEAD.
+func restore(chain blockchain, accountStateDB database.Database, file string, snapshot *Snapshot) error {
+	bcStore := chain.GetStore()
+
+	genesisHash, err := bcStore.GetBlockHash(0)
+	if err != nil {
+		return err
+	}
+
+	if !genesisHash.Equal(snapshot.GenesisHash) {
+		return ErrGenesisMismatch
+	}
+
+	if head, _ := chain.CurrentBlock(); head.HeaderHash.Equal(snapshot.HeadHash) {
+		return nil
+	}
+
+	// verify the content before anything is written into the chain
+	if err = verifyContent(file, snapshot); err != nil {
+		return err
+	}
+
+	f, r, _, err := openArchive(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stream := rlp.NewStream(r, 0)
+	if err = restoreBlocks(bcStore, stream, snapshot); err != nil {
+		return err
+	}
+
+	if err = restoreState(accountStateDB, stream, snapshot); err != nil {
+		return err
+	}
+
+	if err = chain.SetHead(snapshot.HeadHash); err != nil {
+		return err
+	}
+
+	head, _ := chain.CurrentBlock()
+	if !head.HeaderHash.Equal(snapshot.HeadHash) {
+		return ErrHeadMismatch
+	}
+
+	return nil
+}
+
+// verifyContent checks the archive content in the specified file against the snapshot content hash.
+func verifyContent(file string, snapshot *Snapshot) error {
+	f, r, _, err := openArchive(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hasher := sha3.NewKeccak256()
+	if _, err = io.Copy(hasher, r); err != nil {
+		return err
+	}
+
+	if !common.BytesToHash(hasher.Sum(nil)).Equal(snapshot.ContentHash) {
+		return ErrContentHashMismatch
+	}
+
+	return nil
+}
+
+// restoreBlocks writes the archived blocks with their receipts and total difficulties
+// into the chain store, but not as the canonical ones until the HEAD block is switched.
+func restoreBlocks(bcStore store.BlockchainStore, stream *rlp.Stream, snapshot *Snapshot) error {
+	prevHash := snapshot.GenesisHash
+	td, err := bcStore.GetBlockTotalDifficulty(prevHash)
+	if err != nil {
+		return err
+	}
+
+	for height := uint64(1); height <= snapshot.Height; height++ {
+		record := new(blockRecord)
+		if err = stream.Decode(record); err != nil {
+			return err
+		}
+
+		block := record.Block
+		if block == nil || block.Header == nil || block.Header.Height != height ||
+			!block.Header.PreviousBlockHash.Equal(prevHash) ||
+			!block.HeaderHash.Equal(block.Header.Hash()) ||
+			!types.MerkleRootHash(block.Transactions).Equal(block.Header.TxHash) {
+			return ErrBlockInvalid
+		}
+
+		td = new(big.Int).Add(td, block.Header.Difficulty)
+		prevHash = block.HeaderHash
+
+		exist, err := bcStore.HasBlock(block.HeaderHash)
+		if err != nil {
+			return err
+		}
+
+		if exist {
+			continue
+		}
+
+		if err = bcStore.PutBlock(block, td, false); err != nil {
+			return err
+		}
+
+		if err = bcStore.PutReceipts(block.HeaderHash, record.Receipts); err != nil {
+			return err
+		}
+	}
+
+	if !prevHash.Equal(snapshot.HeadHash) {
+		return ErrHeadMismatch
+	}
+
+	return nil
+}
+
+// restoreState rebuilds the HEAD state from the archived accounts.
+func restoreState(accountStateDB database.Database, stream *rlp.Stream, snapshot *Snapshot) error {
+	importer, err := state.NewImporter(accountStateDB)
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < snapshot.Accounts; i++ {
+		record := new(accountRecord)
+		if err = stream.Decode(record); err != nil {
+			return err
+		}
+
+		if record.Account == nil {
+			return ErrStateRootMismatch
+		}
+
+		if err = importer.Put(record.Address, record.Account, record.Code); err != nil {
+			return err
+		}
+	}
+
+	root, err := importer.Commit()
+	if err != nil {
+		return err
+	}
+
+	if !root.Equal(snapshot.StateHash) {
+		return ErrStateRootMismatch
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package snapshot
+
+import (
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/crypto"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+func newTestAccounts() map[common.Address]*big.Int {
+	return map[common.Address]*big.Int{
+		*crypto.MustGenerateRandomAddress(): big.NewInt(100),
+	}
+}
+
+// newTestChain creates a chain of a few blocks, and returns the genesis accounts of the
+// chain to create other chains of the same genesis.
+func newTestChain(t *testing.T) (*testutil.Chain, map[common.Address]*big.Int) {
+	accounts := newTestAccounts()
+	chain := testutil.NewChain(t, accounts)
+
+	chain.Fund(*crypto.MustGenerateRandomAddress(), big.NewInt(1000))
+	chain.MineBlocks(3)
+
+	alloc := map[common.Address]*big.Int{chain.Faucet().Address: testutil.FaucetBalance}
+	for addr, balance := range accounts {
+		alloc[addr] = balance
+	}
+
+	return chain, alloc
+}
+
+func newTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "snapshotFile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func Test_SignedSnapshot_Verify(t *testing.T) {
+	chain, _ := newTestChain(t)
+	defer chain.Close()
+
+	dir, dispose := newTestDir(t)
+	defer dispose()
+
+	file := filepath.Join(dir, FileName)
+	publisher, privKey, _ := crypto.GenerateKeyPair()
+	_, err := New(chain.Blockchain(), chain.Database(), file, privKey)
+	assert.Equal(t, err, error(nil))
+
+	signed, err := Open(file)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, signed.Snapshot.HeadHash, chain.Head().HeaderHash)
+	assert.Equal(t, signed.Verify(*publisher), error(nil))
+
+	// untrusted publisher
+	assert.Equal(t, signed.Verify(*crypto.MustGenerateRandomAddress()), ErrUntrustedPublisher)
+
+	// tampered snapshot
+	signed.Snapshot.Height++
+	assert.Equal(t, signed.Verify(*publisher), ErrSignatureInvalid)
+
+	// signature missing
+	signed.Signature = nil
+	assert.Equal(t, signed.Verify(*publisher), ErrSignatureMissing)
+}
+
+func Test_Import(t *testing.T) {
+	chain, alloc := newTestChain(t)
+	defer chain.Close()
+
+	dir, dispose := newTestDir(t)
+	defer dispose()
+
+	file := filepath.Join(dir, FileName)
+	publisher, privKey, _ := crypto.GenerateKeyPair()
+	_, err := New(chain.Blockchain(), chain.Database(), file, privKey)
+	assert.Equal(t, err, error(nil))
+
+	// import into the chain with the same genesis without replay
+	sameChain, sameDB, disposeSame := testutil.NewBlockchain(t, alloc)
+	defer disposeSame()
+
+	snapshot, err := Import(sameChain, sameDB, file, *publisher)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, snapshot.Height, uint64(3))
+
+	head, statedb := sameChain.CurrentBlock()
+	assert.Equal(t, head.HeaderHash, chain.Head().HeaderHash)
+	assert.Equal(t, statedb.GetBalance(chain.Coinbase().Address), chain.Balance(chain.Coinbase().Address))
+
+	// canonical blocks and receipts are available without replay
+	block, err := sameChain.GetStore().GetBlockByHeight(1)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(block.Transactions), 2)
+
+	receipt, err := chain.Receipt(block.Transactions[1].Hash)
+	assert.Equal(t, err, error(nil))
+	blockHash, err := sameChain.GetStore().GetReceiptBlockHash(receipt.TxHash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, blockHash, block.HeaderHash)
+
+	// import again
+	_, err = Import(sameChain, sameDB, file, *publisher)
+	assert.Equal(t, err, error(nil))
+
+	// import into the chain with a different genesis
+	otherChain, otherDB, disposeOther := testutil.NewBlockchain(t, newTestAccounts())
+	defer disposeOther()
+
+	_, err = Import(otherChain, otherDB, file, *publisher)
+	assert.Equal(t, err, ErrGenesisMismatch)
+}
+
+func Test_Import_ContentTampered(t *testing.T) {
+	chain, alloc := newTestChain(t)
+	defer chain.Close()
+
+	dir, dispose := newTestDir(t)
+	defer dispose()
+
+	file := filepath.Join(dir, FileName)
+	publisher, privKey, _ := crypto.GenerateKeyPair()
+	_, err := New(chain.Blockchain(), chain.Database(), file, privKey)
+	assert.Equal(t, err, error(nil))
+
+	encoded, err := ioutil.ReadFile(file)
+	assert.Equal(t, err, error(nil))
+	encoded[len(encoded)-1]++
+	assert.Equal(t, ioutil.WriteFile(file, encoded, 0644), error(nil))
+
+	sameChain, sameDB, disposeSame := testutil.NewBlockchain(t, alloc)
+	defer disposeSame()
+
+	_, err = Import(sameChain, sameDB, file, *publisher)
+	assert.Equal(t, err, ErrContentHashMismatch)
+
+	// nothing imported
+	head, _ := sameChain.CurrentBlock()
+	assert.Equal(t, head.Header.Height, uint64(0))
+}
+
+func Test_Restore(t *testing.T) {
+	chain, alloc := newTestChain(t)
+	defer chain.Close()
+
+	dir, dispose := newTestDir(t)
+	defer dispose()
+
+	file := filepath.Join(dir, FileName)
+	signed, err := New(chain.Blockchain(), chain.Database(), file, nil)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, signed.Signature == nil, true)
+
+	// restore into the chain with the same genesis
+	sameChain, sameDB, disposeSame := testutil.NewBlockchain(t, alloc)
+	defer disposeSame()
+
+	snapshot, err := Restore(sameChain, sameDB, file)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, snapshot.Hash(), signed.Snapshot.Hash())
+
+	head, _ := sameChain.CurrentBlock()
+	assert.Equal(t, head.HeaderHash, chain.Head().HeaderHash)
+
+	statedb, err := state.NewStatedb(head.Header.StateHash, sameDB)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, statedb.GetBalance(chain.Faucet().Address), chain.Balance(chain.Faucet().Address))
+}
+
+func Test_Publisher_Download(t *testing.T) {
+	chain, alloc := newTestChain(t)
+	defer chain.Close()
+
+	dir, dispose := newTestDir(t)
+	defer dispose()
+
+	publisher, privKey, _ := crypto.GenerateKeyPair()
+	config := Config{
+		PublishDir: filepath.Join(dir, "publish"),
+		PrivateKey: privKey,
+	}
+	p := NewPublisher(chain.Blockchain(), chain.Database(), config, log.GetLogger("snapshot", true))
+
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	// not published yet
+	file := filepath.Join(dir, FileName)
+	assert.Equal(t, Download(server.URL+HTTPPath, file) != nil, true)
+	assert.Equal(t, common.FileOrFolderExists(file), false)
+
+	assert.Equal(t, p.Publish(), error(nil))
+	assert.Equal(t, common.FileOrFolderExists(filepath.Join(config.PublishDir, FileName)), true)
+
+	assert.Equal(t, Download(server.URL+HTTPPath, file), error(nil))
+
+	sameChain, sameDB, disposeSame := testutil.NewBlockchain(t, alloc)
+	defer disposeSame()
+
+	_, err := Import(sameChain, sameDB, file, *publisher)
+	assert.Equal(t, err, error(nil))
+
+	head, _ := sameChain.CurrentBlock()
+	assert.Equal(t, head.HeaderHash, chain.Head().HeaderHash)
+}
@@ -0,0 +1,290 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/p2p"
+)
+
+const (
+	// telemetryProtoName is the sub-protocol to exchange the client version and fork id once the
+	// peers connect. It is a separate protocol so that the legacy peers, which do not advertise
+	// it, are still compatible and counted as unknown.
+	telemetryProtoName = "telemetry"
+
+	telemetryVersion uint = 1
+
+	nodeMetaMsgCode        uint16 = 0
+	telemetryMsgCodeLength uint16 = 1
+
+	// UnknownClientVersion is the client version of the peers without the telemetry protocol.
+	UnknownClientVersion = "unknown"
+
+	networkReportInterval = time.Hour
+	networkReportTimeout  = 30 * time.Second
+)
+
+// ForkID identifies the consensus rules of a chain, i.e. the CRC32 checksum of the genesis hash
+// and the fork heights passed, and the height of the next fork scheduled, 0 if none. Peers of
+// the same Hash but different Next have not upgraded to the same release for the next fork.
+type ForkID struct {
+	Hash uint32
+	Next uint64
+}
+
+// String returns the fork id in the form of <hash>/<next>.
+func (id ForkID) String() string {
+	return fmt.Sprintf("%08x/%d", id.Hash, id.Next)
+}
+
+// newForkID returns the fork id of the chain of the genesis hash and the fork heights in
+// ascending order at the specified height.
+func newForkID(genesis common.Hash, forks []uint64, height uint64) ForkID {
+	hash := crc32.ChecksumIEEE(genesis.Bytes())
+	for _, fork := range forks {
+		if fork > height {
+			return ForkID{hash, fork}
+		}
+
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], fork)
+		hash = crc32.Update(hash, crc32.IEEETable, buf[:])
+	}
+
+	return ForkID{hash, 0}
+}
+
+// forkHeights returns the distinct heights in ascending order to activate the sighash versions
+// after genesis, which are the forks of the chain.
+func forkHeights(rules *types.SigHashRules) []uint64 {
+	var forks []uint64
+	seen := make(map[uint64]bool)
+	for _, fork := range rules.Forks {
+		if fork.Height > 0 && !seen[fork.Height] {
+			seen[fork.Height] = true
+			forks = append(forks, fork.Height)
+		}
+	}
+
+	sort.Slice(forks, func(i, j int) bool { return forks[i] < forks[j] })
+	return forks
+}
+
+// ClientVersion returns the client version advertised to the peers of the specified
+// node version, e.g. seele/v1.0/linux-amd64/go1.10.
+func ClientVersion(version string) string {
+	if len(version) == 0 {
+		version = "unversioned"
+	}
+
+	return fmt.Sprintf("seele/v%s/%s-%s/%s", version, runtime.GOOS, runtime.GOARCH, runtime.Version())
+}
+
+// nodeMeta is the message of the telemetry protocol sent once the peers connect.
+type nodeMeta struct {
+	ClientVersion string
+	ForkID        ForkID
+}
+
+// NetworkSummary is the aggregation of the client versions and fork ids of the connected
+// peers, which contains the counts only and no node ids or addresses.
+type NetworkSummary struct {
+	ClientVersion  string         `json:"clientVersion"`  // client version of the local node
+	ForkID         string         `json:"forkID"`         // fork id of the local node
+	Peers          int            `json:"peers"`          // number of the connected peers
+	ClientVersions map[string]int `json:"clientVersions"` // peer counts by the client version
+	ForkIDs        map[string]int `json:"forkIDs"`        // peer counts by the fork id
+}
+
+// telemetry records the client versions and fork ids observed from the peers, and reports
+// the network summary to the crawler endpoint periodically if configured.
+type telemetry struct {
+	p2p.Protocol
+
+	clientVersion string
+	forks         []uint64
+	chain         *core.Blockchain
+	reportURL     string
+	log           *log.SeeleLog
+
+	lock  sync.RWMutex
+	metas map[common.Address]*nodeMeta // node id => meta of the connected peers
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newTelemetry(clientVersion string, forks []uint64, chain *core.Blockchain, reportURL string, log *log.SeeleLog) *telemetry {
+	t := &telemetry{
+		Protocol: p2p.Protocol{
+			Name:    telemetryProtoName,
+			Version: telemetryVersion,
+			Length:  telemetryMsgCodeLength,
+		},
+		clientVersion: clientVersion,
+		forks:         forks,
+		chain:         chain,
+		reportURL:     reportURL,
+		log:           log,
+		metas:         make(map[common.Address]*nodeMeta),
+		quit:          make(chan struct{}),
+	}
+
+	t.Protocol.AddPeer = t.handleAddPeer
+	return t
+}
+
+// forkID returns the fork id of the local node at the HEAD block.
+func (t *telemetry) forkID() ForkID {
+	genesis, err := t.chain.GetStore().GetBlockHash(0)
+	if err != nil {
+		t.log.Warn("failed to get the genesis hash, %s", err)
+	}
+
+	head, _ := t.chain.CurrentBlock()
+	return newForkID(genesis, t.forks, head.Header.Height)
+}
+
+func (t *telemetry) handleAddPeer(p2pPeer *p2p.Peer, rw p2p.MsgReadWriter) {
+	local := &nodeMeta{t.clientVersion, t.forkID()}
+	if err := p2p.SendMessage(rw, nodeMetaMsgCode, common.SerializePanic(local)); err != nil {
+		t.log.Debug("failed to send node meta to %s, %s", p2pPeer.Node.ID.ToHex(), err)
+		return
+	}
+
+	id := p2pPeer.Node.ID
+	defer func() {
+		t.lock.Lock()
+		delete(t.metas, id)
+		t.lock.Unlock()
+	}()
+
+	// keep reading until the peer disconnects, so that the messages are not blocked
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return
+		}
+
+		if msg.Code != nodeMetaMsgCode {
+			continue
+		}
+
+		meta := new(nodeMeta)
+		if err = common.Deserialize(msg.Payload, meta); err != nil {
+			t.log.Debug("failed to decode node meta from %s, %s", id.ToHex(), err)
+			continue
+		}
+
+		t.lock.Lock()
+		t.metas[id] = meta
+		t.lock.Unlock()
+	}
+}
+
+// meta returns the meta of the connected peer, nil if unknown.
+func (t *telemetry) meta(id common.Address) *nodeMeta {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.metas[id]
+}
+
+// summary aggregates the client versions and fork ids of the specified peers,
+// in which the peers without the telemetry protocol are counted as unknown.
+func (t *telemetry) summary(peers *peerSet) *NetworkSummary {
+	summary := &NetworkSummary{
+		ClientVersion:  t.clientVersion,
+		ForkID:         t.forkID().String(),
+		ClientVersions: make(map[string]int),
+		ForkIDs:        make(map[string]int),
+	}
+
+	peers.ForEach(func(p *peer) bool {
+		summary.Peers++
+
+		if meta := t.meta(p.Node.ID); meta != nil {
+			summary.ClientVersions[meta.ClientVersion]++
+			summary.ForkIDs[meta.ForkID.String()]++
+		} else {
+			summary.ClientVersions[UnknownClientVersion]++
+			summary.ForkIDs[UnknownClientVersion]++
+		}
+
+		return true
+	})
+
+	return summary
+}
+
+// start starts the loop to report the network summary of the peers if the crawler endpoint is configured.
+func (t *telemetry) start(peers *peerSet) {
+	if len(t.reportURL) == 0 {
+		return
+	}
+
+	t.wg.Add(1)
+	go t.reportLoop(peers)
+}
+
+func (t *telemetry) stop() {
+	close(t.quit)
+	t.wg.Wait()
+}
+
+func (t *telemetry) reportLoop(peers *peerSet) {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(networkReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.report(t.summary(peers)); err != nil {
+				t.log.Warn("failed to report the network summary, %s", err)
+			}
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// report posts the network summary in JSON to the crawler endpoint.
+func (t *telemetry) report(summary *NetworkSummary) error {
+	encoded, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: networkReportTimeout}
+	resp, err := client.Post(t.reportURL, "application/json", bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/log"
+	"github.com/seeleteam/go-seele/testutil"
+)
+
+func Test_ForkID(t *testing.T) {
+	genesis := common.StringToHash("genesis")
+	forks := []uint64{100, 200}
+
+	// no fork passed
+	id := newForkID(genesis, forks, 99)
+	assert.Equal(t, id.Next, uint64(100))
+	assert.Equal(t, id, newForkID(genesis, forks, 0))
+
+	// the hash changes once a fork is passed
+	passed := newForkID(genesis, forks, 100)
+	assert.Equal(t, passed.Next, uint64(200))
+	assert.Equal(t, passed.Hash != id.Hash, true)
+
+	// all forks passed
+	last := newForkID(genesis, forks, 200)
+	assert.Equal(t, last.Next, uint64(0))
+	assert.Equal(t, last.Hash != passed.Hash, true)
+
+	// the node unaware of the next fork has the same hash but different next
+	legacy := newForkID(genesis, forks[:1], 150)
+	assert.Equal(t, legacy.Hash, passed.Hash)
+	assert.Equal(t, legacy.Next, uint64(0))
+
+	// different genesis
+	assert.Equal(t, newForkID(common.StringToHash("other"), forks, 99).Hash != id.Hash, true)
+}
+
+func Test_ForkHeights(t *testing.T) {
+	rules := types.DefaultSigHashRules(1)
+	assert.Equal(t, len(forkHeights(rules)), 0)
+
+	rules.Forks = []types.SigHashFork{
+		{Version: types.SigHashLegacy, Height: 0},
+		{Version: types.SigHashV2, Height: 300},
+		{Version: types.SigHashV1, Height: 100},
+		{Version: types.SigHashV3, Height: 300},
+	}
+	assert.Equal(t, forkHeights(rules), []uint64{100, 300})
+}
+
+func Test_Telemetry_Summary(t *testing.T) {
+	chain := testutil.NewChain(t, nil)
+	defer chain.Close()
+
+	tel := newTelemetry(ClientVersion("1.0"), []uint64{100}, chain.Blockchain(), "", log.GetLogger("telemetry", false))
+
+	peers := newPeerSet()
+	peer1, peer2, peer3 := getTestPeer(), getTestPeer(), getTestPeer()
+	peers.Add(peer1)
+	peers.Add(peer2)
+	peers.Add(peer3)
+
+	forkID := newForkID(chain.Head().HeaderHash, []uint64{100}, 0)
+	tel.metas[peer1.Node.ID] = &nodeMeta{"seele/v1.0", forkID}
+	tel.metas[peer2.Node.ID] = &nodeMeta{"seele/v1.0", forkID}
+
+	summary := tel.summary(peers)
+	assert.Equal(t, summary.ClientVersion, ClientVersion("1.0"))
+	assert.Equal(t, summary.ForkID, forkID.String())
+	assert.Equal(t, summary.Peers, 3)
+	assert.Equal(t, summary.ClientVersions, map[string]int{"seele/v1.0": 2, UnknownClientVersion: 1})
+	assert.Equal(t, summary.ForkIDs, map[string]int{forkID.String(): 2, UnknownClientVersion: 1})
+}
+
+func Test_Telemetry_Report(t *testing.T) {
+	var received NetworkSummary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	tel := newTelemetry("", nil, nil, server.URL, log.GetLogger("telemetry", false))
+	summary := &NetworkSummary{
+		Peers:          1,
+		ClientVersions: map[string]int{"seele/v1.0": 1},
+		ForkIDs:        map[string]int{"01234567/0": 1},
+	}
+
+	assert.Equal(t, tel.report(summary), error(nil))
+	assert.Equal(t, received, *summary)
+}
@@ -0,0 +1,159 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+const (
+	// maxPendingTxs is the maximum number of the new txs buffered for a filter,
+	// the later txs are dropped if the filter is not polled in time.
+	maxPendingTxs = 1024
+
+	// pendingTxFilterTimeout is the duration after which the filter not polled is uninstalled.
+	pendingTxFilterTimeout = 5 * time.Minute
+
+	// pendingTxFilterExpiryInterval is the interval to uninstall the filters not polled in time.
+	pendingTxFilterExpiryInterval = time.Minute
+)
+
+// errPendingTxFilterNotFound is returned when the filter id does not exist.
+var errPendingTxFilterNotFound = errors.New("pending tx filter not found")
+
+// pendingTxFilter buffers the txs accepted by the pool since last poll.
+type pendingTxFilter struct {
+	sub      *core.TxSubscription
+	txs      chan *types.Transaction
+	lastPoll time.Time
+}
+
+// pendingTxFilters are the filters of the new txs accepted by the pool, which are polled via RPC.
+type pendingTxFilters struct {
+	pool *core.TransactionPool
+
+	lock    sync.Mutex
+	filters map[uint64]*pendingTxFilter
+	nextID  uint64
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+func newPendingTxFilters(pool *core.TransactionPool) *pendingTxFilters {
+	return &pendingTxFilters{
+		pool:    pool,
+		filters: make(map[uint64]*pendingTxFilter),
+		quit:    make(chan struct{}),
+	}
+}
+
+// start uninstalls the filters not polled in time periodically, so that the abandoned filters
+// are not subscribed to the pool forever.
+func (f *pendingTxFilters) start() {
+	f.wg.Add(1)
+	go f.expiryLoop()
+}
+
+// stop terminates the expiry and uninstalls all the filters.
+func (f *pendingTxFilters) stop() {
+	close(f.quit)
+	f.wg.Wait()
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for id, filter := range f.filters {
+		filter.sub.Unsubscribe()
+		delete(f.filters, id)
+	}
+}
+
+func (f *pendingTxFilters) expiryLoop() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(pendingTxFilterExpiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			f.expire(now)
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// expire uninstalls the filters not polled within pendingTxFilterTimeout.
+func (f *pendingTxFilters) expire(now time.Time) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for id, filter := range f.filters {
+		if now.Sub(filter.lastPoll) > pendingTxFilterTimeout {
+			filter.sub.Unsubscribe()
+			delete(f.filters, id)
+		}
+	}
+}
+
+// newFilter creates a filter of the new txs.
+func (f *pendingTxFilters) newFilter(now time.Time) uint64 {
+	txs := make(chan *types.Transaction, maxPendingTxs)
+	filter := &pendingTxFilter{f.pool.SubscribeNewTxs(txs), txs, now}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.nextID++
+	f.filters[f.nextID] = filter
+	return f.nextID
+}
+
+// uninstallFilter removes the filter of the specified id.
+func (f *pendingTxFilters) uninstallFilter(id uint64) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	filter, ok := f.filters[id]
+	if !ok {
+		return false
+	}
+
+	filter.sub.Unsubscribe()
+	delete(f.filters, id)
+	return true
+}
+
+// getFilterChanges returns and clears the hashes of the new txs of the specified filter since last poll.
+func (f *pendingTxFilters) getFilterChanges(id uint64, now time.Time) ([]common.Hash, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	filter, ok := f.filters[id]
+	if !ok {
+		return nil, errPendingTxFilterNotFound
+	}
+
+	filter.lastPoll = now
+
+	hashes := make([]common.Hash, 0)
+	for {
+		select {
+		case tx := <-filter.txs:
+			hashes = append(hashes, tx.Hash)
+		default:
+			return hashes, nil
+		}
+	}
+}
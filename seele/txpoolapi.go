@@ -0,0 +1,176 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package seele
+
+import (
+	"time"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+// PublicTransactionPoolAPI provides an API to access the tx statistics of the transaction pool.
+type PublicTransactionPoolAPI struct {
+	s *SeeleService
+}
+
+// NewPublicTransactionPoolAPI creates a new PublicTransactionPoolAPI object for rpc service.
+func NewPublicTransactionPoolAPI(s *SeeleService) *PublicTransactionPoolAPI {
+	return &PublicTransactionPoolAPI{s}
+}
+
+// GetInclusionStats returns the latency percentiles in seconds of the recent txs
+// from entering the pool to being included in a block.
+func (api *PublicTransactionPoolAPI) GetInclusionStats(input interface{}, result *map[string]interface{}) error {
+	stats := api.s.TxPool().InclusionStats()
+
+	*result = map[string]interface{}{
+		"samples": stats.Samples,
+		"p50":     stats.P50.Seconds(),
+		"p90":     stats.P90.Seconds(),
+		"p99":     stats.P99.Seconds(),
+		"max":     stats.Max.Seconds(),
+	}
+
+	return nil
+}
+
+// GetSeenStats returns the statistics of the duplicate txs received from peers and RPC,
+// whose validation is skipped for the cached verdicts.
+func (api *PublicTransactionPoolAPI) GetSeenStats(input interface{}, result *map[string]interface{}) error {
+	stats := api.s.TxPool().SeenTxStats()
+
+	*result = map[string]interface{}{
+		"entries":         stats.Entries,
+		"lookups":         stats.Lookups,
+		"duplicates":      stats.Duplicates,
+		"suppressionRate": stats.SuppressionRate,
+	}
+
+	return nil
+}
+
+// Content returns the pending and queued txs in the pool grouped by the sender address in hex,
+// in which the txs of each sender are sorted by nonce.
+func (api *PublicTransactionPoolAPI) Content(input interface{}, result *map[string]map[string][]map[string]interface{}) error {
+	pool := api.s.TxPool()
+
+	*result = map[string]map[string][]map[string]interface{}{
+		core.TxStatusPending: rpcOutputAccountTxs(pool.GetProcessableTransactions()),
+		core.TxStatusQueued:  rpcOutputAccountTxs(pool.GetQueuedTransactions()),
+	}
+
+	return nil
+}
+
+func rpcOutputAccountTxs(accountTxs map[common.Address][]*types.Transaction) map[string][]map[string]interface{} {
+	output := make(map[string][]map[string]interface{})
+	for account, txs := range accountTxs {
+		outputTxs := make([]map[string]interface{}, len(txs))
+		for i, tx := range txs {
+			outputTxs[i] = rpcOutputTx(tx)
+		}
+		output[account.ToHex()] = outputTxs
+	}
+
+	return output
+}
+
+// Status returns the status of the tx of the specified hash in the pool, which is pending,
+// queued or unknown if not in the pool.
+func (api *PublicTransactionPoolAPI) Status(txHash *common.Hash, result *string) error {
+	*result = api.s.TxPool().TransactionStatus(*txHash)
+	return nil
+}
+
+// GetPendingNonce returns the next nonce of the account after its pending txs in the pool.
+func (api *PublicTransactionPoolAPI) GetPendingNonce(account *common.Address, nonce *uint64) error {
+	*nonce = api.s.TxPool().PendingNonce(*account)
+	return nil
+}
+
+// Inspect returns the numbers of the pending and queued txs in the pool, and of their senders.
+func (api *PublicTransactionPoolAPI) Inspect(input interface{}, result *map[string]interface{}) error {
+	pool := api.s.TxPool()
+	pending, queued := pool.GetProcessableTransactions(), pool.GetQueuedTransactions()
+
+	senders := make(map[common.Address]struct{})
+	for account := range pending {
+		senders[account] = struct{}{}
+	}
+	for account := range queued {
+		senders[account] = struct{}{}
+	}
+
+	pendingCount, queuedCount := 0, 0
+	for _, txs := range pending {
+		pendingCount += len(txs)
+	}
+	for _, txs := range queued {
+		queuedCount += len(txs)
+	}
+
+	*result = map[string]interface{}{
+		"pending": pendingCount,
+		"queued":  queuedCount,
+		"senders": len(senders),
+	}
+
+	return nil
+}
+
+// NewPendingTransactionFilter creates a filter of the new txs accepted by the pool, and returns
+// the filter id to poll the tx hashes. The filter not polled for 5 minutes is uninstalled.
+func (api *PublicTransactionPoolAPI) NewPendingTransactionFilter(input interface{}, id *uint64) error {
+	*id = api.s.txFilters.newFilter(time.Now())
+	return nil
+}
+
+// GetPendingTransactionFilterChanges returns the hashes of the new txs of the specified filter since
+// last poll, in which at most 1024 txs are buffered between the polls.
+func (api *PublicTransactionPoolAPI) GetPendingTransactionFilterChanges(id *uint64, result *[]common.Hash) error {
+	hashes, err := api.s.txFilters.getFilterChanges(*id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	*result = hashes
+	return nil
+}
+
+// UninstallPendingTransactionFilter removes the pending tx filter of the specified id.
+func (api *PublicTransactionPoolAPI) UninstallPendingTransactionFilter(id *uint64, result *bool) error {
+	*result = api.s.txFilters.uninstallFilter(*id)
+	return nil
+}
+
+// GetFeeFloor returns the minimum fee per byte of the txs accepted by the pool, see core.FeeFloor.
+func (api *PublicTransactionPoolAPI) GetFeeFloor(input interface{}, result *core.FeeFloor) error {
+	*result = api.s.TxPool().FeeFloor()
+	return nil
+}
+
+// Stuck returns the txs pending in the pool beyond the threshold in seconds with the reasons,
+// the default threshold is used if not specified.
+func (api *PublicTransactionPoolAPI) Stuck(threshold *int64, result *[]map[string]interface{}) error {
+	duration := core.DefaultStuckThreshold
+	if threshold != nil && *threshold > 0 {
+		duration = time.Duration(*threshold) * time.Second
+	}
+
+	stuckTxs := api.s.TxPool().StuckTransactions(duration)
+
+	output := make([]map[string]interface{}, len(stuckTxs))
+	for i, stuck := range stuckTxs {
+		output[i] = rpcOutputTx(stuck.Tx)
+		output[i]["pending"] = stuck.Pending.Seconds()
+		output[i]["reason"] = stuck.Reason
+	}
+
+	*result = output
+	return nil
+}
@@ -0,0 +1,93 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package testutil
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/crypto"
+)
+
+// Account is an account with its private key to sign the txs in tests.
+type Account struct {
+	Address    common.Address
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// NewAccount creates an account of a random key.
+func NewAccount() *Account {
+	addr, privKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		panic(err)
+	}
+
+	return &Account{*addr, privKey}
+}
+
+// NewAccounts creates the specified number of accounts of random keys.
+func NewAccounts(n int) []*Account {
+	accounts := make([]*Account, n)
+	for i := range accounts {
+		accounts[i] = NewAccount()
+	}
+
+	return accounts
+}
+
+// Key returns the keystore key of the account.
+func (account *Account) Key() *keystore.Key {
+	return &keystore.Key{
+		Address:    account.Address,
+		PrivateKey: account.PrivateKey,
+	}
+}
+
+// StoreKey encrypts the account key with the passphrase in a key file of the specified
+// folder, which is named by the account address, and returns the key file path.
+func (account *Account) StoreKey(t testing.TB, dir, passphrase string) string {
+	file := filepath.Join(dir, account.Address.ToHex())
+	if err := keystore.StoreKey(file, passphrase, account.Key()); err != nil {
+		t.Fatalf("failed to store the key of %s, %s", account.Address.ToHex(), err)
+	}
+
+	return file
+}
+
+// NewKeyStore stores the keys of the specified accounts in a temp folder, and returns the
+// folder with the function to remove it once the test is done.
+func NewKeyStore(t testing.TB, passphrase string, accounts ...*Account) (string, func()) {
+	dir, err := ioutil.TempDir("", "testutil-keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispose := func() {
+		os.RemoveAll(dir)
+	}
+
+	for _, account := range accounts {
+		account.StoreKey(t, dir, passphrase)
+	}
+
+	return dir, dispose
+}
+
+// Alloc returns the genesis accounts that allocate the balance to each of the specified accounts.
+func Alloc(balance *big.Int, accounts ...*Account) map[common.Address]*big.Int {
+	alloc := make(map[common.Address]*big.Int)
+	for _, account := range accounts {
+		alloc[account.Address] = new(big.Int).Set(balance)
+	}
+
+	return alloc
+}
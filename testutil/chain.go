@@ -0,0 +1,341 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+// Package testutil provides the helpers for the tests of the projects embedding go-seele,
+// to spin up a chain, fund accounts, mine blocks and assert the state, e.g.
+//
+//	alice, bob := testutil.NewAccount(), testutil.NewAccount()
+//	chain := testutil.NewChain(t, testutil.Alloc(big.NewInt(1000000), alice))
+//	defer chain.Close()
+//
+//	chain.Transfer(alice, bob.Address, big.NewInt(100))
+//	chain.Mine()
+//	chain.AssertBalance(bob.Address, big.NewInt(100))
+//
+// The API of the package is kept stable across releases.
+package testutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/seeleteam/go-seele/common"
+	"github.com/seeleteam/go-seele/core"
+	"github.com/seeleteam/go-seele/core/state"
+	"github.com/seeleteam/go-seele/core/store"
+	"github.com/seeleteam/go-seele/core/types"
+	"github.com/seeleteam/go-seele/database"
+	"github.com/seeleteam/go-seele/database/leveldb"
+)
+
+// ErrReceiptNotFound is returned when the receipt of the tx is not found in the chain.
+var ErrReceiptNotFound = errors.New("receipt not found")
+
+// FaucetBalance is the genesis balance of the faucet account of the chain.
+var FaucetBalance = new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+
+// GasPrice is the gas price of the txs created by the chain.
+var GasPrice = big.NewInt(1)
+
+// Chain is a simulated blockchain in a temp folder, on which the txs sent are pending
+// until a block is mined. The blocks are mined with difficulty 1, which any nonce meets,
+// so the difficulty config of the blockchain should not be set.
+type Chain struct {
+	t       testing.TB
+	dispose func()
+	db      database.Database
+	bcStore store.BlockchainStore
+	chain   *core.Blockchain
+
+	faucet   *Account
+	coinbase *Account
+
+	lock    sync.Mutex
+	pending []*types.Transaction
+}
+
+// NewBlockchain creates a blockchain in a temp folder of which the genesis has the specified accounts
+// only, so that the chains of the same accounts share the genesis. The returned function closes the
+// database and removes the temp folder once the test is done.
+func NewBlockchain(t testing.TB, accounts map[common.Address]*big.Int) (*core.Blockchain, database.Database, func()) {
+	dir, err := ioutil.TempDir("", "testutil-chain")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.NewLevelDB(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	dispose := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	bcStore := store.NewBlockchainDatabase(db)
+	if err = core.GetGenesis(accounts).InitializeAndValidate(bcStore, db); err != nil {
+		dispose()
+		t.Fatal(err)
+	}
+
+	chain, err := core.NewBlockchain(bcStore, db)
+	if err != nil {
+		dispose()
+		t.Fatal(err)
+	}
+
+	return chain, db, dispose
+}
+
+// NewChain creates a chain with the specified genesis accounts, and a faucet account of
+// FaucetBalance to fund other accounts. The chain should be closed once the test is done.
+func NewChain(t testing.TB, accounts map[common.Address]*big.Int) *Chain {
+	c := &Chain{
+		t:        t,
+		faucet:   NewAccount(),
+		coinbase: NewAccount(),
+	}
+
+	alloc := map[common.Address]*big.Int{c.faucet.Address: FaucetBalance}
+	for addr, balance := range accounts {
+		alloc[addr] = balance
+	}
+
+	c.chain, c.db, c.dispose = NewBlockchain(t, alloc)
+	c.bcStore = c.chain.GetStore()
+	return c
+}
+
+// Close closes the database and removes the temp folder of the chain.
+func (c *Chain) Close() {
+	c.dispose()
+}
+
+// Blockchain returns the underlying blockchain, e.g. to create the services under test.
+func (c *Chain) Blockchain() *core.Blockchain {
+	return c.chain
+}
+
+// Database returns the account state database of the chain.
+func (c *Chain) Database() database.Database {
+	return c.db
+}
+
+// Faucet returns the faucet account, which is funded in genesis.
+func (c *Chain) Faucet() *Account {
+	return c.faucet
+}
+
+// Coinbase returns the account rewarded by the mined blocks.
+func (c *Chain) Coinbase() *Account {
+	return c.coinbase
+}
+
+// Head returns the HEAD block of the chain.
+func (c *Chain) Head() *types.Block {
+	head, _ := c.chain.CurrentBlock()
+	return head
+}
+
+// State returns a copy of the state of the HEAD block, which is safe to modify.
+func (c *Chain) State() *state.Statedb {
+	statedb, err := state.NewStatedb(c.Head().Header.StateHash, c.db)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	return statedb
+}
+
+// Balance returns the balance of the account in the HEAD block.
+func (c *Chain) Balance(addr common.Address) *big.Int {
+	return c.State().GetBalance(addr)
+}
+
+// Nonce returns the nonce of the account in the HEAD block.
+func (c *Chain) Nonce(addr common.Address) uint64 {
+	return c.State().GetNonce(addr)
+}
+
+// PendingNonce returns the nonce of the next tx of the account, including the pending txs.
+func (c *Chain) PendingNonce(addr common.Address) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.pendingNonce(addr)
+}
+
+func (c *Chain) pendingNonce(addr common.Address) uint64 {
+	nonce := c.Nonce(addr)
+	for _, tx := range c.pending {
+		if tx.Data.From == addr && tx.Data.AccountNonce >= nonce {
+			nonce = tx.Data.AccountNonce + 1
+		}
+	}
+
+	return nonce
+}
+
+// SendTransaction validates the signed tx against the HEAD block and adds it to the
+// pending txs, which are packed in the next mined block.
+func (c *Chain) SendTransaction(tx *types.Transaction) error {
+	if err := tx.Validate(c.State(), c.chain.ChainConfig()); err != nil {
+		return err
+	}
+
+	if err := tx.ValidateHTLC(c.Head().Header.Height + 1); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.pending = append(c.pending, tx)
+	return nil
+}
+
+// Transfer sends a tx which transfers the amount from the account to the specified
+// address with the next nonce, and returns the tx. The test fails if the tx is invalid.
+func (c *Chain) Transfer(from *Account, to common.Address, amount *big.Int) *types.Transaction {
+	c.lock.Lock()
+	nonce := c.pendingNonce(from.Address)
+	c.lock.Unlock()
+
+	tx := types.NewTransaction(from.Address, to, amount, GasPrice, types.TransferGas, nonce)
+	tx.Sign(from.PrivateKey)
+
+	if err := c.SendTransaction(tx); err != nil {
+		c.t.Fatalf("failed to transfer %s from %s to %s, %s", amount, from.Address.ToHex(), to.ToHex(), err)
+	}
+
+	return tx
+}
+
+// Fund sends a tx which transfers the amount from the faucet to the specified address.
+func (c *Chain) Fund(to common.Address, amount *big.Int) *types.Transaction {
+	return c.Transfer(c.faucet, to, amount)
+}
+
+// Mine packs the pending txs in a new block upon the HEAD block and writes it in the chain.
+// The test fails if any pending tx fails to apply.
+func (c *Chain) Mine() *types.Block {
+	c.lock.Lock()
+	txs := c.pending
+	c.pending = nil
+	c.lock.Unlock()
+
+	block, err := c.newBlock(c.Head(), txs)
+	if err != nil {
+		c.t.Fatalf("failed to mine block, %s", err)
+	}
+
+	if err = c.chain.WriteBlock(block); err != nil {
+		c.t.Fatalf("failed to write block %d, %s", block.Header.Height, err)
+	}
+
+	return block
+}
+
+// MineBlocks mines the specified number of blocks, in which the first one packs the pending txs.
+func (c *Chain) MineBlocks(n int) []*types.Block {
+	blocks := make([]*types.Block, n)
+	for i := range blocks {
+		blocks[i] = c.Mine()
+	}
+
+	return blocks
+}
+
+// newBlock creates a block upon the parent block with the reward tx and the specified txs.
+func (c *Chain) newBlock(parent *types.Block, txs []*types.Transaction) (*types.Block, error) {
+	height := parent.Header.Height + 1
+	header := &types.BlockHeader{
+		PreviousBlockHash: parent.HeaderHash,
+		Creator:           c.coinbase.Address,
+		Height:            height,
+		Difficulty:        big.NewInt(1),
+		CreateTimestamp:   new(big.Int).Add(parent.Header.CreateTimestamp, big.NewInt(1)),
+	}
+
+	statedb, err := state.NewStatedb(parent.Header.StateHash, c.db)
+	if err != nil {
+		return nil, err
+	}
+
+	rewardTx := types.NewRewardTransaction(c.coinbase.Address, c.chain.Engine().BlockReward(height), nil)
+	rewardTx.Sign(c.coinbase.PrivateKey)
+	statedb.GetOrNewStateObject(c.coinbase.Address).AddAmount(rewardTx.Data.Amount)
+
+	receipts := make([]*types.Receipt, len(txs))
+	for i, tx := range txs {
+		if err = tx.ValidateState(statedb); err != nil {
+			return nil, err
+		}
+
+		if receipts[i], err = c.chain.ApplyTransaction(tx, c.coinbase.Address, statedb, header); err != nil {
+			return nil, err
+		}
+	}
+
+	blockTxs := append([]*types.Transaction{rewardTx}, txs...)
+	header.TxHash = types.MerkleRootHash(blockTxs)
+	header.StateHash = statedb.Commit(nil)
+	header.ReceiptHash = types.ReceiptMerkleRootHash(receipts)
+	header.LogsBloom = types.CreateBloom(receipts)
+
+	return &types.Block{
+		HeaderHash:   header.Hash(),
+		Header:       header,
+		Transactions: blockTxs,
+	}, nil
+}
+
+// Receipt returns the receipt of the specified tx packed in the chain.
+func (c *Chain) Receipt(txHash common.Hash) (*types.Receipt, error) {
+	blockHash, err := c.bcStore.GetReceiptBlockHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := c.bcStore.GetReceipts(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, receipt := range receipts {
+		if receipt.TxHash.Equal(txHash) {
+			return receipt, nil
+		}
+	}
+
+	return nil, ErrReceiptNotFound
+}
+
+// AssertBalance fails the test if the balance of the account in the HEAD block is not the expected one.
+func (c *Chain) AssertBalance(addr common.Address, expected *big.Int) {
+	if balance := c.Balance(addr); balance.Cmp(expected) != 0 {
+		c.t.Fatalf("unexpected balance of %s, want %s, got %s", addr.ToHex(), expected, balance)
+	}
+}
+
+// AssertNonce fails the test if the nonce of the account in the HEAD block is not the expected one.
+func (c *Chain) AssertNonce(addr common.Address, expected uint64) {
+	if nonce := c.Nonce(addr); nonce != expected {
+		c.t.Fatalf("unexpected nonce of %s, want %d, got %d", addr.ToHex(), expected, nonce)
+	}
+}
+
+// AssertHeight fails the test if the height of the HEAD block is not the expected one.
+func (c *Chain) AssertHeight(expected uint64) {
+	if height := c.Head().Header.Height; height != expected {
+		c.t.Fatalf("unexpected chain height, want %d, got %d", expected, height)
+	}
+}
@@ -0,0 +1,106 @@
+/**
+*  @file
+*  @copyright defined in go-seele/LICENSE
+ */
+
+package testutil
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/magiconair/properties/assert"
+	"github.com/seeleteam/go-seele/common/keystore"
+	"github.com/seeleteam/go-seele/core/types"
+)
+
+func Test_Chain(t *testing.T) {
+	alice, bob := NewAccount(), NewAccount()
+	chain := NewChain(t, Alloc(big.NewInt(1000000), alice))
+	defer chain.Close()
+
+	chain.AssertBalance(alice.Address, big.NewInt(1000000))
+	chain.AssertBalance(bob.Address, big.NewInt(0))
+
+	tx1 := chain.Transfer(alice, bob.Address, big.NewInt(100))
+	tx2 := chain.Transfer(alice, bob.Address, big.NewInt(200))
+	assert.Equal(t, tx2.Data.AccountNonce, uint64(1))
+	assert.Equal(t, chain.PendingNonce(alice.Address), uint64(2))
+
+	carol := NewAccount()
+	chain.Fund(carol.Address, big.NewInt(500))
+
+	block := chain.Mine()
+	assert.Equal(t, len(block.Transactions), 4)
+	chain.AssertHeight(1)
+	chain.AssertBalance(bob.Address, big.NewInt(300))
+	chain.AssertBalance(carol.Address, big.NewInt(500))
+	chain.AssertBalance(alice.Address, big.NewInt(1000000-300-2*types.TransferGas))
+	chain.AssertNonce(alice.Address, 2)
+
+	receipt, err := chain.Receipt(tx1.Hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, receipt.UsedGas, uint64(types.TransferGas))
+
+	_, err = chain.Receipt(block.HeaderHash)
+	assert.Equal(t, err != nil, true)
+
+	blocks := chain.MineBlocks(3)
+	assert.Equal(t, len(blocks), 3)
+	chain.AssertHeight(4)
+	assert.Equal(t, chain.Head().HeaderHash, blocks[2].HeaderHash)
+	assert.Equal(t, chain.Balance(chain.Coinbase().Address).Sign() > 0, true)
+
+	// invalid tx
+	tx := types.NewTransaction(bob.Address, alice.Address, big.NewInt(1000), GasPrice, types.TransferGas, 0)
+	tx.Sign(bob.PrivateKey)
+	assert.Equal(t, chain.SendTransaction(tx) != nil, true)
+}
+
+func Test_NewKeyStore(t *testing.T) {
+	account := NewAccount()
+	dir, dispose := NewKeyStore(t, "secret", account)
+	defer dispose()
+
+	key, err := keystore.GetKey(filepath.Join(dir, account.Address.ToHex()), "secret")
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, key.Address, account.Address)
+	assert.Equal(t, key.PrivateKey.D, account.PrivateKey.D)
+}
+
+func Test_Chain_HTLC(t *testing.T) {
+	alice, bob := NewAccount(), NewAccount()
+	chain := NewChain(t, Alloc(big.NewInt(1000000), alice))
+	defer chain.Close()
+
+	preimage := make([]byte, types.HTLCPreimageSize)
+	preimage[0] = 1
+	htlc := &types.HTLC{Sender: alice.Address, Receiver: bob.Address, HashLock: types.HTLCHashLock(preimage), TimeLock: 3}
+
+	// lock the funds by transfer
+	chain.Transfer(alice, htlc.Address(), big.NewInt(100000))
+	chain.Mine()
+
+	// refund before the time lock
+	refund, err := types.NewHTLCRefundTransaction(htlc, big.NewInt(10), GasPrice, types.TransferGas, 0)
+	assert.Equal(t, err, error(nil))
+	refund.Sign(alice.PrivateKey)
+	assert.Equal(t, chain.SendTransaction(refund), types.ErrHTLCLocked)
+
+	// claim with the preimage
+	claim, err := types.NewHTLCClaimTransaction(htlc, preimage, big.NewInt(50000), GasPrice, types.TransferGas, 0)
+	assert.Equal(t, err, error(nil))
+	claim.Sign(bob.PrivateKey)
+	assert.Equal(t, chain.SendTransaction(claim), error(nil))
+	chain.Mine()
+
+	chain.AssertBalance(bob.Address, big.NewInt(50000))
+	chain.AssertBalance(htlc.Address(), big.NewInt(100000-50000-types.TransferGas))
+
+	receipt, err := chain.Receipt(claim.Hash)
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, len(receipt.Logs), 1)
+	assert.Equal(t, receipt.Logs[0].Topics[0], types.HTLCClaimTopic)
+	assert.Equal(t, receipt.Logs[0].Data, preimage)
+}
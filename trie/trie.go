@@ -94,6 +94,39 @@ func (t *Trie) Get(key []byte) ([]byte, bool) {
 	return nil, false
 }
 
+// ForEach calls the callback for every [key,value] in the trie.
+// Nodes not loaded yet are read from database but not cached in the trie.
+func (t *Trie) ForEach(callback func(key, value []byte)) error {
+	return t.forEach(t.root, nil, callback)
+}
+
+func (t *Trie) forEach(node noder, prefix []byte, callback func(key, value []byte)) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *ExtendNode:
+		return t.forEach(n.Nextnode, concatNibbles(prefix, n.Key...), callback)
+	case hashNode:
+		child, err := t.loadNode(n)
+		if err != nil {
+			return err
+		}
+		return t.forEach(child, prefix, callback)
+	case *LeafNode:
+		callback(hexToKeybytes(concatNibbles(prefix, n.Key...)), n.Value)
+		return nil
+	case *BranchNode:
+		for i, child := range n.Children {
+			if err := t.forEach(child, concatNibbles(prefix, byte(i)), callback); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		panic(fmt.Sprintf("invalid node: %v", node))
+	}
+}
+
 // Hash return the hash of trie
 func (t *Trie) Hash() common.Hash {
 	if t.root != nil {
@@ -536,6 +569,26 @@ func keybytesToHex(str []byte) []byte {
 	return nibbles
 }
 
+// hexToKeybytes is the reverse of keybytesToHex, and the term key is ignored.
+func hexToKeybytes(nibbles []byte) []byte {
+	for l := len(nibbles); l > 0 && nibbles[l-1] == byte(numBranchNodes-1); l-- {
+		nibbles = nibbles[:l-1]
+	}
+
+	key := make([]byte, len(nibbles)/2)
+	for i := range key {
+		key[i] = nibbles[i*2]*byte(numBranchNodes-1) + nibbles[i*2+1]
+	}
+	return key
+}
+
+func concatNibbles(prefix []byte, nibbles ...byte) []byte {
+	result := make([]byte, len(prefix)+len(nibbles))
+	copy(result, prefix)
+	copy(result[len(prefix):], nibbles)
+	return result
+}
+
 func matchkeyLen(a, b []byte) int {
 	length := len(a)
 	lengthb := len(b)
@@ -196,3 +196,37 @@ func Test_trie_Commit(t *testing.T) {
 	fmt.Println(string(value))
 	assert.Equal(t, string(value), "test2")
 }
+
+func Test_trie_ForEach(t *testing.T) {
+	db, remove := newTestTrieDB()
+	defer remove()
+	trie, err := NewTrie(common.Hash{}, []byte("trietest"), db)
+	if err != nil {
+		panic(err)
+	}
+
+	expected := map[string]string{
+		"12345678":  "test",
+		"12345557":  "test1",
+		"24355879":  "test2",
+		"243558790": "test3",
+	}
+	for k, v := range expected {
+		trie.Put([]byte(k), []byte(v))
+	}
+
+	batch := db.NewBatch()
+	hash := trie.Commit(batch)
+	batch.Commit()
+
+	// iterate the trie loaded from db
+	trienew, err := NewTrie(hash, []byte("trietest"), db)
+	assert.Equal(t, err, error(nil))
+
+	actual := make(map[string]string)
+	err = trienew.ForEach(func(key, value []byte) {
+		actual[string(key)] = string(value)
+	})
+	assert.Equal(t, err, error(nil))
+	assert.Equal(t, actual, expected)
+}